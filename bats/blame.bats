@@ -135,3 +135,42 @@ SQL
     [ "$status" -eq 1 ]
     [[ "$output" =~ "no table named blame_test found" ]] || false
 }
+
+@test "dolt_blame system table annotates each row with the commit that last modified it" {
+    run dolt sql -q "SELECT pk, message FROM dolt_blame_blame_test ORDER BY pk" -r csv
+    [ "$status" -eq 0 ]
+    [[ "${lines[0]}" = "pk,message" ]] || false
+    [[ "${lines[1]}" = "1,create blame_test table" ]] || false
+    [[ "${lines[2]}" = "2,replace richard with harry" ]] || false
+    [[ "${lines[3]}" = "3,add more people to blame_test" ]] || false
+    [[ "${lines[4]}" = "4,add more people to blame_test" ]] || false
+    [ "${#lines[@]}" -eq 5 ]
+
+    run dolt sql -q "SELECT committer, email FROM dolt_blame_blame_test WHERE pk = 2" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Harry Wombat" ]] || false
+    [[ "$output" =~ "bats-3@email.fake" ]] || false
+}
+
+@test "dolt_blame system table can be joined and filtered" {
+    run dolt sql -q "SELECT t.name FROM blame_test t JOIN dolt_blame_blame_test b ON t.pk = b.pk WHERE b.committer LIKE 'Johnny Moolah%' ORDER BY t.name" -r csv
+    [ "$status" -eq 0 ]
+    [[ "${lines[1]}" = "Alan" ]] || false
+    [[ "${lines[2]}" = "Betty" ]] || false
+    [ "${#lines[@]}" -eq 3 ]
+
+    run dolt sql -q "SELECT COUNT(DISTINCT commit_hash) FROM dolt_blame_blame_test" -r csv
+    [ "$status" -eq 0 ]
+    [[ "${lines[1]}" = "3" ]] || false
+}
+
+@test "dolt_blame system table is based on the head commit" {
+    dolt sql -q "insert into blame_test (pk,name) values (5, \"Uncommitted\")"
+    run dolt sql -q "SELECT COUNT(*) FROM dolt_blame_blame_test" -r csv
+    [ "$status" -eq 0 ]
+    [[ "${lines[1]}" = "4" ]] || false
+
+    run dolt sql -q "SELECT * FROM dolt_blame_not_a_table"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "not found" ]] || false
+}
@@ -0,0 +1,152 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql <<SQL
+CREATE TABLE test (
+  pk BIGINT NOT NULL,
+  c1 BIGINT CHECK (c1 >= 0),
+  c2 BIGINT,
+  PRIMARY KEY (pk),
+  CONSTRAINT chk_sum CHECK (c1 + c2 < 10)
+);
+INSERT INTO test VALUES (1,0,0),(2,1,1);
+SQL
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "check-constraints: checks are part of the schema" {
+    run dolt schema show test
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "CONSTRAINT \`test_chk_1\` CHECK (c1 >= 0)" ]] || false
+    [[ "$output" =~ "CONSTRAINT \`chk_sum\` CHECK (c1 + c2 < 10)" ]] || false
+}
+
+@test "check-constraints: inserts and updates that violate a check fail" {
+    run dolt sql -q "INSERT INTO test VALUES (3,-1,0)"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "Check constraint 'test_chk_1' is violated." ]] || false
+
+    run dolt sql -q "INSERT INTO test VALUES (3,5,5)"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "Check constraint 'chk_sum' is violated." ]] || false
+
+    run dolt sql -q "UPDATE test SET c2 = 20 WHERE pk = 1"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "Check constraint 'chk_sum' is violated." ]] || false
+
+    run dolt sql -q "INSERT INTO test VALUES (3,NULL,0)"
+    [ "$status" -eq 0 ]
+
+    run dolt sql -q "SELECT COUNT(*) FROM test" -r csv
+    [[ "$output" =~ "3" ]] || false
+}
+
+@test "check-constraints: alter table add and drop check" {
+    dolt sql -q "ALTER TABLE test ADD CONSTRAINT chk_pk CHECK (pk < 100)"
+    run dolt sql -q "INSERT INTO test VALUES (100,0,0)"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "Check constraint 'chk_pk' is violated." ]] || false
+
+    dolt sql -q "ALTER TABLE test DROP CHECK chk_pk"
+    dolt sql -q "INSERT INTO test VALUES (100,0,0)"
+
+    dolt sql -q "ALTER TABLE test DROP CONSTRAINT chk_sum"
+    dolt sql -q "INSERT INTO test VALUES (101,5,5)"
+    run dolt schema show test
+    [[ ! "$output" =~ "chk_sum" ]] || false
+
+    run dolt sql -q "ALTER TABLE test DROP CHECK chk_sum"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "does not exist" ]] || false
+}
+
+@test "check-constraints: adding a check validates existing rows" {
+    run dolt sql -q "ALTER TABLE test ADD CONSTRAINT chk_c1 CHECK (c1 > 0)"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "Check constraint 'chk_c1' is violated." ]] || false
+
+    run dolt schema show test
+    [[ ! "$output" =~ "chk_c1" ]] || false
+
+    run dolt sql -q "ALTER TABLE test ADD CONSTRAINT chk_c1 CHECK (c1 > 0) NOT ENFORCED"
+    [ "$status" -eq 0 ]
+    run dolt schema show test
+    [[ "$output" =~ "CONSTRAINT \`chk_c1\` CHECK (c1 > 0) NOT ENFORCED" ]] || false
+
+    run dolt sql -q "INSERT INTO test VALUES (3,0,0)"
+    [ "$status" -eq 0 ]
+}
+
+@test "check-constraints: invalid check expressions are rejected" {
+    run dolt sql -q "ALTER TABLE test ADD CONSTRAINT chk_bad CHECK (c3 > 0)"
+    [ "$status" -eq 1 ]
+
+    run dolt sql -q "CREATE TABLE bad (pk int PRIMARY KEY, CHECK (nope > 0))"
+    [ "$status" -eq 1 ]
+    run dolt ls
+    [[ ! "$output" =~ "bad" ]] || false
+}
+
+@test "check-constraints: columns used by a check can't be dropped or renamed" {
+    run dolt sql -q "ALTER TABLE test DROP COLUMN c2"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "check constraint \`chk_sum\`" ]] || false
+
+    run dolt sql -q "ALTER TABLE test RENAME COLUMN c2 TO c3"
+    [ "$status" -eq 1 ]
+
+    dolt sql -q "ALTER TABLE test DROP CHECK chk_sum"
+    dolt sql -q "ALTER TABLE test DROP COLUMN c2"
+}
+
+@test "check-constraints: merged rows that violate a check are conflicts" {
+    dolt add .
+    dolt commit -m "created table"
+    dolt branch other
+
+    dolt sql -q "UPDATE test SET c1 = 5 WHERE pk = 1"
+    dolt add .
+    dolt commit -m "ours"
+    dolt checkout other
+    dolt sql -q "UPDATE test SET c2 = 5 WHERE pk = 1"
+    dolt add .
+    dolt commit -m "theirs"
+    dolt checkout master
+
+    run dolt merge other
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "CONFLICT (check)" ]] || false
+
+    run dolt sql -q "SELECT * FROM test" -r csv
+    [[ "$output" =~ "1,5,0" ]] || false
+}
+
+@test "check-constraints: verify-constraints checks enforced checks" {
+    dolt sql -q "ALTER TABLE test ADD CONSTRAINT chk_c2 CHECK (c2 > 0) NOT ENFORCED"
+    run dolt verify-constraints test
+    [ "$status" -eq 0 ]
+    [ "$output" = "" ]
+}
+
+@test "check-constraints: diff shows added and dropped checks" {
+    dolt add .
+    dolt commit -m "created table"
+    dolt sql -q "ALTER TABLE test DROP CHECK chk_sum"
+    dolt sql -q "ALTER TABLE test ADD CONSTRAINT chk_c2 CHECK (c2 < 5)"
+
+    run dolt diff --schema
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "chk_sum" ]] || false
+    [[ "$output" =~ "chk_c2" ]] || false
+
+    run dolt diff --schema -r sql
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "ALTER TABLE \`test\` DROP CHECK \`chk_sum\`;" ]] || false
+    [[ "$output" =~ "ALTER TABLE \`test\` ADD CONSTRAINT \`chk_c2\` CHECK (c2 < 5);" ]] || false
+}
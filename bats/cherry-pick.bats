@@ -0,0 +1,159 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql <<SQL
+CREATE TABLE test (
+  pk BIGINT NOT NULL,
+  c1 BIGINT,
+  PRIMARY KEY (pk)
+);
+INSERT INTO test VALUES (1,1),(2,2);
+SQL
+    dolt add .
+    dolt commit -m "added table"
+
+    dolt checkout -b release
+    dolt sql -q "INSERT INTO test VALUES (3,3)"
+    dolt add .
+    dolt commit -m "hotfix"
+    dolt sql -q "UPDATE test SET c1 = 20 WHERE pk = 2"
+    dolt add .
+    dolt commit -m "update row 2"
+    dolt checkout master
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "cherry-pick: applies a single commit" {
+    run dolt cherry-pick release~1
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "hotfix" ]] || false
+
+    run dolt sql -q "SELECT * FROM test ORDER BY pk" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "1,1" ]] || false
+    [[ "$output" =~ "2,2" ]] || false
+    [[ "$output" =~ "3,3" ]] || false
+
+    run dolt log -n 1
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "hotfix" ]] || false
+
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+}
+
+@test "cherry-pick: --no-commit stages changes without committing" {
+    run dolt cherry-pick --no-commit release~1
+    [ "$status" -eq 0 ]
+
+    run dolt log -n 1
+    [[ ! "$output" =~ "hotfix" ]] || false
+
+    run dolt status
+    [[ "$output" =~ "Changes to be committed" ]] || false
+}
+
+@test "cherry-pick: refuses with uncommitted changes" {
+    dolt sql -q "INSERT INTO test VALUES (4,4)"
+    run dolt cherry-pick release~1
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "local changes would be overwritten by cherry-pick" ]] || false
+}
+
+@test "cherry-pick: commit already applied" {
+    dolt cherry-pick release~1
+    run dolt cherry-pick release~1
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "no changes to apply" ]] || false
+}
+
+@test "cherry-pick: conflicts are written to the conflicts tables" {
+    dolt sql -q "UPDATE test SET c1 = 99 WHERE pk = 2"
+    dolt add .
+    dolt commit -m "conflicting update"
+
+    run dolt cherry-pick release
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "CONFLICT" ]] || false
+
+    run dolt sql -q "SELECT our_c1, their_c1 FROM dolt_conflicts_test" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "99,20" ]] || false
+
+    dolt conflicts resolve --theirs test
+    dolt add test
+    dolt commit -m "resolved cherry-pick"
+
+    run dolt sql -q "SELECT c1 FROM test WHERE pk = 2" -r csv
+    [[ "$output" =~ "20" ]] || false
+}
+
+@test "cherry-pick: table drop" {
+    dolt checkout -b dropper
+    dolt sql -q "DROP TABLE test"
+    dolt add .
+    dolt commit -m "drop test"
+    dolt checkout master
+
+    run dolt cherry-pick dropper
+    [ "$status" -eq 0 ]
+
+    run dolt ls
+    [[ ! "$output" =~ "test" ]] || false
+}
+
+@test "cherry-pick: unrelated table dropped on the current branch" {
+    dolt sql -q "CREATE TABLE other (pk BIGINT PRIMARY KEY)"
+    dolt add .
+    dolt commit -m "added other"
+    dolt checkout -b feature
+    dolt sql -q "INSERT INTO test VALUES (7,7)"
+    dolt add .
+    dolt commit -m "insert 7"
+    dolt checkout master
+    dolt sql -q "DROP TABLE other"
+    dolt add .
+    dolt commit -m "drop other"
+
+    run dolt cherry-pick feature
+    [ "$status" -eq 0 ]
+
+    run dolt ls
+    [[ ! "$output" =~ "other" ]] || false
+
+    run dolt sql -q "SELECT * FROM test WHERE pk = 7" -r csv
+    [[ "$output" =~ "7,7" ]] || false
+}
+
+@test "cherry-pick: table dropped on one side and modified on the other" {
+    dolt sql -q "DROP TABLE test"
+    dolt add .
+    dolt commit -m "drop test"
+
+    run dolt cherry-pick release
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "deleted and modified" ]] || false
+}
+
+@test "cherry-pick: merge commits are rejected" {
+    dolt checkout -b other
+    dolt sql -q "INSERT INTO test VALUES (5,5)"
+    dolt add .
+    dolt commit -m "insert 5"
+    dolt checkout master
+    dolt sql -q "INSERT INTO test VALUES (6,6)"
+    dolt add .
+    dolt commit -m "insert 6"
+    dolt merge other
+    dolt commit -m "merge other"
+    dolt checkout release
+
+    run dolt cherry-pick master
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "merge commit" ]] || false
+}
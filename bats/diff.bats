@@ -459,3 +459,89 @@ SQL
     [ $status -eq 0 ]
     [[ $output =~ "CONSTRAINT \`fk_named\` FOREIGN KEY (\`cv1\`) REFERENCES \`parent\` (\`pv1\`)" ]] || false
 }
+
+@test "diff -r json" {
+    dolt sql -q "insert into test values (0,0,0,0,0,0), (1,1,1,1,1,1)"
+    dolt add .
+    dolt commit -m rows
+    dolt sql -q "update test set c1 = 10 where pk = 0"
+    dolt sql -q "delete from test where pk = 1"
+    dolt sql -q "insert into test values (2,2,2,2,2,2)"
+    dolt sql -q "alter table test add column c6 bigint"
+
+    run dolt diff -r json
+    [ "$status" -eq 0 ]
+    [ "$(echo "$output" | jq -r '.tables[0].table')" = "test" ]
+    [ "$(echo "$output" | jq -r '.tables[0].diff_type')" = "modified" ]
+    [ "$(echo "$output" | jq -r '.tables[0].schema_diff.columns[0].diff_type')" = "added" ]
+    [ "$(echo "$output" | jq -r '.tables[0].schema_diff.columns[0].to.name')" = "c6" ]
+    [ "$(echo "$output" | jq '.tables[0].rows | length')" -eq 3 ]
+    [ "$(echo "$output" | jq -c '.tables[0].rows[] | select(.diff_type == "modified") | [.key.pk, .from.c1, .to.c1]')" = "[0,0,10]" ]
+    [ "$(echo "$output" | jq -c '.tables[0].rows[] | select(.diff_type == "removed") | [.key.pk, .to]')" = "[1,null]" ]
+    [ "$(echo "$output" | jq -c '.tables[0].rows[] | select(.diff_type == "added") | [.key.pk, .from, .to.c5]')" = "[2,null,2]" ]
+
+    run dolt diff -r json -d
+    [ "$status" -eq 0 ]
+    [ "$(echo "$output" | jq '.tables[0] | has("schema_diff")')" = "false" ]
+
+    run dolt diff -r json -s
+    [ "$status" -eq 0 ]
+    [ "$(echo "$output" | jq '.tables[0] | has("rows")')" = "false" ]
+
+    run dolt diff -r json --summary
+    [ "$status" -eq 1 ]
+}
+
+@test "diff -r json with no changes" {
+    dolt add .
+    dolt commit -m table
+    run dolt diff -r json
+    [ "$status" -eq 0 ]
+    [ "$output" = '{"tables":[]}' ]
+}
+
+@test "diff -r csv" {
+    dolt sql -q "insert into test values (0,0,0,0,0,0), (1,1,1,1,1,1)"
+    dolt add .
+    dolt commit -m rows
+    dolt sql -q "update test set c1 = 10 where pk = 0"
+    dolt sql -q "delete from test where pk = 1"
+    dolt sql -q "create table other (pk int primary key, c1 varchar(20))"
+    dolt sql -q "insert into other values (1, 'a,b')"
+
+    run dolt diff -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "table,diff_type,from_pk,from_c1,from_c2,from_c3,from_c4,from_c5,to_pk,to_c1,to_c2,to_c3,to_c4,to_c5" ]] || false
+    [[ "$output" =~ "test,modified,0,0,0,0,0,0,0,10,0,0,0,0" ]] || false
+    [[ "$output" =~ "test,removed,1,1,1,1,1,1,,,,,," ]] || false
+    [[ "$output" =~ "table,diff_type,from_pk,from_c1,to_pk,to_c1" ]] || false
+    [[ "$output" =~ 'other,added,,,1,"a,b"' ]] || false
+
+    run dolt diff -r csv test
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 3 ]
+    [ "${lines[0]}" = "table,diff_type,from_pk,from_c1,from_c2,from_c3,from_c4,from_c5,to_pk,to_c1,to_c2,to_c3,to_c4,to_c5" ]
+
+    run dolt diff -r csv -s
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "--schema cannot be combined with -r csv" ]] || false
+}
+
+@test "diff shows path level changes to json columns" {
+    dolt sql -q "create table js (pk int primary key, doc json)"
+    dolt sql -q "insert into js values (1, '{\"a\": {\"x\": [1, 2]}, \"b\": true}')"
+    dolt add .
+    dolt commit -m "json table"
+    dolt sql -q "update js set doc = '{\"a\": {\"x\": [1, 3]}, \"c\": null}' where pk = 1"
+
+    run dolt diff
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "doc @ pk=1" ]] || false
+    [[ "$output" =~ '~ $.a.x[1]: 2 -> 3' ]] || false
+    [[ "$output" =~ '- $.b: true' ]] || false
+    [[ "$output" =~ '+ $.c: null' ]] || false
+
+    run dolt diff -r json
+    [ "$status" -eq 0 ]
+    [ "$(echo "$output" | jq -c '.tables[0].rows[0].json_diffs.doc[0]')" = '{"diff_type":"modified","path":"$.a.x[1]","from":2,"to":3}' ]
+}
@@ -7,8 +7,8 @@ from io import StringIO
 from multiprocessing import Process
 
 
-def _connect(user, host, port, database):
-    return mysql.connector.connect(user=user, host=host, port=port, database=database)
+def _connect(user, host, port, database, password=''):
+    return mysql.connector.connect(user=user, password=password, host=host, port=port, database=database)
 
 
 def _print_err_and_exit(e):
@@ -27,8 +27,9 @@ def csv_to_row_maps(csv_str):
 
 
 class DoltConnection(object):
-    def __init__(self, user='root', host='127.0.0.1', port=3306, database='dolt', auto_commit=False):
+    def __init__(self, user='root', host='127.0.0.1', port=3306, database='dolt', auto_commit=False, password=''):
         self.user = user
+        self.password = password
         self.host = host
         self.port = port
         self.database = database
@@ -37,7 +38,7 @@ class DoltConnection(object):
 
     def connect(self):
         try:
-            self.cnx = _connect(self.user, self.host, self.port, self.database)
+            self.cnx = _connect(self.user, self.host, self.port, self.database, self.password)
             self.cnx.autocommit=self.auto_commit
         except BaseException as e:
             _print_err_and_exit(e)
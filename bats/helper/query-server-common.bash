@@ -29,7 +29,9 @@ else:
 
 from pytest import DoltConnection, csv_to_row_maps
 
-dc = DoltConnection(port=int(port_str), database=database, user='dolt', auto_commit=auto_commit)
+user = os.environ.get('SQL_USER', 'dolt')
+password = os.environ.get('SQL_PASSWORD', '')
+dc = DoltConnection(port=int(port_str), database=database, user=user, password=password, auto_commit=auto_commit)
 dc.connect()
 
 queries = query_strs.split(';')
@@ -112,6 +114,25 @@ behavior:
 }
 
 
+# start_sql_server_with_privileges starts a server whose users and privileges are persisted to privileges.json
+start_sql_server_with_privileges() {
+    DEFAULT_DB="$1"
+    let PORT="$$ % (65536-1024) + 1024"
+    echo "
+user:
+  name: dolt
+  privilege_file: privileges.json
+
+listener:
+  host: 0.0.0.0
+  port: $PORT
+  max_connections: 10
+" > .privconfig.yaml
+    dolt sql-server --config .privconfig.yaml &
+    SERVER_PID=$!
+    wait_for_connection $PORT 5000
+}
+
 start_multi_db_server() {
     DEFAULT_DB="$1"
     let PORT="$$ % (65536-1024) + 1024"
@@ -121,7 +142,9 @@ start_multi_db_server() {
 }
 
 stop_sql_server() {
-    kill $SERVER_PID
+    if [ -n "$SERVER_PID" ]; then
+        kill $SERVER_PID
+    fi
 }
 
 # server_query connects to a running mysql server, executes a query and compares the results against what is expected.
@@ -0,0 +1,164 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql <<SQL
+CREATE TABLE test (
+  pk BIGINT NOT NULL,
+  c1 BIGINT,
+  updated_at DATETIME,
+  PRIMARY KEY (pk)
+);
+INSERT INTO test VALUES (1,1,'2020-01-01'),(2,2,'2020-01-01'),(3,3,'2020-01-01');
+SQL
+    dolt add .
+    dolt commit -m "created table"
+    dolt branch other
+}
+
+teardown() {
+    teardown_common
+}
+
+conflicting_updates() {
+    dolt sql <<SQL
+UPDATE test SET c1 = 10, updated_at = '2021-01-01' WHERE pk = 1;
+UPDATE test SET c1 = 20, updated_at = '2019-01-01' WHERE pk = 2;
+DELETE FROM test WHERE pk = 3;
+SQL
+    dolt add .
+    dolt commit -m "ours"
+    dolt checkout other
+    dolt sql -q "UPDATE test SET c1 = c1 + 100, updated_at = '2020-06-01'"
+    dolt add .
+    dolt commit -m "theirs"
+    dolt checkout master
+}
+
+@test "merge-policies: dolt_merge_policies is empty until a policy is added" {
+    run dolt sql -q "SELECT * FROM dolt_merge_policies" -r csv
+    [ "$status" -eq 0 ]
+    [[ "${lines[0]}" = "table_name,strategy,expression" ]] || false
+    [ "${#lines[@]}" -eq 1 ]
+
+    run dolt ls
+    [[ ! "$output" =~ "dolt_merge_policies" ]] || false
+
+    dolt sql -q "INSERT INTO dolt_merge_policies VALUES ('test', 'theirs', NULL)"
+    run dolt sql -q "SELECT * FROM dolt_merge_policies" -r csv
+    [[ "$output" =~ "test,theirs," ]] || false
+
+    run dolt status
+    [[ "$output" =~ "dolt_merge_policies" ]] || false
+}
+
+@test "merge-policies: without a policy conflicts are recorded" {
+    conflicting_updates
+
+    run dolt merge other
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "CONFLICT (content): Merge conflict in test" ]] || false
+}
+
+@test "merge-policies: ours strategy keeps our rows" {
+    dolt sql -q "INSERT INTO dolt_merge_policies VALUES ('test', 'ours', NULL)"
+    conflicting_updates
+
+    run dolt merge other
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "3 conflicting rows resolved by merge policy" ]] || false
+    [[ ! "$output" =~ "CONFLICT" ]] || false
+
+    run dolt sql -q "SELECT pk, c1 FROM test ORDER BY pk" -r csv
+    [[ "$output" =~ "1,10" ]] || false
+    [[ "$output" =~ "2,20" ]] || false
+    [[ ! "$output" =~ "3," ]] || false
+
+    dolt commit -m "merged"
+}
+
+@test "merge-policies: theirs strategy takes their rows" {
+    dolt sql -q "INSERT INTO dolt_merge_policies VALUES ('test', 'theirs', NULL)"
+    conflicting_updates
+
+    run dolt merge other
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "CONFLICT" ]] || false
+
+    run dolt sql -q "SELECT pk, c1 FROM test ORDER BY pk" -r csv
+    [[ "$output" =~ "1,101" ]] || false
+    [[ "$output" =~ "2,102" ]] || false
+    [[ "$output" =~ "3,103" ]] || false
+
+    dolt commit -m "merged"
+}
+
+@test "merge-policies: sql strategy picks the row with the latest timestamp" {
+    dolt sql -q "INSERT INTO dolt_merge_policies VALUES ('test', 'sql', 'IF(theirs.updated_at > ours.updated_at, ''theirs'', ''ours'')')"
+    conflicting_updates
+
+    run dolt merge other
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2 conflicting rows resolved by merge policy" ]] || false
+    [[ "$output" =~ "CONFLICT (content): Merge conflict in test" ]] || false
+
+    run dolt sql -q "SELECT pk, c1 FROM test ORDER BY pk" -r csv
+    [[ "$output" =~ "1,10" ]] || false
+    [[ "$output" =~ "2,102" ]] || false
+
+    # the deleted row is left in conflict
+    run dolt sql -q "SELECT base_pk FROM dolt_conflicts_test" -r csv
+    [[ "$output" =~ "3" ]] || false
+}
+
+@test "merge-policies: sql strategy can name the winning side" {
+    dolt sql -q "INSERT INTO dolt_merge_policies VALUES ('test', 'sql', 'IF(theirs.c1 > ours.c1, ''theirs'', ''ours'')')"
+    conflicting_updates
+
+    run dolt merge other
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2 conflicting rows resolved by merge policy" ]] || false
+
+    run dolt sql -q "SELECT pk, c1 FROM test ORDER BY pk" -r csv
+    [[ "$output" =~ "1,101" ]] || false
+    [[ "$output" =~ "2,102" ]] || false
+}
+
+@test "merge-policies: policies for other tables do not apply" {
+    dolt sql -q "INSERT INTO dolt_merge_policies VALUES ('other_table', 'theirs', NULL)"
+    conflicting_updates
+
+    run dolt merge other
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "CONFLICT (content): Merge conflict in test" ]] || false
+}
+
+@test "merge-policies: invalid policies fail the merge" {
+    dolt sql -q "INSERT INTO dolt_merge_policies VALUES ('test', 'newest', NULL)"
+    conflicting_updates
+
+    run dolt merge other
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "unknown strategy 'newest'" ]] || false
+
+    dolt sql -q "UPDATE dolt_merge_policies SET strategy = 'sql'"
+    dolt commit -am "sql policy without expression"
+    run dolt merge other
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "has no expression" ]] || false
+}
+
+@test "merge-policies: policies are merged like other tables" {
+    dolt checkout other
+    dolt sql -q "INSERT INTO dolt_merge_policies VALUES ('test', 'theirs', NULL)"
+    dolt add .
+    dolt commit -m "added policy"
+    dolt checkout master
+
+    run dolt merge other
+    [ "$status" -eq 0 ]
+
+    run dolt sql -q "SELECT * FROM dolt_merge_policies" -r csv
+    [[ "$output" =~ "test,theirs," ]] || false
+}
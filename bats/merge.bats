@@ -122,6 +122,26 @@ teardown() {
     [[ "$output" =~ "| 0 " ]] || false
 }
 
+@test "3way merge reports added and deleted tables" {
+    dolt checkout -b merge_branch
+    dolt sql -q "CREATE TABLE test3 (pk BIGINT PRIMARY KEY)"
+    dolt sql -q "DROP TABLE test2"
+    dolt add .
+    dolt commit -m "add test3 and drop test2"
+
+    dolt checkout master
+    dolt sql -q "INSERT INTO test1 values (1,2,3)"
+    dolt add test1
+    dolt commit -m "add pk 1 to test1"
+
+    run dolt merge merge_branch
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "test3 added" ]] || false
+    [[ "$output" =~ "test2 deleted" ]] || false
+    [[ ! "$output" =~ "test2 added" ]] || false
+    [[ ! "$output" =~ "test3 deleted" ]] || false
+}
+
 @test "dolt add fails on table with conflict" {
     dolt checkout -b merge_branch
     dolt SQL -q "INSERT INTO test1 values (0,1,1)"
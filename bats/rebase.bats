@@ -0,0 +1,291 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql <<SQL
+CREATE TABLE test (
+  pk BIGINT NOT NULL,
+  c1 BIGINT,
+  PRIMARY KEY (pk)
+);
+INSERT INTO test VALUES (1,1),(2,2);
+SQL
+    dolt add .
+    dolt commit -m "added table"
+
+    dolt checkout -b feature
+    dolt sql -q "INSERT INTO test VALUES (3,3)"
+    dolt add .
+    dolt commit -m "insert 3"
+    dolt sql -q "INSERT INTO test VALUES (4,4)"
+    dolt add .
+    dolt commit -m "insert 4"
+
+    dolt checkout master
+    dolt sql -q "INSERT INTO test VALUES (5,5)"
+    dolt add .
+    dolt commit -m "insert 5"
+    dolt checkout feature
+}
+
+teardown() {
+    teardown_common
+}
+
+# write_todo_editor creates an editor script which replaces the todo file with its arguments, one line each
+write_todo_editor() {
+    editor="$BATS_TMPDIR/todo-editor.sh"
+    {
+        echo '#!/bin/bash'
+        echo "printf '%s\n' \\"
+        for line in "$@"; do
+            echo "  '$line' \\"
+        done
+        echo '  > "$1"'
+    } > "$editor"
+    chmod +x "$editor"
+    export EDITOR="$editor"
+}
+
+@test "rebase: replays commits on top of upstream" {
+    run dolt rebase master
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Successfully rebased and updated refs/heads/feature" ]] || false
+
+    run dolt sql -q "SELECT pk FROM test ORDER BY pk" -r csv
+    [[ "$output" =~ "1" ]] || false
+    [[ "$output" =~ "3" ]] || false
+    [[ "$output" =~ "4" ]] || false
+    [[ "$output" =~ "5" ]] || false
+
+    run dolt log
+    [[ "$output" =~ "insert 4" ]] || false
+    [[ "$output" =~ "insert 3" ]] || false
+    [[ "$output" =~ "insert 5" ]] || false
+    [[ ! "$output" =~ "Merge" ]] || false
+
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+}
+
+@test "rebase: up to date" {
+    dolt checkout master
+    run dolt rebase feature~2
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "is up to date" ]] || false
+
+    run dolt rebase -i feature~2
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "is up to date" ]] || false
+}
+
+@test "rebase: refuses to rebase merge commits" {
+    dolt checkout -b side
+    dolt sql -q "INSERT INTO test VALUES (6,6)"
+    dolt add .
+    dolt commit -m "insert 6"
+    dolt checkout feature
+    dolt sql -q "INSERT INTO test VALUES (7,7)"
+    dolt add .
+    dolt commit -m "insert 7"
+    dolt merge side
+    dolt add .
+    dolt commit -m "merge side"
+
+    run dolt rebase master
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "cannot rebase a merge commit" ]] || false
+}
+
+@test "rebase: refuses with uncommitted changes" {
+    dolt sql -q "INSERT INTO test VALUES (6,6)"
+    run dolt rebase master
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "local changes would be overwritten by rebase" ]] || false
+}
+
+@test "rebase: stops on conflicts and continues" {
+    dolt checkout master
+    dolt sql -q "INSERT INTO test VALUES (3,30)"
+    dolt add .
+    dolt commit -m "conflicting insert 3"
+    dolt checkout feature
+
+    run dolt rebase master
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "CONFLICT" ]] || false
+    [[ "$output" =~ "could not apply" ]] || false
+
+    run dolt rebase master
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "rebase is already in progress" ]] || false
+
+    run dolt rebase --continue
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "unresolved conflicts" ]] || false
+
+    dolt conflicts resolve --theirs test
+    dolt add test
+    run dolt rebase --continue
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Successfully rebased" ]] || false
+
+    run dolt sql -q "SELECT * FROM test ORDER BY pk" -r csv
+    [[ "$output" =~ "3,3" ]] || false
+    [[ "$output" =~ "4,4" ]] || false
+    [[ "$output" =~ "5,5" ]] || false
+
+    run dolt log -n 1
+    [[ "$output" =~ "insert 4" ]] || false
+}
+
+@test "rebase: abort restores the branch" {
+    dolt checkout master
+    dolt sql -q "INSERT INTO test VALUES (3,30)"
+    dolt add .
+    dolt commit -m "conflicting insert 3"
+    dolt checkout feature
+    orig=$(dolt log -n 1 | head -n 1)
+
+    run dolt rebase master
+    [ "$status" -eq 1 ]
+
+    run dolt rebase --abort
+    [ "$status" -eq 0 ]
+
+    run dolt log -n 1
+    [[ "$output" =~ "$orig" ]] || false
+
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+
+    run dolt rebase --abort
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "no rebase in progress" ]] || false
+}
+
+@test "rebase: checkout, merge, commit and stash are refused while a rebase is stopped" {
+    dolt checkout master
+    dolt sql -q "INSERT INTO test VALUES (3,30)"
+    dolt add .
+    dolt commit -m "conflicting insert 3"
+    dolt checkout feature
+    orig=$(dolt log -n 1 | head -n 1)
+
+    run dolt rebase master
+    [ "$status" -eq 1 ]
+
+    run dolt checkout -b other
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "cannot checkout a branch while a rebase is in progress" ]] || false
+
+    run dolt checkout master
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "cannot checkout a branch while a rebase is in progress" ]] || false
+
+    run dolt merge master
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "cannot merge while a rebase is in progress" ]] || false
+
+    run dolt commit -m "commit during rebase"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "cannot commit while a rebase is in progress" ]] || false
+
+    run dolt stash push
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "while a rebase is in progress" ]] || false
+
+    dolt rebase --abort
+    run dolt log -n 1
+    [[ "$output" =~ "$orig" ]] || false
+
+    run dolt branch
+    [[ ! "$output" =~ "other" ]] || false
+}
+
+@test "rebase: squash after a commit already on upstream keeps the upstream commit" {
+    dolt checkout master
+    dolt sql -q "INSERT INTO test VALUES (3,3)"
+    dolt add .
+    dolt commit -m "insert 3 on master"
+    master=$(dolt log -n 1 | grep commit | awk '{print $2}')
+    dolt checkout feature
+    c3=$(dolt log -n 2 | grep commit | tail -n 1 | awk '{print $2}')
+    c4=$(dolt log -n 1 | grep commit | awk '{print $2}')
+
+    write_todo_editor "pick $c3" "squash $c4"
+    run dolt rebase -i master
+    [ "$status" -eq 0 ]
+
+    run dolt log -n 2
+    [[ "$output" =~ "insert 4" ]] || false
+    [[ "$output" =~ "$master" ]] || false
+    [[ "$output" =~ "insert 3 on master" ]] || false
+}
+
+@test "rebase: interactive drop and squash" {
+    c3=$(dolt log -n 2 | grep commit | tail -n 1 | awk '{print $2}')
+    c4=$(dolt log -n 1 | grep commit | awk '{print $2}')
+    dolt sql -q "INSERT INTO test VALUES (6,6)"
+    dolt add .
+    dolt commit -m "insert 6"
+    c6=$(dolt log -n 1 | grep commit | awk '{print $2}')
+
+    write_todo_editor "pick $c3" "squash $c6" "drop $c4"
+    run dolt rebase -i master
+    [ "$status" -eq 0 ]
+
+    run dolt sql -q "SELECT pk FROM test ORDER BY pk" -r csv
+    [[ "$output" =~ "6" ]] || false
+    [[ ! "$output" =~ "4" ]] || false
+
+    run dolt log
+    [[ "$output" =~ "insert 3" ]] || false
+    [[ "$output" =~ "insert 6" ]] || false
+    [[ ! "$output" =~ "insert 4" ]] || false
+    [ "$(dolt log | grep -c '^commit')" -eq 4 ]
+}
+
+@test "rebase: interactive reword" {
+    c3=$(dolt log -n 2 | grep commit | tail -n 1 | awk '{print $2}')
+    c4=$(dolt log -n 1 | grep commit | awk '{print $2}')
+
+    write_todo_editor "pick $c3" "reword $c4"
+    cp "$EDITOR" "$BATS_TMPDIR/todo-only.sh"
+
+    cat > "$BATS_TMPDIR/combined-editor.sh" <<SCRIPT
+#!/bin/bash
+if grep -q "^pick" "\$1"; then
+    "$BATS_TMPDIR/todo-only.sh" "\$1"
+else
+    echo "reworded insert 4" > "\$1"
+fi
+SCRIPT
+    chmod +x "$BATS_TMPDIR/combined-editor.sh"
+    export EDITOR="$BATS_TMPDIR/combined-editor.sh"
+
+    run dolt rebase -i master
+    [ "$status" -eq 0 ]
+
+    run dolt log -n 1
+    [[ "$output" =~ "reworded insert 4" ]] || false
+}
+
+@test "rebase: interactive with an empty todo does nothing" {
+    orig=$(dolt log -n 1 | head -n 1)
+    write_todo_editor "# nothing"
+    run dolt rebase -i master
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Nothing to do" ]] || false
+
+    run dolt log -n 1
+    [[ "$output" =~ "$orig" ]] || false
+}
+
+@test "rebase: invalid todo" {
+    write_todo_editor "frobnicate abc"
+    run dolt rebase -i master
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "unknown action" ]] || false
+}
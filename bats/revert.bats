@@ -0,0 +1,137 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql <<SQL
+CREATE TABLE test (
+  pk BIGINT NOT NULL,
+  c1 BIGINT,
+  PRIMARY KEY (pk)
+);
+INSERT INTO test VALUES (1,1),(2,2);
+SQL
+    dolt add .
+    dolt commit -m "added table"
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "revert: undoes row changes of a commit" {
+    dolt sql -q "INSERT INTO test VALUES (3,3)"
+    dolt sql -q "UPDATE test SET c1 = 10 WHERE pk = 1"
+    dolt add .
+    dolt commit -m "bad data load"
+    HASH=$(dolt log -n 1 | head -n 1 | cut -d ' ' -f 2)
+
+    run dolt revert HEAD
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ 'Revert "bad data load"' ]] || false
+    [[ "$output" =~ "This reverts commit $HASH" ]] || false
+
+    run dolt sql -q "SELECT * FROM test ORDER BY pk" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "1,1" ]] || false
+    [[ "$output" =~ "2,2" ]] || false
+    [[ ! "$output" =~ "3,3" ]] || false
+
+    run dolt log
+    [[ "$output" =~ "bad data load" ]] || false
+
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+}
+
+@test "revert: undoes schema changes of a commit" {
+    dolt sql -q "ALTER TABLE test ADD COLUMN c2 BIGINT"
+    dolt add .
+    dolt commit -m "add column"
+    dolt sql -q "INSERT INTO test VALUES (3,3,3)"
+    dolt add .
+    dolt commit -m "add row"
+
+    run dolt revert HEAD~1
+    [ "$status" -eq 0 ]
+
+    run dolt schema show test
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "c2" ]] || false
+
+    run dolt sql -q "SELECT * FROM test WHERE pk = 3" -r csv
+    [[ "$output" =~ "3,3" ]] || false
+}
+
+@test "revert: multiple commits in a single revert commit" {
+    dolt sql -q "INSERT INTO test VALUES (3,3)"
+    dolt add .
+    dolt commit -m "insert 3"
+    dolt sql -q "INSERT INTO test VALUES (4,4)"
+    dolt add .
+    dolt commit -m "insert 4"
+
+    run dolt revert HEAD HEAD~1
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ 'Revert "insert 4" and "insert 3"' ]] || false
+    [[ "$output" =~ "2 rows deleted" ]] || false
+
+    run dolt sql -q "SELECT COUNT(*) FROM test" -r csv
+    [[ "$output" =~ "2" ]] || false
+}
+
+@test "revert: refuses with uncommitted changes" {
+    dolt sql -q "INSERT INTO test VALUES (3,3)"
+    run dolt revert HEAD
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "local changes would be overwritten by revert" ]] || false
+}
+
+@test "revert: conflicts are written to the conflicts tables" {
+    dolt sql -q "UPDATE test SET c1 = 10 WHERE pk = 1"
+    dolt add .
+    dolt commit -m "update 1"
+    dolt sql -q "UPDATE test SET c1 = 100 WHERE pk = 1"
+    dolt add .
+    dolt commit -m "update 1 again"
+
+    run dolt revert HEAD~1
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "CONFLICT" ]] || false
+
+    run dolt sql -q "SELECT our_c1, their_c1 FROM dolt_conflicts_test" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "100,1" ]] || false
+}
+
+@test "revert: stops before a later commit which conflicts" {
+    dolt sql -q "INSERT INTO test VALUES (3,3)"
+    dolt add .
+    dolt commit -m "insert 3"
+    dolt sql -q "UPDATE test SET c1 = 10 WHERE pk = 1"
+    dolt add .
+    dolt commit -m "update 1"
+    dolt sql -q "UPDATE test SET c1 = 100 WHERE pk = 1"
+    dolt add .
+    dolt commit -m "update 1 again"
+    UPDATE_1=$(dolt log -n 2 | grep commit | tail -n 1 | cut -d ' ' -f 2)
+
+    run dolt revert HEAD~2 HEAD~1
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ 'Revert "insert 3"' ]] || false
+    [[ "$output" =~ "not reverted" ]] || false
+    [[ "$output" =~ "$UPDATE_1" ]] || false
+
+    run dolt sql -q "SELECT * FROM test WHERE pk = 1" -r csv
+    [[ "$output" =~ "1,100" ]] || false
+
+    run dolt status
+    [[ "$output" =~ "working tree clean" ]] || false
+}
+
+@test "revert: initial commit cannot be reverted" {
+    INITIAL=$(dolt log | grep commit | tail -n 1 | cut -d ' ' -f 2)
+    run dolt revert $INITIAL
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "without a parent" ]] || false
+}
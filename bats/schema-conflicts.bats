@@ -0,0 +1,258 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql <<SQL
+CREATE TABLE test (
+  pk BIGINT NOT NULL,
+  c1 BIGINT,
+  c2 BIGINT,
+  PRIMARY KEY (pk)
+);
+INSERT INTO test VALUES (1,1,1),(2,2,2);
+SQL
+    dolt add .
+    dolt commit -m "created table"
+    dolt branch other
+}
+
+teardown() {
+    teardown_common
+}
+
+rename_column_on_both_branches() {
+    dolt sql -q "ALTER TABLE test RENAME COLUMN c2 TO ours"
+    dolt sql -q "INSERT INTO test VALUES (3,3,3)"
+    dolt add .
+    dolt commit -m "renamed c2 to ours"
+    dolt checkout other
+    dolt sql -q "ALTER TABLE test RENAME COLUMN c2 TO theirs"
+    dolt sql -q "INSERT INTO test VALUES (4,4,4)"
+    dolt add .
+    dolt commit -m "renamed c2 to theirs"
+    dolt checkout master
+}
+
+@test "schema-conflicts: merge records column conflicts instead of failing" {
+    rename_column_on_both_branches
+
+    run dolt merge other
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "CONFLICT (schema): Merge conflict in test" ]] || false
+
+    run dolt status
+    [[ "$output" =~ "You have unmerged tables" ]] || false
+
+    run dolt conflicts cat test
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Schema conflicts in table test" ]] || false
+    [[ "$output" =~ "different column definitions for our column ours and their column theirs" ]] || false
+
+    run dolt sql -q "SELECT table_name, description FROM dolt_schema_conflicts" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "test,different column definitions for our column ours and their column theirs" ]] || false
+
+    run dolt sql -q "SELECT num_conflicts FROM dolt_conflicts" -r csv
+    [[ "$output" =~ "1" ]] || false
+
+    run dolt add test
+    [ "$status" -eq 1 ]
+
+    run dolt commit -m "merge"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "unresolved conflicts" ]] || false
+}
+
+@test "schema-conflicts: dolt_schema_conflicts shows each version of the schema" {
+    rename_column_on_both_branches
+    dolt merge other
+
+    run dolt sql -q "SELECT base_schema FROM dolt_schema_conflicts" -r csv
+    [[ "$output" =~ '`c2` BIGINT' ]] || false
+    run dolt sql -q "SELECT our_schema FROM dolt_schema_conflicts" -r csv
+    [[ "$output" =~ '`ours` BIGINT' ]] || false
+    run dolt sql -q "SELECT their_schema FROM dolt_schema_conflicts" -r csv
+    [[ "$output" =~ '`theirs` BIGINT' ]] || false
+}
+
+@test "schema-conflicts: resolve --ours keeps our schema and merges rows" {
+    rename_column_on_both_branches
+    dolt merge other
+
+    run dolt conflicts resolve --ours test
+    [ "$status" -eq 0 ]
+
+    run dolt sql -q "SELECT * FROM test ORDER BY pk" -r csv
+    [[ "${lines[0]}" = "pk,c1,ours" ]] || false
+    [[ "$output" =~ "3,3,3" ]] || false
+    [[ "$output" =~ "4,4,4" ]] || false
+
+    dolt add test
+    dolt commit -m "merged"
+
+    run dolt sql -q "SELECT count(*) FROM dolt_schema_conflicts" -r csv
+    [[ "$output" =~ "0" ]] || false
+}
+
+@test "schema-conflicts: resolve --theirs takes their schema and merges rows" {
+    rename_column_on_both_branches
+    dolt merge other
+
+    run dolt conflicts resolve --theirs test
+    [ "$status" -eq 0 ]
+
+    run dolt sql -q "SELECT * FROM test ORDER BY pk" -r csv
+    [[ "${lines[0]}" = "pk,c1,theirs" ]] || false
+    [[ "$output" =~ "3,3,3" ]] || false
+    [[ "$output" =~ "4,4,4" ]] || false
+
+    dolt add test
+    dolt commit -m "merged"
+}
+
+@test "schema-conflicts: resolve after editing the schema" {
+    rename_column_on_both_branches
+    dolt merge other
+
+    run dolt conflicts resolve test
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "still conflicts" ]] || false
+
+    dolt sql -q "ALTER TABLE test RENAME COLUMN ours TO theirs"
+
+    run dolt conflicts cat test
+    [[ "$output" =~ "no conflicts remain" ]] || false
+
+    run dolt conflicts resolve test
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Schema conflicts resolved" ]] || false
+
+    run dolt sql -q "SELECT * FROM test ORDER BY pk" -r csv
+    [[ "${lines[0]}" = "pk,c1,theirs" ]] || false
+    [[ "$output" =~ "4,4,4" ]] || false
+
+    dolt add test
+    dolt commit -m "merged"
+}
+
+@test "schema-conflicts: columns added with the same name" {
+    dolt sql -q "ALTER TABLE test ADD COLUMN c3 BIGINT"
+    dolt sql -q "UPDATE test SET c3 = 10 WHERE pk = 1"
+    dolt add .
+    dolt commit -m "added c3 as bigint"
+    dolt checkout other
+    dolt sql -q "ALTER TABLE test ADD COLUMN c3 LONGTEXT"
+    dolt sql -q "INSERT INTO test VALUES (5,5,5,'five')"
+    dolt add .
+    dolt commit -m "added c3 as text"
+    dolt checkout master
+
+    run dolt merge other
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "CONFLICT (schema)" ]] || false
+
+    run dolt conflicts cat test
+    [[ "$output" =~ "two columns with the name 'c3'" ]] || false
+
+    dolt conflicts resolve --ours test
+
+    run dolt sql -q "SELECT pk, c3 FROM test ORDER BY pk" -r csv
+    [[ "$output" =~ "1,10" ]] || false
+    [[ "$output" =~ "5," ]] || false
+}
+
+@test "schema-conflicts: index conflicts" {
+    dolt sql -q "CREATE INDEX idx ON test (c1)"
+    dolt add .
+    dolt commit -m "added idx on c1"
+    dolt checkout other
+    dolt sql -q "CREATE INDEX idx ON test (c2)"
+    dolt add .
+    dolt commit -m "added idx on c2"
+    dolt checkout master
+
+    run dolt merge other
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "CONFLICT (schema)" ]] || false
+
+    run dolt sql -q "SELECT description FROM dolt_schema_conflicts" -r csv
+    [[ "$output" =~ "two indexes with the name 'idx'" ]] || false
+
+    dolt conflicts resolve --theirs test
+
+    run dolt schema show test
+    [[ "$output" =~ 'KEY `idx` (`c2`)' ]] || false
+}
+
+@test "schema-conflicts: row conflicts are found once the schema is resolved" {
+    dolt sql -q "ALTER TABLE test RENAME COLUMN c2 TO ours"
+    dolt sql -q "UPDATE test SET c1 = 10 WHERE pk = 1"
+    dolt add .
+    dolt commit -m "ours"
+    dolt checkout other
+    dolt sql -q "ALTER TABLE test RENAME COLUMN c2 TO theirs"
+    dolt sql -q "UPDATE test SET c1 = 20 WHERE pk = 1"
+    dolt add .
+    dolt commit -m "theirs"
+    dolt checkout master
+    dolt merge other
+
+    dolt sql -q "ALTER TABLE test RENAME COLUMN ours TO theirs"
+    run dolt conflicts resolve test
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "1 conflicting rows" ]] || false
+
+    run dolt sql -q "SELECT our_c1, their_c1 FROM dolt_conflicts_test" -r csv
+    [[ "$output" =~ "10,20" ]] || false
+
+    dolt conflicts resolve --theirs test
+    dolt add test
+    dolt commit -m "merged"
+
+    run dolt sql -q "SELECT c1 FROM test WHERE pk = 1" -r csv
+    [[ "$output" =~ "20" ]] || false
+}
+
+@test "schema-conflicts: foreign key conflicts" {
+    dolt sql <<SQL
+CREATE TABLE parent (
+  id BIGINT NOT NULL,
+  v1 BIGINT,
+  PRIMARY KEY (id),
+  INDEX v1 (v1)
+);
+CREATE TABLE child (
+  id BIGINT NOT NULL,
+  p BIGINT,
+  PRIMARY KEY (id),
+  INDEX p (p)
+);
+SQL
+    dolt add .
+    dolt commit -m "added parent and child"
+    dolt branch fk-other
+    dolt sql -q "ALTER TABLE child ADD CONSTRAINT fk1 FOREIGN KEY (p) REFERENCES parent (id)"
+    dolt add .
+    dolt commit -m "fk to id"
+    dolt checkout fk-other
+    dolt sql -q "ALTER TABLE child ADD CONSTRAINT fk1 FOREIGN KEY (p) REFERENCES parent (v1)"
+    dolt add .
+    dolt commit -m "fk to v1"
+    dolt checkout master
+
+    run dolt merge fk-other
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "CONFLICT (schema): Merge conflict in child" ]] || false
+
+    run dolt conflicts cat child
+    [[ "$output" =~ "two foreign keys with the name 'fk1'" ]] || false
+
+    dolt conflicts resolve --theirs child
+
+    run dolt schema show child
+    [[ "$output" =~ 'REFERENCES `parent` (`v1`)' ]] || false
+
+    dolt add .
+    dolt commit -m "merged"
+}
@@ -438,4 +438,118 @@ SQL
 
     server_query 1 "SELECT * FROM repo1.r1_one_pk" "pk,c1,c2\n1,1,1\n2,2,2\n3,3,3"
     server_query 1 "SELECT * FROM repo2.r2_one_pk" "pk,c3,c4\n1,1,1\n2,2,2\n3,3,3"
-}
\ No newline at end of file
+}
+
+@test "test transactions via dolt sql-server" {
+    skiponwindows "Has dependencies that are missing on the Jenkins Windows installation."
+
+    cd repo1
+    dolt sql -q "CREATE TABLE test (pk int PRIMARY KEY, c0 int)"
+    dolt sql -q "INSERT INTO test VALUES (1,1)"
+    dolt add -A && dolt commit -m "added table test"
+
+    start_sql_server repo1
+
+    # rolled back changes never reach the working set
+    multi_query 1 "
+    START TRANSACTION;
+    INSERT INTO test VALUES (2,2);
+    ROLLBACK"
+
+    server_query 1 "SELECT COUNT(*) AS c FROM test" "c\n1"
+
+    # committed changes do
+    multi_query 1 "
+    BEGIN;
+    INSERT INTO test VALUES (3,3);
+    UPDATE test SET c0 = 10 WHERE pk = 1;
+    COMMIT"
+
+    server_query 1 "SELECT pk, c0 FROM test ORDER BY pk" "pk,c0\n1,10\n3,3"
+    run dolt sql -q "SELECT COUNT(*) FROM test" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2" ]] || false
+}
+
+@test "test branch and commit revision databases via dolt sql-server" {
+    skiponwindows "Has dependencies that are missing on the Jenkins Windows installation."
+
+    cd repo1
+    dolt sql -q "CREATE TABLE test (pk int PRIMARY KEY)"
+    dolt sql -q "INSERT INTO test VALUES (1)"
+    dolt add -A && dolt commit -m "added table test"
+    dolt branch feature-x
+    COMMIT_HASH=$(dolt log | grep -m 1 commit | awk '{print $2}')
+
+    start_sql_server repo1
+
+    # writes to a branch database do not touch the checked out branch
+    multi_query 1 "
+    USE repo1/feature-x;
+    INSERT INTO test VALUES (2);
+    SELECT DOLT_COMMIT('-a', '-m', 'added row on feature-x')"
+
+    server_query 1 "SELECT COUNT(*) AS c FROM test" "c\n1"
+    server_query 1 "SELECT COUNT(*) AS c FROM \`repo1/feature-x\`.test" "c\n2"
+
+    run dolt log feature-x
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "added row on feature-x" ]] || false
+
+    # commit databases are read only
+    server_query 1 "USE \`repo1/$COMMIT_HASH\`;SELECT COUNT(*) AS c FROM test" ";c\n1"
+    run server_query 1 "USE \`repo1/$COMMIT_HASH\`;INSERT INTO test VALUES (3)" ";"
+    [ "$status" -eq 1 ]
+}
+
+@test "test users and grants via dolt sql-server" {
+    skiponwindows "Has dependencies that are missing on the Jenkins Windows installation."
+
+    cd repo1
+    start_sql_server_with_privileges repo1
+
+    server_query 1 "CREATE TABLE one_pk (pk BIGINT NOT NULL, c1 BIGINT, PRIMARY KEY (pk))" ""
+    server_query 1 "INSERT INTO one_pk (pk,c1) VALUES (1,1)" ""
+    server_query 1 "CREATE USER 'analyst'@'%' IDENTIFIED BY 'pass'" ""
+    server_query 1 "GRANT SELECT ON repo1.one_pk TO 'analyst'@'%'" ""
+
+    SQL_USER=analyst SQL_PASSWORD=pass server_query 1 "SELECT * FROM one_pk" "pk,c1\n1,1"
+    SQL_USER=analyst SQL_PASSWORD=pass server_query 1 "SHOW GRANTS" "Grants for analyst@%\nGRANT USAGE ON *.* TO 'analyst'@'%'\nGRANT SELECT ON \`repo1\`.\`one_pk\` TO 'analyst'@'%'"
+
+    SQL_USER=analyst SQL_PASSWORD=pass run server_query 1 "INSERT INTO one_pk (pk,c1) VALUES (2,2)" ""
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "access denied" ]] || false
+
+    run dolt sql -q "SELECT COUNT(*) FROM one_pk" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "1" ]] || false
+
+    [ -f privileges.json ]
+    run cat privileges.json
+    [[ "$output" =~ "analyst" ]] || false
+    [[ ! "$output" =~ '"pass"' ]] || false
+}
+
+@test "sql-server rejects incomplete tls configuration" {
+    cd repo1
+    let PORT="$$ % (65536-1024) + 1024"
+    cat > .tlsconfig.yaml <<YAML
+listener:
+  host: 0.0.0.0
+  port: $PORT
+  require_secure_transport: true
+YAML
+    run dolt sql-server --config .tlsconfig.yaml
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "require_secure_transport" ]] || false
+
+    cat > .tlsconfig.yaml <<YAML
+listener:
+  host: 0.0.0.0
+  port: $PORT
+  tls_cert: cert.pem
+YAML
+    run dolt sql-server --config .tlsconfig.yaml
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "tls_key must be supplied" ]] || false
+}
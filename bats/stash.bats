@@ -0,0 +1,155 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql <<SQL
+CREATE TABLE test (
+  pk BIGINT NOT NULL,
+  c1 BIGINT,
+  PRIMARY KEY (pk)
+);
+INSERT INTO test VALUES (1,1),(2,2);
+SQL
+    dolt add .
+    dolt commit -m "added table"
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "stash: push saves changes and resets the working set" {
+    dolt sql -q "INSERT INTO test VALUES (3,3)"
+    run dolt stash push
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Saved working directory and index state WIP on master" ]] || false
+
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+
+    run dolt stash list
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "stash@{0}: WIP on master" ]] || false
+    [[ "$output" =~ "added table" ]] || false
+}
+
+@test "stash: push with no changes" {
+    run dolt stash push
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "No local changes to save" ]] || false
+
+    run dolt stash list
+    [ "$output" = "" ]
+}
+
+@test "stash: pop restores working and staged changes" {
+    dolt sql -q "INSERT INTO test VALUES (3,3)"
+    dolt add test
+    dolt sql -q "UPDATE test SET c1 = 10 WHERE pk = 1"
+    dolt stash push
+
+    run dolt stash pop
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Dropped stash@{0}" ]] || false
+
+    run dolt sql -q "SELECT * FROM test ORDER BY pk" -r csv
+    [[ "$output" =~ "1,10" ]] || false
+    [[ "$output" =~ "3,3" ]] || false
+
+    run dolt diff --cached
+    [[ "$output" =~ "3 " ]] || false
+    [[ ! "$output" =~ "10" ]] || false
+
+    run dolt stash list
+    [ "$output" = "" ]
+}
+
+@test "stash: untracked tables are left in the working set" {
+    dolt sql -q "CREATE TABLE untracked (pk BIGINT PRIMARY KEY)"
+    dolt sql -q "INSERT INTO test VALUES (3,3)"
+    dolt stash push
+
+    run dolt ls
+    [[ "$output" =~ "untracked" ]] || false
+
+    run dolt sql -q "SELECT COUNT(*) FROM test" -r csv
+    [[ "$output" =~ "2" ]] || false
+}
+
+@test "stash: pop onto a different commit" {
+    dolt sql -q "INSERT INTO test VALUES (3,3)"
+    dolt stash push
+    dolt sql -q "INSERT INTO test VALUES (4,4)"
+    dolt add .
+    dolt commit -m "insert 4"
+
+    run dolt stash pop
+    [ "$status" -eq 0 ]
+
+    run dolt sql -q "SELECT * FROM test ORDER BY pk" -r csv
+    [[ "$output" =~ "3,3" ]] || false
+    [[ "$output" =~ "4,4" ]] || false
+}
+
+@test "stash: pop with conflicts keeps the stash" {
+    dolt sql -q "UPDATE test SET c1 = 10 WHERE pk = 1"
+    dolt stash push
+    dolt sql -q "UPDATE test SET c1 = 20 WHERE pk = 1"
+    dolt add .
+    dolt commit -m "conflicting update"
+
+    run dolt stash pop
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "CONFLICT" ]] || false
+
+    run dolt stash list
+    [[ "$output" =~ "stash@{0}" ]] || false
+}
+
+@test "stash: pop with staged conflicts keeps the stash and the working set" {
+    dolt sql -q "UPDATE test SET c1 = 10 WHERE pk = 1"
+    dolt add .
+    dolt stash push
+    dolt sql -q "UPDATE test SET c1 = 20 WHERE pk = 1"
+    dolt add .
+    dolt sql -q "UPDATE test SET c1 = 10 WHERE pk = 1"
+
+    run dolt stash pop
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "staged changes of the stash conflict" ]] || false
+
+    run dolt stash list
+    [[ "$output" =~ "stash@{0}" ]] || false
+
+    run dolt diff --cached
+    [[ "$output" =~ "20" ]] || false
+}
+
+@test "stash: list, pop and drop by index" {
+    dolt sql -q "INSERT INTO test VALUES (3,3)"
+    dolt stash push
+    dolt sql -q "INSERT INTO test VALUES (4,4)"
+    dolt stash push
+
+    run dolt stash list
+    [ "${#lines[@]}" -eq 2 ]
+
+    run dolt stash pop stash@{1}
+    [ "$status" -eq 0 ]
+
+    run dolt sql -q "SELECT * FROM test ORDER BY pk" -r csv
+    [[ "$output" =~ "3,3" ]] || false
+    [[ ! "$output" =~ "4,4" ]] || false
+
+    run dolt stash drop 0
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Dropped stash@{0}" ]] || false
+
+    run dolt stash list
+    [ "$output" = "" ]
+
+    run dolt stash drop
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "stash@{0} is not a valid stash" ]] || false
+}
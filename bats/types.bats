@@ -1246,4 +1246,28 @@ SQL
     [ "$status" -eq "1" ]
     run dolt sql -q "INSERT INTO test VALUES (2, '2156');"
     [ "$status" -eq "1" ]
+}
+
+@test "types: JSON" {
+    dolt sql <<SQL
+CREATE TABLE test (
+  pk BIGINT NOT NULL,
+  v JSON,
+  PRIMARY KEY (pk)
+);
+SQL
+    run dolt schema show
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ "\`v\` json" ]] || false
+    dolt sql -q "INSERT INTO test VALUES (1, '{\"b\": [1, 2],  \"a\": \"x\"}');"
+    run dolt sql -q "SELECT * FROM test" -r csv
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ '{""a"":""x"",""b"":[1,2]}' ]] || false
+    run dolt sql -q "SELECT JSON_EXTRACT(v, '$.a') FROM test" -r csv
+    [ "$status" -eq "0" ]
+    [[ "${lines[1]}" =~ "x" ]] || false
+    dolt sql -q "UPDATE test SET v='[1, 2, 3]' WHERE pk=1;"
+    run dolt sql -q "SELECT * FROM test" -r json
+    [ "$status" -eq "0" ]
+    [[ "$output" =~ '"v":[1,2,3]' ]] || false
 }
\ No newline at end of file
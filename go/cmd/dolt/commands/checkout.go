@@ -99,6 +99,10 @@ func (cmd CheckoutCmd) Exec(ctx context.Context, commandStr string, args []strin
 
 	if newBranch, newBranchOk := apr.GetValue(coBranchArg); newBranchOk {
 		var verr errhand.VerboseError
+		if verr = checkNoActiveRebase(dEnv, "checkout a branch"); verr != nil {
+			return HandleVErrAndExitCode(verr, usagePrt)
+		}
+
 		if len(newBranch) == 0 {
 			verr = errhand.BuildDError("error: cannot checkout empty string").Build()
 		} else {
@@ -118,6 +122,10 @@ func (cmd CheckoutCmd) Exec(ctx context.Context, commandStr string, args []strin
 		verr := errhand.BuildDError("error: unable to determine type of checkout").AddCause(err).Build()
 		return HandleVErrAndExitCode(verr, usagePrt)
 	} else if isBranch {
+		if verr := checkNoActiveRebase(dEnv, "checkout a branch"); verr != nil {
+			return HandleVErrAndExitCode(verr, usagePrt)
+		}
+
 		verr := checkoutBranch(ctx, dEnv, name)
 		return HandleVErrAndExitCode(verr, usagePrt)
 	}
@@ -142,6 +150,10 @@ func checkoutRemoteBranch(ctx context.Context, dEnv *env.DoltEnv, name string) e
 	if ref, refExists, err := getRemoteBranchRef(ctx, dEnv, name); err != nil {
 		return errhand.BuildDError("fatal: unable to read from data repository.").AddCause(err).Build()
 	} else if refExists {
+		if verr := checkNoActiveRebase(dEnv, "checkout a branch"); verr != nil {
+			return verr
+		}
+
 		return checkoutNewBranchFromStartPt(ctx, dEnv, name, ref.String())
 	} else {
 		return errhand.BuildDError("error: could not find %s", name).Build()
@@ -0,0 +1,231 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+const noCommitFlag = "no-commit"
+
+var cherryPickDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Apply the changes introduced by an existing commit",
+	LongDesc: `Applies the changes introduced by the named commit to the current branch and records a new commit for them. The working set must be clean before running {{.EmphasisLeft}}dolt cherry-pick{{.EmphasisRight}}.
+
+The changes are computed as the difference between {{.LessThan}}commit{{.GreaterThan}} and its parent, and are applied using a three-way merge with the parent as the common ancestor. Merge commits cannot be cherry-picked.
+
+If applying the changes results in conflicts, the conflicted tables are left in the working set and can be inspected and resolved with {{.EmphasisLeft}}dolt conflicts{{.EmphasisRight}}. Once resolved, use {{.EmphasisLeft}}dolt add{{.EmphasisRight}} and {{.EmphasisLeft}}dolt commit{{.EmphasisRight}} to record the result.
+`,
+	Synopsis: []string{
+		"[--no-commit] {{.LessThan}}commit{{.GreaterThan}}",
+	},
+}
+
+type CherryPickCmd struct{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd CherryPickCmd) Name() string {
+	return "cherry-pick"
+}
+
+// Description returns a description of the command
+func (cmd CherryPickCmd) Description() string {
+	return "Apply the changes introduced by an existing commit."
+}
+
+// CreateMarkdown creates a markdown file containing the helptext for the command at the given path
+func (cmd CherryPickCmd) CreateMarkdown(fs filesys.Filesys, path, commandStr string) error {
+	ap := cmd.createArgParser()
+	return CreateMarkdown(fs, path, cli.GetCommandDocumentation(commandStr, cherryPickDocs, ap))
+}
+
+func (cmd CherryPickCmd) createArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"commit", "The commit whose changes should be applied to the current branch."})
+	ap.SupportsFlag(noCommitFlag, "n", "Apply and stage the changes without creating a commit.")
+	return ap
+}
+
+// Exec executes the command
+func (cmd CherryPickCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := cmd.createArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, cherryPickDocs, ap))
+	apr := cli.ParseArgs(ap, args, help)
+
+	if apr.NArg() != 1 {
+		usage()
+		return 1
+	}
+
+	verr := checkCleanWorkingSet(ctx, dEnv, "cherry-pick")
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	cm, verr := ResolveCommitWithVErr(dEnv, apr.Arg(0))
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	h, err := cm.HashOf()
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to get hash of commit").AddCause(err).Build(), usage)
+	}
+
+	meta, err := cm.GetCommitMeta()
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to read commit metadata").AddCause(err).Build(), usage)
+	}
+
+	headRoot, err := dEnv.HeadRoot(ctx)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to get the head root").AddCause(err).Build(), usage)
+	}
+
+	mergedRoot, tblToStats, err := merge.CherryPick(ctx, dEnv.DoltDB, headRoot, cm)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: could not apply %s", h.String()).AddCause(err).Build(), usage)
+	}
+
+	applied, verr := applyMergedRoot(ctx, dEnv, headRoot, mergedRoot, tblToStats)
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	if !applied {
+		cli.Println("error: could not apply", h.String())
+		cli.Println("hint: after resolving the conflicts, mark the corrected tables")
+		cli.Println("hint: with 'dolt add <table>' and commit the result with 'dolt commit'")
+		return 1
+	}
+
+	if apr.Contains(noCommitFlag) {
+		return 0
+	}
+
+	_, err = actions.CommitStaged(ctx, dEnv.DbData(), actions.CommitStagedProps{
+		Message:          meta.Description,
+		Date:             doltdb.CommitNowFunc(),
+		AllowEmpty:       false,
+		CheckForeignKeys: true,
+		Name:             meta.Name,
+		Email:            meta.Email,
+	})
+
+	if err != nil {
+		return handleCommitErr(ctx, dEnv, err, usage)
+	}
+
+	return LogCmd{}.Exec(ctx, "log", []string{"-n=1"}, dEnv)
+}
+
+// checkCleanWorkingSet returns an error if the working set has uncommitted changes or conflicts, or if a merge or
+// rebase is in progress. |op| is the name of the operation being attempted, used in the error messages.
+func checkCleanWorkingSet(ctx context.Context, dEnv *env.DoltEnv, op string) errhand.VerboseError {
+	if dEnv.IsMergeActive() {
+		return errhand.BuildDError("error: cannot %s while a merge is in progress.", op).
+			AddDetails("hint: commit or abort the active merge before trying again.").Build()
+	}
+
+	if verr := checkNoActiveRebase(dEnv, op); verr != nil {
+		return verr
+	}
+
+	working, verr := GetWorkingWithVErr(dEnv)
+	if verr != nil {
+		return verr
+	}
+
+	if has, err := working.HasConflicts(ctx); err != nil {
+		return errhand.BuildDError("error: failed to get conflicts").AddCause(err).Build()
+	} else if has {
+		return errhand.BuildDError("error: cannot %s because you have unmerged tables.", op).
+			AddDetails("hint: fix them up in the working set, and then use 'dolt add <table>'").
+			AddDetails("hint: as appropriate to mark resolution and make a commit.").Build()
+	}
+
+	staged, notStaged, err := diff.GetStagedUnstagedTableDeltas(ctx, dEnv.DoltDB, dEnv.RepoStateReader())
+	if err != nil {
+		return errhand.BuildDError("error: failed to determine the state of the working set").AddCause(err).Build()
+	}
+
+	if len(staged) != 0 || len(notStaged) != 0 {
+		return errhand.BuildDError("error: your local changes would be overwritten by %s.", op).
+			AddDetails("hint: commit your changes or stash them before trying again.").Build()
+	}
+
+	return nil
+}
+
+// applyMergedRoot writes the result of a merge that was applied on top of |headRoot| to the working set. If the merge
+// produced no conflicts the result is also staged. Returns false if there were conflicts that need to be resolved.
+func applyMergedRoot(ctx context.Context, dEnv *env.DoltEnv, headRoot, mergedRoot *doltdb.RootValue, tblToStats map[string]*merge.MergeStats) (bool, errhand.VerboseError) {
+	headHash, err := headRoot.HashOf()
+	if err != nil {
+		return false, errhand.BuildDError("error: failed to hash root").AddCause(err).Build()
+	}
+
+	mergedHash, err := mergedRoot.HashOf()
+	if err != nil {
+		return false, errhand.BuildDError("error: failed to hash root").AddCause(err).Build()
+	}
+
+	if headHash == mergedHash {
+		return false, errhand.BuildDError("error: no changes to apply, the changes are already present on the current branch").Build()
+	}
+
+	verr := UpdateWorkingWithVErr(dEnv, mergedRoot)
+	if verr != nil {
+		return false, verr
+	}
+
+	hasConflicts := printSuccessStats(tblToStats)
+	if hasConflicts {
+		return false, nil
+	}
+
+	err = actions.SaveTrackedDocsFromWorking(ctx, dEnv)
+	if err != nil {
+		return false, errhand.BuildDError("error: failed to update docs to the new working root").AddCause(err).Build()
+	}
+
+	verr = UpdateStagedWithVErr(dEnv.DoltDB, dEnv.RepoStateWriter(), mergedRoot)
+	if verr != nil {
+		return false, verr
+	}
+
+	return true, nil
+}
+
+// checkNoActiveRebase returns an error if a rebase is in progress. |op| is the name of the operation being attempted,
+// used in the error message.
+func checkNoActiveRebase(dEnv *env.DoltEnv, op string) errhand.VerboseError {
+	if dEnv.IsRebaseActive() {
+		return errhand.BuildDError("error: cannot %s while a rebase is in progress.", op).
+			AddDetails("hint: use 'dolt rebase --continue' or 'dolt rebase --abort' before trying again.").Build()
+	}
+
+	return nil
+}
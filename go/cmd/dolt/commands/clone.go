@@ -38,12 +38,15 @@ import (
 	"github.com/dolthub/dolt/go/libraries/utils/filesys"
 	"github.com/dolthub/dolt/go/libraries/utils/strhelp"
 	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/nbs"
 	"github.com/dolthub/dolt/go/store/types"
 )
 
 const (
 	remoteParam = "remote"
 	branchParam = "branch"
+	depthParam  = "depth"
+	lazyParam   = "lazy"
 )
 
 var cloneDocs = cli.CommandDocumentationContent{
@@ -53,9 +56,15 @@ var cloneDocs = cli.CommandDocumentationContent{
 After the clone, a plain {{.EmphasisLeft}}dolt fetch{{.EmphasisRight}} without arguments will update all the remote-tracking branches, and a {{.EmphasisLeft}}dolt pull{{.EmphasisRight}} without arguments will in addition merge the remote branch into the current branch.
 
 This default configuration is achieved by creating references to the remote branch heads under {{.LessThan}}refs/remotes/origin{{.GreaterThan}}  and by creating a remote named 'origin'.
+
+With {{.EmphasisLeft}}--depth{{.EmphasisRight}} the clone is shallow: only the data of the given number of most recent commits of each branch is copied, rather than every table file of the remote. If {{.EmphasisLeft}}--branch{{.EmphasisRight}} is given only that branch is cloned. Reading the history which was not copied fails, unless {{.EmphasisLeft}}--lazy{{.EmphasisRight}} is also given.
+
+With {{.EmphasisLeft}}--lazy{{.EmphasisRight}} the data which was not copied is fetched from the remote when it is read, and kept in the clone. Without {{.EmphasisLeft}}--depth{{.EmphasisRight}}, only the data of the most recent commit of each branch is copied up front.
+
+The table files of cloud remotes which were pushed with an encryption key are read with {{.EmphasisLeft}}--encryption-key{{.EmphasisRight}} or {{.EmphasisLeft}}--encryption-creds{{.EmphasisRight}}, which must match the passphrase or credentials they were encrypted with. See {{.EmphasisLeft}}dolt remote{{.EmphasisRight}}.
 `,
 	Synopsis: []string{
-		"[-remote {{.LessThan}}remote{{.GreaterThan}}] [-branch {{.LessThan}}branch{{.GreaterThan}}]  [--aws-region {{.LessThan}}region{{.GreaterThan}}] [--aws-creds-type {{.LessThan}}creds-type{{.GreaterThan}}] [--aws-creds-file {{.LessThan}}file{{.GreaterThan}}] [--aws-creds-profile {{.LessThan}}profile{{.GreaterThan}}] {{.LessThan}}remote-url{{.GreaterThan}} {{.LessThan}}new-dir{{.GreaterThan}}",
+		"[-remote {{.LessThan}}remote{{.GreaterThan}}] [-branch {{.LessThan}}branch{{.GreaterThan}}] [--depth {{.LessThan}}depth{{.GreaterThan}}] [--lazy] [--aws-region {{.LessThan}}region{{.GreaterThan}}] [--aws-creds-type {{.LessThan}}creds-type{{.GreaterThan}}] [--aws-creds-file {{.LessThan}}file{{.GreaterThan}}] [--aws-creds-profile {{.LessThan}}profile{{.GreaterThan}}] [--s3-endpoint {{.LessThan}}url{{.GreaterThan}}] [--azure-endpoint {{.LessThan}}url{{.GreaterThan}}] [--encryption-key {{.LessThan}}passphrase{{.GreaterThan}} | --encryption-creds {{.LessThan}}public-key-or-key-id{{.GreaterThan}}] {{.LessThan}}remote-url{{.GreaterThan}} {{.LessThan}}new-dir{{.GreaterThan}}",
 	},
 }
 
@@ -87,10 +96,16 @@ func (cmd CloneCmd) createArgParser() *argparser.ArgParser {
 	ap := argparser.NewArgParser()
 	ap.SupportsString(remoteParam, "", "name", "Name of the remote to be added. Default will be 'origin'.")
 	ap.SupportsString(branchParam, "b", "branch", "The branch to be cloned.  If not specified all branches will be cloned.")
+	ap.SupportsInt(depthParam, "", "depth", "Copy only the data of the given number of most recent commits of the cloned branches.")
+	ap.SupportsFlag(lazyParam, "", "Fetch the data which was not copied by the clone from the remote when it is read.")
 	ap.SupportsString(dbfactory.AWSRegionParam, "", "region", "")
 	ap.SupportsValidatedString(dbfactory.AWSCredsTypeParam, "", "creds-type", "", argparser.ValidatorFromStrList(dbfactory.AWSCredsTypeParam, credTypes))
 	ap.SupportsString(dbfactory.AWSCredsFileParam, "", "file", "AWS credentials file.")
 	ap.SupportsString(dbfactory.AWSCredsProfile, "", "profile", "AWS profile to use.")
+	ap.SupportsString(dbfactory.S3EndpointParam, "", "url", "Url of the S3 compatible object store of an s3 remote.")
+	ap.SupportsString(dbfactory.AzureEndpointParam, "", "url", "Url of the Blob service of an az remote.")
+	ap.SupportsString(dbfactory.EncryptionKeyParam, "", "passphrase", "Passphrase the table files of the remote are encrypted with.")
+	ap.SupportsString(dbfactory.EncryptionCredsParam, "", "public-key-or-key-id", "Dolt credentials the table files of the remote are encrypted with.")
 	return ap
 }
 
@@ -107,6 +122,8 @@ func (cmd CloneCmd) Exec(ctx context.Context, commandStr string, args []string,
 
 	remoteName := apr.GetValueOrDefault(remoteParam, "origin")
 	branch := apr.GetValueOrDefault(branchParam, "")
+	depth := apr.GetIntOrDefault(depthParam, 0)
+	lazy := apr.Contains(lazyParam)
 	dir, urlStr, verr := parseArgs(apr)
 
 	scheme, remoteUrl, err := getAbsRemoteUrl(dEnv.FS, dEnv.Config, urlStr)
@@ -115,6 +132,10 @@ func (cmd CloneCmd) Exec(ctx context.Context, commandStr string, args []string,
 		verr = errhand.BuildDError("error: '%s' is not valid.", urlStr).Build()
 	}
 
+	if verr == nil && apr.Contains(depthParam) && depth < 1 {
+		verr = errhand.BuildDError("error: --%s must be a positive number of commits", depthParam).Build()
+	}
+
 	if verr == nil {
 		var params map[string]string
 		params, verr = parseRemoteArgs(apr, scheme, remoteUrl)
@@ -128,7 +149,7 @@ func (cmd CloneCmd) Exec(ctx context.Context, commandStr string, args []string,
 				dEnv, verr = envForClone(ctx, srcDB.ValueReadWriter().Format(), r, dir, dEnv.FS, dEnv.Version)
 
 				if verr == nil {
-					verr = cloneRemote(ctx, srcDB, remoteName, branch, dEnv)
+					verr = cloneRemote(ctx, srcDB, remoteName, branch, depth, lazy, dEnv)
 
 					if verr == nil {
 						evt := events.GetEventFromContext(ctx)
@@ -232,6 +253,8 @@ func createRemote(ctx context.Context, remoteName, remoteUrl string, params map[
 		if err == remotestorage.ErrInvalidDoltSpecPath {
 			urlObj, _ := earl.Parse(remoteUrl)
 			bdr.AddDetails("'%s' should be in the format 'organization/repo'", urlObj.Path)
+		} else if errors.Is(err, nbs.ErrWrongEncryptionKey) || errors.Is(err, nbs.ErrTableFileEncrypted) {
+			bdr.AddDetails("the remote must be cloned with the --%s or --%s it was pushed with", dbfactory.EncryptionKeyParam, dbfactory.EncryptionCredsParam)
 		}
 
 		return env.NoRemote, nil, bdr.Build()
@@ -275,20 +298,25 @@ func cloneProg(eventCh <-chan datas.TableFileEvent) {
 	cli.Println()
 }
 
-func cloneRemote(ctx context.Context, srcDB *doltdb.DoltDB, remoteName, branch string, dEnv *env.DoltEnv) errhand.VerboseError {
-	eventCh := make(chan datas.TableFileEvent, 128)
+func cloneRemote(ctx context.Context, srcDB *doltdb.DoltDB, remoteName, branch string, depth int, lazy bool, dEnv *env.DoltEnv) errhand.VerboseError {
+	var err error
+	if depth > 0 || lazy {
+		err = shallowCloneRemote(ctx, srcDB, remoteName, branch, depth, lazy, dEnv)
+	} else {
+		eventCh := make(chan datas.TableFileEvent, 128)
 
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		cloneProg(eventCh)
-	}()
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cloneProg(eventCh)
+		}()
 
-	err := actions.Clone(ctx, srcDB, dEnv.DoltDB, eventCh)
-	close(eventCh)
+		err = actions.Clone(ctx, srcDB, dEnv.DoltDB, eventCh)
+		close(eventCh)
 
-	wg.Wait()
+		wg.Wait()
+	}
 
 	if err != nil {
 		if err == datas.ErrNoData {
@@ -392,6 +420,25 @@ func cloneRemote(ctx context.Context, srcDB *doltdb.DoltDB, remoteName, branch s
 	return nil
 }
 
+// shallowCloneRemote copies the data of the |depth| most recent commits of |branch|, or of every branch if |branch| is
+// empty, from srcDB. Lazy clones without a depth copy only the most recent commits.
+func shallowCloneRemote(ctx context.Context, srcDB *doltdb.DoltDB, remoteName, branch string, depth int, lazy bool, dEnv *env.DoltEnv) error {
+	var branches []string
+	if branch != "" {
+		branches = []string{branch}
+	}
+
+	if depth <= 0 {
+		depth = 1
+	}
+
+	wg, progChan, pullerEventCh := runProgFuncs()
+	err := actions.ShallowClone(ctx, srcDB, dEnv, remoteName, branches, depth, lazy, progChan)
+	stopProgFuncs(wg, progChan, pullerEventCh)
+
+	return err
+}
+
 // Inits an empty, newly cloned repo. This would be unnecessary if we properly initialized the storage for a repository
 // when we created it on dolthub. If we do that, this code can be removed.
 func initEmptyClonedRepo(ctx context.Context, dEnv *env.DoltEnv) error {
@@ -155,6 +155,10 @@ func printConflicts(ctx context.Context, root *doltdb.RootValue, tblNames []stri
 				return errhand.BuildDError("error: unable to read database").AddCause(err).Build()
 			}
 
+			if verr := printSchemaConflicts(ctx, tblName, tbl); verr != nil {
+				return verr
+			}
+
 			cnfRd, err := merge.NewConflictReader(ctx, tbl)
 
 			if err == doltdb.ErrNoConflicts {
@@ -220,3 +224,24 @@ func printConflicts(ctx context.Context, root *doltdb.RootValue, tblNames []stri
 
 	return nil
 }
+
+func printSchemaConflicts(ctx context.Context, tblName string, tbl *doltdb.Table) errhand.VerboseError {
+	sc, err := merge.GetSchemaConflicts(ctx, tblName, tbl)
+
+	if err == doltdb.ErrNoConflicts {
+		return nil
+	} else if err != nil {
+		return errhand.BuildDError("failed to read schema conflicts").AddCause(err).Build()
+	}
+
+	cli.Println("Schema conflicts in table", tblName+":")
+	for _, desc := range sc.Descriptions() {
+		cli.Println("\t" + desc)
+	}
+
+	if sc.Count() == 0 {
+		cli.Println("\tno conflicts remain, use 'dolt conflicts resolve " + tblName + "' to finish merging the table")
+	}
+
+	return nil
+}
@@ -29,6 +29,8 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
 	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
 	"github.com/dolthub/dolt/go/libraries/utils/argparser"
 )
 
@@ -39,11 +41,14 @@ When a merge operation finds conflicting changes, the rows with the conflicts ar
 		
 In its first form {{.EmphasisLeft}}dolt conflicts resolve <table> <key>...{{.EmphasisRight}}, resolve runs in manual merge mode resolving the conflicts whose keys are provided.
 
-In its second form {{.EmphasisLeft}}dolt conflicts resolve --ours|--theirs <table>...{{.EmphasisRight}}, resolve runs in auto resolve mode. Where conflicts are resolved using a rule to determine which version of a row should be used.
+In its second form {{.EmphasisLeft}}dolt conflicts resolve --ours|--theirs <table>...{{.EmphasisRight}}, resolve runs in auto resolve mode. Where conflicts are resolved using a rule to determine which version of a row should be used. Schema conflicts are resolved using the same rule to pick a version of each conflicting column, index and foreign key, after which the rows of the table are merged and any conflicting rows are resolved.
+
+In its third form {{.EmphasisLeft}}dolt conflicts resolve <table>{{.EmphasisRight}}, resolve marks the schema conflicts of a table as resolved after the schema has been altered so that it no longer conflicts, and finishes merging the rows of the table. Conflicting foreign keys are left as they are in the working set.
 `,
 	Synopsis: []string{
 		`{{.LessThan}}table{{.GreaterThan}} [{{.LessThan}}key_definition{{.GreaterThan}}] {{.LessThan}}key{{.GreaterThan}}...`,
 		`--ours|--theirs {{.LessThan}}table{{.GreaterThan}}...`,
+		`{{.LessThan}}table{{.GreaterThan}}`,
 	},
 }
 
@@ -57,6 +62,11 @@ var autoResolvers = map[string]merge.AutoResolver{
 	theirsFlag: merge.Theirs,
 }
 
+var schemaResolvers = map[string]merge.SchemaResolver{
+	oursFlag:   merge.OursSchema,
+	theirsFlag: merge.TheirsSchema,
+}
+
 var autoResolverParams []string
 
 func init() {
@@ -126,13 +136,14 @@ func autoResolve(ctx context.Context, apr *argparser.ArgParseResults, dEnv *env.
 
 	autoResolveFlag := funcFlags.AsSlice()[0]
 	autoResolveFunc := autoResolvers[autoResolveFlag]
+	schResolveFunc := schemaResolvers[autoResolveFlag]
 
 	var err error
 	tbls := apr.Args()
 	if len(tbls) == 1 && tbls[0] == "." {
-		err = actions.AutoResolveAll(ctx, dEnv, autoResolveFunc)
+		err = actions.AutoResolveAll(ctx, dEnv, autoResolveFunc, schResolveFunc)
 	} else {
-		err = actions.AutoResolveTables(ctx, dEnv, autoResolveFunc, tbls)
+		err = actions.AutoResolveTables(ctx, dEnv, autoResolveFunc, schResolveFunc, tbls)
 	}
 
 	if err != nil {
@@ -150,6 +161,10 @@ func autoResolve(ctx context.Context, apr *argparser.ArgParseResults, dEnv *env.
 func manualResolve(ctx context.Context, apr *argparser.ArgParseResults, dEnv *env.DoltEnv) errhand.VerboseError {
 	args := apr.Args()
 
+	if len(args) == 1 {
+		return resolveEditedSchema(ctx, args[0], dEnv)
+	}
+
 	if len(args) < 2 {
 		return errhand.BuildDError("").SetPrintUsage().Build()
 	}
@@ -234,6 +249,58 @@ func manualResolve(ctx context.Context, apr *argparser.ArgParseResults, dEnv *en
 	return saveDocsOnResolve(ctx, dEnv)
 }
 
+// resolveEditedSchema marks the schema conflicts of |tblName| as resolved and finishes merging its rows, once its schema
+// has been altered so that it no longer conflicts.
+func resolveEditedSchema(ctx context.Context, tblName string, dEnv *env.DoltEnv) errhand.VerboseError {
+	root, verr := commands.GetWorkingWithVErr(dEnv)
+
+	if verr != nil {
+		return verr
+	}
+
+	tbl, ok, err := root.GetTable(ctx, tblName)
+
+	if err != nil {
+		return errhand.BuildDError("error: failed to get table '%s'", tblName).AddCause(err).Build()
+	} else if !ok {
+		return errhand.BuildDError("error: table '%s' not found", tblName).Build()
+	}
+
+	if has, err := tbl.HasSchemaConflicts(); err != nil {
+		return errhand.BuildDError("error: failed to read conflicts").AddCause(err).Build()
+	} else if !has {
+		return errhand.BuildDError("error: table '%s' has no schema conflicts", tblName).
+			AddDetails("hint: use --ours or --theirs, or provide the keys of the rows to resolve").Build()
+	}
+
+	tableEditSession := editor.CreateTableEditSession(root, editor.TableEditSessionProps{NewRowValidator: sqlutil.NewCheckRowValidator})
+	stats, err := merge.ResolveSchemaConflicts(ctx, root.VRW(), tblName, tbl, nil, tableEditSession)
+
+	if err != nil {
+		return errhand.BuildDError("error: the schema of table '%s' still conflicts", tblName).
+			AddCause(err).
+			AddDetails("hint: alter the table to remove the conflicts, or use --ours or --theirs").Build()
+	}
+
+	root, err = tableEditSession.Flush(ctx)
+
+	if err != nil {
+		return errhand.BuildDError("error: failed to update the working set").AddCause(err).Build()
+	}
+
+	if verr := commands.UpdateWorkingWithVErr(dEnv, root); verr != nil {
+		return verr
+	}
+
+	if stats.Conflicts > 0 {
+		cli.Printf("Schema conflicts resolved. Merging %s resulted in %d conflicting rows\n", tblName, stats.Conflicts)
+	} else {
+		cli.Println("Schema conflicts resolved for table", tblName)
+	}
+
+	return saveDocsOnResolve(ctx, dEnv)
+}
+
 func saveDocsOnResolve(ctx context.Context, dEnv *env.DoltEnv) errhand.VerboseError {
 	err := actions.SaveTrackedDocsFromWorking(ctx, dEnv)
 	if err != nil {
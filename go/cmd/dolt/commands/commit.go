@@ -73,6 +73,10 @@ func (cmd CommitCmd) Exec(ctx context.Context, commandStr string, args []string,
 	help, usage := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, commitDocs, ap))
 	apr := cli.ParseArgs(ap, args, help)
 
+	if verr := checkNoActiveRebase(dEnv, "commit"); verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
 	// Check if the -all param is provided. Stage all tables if so.
 	allFlag := apr.Contains(cli.AllFlag)
 
@@ -181,19 +185,25 @@ func handleCommitErr(ctx context.Context, dEnv *env.DoltEnv, err error, usage cl
 func getCommitMessageFromEditor(ctx context.Context, dEnv *env.DoltEnv) string {
 	var finalMsg string
 	initialMsg := buildInitalCommitMsg(ctx, dEnv)
-	backupEd := "vim"
-	if ed, edSet := os.LookupEnv("EDITOR"); edSet {
-		backupEd = ed
-	}
-	editorStr := dEnv.Config.GetStringOrDefault(env.DoltEditor, backupEd)
+	editorStr := getEditorString(dEnv)
 
 	cli.ExecuteWithStdioRestored(func() {
-		commitMsg, _ := editor.OpenCommitEditor(*editorStr, initialMsg)
+		commitMsg, _ := editor.OpenCommitEditor(editorStr, initialMsg)
 		finalMsg = parseCommitMessage(commitMsg)
 	})
 	return finalMsg
 }
 
+// getEditorString returns the editor configured for dolt, falling back to $EDITOR and then vim.
+func getEditorString(dEnv *env.DoltEnv) string {
+	backupEd := "vim"
+	if ed, edSet := os.LookupEnv("EDITOR"); edSet {
+		backupEd = ed
+	}
+
+	return *dEnv.Config.GetStringOrDefault(env.DoltEditor, backupEd)
+}
+
 func buildInitalCommitMsg(ctx context.Context, dEnv *env.DoltEnv) string {
 	initialNoColor := color.NoColor
 	color.NoColor = true
@@ -16,6 +16,7 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -63,6 +64,8 @@ const (
 
 	TabularDiffOutput diffOutput = 1
 	SQLDiffOutput     diffOutput = 2
+	JSONDiffOutput    diffOutput = 3
+	CSVDiffOutput     diffOutput = 4
 
 	DataFlag    = "data"
 	SchemaFlag  = "schema"
@@ -96,6 +99,12 @@ Show changes between the working and staged tables, changes between the working
 The diffs displayed can be limited to show the first N by providing the parameter {{.EmphasisLeft}}--limit N{{.EmphasisRight}} where {{.EmphasisLeft}}N{{.EmphasisRight}} is the number of diffs to display.
 
 In order to filter which diffs are displayed {{.EmphasisLeft}}--where key=value{{.EmphasisRight}} can be used.  The key in this case would be either {{.EmphasisLeft}}to_COLUMN_NAME{{.EmphasisRight}} or {{.EmphasisLeft}}from_COLUMN_NAME{{.EmphasisRight}}. where {{.EmphasisLeft}}from_COLUMN_NAME=value{{.EmphasisRight}} would filter based on the original value and {{.EmphasisLeft}}to_COLUMN_NAME{{.EmphasisRight}} would select based on its updated value.
+
+The {{.EmphasisLeft}}json{{.EmphasisRight}} and {{.EmphasisLeft}}csv{{.EmphasisRight}} result formats are intended to be read by other programs. Both emit one record per changed row, holding the type of the change ({{.EmphasisLeft}}added{{.EmphasisRight}}, {{.EmphasisLeft}}removed{{.EmphasisRight}} or {{.EmphasisLeft}}modified{{.EmphasisRight}}) and the values of the row before and after the change.
+
+{{.EmphasisLeft}}json{{.EmphasisRight}} output is a single object of the form {{.EmphasisLeft}}{"tables": [...]}{{.EmphasisRight}} with an entry per changed table. Each entry holds the table's name, its {{.EmphasisLeft}}schema_diff{{.EmphasisRight}} listing the changed columns, indexes and foreign keys, and its changed {{.EmphasisLeft}}rows{{.EmphasisRight}}, each with its {{.EmphasisLeft}}diff_type{{.EmphasisRight}}, primary {{.EmphasisLeft}}key{{.EmphasisRight}}, and {{.EmphasisLeft}}from{{.EmphasisRight}} and {{.EmphasisLeft}}to{{.EmphasisRight}} values.
+
+{{.EmphasisLeft}}csv{{.EmphasisRight}} output has a {{.EmphasisLeft}}table{{.EmphasisRight}} and a {{.EmphasisLeft}}diff_type{{.EmphasisRight}} column followed by a {{.EmphasisLeft}}from_COLUMN_NAME{{.EmphasisRight}} and a {{.EmphasisLeft}}to_COLUMN_NAME{{.EmphasisRight}} column for each column of the table. When several tables have changed, the rows of each table are preceded by a blank line and their own header. Schema changes are not included in csv output.
 `,
 	Synopsis: []string{
 		`[options] [{{.LessThan}}commit{{.GreaterThan}}] [{{.LessThan}}tables{{.GreaterThan}}...]`,
@@ -111,6 +120,9 @@ type diffArgs struct {
 	limit      int
 	where      string
 	query      string
+
+	// tablesWritten counts the tables written so far in the json and csv formats
+	tablesWritten int
 }
 
 type DiffCmd struct{}
@@ -141,7 +153,7 @@ func (cmd DiffCmd) createArgParser() *argparser.ArgParser {
 	ap.SupportsFlag(DataFlag, "d", "Show only the data changes, do not show the schema changes (Both shown by default).")
 	ap.SupportsFlag(SchemaFlag, "s", "Show only the schema changes, do not show the data changes (Both shown by default).")
 	ap.SupportsFlag(SummaryFlag, "", "Show summary of data changes")
-	ap.SupportsString(FormatFlag, "r", "result output format", "How to format diff output. Valid values are tabular & sql. Defaults to tabular. ")
+	ap.SupportsString(FormatFlag, "r", "result output format", "How to format diff output. Valid values are tabular, sql, json & csv. Defaults to tabular. ")
 	ap.SupportsString(whereParam, "", "column", "filters columns based on values in the diff.  See {{.EmphasisLeft}}dolt diff --help{{.EmphasisRight}} for details.")
 	ap.SupportsInt(limitParam, "", "record_count", "limits to the first N diffs.")
 	ap.SupportsString(QueryFlag, "q", "query", "diffs the results of a query at two commits")
@@ -166,12 +178,24 @@ func (cmd DiffCmd) Exec(ctx context.Context, commandStr string, args []string, d
 		return HandleVErrAndExitCode(verr, usage)
 	}
 
+	if dArgs.diffOutput == JSONDiffOutput {
+		cli.Print(`{"tables":[`)
+	}
+
 	verr := diffUserTables(ctx, fromRoot, toRoot, dArgs)
 
+	if dArgs.diffOutput == JSONDiffOutput {
+		cli.Println(`]}`)
+	}
+
 	if verr != nil {
 		return HandleVErrAndExitCode(verr, usage)
 	}
 
+	if dArgs.diffOutput == JSONDiffOutput || dArgs.diffOutput == CSVDiffOutput {
+		return 0
+	}
+
 	err = diffDoltDocs(ctx, dEnv, fromRoot, toRoot, dArgs)
 
 	if err != nil {
@@ -219,6 +243,10 @@ func parseDiffArgs(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgPar
 		dArgs.diffOutput = TabularDiffOutput
 	case "sql":
 		dArgs.diffOutput = SQLDiffOutput
+	case "json":
+		dArgs.diffOutput = JSONDiffOutput
+	case "csv":
+		dArgs.diffOutput = CSVDiffOutput
 	case "":
 		dArgs.diffOutput = TabularDiffOutput
 	default:
@@ -232,6 +260,15 @@ func parseDiffArgs(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgPar
 		dArgs.diffParts = Summary
 	}
 
+	if dArgs.diffOutput == JSONDiffOutput || dArgs.diffOutput == CSVDiffOutput {
+		if dArgs.diffParts == Summary {
+			return nil, nil, nil, fmt.Errorf("invalid Arguments: --summary cannot be combined with -r %s", f)
+		}
+		if dArgs.diffOutput == CSVDiffOutput && dArgs.diffParts == SchemaOnlyDiff {
+			return nil, nil, nil, fmt.Errorf("invalid Arguments: --schema cannot be combined with -r csv, use -r json for schema diffs")
+		}
+	}
+
 	dArgs.limit, _ = apr.GetInt(limitParam)
 	dArgs.where = apr.GetValueOrDefault(whereParam, "")
 
@@ -409,6 +446,19 @@ func diffUserTables(ctx context.Context, fromRoot, toRoot *doltdb.RootValue, dAr
 			return errhand.BuildDError("error: both tables in tableDelta are nil").Build()
 		}
 
+		if dArgs.diffOutput == JSONDiffOutput || dArgs.diffOutput == CSVDiffOutput {
+			if tblName == doltdb.DocTableName {
+				continue
+			}
+
+			verr = diffTableStructured(ctx, td, dArgs)
+			if verr != nil {
+				return verr
+			}
+
+			continue
+		}
+
 		if dArgs.diffOutput == TabularDiffOutput {
 			printTableDiffSummary(td)
 
@@ -544,6 +594,20 @@ func tabularSchemaDiff(ctx context.Context, td diff.TableDelta, fromSchemas, toS
 		}
 	}
 
+	for _, checkDiff := range diff.DiffSchChecks(fromSch, toSch) {
+		switch checkDiff.DiffType {
+		case diff.SchDiffNone:
+			cli.Println("     " + sqlfmt.FmtCheck(checkDiff.To))
+		case diff.SchDiffAdded:
+			cli.Println(color.GreenString("+    " + sqlfmt.FmtCheck(checkDiff.To)))
+		case diff.SchDiffRemoved:
+			cli.Println(color.RedString("-    " + sqlfmt.FmtCheck(checkDiff.From)))
+		case diff.SchDiffModified:
+			cli.Println("<    " + sqlfmt.FmtCheck(checkDiff.From))
+			cli.Println(">    " + sqlfmt.FmtCheck(checkDiff.To))
+		}
+	}
+
 	for _, fkDiff := range diff.DiffForeignKeys(td.FromFks, td.ToFks) {
 		switch fkDiff.DiffType {
 		case diff.SchDiffNone:
@@ -620,6 +684,19 @@ func sqlSchemaDiff(ctx context.Context, td diff.TableDelta, toSchemas map[string
 			}
 		}
 
+		for _, checkDiff := range diff.DiffSchChecks(fromSch, toSch) {
+			switch checkDiff.DiffType {
+			case diff.SchDiffNone:
+			case diff.SchDiffAdded:
+				cli.Println(sqlfmt.AlterTableAddCheckStmt(td.ToName, checkDiff.To))
+			case diff.SchDiffRemoved:
+				cli.Println(sqlfmt.AlterTableDropCheckStmt(td.FromName, checkDiff.From))
+			case diff.SchDiffModified:
+				cli.Println(sqlfmt.AlterTableDropCheckStmt(td.FromName, checkDiff.From))
+				cli.Println(sqlfmt.AlterTableAddCheckStmt(td.ToName, checkDiff.To))
+			}
+		}
+
 		for _, fkDiff := range diff.DiffForeignKeys(td.FromFks, td.ToFks) {
 			switch fkDiff.DiffType {
 			case diff.SchDiffNone:
@@ -638,6 +715,60 @@ func sqlSchemaDiff(ctx context.Context, td diff.TableDelta, toSchemas map[string
 	return nil
 }
 
+type jsonTableDiff struct {
+	Table      string                `json:"table"`
+	FromName   string                `json:"from_name"`
+	ToName     string                `json:"to_name"`
+	DiffType   string                `json:"diff_type"`
+	SchemaDiff *diff.TableSchemaDiff `json:"schema_diff,omitempty"`
+}
+
+// diffTableStructured writes the diff of a single table in the json or csv format.
+func diffTableStructured(ctx context.Context, td diff.TableDelta, dArgs *diffArgs) errhand.VerboseError {
+	if dArgs.diffOutput == CSVDiffOutput {
+		return diffRows(ctx, td, dArgs)
+	}
+
+	fromSch, toSch, err := td.GetSchemas(ctx)
+	if err != nil {
+		return errhand.BuildDError("cannot retrieve schema for table %s", td.CurName()).AddCause(err).Build()
+	}
+
+	jtd := jsonTableDiff{Table: td.CurName(), FromName: td.FromName, ToName: td.ToName, DiffType: "modified"}
+	if td.IsAdd() {
+		jtd.DiffType = "added"
+	} else if td.IsDrop() {
+		jtd.DiffType = "removed"
+	}
+
+	if dArgs.diffParts&SchemaOnlyDiff != 0 {
+		sd := diff.NewTableSchemaDiff(td, fromSch, toSch)
+		jtd.SchemaDiff = &sd
+	}
+
+	data, err := json.Marshal(jtd)
+	if err != nil {
+		return errhand.BuildDError("error: failed to write diff for table %s", td.CurName()).AddCause(err).Build()
+	}
+
+	if dArgs.tablesWritten > 0 {
+		cli.Print(",")
+	}
+	dArgs.tablesWritten++
+
+	if dArgs.diffParts&DataOnlyDiff == 0 {
+		cli.Print(string(data))
+		return nil
+	}
+
+	// leave the object open to add the row diffs
+	cli.Print(string(data[:len(data)-1]) + `,"rows":[`)
+	verr := diffRows(ctx, td, dArgs)
+	cli.Print("]}")
+
+	return verr
+}
+
 func dumbDownSchema(in schema.Schema) (schema.Schema, error) {
 	allCols := in.GetAllCols()
 
@@ -727,9 +858,14 @@ func diffRows(ctx context.Context, td diff.TableDelta, dArgs *diffArgs) errhand.
 	}
 
 	var sink DiffSink
-	if dArgs.diffOutput == TabularDiffOutput {
+	switch dArgs.diffOutput {
+	case TabularDiffOutput:
 		sink, err = diff.NewColorDiffSink(iohelp.NopWrCloser(cli.CliOut), unionSch, numHeaderRows)
-	} else {
+	case JSONDiffOutput:
+		sink, err = diff.NewJSONDiffSink(iohelp.NopWrCloser(cli.CliOut), joiner)
+	case CSVDiffOutput:
+		sink, err = diff.NewCSVDiffSink(iohelp.NopWrCloser(cli.CliOut), joiner, td.CurName(), dArgs.tablesWritten > 0)
+	default:
 		sink, err = diff.NewSQLDiffSink(iohelp.NopWrCloser(cli.CliOut), unionSch, td.CurName())
 	}
 
@@ -737,7 +873,16 @@ func diffRows(ctx context.Context, td diff.TableDelta, dArgs *diffArgs) errhand.
 		return errhand.BuildDError("").AddCause(err).Build()
 	}
 
-	defer sink.Close()
+	defer func() {
+		if sink != nil {
+			_ = sink.Close()
+		}
+	}()
+
+	var jsonDiffs *diff.JSONPathDiffCollector
+	if dArgs.diffOutput == TabularDiffOutput {
+		jsonDiffs = diff.NewJSONPathDiffCollector(joiner, toSch)
+	}
 
 	var badRowVErr errhand.VerboseError
 	badRowCallback := func(trf *pipeline.TransformRowFailure) (quit bool) {
@@ -745,12 +890,12 @@ func diffRows(ctx context.Context, td diff.TableDelta, dArgs *diffArgs) errhand.
 		return true
 	}
 
-	p, verr := buildPipeline(dArgs, joiner, ds, unionSch, src, sink, badRowCallback)
+	p, verr := buildPipeline(dArgs, joiner, ds, unionSch, src, sink, jsonDiffs, badRowCallback)
 	if verr != nil {
 		return verr
 	}
 
-	if dArgs.diffOutput != SQLDiffOutput {
+	if dArgs.diffOutput == TabularDiffOutput {
 		if schemasEqual {
 			schRow, err := untyped.NewRowFromTaggedStrings(toRows.Format(), unionSch, newColNames)
 
@@ -786,10 +931,25 @@ func diffRows(ctx context.Context, td diff.TableDelta, dArgs *diffArgs) errhand.
 		return badRowVErr
 	}
 
+	if jsonDiffs != nil && len(jsonDiffs.Diffs()) > 0 {
+		// the path diffs are printed after the table of row diffs
+		err = sink.Close()
+		sink = nil
+		if err != nil {
+			return errhand.BuildDError("Error diffing: %v", err.Error()).Build()
+		}
+
+		printJSONPathDiffs(jsonDiffs.Diffs())
+	}
+
+	if csvSink, ok := sink.(*diff.CSVDiffSink); ok && csvSink.RowsWritten() > 0 {
+		dArgs.tablesWritten++
+	}
+
 	return nil
 }
 
-func buildPipeline(dArgs *diffArgs, joiner *rowconv.Joiner, ds *diff.DiffSplitter, untypedUnionSch schema.Schema, src *diff.RowDiffSource, sink DiffSink, badRowCB pipeline.BadRowCallback) (*pipeline.Pipeline, errhand.VerboseError) {
+func buildPipeline(dArgs *diffArgs, joiner *rowconv.Joiner, ds *diff.DiffSplitter, untypedUnionSch schema.Schema, src *diff.RowDiffSource, sink DiffSink, jsonDiffs *diff.JSONPathDiffCollector, badRowCB pipeline.BadRowCallback) (*pipeline.Pipeline, errhand.VerboseError) {
 	var where FilterFn
 	var selTrans *SelectTransform
 	where, err := ParseWhere(joiner.GetSchema(), dArgs.where)
@@ -811,9 +971,16 @@ func buildPipeline(dArgs *diffArgs, joiner *rowconv.Joiner, ds *diff.DiffSplitte
 		transforms.AppendTransforms(pipeline.NewNamedTransform("select", selTrans.LimitAndFilter))
 	}
 
-	transforms.AppendTransforms(
-		pipeline.NewNamedTransform("split_diffs", ds.SplitDiffIntoOldAndNew),
-	)
+	if jsonDiffs != nil {
+		transforms.AppendTransforms(pipeline.NewNamedTransform("json_diffs", jsonDiffs.CollectDiffs))
+	}
+
+	// the json and csv sinks write a single record for each row diff, so they need the rows before they are split
+	if dArgs.diffOutput == TabularDiffOutput || dArgs.diffOutput == SQLDiffOutput {
+		transforms.AppendTransforms(
+			pipeline.NewNamedTransform("split_diffs", ds.SplitDiffIntoOldAndNew),
+		)
+	}
 
 	if dArgs.diffOutput == TabularDiffOutput {
 		nullPrinter := nullprinter.NewNullPrinter(untypedUnionSch)
@@ -833,6 +1000,25 @@ func buildPipeline(dArgs *diffArgs, joiner *rowconv.Joiner, ds *diff.DiffSplitte
 	return p, nil
 }
 
+// printJSONPathDiffs prints the changes made within the JSON documents of each modified row.
+func printJSONPathDiffs(rowDiffs []diff.JSONRowPathDiffs) {
+	for _, rd := range rowDiffs {
+		cli.Println(color.CyanString("%s @ %s", rd.Column, rd.Key))
+		for _, d := range rd.Diffs {
+			line := "\t" + d.String()
+			switch d.DiffType {
+			case "added":
+				line = color.GreenString(line)
+			case "removed":
+				line = color.RedString(line)
+			default:
+				line = color.YellowString(line)
+			}
+			cli.Println(line)
+		}
+	}
+}
+
 func mapTagToColName(sch, untypedUnionSch schema.Schema) (map[uint64]string, errhand.VerboseError) {
 	tagToCol := make(map[uint64]string)
 	allCols := sch.GetAllCols()
@@ -46,6 +46,8 @@ var mergeDocs = cli.CommandDocumentationContent{
 
 The second syntax ({{.LessThan}}dolt merge --abort{{.GreaterThan}}) can only be run after the merge has resulted in conflicts. dolt merge {{.EmphasisLeft}}--abort{{.EmphasisRight}} will abort the merge process and try to reconstruct the pre-merge state. However, if there were uncommitted changes when the merge started (and especially if those changes were further modified after the merge was started), dolt merge {{.EmphasisLeft}}--abort{{.EmphasisRight}} will in some cases be unable to reconstruct the original (pre-merge) changes. Therefore: 
 
+Conflicting rows in a table can be resolved automatically by adding a merge policy for the table to the {{.EmphasisLeft}}dolt_merge_policies{{.EmphasisRight}} system table. Each policy has a {{.EmphasisLeft}}table_name{{.EmphasisRight}}, a {{.EmphasisLeft}}strategy{{.EmphasisRight}} and an optional {{.EmphasisLeft}}expression{{.EmphasisRight}}. The strategy {{.EmphasisLeft}}ours{{.EmphasisRight}} keeps our version of each conflicting row, and {{.EmphasisLeft}}theirs{{.EmphasisRight}} takes their version. The strategy {{.EmphasisLeft}}sql{{.EmphasisRight}} evaluates the expression with the two versions of the row available as the tables {{.EmphasisLeft}}ours{{.EmphasisRight}} and {{.EmphasisLeft}}theirs{{.EmphasisRight}}, and the expression must name the winning row by returning 'ours' or 'theirs', e.g. {{.EmphasisLeft}}IF(theirs.updated_at > ours.updated_at, 'theirs', 'ours'){{.EmphasisRight}}. A conflict is left unresolved if the expression returns NULL. Rows deleted on one side are left in conflict by the sql strategy. Merge policies are read from the current branch and only apply to tables with a primary key.
+
 {{.LessThan}}Warning{{.GreaterThan}}: Running dolt merge with non-trivial uncommitted changes is discouraged: while possible, it may leave you in a state that is hard to back out of in the case of a conflict.
 `,
 
@@ -120,6 +122,10 @@ func (cmd MergeCmd) Exec(ctx context.Context, commandStr string, args []string,
 
 		commitSpecStr := apr.Arg(0)
 
+		if verr := checkNoActiveRebase(dEnv, "merge"); verr != nil {
+			return HandleVErrAndExitCode(verr, usage)
+		}
+
 		var root *doltdb.RootValue
 		root, verr = GetWorkingWithVErr(dEnv)
 
@@ -438,7 +444,7 @@ func printSuccessStats(tblToStats map[string]*merge.MergeStats) bool {
 
 func printAdditions(tblToStats map[string]*merge.MergeStats) {
 	for tblName, stats := range tblToStats {
-		if stats.Operation == merge.TableRemoved {
+		if stats.Operation == merge.TableAdded {
 			cli.Println(tblName, "added")
 		}
 	}
@@ -455,9 +461,20 @@ func printDeletions(tblToStats map[string]*merge.MergeStats) {
 func printConflicts(tblToStats map[string]*merge.MergeStats) bool {
 	hasConflicts := false
 	for tblName, stats := range tblToStats {
+		if stats.Operation == merge.TableModified && stats.ResolvedByPolicy > 0 {
+			cli.Printf("Auto-merging %s: %d conflicting rows resolved by merge policy\n", tblName, stats.ResolvedByPolicy)
+		}
+
 		if stats.Operation == merge.TableModified && stats.Conflicts > 0 {
 			cli.Println("Auto-merging", tblName)
-			cli.Println("CONFLICT (content): Merge conflict in", tblName)
+			if stats.SchemaConflicts > 0 {
+				cli.Println("CONFLICT (schema): Merge conflict in", tblName)
+			} else {
+				cli.Println("CONFLICT (content): Merge conflict in", tblName)
+			}
+			if stats.CheckViolations > 0 {
+				cli.Printf("CONFLICT (check): %d merged rows in %s violate a check constraint\n", stats.CheckViolations, tblName)
+			}
 
 			hasConflicts = true
 		}
@@ -0,0 +1,290 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/doltcore/rebase"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/libraries/utils/editor"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+const (
+	interactiveFlag = "interactive"
+	continueFlag    = "continue"
+)
+
+var rebaseDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Reapply commits on top of another branch",
+	LongDesc: `Replays the commits on the current branch that are not on {{.LessThan}}upstream{{.GreaterThan}} on top of {{.LessThan}}upstream{{.GreaterThan}}, one at a time and in order, and updates the current branch to point at the result. Merge commits can't be replayed, so a branch with merge commits that are not on {{.LessThan}}upstream{{.GreaterThan}} can't be rebased. The working set must be clean before running {{.EmphasisLeft}}dolt rebase{{.EmphasisRight}}.
+
+With {{.EmphasisLeft}}--interactive{{.EmphasisRight}}, the list of commits to replay is opened in an editor as a todo file before the rebase starts. Each line of the todo file holds an action, a commit and its message. Lines can be reordered or removed, and the action of each line can be changed to one of:
+
+{{.EmphasisLeft}}pick{{.EmphasisRight}} (p): use the commit as is.
+
+{{.EmphasisLeft}}reword{{.EmphasisRight}} (r): use the commit, but open an editor to change its message.
+
+{{.EmphasisLeft}}squash{{.EmphasisRight}} (s): combine the commit with the previous commit, concatenating their messages.
+
+{{.EmphasisLeft}}drop{{.EmphasisRight}} (d): remove the commit.
+
+If replaying a commit results in conflicts, the rebase stops with the conflicts in the working set. Resolve them with {{.EmphasisLeft}}dolt conflicts{{.EmphasisRight}}, stage the result with {{.EmphasisLeft}}dolt add{{.EmphasisRight}} and run {{.EmphasisLeft}}dolt rebase --continue{{.EmphasisRight}} to carry on. {{.EmphasisLeft}}dolt rebase --abort{{.EmphasisRight}} restores the branch to its state before the rebase started.
+`,
+	Synopsis: []string{
+		"[-i] {{.LessThan}}upstream{{.GreaterThan}}",
+		"--continue",
+		"--abort",
+	},
+}
+
+type RebaseCmd struct{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd RebaseCmd) Name() string {
+	return "rebase"
+}
+
+// Description returns a description of the command
+func (cmd RebaseCmd) Description() string {
+	return "Reapply commits on top of another branch."
+}
+
+// CreateMarkdown creates a markdown file containing the helptext for the command at the given path
+func (cmd RebaseCmd) CreateMarkdown(fs filesys.Filesys, path, commandStr string) error {
+	ap := cmd.createArgParser()
+	return CreateMarkdown(fs, path, cli.GetCommandDocumentation(commandStr, rebaseDocs, ap))
+}
+
+func (cmd RebaseCmd) createArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"upstream", "The branch or commit to replay the commits of the current branch on top of."})
+	ap.SupportsFlag(interactiveFlag, "i", "Edit the list of commits to replay before starting the rebase.")
+	ap.SupportsFlag(continueFlag, "", "Continue the rebase after resolving conflicts.")
+	ap.SupportsFlag(abortParam, "", "Abort the rebase and restore the branch to its state before the rebase started.")
+	return ap
+}
+
+// Exec executes the command
+func (cmd RebaseCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := cmd.createArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, rebaseDocs, ap))
+	apr := cli.ParseArgs(ap, args, help)
+
+	if apr.Contains(continueFlag) || apr.Contains(abortParam) {
+		if apr.NArg() != 0 || apr.Contains(interactiveFlag) || (apr.Contains(continueFlag) && apr.Contains(abortParam)) {
+			usage()
+			return 1
+		}
+
+		if apr.Contains(abortParam) {
+			return abortRebase(ctx, dEnv, usage)
+		}
+
+		stop, err := rebase.Continue(ctx, dEnv, rewordWithEditor(dEnv))
+		return handleRebaseResult(ctx, dEnv, stop, err, usage)
+	}
+
+	if apr.NArg() != 1 {
+		usage()
+		return 1
+	}
+
+	if dEnv.IsRebaseActive() {
+		verr := errhand.BuildDError("error: a rebase is already in progress.").
+			AddDetails("hint: use 'dolt rebase --continue' or 'dolt rebase --abort'.").Build()
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	verr := checkCleanWorkingSet(ctx, dEnv, "rebase")
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	upstream, verr := ResolveCommitWithVErr(dEnv, apr.Arg(0))
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	head, err := dEnv.DoltDB.ResolveRef(ctx, dEnv.RepoState.CWBHeadRef())
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to resolve HEAD").AddCause(err).Build(), usage)
+	}
+
+	upToDate, err := isAncestor(ctx, upstream, head)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to find common ancestor").AddCause(err).Build(), usage)
+	}
+
+	if upToDate {
+		cli.Printf("Current branch %s is up to date.\n", dEnv.RepoState.CWBHeadRef().GetPath())
+		return 0
+	}
+
+	todo, err := rebase.BuildTodo(ctx, dEnv.DoltDB, head, upstream)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to determine the commits to rebase").AddCause(err).Build(), usage)
+	}
+
+	if apr.Contains(interactiveFlag) {
+		todo, verr = editTodo(ctx, dEnv, todo)
+		if verr != nil {
+			return HandleVErrAndExitCode(verr, usage)
+		}
+
+		if len(todo) == 0 {
+			cli.Println("Nothing to do")
+			return 0
+		}
+	}
+
+	stop, err := rebase.Start(ctx, dEnv, upstream, todo, rewordWithEditor(dEnv))
+	return handleRebaseResult(ctx, dEnv, stop, err, usage)
+}
+
+func abortRebase(ctx context.Context, dEnv *env.DoltEnv, usage cli.UsagePrinter) int {
+	err := rebase.Abort(ctx, dEnv)
+	if err == rebase.ErrNoRebaseInProgress {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: no rebase in progress").Build(), usage)
+	} else if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to abort the rebase").AddCause(err).Build(), usage)
+	}
+
+	err = actions.SaveTrackedDocsFromWorking(ctx, dEnv)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to update docs to the new working root").AddCause(err).Build(), usage)
+	}
+
+	return 0
+}
+
+func handleRebaseResult(ctx context.Context, dEnv *env.DoltEnv, stop *rebase.Stop, err error, usage cli.UsagePrinter) int {
+	if err != nil {
+		var verr errhand.VerboseError
+		switch {
+		case err == rebase.ErrNoRebaseInProgress:
+			verr = errhand.BuildDError("error: no rebase in progress").Build()
+		case err == rebase.ErrRebaseUnresolvedConflicts:
+			verr = errhand.BuildDError("error: you have unresolved conflicts.").
+				AddDetails("hint: resolve all conflicts and mark them as resolved with 'dolt add <table>'").
+				AddDetails("hint: before running 'dolt rebase --continue'.").Build()
+		case err == rebase.ErrRebaseUnstagedChanges:
+			verr = errhand.BuildDError("error: you have unstaged changes.").
+				AddDetails("hint: stage them with 'dolt add <table>' before running 'dolt rebase --continue'.").Build()
+		default:
+			verr = errhand.BuildDError("error: rebase failed").AddCause(err).
+				AddDetails("hint: use 'dolt rebase --abort' to restore the branch to its state before the rebase.").Build()
+		}
+
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	if stop != nil {
+		printSuccessStats(stop.Stats)
+		cli.Println("error: could not apply", stop.Step.Commit)
+		cli.Println("hint: resolve all conflicts, mark them as resolved with 'dolt add <table>',")
+		cli.Println("hint: then run 'dolt rebase --continue'. To abort and get back to the state")
+		cli.Println("hint: before the rebase, run 'dolt rebase --abort'.")
+		return 1
+	}
+
+	err = actions.SaveTrackedDocsFromWorking(ctx, dEnv)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to update docs to the new working root").AddCause(err).Build(), usage)
+	}
+
+	cli.Printf("Successfully rebased and updated %s.\n", dEnv.RepoState.CWBHeadRef().String())
+	return 0
+}
+
+// isAncestor returns whether |anc| is an ancestor of, or the same commit as, |cm|.
+func isAncestor(ctx context.Context, anc, cm *doltdb.Commit) (bool, error) {
+	mergeBase, err := doltdb.GetCommitAncestor(ctx, anc, cm)
+	if err != nil {
+		return false, err
+	}
+
+	h1, err := mergeBase.HashOf()
+	if err != nil {
+		return false, err
+	}
+
+	h2, err := anc.HashOf()
+	if err != nil {
+		return false, err
+	}
+
+	return h1 == h2, nil
+}
+
+const todoHelp = `
+# Commands:
+# p, pick <commit> = use commit
+# r, reword <commit> = use commit, but edit the commit message
+# s, squash <commit> = use commit, but meld into previous commit
+# d, drop <commit> = remove commit
+#
+# These lines can be re-ordered; they are executed from top to bottom.
+# If you remove a line here THAT COMMIT WILL BE LOST.
+# However, if you remove everything, the rebase will be aborted.
+`
+
+func editTodo(ctx context.Context, dEnv *env.DoltEnv, todo []env.RebaseStep) ([]env.RebaseStep, errhand.VerboseError) {
+	text, err := rebase.FormatTodo(ctx, dEnv.DoltDB, todo)
+	if err != nil {
+		return nil, errhand.BuildDError("error: failed to build the rebase todo list").AddCause(err).Build()
+	}
+
+	var edited string
+	cli.ExecuteWithStdioRestored(func() {
+		edited, err = editor.OpenCommitEditor(getEditorString(dEnv), text+todoHelp)
+	})
+
+	if err != nil {
+		return nil, errhand.BuildDError("error: failed to edit the rebase todo list").AddCause(err).Build()
+	}
+
+	todo, err = rebase.ParseTodo(ctx, dEnv.DoltDB, edited)
+	if err != nil {
+		return nil, errhand.BuildDError("error: invalid rebase todo list").AddCause(err).Build()
+	}
+
+	return todo, nil
+}
+
+func rewordWithEditor(dEnv *env.DoltEnv) rebase.RewordFn {
+	return func(ctx context.Context, msg string) (string, error) {
+		initialMsg := msg + "\n\n# Please enter the commit message for your changes. Lines starting\n" +
+			"# with '#' will be ignored, and an empty message keeps the original message.\n"
+
+		var edited string
+		var err error
+		cli.ExecuteWithStdioRestored(func() {
+			edited, err = editor.OpenCommitEditor(getEditorString(dEnv), initialMsg)
+		})
+
+		if err != nil {
+			return "", err
+		}
+
+		return parseCommitMessage(edited), nil
+	}
+}
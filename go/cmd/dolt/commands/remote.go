@@ -43,7 +43,7 @@ var remoteDocs = cli.CommandDocumentationContent{
 {{.EmphasisLeft}}add{{.EmphasisRight}}
 Adds a remote named {{.LessThan}}name{{.GreaterThan}} for the repository at {{.LessThan}}url{{.GreaterThan}}. The command dolt fetch {{.LessThan}}name{{.GreaterThan}} can then be used to create and update remote-tracking branches {{.EmphasisLeft}}<name>/<branch>{{.EmphasisRight}}.
 
-The {{.LessThan}}url{{.GreaterThan}} parameter supports url schemes of http, https, aws, gs, and file.  If a url scheme does not prefix the url then https is assumed.  If the {{.LessThan}}url{{.GreaterThan}} paramenter is in the format {{.EmphasisLeft}}<organization>/<repository>{{.EmphasisRight}} then dolt will use the {{.EmphasisLeft}}remotes.default_host{{.EmphasisRight}} from your configuration file (Which will be dolthub.com unless changed).
+The {{.LessThan}}url{{.GreaterThan}} parameter supports url schemes of http, https, aws, gs, s3, az, ssh, and file.  If a url scheme does not prefix the url then https is assumed.  If the {{.LessThan}}url{{.GreaterThan}} paramenter is in the format {{.EmphasisLeft}}<organization>/<repository>{{.EmphasisRight}} then dolt will use the {{.EmphasisLeft}}remotes.default_host{{.EmphasisRight}} from your configuration file (Which will be dolthub.com unless changed).
 
 AWS cloud remote urls should be of the form {{.EmphasisLeft}}aws://[dynamo-table:s3-bucket]/database{{.EmphasisRight}}.  You may configure your aws cloud remote using the optional parameters {{.EmphasisLeft}}aws-region{{.EmphasisRight}}, {{.EmphasisLeft}}aws-creds-type{{.EmphasisRight}}, {{.EmphasisLeft}}aws-creds-file{{.EmphasisRight}}.
 
@@ -55,13 +55,21 @@ aws-creds-type specifies the means by which credentials should be retrieved in o
 	
 GCP remote urls should be of the form gs://gcs-bucket/database and will use the credentials setup using the gcloud command line available from Google +
 
+S3 remote urls should be of the form {{.EmphasisLeft}}s3://s3-bucket/path{{.EmphasisRight}}. Unlike aws remotes they don't use a dynamo table, so they work with S3 compatible object stores such as MinIO and Ceph, whose url is provided with the optional parameter {{.EmphasisLeft}}s3-endpoint{{.EmphasisRight}}. They are configured with the same aws parameters as aws remotes.
+
+Azure Blob Storage remote urls should be of the form {{.EmphasisLeft}}az://storage-account/container/path{{.EmphasisRight}}. The key of the storage account is read from the {{.EmphasisLeft}}AZURE_STORAGE_KEY{{.EmphasisRight}} environment variable, or a shared access signature for the container from {{.EmphasisLeft}}AZURE_STORAGE_SAS_TOKEN{{.EmphasisRight}}. The url of the Blob service, such as that of the Azure storage emulator, can be provided with the optional parameter {{.EmphasisLeft}}azure-endpoint{{.EmphasisRight}}.
+
+The table files of cloud remotes can be encrypted before they are uploaded by providing {{.EmphasisLeft}}encryption-key{{.EmphasisRight}}, a passphrase the key is derived from, or {{.EmphasisLeft}}encryption-creds{{.EmphasisRight}}, the public key or key id of dolt credentials created with {{.EmphasisLeft}}dolt creds new{{.EmphasisRight}} whose private key the key is derived from. Everyone who clones or fetches from the remote must provide the same passphrase or credentials. The passphrase is stored with the configuration of the remote, in the repository's state.
+
 The local filesystem can be used as a remote by providing a repository url in the format file://absolute path. See https://en.wikipedia.org/wiki/File_URI_schemethi
+
+Remotes on other hosts can be accessed over ssh by providing a url in the format {{.EmphasisLeft}}ssh://[user@]host[:port]/absolute path{{.EmphasisRight}}, where the path is a dolt repository or a directory of the form used by file remotes. Dolt runs {{.EmphasisLeft}}dolt transfer{{.EmphasisRight}} on the host, so dolt must be installed there. The ssh command can be changed with the {{.EmphasisLeft}}DOLT_SSH{{.EmphasisRight}} environment variable, and the path of dolt on the host with {{.EmphasisLeft}}DOLT_SSH_EXEC_PATH{{.EmphasisRight}}.
 {{.EmphasisLeft}}remove{{.EmphasisRight}}, {{.EmphasisLeft}}rm{{.EmphasisRight}}, 
 Remove the remote named {{.LessThan}}name{{.GreaterThan}}. All remote-tracking branches and configuration settings for the remote are removed.`,
 
 	Synopsis: []string{
 		"[-v | --verbose]",
-		"add [--aws-region {{.LessThan}}region{{.GreaterThan}}] [--aws-creds-type {{.LessThan}}creds-type{{.GreaterThan}}] [--aws-creds-file {{.LessThan}}file{{.GreaterThan}}] [--aws-creds-profile {{.LessThan}}profile{{.GreaterThan}}] {{.LessThan}}name{{.GreaterThan}} {{.LessThan}}url{{.GreaterThan}}",
+		"add [--aws-region {{.LessThan}}region{{.GreaterThan}}] [--aws-creds-type {{.LessThan}}creds-type{{.GreaterThan}}] [--aws-creds-file {{.LessThan}}file{{.GreaterThan}}] [--aws-creds-profile {{.LessThan}}profile{{.GreaterThan}}] [--s3-endpoint {{.LessThan}}url{{.GreaterThan}}] [--azure-endpoint {{.LessThan}}url{{.GreaterThan}}] [--encryption-key {{.LessThan}}passphrase{{.GreaterThan}} | --encryption-creds {{.LessThan}}public-key-or-key-id{{.GreaterThan}}] {{.LessThan}}name{{.GreaterThan}} {{.LessThan}}url{{.GreaterThan}}",
 		"remove {{.LessThan}}name{{.GreaterThan}}",
 	},
 }
@@ -73,6 +81,8 @@ const (
 )
 
 var awsParams = []string{dbfactory.AWSRegionParam, dbfactory.AWSCredsTypeParam, dbfactory.AWSCredsFileParam, dbfactory.AWSCredsProfile}
+var endpointParams = map[string]string{dbfactory.S3Scheme: dbfactory.S3EndpointParam, dbfactory.AzureScheme: dbfactory.AzureEndpointParam}
+var encryptionParams = []string{dbfactory.EncryptionKeyParam, dbfactory.EncryptionCredsParam}
 var credTypes = []string{dbfactory.RoleCS.String(), dbfactory.EnvCS.String(), dbfactory.FileCS.String()}
 
 type RemoteCmd struct{}
@@ -103,6 +113,10 @@ func (cmd RemoteCmd) createArgParser() *argparser.ArgParser {
 	ap.SupportsValidatedString(dbfactory.AWSCredsTypeParam, "", "creds-type", "", argparser.ValidatorFromStrList(dbfactory.AWSCredsTypeParam, credTypes))
 	ap.SupportsString(dbfactory.AWSCredsFileParam, "", "file", "AWS credentials file")
 	ap.SupportsString(dbfactory.AWSCredsProfile, "", "profile", "AWS profile to use")
+	ap.SupportsString(dbfactory.S3EndpointParam, "", "url", "Url of the S3 compatible object store of an s3 remote.")
+	ap.SupportsString(dbfactory.AzureEndpointParam, "", "url", "Url of the Blob service of an az remote.")
+	ap.SupportsString(dbfactory.EncryptionKeyParam, "", "passphrase", "Encrypt the table files of the remote with a key derived from passphrase.")
+	ap.SupportsString(dbfactory.EncryptionCredsParam, "", "public-key-or-key-id", "Encrypt the table files of the remote with a key derived from the dolt credentials.")
 	return ap
 }
 
@@ -189,7 +203,7 @@ func getAbsRemoteUrl(fs filesys.Filesys, cfg config.ReadableConfig, urlArg strin
 
 	if u.Scheme != "" {
 		if u.Scheme == dbfactory.FileScheme || u.Scheme == dbfactory.LocalBSScheme {
-			absUrl, err := getAbsFileRemoteUrl(u.Host+u.Path, fs)
+			absUrl, err := getAbsFileRemoteUrl(u.Scheme, u.Host+u.Path, fs)
 
 			if err != nil {
 				return "", "", err
@@ -218,7 +232,7 @@ func getAbsRemoteUrl(fs filesys.Filesys, cfg config.ReadableConfig, urlArg strin
 	return dbfactory.HTTPSScheme, "https://" + path.Join(hostName, u.Path), nil
 }
 
-func getAbsFileRemoteUrl(urlStr string, fs filesys.Filesys) (string, error) {
+func getAbsFileRemoteUrl(scheme, urlStr string, fs filesys.Filesys) (string, error) {
 	var err error
 	urlStr = filepath.Clean(urlStr)
 	urlStr, err = fs.Abs(urlStr)
@@ -239,7 +253,7 @@ func getAbsFileRemoteUrl(urlStr string, fs filesys.Filesys) (string, error) {
 	if !strings.HasPrefix(urlStr, "/") {
 		urlStr = "/" + urlStr
 	}
-	return dbfactory.FileScheme + "://" + urlStr, nil
+	return scheme + "://" + urlStr, nil
 }
 
 func addRemote(dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.VerboseError {
@@ -285,22 +299,30 @@ func parseRemoteArgs(apr *argparser.ArgParseResults, scheme, remoteUrl string) (
 	params := map[string]string{}
 
 	var verr errhand.VerboseError
-	if scheme == dbfactory.AWSScheme {
+	if scheme == dbfactory.AWSScheme || scheme == dbfactory.S3Scheme {
 		verr = addAWSParams(remoteUrl, apr, params)
 	} else {
 		verr = verifyNoAwsParams(apr)
 	}
 
+	if verr == nil {
+		verr = addEndpointParams(apr, scheme, params)
+	}
+
+	if verr == nil {
+		verr = addEncryptionParams(apr, scheme, params)
+	}
+
 	return params, verr
 }
 
 func addAWSParams(remoteUrl string, apr *argparser.ArgParseResults, params map[string]string) errhand.VerboseError {
-	isAWS := strings.HasPrefix(remoteUrl, "aws")
+	isAWS := strings.HasPrefix(remoteUrl, "aws") || strings.HasPrefix(remoteUrl, "s3")
 
 	if !isAWS {
 		for _, p := range awsParams {
 			if _, ok := apr.GetValue(p); ok {
-				return errhand.BuildDError(p + " param is only valid for aws cloud remotes in the format aws://dynamo-table:s3-bucket/database").Build()
+				return errhand.BuildDError(p + " param is only valid for aws cloud remotes in the format aws://dynamo-table:s3-bucket/database or s3://s3-bucket/path").Build()
 			}
 		}
 	}
@@ -322,7 +344,45 @@ func verifyNoAwsParams(apr *argparser.ArgParseResults) errhand.VerboseError {
 		}
 
 		keysStr := strings.Join(awsParamKeys, ",")
-		return errhand.BuildDError("The parameters %s, are only valid for aws remotes", keysStr).SetPrintUsage().Build()
+		return errhand.BuildDError("The parameters %s, are only valid for aws and s3 remotes", keysStr).SetPrintUsage().Build()
+	}
+
+	return nil
+}
+
+func addEndpointParams(apr *argparser.ArgParseResults, scheme string, params map[string]string) errhand.VerboseError {
+	for endpointScheme, p := range endpointParams {
+		if val, ok := apr.GetValue(p); ok {
+			if scheme != endpointScheme {
+				return errhand.BuildDError("The parameter %s, is only valid for %s remotes", p, endpointScheme).SetPrintUsage().Build()
+			}
+
+			params[p] = val
+		}
+	}
+
+	return nil
+}
+
+func addEncryptionParams(apr *argparser.ArgParseResults, scheme string, params map[string]string) errhand.VerboseError {
+	vals := apr.GetValues(encryptionParams...)
+
+	if len(vals) == 0 {
+		return nil
+	}
+
+	switch scheme {
+	case dbfactory.AWSScheme, dbfactory.GSScheme, dbfactory.S3Scheme, dbfactory.AzureScheme, dbfactory.LocalBSScheme:
+	default:
+		return errhand.BuildDError("The parameters %s, are only valid for aws, gs, s3 and az remotes", strings.Join(encryptionParams, ",")).SetPrintUsage().Build()
+	}
+
+	if len(vals) > 1 {
+		return errhand.BuildDError("error: only one of --%s and --%s can be used", dbfactory.EncryptionKeyParam, dbfactory.EncryptionCredsParam).Build()
+	}
+
+	for k, v := range vals {
+		params[k] = v
 	}
 
 	return nil
@@ -339,7 +399,7 @@ func printRemotes(dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.Ver
 		if apr.Contains(verboseFlag) {
 			paramStr := make([]byte, 0)
 			if len(r.Params) > 0 {
-				paramStr, _ = json.Marshal(r.Params)
+				paramStr, _ = json.Marshal(maskSecretParams(r.Params))
 			}
 
 			cli.Printf("%s %s %s\n", r.Name, r.Url, paramStr)
@@ -350,3 +410,17 @@ func printRemotes(dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.Ver
 
 	return nil
 }
+
+// maskSecretParams returns a copy of |params| where the values of params which hold secrets are hidden
+func maskSecretParams(params map[string]string) map[string]string {
+	masked := make(map[string]string, len(params))
+	for k, v := range params {
+		if k == dbfactory.EncryptionKeyParam {
+			v = "********"
+		}
+
+		masked[k] = v
+	}
+
+	return masked
+}
@@ -17,10 +17,13 @@ package commands
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
 	"github.com/dolthub/dolt/go/libraries/utils/config"
 	"github.com/dolthub/dolt/go/libraries/utils/filesys"
@@ -111,6 +114,27 @@ func TestGetAbsRemoteUrl(t *testing.T) {
 			"file",
 			false,
 		},
+		{
+			"localbs://./test-repo",
+			config.NewMapConfig(map[string]string{}),
+			fmt.Sprintf("localbs://%s/test-repo", cwd),
+			"localbs",
+			false,
+		},
+		{
+			"s3://bucket/path/to/db",
+			config.NewMapConfig(map[string]string{}),
+			"s3://bucket/path/to/db",
+			"s3",
+			false,
+		},
+		{
+			"az://account/container/db",
+			config.NewMapConfig(map[string]string{}),
+			"az://account/container/db",
+			"az",
+			false,
+		},
 		{
 			// directory doesnt exist
 			"file://./doesnt_exist",
@@ -143,3 +167,66 @@ func TestGetAbsRemoteUrl(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRemoteArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		remoteUrl string
+		expected  map[string]string
+		expectErr bool
+	}{
+		{
+			"s3 with endpoint",
+			[]string{"--aws-creds-type", "env", "--s3-endpoint", "http://localhost:9000"},
+			"s3://bucket/db",
+			map[string]string{dbfactory.AWSCredsTypeParam: "env", dbfactory.S3EndpointParam: "http://localhost:9000"},
+			false,
+		},
+		{
+			"az with endpoint and encryption",
+			[]string{"--azure-endpoint", "http://localhost:10000/account", "--encryption-key", "passphrase"},
+			"az://account/container/db",
+			map[string]string{dbfactory.AzureEndpointParam: "http://localhost:10000/account", dbfactory.EncryptionKeyParam: "passphrase"},
+			false,
+		},
+		{
+			"s3 endpoint with aws remote",
+			[]string{"--s3-endpoint", "http://localhost:9000"},
+			"aws://table:bucket/db",
+			nil,
+			true,
+		},
+		{
+			"azure endpoint with s3 remote",
+			[]string{"--azure-endpoint", "http://localhost:10000/account"},
+			"s3://bucket/db",
+			nil,
+			true,
+		},
+		{
+			"aws params with az remote",
+			[]string{"--aws-region", "us-west-2"},
+			"az://account/container/db",
+			nil,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			apr, err := RemoteCmd{}.createArgParser().Parse(test.args)
+			require.NoError(t, err)
+			scheme := strings.SplitN(test.remoteUrl, "://", 2)[0]
+
+			params, verr := parseRemoteArgs(apr, scheme, test.remoteUrl)
+
+			if test.expectErr {
+				assert.NotNil(t, verr)
+			} else {
+				assert.Nil(t, verr)
+				assert.Equal(t, test.expected, params)
+			}
+		})
+	}
+}
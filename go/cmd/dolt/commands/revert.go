@@ -0,0 +1,154 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+var revertDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Undo the changes introduced by existing commits",
+	LongDesc: `Creates a new commit on the current branch that undoes the row and schema changes introduced by each of the named commits. The history of the branch is not rewritten. The working set must be clean before running {{.EmphasisLeft}}dolt revert{{.EmphasisRight}}.
+
+Each commit is reverted using a three-way merge between the current branch and the commit's parent, with the commit itself as the common ancestor. Merge commits cannot be reverted.
+
+If reverting the first of the commits results in conflicts, the conflicted tables are left in the working set and can be inspected and resolved with {{.EmphasisLeft}}dolt conflicts{{.EmphasisRight}}. Once resolved, use {{.EmphasisLeft}}dolt add{{.EmphasisRight}} and {{.EmphasisLeft}}dolt commit{{.EmphasisRight}} to record the result. If a later commit conflicts, the commits before it are reverted and committed, and the revert stops. In both cases the commits which were not reverted are listed so that they can be reverted once the conflicts are resolved.
+`,
+	Synopsis: []string{
+		"{{.LessThan}}commit{{.GreaterThan}}...",
+	},
+}
+
+type RevertCmd struct{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd RevertCmd) Name() string {
+	return "revert"
+}
+
+// Description returns a description of the command
+func (cmd RevertCmd) Description() string {
+	return "Undo the changes introduced by existing commits."
+}
+
+// CreateMarkdown creates a markdown file containing the helptext for the command at the given path
+func (cmd RevertCmd) CreateMarkdown(fs filesys.Filesys, path, commandStr string) error {
+	ap := cmd.createArgParser()
+	return CreateMarkdown(fs, path, cli.GetCommandDocumentation(commandStr, revertDocs, ap))
+}
+
+func (cmd RevertCmd) createArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"commit", "The commits whose changes should be undone."})
+	return ap
+}
+
+// Exec executes the command
+func (cmd RevertCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := cmd.createArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, revertDocs, ap))
+	apr := cli.ParseArgs(ap, args, help)
+
+	if apr.NArg() == 0 {
+		usage()
+		return 1
+	}
+
+	verr := checkCleanWorkingSet(ctx, dEnv, "revert")
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	commits := make([]*doltdb.Commit, apr.NArg())
+	for i, cSpecStr := range apr.Args() {
+		commits[i], verr = ResolveCommitWithVErr(dEnv, cSpecStr)
+		if verr != nil {
+			return HandleVErrAndExitCode(verr, usage)
+		}
+	}
+
+	name, email, err := actions.GetNameAndEmail(dEnv.Config)
+	if err != nil {
+		return handleCommitErr(ctx, dEnv, err, usage)
+	}
+
+	headRoot, err := dEnv.HeadRoot(ctx)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to get the head root").AddCause(err).Build(), usage)
+	}
+
+	revertedRoot, tblToStats, msg, notReverted, err := merge.Revert(ctx, dEnv.DoltDB, headRoot, commits)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: revert failed").AddCause(err).Build(), usage)
+	}
+
+	applied, verr := applyMergedRoot(ctx, dEnv, headRoot, revertedRoot, tblToStats)
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	if !applied {
+		cli.Println("error: could not revert")
+		cli.Println("hint: after resolving the conflicts, mark the corrected tables")
+		cli.Println("hint: with 'dolt add <table>' and commit the result with 'dolt commit'")
+		if len(notReverted) > 0 {
+			cli.Println("hint: then revert the commits which were not reverted:")
+			printNotReverted(notReverted)
+		}
+		return 1
+	}
+
+	_, err = actions.CommitStaged(ctx, dEnv.DbData(), actions.CommitStagedProps{
+		Message:          msg,
+		Date:             doltdb.CommitNowFunc(),
+		AllowEmpty:       false,
+		CheckForeignKeys: true,
+		Name:             name,
+		Email:            email,
+	})
+
+	if err != nil {
+		return handleCommitErr(ctx, dEnv, err, usage)
+	}
+
+	res := LogCmd{}.Exec(ctx, "log", []string{"-n=1"}, dEnv)
+	if len(notReverted) > 0 {
+		cli.Println("error: the revert stopped before a commit which conflicts, so these commits were not reverted:")
+		printNotReverted(notReverted)
+		return 1
+	}
+
+	return res
+}
+
+// printNotReverted lists the hashes of |commits|, which a revert stopped before reverting
+func printNotReverted(commits []*doltdb.Commit) {
+	for _, cm := range commits {
+		h, err := cm.HashOf()
+		if err != nil {
+			continue
+		}
+		cli.Println("\t" + h.String())
+	}
+}
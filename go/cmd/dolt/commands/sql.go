@@ -817,6 +817,13 @@ func prepend(s string, ss []string) []string {
 // Processes a single query. The Root of the sqlEngine will be updated if necessary.
 // Returns the schema and the row iterator for the results, which may be nil, and an error if one occurs.
 func processQuery(ctx *sql.Context, query string, se *sqlEngine) (sql.Schema, sql.RowIter, error) {
+	checkStmt, err := dsqle.ParseCheckConstraintStatement(query)
+	if err != nil {
+		return nil, nil, err
+	} else if checkStmt != nil {
+		return nil, nil, se.checkConstraint(ctx, checkStmt)
+	}
+
 	sqlStatement, err := sqlparser.Parse(query)
 	if err == sqlparser.ErrEmpty {
 		// silently skip empty statements
@@ -909,6 +916,14 @@ func flushBatchedEdits(ctx *sql.Context, se *sqlEngine) error {
 
 // Processes a single query in batch mode. The Root of the sqlEngine may or may not be changed.
 func processBatchQuery(ctx *sql.Context, query string, se *sqlEngine) error {
+	checkStmt, err := dsqle.ParseCheckConstraintStatement(query)
+	if err != nil {
+		return err
+	} else if checkStmt != nil {
+		// check constraints can't be parsed, so there is no statement to accumulate stats for
+		return processNonInsertBatchQuery(ctx, se, query, nil)
+	}
+
 	sqlStatement, err := sqlparser.Parse(query)
 	if err == sqlparser.ErrEmpty {
 		// silently skip empty statements
@@ -1312,6 +1327,22 @@ func isOkResult(sch sql.Schema) bool {
 	return sch.Equals(sql.OkResultSchema)
 }
 
+// Executes a statement which declares or drops check constraints. Updates the new root value in the sqlEngine if
+// necessary.
+func (se *sqlEngine) checkConstraint(ctx *sql.Context, stmt *dsqle.CheckConstraintStatement) error {
+	dbName := stmt.Database
+	if dbName == "" {
+		dbName = ctx.GetCurrentDatabase()
+	}
+
+	db, err := se.getDB(dbName)
+	if err != nil {
+		return err
+	}
+
+	return dsqle.ExecuteCheckConstraintStatement(ctx, se.engine, db, stmt)
+}
+
 // Executes a SQL DDL statement (create, update, etc.). Updates the new root value in
 // the sqlEngine if necessary.
 func (se *sqlEngine) ddl(ctx *sql.Context, ddl *sqlparser.DDL, query string) (sql.Schema, sql.RowIter, error) {
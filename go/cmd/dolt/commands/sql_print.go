@@ -18,6 +18,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
@@ -77,6 +78,16 @@ func sqlColToStr(col interface{}) string {
 			return strconv.FormatFloat(float64(typedCol), 'g', -1, 32)
 		case string:
 			return typedCol
+		case []byte:
+			return string(typedCol)
+		case json.Number:
+			return typedCol.String()
+		case map[string]interface{}, []interface{}:
+			// the result of a JSON function
+			str, err := json.Marshal(typedCol)
+			if err == nil {
+				return string(str)
+			}
 		case bool:
 			if typedCol {
 				return "true"
@@ -222,7 +233,9 @@ func createJSONPipeline(_ context.Context, sch sql.Schema, iter sql.RowIter) *pi
 
 func getJSONProcessFunc(sch sql.Schema) pipeline.StageFunc {
 	formats := make([]string, len(sch))
+	isJSON := make([]bool, len(sch))
 	for i, col := range sch {
+		isJSON[i] = col.Type == sql.JSON
 		switch col.Type.(type) {
 		case sql.StringType, sql.DatetimeType, sql.EnumType, sql.TimeType:
 			formats[i] = fmt.Sprintf(`"%s":"%%s"`, col.Name)
@@ -255,8 +268,13 @@ func getJSONProcessFunc(sch sql.Schema) pipeline.StageFunc {
 					}
 
 					validCols++
-					colStr := sqlColToStr(col)
-					colStr = strings.Replace(colStr, "\"", "\\\"", -1)
+					var colStr string
+					if doc, err := sql.JSON.Convert(col); isJSON[colNum] && err == nil {
+						colStr = string(doc.([]byte))
+					} else {
+						colStr = sqlColToStr(col)
+						colStr = strings.Replace(colStr, "\"", "\\\"", -1)
+					}
 					str := fmt.Sprintf(formats[colNum], colStr)
 					sb.WriteString(str)
 				}
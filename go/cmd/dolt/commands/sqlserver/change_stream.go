@@ -0,0 +1,103 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/cdc"
+	dsqle "github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+)
+
+// changeStreamPath is the path of the HTTP endpoint which streams changes
+const changeStreamPath = "/changes"
+
+// changeStreamShutdownTimeout is how long HTTP clients are given to receive the last changes when the server stops
+const changeStreamShutdownTimeout = 5 * time.Second
+
+// startChangeStream starts streaming the row level changes made by moving the heads of the branches of |dbs| to the
+// file and HTTP listener configured by |cfg|. If neither is configured the returned stream is nil. The returned
+// function writes the changes which are queued and stops the stream.
+func startChangeStream(cfg ServerConfig, dbs []dsqle.Database) (*cdc.Stream, func() error, error) {
+	var sinks []cdc.Sink
+	closeSinks := func() {
+		for _, sink := range sinks {
+			_ = sink.Close()
+		}
+	}
+
+	if path := cfg.ChangeStreamFile(); path != "" {
+		fileSink, err := cdc.NewFileSink(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		sinks = append(sinks, fileSink)
+	}
+
+	var srv *http.Server
+	if cfg.ChangeStreamPort() != -1 {
+		hostPort := net.JoinHostPort(cfg.ChangeStreamHost(), strconv.Itoa(cfg.ChangeStreamPort()))
+		l, err := net.Listen("tcp", hostPort)
+		if err != nil {
+			closeSinks()
+			return nil, nil, err
+		}
+
+		httpSink := cdc.NewHTTPSink()
+		sinks = append(sinks, httpSink)
+
+		mux := http.NewServeMux()
+		mux.Handle(changeStreamPath, httpSink)
+		srv = &http.Server{Handler: mux}
+		go func() {
+			_ = srv.Serve(l)
+		}()
+	}
+
+	if len(sinks) == 0 {
+		return nil, func() error { return nil }, nil
+	}
+
+	stream := cdc.NewStream(func(err error) {
+		logrus.Errorf("Change stream: %v", err)
+	}, sinks...)
+
+	for _, db := range dbs {
+		stream.Watch(db.Name(), db.GetDoltDB())
+	}
+
+	return stream, func() error {
+		// closing the stream ends the responses of the HTTP clients, which are given time to finish before the listener
+		// is closed
+		err := stream.Close()
+
+		if srv != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), changeStreamShutdownTimeout)
+			defer cancel()
+
+			if serr := srv.Shutdown(ctx); serr != nil {
+				_ = srv.Close()
+			}
+		}
+
+		return err
+	}, nil
+}
@@ -0,0 +1,480 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/dolthub/vitess/go/vt/proto/query"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/sirupsen/logrus"
+
+	dsqle "github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/privileges"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+)
+
+// Handler is a mysql.Handler which runs account management statements against the privilege store of the server and
+// statements declaring check constraints, which the SQL parser does not support, itself. Everything else is passed to
+// the go-mysql-server handler, after adding any revision databases the query uses to the session. The connections and
+// queries it handles are recorded in the server's metrics, and queries which take longer than the slow query threshold
+// are logged to the slow query log if there is one. SELECT statements which run for longer than the max_execution_time
+// of their session are killed.
+type Handler struct {
+	*server.Handler
+	sm        *server.SessionManager
+	engine    *sqle.Engine
+	catalog   *sql.Catalog
+	privAuth  *privileges.Auth
+	revisions *revisionDatabases
+	metrics   *serverMetrics
+	slowLog   *slowQueryLog
+	limits    *queryLimits
+
+	mu    *sync.Mutex
+	conns map[uint32]*mysql.Conn
+}
+
+var _ mysql.Handler = (*Handler)(nil)
+
+// NewHandler returns a Handler wrapping |h|, which must have been created with the session manager |sm| and the
+// engine |e|. |slowLog| may be nil.
+func NewHandler(h *server.Handler, sm *server.SessionManager, e *sqle.Engine, privAuth *privileges.Auth, revisions *revisionDatabases, metrics *serverMetrics, slowLog *slowQueryLog, limits *queryLimits) *Handler {
+	return &Handler{
+		Handler:   h,
+		sm:        sm,
+		engine:    e,
+		catalog:   e.Catalog,
+		privAuth:  privAuth,
+		revisions: revisions,
+		metrics:   metrics,
+		slowLog:   slowLog,
+		limits:    limits,
+		mu:        &sync.Mutex{},
+		conns:     make(map[uint32]*mysql.Conn),
+	}
+}
+
+// NewConnection implements mysql.Handler.
+func (h *Handler) NewConnection(c *mysql.Conn) {
+	h.mu.Lock()
+	h.conns[c.ConnectionID] = c
+	h.mu.Unlock()
+
+	h.metrics.connectionOpened(c.ConnectionID)
+	h.Handler.NewConnection(c)
+}
+
+// ConnectionClosed implements mysql.Handler.
+func (h *Handler) ConnectionClosed(c *mysql.Conn) {
+	h.mu.Lock()
+	delete(h.conns, c.ConnectionID)
+	h.mu.Unlock()
+
+	h.metrics.connectionClosed(c.ConnectionID)
+	h.Handler.ConnectionClosed(c)
+}
+
+// ComInitDB implements mysql.Handler. Users may only select databases they hold a privilege on.
+func (h *Handler) ComInitDB(c *mysql.Conn, schemaName string) error {
+	if err := h.privAuth.RequiresDatabaseAccess(c.User, schemaName); err != nil {
+		return mysql.NewSQLError(mysql.ERDBAccessDenied, mysql.SSAccessDeniedError, "%s", err.Error())
+	}
+
+	ctx, err := h.sm.NewContext(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.addRevisionDatabases(ctx, []string{schemaName}); err != nil {
+		return mysql.NewSQLError(mysql.ERBadDb, mysql.SSUnknownSQLState, "%s", err.Error())
+	}
+
+	return h.Handler.ComInitDB(c, schemaName)
+}
+
+// ComQuery implements mysql.Handler.
+func (h *Handler) ComQuery(c *mysql.Conn, q string, callback func(*sqltypes.Result) error) error {
+	var rowsSent, rowsAffected uint64
+	countingCallback := func(res *sqltypes.Result) error {
+		rowsSent += uint64(len(res.Rows))
+		rowsAffected += res.RowsAffected
+		return callback(res)
+	}
+
+	start := time.Now()
+	err := h.comQuery(c, q, countingCallback)
+	h.metrics.queryFinished(start, err)
+
+	if duration := time.Since(start); h.slowLog != nil && h.slowLog.isSlow(duration) {
+		database, branch := h.currentDatabaseAndBranch(c)
+		h.slowLog.log(slowQuery{
+			query:        q,
+			user:         c.User,
+			database:     database,
+			branch:       branch,
+			rowsSent:     rowsSent,
+			rowsAffected: rowsAffected,
+			duration:     duration,
+			err:          err,
+		})
+	}
+
+	return err
+}
+
+// currentDatabaseAndBranch returns the current database of the session of |c|, and the branch that database is on.
+// The branch is "" if there is no current database or it is bound to a commit.
+func (h *Handler) currentDatabaseAndBranch(c *mysql.Conn) (database string, branch string) {
+	ctx, err := h.sm.NewContext(c)
+	if err != nil {
+		return "", ""
+	}
+
+	database = ctx.GetCurrentDatabase()
+	if rsr, ok := dsqle.DSessFromSess(ctx.Session).GetDoltDBRepoStateReader(database); ok {
+		if headRef := rsr.CWBHeadRef(); headRef != nil {
+			branch = headRef.GetPath()
+		}
+	}
+
+	return database, branch
+}
+
+func (h *Handler) comQuery(c *mysql.Conn, q string, callback func(*sqltypes.Result) error) error {
+	if name, val, ok := parseSetGlobalLimit(q); ok {
+		if !h.privAuth.Store().IsSuperUser(c.User) {
+			return mysql.NewSQLError(mysql.ERSpecifiedAccessDenied, mysql.SSAccessDeniedError, "Access denied; only the super user may set the global value of %s", name)
+		}
+
+		h.limits.setGlobal(name, val)
+		return callback(&sqltypes.Result{})
+	}
+
+	if connID, ok := parseKill(q); ok {
+		if err := h.checkKill(c, connID); err != nil {
+			return err
+		}
+	}
+
+	checkStmt, err := dsqle.ParseCheckConstraintStatement(q)
+	if err != nil {
+		return err
+	} else if checkStmt != nil {
+		// the parser doesn't support the statement, so the session is readied with the CREATE TABLE statement it
+		// runs, if any
+		checkQuery := q
+		if checkStmt.CreatesTable {
+			checkQuery = checkStmt.Query
+		}
+		if err := h.prepareSession(c, checkQuery); err != nil {
+			return err
+		}
+		if err := h.executeCheckStatement(c, checkQuery, checkStmt); err != nil {
+			return err
+		}
+		return callback(&sqltypes.Result{})
+	}
+
+	stmt, err := privileges.ParseAccountStatement(q)
+	if err != nil {
+		return err
+	} else if stmt == nil {
+		q = quoteRevisionUse(q)
+		if err := h.prepareSession(c, q); err != nil {
+			return err
+		}
+
+		if isShowDatabases(q) {
+			callback = h.revisions.filterHidden(callback)
+		}
+
+		return h.runQuery(c, q, callback)
+	}
+
+	result, err := h.executeAccountStatement(c, stmt)
+	logAccountStatement(c, stmt, err)
+	if err != nil {
+		if errors.Is(err, privileges.ErrAccessDenied) {
+			return mysql.NewSQLError(mysql.ERSpecifiedAccessDenied, mysql.SSAccessDeniedError, "%s", err.Error())
+		}
+		return err
+	}
+
+	return callback(result)
+}
+
+// runQuery passes |q| to the go-mysql-server handler. If |q| runs for longer than the execution time limit of the
+// session it is killed, which cancels the context its rows are read with.
+func (h *Handler) runQuery(c *mysql.Conn, q string, callback func(*sqltypes.Result) error) error {
+	ctx, err := h.sm.NewContext(c)
+	if err != nil {
+		return err
+	}
+
+	var timedOut int32
+	if timeout := executionTimeout(ctx, q); timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			atomic.StoreInt32(&timedOut, 1)
+			h.catalog.KillOnlyQueries(c.ConnectionID)
+		})
+		defer timer.Stop()
+	}
+
+	// the error of a row iterator which stops reading rows can be dropped when the rows of a table are read in
+	// parallel, so the limits are checked again before each batch of results is sent
+	counter, _ := ctx.Session.(sqlutil.QueryRowCounter)
+	maxRows := sqlutil.MaxQueryRows(ctx.Session)
+	var prevPid uint64
+	if counter != nil {
+		prevPid, _ = counter.QueryRowsRead()
+	}
+
+	limitedCallback := func(res *sqltypes.Result) error {
+		if atomic.LoadInt32(&timedOut) == 1 {
+			return sqlutil.ErrQueryInterrupted.New()
+		}
+
+		if counter != nil && maxRows > 0 {
+			if pid, rows := counter.QueryRowsRead(); pid != prevPid && rows > maxRows {
+				return sqlutil.ErrQueryRowLimitExceeded.New(maxRows)
+			}
+		}
+
+		return callback(res)
+	}
+
+	err = h.Handler.ComQuery(c, q, limitedCallback)
+	return interruptedQueryError(err, atomic.LoadInt32(&timedOut) == 1)
+}
+
+// executeCheckStatement runs the statement declaring or dropping check constraints |stmt|, which is the query |query|.
+// A CREATE TABLE statement is authorized like any other, and an ALTER TABLE statement requires the ALTER privilege on
+// its table. The session's transaction is committed afterwards if autocommit is on, as is done for the DDL statements
+// the go-mysql-server handler runs.
+func (h *Handler) executeCheckStatement(c *mysql.Conn, query string, stmt *dsqle.CheckConstraintStatement) error {
+	ctx, err := h.sm.NewContextWithQuery(c, query)
+	if err != nil {
+		return err
+	}
+
+	dbName := stmt.Database
+	if dbName == "" {
+		dbName = ctx.GetCurrentDatabase()
+	}
+	if dbName == "" {
+		return sql.ErrNoDatabaseSelected.New()
+	}
+
+	if stmt.CreatesTable {
+		err = h.engine.Auth.Allowed(ctx, auth.ReadPerm|auth.WritePerm)
+	} else {
+		err = h.privAuth.RequiresTablePrivileges(ctx.Client().User, dbName, stmt.TableName, privileges.AlterPriv)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := h.addRevisionDatabases(ctx, []string{dbName}); err != nil {
+		return err
+	}
+
+	catalogDB, err := h.catalog.Database(dbName)
+	if err != nil {
+		return err
+	}
+	db, ok := catalogDB.(dsqle.Database)
+	if !ok {
+		return fmt.Errorf("database %s does not support check constraints", dbName)
+	}
+
+	if err := dsqle.ExecuteCheckConstraintStatement(ctx, h.engine, db, stmt); err != nil {
+		return err
+	}
+
+	if dsess := dsqle.DSessFromSess(ctx.Session); !dsess.InTransaction() && isAutocommit(ctx) {
+		return dsess.CommitTransaction(ctx)
+	}
+
+	return nil
+}
+
+// isAutocommit returns whether autocommit is on for the session of |ctx|
+func isAutocommit(ctx *sql.Context) bool {
+	_, val := ctx.Get(sql.AutoCommitSessionVar)
+	if val == nil {
+		return false
+	}
+
+	autocommit, err := sql.ConvertToBool(val)
+	return err == nil && autocommit
+}
+
+// checkKill returns an error if the user of |c| may not kill the query or connection with the id |connID|. As in
+// MySQL, users may kill their own queries and connections, and the super user may kill any of them.
+func (h *Handler) checkKill(c *mysql.Conn, connID uint32) error {
+	h.mu.Lock()
+	target, ok := h.conns[connID]
+	h.mu.Unlock()
+
+	if !ok {
+		return mysql.NewSQLError(mysql.ERNoSuchThread, mysql.SSUnknownSQLState, "Unknown thread id: %d", connID)
+	}
+
+	if target.User != c.User && !h.privAuth.Store().IsSuperUser(c.User) {
+		return mysql.NewSQLError(mysql.ERKillDenied, mysql.SSUnknownSQLState, "You are not owner of thread %d", connID)
+	}
+
+	return nil
+}
+
+// prepareSession readies the session of |c| to run |query|. The revision databases the query uses are added to the
+// session, using databases which have been removed from the server is an error, and BEGIN and ROLLBACK start and roll
+// back the session's transaction. Other statements run outside of a transaction see the changes committed to each
+// database by other sessions.
+func (h *Handler) prepareSession(c *mysql.Conn, query string) error {
+	ctx, err := h.sm.NewContextWithQuery(c, query)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := sqlparser.Parse(query)
+	if err == nil {
+		if err := h.addRevisionDatabases(ctx, referencedDatabases(parsed)); err != nil {
+			return err
+		}
+	}
+
+	// the current database may have been removed from the server since it was selected, in which case the session
+	// may only select another one
+	if _, isUse := parsed.(*sqlparser.Use); !isUse {
+		if current := ctx.GetCurrentDatabase(); current != "" && h.revisions.isHidden(current) {
+			return sql.ErrDatabaseNotFound.New(current)
+		}
+	}
+
+	dsess := dsqle.DSessFromSess(ctx.Session)
+	switch parsed.(type) {
+	case *sqlparser.Begin:
+		// starting a transaction implicitly commits the open one
+		if dsess.InTransaction() {
+			if err := dsess.CommitTransaction(ctx); err != nil {
+				return err
+			}
+		}
+		return dsess.BeginTransaction(ctx)
+	case *sqlparser.Rollback:
+		return dsess.RollbackTransaction(ctx)
+	case *sqlparser.Commit:
+		return nil
+	default:
+		if dsess.InTransaction() {
+			return nil
+		}
+		return dsess.StartTransaction(ctx)
+	}
+}
+
+// addRevisionDatabases adds the revision databases among |dbNames| to the session of |ctx|
+func (h *Handler) addRevisionDatabases(ctx *sql.Context, dbNames []string) error {
+	for _, name := range dbNames {
+		if err := h.revisions.addToSession(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *Handler) executeAccountStatement(c *mysql.Conn, stmt *privileges.AccountStatement) (*sqltypes.Result, error) {
+	if stmt.ModifiesAccounts() && h.privAuth.ReadOnly() {
+		return nil, fmt.Errorf("%w; the server is read only", privileges.ErrAccessDenied)
+	}
+
+	ctx, err := h.sm.NewContext(c)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := h.privAuth.Store().Execute(c.User, ctx.GetCurrentDatabase(), stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	if stmt.Kind != privileges.ShowGrantsStmt {
+		return &sqltypes.Result{}, nil
+	}
+
+	result := &sqltypes.Result{
+		Fields: []*query.Field{{
+			Name: fmt.Sprintf("Grants for %s@%%", stmt.ShowGrantsUser(c.User)),
+			Type: sqltypes.VarChar,
+		}},
+		RowsAffected: uint64(len(grants)),
+	}
+	for _, grant := range grants {
+		result.Rows = append(result.Rows, []sqltypes.Value{sqltypes.NewVarChar(grant)})
+	}
+
+	return result, nil
+}
+
+// logAccountStatement records the account management statement |stmt| in the audit log
+func logAccountStatement(c *mysql.Conn, stmt *privileges.AccountStatement, err error) {
+	fields := logrus.Fields{
+		"system":        "audit",
+		"action":        "account",
+		"user":          c.User,
+		"address":       c.RemoteAddr().String(),
+		"connection_id": c.ConnectionID,
+		"statement":     stmt.String(),
+		"success":       true,
+	}
+
+	if err != nil {
+		fields["success"] = false
+		fields["err"] = err
+	}
+
+	logrus.WithFields(fields).Info("audit trail")
+}
+
+// bootstrapPrivileges runs the account management statements in |statements| as the super user of |store|.
+func bootstrapPrivileges(store *privileges.Store, superUser string, statements []string) error {
+	for _, query := range statements {
+		stmt, err := privileges.ParseAccountStatement(query)
+		if err != nil {
+			return err
+		} else if stmt == nil {
+			return fmt.Errorf("bootstrap files may only contain account management statements: %s", query)
+		}
+
+		if _, err := store.Execute(superUser, "", stmt); err != nil {
+			return fmt.Errorf("error running bootstrap statement '%s': %v", stmt.String(), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/server"
+)
+
+// maxConnectionsWait is how long Accept waits before checking again whether a connection may be accepted when the
+// server has the maximum number of connections open
+const maxConnectionsWait = 100 * time.Millisecond
+
+var errListenerClosed = errors.New("listener closed")
+
+type acceptedConn struct {
+	conn net.Conn
+	err  error
+}
+
+// serverListener is the net.Listener that the server accepts connections from. Unlike the listener the server would
+// otherwise create, its address, connection limit and timeouts may be changed while the server runs. When the address
+// changes the server starts listening on the new address and stops accepting connections on the old one, but the
+// connections already open are served until the clients close them.
+type serverListener struct {
+	handler *server.Handler
+
+	mu       *sync.Mutex
+	protocol string
+	address  string
+	socket   net.Listener
+
+	accepted chan acceptedConn
+	closed   chan struct{}
+	once     *sync.Once
+
+	active       int64
+	maxConns     uint64
+	readTimeout  int64
+	writeTimeout int64
+}
+
+var _ net.Listener = (*serverListener)(nil)
+
+// newServerListener listens on |address|. Each connection accepted is added to |handler|.
+func newServerListener(protocol, address string, handler *server.Handler, maxConns uint64, readTimeout, writeTimeout time.Duration) (*serverListener, error) {
+	socket, err := net.Listen(protocol, address)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &serverListener{
+		handler:  handler,
+		mu:       &sync.Mutex{},
+		protocol: protocol,
+		address:  address,
+		socket:   socket,
+		accepted: make(chan acceptedConn),
+		closed:   make(chan struct{}),
+		once:     &sync.Once{},
+	}
+	l.SetMaxConnections(maxConns)
+	l.SetTimeouts(readTimeout, writeTimeout)
+
+	go l.acceptLoop(socket)
+	return l, nil
+}
+
+// acceptLoop accepts connections on |socket| until it is closed
+func (l *serverListener) acceptLoop(socket net.Listener) {
+	for {
+		conn, err := socket.Accept()
+		if err != nil {
+			l.mu.Lock()
+			replaced := l.socket != socket
+			l.mu.Unlock()
+
+			if replaced {
+				return
+			}
+		}
+
+		select {
+		case l.accepted <- acceptedConn{conn, err}:
+			if err != nil {
+				return
+			}
+		case <-l.closed:
+			if conn != nil {
+				_ = conn.Close()
+			}
+			return
+		}
+	}
+}
+
+// Accept implements net.Listener. While the maximum number of connections are open it waits for one to close.
+func (l *serverListener) Accept() (net.Conn, error) {
+	for {
+		maxConns := atomic.LoadUint64(&l.maxConns)
+		if maxConns == 0 || uint64(atomic.LoadInt64(&l.active)) < maxConns {
+			break
+		}
+
+		select {
+		case <-l.closed:
+			return nil, errListenerClosed
+		case <-time.After(maxConnectionsWait):
+		}
+	}
+
+	select {
+	case <-l.closed:
+		return nil, errListenerClosed
+	case ac := <-l.accepted:
+		if ac.err != nil {
+			return nil, ac.err
+		}
+
+		atomic.AddInt64(&l.active, 1)
+
+		// the handler checks whether the socket of a connection running a query has been closed, so it gets the socket
+		// rather than the wrapped connection
+		socketConn := ac.conn
+		l.handler.AddNetConnection(&socketConn)
+
+		return &serverConn{Conn: ac.conn, l: l, once: &sync.Once{}}, nil
+	}
+}
+
+// Close implements net.Listener. Connections which are already open are not closed.
+func (l *serverListener) Close() error {
+	var err error
+	l.once.Do(func() {
+		close(l.closed)
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		err = l.socket.Close()
+	})
+	return err
+}
+
+// Addr implements net.Listener
+func (l *serverListener) Addr() net.Addr {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.socket.Addr()
+}
+
+// Listen returns a socket listening on |address|, for the listener to move to with SetSocket. The socket is nil if the
+// listener is already listening on |address|.
+func (l *serverListener) Listen(address string) (net.Listener, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	select {
+	case <-l.closed:
+		return nil, errListenerClosed
+	default:
+	}
+
+	if address == l.address {
+		return nil, nil
+	}
+
+	return net.Listen(l.protocol, address)
+}
+
+// SetSocket starts accepting connections from |socket|, which listens on |address|, and stops listening on the previous
+// address. If the listener has been closed |socket| is closed instead.
+func (l *serverListener) SetSocket(address string, socket net.Listener) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	select {
+	case <-l.closed:
+		_ = socket.Close()
+		return errListenerClosed
+	default:
+	}
+
+	prev := l.socket
+	l.socket = socket
+	l.address = address
+	go l.acceptLoop(socket)
+
+	return prev.Close()
+}
+
+// SetMaxConnections sets the maximum number of connections which may be open at once. 0 means there is no limit.
+func (l *serverListener) SetMaxConnections(maxConns uint64) {
+	atomic.StoreUint64(&l.maxConns, maxConns)
+}
+
+// SetTimeouts sets the read and write timeouts of every connection, including those already open. 0 means there is
+// no timeout.
+func (l *serverListener) SetTimeouts(readTimeout, writeTimeout time.Duration) {
+	atomic.StoreInt64(&l.readTimeout, int64(readTimeout))
+	atomic.StoreInt64(&l.writeTimeout, int64(writeTimeout))
+}
+
+// serverConn is a connection accepted by a serverListener, which applies the listener's current timeouts to each read
+// and write.
+type serverConn struct {
+	net.Conn
+	l    *serverListener
+	once *sync.Once
+}
+
+// Read implements net.Conn
+func (c *serverConn) Read(b []byte) (int, error) {
+	if timeout := time.Duration(atomic.LoadInt64(&c.l.readTimeout)); timeout != 0 {
+		if err := c.Conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return 0, err
+		}
+	}
+	return c.Conn.Read(b)
+}
+
+// Write implements net.Conn
+func (c *serverConn) Write(b []byte) (int, error) {
+	if timeout := time.Duration(atomic.LoadInt64(&c.l.writeTimeout)); timeout != 0 {
+		if err := c.Conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+			return 0, err
+		}
+	}
+	return c.Conn.Write(b)
+}
+
+// Close implements net.Conn
+func (c *serverConn) Close() error {
+	c.once.Do(func() {
+		atomic.AddInt64(&c.l.active, -1)
+	})
+	return c.Conn.Close()
+}
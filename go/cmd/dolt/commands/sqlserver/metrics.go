@@ -0,0 +1,391 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"bytes"
+	"math"
+	"net"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	dsqle "github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/metrics"
+	"github.com/dolthub/dolt/go/store/nbs"
+)
+
+const metricsPath = "/metrics"
+
+var metricLabelNameRegex = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+
+// queryLatencyBuckets are the upper bounds, in seconds, of the buckets of the query latency histogram
+var queryLatencyBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// connectionDurationBuckets are the upper bounds, in seconds, of the buckets of the connection duration histogram
+var connectionDurationBuckets = []float64{1, 10, 60, 300, 900, 1800, 3600, 4 * 3600, 8 * 3600, 24 * 3600}
+
+func isValidMetricLabelName(name string) bool {
+	return metricLabelNameRegex.MatchString(name) && !strings.HasPrefix(name, "__")
+}
+
+// serverMetrics collects the connection, query and transaction commit metrics of a running server, and writes them
+// along with the chunk store statistics of each database being served in the Prometheus text exposition format.
+type serverMetrics struct {
+	catalog *sql.Catalog
+	labels  map[string]string
+
+	activeConnections int64
+	connections       uint64
+	queries           uint64
+	queryErrors       uint64
+
+	mu                 *sync.Mutex
+	connStarts         map[uint32]time.Time
+	connectionDuration *histogram
+	queryLatency       *histogram
+	commits            map[string]uint64
+	commitFailures     map[string]uint64
+}
+
+func newServerMetrics(catalog *sql.Catalog, labels map[string]string) *serverMetrics {
+	return &serverMetrics{
+		catalog:            catalog,
+		labels:             labels,
+		mu:                 &sync.Mutex{},
+		connStarts:         make(map[uint32]time.Time),
+		connectionDuration: newHistogram(connectionDurationBuckets),
+		queryLatency:       newHistogram(queryLatencyBuckets),
+		commits:            make(map[string]uint64),
+		commitFailures:     make(map[string]uint64),
+	}
+}
+
+func (m *serverMetrics) connectionOpened(connID uint32) {
+	atomic.AddInt64(&m.activeConnections, 1)
+	atomic.AddUint64(&m.connections, 1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.connStarts[connID] = time.Now()
+}
+
+func (m *serverMetrics) connectionClosed(connID uint32) {
+	atomic.AddInt64(&m.activeConnections, -1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if start, ok := m.connStarts[connID]; ok {
+		delete(m.connStarts, connID)
+		m.connectionDuration.observe(time.Since(start).Seconds())
+	}
+}
+
+func (m *serverMetrics) queryFinished(start time.Time, err error) {
+	atomic.AddUint64(&m.queries, 1)
+	if err != nil {
+		atomic.AddUint64(&m.queryErrors, 1)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queryLatency.observe(time.Since(start).Seconds())
+}
+
+// transactionCommitted is the dsqle.CommitListener of every session of the server
+func (m *serverMetrics) transactionCommitted(dbName string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err != nil {
+		m.commitFailures[dbName]++
+	} else {
+		m.commits[dbName]++
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (m *serverMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != metricsPath {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(m.export())
+}
+
+// export returns all metrics in the Prometheus text exposition format
+func (m *serverMetrics) export() []byte {
+	mw := newMetricsWriter(m.labels)
+
+	mw.header("dolt_sql_server_connections", "Number of currently open client connections.", "gauge")
+	mw.sample("dolt_sql_server_connections", float64(atomic.LoadInt64(&m.activeConnections)))
+	mw.header("dolt_sql_server_connections_total", "Number of client connections opened since the server started.", "counter")
+	mw.sample("dolt_sql_server_connections_total", float64(atomic.LoadUint64(&m.connections)))
+	mw.header("dolt_sql_server_queries_total", "Number of queries run since the server started.", "counter")
+	mw.sample("dolt_sql_server_queries_total", float64(atomic.LoadUint64(&m.queries)))
+	mw.header("dolt_sql_server_query_errors_total", "Number of queries which returned an error.", "counter")
+	mw.sample("dolt_sql_server_query_errors_total", float64(atomic.LoadUint64(&m.queryErrors)))
+
+	m.mu.Lock()
+	mw.header("dolt_sql_server_connection_duration_seconds", "How long closed client connections were open.", "histogram")
+	mw.histogram("dolt_sql_server_connection_duration_seconds", m.connectionDuration)
+	mw.header("dolt_sql_server_query_latency_seconds", "How long queries took to run.", "histogram")
+	mw.histogram("dolt_sql_server_query_latency_seconds", m.queryLatency)
+	mw.header("dolt_sql_server_transaction_commits_total", "Number of transactions committed to each database.", "counter")
+	for _, dbName := range sortedKeys(m.commits) {
+		mw.sample("dolt_sql_server_transaction_commits_total", float64(m.commits[dbName]), "database", dbName)
+	}
+	mw.header("dolt_sql_server_transaction_commit_failures_total", "Number of transactions rolled back because they conflicted with another transaction.", "counter")
+	for _, dbName := range sortedKeys(m.commitFailures) {
+		mw.sample("dolt_sql_server_transaction_commit_failures_total", float64(m.commitFailures[dbName]), "database", dbName)
+	}
+	m.mu.Unlock()
+
+	m.exportChunkStoreStats(mw)
+
+	return mw.buf.Bytes()
+}
+
+// exportChunkStoreStats writes the statistics gathered by the chunk stores of the databases in the catalog. Revision
+// databases share the chunk store of the database they are a revision of, and are skipped.
+func (m *serverMetrics) exportChunkStoreStats(mw *metricsWriter) {
+	csMetrics := make(map[string]chunks.CSMetrics)
+	seen := make(map[*doltdb.DoltDB]bool)
+	for _, db := range dbsAsDSQLDBs(m.catalog.AllDatabases()) {
+		if _, _, ok := dsqle.SplitRevisionDbName(db.Name()); ok || seen[db.GetDoltDB()] {
+			continue
+		}
+		seen[db.GetDoltDB()] = true
+
+		if stats, ok := db.GetDoltDB().CSMetrics().(chunks.CSMetrics); ok {
+			csMetrics[db.Name()] = stats
+		}
+	}
+
+	dbNames := make([]string, 0, len(csMetrics))
+	for dbName := range csMetrics {
+		dbNames = append(dbNames, dbName)
+	}
+	sort.Strings(dbNames)
+
+	counters := []struct {
+		name string
+		help string
+		val  func(chunks.CSMetrics) int32
+	}{
+		{"dolt_chunk_store_gets_total", "Number of chunks read from the chunk store of each database.", func(s chunks.CSMetrics) int32 { return s.TotalChunkGets }},
+		{"dolt_chunk_store_has_checks_total", "Number of chunks checked for in the chunk store of each database.", func(s chunks.CSMetrics) int32 { return s.TotalChunkHasChecks }},
+		{"dolt_chunk_store_puts_total", "Number of chunks written to the chunk store of each database.", func(s chunks.CSMetrics) int32 { return s.TotalChunkPuts }},
+	}
+	for _, c := range counters {
+		mw.header(c.name, c.help, "counter")
+		for _, dbName := range dbNames {
+			mw.sample(c.name, float64(c.val(csMetrics[dbName])), "database", dbName)
+		}
+	}
+
+	var nbsDbNames []string
+	nbsStats := make(map[string]reflect.Value)
+	for _, dbName := range dbNames {
+		if stats, ok := csMetrics[dbName].Delegate.(nbs.Stats); ok {
+			nbsDbNames = append(nbsDbNames, dbName)
+			nbsStats[dbName] = reflect.ValueOf(stats)
+		}
+	}
+	if len(nbsDbNames) == 0 {
+		return
+	}
+
+	statsType := reflect.TypeOf(nbs.Stats{})
+	histType := reflect.TypeOf(metrics.Histogram{})
+	for i := 0; i < statsType.NumField(); i++ {
+		field := statsType.Field(i)
+		if field.Type != histType {
+			continue
+		}
+
+		// time histograms are sampled in nanoseconds and exported in seconds
+		name := "dolt_nbs_" + toSnakeCase(field.Name)
+		help := "The " + field.Name + " statistic of the noms block store of each database."
+		scale := 1.0
+		if nbsStats[nbsDbNames[0]].Field(i).Interface().(metrics.Histogram).Type() == metrics.TimeHistogram {
+			name += "_seconds"
+			help = "The " + field.Name + " statistic of the noms block store of each database in seconds."
+			scale = float64(time.Second)
+		}
+
+		mw.header(name, help, "histogram")
+		for _, dbName := range nbsDbNames {
+			h := nbsStats[dbName].Field(i).Interface().(metrics.Histogram)
+			mw.histogram(name, log2Histogram(h, scale), "database", dbName)
+		}
+	}
+}
+
+// log2Histogram converts |h|, whose bucket i holds the samples in the range [2^i, 2^(i+1)), to a histogram with the
+// bucket boundaries of |h| divided by |scale|. Buckets above the highest bucket with samples are omitted.
+func log2Histogram(h metrics.Histogram, scale float64) *histogram {
+	counts := h.BucketCounts()
+	last := -1
+	for i, n := range counts {
+		if n > 0 {
+			last = i
+		}
+	}
+
+	bounds := make([]float64, last+1)
+	for i := range bounds {
+		bounds[i] = float64(uint64(1)<<uint(i+1)-1) / scale
+	}
+
+	converted := newHistogram(bounds)
+	copy(converted.counts, counts[:last+1])
+	converted.sum = float64(h.Sum()) / scale
+	return converted
+}
+
+// histogram counts observations in buckets with fixed upper bounds
+type histogram struct {
+	bounds []float64
+	// counts[i] is the number of observations which are > bounds[i-1] and <= bounds[i]. The last count is the number
+	// of observations greater than every bound.
+	counts []uint64
+	sum    float64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(v float64) {
+	h.counts[sort.SearchFloat64s(h.bounds, v)]++
+	h.sum += v
+}
+
+// metricsWriter writes metrics in the Prometheus text exposition format
+type metricsWriter struct {
+	buf         *bytes.Buffer
+	constLabels []string
+}
+
+func newMetricsWriter(labels map[string]string) *metricsWriter {
+	var constLabels []string
+	for _, name := range sortedKeys(labels) {
+		constLabels = append(constLabels, name, labels[name])
+	}
+
+	return &metricsWriter{buf: &bytes.Buffer{}, constLabels: constLabels}
+}
+
+func (mw *metricsWriter) header(name, help, metricType string) {
+	mw.buf.WriteString("# HELP " + name + " " + help + "\n")
+	mw.buf.WriteString("# TYPE " + name + " " + metricType + "\n")
+}
+
+// sample writes a single sample of the metric |name|. |labels| are label names alternating with their values.
+func (mw *metricsWriter) sample(name string, val float64, labels ...string) {
+	mw.buf.WriteString(name)
+
+	labels = append(append([]string{}, mw.constLabels...), labels...)
+	if len(labels) > 0 {
+		mw.buf.WriteString("{")
+		for i := 0; i < len(labels); i += 2 {
+			if i > 0 {
+				mw.buf.WriteString(",")
+			}
+			mw.buf.WriteString(labels[i] + "=\"" + escapeLabelValue(labels[i+1]) + "\"")
+		}
+		mw.buf.WriteString("}")
+	}
+
+	mw.buf.WriteString(" " + formatMetricValue(val) + "\n")
+}
+
+func (mw *metricsWriter) histogram(name string, h *histogram, labels ...string) {
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		mw.sample(name+"_bucket", float64(cumulative), append(labels, "le", formatMetricValue(bound))...)
+	}
+	cumulative += h.counts[len(h.bounds)]
+	mw.sample(name+"_bucket", float64(cumulative), append(labels, "le", "+Inf")...)
+	mw.sample(name+"_sum", h.sum, labels...)
+	mw.sample(name+"_count", float64(cumulative), labels...)
+}
+
+func formatMetricValue(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelValueReplacer = strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n")
+
+func escapeLabelValue(v string) string {
+	return labelValueReplacer.Replace(v)
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toSnakeCase converts a CamelCase name like BytesPerPersist to bytes_per_persist
+func toSnakeCase(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// startMetricsListener serves the metrics of the server over HTTP at |hostPort|. The returned function stops the
+// listener.
+func startMetricsListener(hostPort string, m *serverMetrics) (func() error, error) {
+	l, err := net.Listen("tcp", hostPort)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &http.Server{Handler: m}
+	go func() {
+		_ = srv.Serve(l)
+	}()
+
+	return srv.Close, nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dolthub/dolt/go/store/metrics"
+)
+
+func TestMetricsWriterHistogram(t *testing.T) {
+	h := newHistogram([]float64{1, 5})
+	h.observe(0.5)
+	h.observe(1)
+	h.observe(3)
+	h.observe(10)
+
+	mw := newMetricsWriter(map[string]string{"b": "2", "a": "quote\"d"})
+	mw.header("latency_seconds", "Latency.", "histogram")
+	mw.histogram("latency_seconds", h, "database", "db")
+
+	expected := `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{a="quote\"d",b="2",database="db",le="1"} 2
+latency_seconds_bucket{a="quote\"d",b="2",database="db",le="5"} 3
+latency_seconds_bucket{a="quote\"d",b="2",database="db",le="+Inf"} 4
+latency_seconds_sum{a="quote\"d",b="2",database="db"} 14.5
+latency_seconds_count{a="quote\"d",b="2",database="db"} 4
+`
+	assert.Equal(t, expected, mw.buf.String())
+}
+
+func TestLog2Histogram(t *testing.T) {
+	h := metrics.NewTimeHistogram()
+	h.Sample(1)
+	h.Sample(3)
+	h.Sample(6)
+
+	converted := log2Histogram(h, 1)
+	assert.Equal(t, []float64{1, 3, 7}, converted.bounds)
+	assert.Equal(t, []uint64{1, 1, 1, 0}, converted.counts)
+	assert.Equal(t, float64(10), converted.sum)
+
+	empty := log2Histogram(metrics.NewTimeHistogram(), 1e9)
+	assert.Empty(t, empty.bounds)
+	assert.Equal(t, []uint64{0}, empty.counts)
+}
+
+func TestToSnakeCase(t *testing.T) {
+	assert.Equal(t, "get_latency", toSnakeCase("GetLatency"))
+	assert.Equal(t, "bytes_per_persist", toSnakeCase("BytesPerPersist"))
+}
@@ -0,0 +1,155 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+)
+
+const (
+	maxExecutionTimeSessionVar = "max_execution_time"
+
+	// erQueryTimeout is the MySQL error returned for statements which run for longer than max_execution_time
+	erQueryTimeout = 3024
+	// ssQueryInterrupted is the SQLSTATE of the MySQL error returned for interrupted statements
+	ssQueryInterrupted = "70100"
+)
+
+var setGlobalLimitRegex = regexp.MustCompile(`(?i)^\s*set\s+(?:global\s+|@@global\.)(max_execution_time|dolt_max_query_rows)\s*=\s*(\d+)\s*;?\s*$`)
+var killRegex = regexp.MustCompile(`(?i)^\s*kill\s+(?:query\s+|connection\s+)?(\d+)\s*;?\s*$`)
+
+// queryLimits holds the global values of the session variables which limit the time a SELECT statement may run for
+// and the number of rows a query may read. New sessions start with the global values, which are set by the server
+// config and by SET GLOBAL.
+type queryLimits struct {
+	maxExecutionTime uint64
+	maxQueryRows     uint64
+}
+
+func newQueryLimits(maxExecutionTime, maxQueryRows uint64) *queryLimits {
+	limits := &queryLimits{}
+	limits.set(maxExecutionTime, maxQueryRows)
+	return limits
+}
+
+// set updates the global limits. Sessions which have already started are not affected.
+func (l *queryLimits) set(maxExecutionTime, maxQueryRows uint64) {
+	atomic.StoreUint64(&l.maxExecutionTime, maxExecutionTime)
+	atomic.StoreUint64(&l.maxQueryRows, maxQueryRows)
+}
+
+// setGlobal sets the global value of the session variable |name|
+func (l *queryLimits) setGlobal(name string, val uint64) {
+	switch name {
+	case maxExecutionTimeSessionVar:
+		atomic.StoreUint64(&l.maxExecutionTime, val)
+	case sqlutil.MaxQueryRowsSessionVar:
+		atomic.StoreUint64(&l.maxQueryRows, val)
+	}
+}
+
+// setSessionDefaults sets the limit session variables of |sess| to their global values
+func (l *queryLimits) setSessionDefaults(ctx context.Context, sess sql.Session) error {
+	err := sess.Set(ctx, maxExecutionTimeSessionVar, sql.Uint64, atomic.LoadUint64(&l.maxExecutionTime))
+
+	if err != nil {
+		return err
+	}
+
+	return sess.Set(ctx, sqlutil.MaxQueryRowsSessionVar, sql.Uint64, atomic.LoadUint64(&l.maxQueryRows))
+}
+
+// parseSetGlobalLimit returns the variable and value set by |query| if it sets the global value of one of the limit
+// session variables.
+func parseSetGlobalLimit(query string) (name string, val uint64, ok bool) {
+	m := setGlobalLimitRegex.FindStringSubmatch(query)
+	if m == nil {
+		return "", 0, false
+	}
+
+	val, err := strconv.ParseUint(m[2], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+
+	return strings.ToLower(m[1]), val, true
+}
+
+// parseKill returns the id of the connection |query| kills the query or connection of, if it is a KILL statement.
+func parseKill(query string) (uint32, bool) {
+	m := killRegex.FindStringSubmatch(query)
+	if m == nil {
+		return 0, false
+	}
+
+	id, err := strconv.ParseUint(m[1], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	return uint32(id), true
+}
+
+// executionTimeout returns how long |query| may run for in the session of |ctx|, or 0 if there is no limit. As in
+// MySQL, max_execution_time only applies to SELECT statements.
+func executionTimeout(ctx *sql.Context, query string) time.Duration {
+	_, val := ctx.Session.Get(maxExecutionTimeSessionVar)
+	if val == nil {
+		return 0
+	}
+
+	millis, err := sql.Uint64.Convert(val)
+	if err != nil || millis.(uint64) == 0 {
+		return 0
+	}
+
+	parsed, err := sqlparser.Parse(query)
+	if err != nil {
+		return 0
+	}
+
+	switch parsed.(type) {
+	case *sqlparser.Select, *sqlparser.Union, *sqlparser.ParenSelect:
+		return time.Duration(millis.(uint64)) * time.Millisecond
+	default:
+		return 0
+	}
+}
+
+// interruptedQueryError returns the MySQL error for |err| if it is the error of a query which was interrupted because
+// it was killed or ran for longer than its execution time limit. Other errors are returned unchanged.
+func interruptedQueryError(err error, timedOut bool) error {
+	if err == nil || !(sqlutil.ErrQueryInterrupted.Is(err) || errors.Is(err, context.Canceled)) {
+		return err
+	}
+
+	if timedOut {
+		return mysql.NewSQLError(erQueryTimeout, mysql.SSUnknownSQLState, "Query execution was interrupted, maximum statement execution time exceeded")
+	}
+
+	return mysql.NewSQLError(mysql.ERQueryInterrupted, ssQueryInterrupted, "Query execution was interrupted")
+}
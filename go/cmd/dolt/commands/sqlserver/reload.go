@@ -0,0 +1,279 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/libraries/doltcore/cdc"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	dsqle "github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/privileges"
+)
+
+// serverReloader applies changes to the config of a running server. The log level, connection limit, timeouts,
+// read-only mode, query limits, address, databases and their write protections are changed without closing the
+// connections which are open. Other settings only take effect when the server is restarted. Changed query limits apply
+// to new sessions.
+type serverReloader struct {
+	mu      *sync.Mutex
+	ctx     context.Context
+	version string
+	dEnv    *env.DoltEnv
+	cfg     ServerConfig
+	srv     *sqlServer
+	auth    *privileges.Auth
+	catalog *sql.Catalog
+	// changes is the stream of the row level changes made to the databases, or nil if changes aren't streamed
+	changes *cdc.Stream
+
+	// dbPaths are the paths of the databases which have been loaded by the server, by name. The path of the database
+	// in the working directory, which is served when the config lists no databases, is "".
+	dbPaths map[string]string
+}
+
+func newServerReloader(ctx context.Context, version string, dEnv *env.DoltEnv, cfg ServerConfig, srv *sqlServer, auth *privileges.Auth, catalog *sql.Catalog, dbs []dsqle.Database, changes *cdc.Stream) *serverReloader {
+	paths := make(map[string]string)
+	for _, nameAndPath := range cfg.DatabaseNamesAndPaths() {
+		paths[nameAndPath.Name] = nameAndPath.Path
+	}
+
+	dbPaths := make(map[string]string, len(dbs))
+	for _, db := range dbs {
+		dbPaths[db.Name()] = paths[db.Name()]
+	}
+
+	return &serverReloader{
+		mu:      &sync.Mutex{},
+		ctx:     ctx,
+		version: version,
+		dEnv:    dEnv,
+		cfg:     cfg,
+		srv:     srv,
+		auth:    auth,
+		catalog: catalog,
+		changes: changes,
+		dbPaths: dbPaths,
+	}
+}
+
+// reload applies |cfg| to the server. The settings which can fail to be applied, the databases to load and the address
+// to listen on, are prepared before any setting is changed, so if |cfg| is invalid or one of them fails an error is
+// returned and nothing is changed.
+func (r *serverReloader) reload(cfg ServerConfig) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ValidateConfig(cfg); err != nil {
+		return err
+	}
+
+	level, err := logrus.ParseLevel(cfg.LogLevel().String())
+	if err != nil {
+		return err
+	}
+
+	hostPort := net.JoinHostPort(cfg.Host(), strconv.Itoa(cfg.Port()))
+	socket, err := r.srv.listener.Listen(hostPort)
+	if err != nil {
+		return err
+	}
+
+	dbs, err := r.loadDatabases(cfg)
+	if err != nil {
+		if socket != nil {
+			_ = socket.Close()
+		}
+		return err
+	}
+
+	warnRestartRequired(r.cfg, cfg)
+
+	logrus.SetLevel(level)
+	r.srv.listener.SetMaxConnections(cfg.MaxConnections())
+	r.srv.listener.SetTimeouts(
+		time.Duration(cfg.ReadTimeout())*time.Millisecond,
+		time.Duration(cfg.WriteTimeout())*time.Millisecond)
+	r.auth.SetReadOnly(cfg.ReadOnly())
+	if cfg.MaxExecutionTime() != r.cfg.MaxExecutionTime() || cfg.MaxQueryRows() != r.cfg.MaxQueryRows() {
+		r.srv.limits.set(cfg.MaxExecutionTime(), cfg.MaxQueryRows())
+	}
+
+	r.applyDatabases(cfg, dbs)
+
+	if socket != nil {
+		if err := r.srv.listener.SetSocket(hostPort, socket); err != nil {
+			logrus.Warnf("Failed to stop listening on the previous address: %v", err)
+		}
+		logrus.Infof("Listening on %s, open connections on the previous address are served until they are closed", hostPort)
+	}
+
+	r.cfg = cfg
+	return nil
+}
+
+// loadedDatabases are the databases in a config which the server hasn't loaded yet, with the paths they were loaded
+// from and the environments of their repos
+type loadedDatabases struct {
+	dbs   []dsqle.Database
+	paths map[string]string
+	mrEnv env.MultiRepoEnv
+}
+
+// loadDatabases loads the databases in |cfg| which the server hasn't loaded yet, without adding them to the server.
+func (r *serverReloader) loadDatabases(cfg ServerConfig) (loadedDatabases, error) {
+	var mrEnv env.MultiRepoEnv
+	namesAndPaths := cfg.DatabaseNamesAndPaths()
+	if len(namesAndPaths) == 0 {
+		mrEnv = env.DoltEnvAsMultiEnv(r.dEnv)
+		for name := range mrEnv {
+			namesAndPaths = append(namesAndPaths, env.EnvNameAndPath{Name: name})
+		}
+	}
+
+	var toLoad []env.EnvNameAndPath
+	for _, nameAndPath := range namesAndPaths {
+		if _, ok := r.dbPaths[nameAndPath.Name]; !ok {
+			toLoad = append(toLoad, nameAndPath)
+		}
+	}
+
+	if len(toLoad) == 0 {
+		return loadedDatabases{}, nil
+	}
+
+	if mrEnv == nil {
+		var err error
+		mrEnv, err = env.LoadMultiEnv(r.ctx, env.GetCurrentUserHomeDir, r.dEnv.FS, r.version, toLoad...)
+		if err != nil {
+			return loadedDatabases{}, err
+		}
+	}
+
+	loaded := loadedDatabases{paths: make(map[string]string), mrEnv: mrEnv}
+	for _, db := range applyWriteProtections(commands.CollectDBs(mrEnv, newDatabase), cfg.DatabaseWriteProtections()) {
+		for _, nameAndPath := range toLoad {
+			if nameAndPath.Name == db.Name() {
+				loaded.dbs = append(loaded.dbs, db)
+				loaded.paths[db.Name()] = nameAndPath.Path
+			}
+		}
+	}
+
+	return loaded, nil
+}
+
+// applyDatabases adds the databases loaded for |cfg| to the server and hides those which are no longer in |cfg| from
+// clients.
+func (r *serverReloader) applyDatabases(cfg ServerConfig, loaded loadedDatabases) {
+	namesAndPaths := cfg.DatabaseNamesAndPaths()
+	if len(namesAndPaths) == 0 {
+		for name := range env.DoltEnvAsMultiEnv(r.dEnv) {
+			namesAndPaths = append(namesAndPaths, env.EnvNameAndPath{Name: name})
+		}
+	}
+
+	served := make(map[string]bool, len(namesAndPaths))
+	for _, nameAndPath := range namesAndPaths {
+		served[nameAndPath.Name] = true
+
+		if loadedPath, ok := r.dbPaths[nameAndPath.Name]; ok && loadedPath != nameAndPath.Path {
+			logrus.Warnf("The path of database %s can't be changed while the server is running, restart the server to serve %s", nameAndPath.Name, nameAndPath.Path)
+		}
+	}
+
+	// the write protections of the loaded databases are shared with their revision databases, so changing them applies
+	// to every session immediately
+	for name := range r.dbPaths {
+		wp := cfg.DatabaseWriteProtections()[name]
+		if reflect.DeepEqual(r.cfg.DatabaseWriteProtections()[name], wp) {
+			continue
+		}
+
+		if db, err := r.catalog.Database(name); err == nil {
+			if ddb, ok := db.(dsqle.Database); ok {
+				ddb.SetWriteProtection(wp)
+				logrus.Infof("Applied the read_only and protected_branches settings of database %s", name)
+			}
+		}
+	}
+
+	for _, db := range loaded.dbs {
+		r.catalog.AddDatabase(db)
+		if r.changes != nil {
+			r.changes.Watch(db.Name(), db.GetDoltDB())
+		}
+		r.dbPaths[db.Name()] = loaded.paths[db.Name()]
+		logrus.Infof("Serving database %s", db.Name())
+	}
+	if loaded.mrEnv != nil {
+		r.srv.revisions.setRepos(loaded.mrEnv)
+	}
+
+	for name := range r.dbPaths {
+		hidden := !served[name]
+		if hidden != r.srv.revisions.isHidden(name) {
+			if hidden {
+				logrus.Infof("No longer serving database %s", name)
+			} else {
+				logrus.Infof("Serving database %s", name)
+			}
+		}
+		r.srv.revisions.setHidden(name, hidden)
+	}
+}
+
+// warnRestartRequired logs a warning for each setting which differs between |prev| and |cfg| but is only applied when
+// the server starts.
+func warnRestartRequired(prev, cfg ServerConfig) {
+	settings := []struct {
+		name       string
+		prev, curr interface{}
+	}{
+		{"user", prev.User(), cfg.User()},
+		{"password", prev.Password(), cfg.Password()},
+		{"privilege file", prev.PrivilegeFilePath(), cfg.PrivilegeFilePath()},
+		{"bootstrap file", prev.BootstrapFilePath(), cfg.BootstrapFilePath()},
+		{"autocommit", prev.AutoCommit(), cfg.AutoCommit()},
+		{"query parallelism", prev.QueryParallelism(), cfg.QueryParallelism()},
+		{"TLS key", prev.TLSKey(), cfg.TLSKey()},
+		{"TLS certificate", prev.TLSCert(), cfg.TLSCert()},
+		{"require secure transport", prev.RequireSecureTransport(), cfg.RequireSecureTransport()},
+		{"metrics host", prev.MetricsHost(), cfg.MetricsHost()},
+		{"metrics port", prev.MetricsPort(), cfg.MetricsPort()},
+		{"metrics labels", prev.MetricsLabels(), cfg.MetricsLabels()},
+		{"slow query log file", prev.SlowQueryLogFile(), cfg.SlowQueryLogFile()},
+		{"slow query threshold", prev.SlowQueryThreshold(), cfg.SlowQueryThreshold()},
+		{"trace file", prev.TraceFile(), cfg.TraceFile()},
+		{"change stream file", prev.ChangeStreamFile(), cfg.ChangeStreamFile()},
+		{"change stream host", prev.ChangeStreamHost(), cfg.ChangeStreamHost()},
+		{"change stream port", prev.ChangeStreamPort(), cfg.ChangeStreamPort()},
+	}
+
+	for _, setting := range settings {
+		if !reflect.DeepEqual(setting.prev, setting.curr) {
+			logrus.Warnf("The %s setting can't be changed while the server is running, restart the server to apply it", setting.name)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	dsqle "github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+// unquotedUseRegex matches USE statements naming a revision database without quoting it, which the parser rejects
+var unquotedUseRegex = regexp.MustCompile("(?i)^\\s*use\\s+([^\\s`;]+/[^\\s`;]+)\\s*;?\\s*$")
+
+// revisionDatabases adds the revision databases of the databases being served, named <database>/<branch or commit>,
+// to the catalog the first time a client uses them. Databases which are removed from the server's config while it runs
+// can't be removed from the catalog, so they are hidden from clients instead. The working sets of branch databases are
+// saved in the repo of the database they are a revision of.
+type revisionDatabases struct {
+	mu      *sync.Mutex
+	catalog *sql.Catalog
+	hidden  map[string]bool
+	stateFS map[string]filesys.ReadWriteFS
+}
+
+func newRevisionDatabases(catalog *sql.Catalog) *revisionDatabases {
+	return &revisionDatabases{mu: &sync.Mutex{}, catalog: catalog, hidden: make(map[string]bool), stateFS: make(map[string]filesys.ReadWriteFS)}
+}
+
+// setRepos sets the repos the working sets of the revision databases of each database in |mrEnv| are saved in
+func (rd *revisionDatabases) setRepos(mrEnv env.MultiRepoEnv) {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
+	for name, dEnv := range mrEnv {
+		rd.stateFS[name] = dEnv.FS
+	}
+}
+
+// setHidden sets whether the database named |name| and its revision databases are hidden from clients
+func (rd *revisionDatabases) setHidden(name string, hidden bool) {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
+	if hidden {
+		rd.hidden[name] = true
+	} else {
+		delete(rd.hidden, name)
+	}
+}
+
+// isHidden returns whether the database named |name| or the database it is a revision of is hidden from clients
+func (rd *revisionDatabases) isHidden(name string) bool {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+	return rd.hidden[dsqle.BaseDbName(name)]
+}
+
+// servedDatabases returns the databases in the catalog which are not hidden
+func (rd *revisionDatabases) servedDatabases() []dsqle.Database {
+	var dbs []dsqle.Database
+	for _, db := range dbsAsDSQLDBs(rd.catalog.AllDatabases()) {
+		if !rd.isHidden(db.Name()) {
+			dbs = append(dbs, db)
+		}
+	}
+
+	return dbs
+}
+
+// HasDB returns whether the database named |name| exists, creating it if it is a revision database.
+func (rd *revisionDatabases) HasDB(name string) bool {
+	if rd.isHidden(name) {
+		return false
+	}
+
+	if rd.catalog.HasDB(name) {
+		return true
+	}
+
+	_, ok, err := rd.resolve(sql.NewEmptyContext(), name)
+	return ok && err == nil
+}
+
+// resolve returns the revision database named |name|, adding it to the catalog if it isn't there yet. ok is false if
+// |name| does not name a revision of a database in the catalog.
+func (rd *revisionDatabases) resolve(ctx *sql.Context, name string) (db dsqle.Database, ok bool, err error) {
+	baseName, revision, ok := dsqle.SplitRevisionDbName(name)
+	if !ok {
+		return dsqle.Database{}, false, nil
+	}
+
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
+	if existing, err := rd.catalog.Database(name); err == nil {
+		db, ok = existing.(dsqle.Database)
+		return db, ok, nil
+	}
+
+	base, err := rd.catalog.Database(baseName)
+	if err != nil {
+		return dsqle.Database{}, false, nil
+	}
+
+	baseDB, ok := base.(dsqle.Database)
+	if !ok {
+		return dsqle.Database{}, false, nil
+	}
+
+	dbData := env.DbData{
+		Ddb: baseDB.GetDoltDB(),
+		Rsr: baseDB.GetStateReader(),
+		Rsw: baseDB.GetStateWriter(),
+		Drw: baseDB.GetDocsReadWriter(),
+	}
+
+	db, err = dsqle.NewRevisionDatabase(ctx, baseDB.Name(), revision, dbData, rd.stateFS[baseDB.Name()])
+	if err != nil {
+		return dsqle.Database{}, true, err
+	}
+	db = db.WithWriteProtectionOf(baseDB)
+
+	rd.catalog.AddDatabase(db)
+	return db, true, nil
+}
+
+// addToSession adds the database named |name| to the session of |ctx| if it isn't part of it yet, resolving it first if
+// it is a revision database. This also adds databases which were added to the catalog after the session began. Names
+// which aren't databases in the catalog are ignored, and hidden databases are an error.
+func (rd *revisionDatabases) addToSession(ctx *sql.Context, name string) error {
+	if rd.isHidden(name) {
+		return sql.ErrDatabaseNotFound.New(name)
+	}
+
+	db, ok, err := rd.resolve(ctx, name)
+	if err != nil {
+		return err
+	} else if !ok {
+		catalogDB, err := rd.catalog.Database(name)
+		if err != nil {
+			return nil
+		}
+
+		db, ok = catalogDB.(dsqle.Database)
+		if !ok {
+			return nil
+		}
+	}
+
+	dsess := dsqle.DSessFromSess(ctx.Session)
+	if dsess.HasDB(db.Name()) {
+		return nil
+	}
+
+	if err := dsess.AddDB(ctx, db); err != nil {
+		return err
+	}
+
+	if err := db.LoadRootFromRepoState(ctx); err != nil {
+		return err
+	}
+
+	root, err := db.GetRoot(ctx)
+	if err != nil {
+		return err
+	}
+
+	return dsqle.RegisterSchemaFragments(ctx, db, root)
+}
+
+// referencedDatabases returns the names of the databases |stmt| selects with USE or qualifies table names with.
+func referencedDatabases(stmt sqlparser.Statement) []string {
+	var names []string
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *sqlparser.Use:
+			names = append(names, n.DBName.String())
+		case sqlparser.TableName:
+			if !n.Qualifier.IsEmpty() {
+				names = append(names, n.Qualifier.String())
+			}
+		}
+		return true, nil
+	}, stmt)
+
+	return names
+}
+
+// quoteRevisionUse quotes the database name of a USE statement selecting a revision database, so that
+// `USE mydb/feature-x` may be written without backticks.
+func quoteRevisionUse(query string) string {
+	if m := unquotedUseRegex.FindStringSubmatch(query); m != nil {
+		return "USE `" + m[1] + "`"
+	}
+	return query
+}
+
+// filterHidden returns a callback which removes the rows naming hidden databases from the results of SHOW DATABASES
+// before passing them to |callback|.
+func (rd *revisionDatabases) filterHidden(callback func(*sqltypes.Result) error) func(*sqltypes.Result) error {
+	return func(res *sqltypes.Result) error {
+		rows := make([][]sqltypes.Value, 0, len(res.Rows))
+		for _, row := range res.Rows {
+			if len(row) == 0 || !rd.isHidden(row[0].ToString()) {
+				rows = append(rows, row)
+			}
+		}
+
+		filtered := *res
+		filtered.Rows = rows
+		return callback(&filtered)
+	}
+}
+
+// isShowDatabases returns whether |query| is SHOW DATABASES or SHOW SCHEMAS
+func isShowDatabases(query string) bool {
+	parsed, err := sqlparser.Parse(query)
+	if err != nil {
+		return false
+	}
+
+	show, ok := parsed.(*sqlparser.Show)
+	if !ok {
+		return false
+	}
+
+	showType := strings.ToLower(show.Type)
+	return showType == "databases" || showType == "schemas"
+}
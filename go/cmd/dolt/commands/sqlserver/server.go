@@ -15,9 +15,14 @@
 package sqlserver
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"fmt"
 	"net"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	sqle "github.com/dolthub/go-mysql-server"
@@ -27,6 +32,7 @@ import (
 	"github.com/dolthub/go-mysql-server/sql/analyzer"
 	"github.com/dolthub/go-mysql-server/sql/information_schema"
 	"github.com/dolthub/vitess/go/mysql"
+	"github.com/opentracing/opentracing-go"
 	"github.com/sirupsen/logrus"
 
 	"github.com/dolthub/dolt/go/cmd/dolt/cli"
@@ -35,6 +41,8 @@ import (
 	dsqle "github.com/dolthub/dolt/go/libraries/doltcore/sqle"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dfunctions"
 	_ "github.com/dolthub/dolt/go/libraries/doltcore/sqle/dfunctions"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/privileges"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
 	"github.com/dolthub/dolt/go/libraries/utils/tracing"
 )
 
@@ -50,14 +58,12 @@ func Serve(ctx context.Context, version string, serverConfig ServerConfig, serve
 		serverController = CreateServerController()
 	}
 
-	var mySQLServer *server.Server
+	var mySQLServer *sqlServer
+	// closeFuncs stop the server and everything started along with it, in order
+	var closeFuncs []func() error
 	// This guarantees unblocking on any routines with a waiting `ServerController`
 	defer func() {
-		if mySQLServer != nil {
-			serverController.registerCloseFunction(startError, mySQLServer.Close)
-		} else {
-			serverController.registerCloseFunction(startError, func() error { return nil })
-		}
+		serverController.registerCloseFunction(startError, closeAll(closeFuncs))
 		serverController.StopServer()
 		serverController.serverStopped(closeError)
 	}()
@@ -76,16 +82,17 @@ func Serve(ctx context.Context, version string, serverConfig ServerConfig, serve
 		logrus.SetLevel(level)
 	}
 
-	permissions := auth.AllPermissions
-	if serverConfig.ReadOnly() {
-		permissions = auth.ReadPerm
+	privAuth, startError := newPrivilegeAuth(dEnv.FS, serverConfig)
+	if startError != nil {
+		cli.PrintErr(startError)
+		return
 	}
 
-	userAuth := auth.NewAudit(auth.NewNativeSingle(serverConfig.User(), serverConfig.Password(), permissions), auth.NewAuditLog(logrus.StandardLogger()))
+	userAuth := auth.NewAudit(privAuth, auth.NewAuditLog(logrus.StandardLogger()))
 
 	c := sql.NewCatalog()
 	a := analyzer.NewBuilder(c).WithParallelism(serverConfig.QueryParallelism()).Build()
-	sqlEngine := sqle.New(c, a, nil)
+	sqlEngine := sqle.New(c, a, &sqle.Config{Auth: userAuth})
 
 	err := sqlEngine.Catalog.Register(dfunctions.DoltFunctions...)
 
@@ -116,18 +123,48 @@ func Serve(ctx context.Context, version string, serverConfig ServerConfig, serve
 		}
 	}
 
-	dbs := commands.CollectDBs(mrEnv, newDatabase)
+	dbs := applyWriteProtections(commands.CollectDBs(mrEnv, newDatabase), serverConfig.DatabaseWriteProtections())
 
 	for _, db := range dbs {
 		sqlEngine.AddDatabase(db)
 	}
 
 	sqlEngine.AddDatabase(information_schema.NewInformationSchemaDatabase(sqlEngine.Catalog))
+	revisions := newRevisionDatabases(sqlEngine.Catalog)
+	revisions.setRepos(mrEnv)
+
+	tlsConfig, startError := LoadTLSConfig(dEnv.FS, serverConfig)
+	if startError != nil {
+		cli.PrintErr(startError)
+		return
+	}
+
+	metrics := newServerMetrics(sqlEngine.Catalog, serverConfig.MetricsLabels())
+	limits := newQueryLimits(serverConfig.MaxExecutionTime(), serverConfig.MaxQueryRows())
+
+	var slowLog *slowQueryLog
+	if slowLogPath := serverConfig.SlowQueryLogFile(); slowLogPath != "" {
+		var closeSlowLog func() error
+		threshold := time.Duration(serverConfig.SlowQueryThreshold()) * time.Millisecond
+		slowLog, closeSlowLog, startError = openSlowQueryLog(slowLogPath, threshold)
+		if startError != nil {
+			cli.PrintErr(startError)
+			return
+		}
+		closeFuncs = append(closeFuncs, closeSlowLog)
+	}
+
+	tracer, closeTracer, startError := newQueryTracer(serverConfig.TraceFile())
+	if startError != nil {
+		cli.PrintErr(startError)
+		return
+	}
+	closeFuncs = append(closeFuncs, closeTracer)
 
 	hostPort := net.JoinHostPort(serverConfig.Host(), strconv.Itoa(serverConfig.Port()))
 	readTimeout := time.Duration(serverConfig.ReadTimeout()) * time.Millisecond
 	writeTimeout := time.Duration(serverConfig.WriteTimeout()) * time.Millisecond
-	mySQLServer, startError = server.NewServer(
+	mySQLServer, startError = newServer(
 		server.Config{
 			Protocol:         "tcp",
 			Address:          hostPort,
@@ -139,7 +176,15 @@ func Serve(ctx context.Context, version string, serverConfig ServerConfig, serve
 			// to the value of mysql that we support.
 		},
 		sqlEngine,
-		newSessionBuilder(sqlEngine, username, email, serverConfig.AutoCommit()),
+		newSessionBuilder(revisions, username, email, serverConfig.AutoCommit(), metrics, limits),
+		privAuth,
+		revisions,
+		metrics,
+		slowLog,
+		limits,
+		tracer,
+		tlsConfig,
+		serverConfig.RequireSecureTransport(),
 	)
 
 	if startError != nil {
@@ -147,7 +192,29 @@ func Serve(ctx context.Context, version string, serverConfig ServerConfig, serve
 		return
 	}
 
-	serverController.registerCloseFunction(startError, mySQLServer.Close)
+	closeFuncs = append([]func() error{mySQLServer.Close}, closeFuncs...)
+	if serverConfig.MetricsPort() != -1 {
+		metricsHostPort := net.JoinHostPort(serverConfig.MetricsHost(), strconv.Itoa(serverConfig.MetricsPort()))
+		closeMetrics, err := startMetricsListener(metricsHostPort, metrics)
+		if err != nil {
+			startError = err
+			cli.PrintErr(startError)
+			return
+		}
+		closeFuncs = append(closeFuncs, closeMetrics)
+	}
+
+	changes, closeChanges, startError := startChangeStream(serverConfig, dbs)
+	if startError != nil {
+		cli.PrintErr(startError)
+		return
+	}
+	closeFuncs = append(closeFuncs, closeChanges)
+
+	reloader := newServerReloader(ctx, version, dEnv, serverConfig, mySQLServer, privAuth, sqlEngine.Catalog, dbs, changes)
+	serverController.registerReloadFunction(reloader.reload)
+
+	serverController.registerCloseFunction(startError, closeAll(closeFuncs))
 	closeError = mySQLServer.Start()
 	if closeError != nil {
 		cli.PrintErr(closeError)
@@ -156,21 +223,133 @@ func Serve(ctx context.Context, version string, serverConfig ServerConfig, serve
 	return
 }
 
-func newSessionBuilder(sqlEngine *sqle.Engine, username, email string, autocommit bool) server.SessionBuilder {
+// sqlServer is a server along with the parts of it which may be changed while it runs
+type sqlServer struct {
+	*server.Server
+	listener  *serverListener
+	revisions *revisionDatabases
+	limits    *queryLimits
+}
+
+// newServer creates a server in the same way as server.NewServer, with the go-mysql-server handler wrapped in a Handler
+// which runs account management statements. The databases clients may use are given by |revisions|. If |tlsConfig| is
+// not nil clients may use TLS, and if |requireSecure| is true they must. Connections and queries are recorded in
+// |metrics|, queries which are slow are logged to |slowLog| if it is not nil, and the spans of each query are reported
+// to |tracer|. The global values of the query limit session variables are held by |limits|.
+func newServer(cfg server.Config, e *sqle.Engine, sb server.SessionBuilder, privAuth *privileges.Auth, revisions *revisionDatabases, metrics *serverMetrics, slowLog *slowQueryLog, limits *queryLimits, tracer opentracing.Tracer, tlsConfig *tls.Config, requireSecure bool) (*sqlServer, error) {
+	sm := server.NewSessionManager(sb, tracer, revisions.HasDB, e.Catalog.MemoryManager, cfg.Address)
+	gmsHandler := server.NewHandler(e, sm, cfg.ConnReadTimeout)
+
+	l, err := newServerListener(cfg.Protocol, cfg.Address, gmsHandler, cfg.MaxConnections, cfg.ConnReadTimeout, cfg.ConnWriteTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	// the connection limit and timeouts are enforced by |l| so that they can be changed while the server runs
+	vtListener, err := mysql.NewListenerWithConfig(mysql.ListenerConfig{
+		Listener:           l,
+		AuthServer:         cfg.Auth.Mysql(),
+		Handler:            NewHandler(gmsHandler, sm, e, privAuth, revisions, metrics, slowLog, limits),
+		ConnReadBufferSize: mysql.DefaultConnBufferSize,
+	})
+	if err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+
+	vtListener.TLSConfig = tlsConfig
+	vtListener.RequireSecureTransport = requireSecure
+
+	return &sqlServer{Server: &server.Server{Listener: vtListener}, listener: l, revisions: revisions, limits: limits}, nil
+}
+
+// newQueryTracer returns the tracer that the spans of each query are reported to, which writes them to |traceFile| as
+// lines of JSON, along with the function that closes the file. If |traceFile| is "" queries are not traced.
+func newQueryTracer(traceFile string) (opentracing.Tracer, func() error, error) {
+	switch traceFile {
+	case "":
+		return opentracing.NoopTracer{}, func() error { return nil }, nil
+	case TraceFileStdout:
+		return tracing.NewJSONTracer(cli.CliOut), func() error { return nil }, nil
+	}
+
+	f, err := os.OpenFile(traceFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tracing.NewJSONTracer(f), f.Close, nil
+}
+
+// closeAll returns a function which calls each of |closeFuncs| in order, returning the first error encountered.
+func closeAll(closeFuncs []func() error) func() error {
+	return func() error {
+		var firstErr error
+		for _, closeFunc := range closeFuncs {
+			if err := closeFunc(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+}
+
+// newPrivilegeAuth loads the users and privileges of the server. The configured user is the super user, and if the
+// privilege file does not exist yet the statements in the bootstrap file are run to create the initial users.
+func newPrivilegeAuth(fs filesys.Filesys, serverConfig ServerConfig) (*privileges.Auth, error) {
+	store, err := privileges.NewStore(fs, serverConfig.PrivilegeFilePath(), serverConfig.User(), serverConfig.Password())
+	if err != nil {
+		return nil, err
+	}
+
+	if bootstrapPath := serverConfig.BootstrapFilePath(); bootstrapPath != "" && !store.Persisted() {
+		data, err := fs.ReadFile(bootstrapPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading bootstrap file %s: %v", bootstrapPath, err)
+		}
+
+		var statements []string
+		scanner := commands.NewSqlStatementScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			if query := strings.TrimSpace(scanner.Text()); query != "" {
+				statements = append(statements, query)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+
+		if err := bootstrapPrivileges(store, serverConfig.User(), statements); err != nil {
+			return nil, err
+		}
+	}
+
+	return privileges.NewAuth(store, serverConfig.ReadOnly()), nil
+}
+
+func newSessionBuilder(revisions *revisionDatabases, username, email string, autocommit bool, metrics *serverMetrics, limits *queryLimits) server.SessionBuilder {
 	return func(ctx context.Context, conn *mysql.Conn, host string) (sql.Session, *sql.IndexRegistry, *sql.ViewRegistry, error) {
 		mysqlSess := sql.NewSession(host, conn.RemoteAddr().String(), conn.User, conn.ConnectionID)
-		doltSess, err := dsqle.NewDoltSession(ctx, mysqlSess, username, email, dbsAsDSQLDBs(sqlEngine.Catalog.AllDatabases())...)
+		doltSess, err := dsqle.NewDoltSession(ctx, mysqlSess, username, email, revisions.servedDatabases()...)
 
 		if err != nil {
 			return nil, nil, nil, err
 		}
 
+		doltSess.SetCommitListener(metrics.transactionCommitted)
+
 		err = doltSess.Set(ctx, sql.AutoCommitSessionVar, sql.Boolean, autocommit)
 
 		if err != nil {
 			return nil, nil, nil, err
 		}
 
+		err = limits.setSessionDefaults(ctx, doltSess)
+
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
 		ir := sql.NewIndexRegistry()
 		vr := sql.NewViewRegistry()
 		sqlCtx := sql.NewContext(
@@ -180,8 +359,7 @@ func newSessionBuilder(sqlEngine *sqle.Engine, username, email string, autocommi
 			sql.WithSession(doltSess),
 			sql.WithTracer(tracing.Tracer(ctx)))
 
-		dbs := dbsAsDSQLDBs(sqlEngine.Catalog.AllDatabases())
-		for _, db := range dbs {
+		for _, db := range revisions.servedDatabases() {
 			err := db.LoadRootFromRepoState(sqlCtx)
 			if err != nil {
 				return nil, nil, nil, err
@@ -208,6 +386,16 @@ func newDatabase(name string, dEnv *env.DoltEnv) dsqle.Database {
 	return dsqle.NewDatabase(name, dEnv.DbData())
 }
 
+// applyWriteProtections restricts the writes which may be made to each of |dbs| according to |protections|. Databases
+// without protections in |protections| can be written to.
+func applyWriteProtections(dbs []dsqle.Database, protections map[string]dsqle.WriteProtection) []dsqle.Database {
+	for _, db := range dbs {
+		db.SetWriteProtection(protections[db.Name()])
+	}
+
+	return dbs
+}
+
 func dbsAsDSQLDBs(dbs []sql.Database) []dsqle.Database {
 	dsqlDBs := make([]dsqle.Database, 0, len(dbs))
 
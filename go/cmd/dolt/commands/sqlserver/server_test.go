@@ -15,16 +15,38 @@
 package sqlserver
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	gosql "database/sql"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"github.com/gocraft/dbr/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
 
+	"github.com/dolthub/dolt/go/libraries/doltcore/cdc"
 	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	dsqle "github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/dolt/go/libraries/utils/tracing"
 )
 
 type testPerson struct {
@@ -216,3 +238,996 @@ func TestServerSelect(t *testing.T) {
 		})
 	}
 }
+
+func TestServerPrivileges(t *testing.T) {
+	env := dtestutils.CreateEnvWithSeedData(t)
+	serverConfig := DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15310).withMaxConnections(3)
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(context.Background(), "", serverConfig, sc, env)
+	}()
+	err := sc.WaitForStart()
+	require.NoError(t, err)
+
+	const dbName = "dolt"
+	rootConn, err := dbr.Open("mysql", ConnectionString(serverConfig)+dbName, nil)
+	require.NoError(t, err)
+	defer rootConn.Close()
+
+	_, err = rootConn.Exec("CREATE USER 'analyst'@'%' IDENTIFIED BY 'secret'")
+	require.NoError(t, err)
+	_, err = rootConn.Exec("GRANT SELECT ON dolt.people TO analyst")
+	require.NoError(t, err)
+
+	conn, err := dbr.Open("mysql", "analyst:secret@tcp(localhost:15310)/"+dbName, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var peoples []testPerson
+	_, err = conn.NewSession(nil).Select("*").From("people").LoadContext(context.Background(), &peoples)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []testPerson{bill, john, rob}, peoples)
+
+	_, err = conn.Exec("INSERT INTO people (id, name, age, is_married, title) VALUES ('00000000-0000-0000-0000-000000000005', 'Ann Annerson', 40, true, 'Boss')")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied")
+
+	_, err = conn.Exec("CREATE TABLE stolen (pk int primary key)")
+	require.Error(t, err)
+
+	// check constraints require the ALTER privilege on their table
+	_, err = conn.Exec("ALTER TABLE people ADD CONSTRAINT adult CHECK (age > 17)")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied")
+	_, err = rootConn.Exec("GRANT ALTER ON dolt.people TO analyst")
+	require.NoError(t, err)
+	_, err = conn.Exec("ALTER TABLE people ADD CONSTRAINT adult CHECK (age > 17)")
+	require.NoError(t, err)
+	_, err = rootConn.Exec("REVOKE ALTER ON dolt.people FROM analyst")
+	require.NoError(t, err)
+
+	_, err = conn.Exec("CREATE USER intruder")
+	require.Error(t, err)
+
+	var grants []string
+	_, err = conn.NewSession(nil).SelectBySql("SHOW GRANTS").LoadContext(context.Background(), &grants)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GRANT USAGE ON *.* TO 'analyst'@'%'", "GRANT SELECT ON `dolt`.`people` TO 'analyst'@'%'"}, grants)
+
+	badConn, err := dbr.Open("mysql", "analyst:wrong@tcp(localhost:15310)/"+dbName, nil)
+	if err == nil {
+		assert.Error(t, badConn.Ping())
+		badConn.Close()
+	}
+}
+
+func TestServerBootstrapPrivileges(t *testing.T) {
+	env := dtestutils.CreateEnvWithSeedData(t)
+	require.NoError(t, env.FS.WriteFile("bootstrap.sql", []byte(`
+CREATE USER etl IDENTIFIED BY 'etlpass';
+GRANT SELECT, INSERT ON dolt.* TO etl;
+`)))
+
+	serverConfig := YAMLConfig{
+		LogLevelStr: strPtr(string(LogLevel_Fatal)),
+		UserConfig: UserYAMLConfig{
+			PrivilegeFile: strPtr("privileges.json"),
+			BootstrapFile: strPtr("bootstrap.sql"),
+		},
+		ListenerConfig: ListenerYAMLConfig{PortNumber: intPtr(15311)},
+	}
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(context.Background(), "", serverConfig, sc, env)
+	}()
+	err := sc.WaitForStart()
+	require.NoError(t, err)
+
+	conn, err := dbr.Open("mysql", "etl:etlpass@tcp(localhost:15311)/dolt", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec("INSERT INTO people (id, name, age, is_married, title) VALUES ('00000000-0000-0000-0000-000000000005', 'Ann Annerson', 40, true, 'Boss')")
+	require.NoError(t, err)
+	_, err = conn.Exec("DELETE FROM people WHERE name = 'Ann Annerson'")
+	require.Error(t, err)
+
+	exists, _ := env.FS.Exists("privileges.json")
+	assert.True(t, exists)
+}
+
+// generateTestCert returns a PEM encoded self-signed certificate for localhost and its private key
+func TestServerRevisionDatabases(t *testing.T) {
+	dEnv := dtestutils.CreateEnvWithSeedData(t)
+	serverConfig := DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15330).withMaxConnections(2)
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(context.Background(), "", serverConfig, sc, dEnv)
+	}()
+	err := sc.WaitForStart()
+	require.NoError(t, err)
+
+	const dbName = "dolt"
+	db, err := dbr.Open("mysql", ConnectionString(serverConfig)+dbName, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "SELECT DOLT_COMMIT('-a', '-m', 'seed data')")
+	require.NoError(t, err)
+
+	head, err := dEnv.DoltDB.ResolveRef(ctx, dEnv.RepoState.CWBHeadRef())
+	require.NoError(t, err)
+	require.NoError(t, dEnv.DoltDB.NewBranchAtCommit(ctx, ref.NewBranchRef("feature-x"), head))
+	headHash, err := head.HashOf()
+	require.NoError(t, err)
+
+	countPeople := func(table string) int {
+		var count int
+		require.NoError(t, conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&count))
+		return count
+	}
+
+	_, err = conn.ExecContext(ctx, "USE dolt/feature-x")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "INSERT INTO people (id, name, age, is_married, title) VALUES ('00000000-0000-0000-0000-000000000005', 'Ann Annerson', 40, true, 'Boss')")
+	require.NoError(t, err)
+	assert.Equal(t, 4, countPeople("people"))
+	_, err = conn.ExecContext(ctx, "SELECT DOLT_COMMIT('-a', '-m', 'add ann')")
+	require.NoError(t, err)
+
+	branchHead, err := dEnv.DoltDB.ResolveRef(ctx, ref.NewBranchRef("feature-x"))
+	require.NoError(t, err)
+	branchHash, err := branchHead.HashOf()
+	require.NoError(t, err)
+	assert.NotEqual(t, headHash, branchHash)
+
+	_, err = conn.ExecContext(ctx, "USE dolt")
+	require.NoError(t, err)
+	assert.Equal(t, 3, countPeople("people"))
+	assert.Equal(t, 4, countPeople("`dolt/feature-x`.people"))
+
+	_, err = conn.ExecContext(ctx, "USE `dolt/"+headHash.String()+"`")
+	require.NoError(t, err)
+	assert.Equal(t, 3, countPeople("people"))
+	_, err = conn.ExecContext(ctx, "DELETE FROM people")
+	assert.Error(t, err)
+	_, err = conn.ExecContext(ctx, "CREATE TABLE nope (pk int primary key)")
+	assert.Error(t, err)
+
+	_, err = conn.ExecContext(ctx, "USE dolt/no-such-branch")
+	assert.Error(t, err)
+}
+
+func TestServerRevisionDatabaseFollowsBranch(t *testing.T) {
+	dEnv := dtestutils.CreateEnvWithSeedData(t)
+	serverConfig := DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15331).withMaxConnections(3)
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(context.Background(), "", serverConfig, sc, dEnv)
+	}()
+	err := sc.WaitForStart()
+	require.NoError(t, err)
+
+	const dbName = "dolt"
+	db, err := dbr.Open("mysql", ConnectionString(serverConfig)+dbName, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	conn1, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn1.Close()
+	conn2, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn2.Close()
+
+	exec := func(conn *gosql.Conn, query string) error {
+		_, err := conn.ExecContext(ctx, query)
+		return err
+	}
+	countPeople := func(conn *gosql.Conn, table string) int {
+		var count int
+		require.NoError(t, conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&count))
+		return count
+	}
+	johnsAge := func(conn *gosql.Conn, table string) int {
+		var age int
+		require.NoError(t, conn.QueryRowContext(ctx, "SELECT age FROM "+table+" WHERE name = 'John Johnson'").Scan(&age))
+		return age
+	}
+
+	require.NoError(t, exec(conn1, "SELECT DOLT_COMMIT('-a', '-m', 'seed data')"))
+	require.NoError(t, exec(conn1, "USE dolt/master"))
+	assert.Equal(t, 3, countPeople(conn1, "people"))
+
+	// a commit to the branch through another database is seen by the revision database
+	require.NoError(t, exec(conn2, "INSERT INTO people (id, name, age, is_married, title) VALUES ('00000000-0000-0000-0000-000000000005', 'Ann Annerson', 40, true, 'Boss')"))
+	require.NoError(t, exec(conn2, "SELECT DOLT_COMMIT('-a', '-m', 'add ann')"))
+	assert.Equal(t, 4, countPeople(conn1, "people"))
+
+	// and uncommitted changes to the revision database which conflict with it can't be committed
+	require.NoError(t, exec(conn1, "UPDATE people SET age = 40 WHERE name = 'John Johnson'"))
+	require.NoError(t, exec(conn2, "UPDATE people SET age = 50 WHERE name = 'John Johnson'"))
+	require.NoError(t, exec(conn2, "SELECT DOLT_COMMIT('-a', '-m', 'update john')"))
+	err = exec(conn1, "SELECT DOLT_COMMIT('-a', '-m', 'conflicting update')")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "master")
+
+	// once the conflict is resolved the changes are rebased onto the branch and committed on top of it
+	require.NoError(t, exec(conn1, "UPDATE people SET age = 50 WHERE name = 'John Johnson'"))
+	require.NoError(t, exec(conn1, "INSERT INTO people (id, name, age, is_married, title) VALUES ('00000000-0000-0000-0000-000000000006', 'Bob Bobberson', 50, false, 'Boss')"))
+	require.NoError(t, exec(conn1, "SELECT DOLT_COMMIT('-a', '-m', 'add bob')"))
+
+	head, err := dEnv.DoltDB.ResolveRef(ctx, ref.NewBranchRef("master"))
+	require.NoError(t, err)
+	meta, err := head.GetCommitMeta()
+	require.NoError(t, err)
+	assert.Equal(t, "add bob", meta.Description)
+	assert.Equal(t, 5, countPeople(conn2, "`dolt/master`.people"))
+	assert.Equal(t, 50, johnsAge(conn2, "`dolt/master`.people"))
+}
+
+func TestServerRevisionDatabaseSurvivesRestart(t *testing.T) {
+	dEnv := dtestutils.CreateEnvWithSeedData(t)
+	ctx := context.Background()
+
+	serve := func(port int, queries ...string) int {
+		serverConfig := DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(port)
+		sc := CreateServerController()
+		defer sc.StopServer()
+		go func() {
+			_, _ = Serve(context.Background(), "", serverConfig, sc, dEnv)
+		}()
+		require.NoError(t, sc.WaitForStart())
+
+		db, err := dbr.Open("mysql", ConnectionString(serverConfig)+"dolt", nil)
+		require.NoError(t, err)
+		defer db.Close()
+		conn, err := db.Conn(ctx)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		for _, query := range queries {
+			_, err = conn.ExecContext(ctx, query)
+			require.NoError(t, err)
+		}
+
+		var count int
+		require.NoError(t, conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM people").Scan(&count))
+		return count
+	}
+
+	assert.Equal(t, 3, serve(15333, "SELECT DOLT_COMMIT('-a', '-m', 'seed data')"))
+	head, err := dEnv.DoltDB.ResolveRef(ctx, dEnv.RepoState.CWBHeadRef())
+	require.NoError(t, err)
+	require.NoError(t, dEnv.DoltDB.NewBranchAtCommit(ctx, ref.NewBranchRef("feature-x"), head))
+
+	assert.Equal(t, 4, serve(15334, "USE dolt/feature-x", "INSERT INTO people (id, name, age, is_married, title) VALUES ('00000000-0000-0000-0000-000000000005', 'Ann Annerson', 40, true, 'Boss')"))
+	exists, _ := dEnv.FS.Exists(".dolt/revisions/feature-x.json")
+	assert.True(t, exists)
+
+	// the uncommitted insert is restored by the next server, and committing it removes the saved working set
+	assert.Equal(t, 4, serve(15335, "USE dolt/feature-x", "SELECT DOLT_COMMIT('-a', '-m', 'add ann')"))
+	exists, _ = dEnv.FS.Exists(".dolt/revisions/feature-x.json")
+	assert.False(t, exists)
+	assert.Equal(t, 4, serve(15336, "USE dolt/feature-x"))
+	assert.Equal(t, 3, serve(15337))
+}
+
+func TestServerCheckConstraints(t *testing.T) {
+	dEnv := dtestutils.CreateEnvWithSeedData(t)
+	serverConfig := DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15332).withMaxConnections(2)
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(context.Background(), "", serverConfig, sc, dEnv)
+	}()
+	err := sc.WaitForStart()
+	require.NoError(t, err)
+
+	const dbName = "dolt"
+	db, err := dbr.Open("mysql", ConnectionString(serverConfig)+dbName, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	exec := func(query string) error {
+		_, err := conn.ExecContext(ctx, query)
+		return err
+	}
+
+	require.NoError(t, exec("CREATE TABLE t (pk int primary key, v int, check (v > 0))"))
+	err = exec("INSERT INTO t VALUES (1, -1)")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "is violated")
+	}
+	require.NoError(t, exec("INSERT INTO t VALUES (1, 1)"))
+
+	require.NoError(t, exec("ALTER TABLE t ADD CONSTRAINT v_small CHECK (v < 10)"))
+	assert.Error(t, exec("UPDATE t SET v = 10"))
+	require.NoError(t, exec("ALTER TABLE t DROP CHECK v_small"))
+	require.NoError(t, exec("UPDATE t SET v = 10"))
+
+	// the checks are committed to the working set, so they are enforced on other connections
+	conn2, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn2.Close()
+	_, err = conn2.ExecContext(ctx, "INSERT INTO t VALUES (2, 0)")
+	assert.Error(t, err)
+
+	var count int
+	require.NoError(t, conn2.QueryRowContext(ctx, "SELECT COUNT(*) FROM t WHERE v = 10").Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestServerTransactions(t *testing.T) {
+	dEnv := dtestutils.CreateEnvWithSeedData(t)
+	serverConfig := DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15340).withMaxConnections(3)
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(context.Background(), "", serverConfig, sc, dEnv)
+	}()
+	err := sc.WaitForStart()
+	require.NoError(t, err)
+
+	const dbName = "dolt"
+	db, err := dbr.Open("mysql", ConnectionString(serverConfig)+dbName, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	conn1, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn1.Close()
+	conn2, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn2.Close()
+
+	exec := func(conn *gosql.Conn, query string) error {
+		_, err := conn.ExecContext(ctx, query)
+		return err
+	}
+	ageOf := func(conn *gosql.Conn, name string) int {
+		var age int
+		require.NoError(t, conn.QueryRowContext(ctx, "SELECT age FROM people WHERE name = ?", name).Scan(&age))
+		return age
+	}
+	countPeople := func(conn *gosql.Conn) int {
+		var count int
+		require.NoError(t, conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM people").Scan(&count))
+		return count
+	}
+
+	// concurrent changes to different rows are merged
+	require.NoError(t, exec(conn1, "START TRANSACTION"))
+	require.NoError(t, exec(conn1, "INSERT INTO people (id, name, age, is_married, title) VALUES ('00000000-0000-0000-0000-000000000005', 'Ann Annerson', 40, true, 'Boss')"))
+	require.NoError(t, exec(conn2, "UPDATE people SET age = 33 WHERE name = 'Bill Billerson'"))
+	assert.Equal(t, 3, countPeople(conn2))
+	assert.Equal(t, 32, ageOf(conn1, "Bill Billerson"))
+	require.NoError(t, exec(conn1, "COMMIT"))
+	assert.Equal(t, 4, countPeople(conn1))
+	assert.Equal(t, 33, ageOf(conn1, "Bill Billerson"))
+	assert.Equal(t, 4, countPeople(conn2))
+
+	// concurrent changes to the same row are a conflict, which rolls back the transaction
+	require.NoError(t, exec(conn1, "BEGIN"))
+	require.NoError(t, exec(conn1, "UPDATE people SET age = 26 WHERE name = 'John Johnson'"))
+	require.NoError(t, exec(conn2, "UPDATE people SET age = 27 WHERE name = 'John Johnson'"))
+	err = exec(conn1, "COMMIT")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "people")
+	assert.Equal(t, 27, ageOf(conn1, "John Johnson"))
+
+	// rolled back changes are discarded
+	require.NoError(t, exec(conn1, "BEGIN"))
+	require.NoError(t, exec(conn1, "DELETE FROM people"))
+	assert.Equal(t, 0, countPeople(conn1))
+	assert.Equal(t, 4, countPeople(conn2))
+	require.NoError(t, exec(conn1, "ROLLBACK"))
+	assert.Equal(t, 4, countPeople(conn1))
+
+	// autocommit is restored when the transaction ends
+	require.NoError(t, exec(conn1, "DELETE FROM people WHERE name = 'Ann Annerson'"))
+	assert.Equal(t, 3, countPeople(conn2))
+
+	// a table dropped in a transaction stays dropped when another session changes a different table
+	countRows := func(conn *gosql.Conn, table string) int {
+		var count int
+		require.NoError(t, conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&count))
+		return count
+	}
+	require.NoError(t, exec(conn1, "CREATE TABLE t (pk int primary key)"))
+	require.NoError(t, exec(conn1, "CREATE TABLE u (pk int primary key)"))
+	require.NoError(t, exec(conn1, "BEGIN"))
+	require.NoError(t, exec(conn1, "DROP TABLE t"))
+	require.NoError(t, exec(conn2, "INSERT INTO u VALUES (1)"))
+	require.NoError(t, exec(conn1, "COMMIT"))
+	assert.Error(t, exec(conn2, "SELECT * FROM t"))
+	assert.Equal(t, 1, countRows(conn2, "u"))
+	assert.Equal(t, 1, countRows(conn1, "u"))
+
+	// and a table dropped by another session stays dropped when a transaction changes a different table
+	require.NoError(t, exec(conn1, "BEGIN"))
+	require.NoError(t, exec(conn1, "INSERT INTO people (id, name, age, is_married, title) VALUES ('00000000-0000-0000-0000-000000000006', 'Bob Bobberson', 50, false, 'Boss')"))
+	require.NoError(t, exec(conn2, "DROP TABLE u"))
+	require.NoError(t, exec(conn1, "COMMIT"))
+	assert.Error(t, exec(conn1, "SELECT * FROM u"))
+	assert.Equal(t, 4, countPeople(conn2))
+}
+
+func generateTestCert(t *testing.T) (certPEM, keyPEM []byte) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	require.NoError(t, err)
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM
+}
+
+func TestServerMetrics(t *testing.T) {
+	dEnv := dtestutils.CreateEnvWithSeedData(t)
+	labels := map[string]string{"cluster": "test"}
+	serverConfig := DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15350).withMetrics("localhost", 15351, labels)
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(context.Background(), "", serverConfig, sc, dEnv)
+	}()
+	err := sc.WaitForStart()
+	require.NoError(t, err)
+
+	const dbName = "dolt"
+	conn, err := dbr.Open("mysql", ConnectionString(serverConfig)+dbName, nil)
+	require.NoError(t, err)
+	sess := conn.NewSession(nil)
+
+	rows, err := sess.Query("SELECT * FROM people")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	_, err = sess.Exec("UPDATE people SET age = 33 WHERE name = 'Bill Billerson'")
+	require.NoError(t, err)
+	_, err = sess.Query("SELECT * FROM not_a_table")
+	require.Error(t, err)
+
+	resp, err := http.Get("http://localhost:15351/metrics")
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	metrics := string(body)
+	assert.Contains(t, metrics, "# TYPE dolt_sql_server_query_latency_seconds histogram")
+	assert.Contains(t, metrics, `dolt_sql_server_connections{cluster="test"} 1`)
+	assert.Contains(t, metrics, `dolt_sql_server_query_errors_total{cluster="test"} 1`)
+	assert.Contains(t, metrics, `dolt_sql_server_query_latency_seconds_bucket{cluster="test",le="+Inf"}`)
+	assert.Contains(t, metrics, `dolt_sql_server_transaction_commits_total{cluster="test",database="dolt"}`)
+
+	resp, err = http.Get("http://localhost:15351/not_metrics")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	require.NoError(t, conn.Close())
+	sc.StopServer()
+	err = sc.WaitForClose()
+	require.NoError(t, err)
+
+	_, err = http.Get("http://localhost:15351/metrics")
+	assert.Error(t, err)
+}
+
+func TestServerSlowQueryLogAndTracing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slow_query_log")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	slowLogPath := filepath.Join(dir, "slow.log")
+	tracePath := filepath.Join(dir, "traces.json")
+
+	dEnv := dtestutils.CreateEnvWithSeedData(t)
+	serverConfig := DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15352).withSlowQueryLog(slowLogPath, 0).withTraceFile(tracePath)
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(context.Background(), "", serverConfig, sc, dEnv)
+	}()
+	err = sc.WaitForStart()
+	require.NoError(t, err)
+
+	const dbName = "dolt"
+	conn, err := dbr.Open("mysql", ConnectionString(serverConfig)+dbName, nil)
+	require.NoError(t, err)
+	sess := conn.NewSession(nil)
+
+	var names []string
+	_, err = sess.Select("name").From("people").Where("age > 21").Load(&names)
+	require.NoError(t, err)
+	require.Len(t, names, 2)
+	_, err = sess.Exec("UPDATE people SET age = age + 1")
+	require.NoError(t, err)
+
+	require.NoError(t, conn.Close())
+	sc.StopServer()
+	err = sc.WaitForClose()
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(slowLogPath)
+	require.NoError(t, err)
+	entries := make(map[string]map[string]interface{})
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries[entry["query"].(string)] = entry
+	}
+
+	selectEntry, ok := entries["SELECT name FROM people WHERE (age > 21)"]
+	require.True(t, ok, "slow query log: %s", string(data))
+	assert.Equal(t, "root", selectEntry["user"])
+	assert.Equal(t, dbName, selectEntry["database"])
+	assert.Equal(t, "master", selectEntry["branch"])
+	assert.Equal(t, float64(2), selectEntry["rows_sent"])
+
+	updateEntry, ok := entries["UPDATE people SET age = age + 1"]
+	require.True(t, ok, "slow query log: %s", string(data))
+	assert.Equal(t, float64(3), updateEntry["rows_affected"])
+
+	data, err = ioutil.ReadFile(tracePath)
+	require.NoError(t, err)
+	operations := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec tracing.SpanRecord
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		operations[rec.Operation] = true
+	}
+	assert.True(t, operations["analyze"], "traced operations: %v", operations)
+	assert.True(t, operations["plan.Project"], "traced operations: %v", operations)
+}
+
+func TestServerReloadConfig(t *testing.T) {
+	dEnv := dtestutils.CreateEnvWithSeedData(t)
+	serverConfig := DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15353)
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(context.Background(), "", serverConfig, sc, dEnv)
+	}()
+	err := sc.WaitForStart()
+	require.NoError(t, err)
+
+	const dbName = "dolt"
+	conn, err := dbr.Open("mysql", ConnectionString(serverConfig)+dbName, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+	sess := conn.NewSession(nil)
+
+	_, err = sess.Exec("UPDATE people SET age = age + 1")
+	require.NoError(t, err)
+
+	reloadedConfig := DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15354).withReadOnly(true).withMaxConnections(2)
+	err = sc.ReloadConfig(reloadedConfig)
+	require.NoError(t, err)
+
+	// the connection opened before the reload is still served
+	var names []string
+	_, err = sess.Select("name").From("people").Load(&names)
+	require.NoError(t, err)
+	assert.Len(t, names, 3)
+	_, err = sess.Exec("UPDATE people SET age = age + 1")
+	assert.Error(t, err)
+
+	_, err = net.DialTimeout("tcp", "localhost:15353", time.Second)
+	assert.Error(t, err)
+
+	// two connections may now be open at once
+	conn2, err := dbr.Open("mysql", ConnectionString(reloadedConfig)+dbName, nil)
+	require.NoError(t, err)
+	defer conn2.Close()
+	conn2.SetMaxOpenConns(1)
+	var ages []int
+	_, err = conn2.NewSession(nil).Select("age").From("people").Where("name = ?", bill.Name).Load(&ages)
+	require.NoError(t, err)
+	assert.Equal(t, []int{bill.Age + 1}, ages)
+
+	// a config whose address can't be listened on isn't applied, so the server stays read only
+	taken, err := net.Listen("tcp", "localhost:15360")
+	require.NoError(t, err)
+	defer taken.Close()
+	err = sc.ReloadConfig(DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15360).withMaxConnections(2))
+	assert.Error(t, err)
+	_, err = sess.Exec("UPDATE people SET age = age + 1")
+	assert.Error(t, err)
+
+	// an invalid config isn't applied
+	err = sc.ReloadConfig(DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(-1))
+	assert.Error(t, err)
+	_, err = sess.Select("name").From("people").Load(&names)
+	assert.NoError(t, err)
+
+	sc.StopServer()
+	err = sc.WaitForClose()
+	require.NoError(t, err)
+
+	err = sc.ReloadConfig(reloadedConfig)
+	assert.Error(t, err)
+}
+
+func TestServerWriteProtection(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateEnvWithSeedData(t)
+	head, err := dEnv.DoltDB.ResolveRef(ctx, dEnv.RepoState.CWBHeadRef())
+	require.NoError(t, err)
+	require.NoError(t, dEnv.DoltDB.NewBranchAtCommit(ctx, ref.NewBranchRef("feature-x"), head))
+
+	const dbName = "dolt"
+	serverConfig := DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15355).
+		withDBWriteProtection(dbName, dsqle.WriteProtection{ProtectedBranches: []string{"mast*"}})
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(ctx, "", serverConfig, sc, dEnv)
+	}()
+	err = sc.WaitForStart()
+	require.NoError(t, err)
+
+	db, err := dbr.Open("mysql", ConnectionString(serverConfig)+dbName, nil)
+	require.NoError(t, err)
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var count int
+	require.NoError(t, conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM people").Scan(&count))
+	assert.Equal(t, 3, count)
+
+	protectedErr := dsqle.ErrProtectedBranch.New("master", dbName).Error()
+	for _, query := range []string{
+		"UPDATE people SET age = age + 1",
+		"CREATE TABLE nope (pk int primary key)",
+		"SELECT DOLT_COMMIT('-a', '-m', 'nope')",
+		"SELECT DOLT_ADD('.')",
+	} {
+		_, err = conn.ExecContext(ctx, query)
+		if assert.Error(t, err, query) {
+			assert.Contains(t, err.Error(), protectedErr, query)
+		}
+	}
+
+	require.NoError(t, conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM people WHERE age > 32").Scan(&count))
+	assert.Equal(t, 0, count)
+
+	_, err = conn.ExecContext(ctx, "USE dolt/feature-x")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "CREATE TABLE feature (pk int primary key)")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "INSERT INTO feature VALUES (1)")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "SELECT DOLT_COMMIT('-a', '-m', 'add feature')")
+	require.NoError(t, err)
+
+	require.NoError(t, conn.Close())
+	sc.StopServer()
+	require.NoError(t, sc.WaitForClose())
+
+	readOnlyConfig := DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15356).
+		withDBWriteProtection(dbName, dsqle.WriteProtection{ReadOnly: true})
+
+	sc = CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(ctx, "", readOnlyConfig, sc, dEnv)
+	}()
+	err = sc.WaitForStart()
+	require.NoError(t, err)
+
+	db, err = dbr.Open("mysql", ConnectionString(readOnlyConfig)+dbName, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM people").Scan(&count))
+	assert.Equal(t, 3, count)
+	_, err = db.Exec("DELETE FROM people")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), dsqle.ErrReadOnlyDatabase.New(dbName).Error())
+	}
+	_, err = db.Exec("INSERT INTO `dolt/feature-x`.feature VALUES (2)")
+	assert.Error(t, err)
+
+	// reloading the config changes the write protection of the database and its revision databases immediately
+	require.NoError(t, sc.ReloadConfig(DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15356)))
+	_, err = db.Exec("INSERT INTO `dolt/feature-x`.feature VALUES (2)")
+	assert.NoError(t, err)
+	_, err = db.Exec("UPDATE people SET age = age + 1")
+	assert.NoError(t, err)
+
+	require.NoError(t, sc.ReloadConfig(readOnlyConfig))
+	_, err = db.Exec("INSERT INTO `dolt/feature-x`.feature VALUES (3)")
+	assert.Error(t, err)
+	_, err = db.Exec("UPDATE people SET age = age + 1")
+	assert.Error(t, err)
+}
+
+func TestServerQueryLimits(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateEnvWithSeedData(t)
+
+	const dbName = "dolt"
+	serverConfig := DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15357).withMaxConnections(3).
+		withQueryLimits(0, 2)
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(ctx, "", serverConfig, sc, dEnv)
+	}()
+	err := sc.WaitForStart()
+	require.NoError(t, err)
+
+	db, err := dbr.Open("mysql", ConnectionString(serverConfig)+dbName, nil)
+	require.NoError(t, err)
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var count int
+	err = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM people").Scan(&count)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "read more than 2 rows")
+	}
+	_, err = conn.ExecContext(ctx, "SET dolt_max_query_rows = 0")
+	require.NoError(t, err)
+	require.NoError(t, conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM people").Scan(&count))
+	assert.Equal(t, 3, count)
+
+	_, err = conn.ExecContext(ctx, "SET max_execution_time = 100")
+	require.NoError(t, err)
+	start := time.Now()
+	_, err = conn.ExecContext(ctx, "SELECT SLEEP(5)")
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	if assert.IsType(t, &mysql.MySQLError{}, err) {
+		assert.Equal(t, uint16(erQueryTimeout), err.(*mysql.MySQLError).Number)
+	}
+
+	_, err = conn.ExecContext(ctx, "SET GLOBAL max_execution_time = 60000")
+	require.NoError(t, err)
+	other, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer other.Close()
+	var maxExecutionTime, otherID int
+	require.NoError(t, other.QueryRowContext(ctx, "SELECT @@max_execution_time, CONNECTION_ID()").Scan(&maxExecutionTime, &otherID))
+	assert.Equal(t, 60000, maxExecutionTime)
+
+	_, err = conn.ExecContext(ctx, "CREATE USER 'analyst'@'%' IDENTIFIED BY 'secret'")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "GRANT SELECT ON dolt.people TO analyst")
+	require.NoError(t, err)
+	analystDB, err := dbr.Open("mysql", "analyst:secret@tcp(localhost:15357)/"+dbName, nil)
+	require.NoError(t, err)
+	defer analystDB.Close()
+	analyst, err := analystDB.Conn(ctx)
+	require.NoError(t, err)
+	_, err = analyst.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", otherID))
+	if assert.IsType(t, &mysql.MySQLError{}, err) {
+		assert.Equal(t, uint16(1095), err.(*mysql.MySQLError).Number)
+	}
+	_, err = analyst.ExecContext(ctx, "SET GLOBAL max_execution_time = 1")
+	assert.Error(t, err)
+	require.NoError(t, analyst.Close())
+
+	errCh := make(chan error)
+	go func() {
+		_, err := other.ExecContext(ctx, "SELECT SLEEP(5)")
+		errCh <- err
+	}()
+	time.Sleep(250 * time.Millisecond)
+	_, err = conn.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", otherID))
+	require.NoError(t, err)
+	err = <-errCh
+	if assert.IsType(t, &mysql.MySQLError{}, err) {
+		assert.Equal(t, uint16(1317), err.(*mysql.MySQLError).Number)
+	}
+
+	for i := 0; i < 10; i++ {
+		err = other.QueryRowContext(ctx, "SELECT COUNT(*) FROM people").Scan(&count)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "read more than 2 rows")
+		}
+	}
+	_, err = other.ExecContext(ctx, "SET dolt_max_query_rows = 3")
+	require.NoError(t, err)
+	require.NoError(t, other.QueryRowContext(ctx, "SELECT COUNT(*) FROM people").Scan(&count))
+	assert.Equal(t, 3, count)
+}
+
+func TestServerChangeStream(t *testing.T) {
+	dir, err := ioutil.TempDir("", "change_stream")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	changesPath := filepath.Join(dir, "changes.jsonl")
+
+	dEnv := dtestutils.CreateEnvWithSeedData(t)
+	serverConfig := DefaultServerConfig().withLogLevel(LogLevel_Fatal).withPort(15358).
+		withChangeStream(changesPath, "localhost", 15359)
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(context.Background(), "", serverConfig, sc, dEnv)
+	}()
+	err = sc.WaitForStart()
+	require.NoError(t, err)
+
+	resp, err := http.Get("http://localhost:15359/changes?database=dolt&branch=master")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	const dbName = "dolt"
+	conn, err := dbr.Open("mysql", ConnectionString(serverConfig)+dbName, nil)
+	require.NoError(t, err)
+	sess := conn.NewSession(nil)
+
+	// the people table is added by the first commit, and then changed by the second
+	var hash string
+	require.NoError(t, sess.SelectBySql("SELECT DOLT_COMMIT('-a', '-m', 'add people')").LoadOne(&hash))
+	_, err = sess.Exec("UPDATE people SET age = 33 WHERE name = 'Bill Billerson'")
+	require.NoError(t, err)
+	_, err = sess.Exec("DELETE FROM people WHERE name = 'John Johnson'")
+	require.NoError(t, err)
+	require.NoError(t, sess.SelectBySql("SELECT DOLT_COMMIT('-a', '-m', 'change people')").LoadOne(&hash))
+
+	require.NoError(t, conn.Close())
+	sc.StopServer()
+	err = sc.WaitForClose()
+	require.NoError(t, err)
+
+	readEvents := func(rd io.Reader) []cdc.Event {
+		var events []cdc.Event
+		dec := json.NewDecoder(rd)
+		for {
+			var evt cdc.Event
+			err := dec.Decode(&evt)
+			if err == io.EOF {
+				return events
+			}
+			require.NoError(t, err)
+			events = append(events, evt)
+		}
+	}
+
+	f, err := os.Open(changesPath)
+	require.NoError(t, err)
+	defer f.Close()
+	events := readEvents(f)
+
+	counts := make(map[cdc.ChangeType]int)
+	for _, evt := range events {
+		assert.Equal(t, dbName, evt.Database)
+		assert.Equal(t, "master", evt.Branch)
+		assert.Equal(t, "people", evt.Table)
+		counts[evt.Type]++
+
+		switch evt.Type {
+		case cdc.Update:
+			assert.Equal(t, hash, evt.Commit)
+			assert.Equal(t, float64(32), evt.Old["age"])
+			assert.Equal(t, float64(33), evt.New["age"])
+		case cdc.Delete:
+			assert.Equal(t, hash, evt.Commit)
+			assert.Equal(t, "John Johnson", evt.Old["name"])
+			assert.Equal(t, evt.Old["id"], evt.PK["id"])
+		}
+	}
+	assert.Equal(t, map[cdc.ChangeType]int{cdc.Insert: 3, cdc.Update: 1, cdc.Delete: 1}, counts)
+
+	// the HTTP endpoint streams the same events, and ends its responses when the server stops
+	assert.Equal(t, events, readEvents(resp.Body))
+}
+
+func TestServerTLS(t *testing.T) {
+	certPEM, keyPEM := generateTestCert(t)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(certPEM))
+	err := mysql.RegisterTLSConfig("dolt-test", &tls.Config{RootCAs: roots, ServerName: "localhost"})
+	require.NoError(t, err)
+
+	assert.Error(t, ValidateConfig(DefaultServerConfig().withTLS("key.pem", "")))
+	assert.Error(t, ValidateConfig(DefaultServerConfig().withTLS("", "cert.pem")))
+	assert.Error(t, ValidateConfig(DefaultServerConfig().withRequireSecureTransport(true)))
+
+	tests := []struct {
+		name          string
+		requireSecure bool
+		port          int
+	}{
+		{"tls optional", false, 15320},
+		{"tls required", true, 15321},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := dtestutils.CreateEnvWithSeedData(t)
+			require.NoError(t, env.FS.WriteFile("cert.pem", certPEM))
+			require.NoError(t, env.FS.WriteFile("key.pem", keyPEM))
+
+			serverConfig := DefaultServerConfig().
+				withLogLevel(LogLevel_Fatal).
+				withPort(test.port).
+				withMaxConnections(2).
+				withTLS("key.pem", "cert.pem").
+				withRequireSecureTransport(test.requireSecure)
+
+			sc := CreateServerController()
+			defer sc.StopServer()
+			go func() {
+				_, _ = Serve(context.Background(), "", serverConfig, sc, env)
+			}()
+			err := sc.WaitForStart()
+			require.NoError(t, err)
+
+			secureConn, err := dbr.Open("mysql", ConnectionString(serverConfig)+"dolt?tls=dolt-test", nil)
+			require.NoError(t, err)
+			defer secureConn.Close()
+
+			var peoples []testPerson
+			_, err = secureConn.NewSession(nil).Select("*").From("people").LoadContext(context.Background(), &peoples)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []testPerson{bill, john, rob}, peoples)
+
+			plainConn, err := dbr.Open("mysql", ConnectionString(serverConfig)+"dolt", nil)
+			require.NoError(t, err)
+			defer plainConn.Close()
+
+			if test.requireSecure {
+				assert.Error(t, plainConn.Ping())
+			} else {
+				assert.NoError(t, plainConn.Ping())
+			}
+		})
+	}
+}
@@ -15,10 +15,13 @@
 package sqlserver
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	dsqle "github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
 )
 
 // LogLevel defines the available levels of logging for the server.
@@ -34,18 +37,34 @@ const (
 )
 
 const (
-	defaultHost             = "localhost"
-	defaultPort             = 3306
-	defaultUser             = "root"
-	defaultPass             = ""
-	defaultTimeout          = 8 * 60 * 60 * 1000 // 8 hours, same as MySQL
-	defaultReadOnly         = false
-	defaultLogLevel         = LogLevel_Info
-	defaultAutoCommit       = true
-	defaultMaxConnections   = 1
-	defaultQueryParallelism = 2
+	defaultHost                   = "localhost"
+	defaultPort                   = 3306
+	defaultUser                   = "root"
+	defaultPass                   = ""
+	defaultPrivilegeFilePath      = ""
+	defaultBootstrapFilePath      = ""
+	defaultTimeout                = 8 * 60 * 60 * 1000 // 8 hours, same as MySQL
+	defaultReadOnly               = false
+	defaultLogLevel               = LogLevel_Info
+	defaultAutoCommit             = true
+	defaultMaxConnections         = 1
+	defaultQueryParallelism       = 2
+	defaultMaxExecutionTime       = 0
+	defaultMaxQueryRows           = 0
+	defaultRequireSecureTransport = false
+	defaultMetricsHost            = "localhost"
+	defaultMetricsPort            = -1
+	defaultSlowQueryLogFile       = ""
+	defaultSlowQueryThreshold     = 1000
+	defaultTraceFile              = ""
+	defaultChangeStreamFile       = ""
+	defaultChangeStreamHost       = "localhost"
+	defaultChangeStreamPort       = -1
 )
 
+// TraceFileStdout is the trace file which writes query traces to stdout
+const TraceFileStdout = "stdout"
+
 // String returns the string representation of the log level.
 func (level LogLevel) String() string {
 	switch level {
@@ -76,6 +95,12 @@ type ServerConfig interface {
 	User() string
 	// Password returns the password that connecting clients must use.
 	Password() string
+	// PrivilegeFilePath returns the path of the file that users and privileges are persisted to. If empty, users
+	// created while the server runs are not persisted.
+	PrivilegeFilePath() string
+	// BootstrapFilePath returns the path of a file of account management statements which are run when the server
+	// starts and the privilege file does not exist yet.
+	BootstrapFilePath() string
 	// ReadTimeout returns the read timeout in milliseconds
 	ReadTimeout() uint64
 	// WriteTimeout returns the write timeout in milliseconds
@@ -90,10 +115,46 @@ type ServerConfig interface {
 	// a multiple db configuration. If nil is returned the server will look for a database in the current directory and
 	// give it a name automatically.
 	DatabaseNamesAndPaths() []env.EnvNameAndPath
+	// DatabaseWriteProtections returns the restrictions on the writes which may be made to each database, by name.
+	// Databases which aren't in the map may be written to unless the whole server is read only.
+	DatabaseWriteProtections() map[string]dsqle.WriteProtection
 	// MaxConnections returns the maximum number of simultaneous connections the server will allow.  The default is 1
 	MaxConnections() uint64
 	// QueryParallelism returns the parallelism that should be used by the go-mysql-server analyzer
 	QueryParallelism() int
+	// MaxExecutionTime returns the default of the max_execution_time session variable, the number of milliseconds a
+	// SELECT statement may run for before it is interrupted. 0 means there is no limit.
+	MaxExecutionTime() uint64
+	// MaxQueryRows returns the default of the dolt_max_query_rows session variable, the number of rows a query may
+	// read from tables before it is interrupted. 0 means there is no limit.
+	MaxQueryRows() uint64
+	// TLSKey returns a path to the servers PEM-encoded private TLS key. "" if there is none.
+	TLSKey() string
+	// TLSCert returns a path to the servers PEM-encoded TLS certificate chain. "" if there is none.
+	TLSCert() string
+	// RequireSecureTransport is true if the server should reject non-TLS connections.
+	RequireSecureTransport() bool
+	// MetricsHost returns the host that the HTTP listener serving the server's metrics will run on.
+	MetricsHost() string
+	// MetricsPort returns the port of the HTTP listener serving the server's metrics, or -1 if metrics are disabled.
+	MetricsPort() int
+	// MetricsLabels returns labels which are added to every metric the server exports.
+	MetricsLabels() map[string]string
+	// SlowQueryLogFile returns the path of the file that queries which take longer than the slow query threshold are
+	// logged to. "" if slow queries are not logged.
+	SlowQueryLogFile() string
+	// SlowQueryThreshold returns the number of milliseconds a query must run for to be logged as a slow query.
+	SlowQueryThreshold() uint64
+	// TraceFile returns the path of the file that the opentracing spans of each query are written to, or
+	// TraceFileStdout to write them to stdout. "" if queries are not traced.
+	TraceFile() string
+	// ChangeStreamFile returns the path of the file that the row level changes made by moving the heads of branches
+	// are appended to. "" if changes are not written to a file.
+	ChangeStreamFile() string
+	// ChangeStreamHost returns the host that the HTTP listener streaming row level changes will run on.
+	ChangeStreamHost() string
+	// ChangeStreamPort returns the port of the HTTP listener streaming row level changes, or -1 if it is disabled.
+	ChangeStreamPort() int
 }
 
 type commandLineServerConfig struct {
@@ -101,13 +162,30 @@ type commandLineServerConfig struct {
 	port             int
 	user             string
 	password         string
+	privilegeFile    string
+	bootstrapFile    string
 	timeout          uint64
 	readOnly         bool
 	logLevel         LogLevel
 	dbNamesAndPaths  []env.EnvNameAndPath
+	dbProtections    map[string]dsqle.WriteProtection
 	autoCommit       bool
 	maxConnections   uint64
 	queryParallelism int
+	maxExecutionTime uint64
+	maxQueryRows     uint64
+	tlsKey           string
+	tlsCert          string
+	requireSecure    bool
+	metricsHost      string
+	metricsPort      int
+	metricsLabels    map[string]string
+	slowQueryLog     string
+	slowQueryMillis  uint64
+	traceFile        string
+	changeFile       string
+	changeHost       string
+	changePort       int
 }
 
 // Host returns the domain that the server will run on. Accepts an IPv4 or IPv6 address, in addition to localhost.
@@ -130,6 +208,18 @@ func (cfg *commandLineServerConfig) Password() string {
 	return cfg.password
 }
 
+// PrivilegeFilePath returns the path of the file that users and privileges are persisted to. If empty, users created
+// while the server runs are not persisted.
+func (cfg *commandLineServerConfig) PrivilegeFilePath() string {
+	return cfg.privilegeFile
+}
+
+// BootstrapFilePath returns the path of a file of account management statements which are run when the server starts
+// and the privilege file does not exist yet.
+func (cfg *commandLineServerConfig) BootstrapFilePath() string {
+	return cfg.bootstrapFile
+}
+
 // ReadTimeout returns the read and write timeouts.
 func (cfg *commandLineServerConfig) ReadTimeout() uint64 {
 	return cfg.timeout
@@ -165,6 +255,81 @@ func (cfg *commandLineServerConfig) QueryParallelism() int {
 	return cfg.queryParallelism
 }
 
+// MaxExecutionTime returns the default of the max_execution_time session variable, the number of milliseconds a SELECT
+// statement may run for before it is interrupted. 0 means there is no limit.
+func (cfg *commandLineServerConfig) MaxExecutionTime() uint64 {
+	return cfg.maxExecutionTime
+}
+
+// MaxQueryRows returns the default of the dolt_max_query_rows session variable, the number of rows a query may read
+// from tables before it is interrupted. 0 means there is no limit.
+func (cfg *commandLineServerConfig) MaxQueryRows() uint64 {
+	return cfg.maxQueryRows
+}
+
+// TLSKey returns a path to the servers PEM-encoded private TLS key. "" if there is none.
+func (cfg *commandLineServerConfig) TLSKey() string {
+	return cfg.tlsKey
+}
+
+// TLSCert returns a path to the servers PEM-encoded TLS certificate chain. "" if there is none.
+func (cfg *commandLineServerConfig) TLSCert() string {
+	return cfg.tlsCert
+}
+
+// RequireSecureTransport is true if the server should reject non-TLS connections.
+func (cfg *commandLineServerConfig) RequireSecureTransport() bool {
+	return cfg.requireSecure
+}
+
+// MetricsHost returns the host that the HTTP listener serving the server's metrics will run on.
+func (cfg *commandLineServerConfig) MetricsHost() string {
+	return cfg.metricsHost
+}
+
+// MetricsPort returns the port of the HTTP listener serving the server's metrics, or -1 if metrics are disabled.
+func (cfg *commandLineServerConfig) MetricsPort() int {
+	return cfg.metricsPort
+}
+
+// MetricsLabels returns labels which are added to every metric the server exports.
+func (cfg *commandLineServerConfig) MetricsLabels() map[string]string {
+	return cfg.metricsLabels
+}
+
+// SlowQueryLogFile returns the path of the file that queries which take longer than the slow query threshold are
+// logged to. "" if slow queries are not logged.
+func (cfg *commandLineServerConfig) SlowQueryLogFile() string {
+	return cfg.slowQueryLog
+}
+
+// SlowQueryThreshold returns the number of milliseconds a query must run for to be logged as a slow query.
+func (cfg *commandLineServerConfig) SlowQueryThreshold() uint64 {
+	return cfg.slowQueryMillis
+}
+
+// TraceFile returns the path of the file that the opentracing spans of each query are written to, or TraceFileStdout
+// to write them to stdout. "" if queries are not traced.
+func (cfg *commandLineServerConfig) TraceFile() string {
+	return cfg.traceFile
+}
+
+// ChangeStreamFile returns the path of the file that the row level changes made by moving the heads of branches are
+// appended to. "" if changes are not written to a file.
+func (cfg *commandLineServerConfig) ChangeStreamFile() string {
+	return cfg.changeFile
+}
+
+// ChangeStreamHost returns the host that the HTTP listener streaming row level changes will run on.
+func (cfg *commandLineServerConfig) ChangeStreamHost() string {
+	return cfg.changeHost
+}
+
+// ChangeStreamPort returns the port of the HTTP listener streaming row level changes, or -1 if it is disabled.
+func (cfg *commandLineServerConfig) ChangeStreamPort() int {
+	return cfg.changePort
+}
+
 // DatabaseNamesAndPaths returns an array of env.EnvNameAndPathObjects corresponding to the databases to be loaded in
 // a multiple db configuration. If nil is returned the server will look for a database in the current directory and
 // give it a name automatically.
@@ -172,6 +337,11 @@ func (cfg *commandLineServerConfig) DatabaseNamesAndPaths() []env.EnvNameAndPath
 	return cfg.dbNamesAndPaths
 }
 
+// DatabaseWriteProtections returns the restrictions on the writes which may be made to each database, by name.
+func (cfg *commandLineServerConfig) DatabaseWriteProtections() map[string]dsqle.WriteProtection {
+	return cfg.dbProtections
+}
+
 // withHost updates the host and returns the called `*commandLineServerConfig`, which is useful for chaining calls.
 func (cfg *commandLineServerConfig) withHost(host string) *commandLineServerConfig {
 	cfg.host = host
@@ -196,6 +366,13 @@ func (cfg *commandLineServerConfig) withPassword(password string) *commandLineSe
 	return cfg
 }
 
+// withPrivilegeFile updates the privilege file path and returns the called `*commandLineServerConfig`, which is useful
+// for chaining calls.
+func (cfg *commandLineServerConfig) withPrivilegeFile(privilegeFile string) *commandLineServerConfig {
+	cfg.privilegeFile = privilegeFile
+	return cfg
+}
+
 // withTimeout updates the timeout and returns the called `*commandLineServerConfig`, which is useful for chaining calls.
 func (cfg *commandLineServerConfig) withTimeout(timeout uint64) *commandLineServerConfig {
 	cfg.timeout = timeout
@@ -227,11 +404,76 @@ func (cfg *commandLineServerConfig) withQueryParallelism(queryParallelism int) *
 	return cfg
 }
 
+// withQueryLimits updates the default execution time and row limits of queries and returns the called
+// `*commandLineServerConfig`, which is useful for chaining calls.
+func (cfg *commandLineServerConfig) withQueryLimits(maxExecutionTime, maxQueryRows uint64) *commandLineServerConfig {
+	cfg.maxExecutionTime = maxExecutionTime
+	cfg.maxQueryRows = maxQueryRows
+	return cfg
+}
+
+// withTLS updates the TLS key and certificate paths and returns the called `*commandLineServerConfig`, which is useful
+// for chaining calls.
+func (cfg *commandLineServerConfig) withTLS(tlsKey, tlsCert string) *commandLineServerConfig {
+	cfg.tlsKey = tlsKey
+	cfg.tlsCert = tlsCert
+	return cfg
+}
+
+// withRequireSecureTransport updates the require secure transport flag and returns the called
+// `*commandLineServerConfig`, which is useful for chaining calls.
+func (cfg *commandLineServerConfig) withRequireSecureTransport(requireSecure bool) *commandLineServerConfig {
+	cfg.requireSecure = requireSecure
+	return cfg
+}
+
+// withMetrics updates the host, port and labels of the metrics listener and returns the called
+// `*commandLineServerConfig`, which is useful for chaining calls.
+func (cfg *commandLineServerConfig) withMetrics(host string, port int, labels map[string]string) *commandLineServerConfig {
+	cfg.metricsHost = host
+	cfg.metricsPort = port
+	cfg.metricsLabels = labels
+	return cfg
+}
+
+// withSlowQueryLog updates the slow query log file and threshold and returns the called `*commandLineServerConfig`,
+// which is useful for chaining calls.
+func (cfg *commandLineServerConfig) withSlowQueryLog(file string, thresholdMillis uint64) *commandLineServerConfig {
+	cfg.slowQueryLog = file
+	cfg.slowQueryMillis = thresholdMillis
+	return cfg
+}
+
+// withTraceFile updates the trace file and returns the called `*commandLineServerConfig`, which is useful for chaining
+// calls.
+func (cfg *commandLineServerConfig) withTraceFile(traceFile string) *commandLineServerConfig {
+	cfg.traceFile = traceFile
+	return cfg
+}
+
+// withChangeStream updates the change stream file and the host and port of the change stream listener and returns the
+// called `*commandLineServerConfig`, which is useful for chaining calls.
+func (cfg *commandLineServerConfig) withChangeStream(file, host string, port int) *commandLineServerConfig {
+	cfg.changeFile = file
+	cfg.changeHost = host
+	cfg.changePort = port
+	return cfg
+}
+
 func (cfg *commandLineServerConfig) withDBNamesAndPaths(dbNamesAndPaths []env.EnvNameAndPath) *commandLineServerConfig {
 	cfg.dbNamesAndPaths = dbNamesAndPaths
 	return cfg
 }
 
+// withDBWriteProtection restricts the writes which may be made to the database |dbName| according to |wp|
+func (cfg *commandLineServerConfig) withDBWriteProtection(dbName string, wp dsqle.WriteProtection) *commandLineServerConfig {
+	if cfg.dbProtections == nil {
+		cfg.dbProtections = make(map[string]dsqle.WriteProtection)
+	}
+	cfg.dbProtections[dbName] = wp
+	return cfg
+}
+
 // DefaultServerConfig creates a `*ServerConfig` that has all of the options set to their default values.
 func DefaultServerConfig() *commandLineServerConfig {
 	return &commandLineServerConfig{
@@ -239,12 +481,25 @@ func DefaultServerConfig() *commandLineServerConfig {
 		port:             defaultPort,
 		user:             defaultUser,
 		password:         defaultPass,
+		privilegeFile:    defaultPrivilegeFilePath,
+		bootstrapFile:    defaultBootstrapFilePath,
 		timeout:          defaultTimeout,
 		readOnly:         defaultReadOnly,
 		logLevel:         defaultLogLevel,
 		autoCommit:       defaultAutoCommit,
 		maxConnections:   defaultMaxConnections,
 		queryParallelism: defaultQueryParallelism,
+		maxExecutionTime: defaultMaxExecutionTime,
+		maxQueryRows:     defaultMaxQueryRows,
+		requireSecure:    defaultRequireSecureTransport,
+		metricsHost:      defaultMetricsHost,
+		metricsPort:      defaultMetricsPort,
+		slowQueryLog:     defaultSlowQueryLogFile,
+		slowQueryMillis:  defaultSlowQueryThreshold,
+		traceFile:        defaultTraceFile,
+		changeFile:       defaultChangeStreamFile,
+		changeHost:       defaultChangeStreamHost,
+		changePort:       defaultChangeStreamPort,
 	}
 }
 
@@ -265,9 +520,69 @@ func ValidateConfig(config ServerConfig) error {
 	if config.LogLevel().String() == "unknown" {
 		return fmt.Errorf("loglevel is invalid: %v\n", string(config.LogLevel()))
 	}
+	if config.TLSKey() == "" && config.TLSCert() != "" {
+		return fmt.Errorf("tls_key must be supplied along with tls_cert")
+	}
+	if config.TLSKey() != "" && config.TLSCert() == "" {
+		return fmt.Errorf("tls_cert must be supplied along with tls_key")
+	}
+	if config.RequireSecureTransport() && config.TLSKey() == "" {
+		return fmt.Errorf("require_secure_transport can only be enabled when tls_key and tls_cert are supplied")
+	}
+	if config.MetricsPort() != -1 {
+		if config.MetricsPort() < 1024 || config.MetricsPort() > 65535 {
+			return fmt.Errorf("metrics port is not in the range between 1024-65535: %v\n", config.MetricsPort())
+		}
+		if config.MetricsPort() == config.Port() {
+			return fmt.Errorf("metrics port cannot be the same as the listener port: %v\n", config.MetricsPort())
+		}
+		for name := range config.MetricsLabels() {
+			if !isValidMetricLabelName(name) {
+				return fmt.Errorf("metrics label name is invalid: %v\n", name)
+			}
+		}
+	}
+	if config.ChangeStreamPort() != -1 {
+		if config.ChangeStreamPort() < 1024 || config.ChangeStreamPort() > 65535 {
+			return fmt.Errorf("change stream port is not in the range between 1024-65535: %v\n", config.ChangeStreamPort())
+		}
+		if config.ChangeStreamPort() == config.Port() || config.ChangeStreamPort() == config.MetricsPort() {
+			return fmt.Errorf("change stream port cannot be the same as the listener or metrics port: %v\n", config.ChangeStreamPort())
+		}
+	}
+	for dbName, wp := range config.DatabaseWriteProtections() {
+		if err := wp.Validate(); err != nil {
+			return fmt.Errorf("database %s: %v", dbName, err)
+		}
+	}
 	return nil
 }
 
+// LoadTLSConfig loads the certificate chain and private key of |config| from |fs|. If the config has no TLS key and
+// certificate, nil is returned.
+func LoadTLSConfig(fs filesys.ReadableFS, config ServerConfig) (*tls.Config, error) {
+	if config.TLSKey() == "" && config.TLSCert() == "" {
+		return nil, nil
+	}
+
+	certPEM, err := fs.ReadFile(config.TLSCert())
+	if err != nil {
+		return nil, fmt.Errorf("error reading tls_cert %s: %v", config.TLSCert(), err)
+	}
+
+	keyPEM, err := fs.ReadFile(config.TLSKey())
+	if err != nil {
+		return nil, fmt.Errorf("error reading tls_key %s: %v", config.TLSKey(), err)
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("error loading tls_cert %s and tls_key %s: %v", config.TLSCert(), config.TLSKey(), err)
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
 // ConnectionString returns a Data Source Name (DSN) to be used by go clients for connecting to a running server.
 func ConnectionString(config ServerConfig) string {
 	return fmt.Sprintf("%v:%v@tcp(%v:%v)/", config.User(), config.Password(), config.Host(), config.Port())
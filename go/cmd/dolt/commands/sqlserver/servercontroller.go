@@ -15,9 +15,12 @@
 package sqlserver
 
 import (
+	"errors"
 	"sync"
 )
 
+var errServerNotRunning = errors.New("the server is not running")
+
 type ServerController struct {
 	//serverClosed    *sync.WaitGroup
 	//serverStarted   *sync.WaitGroup
@@ -27,6 +30,8 @@ type ServerController struct {
 	closeRegistered *sync.Once
 	stopRegistered  *sync.Once
 	closeFunction   func() error
+	reloadMu        *sync.Mutex
+	reloadFunction  func(ServerConfig) error
 	startError      error
 	closeError      error
 }
@@ -39,6 +44,7 @@ func CreateServerController() *ServerController {
 		closeCalled:     &sync.Once{},
 		closeRegistered: &sync.Once{},
 		stopRegistered:  &sync.Once{},
+		reloadMu:        &sync.Mutex{},
 	}
 	return sc
 }
@@ -55,6 +61,34 @@ func (controller *ServerController) registerCloseFunction(startError error, clos
 	})
 }
 
+// registerReloadFunction is called within `Serve` to associate the function which applies a changed config to the
+// running server with future `ReloadConfig` calls.
+func (controller *ServerController) registerReloadFunction(reloadFunc func(ServerConfig) error) {
+	controller.reloadMu.Lock()
+	defer controller.reloadMu.Unlock()
+	controller.reloadFunction = reloadFunc
+}
+
+// ReloadConfig applies |serverConfig| to the running server without closing the connections which are open. An error
+// is returned if the server is not running or the config is invalid, in which case the server keeps its current
+// config.
+func (controller *ServerController) ReloadConfig(serverConfig ServerConfig) error {
+	controller.reloadMu.Lock()
+	defer controller.reloadMu.Unlock()
+
+	if controller.reloadFunction == nil {
+		return errServerNotRunning
+	}
+
+	select {
+	case <-controller.closeCh:
+		return errServerNotRunning
+	default:
+	}
+
+	return controller.reloadFunction(serverConfig)
+}
+
 // serverStopped is called within `Serve` to signal that the server has stopped and set the exit code.
 // Only the first call will register and unblock, thus it is safe to be called multiple times.
 func (controller *ServerController) serverStopped(closeError error) {
@@ -0,0 +1,95 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// slowQueryLog logs queries which take longer than a threshold to run as lines of JSON
+type slowQueryLog struct {
+	logger    *logrus.Logger
+	threshold time.Duration
+}
+
+// slowQuery describes a query run by the server
+type slowQuery struct {
+	query        string
+	user         string
+	database     string
+	branch       string
+	rowsSent     uint64
+	rowsAffected uint64
+	duration     time.Duration
+	err          error
+}
+
+func newSlowQueryLog(wr io.Writer, threshold time.Duration) *slowQueryLog {
+	logger := logrus.New()
+	logger.Out = wr
+	logger.Formatter = &logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano}
+	logger.Level = logrus.InfoLevel
+
+	return &slowQueryLog{logger: logger, threshold: threshold}
+}
+
+// openSlowQueryLog opens the file at |path| for appending and returns a slowQueryLog writing to it, along with the
+// function that closes the file.
+func openSlowQueryLog(path string, threshold time.Duration) (*slowQueryLog, func() error, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newSlowQueryLog(f, threshold), f.Close, nil
+}
+
+// passwordLiteralRegex matches the string literals holding passwords in account statements, such as
+// IDENTIFIED BY 'pass', SET PASSWORD FOR user = 'pass' and PASSWORD('pass'). The first group is everything before the
+// literal.
+var passwordLiteralRegex = regexp.MustCompile(`(?i)(\b(?:IDENTIFIED(?:\s+WITH\s+\S+)?\s+(?:BY|AS)|PASSWORD(?:\s+FOR\s+\S+)?\s*(?:\(|=\s*(?:PASSWORD\s*\()?))\s*)(?:'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*")`)
+
+// redactPasswords returns |query| with the passwords it holds replaced, so they aren't written to the log.
+func redactPasswords(query string) string {
+	return passwordLiteralRegex.ReplaceAllString(query, "${1}'<redacted>'")
+}
+
+// isSlow returns whether a query which took |duration| to run should be logged.
+func (l *slowQueryLog) isSlow(duration time.Duration) bool {
+	return duration >= l.threshold
+}
+
+func (l *slowQueryLog) log(q slowQuery) {
+	entry := l.logger.WithFields(logrus.Fields{
+		"query":         redactPasswords(q.query),
+		"user":          q.user,
+		"database":      q.database,
+		"branch":        q.branch,
+		"rows_sent":     q.rowsSent,
+		"rows_affected": q.rowsAffected,
+		"duration_ms":   float64(q.duration) / float64(time.Millisecond),
+	})
+
+	if q.err != nil {
+		entry = entry.WithError(q.err)
+	}
+
+	entry.Info("slow query")
+}
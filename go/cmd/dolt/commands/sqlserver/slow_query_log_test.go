@@ -0,0 +1,68 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactPasswords(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{
+			"CREATE USER bob IDENTIFIED BY 'hunter2'",
+			"CREATE USER bob IDENTIFIED BY '<redacted>'",
+		},
+		{
+			`create user 'bob'@'%' identified with mysql_native_password by "it's"`,
+			`create user 'bob'@'%' identified with mysql_native_password by '<redacted>'`,
+		},
+		{
+			"ALTER USER bob IDENTIFIED BY 'a\\'b''c', alice IDENTIFIED BY 'd'",
+			"ALTER USER bob IDENTIFIED BY '<redacted>', alice IDENTIFIED BY '<redacted>'",
+		},
+		{
+			"SET PASSWORD FOR 'bob'@'%' = 'hunter2'",
+			"SET PASSWORD FOR 'bob'@'%' = '<redacted>'",
+		},
+		{
+			"SET PASSWORD = PASSWORD('hunter2')",
+			"SET PASSWORD = PASSWORD('<redacted>')",
+		},
+		{
+			"SELECT * FROM people WHERE name = 'bob'",
+			"SELECT * FROM people WHERE name = 'bob'",
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, redactPasswords(test.query))
+	}
+}
+
+func TestSlowQueryLogRedactsPasswords(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := newSlowQueryLog(buf, 0)
+	l.log(slowQuery{query: "CREATE USER bob IDENTIFIED BY 'hunter2'", user: "root", duration: time.Second})
+
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.Contains(t, buf.String(), "IDENTIFIED BY '\\u003credacted\\u003e'")
+}
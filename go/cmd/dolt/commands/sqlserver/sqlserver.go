@@ -18,7 +18,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 
 	"github.com/fatih/color"
 	"gopkg.in/yaml.v2"
@@ -43,6 +46,7 @@ const (
 	noAutoCommitFlag     = "no-auto-commit"
 	configFileFlag       = "config"
 	queryParallelismFlag = "query-parallelism"
+	privilegeFileFlag    = "privilege-file"
 )
 
 var sqlServerDocs = cli.CommandDocumentationContent{
@@ -62,12 +66,16 @@ SUPPORTED CONFIG FILE FIELDS:
 
 		{{.EmphasisLeft}}behavior.read_only{{.EmphasisRight}} - If true database modification is disabled
 
-		{{.EmphasisLeft}}behavior.autocommit{{.EmphasisRight}} - If true write queries will automatically alter the working set. When working with autocommit enabled it is highly recommended that listener.max_connections be set to 1 as concurrency issues will arise otherwise
+		{{.EmphasisLeft}}behavior.autocommit{{.EmphasisRight}} - If true write queries will automatically alter the working set. Otherwise changes are written to the working set by {{.EmphasisLeft}}COMMIT{{.EmphasisRight}}. Changes committed by concurrent connections are merged, and a transaction which changes the same row as one committed since it began fails to commit and is rolled back
 
 		{{.EmphasisLeft}}user.name{{.EmphasisRight}} - The username that connections should use for authentication
 
 		{{.EmphasisLeft}}user.password{{.EmphasisRight}} - The password that connections should use for authentication.
 
+		{{.EmphasisLeft}}user.privilege_file{{.EmphasisRight}} - A file that users created with {{.EmphasisLeft}}CREATE USER{{.EmphasisRight}} and the privileges given to them with {{.EmphasisLeft}}GRANT{{.EmphasisRight}} are persisted to. The user given by {{.EmphasisLeft}}user.name{{.EmphasisRight}} always has every privilege. If not set, users and privileges are lost when the server stops
+
+		{{.EmphasisLeft}}user.bootstrap_file{{.EmphasisRight}} - A file of {{.EmphasisLeft}}CREATE USER{{.EmphasisRight}} and {{.EmphasisLeft}}GRANT{{.EmphasisRight}} statements which are run as {{.EmphasisLeft}}user.name{{.EmphasisRight}} when the server starts and the privilege file does not exist yet
+
 		{{.EmphasisLeft}}listener.host{{.EmphasisRight}} - The host address that the server will run on.  This may be {{.EmphasisLeft}}localhost{{.EmphasisRight}} or an IPv4 or IPv6 address
 
 		{{.EmphasisLeft}}listener.port{{.EmphasisRight}} - The port that the server should listen on
@@ -78,18 +86,58 @@ SUPPORTED CONFIG FILE FIELDS:
 
 		{{.EmphasisLeft}}listener.write_timeout_millis{{.EmphasisRight}} - The number of milliseconds that the server will wait for a write operation
 
+		{{.EmphasisLeft}}listener.tls_key{{.EmphasisRight}} - A path to an unencrypted private TLS key in PEM format. When given along with {{.EmphasisLeft}}listener.tls_cert{{.EmphasisRight}}, clients may connect using TLS
+
+		{{.EmphasisLeft}}listener.tls_cert{{.EmphasisRight}} - A path to the TLS certificate chain of the server in PEM format
+
+		{{.EmphasisLeft}}listener.require_secure_transport{{.EmphasisRight}} - If true connections which do not use TLS are rejected. Requires {{.EmphasisLeft}}listener.tls_key{{.EmphasisRight}} and {{.EmphasisLeft}}listener.tls_cert{{.EmphasisRight}}
+
 		{{.EmphasisLeft}}performance.query_parallelism{{.EmphasisRight}} - Amount of go routines spawned to process each query
 
+		{{.EmphasisLeft}}performance.max_execution_time_millis{{.EmphasisRight}} - The default of the {{.EmphasisLeft}}max_execution_time{{.EmphasisRight}} session variable, the number of milliseconds a SELECT statement may run for before it is interrupted. Defaults to 0, which is no limit
+
+		{{.EmphasisLeft}}performance.max_query_rows{{.EmphasisRight}} - The default of the {{.EmphasisLeft}}dolt_max_query_rows{{.EmphasisRight}} session variable, the number of rows a query may read from tables, including system tables such as {{.EmphasisLeft}}dolt_history_<table>{{.EmphasisRight}} and {{.EmphasisLeft}}dolt_diff_<table>{{.EmphasisRight}}, before it is interrupted. Defaults to 0, which is no limit
+
+		{{.EmphasisLeft}}metrics.host{{.EmphasisRight}} - The host address that the HTTP listener serving the server's metrics will run on. Defaults to {{.EmphasisLeft}}localhost{{.EmphasisRight}}
+
+		{{.EmphasisLeft}}metrics.port{{.EmphasisRight}} - The port of the HTTP listener serving the server's metrics at {{.EmphasisLeft}}/metrics{{.EmphasisRight}} in the Prometheus text format. Metrics include connection and query latency histograms, per database transaction commit counts, and the chunk store statistics of each database. If not set, metrics are not served
+
+		{{.EmphasisLeft}}metrics.labels{{.EmphasisRight}} - A map of label names to values which are added to every metric
+
+		{{.EmphasisLeft}}slow_query_log.file{{.EmphasisRight}} - A file that queries which take longer than {{.EmphasisLeft}}slow_query_log.threshold_millis{{.EmphasisRight}} to run are appended to as lines of JSON, with the query, user, database, branch, number of rows sent and affected, and duration. If not set, slow queries are not logged
+
+		{{.EmphasisLeft}}slow_query_log.threshold_millis{{.EmphasisRight}} - The number of milliseconds a query must run for to be logged as a slow query. Defaults to 1000
+
+		{{.EmphasisLeft}}tracing.file{{.EmphasisRight}} - A file that the opentracing spans of the analysis and execution of each query are appended to as lines of JSON, or {{.EmphasisLeft}}stdout{{.EmphasisRight}}. If not set, queries are not traced
+
+		{{.EmphasisLeft}}change_stream.file{{.EmphasisRight}} - A file that the row level changes made by moving the head of a branch are appended to as lines of JSON. If not set, changes are not written to a file
+
+		{{.EmphasisLeft}}change_stream.host{{.EmphasisRight}} - The host address that the HTTP listener streaming changes will run on. Defaults to {{.EmphasisLeft}}localhost{{.EmphasisRight}}
+
+		{{.EmphasisLeft}}change_stream.port{{.EmphasisRight}} - The port of the HTTP listener which streams changes at {{.EmphasisLeft}}/changes{{.EmphasisRight}} as lines of JSON, optionally limited to those of a single database or branch with the {{.EmphasisLeft}}database{{.EmphasisRight}} and {{.EmphasisLeft}}branch{{.EmphasisRight}} query parameters. If not set, changes are not served
+
 		{{.EmphasisLeft}}databases{{.EmphasisRight}} - a list of dolt data repositories to make available as SQL databases. If databases is missing or empty then the working directory must be a valid dolt data repository which will be made available as a SQL database
 		
 		{{.EmphasisLeft}}databases[i].path{{.EmphasisRight}} - A path to a dolt data repository
 		
 		{{.EmphasisLeft}}databases[i].name{{.EmphasisRight}} - The name that the database corresponding to the given path should be referenced via SQL
 
-If a config file is not provided many of these settings may be configured on the command line.`,
+		{{.EmphasisLeft}}databases[i].read_only{{.EmphasisRight}} - If true, every write to the database is rejected, while the other databases remain writable. Defaults to false
+
+		{{.EmphasisLeft}}databases[i].protected_branches{{.EmphasisRight}} - A list of patterns, such as {{.EmphasisLeft}}main{{.EmphasisRight}} or {{.EmphasisLeft}}release/*{{.EmphasisRight}}, matching branches of the database which can't be written to. Writes, {{.EmphasisLeft}}DOLT_COMMIT(){{.EmphasisRight}} and {{.EmphasisLeft}}MERGE(){{.EmphasisRight}} on a protected branch are rejected, while other branches stay writable
+
+If a config file is not provided many of these settings may be configured on the command line.
+
+When the server is started with {{.EmphasisLeft}}--config <file>{{.EmphasisRight}}, sending it SIGHUP reloads the file without closing the open connections. Changes to the log level, {{.EmphasisLeft}}max_connections{{.EmphasisRight}}, the timeouts, {{.EmphasisLeft}}read_only{{.EmphasisRight}}, the list of databases and their {{.EmphasisLeft}}read_only{{.EmphasisRight}} and {{.EmphasisLeft}}protected_branches{{.EmphasisRight}} settings take effect immediately, and changes to the query limits apply to new sessions. When the host or port changes the server starts listening on the new address, and connections to the old address are served until they are closed. Changes to other settings take effect when the server is restarted.
+
+Sessions may change their own {{.EmphasisLeft}}max_execution_time{{.EmphasisRight}} and {{.EmphasisLeft}}dolt_max_query_rows{{.EmphasisRight}} with {{.EmphasisLeft}}SET{{.EmphasisRight}}, and the super user may change the values new sessions start with using {{.EmphasisLeft}}SET GLOBAL{{.EmphasisRight}}. {{.EmphasisLeft}}KILL QUERY <id>{{.EmphasisRight}} interrupts the statement a connection is running, and {{.EmphasisLeft}}KILL <id>{{.EmphasisRight}} closes the connection. Users may kill their own connections, and the super user may kill any connection.
+
+When a change stream is configured, each commit, merge, reset or other statement which moves the head of a branch emits an event for each row which differs between the old and new head, with the database, branch, table, change type ({{.EmphasisLeft}}insert{{.EmphasisRight}}, {{.EmphasisLeft}}update{{.EmphasisRight}} or {{.EmphasisLeft}}delete{{.EmphasisRight}}), primary key, old and new column values, and the hashes of both commits. Events are computed in the background, in the order the heads moved. Creating or deleting a branch emits no events, and heads moved by other processes, such as the dolt command line, are not seen.
+
+Every branch and commit of a database being served is also available as a database named {{.EmphasisLeft}}<database>/<branch or commit hash>{{.EmphasisRight}}, e.g. {{.EmphasisLeft}}USE mydb/feature-x{{.EmphasisRight}}. Changes made to a branch database are kept in a working set for the branch until they are committed with {{.EmphasisLeft}}DOLT_COMMIT(){{.EmphasisRight}}, which advances the branch. The working set is saved in the database's {{.EmphasisLeft}}.dolt/revisions{{.EmphasisRight}} directory, so uncommitted changes survive a restart of the server. Databases for a commit hash are read only.`,
 	Synopsis: []string{
 		"--config {{.LessThan}}file{{.GreaterThan}}",
-		"[-H {{.LessThan}}host{{.GreaterThan}}] [-P {{.LessThan}}port{{.GreaterThan}}] [-u {{.LessThan}}user{{.GreaterThan}}] [-p {{.LessThan}}password{{.GreaterThan}}] [-t {{.LessThan}}timeout{{.GreaterThan}}] [-l {{.LessThan}}loglevel{{.GreaterThan}}] [--multi-db-dir {{.LessThan}}directory{{.GreaterThan}}] [--query-parallelism {{.LessThan}}num-go-routines{{.GreaterThan}}] [-r]",
+		"[-H {{.LessThan}}host{{.GreaterThan}}] [-P {{.LessThan}}port{{.GreaterThan}}] [-u {{.LessThan}}user{{.GreaterThan}}] [-p {{.LessThan}}password{{.GreaterThan}}] [-t {{.LessThan}}timeout{{.GreaterThan}}] [-l {{.LessThan}}loglevel{{.GreaterThan}}] [--multi-db-dir {{.LessThan}}directory{{.GreaterThan}}] [--query-parallelism {{.LessThan}}num-go-routines{{.GreaterThan}}] [--privilege-file {{.LessThan}}file{{.GreaterThan}}] [-r]",
 	},
 }
 
@@ -128,6 +176,7 @@ func (cmd SqlServerCmd) CreateArgParser() *argparser.ArgParser {
 	ap.SupportsString(multiDBDirFlag, "", "directory", "Defines a directory whose subdirectories should all be dolt data repositories accessible as independent databases.")
 	ap.SupportsFlag(noAutoCommitFlag, "", "When provided sessions will not automatically commit their changes to the working set. Anything not manually committed will be lost.")
 	ap.SupportsInt(queryParallelismFlag, "", "num-go-routines", fmt.Sprintf("Set the number of go routines spawned to handle each query (default `%d`)", serverConfig.QueryParallelism()))
+	ap.SupportsString(privilegeFileFlag, "", "file", "Defines a file that users and their privileges are persisted to.")
 	return ap
 }
 
@@ -169,6 +218,15 @@ func startServer(ctx context.Context, versionStr, commandStr string, args []stri
 
 	cli.PrintErrf("Starting server with Config %v\n", ConfigInfo(serverConfig))
 
+	if cfgFile, ok := apr.GetValue(configFileFlag); ok {
+		if serverController == nil {
+			serverController = CreateServerController()
+		}
+
+		stopReloading := reloadOnSIGHUP(dEnv, cfgFile, serverController)
+		defer stopReloading()
+	}
+
 	if startError, closeError := Serve(ctx, versionStr, serverConfig, serverController, dEnv); startError != nil || closeError != nil {
 		if startError != nil {
 			cli.PrintErrln(startError)
@@ -182,6 +240,42 @@ func startServer(ctx context.Context, versionStr, commandStr string, args []stri
 	return 0
 }
 
+// reloadOnSIGHUP reloads the config of the server controlled by |serverController| from |cfgFile| each time the
+// process receives SIGHUP, until the returned function is called.
+func reloadOnSIGHUP(dEnv *env.DoltEnv, cfgFile string, serverController *ServerController) func() {
+	sigCh := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigCh, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-sigCh:
+			}
+
+			serverConfig, err := getYAMLServerConfig(dEnv.FS, cfgFile)
+			if err == nil {
+				err = serverController.ReloadConfig(serverConfig)
+			}
+
+			if err != nil {
+				cli.PrintErrln(color.RedString("Failed to reload config from %s", cfgFile))
+				cli.PrintErrln(err.Error())
+				continue
+			}
+
+			cli.PrintErrf("Reloaded server with Config %v\n", ConfigInfo(serverConfig))
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigCh)
+		close(done)
+	}
+}
+
 func GetServerConfig(dEnv *env.DoltEnv, apr *argparser.ArgParseResults) (ServerConfig, error) {
 	cfgFile, ok := apr.GetValue(configFileFlag)
 
@@ -207,6 +301,9 @@ func getCommandLineServerConfig(dEnv *env.DoltEnv, apr *argparser.ArgParseResult
 	if password, ok := apr.GetValue(passwordFlag); ok {
 		serverConfig.withPassword(password)
 	}
+	if privilegeFile, ok := apr.GetValue(privilegeFileFlag); ok {
+		serverConfig.withPrivilegeFile(privilegeFile)
+	}
 	if timeoutStr, ok := apr.GetValue(timeoutFlag); ok {
 		timeout, err := strconv.ParseUint(timeoutStr, 10, 64)
 
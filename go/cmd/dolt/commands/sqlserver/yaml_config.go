@@ -22,6 +22,7 @@ import (
 	"gopkg.in/yaml.v2"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	dsqle "github.com/dolthub/dolt/go/libraries/doltcore/sqle"
 )
 
 func strPtr(s string) *string {
@@ -46,16 +47,24 @@ type BehaviorYAMLConfig struct {
 	AutoCommit *bool
 }
 
-// UserYAMLConfig contains server configuration regarding the user account clients must use to connect
+// UserYAMLConfig contains server configuration regarding the user accounts clients use to connect
 type UserYAMLConfig struct {
 	Name     *string
 	Password *string
+	// PrivilegeFile is the file that additional users and their privileges are persisted to
+	PrivilegeFile *string `yaml:"privilege_file"`
+	// BootstrapFile is a file of account management statements which are run when the privilege file does not exist
+	BootstrapFile *string `yaml:"bootstrap_file"`
 }
 
 // DatabaseYAMLConfig contains information on a database that this server will provide access to
 type DatabaseYAMLConfig struct {
 	Name string
 	Path string
+	// ReadOnly rejects every write to the database
+	ReadOnly *bool `yaml:"read_only,omitempty"`
+	// ProtectedBranches are patterns matching the branches of the database which can't be written to
+	ProtectedBranches []string `yaml:"protected_branches,omitempty"`
 }
 
 // ListenerYAMLConfig contains information on the network connection that the server will open
@@ -65,34 +74,80 @@ type ListenerYAMLConfig struct {
 	MaxConnections     *uint64 `yaml:"max_connections"`
 	ReadTimeoutMillis  *uint64 `yaml:"read_timeout_millis"`
 	WriteTimeoutMillis *uint64 `yaml:"write_timeout_millis"`
+	// TLSKey is a file system path to an unencrypted private TLS key in PEM format.
+	TLSKey *string `yaml:"tls_key"`
+	// TLSCert is a file system path to a TLS certificate chain in PEM format.
+	TLSCert *string `yaml:"tls_cert"`
+	// RequireSecureTransport can enable a mode where non-TLS connections are turned away.
+	RequireSecureTransport *bool `yaml:"require_secure_transport"`
 }
 
 // PerformanceYAMLConfig contains configuration parameters for performance tweaking
 type PerformanceYAMLConfig struct {
 	QueryParallelism *int `yaml:"query_parallelism"`
+	// MaxExecutionTimeMillis is the default of the max_execution_time session variable
+	MaxExecutionTimeMillis *uint64 `yaml:"max_execution_time_millis"`
+	// MaxQueryRows is the default of the dolt_max_query_rows session variable
+	MaxQueryRows *uint64 `yaml:"max_query_rows"`
+}
+
+// MetricsYAMLConfig contains configuration for the HTTP listener which serves the server's metrics
+type MetricsYAMLConfig struct {
+	// Labels are added to every metric the server exports
+	Labels map[string]string `yaml:"labels"`
+	Host   *string           `yaml:"host"`
+	Port   *int              `yaml:"port"`
+}
+
+// SlowQueryLogYAMLConfig contains configuration for logging queries which take longer than a threshold to run
+type SlowQueryLogYAMLConfig struct {
+	File            *string `yaml:"file"`
+	ThresholdMillis *uint64 `yaml:"threshold_millis"`
+}
+
+// TracingYAMLConfig contains configuration for exporting the opentracing spans of each query
+type TracingYAMLConfig struct {
+	// File is the path spans are written to, or "stdout"
+	File *string `yaml:"file"`
+}
+
+// ChangeStreamYAMLConfig contains configuration for streaming the row level changes made by moving the heads of
+// branches
+type ChangeStreamYAMLConfig struct {
+	// File is the path changes are appended to as newline delimited JSON
+	File *string `yaml:"file"`
+	Host *string `yaml:"host"`
+	Port *int    `yaml:"port"`
 }
 
 // YAMLConfig is a ServerConfig implementation which is read from a yaml file
 type YAMLConfig struct {
-	LogLevelStr       *string               `yaml:"log_level"`
-	BehaviorConfig    BehaviorYAMLConfig    `yaml:"behavior"`
-	UserConfig        UserYAMLConfig        `yaml:"user"`
-	ListenerConfig    ListenerYAMLConfig    `yaml:"listener"`
-	DatabaseConfig    []DatabaseYAMLConfig  `yaml:"databases"`
-	PerformanceConfig PerformanceYAMLConfig `yaml:"performance"`
+	LogLevelStr       *string                `yaml:"log_level"`
+	BehaviorConfig    BehaviorYAMLConfig     `yaml:"behavior"`
+	UserConfig        UserYAMLConfig         `yaml:"user"`
+	ListenerConfig    ListenerYAMLConfig     `yaml:"listener"`
+	DatabaseConfig    []DatabaseYAMLConfig   `yaml:"databases"`
+	PerformanceConfig PerformanceYAMLConfig  `yaml:"performance"`
+	MetricsConfig     MetricsYAMLConfig      `yaml:"metrics"`
+	SlowQueryLog      SlowQueryLogYAMLConfig `yaml:"slow_query_log"`
+	TracingConfig     TracingYAMLConfig      `yaml:"tracing"`
+	ChangeStream      ChangeStreamYAMLConfig `yaml:"change_stream"`
 }
 
 func serverConfigAsYAMLConfig(cfg ServerConfig) YAMLConfig {
 	return YAMLConfig{
 		LogLevelStr:    strPtr(string(cfg.LogLevel())),
 		BehaviorConfig: BehaviorYAMLConfig{boolPtr(cfg.ReadOnly()), boolPtr(cfg.AutoCommit())},
-		UserConfig:     UserYAMLConfig{strPtr(cfg.User()), strPtr(cfg.Password())},
+		UserConfig:     UserYAMLConfig{strPtr(cfg.User()), strPtr(cfg.Password()), nil, nil},
 		ListenerConfig: ListenerYAMLConfig{
 			strPtr(cfg.Host()),
 			intPtr(cfg.Port()),
 			uint64Ptr(cfg.MaxConnections()),
 			uint64Ptr(cfg.ReadTimeout()),
 			uint64Ptr(cfg.WriteTimeout()),
+			nil,
+			nil,
+			boolPtr(cfg.RequireSecureTransport()),
 		},
 		DatabaseConfig: nil,
 	}
@@ -166,6 +221,113 @@ func (cfg YAMLConfig) WriteTimeout() uint64 {
 	return *cfg.ListenerConfig.WriteTimeoutMillis
 }
 
+// TLSKey returns a path to the servers PEM-encoded private TLS key. "" if there is none.
+func (cfg YAMLConfig) TLSKey() string {
+	if cfg.ListenerConfig.TLSKey == nil {
+		return ""
+	}
+
+	return *cfg.ListenerConfig.TLSKey
+}
+
+// TLSCert returns a path to the servers PEM-encoded TLS certificate chain. "" if there is none.
+func (cfg YAMLConfig) TLSCert() string {
+	if cfg.ListenerConfig.TLSCert == nil {
+		return ""
+	}
+
+	return *cfg.ListenerConfig.TLSCert
+}
+
+// RequireSecureTransport returns true if the server should reject non-TLS connections.
+func (cfg YAMLConfig) RequireSecureTransport() bool {
+	if cfg.ListenerConfig.RequireSecureTransport == nil {
+		return defaultRequireSecureTransport
+	}
+
+	return *cfg.ListenerConfig.RequireSecureTransport
+}
+
+// MetricsHost returns the host that the HTTP listener serving the server's metrics will run on.
+func (cfg YAMLConfig) MetricsHost() string {
+	if cfg.MetricsConfig.Host == nil {
+		return defaultMetricsHost
+	}
+
+	return *cfg.MetricsConfig.Host
+}
+
+// MetricsPort returns the port of the HTTP listener serving the server's metrics, or -1 if metrics are disabled.
+func (cfg YAMLConfig) MetricsPort() int {
+	if cfg.MetricsConfig.Port == nil {
+		return defaultMetricsPort
+	}
+
+	return *cfg.MetricsConfig.Port
+}
+
+// MetricsLabels returns labels which are added to every metric the server exports.
+func (cfg YAMLConfig) MetricsLabels() map[string]string {
+	return cfg.MetricsConfig.Labels
+}
+
+// SlowQueryLogFile returns the path of the file that queries which take longer than the slow query threshold are
+// logged to. "" if slow queries are not logged.
+func (cfg YAMLConfig) SlowQueryLogFile() string {
+	if cfg.SlowQueryLog.File == nil {
+		return defaultSlowQueryLogFile
+	}
+
+	return *cfg.SlowQueryLog.File
+}
+
+// SlowQueryThreshold returns the number of milliseconds a query must run for to be logged as a slow query.
+func (cfg YAMLConfig) SlowQueryThreshold() uint64 {
+	if cfg.SlowQueryLog.ThresholdMillis == nil {
+		return defaultSlowQueryThreshold
+	}
+
+	return *cfg.SlowQueryLog.ThresholdMillis
+}
+
+// TraceFile returns the path of the file that the opentracing spans of each query are written to, or TraceFileStdout
+// to write them to stdout. "" if queries are not traced.
+func (cfg YAMLConfig) TraceFile() string {
+	if cfg.TracingConfig.File == nil {
+		return defaultTraceFile
+	}
+
+	return *cfg.TracingConfig.File
+}
+
+// ChangeStreamFile returns the path of the file that the row level changes made by moving the heads of branches are
+// appended to. "" if changes are not written to a file.
+func (cfg YAMLConfig) ChangeStreamFile() string {
+	if cfg.ChangeStream.File == nil {
+		return defaultChangeStreamFile
+	}
+
+	return *cfg.ChangeStream.File
+}
+
+// ChangeStreamHost returns the host that the HTTP listener streaming row level changes will run on.
+func (cfg YAMLConfig) ChangeStreamHost() string {
+	if cfg.ChangeStream.Host == nil {
+		return defaultChangeStreamHost
+	}
+
+	return *cfg.ChangeStream.Host
+}
+
+// ChangeStreamPort returns the port of the HTTP listener streaming row level changes, or -1 if it is disabled.
+func (cfg YAMLConfig) ChangeStreamPort() int {
+	if cfg.ChangeStream.Port == nil {
+		return defaultChangeStreamPort
+	}
+
+	return *cfg.ChangeStream.Port
+}
+
 // User returns the username that connecting clients must use.
 func (cfg YAMLConfig) User() string {
 	if cfg.UserConfig.Name == nil {
@@ -184,6 +346,26 @@ func (cfg YAMLConfig) Password() string {
 	return *cfg.UserConfig.Password
 }
 
+// PrivilegeFilePath returns the path of the file that users and privileges are persisted to. If empty, users created
+// while the server runs are not persisted.
+func (cfg YAMLConfig) PrivilegeFilePath() string {
+	if cfg.UserConfig.PrivilegeFile == nil {
+		return defaultPrivilegeFilePath
+	}
+
+	return *cfg.UserConfig.PrivilegeFile
+}
+
+// BootstrapFilePath returns the path of a file of account management statements which are run when the server starts
+// and the privilege file does not exist yet.
+func (cfg YAMLConfig) BootstrapFilePath() string {
+	if cfg.UserConfig.BootstrapFile == nil {
+		return defaultBootstrapFilePath
+	}
+
+	return *cfg.UserConfig.BootstrapFile
+}
+
 // ReadOnly returns whether the server will only accept read statements or all statements.
 func (cfg YAMLConfig) ReadOnly() bool {
 	if cfg.BehaviorConfig.ReadOnly == nil {
@@ -223,6 +405,23 @@ func (cfg YAMLConfig) DatabaseNamesAndPaths() []env.EnvNameAndPath {
 	return dbNamesAndPaths
 }
 
+// DatabaseWriteProtections returns the restrictions on the writes which may be made to each database, by name.
+func (cfg YAMLConfig) DatabaseWriteProtections() map[string]dsqle.WriteProtection {
+	protections := make(map[string]dsqle.WriteProtection)
+	for _, dbConfig := range cfg.DatabaseConfig {
+		wp := dsqle.WriteProtection{ProtectedBranches: dbConfig.ProtectedBranches}
+		if dbConfig.ReadOnly != nil {
+			wp.ReadOnly = *dbConfig.ReadOnly
+		}
+
+		if wp.ReadOnly || len(wp.ProtectedBranches) > 0 {
+			protections[dbConfig.Name] = wp
+		}
+	}
+
+	return protections
+}
+
 // MaxConnections returns the maximum number of simultaneous connections the server will allow.  The default is 1
 func (cfg YAMLConfig) MaxConnections() uint64 {
 	if cfg.ListenerConfig.MaxConnections == nil {
@@ -240,3 +439,23 @@ func (cfg YAMLConfig) QueryParallelism() int {
 
 	return *cfg.PerformanceConfig.QueryParallelism
 }
+
+// MaxExecutionTime returns the default of the max_execution_time session variable, the number of milliseconds a SELECT
+// statement may run for before it is interrupted. 0 means there is no limit.
+func (cfg YAMLConfig) MaxExecutionTime() uint64 {
+	if cfg.PerformanceConfig.MaxExecutionTimeMillis == nil {
+		return defaultMaxExecutionTime
+	}
+
+	return *cfg.PerformanceConfig.MaxExecutionTimeMillis
+}
+
+// MaxQueryRows returns the default of the dolt_max_query_rows session variable, the number of rows a query may read
+// from tables before it is interrupted. 0 means there is no limit.
+func (cfg YAMLConfig) MaxQueryRows() uint64 {
+	if cfg.PerformanceConfig.MaxQueryRows == nil {
+		return defaultMaxQueryRows
+	}
+
+	return *cfg.PerformanceConfig.MaxQueryRows
+}
@@ -21,6 +21,8 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
+
+	dsqle "github.com/dolthub/dolt/go/libraries/doltcore/sqle"
 )
 
 func TestUnmarshall(t *testing.T) {
@@ -41,6 +43,7 @@ listener:
     max_connections: 1
     read_timeout_millis: 28800000
     write_timeout_millis: 28800000
+    require_secure_transport: false
     
 databases:
     - name: irs_soi
@@ -82,4 +85,135 @@ func TestYAMLConfigDefaults(t *testing.T) {
 	assert.Equal(t, defaultLogLevel, cfg.LogLevel())
 	assert.Equal(t, defaultAutoCommit, cfg.AutoCommit())
 	assert.Equal(t, uint64(defaultMaxConnections), cfg.MaxConnections())
+	assert.Equal(t, "", cfg.TLSKey())
+	assert.Equal(t, "", cfg.TLSCert())
+	assert.Equal(t, defaultRequireSecureTransport, cfg.RequireSecureTransport())
+	assert.Equal(t, defaultMetricsHost, cfg.MetricsHost())
+	assert.Equal(t, defaultMetricsPort, cfg.MetricsPort())
+	assert.Empty(t, cfg.MetricsLabels())
+	assert.Equal(t, defaultSlowQueryLogFile, cfg.SlowQueryLogFile())
+	assert.Equal(t, uint64(defaultSlowQueryThreshold), cfg.SlowQueryThreshold())
+	assert.Equal(t, defaultTraceFile, cfg.TraceFile())
+	assert.Equal(t, uint64(defaultMaxExecutionTime), cfg.MaxExecutionTime())
+	assert.Equal(t, uint64(defaultMaxQueryRows), cfg.MaxQueryRows())
+	assert.Equal(t, defaultChangeStreamFile, cfg.ChangeStreamFile())
+	assert.Equal(t, defaultChangeStreamHost, cfg.ChangeStreamHost())
+	assert.Equal(t, defaultChangeStreamPort, cfg.ChangeStreamPort())
+}
+
+func TestYAMLConfigQueryLimits(t *testing.T) {
+	testStr := `
+performance:
+    query_parallelism: 4
+    max_execution_time_millis: 30000
+    max_query_rows: 1000000
+`
+
+	var cfg YAMLConfig
+	err := yaml.Unmarshal([]byte(testStr), &cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, cfg.QueryParallelism())
+	assert.Equal(t, uint64(30000), cfg.MaxExecutionTime())
+	assert.Equal(t, uint64(1000000), cfg.MaxQueryRows())
+}
+
+func TestYAMLConfigSlowQueryLogAndTracing(t *testing.T) {
+	testStr := `
+slow_query_log:
+    file: slow.log
+    threshold_millis: 250
+
+tracing:
+    file: stdout
+`
+
+	var cfg YAMLConfig
+	err := yaml.Unmarshal([]byte(testStr), &cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "slow.log", cfg.SlowQueryLogFile())
+	assert.Equal(t, uint64(250), cfg.SlowQueryThreshold())
+	assert.Equal(t, TraceFileStdout, cfg.TraceFile())
+}
+
+func TestYAMLConfigMetrics(t *testing.T) {
+	testStr := `
+metrics:
+    host: 0.0.0.0
+    port: 9091
+    labels:
+        cluster: prod
+        region: us-west-2
+`
+
+	var cfg YAMLConfig
+	err := yaml.Unmarshal([]byte(testStr), &cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "0.0.0.0", cfg.MetricsHost())
+	assert.Equal(t, 9091, cfg.MetricsPort())
+	assert.Equal(t, map[string]string{"cluster": "prod", "region": "us-west-2"}, cfg.MetricsLabels())
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.MetricsConfig.Labels["bad-label"] = "x"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.MetricsConfig.Port = intPtr(defaultPort)
+	delete(cfg.MetricsConfig.Labels, "bad-label")
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestYAMLConfigChangeStream(t *testing.T) {
+	testStr := `
+metrics:
+    port: 9091
+
+change_stream:
+    file: changes.jsonl
+    host: 0.0.0.0
+    port: 9092
+`
+
+	var cfg YAMLConfig
+	err := yaml.Unmarshal([]byte(testStr), &cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "changes.jsonl", cfg.ChangeStreamFile())
+	assert.Equal(t, "0.0.0.0", cfg.ChangeStreamHost())
+	assert.Equal(t, 9092, cfg.ChangeStreamPort())
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.ChangeStream.Port = intPtr(9091)
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.ChangeStream.Port = intPtr(80)
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestYAMLConfigDatabaseWriteProtections(t *testing.T) {
+	testStr := `
+databases:
+    - name: reference
+      path: ./reference
+      read_only: true
+    - name: app
+      path: ./app
+      protected_branches: [main, release/*]
+    - name: scratch
+      path: ./scratch
+`
+
+	var cfg YAMLConfig
+	err := yaml.Unmarshal([]byte(testStr), &cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]dsqle.WriteProtection{
+		"reference": {ReadOnly: true},
+		"app":       {ProtectedBranches: []string{"main", "release/*"}},
+	}, cfg.DatabaseWriteProtections())
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.DatabaseConfig[1].ProtectedBranches = []string{"release/["}
+	assert.Error(t, ValidateConfig(cfg))
 }
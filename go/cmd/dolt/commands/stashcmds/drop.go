@@ -0,0 +1,80 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stashcmds
+
+import (
+	"context"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+var dropDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Remove a single stash entry from the stash list.",
+	LongDesc:  `Removes a single stash entry from the stash list. If no stash is given, the most recent stash {{.EmphasisLeft}}stash@{0}{{.EmphasisRight}} is removed.`,
+	Synopsis:  []string{"[{{.LessThan}}stash{{.GreaterThan}}]"},
+}
+
+type DropCmd struct{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd DropCmd) Name() string {
+	return "drop"
+}
+
+// Description returns a description of the command
+func (cmd DropCmd) Description() string {
+	return dropDocs.ShortDesc
+}
+
+// CreateMarkdown creates a markdown file containing the helptext for the command at the given path
+func (cmd DropCmd) CreateMarkdown(fs filesys.Filesys, path, commandStr string) error {
+	ap := cmd.createArgParser()
+	return commands.CreateMarkdown(fs, path, cli.GetCommandDocumentation(commandStr, dropDocs, ap))
+}
+
+func (cmd DropCmd) createArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"stash", "The stash to drop, given as stash@{n} or n."})
+	return ap
+}
+
+// Exec executes the command
+func (cmd DropCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := cmd.createArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, dropDocs, ap))
+	apr := cli.ParseArgs(ap, args, help)
+
+	idx, verr := parseStashIndex(apr.Args())
+	if verr != nil {
+		return commands.HandleVErrAndExitCode(verr, usage)
+	}
+
+	stash, err := actions.DropStash(ctx, dEnv.DoltDB, idx)
+	if err == doltdb.ErrStashNotFound {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: %s is not a valid stash", actions.StashName(idx)).Build(), usage)
+	} else if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: failed to drop %s", actions.StashName(idx)).AddCause(err).Build(), usage)
+	}
+
+	cli.Printf("Dropped %s (%s)\n", actions.StashName(idx), stash.CommitHash())
+	return 0
+}
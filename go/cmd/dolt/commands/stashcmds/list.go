@@ -0,0 +1,73 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stashcmds
+
+import (
+	"context"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+var listDocs = cli.CommandDocumentationContent{
+	ShortDesc: "List the stash entries that you currently have.",
+	LongDesc:  `Lists the stash entries that you currently have, most recent first. Each entry is listed with its name, e.g. {{.EmphasisLeft}}stash@{0}{{.EmphasisRight}}, and a description of the commit it was created on.`,
+	Synopsis:  []string{""},
+}
+
+type ListCmd struct{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd ListCmd) Name() string {
+	return "list"
+}
+
+// Description returns a description of the command
+func (cmd ListCmd) Description() string {
+	return listDocs.ShortDesc
+}
+
+// CreateMarkdown creates a markdown file containing the helptext for the command at the given path
+func (cmd ListCmd) CreateMarkdown(fs filesys.Filesys, path, commandStr string) error {
+	ap := cmd.createArgParser()
+	return commands.CreateMarkdown(fs, path, cli.GetCommandDocumentation(commandStr, listDocs, ap))
+}
+
+func (cmd ListCmd) createArgParser() *argparser.ArgParser {
+	return argparser.NewArgParser()
+}
+
+// Exec executes the command
+func (cmd ListCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := cmd.createArgParser()
+	help, _ := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, listDocs, ap))
+	cli.ParseArgs(ap, args, help)
+
+	stashes, err := actions.ListStashes(ctx, dEnv.DoltDB)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: failed to read stashes").AddCause(err).Build(), nil)
+	}
+
+	for i, stash := range stashes {
+		cli.Printf("%s: %s\n", actions.StashName(i), stash.Meta.Description)
+	}
+
+	return 0
+}
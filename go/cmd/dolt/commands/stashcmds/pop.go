@@ -0,0 +1,108 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stashcmds
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+var popDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Apply a stash to the working set and remove it from the stash list.",
+	LongDesc: `Applies the changes saved in a stash entry to the working set and removes the entry from the stash list. If no stash is given, the most recent stash {{.EmphasisLeft}}stash@{0}{{.EmphasisRight}} is used.
+
+The stashed changes are applied using a three-way merge between the current working set and the stashed working set, with the commit the stash was created on as the common ancestor. If this results in conflicts, they can be resolved with {{.EmphasisLeft}}dolt conflicts{{.EmphasisRight}}, and the stash entry is kept.`,
+	Synopsis: []string{"[{{.LessThan}}stash{{.GreaterThan}}]"},
+}
+
+type PopCmd struct{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd PopCmd) Name() string {
+	return "pop"
+}
+
+// Description returns a description of the command
+func (cmd PopCmd) Description() string {
+	return popDocs.ShortDesc
+}
+
+// CreateMarkdown creates a markdown file containing the helptext for the command at the given path
+func (cmd PopCmd) CreateMarkdown(fs filesys.Filesys, path, commandStr string) error {
+	ap := cmd.createArgParser()
+	return commands.CreateMarkdown(fs, path, cli.GetCommandDocumentation(commandStr, popDocs, ap))
+}
+
+func (cmd PopCmd) createArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"stash", "The stash to apply, given as stash@{n} or n."})
+	return ap
+}
+
+// Exec executes the command
+func (cmd PopCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := cmd.createArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, popDocs, ap))
+	apr := cli.ParseArgs(ap, args, help)
+
+	idx, verr := parseStashIndex(apr.Args())
+	if verr != nil {
+		return commands.HandleVErrAndExitCode(verr, usage)
+	}
+
+	stash, err := actions.GetStash(ctx, dEnv.DoltDB, idx)
+	if err == doltdb.ErrStashNotFound {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: %s is not a valid stash", actions.StashName(idx)).Build(), usage)
+	} else if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: failed to read stash").AddCause(err).Build(), usage)
+	}
+
+	tblToStats, err := actions.StashPop(ctx, dEnv, idx)
+	if err == actions.ErrStashStagedConflicts {
+		bdr := errhand.BuildDError("error: failed to apply %s", actions.StashName(idx)).AddCause(err)
+		bdr.AddDetails("The stash entry is kept in case you need it again.")
+		return commands.HandleVErrAndExitCode(bdr.Build(), usage)
+	} else if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: failed to apply %s", actions.StashName(idx)).AddCause(err).Build(), usage)
+	}
+
+	var conflicted []string
+	for tblName, stats := range tblToStats {
+		if stats.Conflicts > 0 {
+			conflicted = append(conflicted, tblName)
+		}
+	}
+
+	if len(conflicted) > 0 {
+		sort.Strings(conflicted)
+		for _, tblName := range conflicted {
+			cli.Println("CONFLICT (content): Merge conflict in", tblName)
+		}
+		cli.Println("The stash entry is kept in case you need it again.")
+		return 1
+	}
+
+	cli.Printf("Dropped %s (%s)\n", actions.StashName(idx), stash.CommitHash())
+	return 0
+}
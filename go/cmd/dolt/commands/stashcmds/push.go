@@ -0,0 +1,85 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stashcmds
+
+import (
+	"context"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+var pushDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Stash the changes in the working set away.",
+	LongDesc: `Saves the staged and unstaged changes in the working set as a new stash entry, and resets the working set to match HEAD. Untracked tables are not stashed and are left in the working set.
+
+The stashed changes can be restored, possibly on top of a different commit, with {{.EmphasisLeft}}dolt stash pop{{.EmphasisRight}}.`,
+	Synopsis: []string{""},
+}
+
+type PushCmd struct{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd PushCmd) Name() string {
+	return "push"
+}
+
+// Description returns a description of the command
+func (cmd PushCmd) Description() string {
+	return pushDocs.ShortDesc
+}
+
+// CreateMarkdown creates a markdown file containing the helptext for the command at the given path
+func (cmd PushCmd) CreateMarkdown(fs filesys.Filesys, path, commandStr string) error {
+	ap := cmd.createArgParser()
+	return commands.CreateMarkdown(fs, path, cli.GetCommandDocumentation(commandStr, pushDocs, ap))
+}
+
+func (cmd PushCmd) createArgParser() *argparser.ArgParser {
+	return argparser.NewArgParser()
+}
+
+// Exec executes the command
+func (cmd PushCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := cmd.createArgParser()
+	help, usage := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, pushDocs, ap))
+	apr := cli.ParseArgs(ap, args, help)
+
+	if apr.NArg() != 0 {
+		usage()
+		return 1
+	}
+
+	name, email, err := actions.GetNameAndEmail(dEnv.Config)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: could not determine user name and email").AddCause(err).Build(), usage)
+	}
+
+	stash, err := actions.StashPush(ctx, dEnv, name, email)
+	if err == actions.ErrNoLocalChangesToStash {
+		cli.Println("No local changes to save")
+		return 0
+	} else if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: failed to stash changes").AddCause(err).Build(), usage)
+	}
+
+	cli.Println("Saved working directory and index state", stash.Meta.Description)
+	return 0
+}
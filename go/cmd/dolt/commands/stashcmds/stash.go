@@ -0,0 +1,52 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stashcmds
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+)
+
+var Commands = cli.NewSubCommandHandler("stash", "Commands for stashing the changes in a dirty working set away.", []cli.Command{
+	PushCmd{},
+	PopCmd{},
+	ListCmd{},
+	DropCmd{},
+})
+
+// parseStashIndex parses a stash given as either stash@{n} or n, returning n. If no stash is given the most recent
+// stash, at index 0, is used.
+func parseStashIndex(args []string) (int, errhand.VerboseError) {
+	if len(args) == 0 {
+		return 0, nil
+	} else if len(args) > 1 {
+		return 0, errhand.BuildDError("error: too many arguments").Build()
+	}
+
+	str := args[0]
+	if strings.HasPrefix(str, "stash@{") && strings.HasSuffix(str, "}") {
+		str = str[len("stash@{") : len(str)-1]
+	}
+
+	idx, err := strconv.Atoi(str)
+	if err != nil || idx < 0 {
+		return 0, errhand.BuildDError("error: '%s' is not a valid stash reference", args[0]).Build()
+	}
+
+	return idx, nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sshremote"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+var transferDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Serves the chunk store of a database over stdin and stdout",
+	LongDesc: `Serves the chunk store of the database at {{.LessThan}}path{{.GreaterThan}} to a client speaking over stdin and stdout. It is run over ssh on the hosts of {{.EmphasisLeft}}ssh://{{.EmphasisRight}} remotes, and is not meant to be run directly.
+
+{{.LessThan}}path{{.GreaterThan}} is either a dolt data repository, or a directory of table files such as those of {{.EmphasisLeft}}file://{{.EmphasisRight}} remotes.`,
+	Synopsis: []string{
+		"{{.LessThan}}path{{.GreaterThan}}",
+	},
+}
+
+type TransferCmd struct{}
+
+// Name is returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd TransferCmd) Name() string {
+	return "transfer"
+}
+
+// Description returns a description of the command
+func (cmd TransferCmd) Description() string {
+	return transferDocs.ShortDesc
+}
+
+// RequiresRepo should return false if this interface is implemented, and the command does not have the requirement
+// that it be run from within a data repository directory
+func (cmd TransferCmd) RequiresRepo() bool {
+	return false
+}
+
+// Hidden should return true if this command should be hidden from the help text
+func (cmd TransferCmd) Hidden() bool {
+	return true
+}
+
+// CreateMarkdown creates a markdown file containing the helptext for the command at the given path
+func (cmd TransferCmd) CreateMarkdown(fs filesys.Filesys, path, commandStr string) error {
+	return nil
+}
+
+// Exec executes the command
+func (cmd TransferCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"path", "The path of the database to serve."})
+	help, usage := cli.HelpAndUsagePrinters(cli.GetCommandDocumentation(commandStr, transferDocs, ap))
+	apr := cli.ParseArgs(ap, args, help)
+
+	if apr.NArg() != 1 {
+		verr := errhand.BuildDError("expected exactly one path").SetPrintUsage().Build()
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	dir := apr.Arg(0)
+	if info, err := os.Stat(filepath.Join(dir, dbfactory.DoltDataDir)); err == nil && info.IsDir() {
+		dir = filepath.Join(dir, dbfactory.DoltDataDir)
+	}
+
+	srv, err := sshremote.NewServer(dir)
+
+	if err != nil {
+		verr := errhand.BuildDError("error: unable to serve '%s'", apr.Arg(0)).AddCause(err).Build()
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	// the client speaks to the server over the stdin and stdout of the process, so nothing else can be written to stdout
+	cli.ExecuteWithStdioRestored(func() {
+		conn := sshremote.NewPipeConn(os.Stdin, os.Stdout, os.Stdout.Close)
+		srv.Serve(ctx, conn)
+	})
+
+	err = srv.Close()
+
+	if err != nil {
+		verr := errhand.BuildDError("error: failed to close the database").AddCause(err).Build()
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	return 0
+}
@@ -29,7 +29,7 @@ import (
 
 var verifyConstraintsDocs = cli.CommandDocumentationContent{
 	ShortDesc: `Verifies a table's constraints'`,
-	LongDesc:  `This command verifies that the defined constraints on the given table(s)—such as a foreign key—are correct and satisfied.`,
+	LongDesc:  `This command verifies that the defined constraints on the given table(s)—such as a foreign key or a check constraint—are correct and satisfied.`,
 	Synopsis:  []string{`{{.LessThan}}table{{.GreaterThan}}...`},
 }
 
@@ -119,6 +119,16 @@ func (cmd VerifyConstraintsCmd) Exec(ctx context.Context, commandStr string, arg
 				accumulatedConstraintErrors = append(accumulatedConstraintErrors, err.Error())
 			}
 		}
+
+		rowData, err := tbl.GetRowData(ctx)
+		if err != nil {
+			return HandleVErrAndExitCode(errhand.BuildDError("Unable to get row data for %s.", tableName).AddCause(err).Build(), nil)
+		}
+		checkViolations, err := table.CheckConstraintViolations(ctx, tableName, tblSch, rowData)
+		if err != nil {
+			return HandleVErrAndExitCode(errhand.BuildDError("Unable to verify check constraints for %s.", tableName).AddCause(err).Build(), nil)
+		}
+		accumulatedConstraintErrors = append(accumulatedConstraintErrors, checkViolations...)
 	}
 
 	if len(accumulatedConstraintErrors) > 0 {
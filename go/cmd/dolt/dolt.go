@@ -35,6 +35,7 @@ import (
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/indexcmds"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/schcmds"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/sqlserver"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/stashcmds"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/tblcmds"
 	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
@@ -64,6 +65,10 @@ var doltCommand = cli.NewSubCommandHandler("dolt", "it's git for data", []cli.Co
 	commands.DiffCmd{},
 	commands.BlameCmd{},
 	commands.MergeCmd{},
+	commands.CherryPickCmd{},
+	commands.RevertCmd{},
+	commands.RebaseCmd{},
+	stashcmds.Commands,
 	commands.BranchCmd{},
 	commands.TagCmd{},
 	commands.CheckoutCmd{},
@@ -81,6 +86,7 @@ var doltCommand = cli.NewSubCommandHandler("dolt", "it's git for data", []cli.Co
 	tblcmds.Commands,
 	cnfcmds.Commands,
 	commands.SendMetricsCmd{},
+	commands.TransferCmd{},
 	dumpDocsCommand,
 	commands.MigrateCmd{},
 	indexcmds.Commands,
@@ -0,0 +1,408 @@
+// Copyright 2019 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blame
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions/commitwalk"
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// BlameInfo contains blame information for a row
+type BlameInfo struct {
+	// Key represents the primary key of the row
+	Key types.Value
+
+	// CommitHash is the commit hash of the commit which last modified the row
+	CommitHash string
+
+	// Author is the name of the author of the commit which last modified the row
+	Author string
+
+	// Email is the email of the author of the commit which last modified the row
+	Email string
+
+	// Description is the description of the commit which last modified the row
+	Description string
+
+	// Timestamp is the timestamp of the commit which last modified the row
+	Timestamp int64
+}
+
+// TimestampTime returns a time.Time object representing the BlameInfo timestamp
+func (bi *BlameInfo) TimestampTime() time.Time {
+	return time.Unix(bi.Timestamp/1000, 0)
+}
+
+// TimestampString returns a string representing the BlameInfo timestamp
+func (bi *BlameInfo) TimestampString() string {
+	return bi.TimestampTime().Format(time.UnixDate)
+}
+
+// A BlameGraph is a map of primary key hashes to BlameInfo structs
+type BlameGraph map[hash.Hash]BlameInfo
+
+type blameInput struct {
+	Commit       *doltdb.Commit
+	Hash         string
+	Parent       *doltdb.Commit
+	ParentHash   string
+	ParentSchema schema.Schema
+	ParentTable  *doltdb.Table
+	Table        *doltdb.Table
+	TableName    string
+	Schema       schema.Schema
+}
+
+// BlameGraphFromCommit annotates each row in the table named |tableName| at |commit| with the commit which last
+// modified the row.
+//
+// Blame is computed as follows:
+//
+// First, a blame graph is initialized with one node for every row in the table at the given commit.
+//
+// Starting from the given commit, walk backwards through the commit graph (currently by following each commit's
+// first parent, though this may change in the future).
+//
+// For each adjacent pair of commits `old` and `new`, check each remaining unblamed node to see if the row it represents
+// changed between the commits. If so, mark it with `new` as the blame origin and continue to the next node without blame.
+//
+// When all nodes have blame information, stop iterating through commits.
+func BlameGraphFromCommit(ctx context.Context, ddb *doltdb.DoltDB, commit *doltdb.Commit, tableName string) (*BlameGraph, error) {
+	// get the commits in reverse topological order ending with `commit`
+	hash, err := commit.HashOf()
+	if err != nil {
+		return nil, err
+	}
+	commits, err := commitwalk.GetTopologicalOrderCommits(ctx, ddb, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := RowsFromCommit(ctx, commit, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	tbl, err := MaybeTableFromCommit(ctx, commit, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if tbl == nil {
+		return nil, fmt.Errorf("no table named %s found", tableName)
+	}
+
+	nbf := tbl.Format()
+
+	blameGraph, err := blameGraphFromRows(ctx, nbf, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// precompute blame inputs for each commit
+	blameInputs, err := blameInputsFromCommits(ctx, ddb, tableName, commits)
+	if err != nil {
+		return nil, err
+	}
+
+ROWLOOP:
+	for _, node := range *blameGraph {
+		for _, blameInput := range *blameInputs {
+			// did the node change between the commit-parent pair represented by blameInput?
+			changed, err := rowChanged(ctx, blameInput, node.Key)
+			if err != nil {
+				return nil, err
+			}
+
+			// if so, mark the commit as the blame origin
+			if changed {
+				err = blameGraph.AssignBlame(node.Key, nbf, blameInput.Commit)
+				if err != nil {
+					return nil, err
+				}
+				continue ROWLOOP
+			}
+		}
+		// didn't find blame for a row...something's wrong
+		return nil, fmt.Errorf("couldn't find blame for row with primary key %v", strings.Join(GetPKStrs(ctx, node.Key), ", "))
+	}
+
+	return blameGraph, nil
+}
+
+func blameInputsFromCommits(ctx context.Context, ddb *doltdb.DoltDB, tableName string, commits []*doltdb.Commit) (*[]blameInput, error) {
+	numCommits := len(commits)
+	blameInputs := make([]blameInput, numCommits)
+	for i, c := range commits {
+		// don't precompute inputs for the initial commit; we don't need them
+		if i == numCommits-1 {
+			break
+		}
+
+		parent, err := ddb.ResolveParent(ctx, c, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		parentHash, hash, err := getCommitHashes(parent, c)
+		if err != nil {
+			return nil, err
+		}
+
+		tbl, err := MaybeTableFromCommit(ctx, c, tableName)
+		if err != nil {
+			return nil, fmt.Errorf("error getting table from child commit %s: %v", hash, err)
+		}
+		parentTbl, err := MaybeTableFromCommit(ctx, parent, tableName)
+		if err != nil {
+			return nil, fmt.Errorf("error getting table from parent commit %s: %v", parentHash, err)
+		}
+
+		var s schema.Schema
+		if tbl != nil {
+			s, err = tbl.GetSchema(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("error getting schema from table %s in child commit %s: %v", tableName, hash, err)
+			}
+		}
+
+		var parentSchema schema.Schema
+		if parentTbl != nil {
+			parentSchema, err = parentTbl.GetSchema(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("error getting schema from table %s in parent commit %s: %v", tableName, parentHash, err)
+			}
+		}
+
+		blameInputs[i] = blameInput{
+			Commit:       c,
+			Hash:         hash,
+			Parent:       parent,
+			ParentHash:   parentHash,
+			ParentSchema: parentSchema,
+			ParentTable:  parentTbl,
+			Table:        tbl,
+			TableName:    tableName,
+			Schema:       s,
+		}
+	}
+	return &blameInputs, nil
+}
+
+// RowsFromCommit returns the row data of the table with the given name at the given commit
+func RowsFromCommit(ctx context.Context, commit *doltdb.Commit, tableName string) (types.Map, error) {
+	root, err := commit.GetRootValue()
+	if err != nil {
+		return types.EmptyMap, err
+	}
+
+	table, ok, err := root.GetTable(ctx, tableName)
+	if err != nil {
+		return types.EmptyMap, err
+	}
+	if !ok {
+		return types.EmptyMap, fmt.Errorf("no table named %s found", tableName)
+	}
+
+	rowData, err := table.GetRowData(ctx)
+	if err != nil {
+		return types.EmptyMap, err
+	}
+
+	return rowData, nil
+}
+
+func getCommitHashes(old, new *doltdb.Commit) (string, string, error) {
+	oldHash, err := old.HashOf()
+	if err != nil {
+		return "", "", fmt.Errorf("error getting hash of old commit: %v", err)
+	}
+	newHash, err := new.HashOf()
+	if err != nil {
+		return "", "", fmt.Errorf("error getting hash of new commit: %v", err)
+	}
+	return oldHash.String(), newHash.String(), nil
+}
+
+// MaybeTableFromCommit takes a commit and a table name and returns a (possibly nil) pointer to a table
+func MaybeTableFromCommit(ctx context.Context, c *doltdb.Commit, tableName string) (*doltdb.Table, error) {
+	root, err := c.GetRootValue()
+	if err != nil {
+		return nil, fmt.Errorf("error getting root value of commit: %v", err)
+	}
+	table, _, err := root.GetTable(ctx, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting table %s from root value: %v", tableName, err)
+	}
+	return table, nil
+}
+
+// maybeRowFromTable takes a table and a primary key and returns a (possibly nil) pointer to a row
+func maybeRowFromTable(ctx context.Context, t *doltdb.Table, rowPK types.Value) (*row.Row, error) {
+	sch, err := t.GetSchema(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting schema from table: %v", err)
+	}
+
+	r, ok, err := table.GetRow(ctx, t, sch, rowPK.(types.Tuple))
+	if err != nil {
+		return nil, fmt.Errorf("error getting row from table: %v", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	return &r, err
+}
+
+// SchemaFromCommit returns the schema of the table named |tableName| at commit |c|
+func SchemaFromCommit(ctx context.Context, c *doltdb.Commit, tableName string) (schema.Schema, error) {
+	t, err := MaybeTableFromCommit(ctx, c, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting table %s from commit: %v", tableName, err)
+	}
+	if t == nil {
+		return nil, fmt.Errorf("no table named %s found in commit", tableName)
+	}
+
+	schema, err := t.GetSchema(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting schema from table %s: %v", tableName, err)
+	}
+
+	return schema, nil
+}
+
+// rowChanged returns true if the row identified by `rowPK` changed between the parent-child commit pair
+// represented by `input`
+func rowChanged(ctx context.Context, input blameInput, rowPK types.Value) (bool, error) {
+	parentTable := input.ParentTable
+	childTable := input.Table
+
+	// if the table is in the parent commit but not the child one...something's wrong. bail!
+	if parentTable != nil && childTable == nil {
+		return false, fmt.Errorf("expected to find table with name %v in child commit %s, but didn't", input.TableName, input.Hash)
+	}
+	// if the table is in the child commit but not the parent one, it must be new; return true
+	if childTable != nil && parentTable == nil {
+		return true, nil
+	}
+
+	if input.Schema == nil {
+		return false, fmt.Errorf("unexpected nil schema for table %s in child commit %s", input.TableName, input.Hash)
+	}
+	if input.ParentSchema == nil {
+		return false, fmt.Errorf("unexpected nil schema for table %s in parent commit %s", input.TableName, input.ParentHash)
+	}
+
+	// if the table schema has changed, every row has changed (according to our current definition of blame)
+	schemasEql, err := schema.SchemasAreEqual(input.ParentSchema, input.Schema)
+	if err != nil {
+		return false, err
+	}
+	if !schemasEql {
+		return true, nil
+	}
+
+	parentRow, err := maybeRowFromTable(ctx, parentTable, rowPK)
+	if err != nil {
+		return false, fmt.Errorf("error getting row from %s in parent commit %s: %v", input.TableName, input.ParentHash, err)
+	}
+	childRow, err := maybeRowFromTable(ctx, childTable, rowPK)
+	if err != nil {
+		return false, fmt.Errorf("error getting row from %s in child commit %s: %v", input.TableName, input.Hash, err)
+	}
+
+	// if the row is in the parent table but not the child one...something's wrong. bail!
+	if parentRow != nil && childRow == nil {
+		return false, fmt.Errorf("expected to find row with PK %v in table %s in child commit %s, but didn't", rowPK, input.TableName, input.Hash)
+	}
+	// if the row is in the child table but not the parent one, it must be new; return true
+	if childRow != nil && parentRow == nil {
+		return true, nil
+	}
+
+	return !row.AreEqual(*parentRow, *childRow, input.ParentSchema), nil
+}
+
+func blameGraphFromRows(ctx context.Context, nbf *types.NomsBinFormat, rows types.Map) (*BlameGraph, error) {
+	graph := make(BlameGraph)
+	err := rows.IterAll(ctx, func(key, val types.Value) error {
+		hash, err := key.Hash(nbf)
+		if err != nil {
+			return err
+		}
+		graph[hash] = BlameInfo{Key: key}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &graph, nil
+}
+
+// AssignBlame updates the blame graph to contain blame information from the given commit
+// for the row identified by the given primary key
+func (bg *BlameGraph) AssignBlame(rowPK types.Value, nbf *types.NomsBinFormat, c *doltdb.Commit) error {
+	commitHash, err := c.HashOf()
+	if err != nil {
+		return fmt.Errorf("error getting commit hash: %v", err)
+	}
+
+	meta, err := c.GetCommitMeta()
+	if err != nil {
+		return fmt.Errorf("error getting metadata for commit %s: %v", commitHash.String(), err)
+	}
+
+	pkHash, err := rowPK.Hash(nbf)
+	if err != nil {
+		return fmt.Errorf("error getting PK hash for commit %s: %v", commitHash.String(), err)
+	}
+
+	(*bg)[pkHash] = BlameInfo{
+		Key:         rowPK,
+		CommitHash:  commitHash.String(),
+		Author:      meta.Name,
+		Email:       meta.Email,
+		Description: meta.Description,
+		Timestamp:   meta.UserTimestamp,
+	}
+
+	return nil
+}
+
+// GetPKStrs returns the string representations of the values of the primary key |pk|
+func GetPKStrs(ctx context.Context, pk types.Value) (strs []string) {
+	i := 0
+	pk.WalkValues(ctx, func(val types.Value) error {
+		// even-indexed values are index numbers. they aren't useful, don't print them.
+		if i%2 == 1 {
+			strs = append(strs, fmt.Sprintf("%v", val))
+		}
+		i++
+		return nil
+	})
+
+	return strs
+}
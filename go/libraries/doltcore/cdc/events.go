@@ -0,0 +1,235 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	ndiff "github.com/dolthub/dolt/go/store/diff"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// ChangeType is the type of change made to a row
+type ChangeType string
+
+const (
+	Insert ChangeType = "insert"
+	Update ChangeType = "update"
+	Delete ChangeType = "delete"
+)
+
+// Event is a change made to a single row of a table by moving the head of a branch. Updates to keyless tables are
+// reported as a delete of the old row and an insert of the new one, once for each copy of the row.
+type Event struct {
+	Database string `json:"database"`
+	Branch   string `json:"branch"`
+	// Commit is the hash of the commit the head of the branch was moved to
+	Commit string `json:"commit"`
+	// PrevCommit is the hash of the commit the head of the branch was moved from
+	PrevCommit string     `json:"prev_commit"`
+	Table      string     `json:"table"`
+	Type       ChangeType `json:"type"`
+	// PK maps the names of the primary key columns of the row to their values. It is empty for keyless tables.
+	PK map[string]interface{} `json:"pk,omitempty"`
+	// Old maps the names of the columns of the row to their values before the change. It is empty for inserts.
+	Old map[string]interface{} `json:"old,omitempty"`
+	// New maps the names of the columns of the row to their values after the change. It is empty for deletes.
+	New map[string]interface{} `json:"new,omitempty"`
+}
+
+const diffBatchSize = 256
+
+// HeadUpdateEvents computes the events for each row which differs between the roots of the commits |prev| and |curr|,
+// and calls |cb| with each of them, in order of table and then primary key. Tables which were added or dropped report
+// each of their rows as inserted or deleted.
+func HeadUpdateEvents(ctx context.Context, dbName, branch string, prev, curr *doltdb.Commit, cb func(Event) error) error {
+	prevHash, err := prev.HashOf()
+
+	if err != nil {
+		return err
+	}
+
+	currHash, err := curr.HashOf()
+
+	if err != nil {
+		return err
+	}
+
+	if prevHash == currHash {
+		return nil
+	}
+
+	fromRoot, err := prev.GetRootValue()
+
+	if err != nil {
+		return err
+	}
+
+	toRoot, err := curr.GetRootValue()
+
+	if err != nil {
+		return err
+	}
+
+	deltas, err := diff.GetTableDeltas(ctx, fromRoot, toRoot)
+
+	if err != nil {
+		return err
+	}
+
+	base := Event{Database: dbName, Branch: branch, Commit: currHash.String(), PrevCommit: prevHash.String()}
+
+	for _, td := range deltas {
+		base.Table = td.CurName()
+		err = tableDeltaEvents(ctx, td, base, cb)
+
+		if err != nil {
+			return fmt.Errorf("error computing the changes to table '%s': %w", base.Table, err)
+		}
+	}
+
+	return nil
+}
+
+func tableDeltaEvents(ctx context.Context, td diff.TableDelta, base Event, cb func(Event) error) (err error) {
+	fromSch, toSch, err := td.GetSchemas(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	// an added or dropped table only has the schema it was added or dropped with
+	if td.FromTable == nil {
+		fromSch = toSch
+	} else if td.ToTable == nil {
+		toSch = fromSch
+	}
+
+	fromRows, toRows, err := td.GetMaps(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	rd := diff.NewRowDiffer(ctx, fromSch, toSch, 1024)
+	rd.Start(ctx, fromRows, toRows)
+	defer func() {
+		if cerr := rd.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	hasMore := true
+	var diffs []*ndiff.Difference
+	for hasMore {
+		diffs, hasMore, err = rd.GetDiffs(diffBatchSize, time.Millisecond)
+
+		if err != nil {
+			return err
+		}
+
+		for _, d := range diffs {
+			evt, err := rowEvent(base, fromSch, toSch, d)
+
+			if err != nil {
+				return err
+			}
+
+			err = cb(evt)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func rowEvent(base Event, fromSch, toSch schema.Schema, d *ndiff.Difference) (Event, error) {
+	evt := base
+	key := d.KeyValue.(types.Tuple)
+
+	switch d.ChangeType {
+	case types.DiffChangeAdded:
+		evt.Type = Insert
+	case types.DiffChangeRemoved:
+		evt.Type = Delete
+	case types.DiffChangeModified:
+		evt.Type = Update
+	default:
+		return Event{}, fmt.Errorf("unknown change type %v", d.ChangeType)
+	}
+
+	var err error
+	if d.OldValue != nil {
+		evt.Old, err = columnValues(fromSch, key, d.OldValue.(types.Tuple))
+
+		if err != nil {
+			return Event{}, err
+		}
+	}
+
+	if d.NewValue != nil {
+		evt.New, err = columnValues(toSch, key, d.NewValue.(types.Tuple))
+
+		if err != nil {
+			return Event{}, err
+		}
+	}
+
+	vals, sch := evt.New, toSch
+	if evt.Type == Delete {
+		vals, sch = evt.Old, fromSch
+	}
+
+	if !schema.IsKeyless(sch) {
+		evt.PK = make(map[string]interface{})
+		for _, col := range sch.GetPKCols().GetColumns() {
+			evt.PK[col.Name] = vals[col.Name]
+		}
+	}
+
+	return evt, nil
+}
+
+// columnValues returns the values of the columns of the row with the tuples |key| and |val|, by column name
+func columnValues(sch schema.Schema, key, val types.Tuple) (map[string]interface{}, error) {
+	r, err := row.FromNoms(sch, key, val)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sqlRow, err := sqlutil.DoltRowToSqlRow(r, sch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make(map[string]interface{}, len(sqlRow))
+	for i, col := range sch.GetAllCols().GetColumns() {
+		vals[col.Name] = sqlRow[i]
+	}
+
+	return vals, nil
+}
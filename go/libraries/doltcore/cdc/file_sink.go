@@ -0,0 +1,59 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+)
+
+// FileSink appends events to a file as newline delimited JSON.
+type FileSink struct {
+	f *os.File
+}
+
+var _ Sink = (*FileSink)(nil)
+
+// NewFileSink opens the file at |path| for appending, creating it if it doesn't exist. The events hold row data, so a
+// new file is only readable by its owner.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{f}, nil
+}
+
+// Write appends |events| to the file, one per line. Each batch is written in full before Write returns.
+func (fs *FileSink) Write(events []Event) error {
+	wr := bufio.NewWriter(fs.f)
+	enc := json.NewEncoder(wr)
+
+	for _, evt := range events {
+		if err := enc.Encode(evt); err != nil {
+			return err
+		}
+	}
+
+	return wr.Flush()
+}
+
+// Close closes the file.
+func (fs *FileSink) Close() error {
+	return fs.f.Close()
+}
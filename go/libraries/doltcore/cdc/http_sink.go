@@ -0,0 +1,160 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// subscriberBufferSize is the number of batches of events which may be waiting to be sent to a subscriber. A subscriber
+// which falls further behind is disconnected rather than holding up the stream.
+const subscriberBufferSize = 64
+
+// HTTPSink streams events to HTTP clients as newline delimited JSON. Clients receive the events written after they
+// connect, and may limit them to those of a single database or branch with the "database" and "branch" query
+// parameters.
+type HTTPSink struct {
+	mu     *sync.Mutex
+	subs   map[*subscriber]struct{}
+	closed bool
+}
+
+var _ Sink = (*HTTPSink)(nil)
+var _ http.Handler = (*HTTPSink)(nil)
+
+type subscriber struct {
+	database string
+	branch   string
+	events   chan []Event
+}
+
+func (sub *subscriber) filter(events []Event) []Event {
+	if sub.database == "" && sub.branch == "" {
+		return events
+	}
+
+	var filtered []Event
+	for _, evt := range events {
+		if (sub.database == "" || sub.database == evt.Database) && (sub.branch == "" || sub.branch == evt.Branch) {
+			filtered = append(filtered, evt)
+		}
+	}
+
+	return filtered
+}
+
+// NewHTTPSink returns an HTTPSink with no connected clients.
+func NewHTTPSink() *HTTPSink {
+	return &HTTPSink{mu: &sync.Mutex{}, subs: make(map[*subscriber]struct{})}
+}
+
+// Write sends |events| to each connected client.
+func (hs *HTTPSink) Write(events []Event) error {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	for sub := range hs.subs {
+		filtered := sub.filter(events)
+
+		if len(filtered) == 0 {
+			continue
+		}
+
+		select {
+		case sub.events <- filtered:
+		default:
+			close(sub.events)
+			delete(hs.subs, sub)
+		}
+	}
+
+	return nil
+}
+
+// Close ends the responses of the connected clients.
+func (hs *HTTPSink) Close() error {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	for sub := range hs.subs {
+		close(sub.events)
+		delete(hs.subs, sub)
+	}
+
+	hs.closed = true
+	return nil
+}
+
+// ServeHTTP streams the events written to the sink to the client until it disconnects, or the sink is closed.
+func (hs *HTTPSink) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	sub := &subscriber{
+		database: req.URL.Query().Get("database"),
+		branch:   req.URL.Query().Get("branch"),
+		events:   make(chan []Event, subscriberBufferSize),
+	}
+
+	hs.mu.Lock()
+	if hs.closed {
+		hs.mu.Unlock()
+		http.Error(w, "the change stream is closed", http.StatusServiceUnavailable)
+		return
+	}
+
+	hs.subs[sub] = struct{}{}
+	hs.mu.Unlock()
+
+	defer func() {
+		hs.mu.Lock()
+		defer hs.mu.Unlock()
+
+		if _, ok := hs.subs[sub]; ok {
+			close(sub.events)
+			delete(hs.subs, sub)
+		}
+	}()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case events, ok := <-sub.events:
+			if !ok {
+				return
+			}
+
+			for _, evt := range events {
+				if err := enc.Encode(evt); err != nil {
+					return
+				}
+			}
+
+			flusher.Flush()
+		}
+	}
+}
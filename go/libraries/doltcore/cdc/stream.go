@@ -0,0 +1,175 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+)
+
+// Sink receives the events of a Stream.
+type Sink interface {
+	// Write is called with batches of events, in the order the changes were made. It is never called concurrently.
+	Write(events []Event) error
+	// Close is called once the stream has written its last events to the sink.
+	Close() error
+}
+
+const eventBatchSize = 256
+
+type headUpdate struct {
+	dbName string
+	branch string
+	prev   *doltdb.Commit
+	curr   *doltdb.Commit
+}
+
+// Stream computes the events for each branch head moved by the databases it watches, and writes them to its sinks.
+// The events are computed asynchronously, in the order the heads were moved, so moving a head doesn't wait for its
+// changes to be diffed. Branches which are created or deleted have no events.
+type Stream struct {
+	sinks []Sink
+	onErr func(error)
+
+	mu     *sync.Mutex
+	cond   *sync.Cond
+	queue  []headUpdate
+	closed bool
+	done   chan struct{}
+}
+
+// NewStream returns a Stream which writes its events to |sinks|. |onErr| is called with the errors of computing or
+// writing the events of a head update, which are dropped.
+func NewStream(onErr func(error), sinks ...Sink) *Stream {
+	mu := &sync.Mutex{}
+	s := &Stream{
+		sinks: sinks,
+		onErr: onErr,
+		mu:    mu,
+		cond:  sync.NewCond(mu),
+		done:  make(chan struct{}),
+	}
+
+	go s.run()
+
+	return s
+}
+
+// Watch registers the stream as a listener of the head updates of |ddb|, whose events are reported as changes to the
+// database |dbName|.
+func (s *Stream) Watch(dbName string, ddb *doltdb.DoltDB) {
+	ddb.AddHeadUpdateListener(func(ctx context.Context, branch ref.DoltRef, prev, curr *doltdb.Commit) {
+		if prev == nil || curr == nil {
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.closed {
+			return
+		}
+
+		s.queue = append(s.queue, headUpdate{dbName, branch.GetPath(), prev, curr})
+		s.cond.Signal()
+	})
+}
+
+// Close writes the events of the head updates which are already queued, and then closes the sinks of the stream.
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Signal()
+	s.mu.Unlock()
+
+	<-s.done
+
+	var firstErr error
+	for _, sink := range s.sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+func (s *Stream) run() {
+	defer close(s.done)
+
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			return
+		}
+
+		update := s.queue[0]
+		s.queue[0] = headUpdate{}
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		if err := s.writeEvents(update); err != nil {
+			s.onErr(fmt.Errorf("error streaming the changes to branch '%s' of database '%s': %w", update.branch, update.dbName, err))
+		}
+	}
+}
+
+// writeEvents computes the events of |update| and writes them to the sinks of the stream in batches.
+func (s *Stream) writeEvents(update headUpdate) error {
+	// the context of the head update belongs to the query which made it, and is done long before its changes are
+	// computed
+	ctx := context.Background()
+
+	batch := make([]Event, 0, eventBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		for _, sink := range s.sinks {
+			if err := sink.Write(batch); err != nil {
+				return err
+			}
+		}
+
+		batch = make([]Event, 0, eventBatchSize)
+		return nil
+	}
+
+	err := HeadUpdateEvents(ctx, update.dbName, update.branch, update.prev, update.curr, func(evt Event) error {
+		batch = append(batch, evt)
+
+		if len(batch) == eventBatchSize {
+			return flush()
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return flush()
+}
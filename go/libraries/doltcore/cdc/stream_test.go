@@ -0,0 +1,173 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
+	tc "github.com/dolthub/dolt/go/libraries/doltcore/dtestutils/testcommands"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+)
+
+func readEvents(t *testing.T, rd io.Reader) []Event {
+	var events []Event
+	scanner := bufio.NewScanner(rd)
+	for scanner.Scan() {
+		var evt Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &evt))
+		events = append(events, evt)
+	}
+	require.NoError(t, scanner.Err())
+
+	return events
+}
+
+func headHash(t *testing.T, dEnv *env.DoltEnv) string {
+	cm, err := dEnv.DoltDB.ResolveRef(context.Background(), dEnv.RepoState.CWBHeadRef())
+	require.NoError(t, err)
+	h, err := cm.HashOf()
+	require.NoError(t, err)
+	return h.String()
+}
+
+func TestStream(t *testing.T) {
+	dEnv := dtestutils.CreateTestEnv()
+	setup := []tc.Command{
+		tc.Query{Query: "create table test (pk int primary key, c1 varchar(20));"},
+		tc.Query{Query: "insert into test values (1, 'a'), (2, 'b'), (3, 'c');"},
+		tc.CommitAll{Message: "add test"},
+	}
+	for _, cmd := range setup {
+		require.NoError(t, cmd.Exec(t, dEnv), cmd.CommandString())
+	}
+	prevHash := headHash(t, dEnv)
+
+	dir, err := ioutil.TempDir("", "cdc")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "changes.jsonl")
+	fileSink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	httpSink := NewHTTPSink()
+	srv := httptest.NewServer(httpSink)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?database=dolt&branch=master")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	filteredResp, err := http.Get(srv.URL + "?branch=other")
+	require.NoError(t, err)
+	defer filteredResp.Body.Close()
+
+	stream := NewStream(func(err error) { t.Error(err) }, fileSink, httpSink)
+	stream.Watch("dolt", dEnv.DoltDB)
+
+	changes := []tc.Command{
+		tc.Query{Query: "update test set c1 = 'aa' where pk = 1;"},
+		tc.Query{Query: "delete from test where pk = 2;"},
+		tc.Query{Query: "insert into test values (4, 'd');"},
+		tc.CommitAll{Message: "change test"},
+		// creating a branch has no events
+		tc.Branch{BranchName: "other"},
+	}
+	for _, cmd := range changes {
+		require.NoError(t, cmd.Exec(t, dEnv), cmd.CommandString())
+	}
+	currHash := headHash(t, dEnv)
+
+	require.NoError(t, stream.Close())
+
+	base := Event{Database: "dolt", Branch: "master", Commit: currHash, PrevCommit: prevHash, Table: "test"}
+	update, del, insert := base, base, base
+	update.Type = Update
+	update.PK = map[string]interface{}{"pk": float64(1)}
+	update.Old = map[string]interface{}{"pk": float64(1), "c1": "a"}
+	update.New = map[string]interface{}{"pk": float64(1), "c1": "aa"}
+	del.Type = Delete
+	del.PK = map[string]interface{}{"pk": float64(2)}
+	del.Old = map[string]interface{}{"pk": float64(2), "c1": "b"}
+	insert.Type = Insert
+	insert.PK = map[string]interface{}{"pk": float64(4)}
+	insert.New = map[string]interface{}{"pk": float64(4), "c1": "d"}
+	expected := []Event{update, del, insert}
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, expected, readEvents(t, f))
+
+	// closing the stream ends the responses of its clients
+	assert.Equal(t, expected, readEvents(t, resp.Body))
+	assert.Empty(t, readEvents(t, filteredResp.Body))
+}
+
+func TestHeadUpdateEventsForDroppedTable(t *testing.T) {
+	dEnv := dtestutils.CreateTestEnv()
+	cmds := []tc.Command{
+		tc.Query{Query: "create table test (pk int primary key, c1 varchar(20));"},
+		tc.Query{Query: "insert into test values (1, 'a'), (2, 'b');"},
+		tc.CommitAll{Message: "add test"},
+		tc.Query{Query: "drop table test;"},
+		tc.CommitAll{Message: "drop test"},
+	}
+	for _, cmd := range cmds {
+		require.NoError(t, cmd.Exec(t, dEnv), cmd.CommandString())
+	}
+
+	ctx := context.Background()
+	curr, err := dEnv.DoltDB.ResolveRef(ctx, dEnv.RepoState.CWBHeadRef())
+	require.NoError(t, err)
+	prev, err := dEnv.DoltDB.ResolveParent(ctx, curr, 0)
+	require.NoError(t, err)
+
+	var events []Event
+	err = HeadUpdateEvents(ctx, "dolt", "master", prev, curr, func(evt Event) error {
+		events = append(events, evt)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, events, 2)
+	for i, evt := range events {
+		assert.Equal(t, Delete, evt.Type)
+		assert.Equal(t, "test", evt.Table)
+		assert.Equal(t, map[string]interface{}{"pk": int32(i + 1)}, evt.PK)
+		assert.Nil(t, evt.New)
+	}
+
+	// moving a head back to the same commit has no events
+	events = nil
+	err = HeadUpdateEvents(ctx, "dolt", "master", curr, curr, func(evt Event) error {
+		events = append(events, evt)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
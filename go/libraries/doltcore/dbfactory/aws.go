@@ -102,6 +102,7 @@ func AWSCredentialSourceFromStr(str string) AWSCredentialSource {
 
 // AWSFactory is a DBFactory implementation for creating AWS backed databases
 type AWSFactory struct {
+	cp CredsProvider
 }
 
 // CreateDB creates an AWS backed database
@@ -136,8 +137,14 @@ func (fact AWSFactory) newChunkStore(ctx context.Context, nbf *types.NomsBinForm
 		return nil, err
 	}
 
+	tfc, err := tableFileCipherFromParams(fact.cp, params)
+
+	if err != nil {
+		return nil, err
+	}
+
 	sess := session.Must(session.NewSessionWithOptions(opts))
-	return nbs.NewAWSStore(ctx, nbf.VersionString(), parts[0], dbName, parts[1], s3.New(sess), dynamodb.New(sess), defaultMemTableSize)
+	return nbs.NewEncryptedAWSStore(ctx, nbf.VersionString(), parts[0], dbName, parts[1], s3.New(sess), dynamodb.New(sess), defaultMemTableSize, tfc)
 }
 
 func validatePath(path string) (string, error) {
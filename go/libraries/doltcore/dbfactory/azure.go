@@ -0,0 +1,94 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/dolthub/dolt/go/store/blobstore"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/nbs"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	// AzureEndpointParam is a creation parameter that can be used to set the url of the Blob service of the storage
+	// account, such as the url of the Azure storage emulator. It defaults to https://[account].blob.core.windows.net
+	AzureEndpointParam = "azure-endpoint"
+
+	// AzureStorageKeyEnvVar is the environment variable holding the key of the storage account
+	AzureStorageKeyEnvVar = "AZURE_STORAGE_KEY"
+
+	// AzureStorageSASTokenEnvVar is the environment variable holding a shared access signature for the container. It is
+	// used when AZURE_STORAGE_KEY isn't set.
+	AzureStorageSASTokenEnvVar = "AZURE_STORAGE_SAS_TOKEN"
+)
+
+// AzureFactory is a DBFactory implementation for creating Azure Blob Storage backed databases
+type AzureFactory struct {
+	cp CredsProvider
+}
+
+// CreateDB creates an Azure Blob Storage backed database
+func (fact AzureFactory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat, urlObj *url.URL, params map[string]string) (datas.Database, error) {
+	var db datas.Database
+	bs, err := azureBlobstoreFromURL(urlObj, params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tfc, err := tableFileCipherFromParams(fact.cp, params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	azStore, err := nbs.NewEncryptedBSStore(ctx, nbf.VersionString(), bs, defaultMemTableSize, tfc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	db = datas.NewDatabase(azStore)
+
+	return db, err
+}
+
+// azureBlobstoreFromURL returns the blobstore for a url of the form az://[account]/[container]/[path]
+func azureBlobstoreFromURL(urlObj *url.URL, params map[string]string) (*blobstore.AzureBlobstore, error) {
+	parts := strings.SplitN(strings.TrimPrefix(urlObj.Path, "/"), "/", 2) // [container]/[path]
+	if urlObj.Host == "" || parts[0] == "" {
+		return nil, errors.New("azure url has an invalid format, expected az://[account]/[container]/[path]")
+	}
+
+	prefix := ""
+	if len(parts) == 2 {
+		prefix = parts[1]
+	}
+
+	creds := blobstore.AzureCredentials{
+		AccountName: urlObj.Host,
+		AccountKey:  os.Getenv(AzureStorageKeyEnvVar),
+		SASToken:    os.Getenv(AzureStorageSASTokenEnvVar),
+	}
+
+	return blobstore.NewAzureBlobstore(http.DefaultClient, params[AzureEndpointParam], parts[0], prefix, creds)
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/creds"
+	"github.com/dolthub/dolt/go/store/nbs"
+)
+
+const (
+	// EncryptionKeyParam is a creation parameter holding a passphrase. The table files of cloud remotes are
+	// encrypted with a key derived from the passphrase.
+	EncryptionKeyParam = "encryption-key"
+
+	// EncryptionCredsParam is a creation parameter holding the public key or key id of dolt credentials. The table files
+	// of cloud remotes are encrypted with a key derived from the private key of the credentials.
+	EncryptionCredsParam = "encryption-creds"
+
+	passphraseSalt    = "dolt table file encryption"
+	credsKeyInfo      = "dolt table file encryption key"
+	scryptN, scryptR  = 1 << 15, 8
+	scryptParallelism = 1
+)
+
+var ErrNoCredsProvider = errors.New("dolt credentials are not available to derive the encryption key from")
+
+// CredsProvider is an interface for getting the dolt credentials which encrypt the table files of remotes
+type CredsProvider interface {
+	GetCreds(pubKeyOrId string) (creds.DoltCreds, error)
+}
+
+// tableFileCipherFromParams returns the cipher for the encryption params of a remote, or nil if its table files aren't
+// encrypted
+func tableFileCipherFromParams(cp CredsProvider, params map[string]string) (*nbs.TableFileCipher, error) {
+	passphrase, hasPassphrase := params[EncryptionKeyParam]
+	pubKeyOrId, hasCreds := params[EncryptionCredsParam]
+
+	var key []byte
+	var err error
+	switch {
+	case hasPassphrase && hasCreds:
+		return nil, fmt.Errorf("only one of %s and %s can be used", EncryptionKeyParam, EncryptionCredsParam)
+	case hasPassphrase:
+		key, err = KeyFromPassphrase(passphrase)
+	case hasCreds:
+		key, err = keyFromCreds(cp, pubKeyOrId)
+	default:
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return nbs.NewTableFileCipher(key)
+}
+
+// KeyFromPassphrase derives the key which encrypts table files from |passphrase|
+func KeyFromPassphrase(passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("%s cannot be empty", EncryptionKeyParam)
+	}
+
+	return scrypt.Key([]byte(passphrase), []byte(passphraseSalt), scryptN, scryptR, scryptParallelism, nbs.TableFileEncryptionKeySize)
+}
+
+func keyFromCreds(cp CredsProvider, pubKeyOrId string) ([]byte, error) {
+	if cp == nil {
+		return nil, ErrNoCredsProvider
+	}
+
+	dc, err := cp.GetCreds(pubKeyOrId)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the credentials '%s' used for encryption: %w", pubKeyOrId, err)
+	}
+
+	if !dc.IsPrivKeyValid() {
+		return nil, fmt.Errorf("the credentials '%s' used for encryption have no valid private key", pubKeyOrId)
+	}
+
+	mac := hmac.New(sha256.New, dc.PrivKey)
+	mac.Write([]byte(credsKeyInfo))
+	return mac.Sum(nil), nil
+}
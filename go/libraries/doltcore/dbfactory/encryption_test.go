@@ -0,0 +1,116 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/creds"
+	"github.com/dolthub/dolt/go/store/nbs"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+type testCredsProvider map[string]creds.DoltCreds
+
+func (cp testCredsProvider) GetCreds(pubKeyOrId string) (creds.DoltCreds, error) {
+	if dc, ok := cp[pubKeyOrId]; ok {
+		return dc, nil
+	}
+
+	return creds.EmptyCreds, creds.ErrCredsNotFound
+}
+
+func TestTableFileCipherFromParams(t *testing.T) {
+	dc, err := creds.GenerateCredentials()
+	require.NoError(t, err)
+	cp := testCredsProvider{dc.KeyIDBase32Str(): dc}
+
+	tfc, err := tableFileCipherFromParams(cp, map[string]string{AWSRegionParam: "us-west-2"})
+	require.NoError(t, err)
+	assert.Nil(t, tfc)
+
+	tfc, err = tableFileCipherFromParams(cp, map[string]string{EncryptionKeyParam: "passphrase"})
+	require.NoError(t, err)
+	assert.NotNil(t, tfc)
+
+	tfc, err = tableFileCipherFromParams(cp, map[string]string{EncryptionCredsParam: dc.KeyIDBase32Str()})
+	require.NoError(t, err)
+	assert.NotNil(t, tfc)
+
+	_, err = tableFileCipherFromParams(cp, map[string]string{EncryptionKeyParam: ""})
+	assert.Error(t, err)
+
+	_, err = tableFileCipherFromParams(cp, map[string]string{EncryptionKeyParam: "passphrase", EncryptionCredsParam: dc.KeyIDBase32Str()})
+	assert.Error(t, err)
+
+	_, err = tableFileCipherFromParams(cp, map[string]string{EncryptionCredsParam: "unknown"})
+	assert.True(t, errors.Is(err, creds.ErrCredsNotFound))
+
+	_, err = tableFileCipherFromParams(nil, map[string]string{EncryptionCredsParam: dc.KeyIDBase32Str()})
+	assert.Equal(t, ErrNoCredsProvider, err)
+
+	key1, err := KeyFromPassphrase("passphrase")
+	require.NoError(t, err)
+	key2, err := KeyFromPassphrase("passphrase")
+	require.NoError(t, err)
+	key3, err := KeyFromPassphrase("other passphrase")
+	require.NoError(t, err)
+	assert.Equal(t, key1, key2)
+	assert.NotEqual(t, key1, key3)
+}
+
+func TestEncryptedLocalBSDB(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "encrypted_localbs_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	urlObj, err := url.Parse("localbs://" + dir)
+	require.NoError(t, err)
+	fact := LocalBSFactory{}
+	params := map[string]string{EncryptionKeyParam: "passphrase"}
+
+	db, err := fact.CreateDB(ctx, types.Format_Default, urlObj, params)
+	require.NoError(t, err)
+	ds, err := db.GetDataset(ctx, "ds")
+	require.NoError(t, err)
+	_, err = db.CommitValue(ctx, ds, types.String("encrypted"))
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	db, err = fact.CreateDB(ctx, types.Format_Default, urlObj, params)
+	require.NoError(t, err)
+	ds, err = db.GetDataset(ctx, "ds")
+	require.NoError(t, err)
+	val, ok, err := ds.MaybeHeadValue()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, types.String("encrypted"), val)
+	require.NoError(t, db.Close())
+
+	_, err = fact.CreateDB(ctx, types.Format_Default, urlObj, map[string]string{EncryptionKeyParam: "wrong passphrase"})
+	assert.True(t, errors.Is(err, nbs.ErrWrongEncryptionKey))
+
+	_, err = fact.CreateDB(ctx, types.Format_Default, urlObj, nil)
+	assert.True(t, errors.Is(err, nbs.ErrTableFileEncrypted))
+}
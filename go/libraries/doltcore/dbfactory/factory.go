@@ -32,6 +32,12 @@ const (
 	// GSScheme
 	GSScheme = "gs"
 
+	// S3Scheme
+	S3Scheme = "s3"
+
+	// AzureScheme
+	AzureScheme = "az"
+
 	// FileScheme
 	FileScheme = "file"
 
@@ -47,6 +53,9 @@ const (
 	// InMemBlobstore Scheme
 	LocalBSScheme = "localbs"
 
+	// SSHScheme
+	SSHScheme = "ssh"
+
 	defaultScheme       = HTTPSScheme
 	defaultMemTableSize = 256 * 1024 * 1024
 )
@@ -61,15 +70,24 @@ type DBFactory interface {
 var DBFactories = map[string]DBFactory{
 	AWSScheme:     AWSFactory{},
 	GSScheme:      GSFactory{},
+	S3Scheme:      S3Factory{},
+	AzureScheme:   AzureFactory{},
 	FileScheme:    FileFactory{},
 	MemScheme:     MemFactory{},
 	LocalBSScheme: LocalBSFactory{},
+	SSHScheme:     SSHFactory{},
 }
 
-// InitializeFactories initializes any factories that rely on a GRPCConnectionProvider (Namely http and https)
-func InitializeFactories(dp GRPCDialProvider) {
+// InitializeFactories initializes any factories that rely on a GRPCConnectionProvider (Namely http and https), or on a
+// CredsProvider (the cloud factories, which can encrypt table files with a key derived from dolt credentials)
+func InitializeFactories(dp GRPCDialProvider, cp CredsProvider) {
 	DBFactories[HTTPScheme] = NewDoltRemoteFactory(dp, true)
 	DBFactories[HTTPSScheme] = NewDoltRemoteFactory(dp, false)
+	DBFactories[AWSScheme] = AWSFactory{cp}
+	DBFactories[GSScheme] = GSFactory{cp}
+	DBFactories[S3Scheme] = S3Factory{cp}
+	DBFactories[AzureScheme] = AzureFactory{cp}
+	DBFactories[LocalBSScheme] = LocalBSFactory{cp}
 }
 
 // CreateDB creates a database based on the supplied urlStr, and creation params.  The DBFactory used for creation is
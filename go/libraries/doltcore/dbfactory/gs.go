@@ -29,11 +29,18 @@ import (
 
 // GSFactory is a DBFactory implementation for creating GCS backed databases
 type GSFactory struct {
+	cp CredsProvider
 }
 
 // CreateDB creates an GCS backed database
 func (fact GSFactory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat, urlObj *url.URL, params map[string]string) (datas.Database, error) {
 	var db datas.Database
+	tfc, err := tableFileCipherFromParams(fact.cp, params)
+
+	if err != nil {
+		return nil, err
+	}
+
 	gcs, err := storage.NewClient(ctx)
 
 	if err != nil {
@@ -41,7 +48,7 @@ func (fact GSFactory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat, ur
 	}
 
 	bs := blobstore.NewGCSBlobstore(gcs, urlObj.Host, urlObj.Path)
-	gcsStore, err := nbs.NewBSStore(ctx, nbf.VersionString(), bs, defaultMemTableSize)
+	gcsStore, err := nbs.NewEncryptedBSStore(ctx, nbf.VersionString(), bs, defaultMemTableSize, tfc)
 
 	if err != nil {
 		return nil, err
@@ -54,6 +61,7 @@ func (fact GSFactory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat, ur
 
 // LocalBSFactory is a DBFactory implementation for creating a local filesystem blobstore backed databases for testing
 type LocalBSFactory struct {
+	cp CredsProvider
 }
 
 // CreateDB creates a local filesystem blobstore backed database
@@ -65,8 +73,14 @@ func (fact LocalBSFactory) CreateDB(ctx context.Context, nbf *types.NomsBinForma
 		return nil, err
 	}
 
+	tfc, err := tableFileCipherFromParams(fact.cp, params)
+
+	if err != nil {
+		return nil, err
+	}
+
 	bs := blobstore.NewLocalBlobstore(absPath)
-	bsStore, err := nbs.NewBSStore(ctx, nbf.VersionString(), bs, defaultMemTableSize)
+	bsStore, err := nbs.NewEncryptedBSStore(ctx, nbf.VersionString(), bs, defaultMemTableSize, tfc)
 
 	if err != nil {
 		return nil, err
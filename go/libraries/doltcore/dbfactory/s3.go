@@ -0,0 +1,102 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/dolthub/dolt/go/store/blobstore"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/nbs"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	// S3EndpointParam is a creation parameter that can be used to set the url of an S3 compatible object store, such as
+	// MinIO or Ceph, to use instead of AWS S3
+	S3EndpointParam = "s3-endpoint"
+
+	// the region used with an s3 compatible endpoint when none is given. Most s3 compatible stores ignore the region,
+	// but requests can't be signed without one.
+	defaultS3EndpointRegion = "us-east-1"
+)
+
+// S3Factory is a DBFactory implementation for creating databases backed by S3 or an S3 compatible object store. Unlike
+// the AWSFactory it doesn't use DynamoDB, and the manifest is stored in the bucket and updated with conditional writes.
+type S3Factory struct {
+	cp CredsProvider
+}
+
+// CreateDB creates an S3 backed database
+func (fact S3Factory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat, urlObj *url.URL, params map[string]string) (datas.Database, error) {
+	var db datas.Database
+	if urlObj.Host == "" {
+		return nil, errors.New("s3 url has an invalid format, expected s3://[bucket]/[path]")
+	}
+
+	opts, err := s3SessionOptionsFromParams(params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tfc, err := tableFileCipherFromParams(fact.cp, params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sess := session.Must(session.NewSessionWithOptions(opts))
+	bs := blobstore.NewS3Blobstore(s3.New(sess), urlObj.Host, urlObj.Path)
+	s3Store, err := nbs.NewEncryptedBSStore(ctx, nbf.VersionString(), bs, defaultMemTableSize, tfc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	db = datas.NewDatabase(s3Store)
+
+	return db, err
+}
+
+func s3SessionOptionsFromParams(params map[string]string) (session.Options, error) {
+	opts, err := awsConfigFromParams(params)
+
+	if err != nil {
+		return session.Options{}, err
+	}
+
+	if endpoint, ok := params[S3EndpointParam]; ok {
+		if endpoint == "" {
+			return session.Options{}, errors.New(S3EndpointParam + " cannot be empty")
+		}
+
+		// s3 compatible stores generally don't support bucket names in the host name of urls
+		opts.Config.Endpoint = aws.String(endpoint)
+		opts.Config.S3ForcePathStyle = aws.Bool(true)
+
+		if _, ok := params[AWSRegionParam]; !ok {
+			opts.Config.Region = aws.String(defaultS3EndpointRegion)
+		}
+	}
+
+	return opts, nil
+}
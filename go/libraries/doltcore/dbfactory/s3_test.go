@@ -0,0 +1,44 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3SessionOptionsFromParams(t *testing.T) {
+	opts, err := s3SessionOptionsFromParams(map[string]string{AWSRegionParam: "us-west-2"})
+	require.NoError(t, err)
+	assert.Equal(t, "us-west-2", aws.StringValue(opts.Config.Region))
+	assert.Nil(t, opts.Config.Endpoint)
+	assert.False(t, aws.BoolValue(opts.Config.S3ForcePathStyle))
+
+	opts, err = s3SessionOptionsFromParams(map[string]string{S3EndpointParam: "http://localhost:9000"})
+	require.NoError(t, err)
+	assert.Equal(t, defaultS3EndpointRegion, aws.StringValue(opts.Config.Region))
+	assert.Equal(t, "http://localhost:9000", aws.StringValue(opts.Config.Endpoint))
+	assert.True(t, aws.BoolValue(opts.Config.S3ForcePathStyle))
+
+	opts, err = s3SessionOptionsFromParams(map[string]string{S3EndpointParam: "http://localhost:9000", AWSRegionParam: "eu-west-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", aws.StringValue(opts.Config.Region))
+
+	_, err = s3SessionOptionsFromParams(map[string]string{S3EndpointParam: ""})
+	assert.Error(t, err)
+}
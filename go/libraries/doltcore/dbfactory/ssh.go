@@ -0,0 +1,130 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotestorage"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sshremote"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	// SSHCommandEnvVar is the environment variable holding the ssh command used to connect to the hosts of ssh remotes
+	SSHCommandEnvVar = "DOLT_SSH"
+
+	// SSHExecPathEnvVar is the environment variable holding the path of the dolt binary on the hosts of ssh remotes
+	SSHExecPathEnvVar = "DOLT_SSH_EXEC_PATH"
+
+	defaultSSHCommand  = "ssh"
+	defaultSSHExecPath = "dolt"
+)
+
+// SSHFactory is a DBFactory implementation for creating databases which are stored on a remote host, and accessed by
+// running `dolt transfer` on the host over ssh
+type SSHFactory struct {
+}
+
+// CreateDB creates a database backed by the chunk store served by `dolt transfer` on the host of the url
+func (fact SSHFactory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat, urlObj *url.URL, params map[string]string) (datas.Database, error) {
+	path, err := url.PathUnescape(urlObj.Path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if urlObj.Hostname() == "" || path == "" || path == "/" {
+		return nil, errors.New("invalid ssh url '" + urlObj.String() + "', expected ssh://[user@]host[:port]/path")
+	}
+
+	// a host or user starting with a dash would be read by ssh as an option
+	if strings.HasPrefix(urlObj.Hostname(), "-") || (urlObj.User != nil && strings.HasPrefix(urlObj.User.Username(), "-")) {
+		return nil, errors.New("invalid ssh url '" + urlObj.String() + "', the host and user may not start with '-'")
+	}
+
+	client, err := sshremote.StartCommand(sshTransferCommand(urlObj, path))
+
+	if err != nil {
+		return nil, err
+	}
+
+	cs, err := remotestorage.NewDoltChunkStore(ctx, nbf, "", path, urlObj.Host, client)
+
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	return datas.NewDatabase(sshChunkStore{cs.WithHTTPFetcher(client), client}), nil
+}
+
+// sshTransferCommand returns the command which runs `dolt transfer` for |path| on the host of |urlObj|
+func sshTransferCommand(urlObj *url.URL, path string) *exec.Cmd {
+	sshCmd := strings.Fields(os.Getenv(SSHCommandEnvVar))
+	if len(sshCmd) == 0 {
+		sshCmd = []string{defaultSSHCommand}
+	}
+
+	execPath := os.Getenv(SSHExecPathEnvVar)
+	if execPath == "" {
+		execPath = defaultSSHExecPath
+	}
+
+	args := sshCmd[1:]
+	if port := urlObj.Port(); port != "" {
+		args = append(args, "-p", port)
+	}
+
+	host := urlObj.Hostname()
+	if urlObj.User != nil {
+		host = urlObj.User.Username() + "@" + host
+	}
+
+	// the remote command is run by the shell of the remote user. The options end before the destination, so it can't
+	// be read as one.
+	args = append(args, "--", host, execPath+" transfer "+shellQuote(path))
+
+	cmd := exec.Command(sshCmd[0], args...)
+	cmd.Stderr = os.Stderr
+
+	return cmd
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// sshChunkStore is a remotestorage.DoltChunkStore which closes its connection to the remote host when it is closed
+type sshChunkStore struct {
+	*remotestorage.DoltChunkStore
+	client *sshremote.Client
+}
+
+func (cs sshChunkStore) Close() error {
+	err := cs.DoltChunkStore.Close()
+
+	if cerr := cs.client.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
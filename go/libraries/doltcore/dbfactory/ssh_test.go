@@ -0,0 +1,85 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+func TestSSHTransferCommand(t *testing.T) {
+	tests := []struct {
+		url      string
+		sshCmd   string
+		execPath string
+		expected []string
+	}{
+		{
+			"ssh://example.com/var/dbs/test",
+			"",
+			"",
+			[]string{"ssh", "--", "example.com", "dolt transfer '/var/dbs/test'"},
+		},
+		{
+			"ssh://user@example.com:2222/var/dbs/it's",
+			"ssh -i key -o BatchMode=yes",
+			"/usr/local/bin/dolt",
+			[]string{"ssh", "-i", "key", "-o", "BatchMode=yes", "-p", "2222", "--", "user@example.com", `/usr/local/bin/dolt transfer '/var/dbs/it'\''s'`},
+		},
+	}
+
+	defer os.Unsetenv(SSHCommandEnvVar)
+	defer os.Unsetenv(SSHExecPathEnvVar)
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			require.NoError(t, os.Setenv(SSHCommandEnvVar, test.sshCmd))
+			require.NoError(t, os.Setenv(SSHExecPathEnvVar, test.execPath))
+
+			urlObj, err := url.Parse(test.url)
+			require.NoError(t, err)
+			path, err := url.PathUnescape(urlObj.Path)
+			require.NoError(t, err)
+
+			cmd := sshTransferCommand(urlObj, path)
+			assert.Equal(t, test.expected, cmd.Args)
+		})
+	}
+}
+
+func TestCreateSSHDBInvalidURL(t *testing.T) {
+	ctx := context.Background()
+	for _, urlStr := range []string{"ssh://example.com", "ssh://example.com/", "ssh:///var/dbs/test"} {
+		_, err := CreateDB(ctx, types.Format_Default, urlStr, nil)
+		assert.Error(t, err, urlStr)
+	}
+}
+
+func TestCreateSSHDBRejectsOptions(t *testing.T) {
+	ctx := context.Background()
+	for _, urlStr := range []string{"ssh://-oProxyCommand=id/repo", "ssh://-oProxyCommand=id@example.com/repo"} {
+		_, err := CreateDB(ctx, types.Format_Default, urlStr, nil)
+		if assert.Error(t, err, urlStr) {
+			assert.Contains(t, err.Error(), "may not start with '-'", urlStr)
+		}
+	}
+}
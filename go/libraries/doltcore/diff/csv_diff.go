@@ -0,0 +1,168 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/rowconv"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/pipeline"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// CSVDiffSink writes each row diff as a CSV record holding the name of the table, the type of the change, and the
+// value of every column before and after the change. A header is written before the first record, with a from_ and a
+// to_ column for each column of the table. If |separate| is true a blank line is written before the header, so that
+// the records for several tables can be written to the same output. The sink expects the joined rows produced by a
+// RowDiffSource, not rows that have been split by a DiffSplitter.
+type CSVDiffSink struct {
+	wr          io.WriteCloser
+	csvWr       *csv.Writer
+	joiner      *rowconv.Joiner
+	tableName   string
+	separate    bool
+	rowsWritten int
+}
+
+// NewCSVDiffSink creates a CSVDiffSink for a diff pipeline.
+func NewCSVDiffSink(wr io.WriteCloser, joiner *rowconv.Joiner, tableName string, separate bool) (*CSVDiffSink, error) {
+	return &CSVDiffSink{wr: wr, csvWr: csv.NewWriter(wr), joiner: joiner, tableName: tableName, separate: separate}, nil
+}
+
+// GetSchema gets the schema of the joined rows that the CSVDiffSink processes.
+func (cds *CSVDiffSink) GetSchema() schema.Schema {
+	return cds.joiner.GetSchema()
+}
+
+// RowsWritten returns the number of row diffs written by the sink.
+func (cds *CSVDiffSink) RowsWritten() int {
+	return cds.rowsWritten
+}
+
+// ProcRowWithProps satisfies pipeline.SinkFunc; it writes a row diff as a CSV record.
+func (cds *CSVDiffSink) ProcRowWithProps(r row.Row, _ pipeline.ReadableMap) error {
+	if cds.rowsWritten == 0 {
+		err := cds.writeHeader()
+		if err != nil {
+			return err
+		}
+	}
+
+	diffType, from, to, err := splitJoinedRow(cds.joiner, r)
+	if err != nil {
+		return err
+	}
+
+	record := []string{cds.tableName, diffType}
+
+	record, err = appendCSVValues(record, cds.joiner.SchemaForName(From).GetAllCols(), from)
+	if err != nil {
+		return err
+	}
+
+	record, err = appendCSVValues(record, cds.joiner.SchemaForName(To).GetAllCols(), to)
+	if err != nil {
+		return err
+	}
+
+	cds.rowsWritten++
+	return cds.csvWr.Write(record)
+}
+
+func (cds *CSVDiffSink) writeHeader() error {
+	if cds.separate {
+		_, err := cds.wr.Write([]byte("\n"))
+		if err != nil {
+			return err
+		}
+	}
+
+	header := []string{"table", "diff_type"}
+	for _, name := range []string{From, To} {
+		prefix := name + "_"
+		header = append(header, prefixColNames(prefix, cds.joiner.SchemaForName(name).GetAllCols())...)
+	}
+
+	err := cds.csvWr.Write(header)
+	if err != nil {
+		return err
+	}
+
+	cds.csvWr.Flush()
+	return cds.csvWr.Error()
+}
+
+// Close should flush all writes, release resources being held
+func (cds *CSVDiffSink) Close() error {
+	if cds.wr != nil {
+		cds.csvWr.Flush()
+		errFl := cds.csvWr.Error()
+		errCl := cds.wr.Close()
+		cds.wr = nil
+
+		if errCl != nil {
+			return errCl
+		}
+
+		return errFl
+	} else {
+		return errors.New("Already closed.")
+	}
+}
+
+func prefixColNames(prefix string, cols *schema.ColCollection) []string {
+	names := cols.GetColumnNames()
+	for i := range names {
+		names[i] = prefix + names[i]
+	}
+
+	return names
+}
+
+// appendCSVValues appends the value of each column in |cols| from |r| to |record|. Missing rows and NULL values are
+// written as empty strings.
+func appendCSVValues(record []string, cols *schema.ColCollection, r row.Row) ([]string, error) {
+	err := cols.Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		if r == nil {
+			record = append(record, "")
+			return false, nil
+		}
+
+		val, ok := r.GetColVal(tag)
+		if !ok || types.IsNull(val) {
+			record = append(record, "")
+			return false, nil
+		}
+
+		str, err := col.TypeInfo.FormatValue(val)
+		if err != nil {
+			return true, err
+		}
+
+		if str == nil {
+			record = append(record, "")
+		} else {
+			record = append(record, *str)
+		}
+
+		return false, nil
+	})
+
+	return record, err
+}
@@ -0,0 +1,363 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/rowconv"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/pipeline"
+	"github.com/dolthub/dolt/go/libraries/utils/iohelp"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	addedDiffName    = "added"
+	removedDiffName  = "removed"
+	modifiedDiffName = "modified"
+)
+
+// TableSchemaDiff is a machine-readable description of the schema changes made to a table.
+type TableSchemaDiff struct {
+	Columns     []ColumnSchemaDiff     `json:"columns"`
+	Indexes     []IndexSchemaDiff      `json:"indexes"`
+	ForeignKeys []ForeignKeySchemaDiff `json:"foreign_keys"`
+	Checks      []CheckSchemaDiff      `json:"checks"`
+}
+
+type ColumnSchemaDiff struct {
+	DiffType string      `json:"diff_type"`
+	Tag      uint64      `json:"tag"`
+	From     *ColumnInfo `json:"from"`
+	To       *ColumnInfo `json:"to"`
+}
+
+type ColumnInfo struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	PrimaryKey bool   `json:"primary_key"`
+	Nullable   bool   `json:"nullable"`
+}
+
+type IndexSchemaDiff struct {
+	DiffType string     `json:"diff_type"`
+	From     *IndexInfo `json:"from"`
+	To       *IndexInfo `json:"to"`
+}
+
+type IndexInfo struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+}
+
+type CheckSchemaDiff struct {
+	DiffType string     `json:"diff_type"`
+	From     *CheckInfo `json:"from"`
+	To       *CheckInfo `json:"to"`
+}
+
+type CheckInfo struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	Enforced   bool   `json:"enforced"`
+}
+
+type ForeignKeySchemaDiff struct {
+	DiffType string          `json:"diff_type"`
+	From     *ForeignKeyInfo `json:"from"`
+	To       *ForeignKeyInfo `json:"to"`
+}
+
+type ForeignKeyInfo struct {
+	Name                 string `json:"name"`
+	Table                string `json:"table"`
+	TableIndex           string `json:"table_index"`
+	ReferencedTable      string `json:"referenced_table"`
+	ReferencedTableIndex string `json:"referenced_table_index"`
+	OnUpdate             string `json:"on_update"`
+	OnDelete             string `json:"on_delete"`
+}
+
+// NewTableSchemaDiff builds a TableSchemaDiff from the column, index, foreign key and check differences between |fromSch|
+// and |toSch| and the foreign keys of |td|. Only definitions that changed are included.
+func NewTableSchemaDiff(td TableDelta, fromSch, toSch schema.Schema) TableSchemaDiff {
+	sd := TableSchemaDiff{
+		Columns:     []ColumnSchemaDiff{},
+		Indexes:     []IndexSchemaDiff{},
+		ForeignKeys: []ForeignKeySchemaDiff{},
+		Checks:      []CheckSchemaDiff{},
+	}
+
+	colDiffs, tags := DiffSchColumns(fromSch, toSch)
+	for _, tag := range tags {
+		cd := colDiffs[tag]
+		if cd.DiffType == SchDiffNone {
+			continue
+		}
+
+		sd.Columns = append(sd.Columns, ColumnSchemaDiff{
+			DiffType: schemaChangeName(cd.DiffType),
+			Tag:      tag,
+			From:     newColumnInfo(cd.Old),
+			To:       newColumnInfo(cd.New),
+		})
+	}
+
+	for _, idxDiff := range DiffSchIndexes(fromSch, toSch) {
+		if idxDiff.DiffType == SchDiffNone {
+			continue
+		}
+
+		sd.Indexes = append(sd.Indexes, IndexSchemaDiff{
+			DiffType: schemaChangeName(idxDiff.DiffType),
+			From:     newIndexInfo(idxDiff.From),
+			To:       newIndexInfo(idxDiff.To),
+		})
+	}
+
+	for _, fkDiff := range DiffForeignKeys(td.FromFks, td.ToFks) {
+		if fkDiff.DiffType == SchDiffNone {
+			continue
+		}
+
+		fkd := ForeignKeySchemaDiff{DiffType: schemaChangeName(fkDiff.DiffType)}
+		if fkDiff.DiffType != SchDiffAdded {
+			fkd.From = &ForeignKeyInfo{fkDiff.From.Name, fkDiff.From.TableName, fkDiff.From.TableIndex, fkDiff.From.ReferencedTableName,
+				fkDiff.From.ReferencedTableIndex, fkDiff.From.OnUpdate.String(), fkDiff.From.OnDelete.String()}
+		}
+		if fkDiff.DiffType != SchDiffRemoved {
+			fkd.To = &ForeignKeyInfo{fkDiff.To.Name, fkDiff.To.TableName, fkDiff.To.TableIndex, fkDiff.To.ReferencedTableName,
+				fkDiff.To.ReferencedTableIndex, fkDiff.To.OnUpdate.String(), fkDiff.To.OnDelete.String()}
+		}
+
+		sd.ForeignKeys = append(sd.ForeignKeys, fkd)
+	}
+
+	for _, checkDiff := range DiffSchChecks(fromSch, toSch) {
+		if checkDiff.DiffType == SchDiffNone {
+			continue
+		}
+
+		sd.Checks = append(sd.Checks, CheckSchemaDiff{
+			DiffType: schemaChangeName(checkDiff.DiffType),
+			From:     newCheckInfo(checkDiff.From),
+			To:       newCheckInfo(checkDiff.To),
+		})
+	}
+
+	return sd
+}
+
+func newColumnInfo(col *schema.Column) *ColumnInfo {
+	if col == nil {
+		return nil
+	}
+
+	return &ColumnInfo{
+		Name:       col.Name,
+		Type:       col.TypeInfo.ToSqlType().String(),
+		PrimaryKey: col.IsPartOfPK,
+		Nullable:   col.IsNullable(),
+	}
+}
+
+func newIndexInfo(idx schema.Index) *IndexInfo {
+	if idx == nil {
+		return nil
+	}
+
+	return &IndexInfo{Name: idx.Name(), Columns: idx.ColumnNames(), Unique: idx.IsUnique()}
+}
+
+func newCheckInfo(check schema.Check) *CheckInfo {
+	if check == nil {
+		return nil
+	}
+
+	return &CheckInfo{Name: check.Name(), Expression: check.Expression(), Enforced: check.Enforced()}
+}
+
+func schemaChangeName(dt SchemaChangeType) string {
+	switch dt {
+	case SchDiffAdded:
+		return addedDiffName
+	case SchDiffRemoved:
+		return removedDiffName
+	case SchDiffModified:
+		return modifiedDiffName
+	default:
+		return "none"
+	}
+}
+
+type jsonRowDiff struct {
+	DiffType  string                    `json:"diff_type"`
+	Key       map[string]interface{}    `json:"key"`
+	From      map[string]interface{}    `json:"from"`
+	To        map[string]interface{}    `json:"to"`
+	JSONDiffs map[string][]JSONPathDiff `json:"json_diffs,omitempty"`
+}
+
+// JSONDiffSink writes each row diff as a JSON object holding the type of the change, the primary key of the row, and
+// the values of the row before and after the change. The objects are separated by commas so that the output of the
+// sink can be embedded in a JSON array. The sink expects the joined rows produced by a RowDiffSource, not rows that
+// have been split by a DiffSplitter.
+type JSONDiffSink struct {
+	wr          io.WriteCloser
+	joiner      *rowconv.Joiner
+	rowsWritten int
+}
+
+// NewJSONDiffSink creates a JSONDiffSink for a diff pipeline.
+func NewJSONDiffSink(wr io.WriteCloser, joiner *rowconv.Joiner) (*JSONDiffSink, error) {
+	return &JSONDiffSink{wr: wr, joiner: joiner}, nil
+}
+
+// GetSchema gets the schema of the joined rows that the JSONDiffSink processes.
+func (jds *JSONDiffSink) GetSchema() schema.Schema {
+	return jds.joiner.GetSchema()
+}
+
+// ProcRowWithProps satisfies pipeline.SinkFunc; it writes a row diff as a JSON object.
+func (jds *JSONDiffSink) ProcRowWithProps(r row.Row, _ pipeline.ReadableMap) error {
+	diffType, from, to, err := splitJoinedRow(jds.joiner, r)
+	if err != nil {
+		return err
+	}
+
+	rd := jsonRowDiff{DiffType: diffType}
+	keySch, keyRow := jds.joiner.SchemaForName(To), to
+	if to == nil {
+		keySch, keyRow = jds.joiner.SchemaForName(From), from
+	}
+
+	rd.Key, err = jsonValues(keySch.GetPKCols(), keyRow)
+	if err != nil {
+		return err
+	}
+
+	if from != nil {
+		rd.From, err = jsonValues(jds.joiner.SchemaForName(From).GetAllCols(), from)
+		if err != nil {
+			return err
+		}
+	}
+
+	if to != nil {
+		rd.To, err = jsonValues(jds.joiner.SchemaForName(To).GetAllCols(), to)
+		if err != nil {
+			return err
+		}
+	}
+
+	if from != nil && to != nil {
+		rd.JSONDiffs, err = diffJSONColumns(jds.joiner.SchemaForName(From), jds.joiner.SchemaForName(To), from, to)
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(rd)
+	if err != nil {
+		return err
+	}
+
+	if jds.rowsWritten != 0 {
+		data = append([]byte(","), data...)
+	}
+
+	jds.rowsWritten++
+	return iohelp.WriteAll(jds.wr, data)
+}
+
+// Close should release resources being held
+func (jds *JSONDiffSink) Close() error {
+	if jds.wr != nil {
+		err := jds.wr.Close()
+		if err != nil {
+			return err
+		}
+		jds.wr = nil
+		return nil
+	} else {
+		return errors.New("Already closed.")
+	}
+}
+
+// splitJoinedRow splits a row produced by a RowDiffSource into the row before and the row after the change, and
+// returns the type of the change. The row before the change is nil for added rows, and the row after the change is nil
+// for removed rows.
+func splitJoinedRow(joiner *rowconv.Joiner, r row.Row) (string, row.Row, row.Row, error) {
+	rows, err := joiner.Split(r)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	from, to := rows[From], rows[To]
+	switch {
+	case from == nil && to == nil:
+		return "", nil, nil, errors.New("row diff has neither an old nor a new value")
+	case from == nil:
+		return addedDiffName, nil, to, nil
+	case to == nil:
+		return removedDiffName, from, nil, nil
+	default:
+		return modifiedDiffName, from, to, nil
+	}
+}
+
+// jsonValues returns a map from column name to the value of the column in |r|, for each column in |cols|. Values are
+// converted to JSON numbers, strings and booleans the same way they are when exporting a table to JSON.
+func jsonValues(cols *schema.ColCollection, r row.Row) (map[string]interface{}, error) {
+	vals := make(map[string]interface{}, cols.Size())
+	err := cols.Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		val, ok := r.GetColVal(tag)
+		if !ok || types.IsNull(val) {
+			vals[col.Name] = nil
+			return false, nil
+		}
+
+		switch col.TypeInfo.GetTypeIdentifier() {
+		case typeinfo.BitTypeIdentifier,
+			typeinfo.BoolTypeIdentifier,
+			typeinfo.UintTypeIdentifier,
+			typeinfo.IntTypeIdentifier,
+			typeinfo.FloatTypeIdentifier:
+			vals[col.Name] = val
+		case typeinfo.JSONTypeIdentifier:
+			vals[col.Name] = json.RawMessage(val.(types.String))
+		default:
+			str, err := col.TypeInfo.FormatValue(val)
+			if err != nil {
+				return true, err
+			}
+			vals[col.Name] = *str
+		}
+
+		return false, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return vals, nil
+}
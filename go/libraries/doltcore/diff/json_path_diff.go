@@ -0,0 +1,279 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/rowconv"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/pipeline"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+var jsonPathIdentifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// JSONPathDiff is a difference between two JSON documents at a single path. The path is written the way MySQL's JSON
+// functions expect it, e.g. $.a.b[2]. From is absent for added values and To is absent for removed values.
+type JSONPathDiff struct {
+	DiffType string      `json:"diff_type"`
+	Path     string      `json:"path"`
+	From     interface{} `json:"from,omitempty"`
+	To       interface{} `json:"to,omitempty"`
+}
+
+// String returns a one line description of the diff.
+func (d JSONPathDiff) String() string {
+	switch d.DiffType {
+	case addedDiffName:
+		return fmt.Sprintf("+ %s: %s", d.Path, jsonString(d.To))
+	case removedDiffName:
+		return fmt.Sprintf("- %s: %s", d.Path, jsonString(d.From))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", d.Path, jsonString(d.From), jsonString(d.To))
+	}
+}
+
+// DiffJSON returns the differences between the JSON documents |from| and |to|, in path order. Objects are compared key
+// by key and arrays element by element. Any other change, including a change in the type of a value, is reported as a
+// modification of the whole value.
+func DiffJSON(from, to []byte) ([]JSONPathDiff, error) {
+	fromDoc, err := typeinfo.UnmarshalJSON(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toDoc, err := typeinfo.UnmarshalJSON(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []JSONPathDiff
+	diffJSONValues("$", fromDoc, toDoc, &diffs)
+	return diffs, nil
+}
+
+func diffJSONValues(path string, from, to interface{}, diffs *[]JSONPathDiff) {
+	switch fromVal := from.(type) {
+	case map[string]interface{}:
+		if toVal, ok := to.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(fromVal)+len(toVal))
+			for k := range fromVal {
+				keys = append(keys, k)
+			}
+			for k := range toVal {
+				if _, ok := fromVal[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				keyPath := path + jsonPathKey(k)
+				fromChild, inFrom := fromVal[k]
+				toChild, inTo := toVal[k]
+				switch {
+				case !inTo:
+					*diffs = append(*diffs, JSONPathDiff{DiffType: removedDiffName, Path: keyPath, From: jsonNullable(fromChild)})
+				case !inFrom:
+					*diffs = append(*diffs, JSONPathDiff{DiffType: addedDiffName, Path: keyPath, To: jsonNullable(toChild)})
+				default:
+					diffJSONValues(keyPath, fromChild, toChild, diffs)
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if toVal, ok := to.([]interface{}); ok {
+			for i := 0; i < len(fromVal) || i < len(toVal); i++ {
+				idxPath := path + "[" + strconv.Itoa(i) + "]"
+				switch {
+				case i >= len(toVal):
+					*diffs = append(*diffs, JSONPathDiff{DiffType: removedDiffName, Path: idxPath, From: jsonNullable(fromVal[i])})
+				case i >= len(fromVal):
+					*diffs = append(*diffs, JSONPathDiff{DiffType: addedDiffName, Path: idxPath, To: jsonNullable(toVal[i])})
+				default:
+					diffJSONValues(idxPath, fromVal[i], toVal[i], diffs)
+				}
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*diffs = append(*diffs, JSONPathDiff{DiffType: modifiedDiffName, Path: path, From: jsonNullable(from), To: jsonNullable(to)})
+	}
+}
+
+func jsonPathKey(k string) string {
+	if jsonPathIdentifierRegex.MatchString(k) {
+		return "." + k
+	}
+	return "." + strconv.Quote(k)
+}
+
+// jsonNullable keeps JSON nulls from being omitted from a JSONPathDiff
+func jsonNullable(v interface{}) interface{} {
+	if v == nil {
+		return json.RawMessage("null")
+	}
+	return v
+}
+
+func jsonString(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// diffJSONColumns returns the path level differences of each of the JSON columns that changed between |from| and |to|,
+// keyed by column name. Columns that are only in one of the schemas, or that are null in either row, are skipped.
+func diffJSONColumns(fromSch, toSch schema.Schema, from, to row.Row) (map[string][]JSONPathDiff, error) {
+	var colDiffs map[string][]JSONPathDiff
+	err := toSch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		if col.TypeInfo.GetTypeIdentifier() != typeinfo.JSONTypeIdentifier {
+			return false, nil
+		}
+
+		fromCol, ok := fromSch.GetAllCols().GetByTag(tag)
+		if !ok || fromCol.TypeInfo.GetTypeIdentifier() != typeinfo.JSONTypeIdentifier {
+			return false, nil
+		}
+
+		fromVal, _ := from.GetColVal(tag)
+		toVal, _ := to.GetColVal(tag)
+		if types.IsNull(fromVal) || types.IsNull(toVal) || fromVal.Equals(toVal) {
+			return false, nil
+		}
+
+		diffs, err := DiffJSON([]byte(fromVal.(types.String)), []byte(toVal.(types.String)))
+		if err != nil {
+			return true, err
+		}
+
+		if len(diffs) > 0 {
+			if colDiffs == nil {
+				colDiffs = make(map[string][]JSONPathDiff)
+			}
+			colDiffs[col.Name] = diffs
+		}
+
+		return false, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return colDiffs, nil
+}
+
+// JSONRowPathDiffs are the path level differences of a JSON column in a modified row.
+type JSONRowPathDiffs struct {
+	// Key describes the primary key of the row, e.g. "pk=1"
+	Key    string
+	Column string
+	Diffs  []JSONPathDiff
+}
+
+// JSONPathDiffCollector is a pipeline transform that collects the path level differences of the JSON columns of the
+// modified rows in a diff, and passes the rows on unchanged. It expects the joined rows produced by a RowDiffSource.
+type JSONPathDiffCollector struct {
+	joiner *rowconv.Joiner
+	mu     *sync.Mutex
+	diffs  []JSONRowPathDiffs
+}
+
+// NewJSONPathDiffCollector creates a JSONPathDiffCollector, or returns nil if |toSch| has no JSON columns.
+func NewJSONPathDiffCollector(joiner *rowconv.Joiner, toSch schema.Schema) *JSONPathDiffCollector {
+	hasJSON := false
+	_ = toSch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		hasJSON = col.TypeInfo.GetTypeIdentifier() == typeinfo.JSONTypeIdentifier
+		return hasJSON, nil
+	})
+
+	if !hasJSON {
+		return nil
+	}
+
+	return &JSONPathDiffCollector{joiner: joiner, mu: &sync.Mutex{}}
+}
+
+// CollectDiffs is a pipeline.TransformRowFunc which records the JSON differences of |inRow|.
+func (c *JSONPathDiffCollector) CollectDiffs(inRow row.Row, props pipeline.ReadableMap) ([]*pipeline.TransformedRowResult, string) {
+	diffType, from, to, err := splitJoinedRow(c.joiner, inRow)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	if diffType == modifiedDiffName {
+		toSch := c.joiner.SchemaForName(To)
+		colDiffs, err := diffJSONColumns(c.joiner.SchemaForName(From), toSch, from, to)
+		if err != nil {
+			return nil, err.Error()
+		}
+
+		if len(colDiffs) > 0 {
+			key := rowKeyString(toSch, to)
+			cols := make([]string, 0, len(colDiffs))
+			for col := range colDiffs {
+				cols = append(cols, col)
+			}
+			sort.Strings(cols)
+
+			c.mu.Lock()
+			for _, col := range cols {
+				c.diffs = append(c.diffs, JSONRowPathDiffs{Key: key, Column: col, Diffs: colDiffs[col]})
+			}
+			c.mu.Unlock()
+		}
+	}
+
+	return []*pipeline.TransformedRowResult{{RowData: inRow}}, ""
+}
+
+// Diffs returns the differences collected so far.
+func (c *JSONPathDiffCollector) Diffs() []JSONRowPathDiffs {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.diffs
+}
+
+func rowKeyString(sch schema.Schema, r row.Row) string {
+	var parts []string
+	_ = sch.GetPKCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		val, _ := r.GetColVal(tag)
+		str := "NULL"
+		if !types.IsNull(val) {
+			if s, err := col.TypeInfo.FormatValue(val); err == nil && s != nil {
+				str = *s
+			}
+		}
+		parts = append(parts, col.Name+"="+str)
+		return false, nil
+	})
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		expected []string
+	}{
+		{
+			name:     "equal",
+			from:     `{"a":1,"b":[1,2]}`,
+			to:       `{"b":[1,2],"a":1}`,
+			expected: nil,
+		},
+		{
+			name:     "modified scalar",
+			from:     `{"a":{"b":1}}`,
+			to:       `{"a":{"b":"1"}}`,
+			expected: []string{`~ $.a.b: 1 -> "1"`},
+		},
+		{
+			name:     "added and removed keys",
+			from:     `{"a":1,"b":2}`,
+			to:       `{"b":2,"c":null,"d e":true}`,
+			expected: []string{`- $.a: 1`, `+ $.c: null`, `+ $."d e": true`},
+		},
+		{
+			name:     "arrays",
+			from:     `[1,[2,3],4]`,
+			to:       `[1,[2,5]]`,
+			expected: []string{`~ $[1][1]: 3 -> 5`, `- $[2]: 4`},
+		},
+		{
+			name:     "changed type",
+			from:     `{"a":[1]}`,
+			to:       `{"a":{"0":1}}`,
+			expected: []string{`~ $.a: [1] -> {"0":1}`},
+		},
+		{
+			name:     "top level scalar",
+			from:     `1`,
+			to:       `null`,
+			expected: []string{`~ $: 1 -> null`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diffs, err := DiffJSON([]byte(test.from), []byte(test.to))
+			require.NoError(t, err)
+
+			var actual []string
+			for _, d := range diffs {
+				actual = append(actual, d.String())
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+
+	_, err := DiffJSON([]byte(`{`), []byte(`{}`))
+	assert.Error(t, err)
+}
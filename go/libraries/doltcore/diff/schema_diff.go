@@ -146,6 +146,38 @@ func DiffSchIndexes(fromSch, toSch schema.Schema) (diffs []IndexDifference) {
 	return diffs
 }
 
+type CheckDifference struct {
+	DiffType SchemaChangeType
+	From     schema.Check
+	To       schema.Check
+}
+
+// DiffSchChecks matches two sets of Checks based on their names.
+// It returns matched and unmatched Checks as a slice of CheckDifferences.
+func DiffSchChecks(fromSch, toSch schema.Schema) (diffs []CheckDifference) {
+	for _, fromCheck := range fromSch.Checks().AllChecks() {
+		toCheck, ok := toSch.Checks().GetByNameCaseInsensitive(fromCheck.Name())
+		if !ok {
+			diffs = append(diffs, CheckDifference{DiffType: SchDiffRemoved, From: fromCheck})
+			continue
+		}
+
+		d := CheckDifference{DiffType: SchDiffModified, From: fromCheck, To: toCheck}
+		if fromCheck.Expression() == toCheck.Expression() && fromCheck.Enforced() == toCheck.Enforced() {
+			d.DiffType = SchDiffNone
+		}
+		diffs = append(diffs, d)
+	}
+
+	for _, toCheck := range toSch.Checks().AllChecks() {
+		if _, ok := fromSch.Checks().GetByNameCaseInsensitive(toCheck.Name()); !ok {
+			diffs = append(diffs, CheckDifference{DiffType: SchDiffAdded, To: toCheck})
+		}
+	}
+
+	return diffs
+}
+
 type ForeignKeyDifference struct {
 	DiffType SchemaChangeType
 	From     doltdb.ForeignKey
@@ -158,6 +158,10 @@ func (c *Commit) getParent(ctx context.Context, idx int) (*types.Struct, error)
 	if err != nil {
 		return nil, err
 	}
+	if targVal == nil {
+		// the parents of the oldest commits of a shallow clone are missing
+		return nil, ErrHashNotFound
+	}
 	parentSt := targVal.(types.Struct)
 	return &parentSt, nil
 }
@@ -62,14 +62,15 @@ var InMemDoltDB = "mem://"
 // Additionally the noms codebase uses panics in a way that is non idiomatic and I've opted to recover and return
 // errors in many cases.
 type DoltDB struct {
-	db datas.Database
+	db        datas.Database
+	listeners *headUpdateListeners
 }
 
 // DoltDBFromCS creates a DoltDB from a noms chunks.ChunkStore
 func DoltDBFromCS(cs chunks.ChunkStore) *DoltDB {
 	db := datas.NewDatabase(cs)
 
-	return &DoltDB{db}
+	return &DoltDB{db: db, listeners: newHeadUpdateListeners()}
 }
 
 // LoadDoltDB will acquire a reference to the underlying noms db.  If the Location is InMemDoltDB then a reference
@@ -96,13 +97,24 @@ func LoadDoltDBWithParams(ctx context.Context, nbf *types.NomsBinFormat, urlStr
 		return nil, err
 	}
 
-	return &DoltDB{db}, nil
+	return &DoltDB{db: db, listeners: newHeadUpdateListeners()}, nil
 }
 
 func (ddb *DoltDB) CSMetricsSummary() string {
 	return datas.GetCSStatSummaryForDB(ddb.db)
 }
 
+// CSMetrics returns the statistics gathered by the chunk store of the database. The type is implementation dependent,
+// and may be nil.
+func (ddb *DoltDB) CSMetrics() interface{} {
+	return ddb.db.Stats()
+}
+
+// ChunkStore returns the chunk store the database reads from and writes to.
+func (ddb *DoltDB) ChunkStore() chunks.ChunkStore {
+	return datas.ChunkStoreFromDatabase(ddb.db)
+}
+
 // WriteEmptyRepo will create initialize the given db with a master branch which points to a commit which has valid
 // metadata for the creation commit, and an empty RootValue.
 func (ddb *DoltDB) WriteEmptyRepo(ctx context.Context, name, email string) error {
@@ -338,6 +350,9 @@ func (ddb *DoltDB) Resolve(ctx context.Context, cs *CommitSpec, cwb ref.DoltRef)
 			}
 		}
 	case headCommitSpec:
+		if cwb == nil {
+			return nil, ErrDetachedHead
+		}
 		commitSt, err = getCommitStForRefStr(ctx, ddb.db, cwb.String())
 	default:
 		panic("unrecognized commit spec csType: " + cs.csType)
@@ -358,6 +373,10 @@ func (ddb *DoltDB) Resolve(ctx context.Context, cs *CommitSpec, cwb ref.DoltRef)
 
 // ResolveRef takes a DoltRef and returns a Commit, or an error if the commit cannot be found.
 func (ddb *DoltDB) ResolveRef(ctx context.Context, ref ref.DoltRef) (*Commit, error) {
+	if ref == nil {
+		return nil, ErrDetachedHead
+	}
+
 	commitSt, err := getCommitStForRefStr(ctx, ddb.db, ref.String())
 	if err != nil {
 		return nil, err
@@ -466,9 +485,14 @@ func (ddb *DoltDB) FastForward(ctx context.Context, branch ref.DoltRef, commit *
 		return err
 	}
 
-	_, err = ddb.db.FastForward(ctx, ds, rf)
+	newDs, err := ddb.db.FastForward(ctx, ds, rf)
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	ddb.headUpdated(ctx, branch, ds, newDs)
+	return nil
 }
 
 // CanFastForward returns whether the given branch can be fast-forwarded to the commit given.
@@ -505,8 +529,14 @@ func (ddb *DoltDB) SetHead(ctx context.Context, ref ref.DoltRef, stRef types.Ref
 		return err
 	}
 
-	_, err = ddb.db.SetHead(ctx, ds, stRef)
-	return err
+	newDs, err := ddb.db.SetHead(ctx, ds, stRef)
+
+	if err != nil {
+		return err
+	}
+
+	ddb.headUpdated(ctx, ref, ds, newDs)
+	return nil
 }
 
 // CommitWithParentSpecs commits the value hash given to the branch given, using the list of parent hashes given. Returns an
@@ -641,6 +671,7 @@ func (ddb *DoltDB) CommitWithParentCommits(ctx context.Context, valHash hash.Has
 		return nil, err
 	}
 
+	prevDs := ds
 	ds, err = ddb.db.Commit(ctx, ds, val, commitOpts)
 
 	if err != nil {
@@ -653,6 +684,8 @@ func (ddb *DoltDB) CommitWithParentCommits(ctx context.Context, valHash hash.Has
 		return nil, errors.New("commit has no head but commit succeeded (How?!?!?)")
 	}
 
+	ddb.headUpdated(ctx, dref, prevDs, ds)
+
 	return NewCommit(ddb.db, commitSt), nil
 }
 
@@ -801,6 +834,13 @@ func (ddb *DoltDB) GetTags(ctx context.Context) ([]ref.DoltRef, error) {
 	return ddb.GetRefsOfType(ctx, tagsRefFilter)
 }
 
+var stashRefFilter = map[ref.RefType]struct{}{ref.StashRefType: {}}
+
+// GetStashes returns a list of all stash refs in the database.
+func (ddb *DoltDB) GetStashes(ctx context.Context) ([]ref.DoltRef, error) {
+	return ddb.GetRefsOfType(ctx, stashRefFilter)
+}
+
 // GetRefs returns a list of all refs in the database.
 func (ddb *DoltDB) GetRefs(ctx context.Context) ([]ref.DoltRef, error) {
 	return ddb.GetRefsOfType(ctx, ref.RefTypes)
@@ -854,9 +894,14 @@ func (ddb *DoltDB) NewBranchAtCommit(ctx context.Context, dref ref.DoltRef, comm
 		return err
 	}
 
-	_, err = ddb.db.SetHead(ctx, ds, rf)
+	newDs, err := ddb.db.SetHead(ctx, ds, rf)
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	ddb.headUpdated(ctx, dref, ds, newDs)
+	return nil
 }
 
 // DeleteBranch deletes the branch given, returning an error if it doesn't exist.
@@ -875,8 +920,14 @@ func (ddb *DoltDB) deleteRef(ctx context.Context, dref ref.DoltRef) error {
 		return ErrBranchNotFound
 	}
 
-	_, err = ddb.db.Delete(ctx, ds)
-	return err
+	newDs, err := ddb.db.Delete(ctx, ds)
+
+	if err != nil {
+		return err
+	}
+
+	ddb.headUpdated(ctx, dref, ds, newDs)
+	return nil
 }
 
 // NewTagAtCommit create a new tag at the commit given.
@@ -956,6 +1007,34 @@ func (ddb *DoltDB) DeleteWorkspace(ctx context.Context, workRef ref.DoltRef) err
 	return err
 }
 
+// NewStashAtCommit creates a new stash ref pointing at the commit given.
+func (ddb *DoltDB) NewStashAtCommit(ctx context.Context, stashRef ref.DoltRef, c *Commit) error {
+	ds, err := ddb.db.GetDataset(ctx, stashRef.String())
+	if err != nil {
+		return err
+	}
+
+	r, err := types.NewRef(c.commitSt, ddb.Format())
+	if err != nil {
+		return err
+	}
+
+	_, err = ddb.db.SetHead(ctx, ds, r)
+
+	return err
+}
+
+// DeleteStash deletes the stash ref given, returning an error if it doesn't exist.
+func (ddb *DoltDB) DeleteStash(ctx context.Context, stashRef ref.DoltRef) error {
+	err := ddb.deleteRef(ctx, stashRef)
+
+	if err == ErrBranchNotFound {
+		return ErrStashNotFound
+	}
+
+	return err
+}
+
 // GC performs garbage collection on this ddb. Values passed in |uncommitedVals| will be temporarily saved during gc.
 func (ddb *DoltDB) GC(ctx context.Context, uncommitedVals ...hash.Hash) error {
 	collector, ok := ddb.db.(datas.GarbageCollector)
@@ -1106,6 +1185,24 @@ func (ddb *DoltDB) PullChunks(ctx context.Context, tempDir string, srcDB *DoltDB
 	}
 }
 
+// ShallowPullChunks pulls the chunks of |commits|, and of their ancestors up to |depth| commits away from them, from the
+// source database given. A |depth| of 1 pulls only |commits|. It returns the commits which are the parents of the
+// pulled commits, but were not pulled.
+func (ddb *DoltDB) ShallowPullChunks(ctx context.Context, srcDB *DoltDB, commits []*Commit, depth int, progChan chan datas.PullProgress) (hash.HashSet, error) {
+	stRefs := make([]types.Ref, len(commits))
+	for i, cm := range commits {
+		stRef, err := cm.GetStRef()
+
+		if err != nil {
+			return nil, err
+		}
+
+		stRefs[i] = stRef
+	}
+
+	return datas.ShallowPull(ctx, srcDB.db, ddb.db, stRefs, depth, progChan)
+}
+
 func (ddb *DoltDB) Clone(ctx context.Context, destDB *DoltDB, eventCh chan<- datas.TableFileEvent) error {
 	return datas.Clone(ctx, ddb.db, destDB.db, eventCh)
 }
@@ -32,8 +32,10 @@ var ErrFoundHashNotACommit = errors.New("the value retrieved for this hash is no
 
 var ErrHashNotFound = errors.New("could not find a value for this hash")
 var ErrBranchNotFound = errors.New("branch not found")
+var ErrDetachedHead = errors.New("HEAD is not a branch")
 var ErrTagNotFound = errors.New("tag not found")
 var ErrWorkspaceNotFound = errors.New("workspace not found")
+var ErrStashNotFound = errors.New("stash not found")
 var ErrTableNotFound = errors.New("table not found")
 var ErrTableExists = errors.New("table already exists")
 var ErrAlreadyOnBranch = errors.New("Already on branch")
@@ -0,0 +1,83 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/store/datas"
+)
+
+// HeadUpdateListener is called after the head of a branch has been moved by this DoltDB. |prev| is nil if the branch
+// was created, and |curr| is nil if the branch was deleted. Listeners are called synchronously by the goroutine which
+// moved the head, so they should return quickly.
+type HeadUpdateListener func(ctx context.Context, branch ref.DoltRef, prev, curr *Commit)
+
+type headUpdateListeners struct {
+	mu        *sync.RWMutex
+	listeners []HeadUpdateListener
+}
+
+func newHeadUpdateListeners() *headUpdateListeners {
+	return &headUpdateListeners{mu: &sync.RWMutex{}}
+}
+
+// AddHeadUpdateListener registers a listener which is called each time this DoltDB moves the head of a branch. Heads
+// moved by other processes sharing the same storage are not seen.
+func (ddb *DoltDB) AddHeadUpdateListener(l HeadUpdateListener) {
+	ddb.listeners.mu.Lock()
+	defer ddb.listeners.mu.Unlock()
+
+	ddb.listeners.listeners = append(ddb.listeners.listeners, l)
+}
+
+// headUpdated notifies the listeners of the DoltDB that the head of |dref| was moved from the head of |prevDs| to the
+// head of |currDs|. Refs which aren't branches are ignored.
+func (ddb *DoltDB) headUpdated(ctx context.Context, dref ref.DoltRef, prevDs, currDs datas.Dataset) {
+	if dref.GetType() != ref.BranchRefType {
+		return
+	}
+
+	ddb.listeners.mu.RLock()
+	listeners := ddb.listeners.listeners
+	ddb.listeners.mu.RUnlock()
+
+	if len(listeners) == 0 {
+		return
+	}
+
+	prev := ddb.datasetHeadCommit(prevDs)
+	curr := ddb.datasetHeadCommit(currDs)
+
+	if prev == nil && curr == nil {
+		return
+	}
+
+	for _, l := range listeners {
+		l(ctx, dref, prev, curr)
+	}
+}
+
+func (ddb *DoltDB) datasetHeadCommit(ds datas.Dataset) *Commit {
+	st, ok := ds.MaybeHead()
+
+	if !ok {
+		return nil
+	}
+
+	return NewCommit(ddb.db, st)
+}
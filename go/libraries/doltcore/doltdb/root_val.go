@@ -528,6 +528,10 @@ func (root *RootValue) TablesInConflict(ctx context.Context) ([]string, error) {
 			return false, err
 		} else if has {
 			names = append(names, string(key.(types.String)))
+		} else if has, err := tbl.HasSchemaConflicts(); err != nil {
+			return false, err
+		} else if has {
+			names = append(names, string(key.(types.String)))
 		}
 
 		return false, nil
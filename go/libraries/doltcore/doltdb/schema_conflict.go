@@ -0,0 +1,165 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+
+	"github.com/dolthub/dolt/go/store/marshal"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	schemaConflictsStructName = "schema_conflicts"
+
+	schConflictAncestorKey    = "ancestor"
+	schConflictTheirsKey      = "theirs"
+	schConflictForeignKeysKey = "foreign_keys"
+)
+
+// ForeignKeyConflict is a pair of foreign keys, one from each side of a merge, which could not be merged automatically.
+type ForeignKeyConflict struct {
+	Kind   uint8      `noms:"kind"`
+	Ours   ForeignKey `noms:"ours"`
+	Theirs ForeignKey `noms:"theirs"`
+}
+
+// SchemaConflicts is the state persisted on a table whose schema could not be merged automatically. Ancestor and Theirs
+// are the versions of the table from the merge base and the merged in commit, and are needed to finish merging the
+// table's rows once the conflicts are resolved. They are nil when only the table's foreign keys are in conflict.
+type SchemaConflicts struct {
+	Ancestor    *Table
+	Theirs      *Table
+	ForeignKeys []ForeignKeyConflict
+}
+
+// SetSchemaConflicts returns a copy of this table with the given schema conflicts recorded.
+func (t *Table) SetSchemaConflicts(ctx context.Context, sc SchemaConflicts) (*Table, error) {
+	sd := types.StructData{}
+
+	if sc.Ancestor != nil && sc.Theirs != nil {
+		ancRef, err := WriteValAndGetRef(ctx, t.vrw, sc.Ancestor.tableStruct)
+		if err != nil {
+			return nil, err
+		}
+
+		theirRef, err := WriteValAndGetRef(ctx, t.vrw, sc.Theirs.tableStruct)
+		if err != nil {
+			return nil, err
+		}
+
+		sd[schConflictAncestorKey] = ancRef
+		sd[schConflictTheirsKey] = theirRef
+	}
+
+	if len(sc.ForeignKeys) > 0 {
+		fksVal, err := marshal.Marshal(ctx, t.vrw, sc.ForeignKeys)
+		if err != nil {
+			return nil, err
+		}
+
+		sd[schConflictForeignKeysKey] = fksVal
+	}
+
+	st, err := types.NewStruct(t.vrw.Format(), schemaConflictsStructName, sd)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedSt, err := t.tableStruct.Set(schemaConflictsKey, st)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Table{t.vrw, updatedSt}, nil
+}
+
+// GetSchemaConflicts returns the schema conflicts recorded on this table, or ErrNoConflicts if there are none.
+func (t *Table) GetSchemaConflicts(ctx context.Context) (SchemaConflicts, error) {
+	val, ok, err := t.tableStruct.MaybeGet(schemaConflictsKey)
+
+	if err != nil {
+		return SchemaConflicts{}, err
+	}
+
+	if !ok {
+		return SchemaConflicts{}, ErrNoConflicts
+	}
+
+	st := val.(types.Struct)
+
+	var sc SchemaConflicts
+	if sc.Ancestor, err = t.maybeGetTableFromRef(ctx, st, schConflictAncestorKey); err != nil {
+		return SchemaConflicts{}, err
+	}
+
+	if sc.Theirs, err = t.maybeGetTableFromRef(ctx, st, schConflictTheirsKey); err != nil {
+		return SchemaConflicts{}, err
+	}
+
+	fksVal, ok, err := st.MaybeGet(schConflictForeignKeysKey)
+
+	if err != nil {
+		return SchemaConflicts{}, err
+	}
+
+	if ok {
+		err = marshal.Unmarshal(ctx, t.vrw.Format(), fksVal, &sc.ForeignKeys)
+
+		if err != nil {
+			return SchemaConflicts{}, err
+		}
+	}
+
+	return sc, nil
+}
+
+// HasSchemaConflicts returns whether this table has unresolved schema conflicts from a merge.
+func (t *Table) HasSchemaConflicts() (bool, error) {
+	if t == nil {
+		return false, nil
+	}
+
+	_, ok, err := t.tableStruct.MaybeGet(schemaConflictsKey)
+
+	return ok, err
+}
+
+// ClearSchemaConflicts returns a copy of this table without any schema conflicts.
+func (t *Table) ClearSchemaConflicts() (*Table, error) {
+	tSt, err := t.tableStruct.Delete(schemaConflictsKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Table{t.vrw, tSt}, nil
+}
+
+func (t *Table) maybeGetTableFromRef(ctx context.Context, st types.Struct, key string) (*Table, error) {
+	val, ok, err := st.MaybeGet(key)
+
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	tblVal, err := val.(types.Ref).TargetValue(ctx, t.vrw)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Table{t.vrw, tblVal.(types.Struct)}, nil
+}
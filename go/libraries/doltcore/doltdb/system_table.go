@@ -120,12 +120,14 @@ func GetAllTableNames(ctx context.Context, root *RootValue) ([]string, error) {
 var writeableSystemTables = []string{
 	DoltQueryCatalogTableName,
 	SchemasTableName,
+	MergePoliciesTableName,
 }
 
 var persistedSystemTables = []string{
 	DocTableName,
 	DoltQueryCatalogTableName,
 	SchemasTableName,
+	MergePoliciesTableName,
 }
 
 var generatedSystemTables = []string{
@@ -135,6 +137,7 @@ var generatedSystemTables = []string{
 	CommitsTableName,
 	CommitAncestorsTableName,
 	StatusTableName,
+	SchemaConflictsTableName,
 }
 
 var generatedSystemTablePrefixes = []string{
@@ -142,6 +145,7 @@ var generatedSystemTablePrefixes = []string{
 	DoltCommitDiffTablePrefix,
 	DoltHistoryTablePrefix,
 	DoltConfTablePrefix,
+	DoltBlameTablePrefix,
 }
 
 const (
@@ -189,6 +193,20 @@ const (
 	SchemasTablesIndexName = "fragment_name"
 )
 
+const (
+	// MergePoliciesTableName is the name of the table containing the merge policies of the other tables in the database
+	MergePoliciesTableName = "dolt_merge_policies"
+
+	// MergePoliciesTableNameCol is the name of the primary key column, containing the name of the table the policy applies to
+	MergePoliciesTableNameCol = "table_name"
+
+	// MergePoliciesStrategyCol is the name of the column containing the strategy used to resolve conflicts in the table
+	MergePoliciesStrategyCol = "strategy"
+
+	// MergePoliciesExpressionCol is the name of the column containing the expression used by the sql strategy
+	MergePoliciesExpressionCol = "expression"
+)
+
 const (
 	// DoltHistoryTablePrefix is the prefix assigned to all the generated history tables
 	DoltHistoryTablePrefix = "dolt_history_"
@@ -198,6 +216,8 @@ const (
 	DoltCommitDiffTablePrefix = "dolt_commit_diff_"
 	// DoltConfTablePrefix is the prefix assigned to all the generated conflict tables
 	DoltConfTablePrefix = "dolt_conflicts_"
+	// DoltBlameTablePrefix is the prefix assigned to all the generated blame tables
+	DoltBlameTablePrefix = "dolt_blame_"
 )
 
 const (
@@ -218,4 +238,7 @@ const (
 
 	// StatusTableName is the status system table name.
 	StatusTableName = "dolt_status"
+
+	// SchemaConflictsTableName is the schema conflicts system table name
+	SchemaConflictsTableName = "dolt_schema_conflicts"
 )
@@ -33,6 +33,7 @@ const (
 	tableRowsKey       = "rows"
 	conflictsKey       = "conflicts"
 	conflictSchemasKey = "conflict_schemas"
+	schemaConflictsKey = "schema_conflicts"
 	indexesKey         = "indexes"
 	autoIncrementKey   = "auto_increment"
 
@@ -50,6 +50,16 @@ func (q *q) PopPending() *c {
 	return c
 }
 
+// AddParentIfUnseen adds the parent |id| of a commit like AddPendingIfUnseen. The parents of the oldest commits of a
+// shallow clone are missing, and the history ends there.
+func (q *q) AddParentIfUnseen(ctx context.Context, ddb *doltdb.DoltDB, id hash.Hash) error {
+	err := q.AddPendingIfUnseen(ctx, ddb, id)
+	if err == doltdb.ErrHashNotFound {
+		return nil
+	}
+	return err
+}
+
 func (q *q) AddPendingIfUnseen(ctx context.Context, ddb *doltdb.DoltDB, id hash.Hash) error {
 	c, err := q.Get(ctx, ddb, id)
 	if err != nil {
@@ -141,14 +151,14 @@ func newQueue() *q {
 // GetDotDotRevisions returns the commits reachable from commit at hash
 // `includedHead` that are not reachable from hash `excludedHead`.
 // `includedHead` and `excludedHead` must be commits in `ddb`. Returns up
-// to `num` commits, in reverse topological order starting at `includedHead`,
+// to `num` commits (if `num` <= 0 then all of them), in reverse topological order starting at `includedHead`,
 // with tie breaking based on the height of commit graph between
 // concurrent commits --- higher commits appear first. Remaining
 // ties are broken by timestamp; newer commits appear first.
 //
 // Roughly mimics `git log master..feature`.
 func GetDotDotRevisions(ctx context.Context, includedDB *doltdb.DoltDB, includedHead hash.Hash, excludedDB *doltdb.DoltDB, excludedHead hash.Hash, num int) ([]*doltdb.Commit, error) {
-	commitList := make([]*doltdb.Commit, 0, num)
+	var commitList []*doltdb.Commit
 	q := newQueue()
 	if err := q.SetInvisible(ctx, excludedDB, excludedHead); err != nil {
 		return nil, err
@@ -171,7 +181,7 @@ func GetDotDotRevisions(ctx context.Context, includedDB *doltdb.DoltDB, included
 					return nil, err
 				}
 			}
-			if err := q.AddPendingIfUnseen(ctx, nextC.ddb, parentID); err != nil {
+			if err := q.AddParentIfUnseen(ctx, nextC.ddb, parentID); err != nil {
 				return nil, err
 			}
 		}
@@ -230,7 +240,7 @@ func (i *commiterator) Next(ctx context.Context) (hash.Hash, *doltdb.Commit, err
 		}
 
 		for _, parentID := range parents {
-			if err := i.q.AddPendingIfUnseen(ctx, nextC.ddb, parentID); err != nil {
+			if err := i.q.AddParentIfUnseen(ctx, nextC.ddb, parentID); err != nil {
 				return hash.Hash{}, nil, err
 			}
 		}
@@ -17,6 +17,7 @@ package actions
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
@@ -144,3 +145,64 @@ func FetchTag(ctx context.Context, dEnv *env.DoltEnv, srcDB, destDB *doltdb.Dolt
 func Clone(ctx context.Context, srcDB, destDB *doltdb.DoltDB, eventCh chan<- datas.TableFileEvent) error {
 	return srcDB.Clone(ctx, destDB, eventCh)
 }
+
+// ShallowClone pulls the data of the |depth| most recent commits of the branches |branches| from a remote source
+// database to the database of |dEnv|, and creates the branches in it. If |branches| is empty every branch is cloned.
+// If |lazy| is true, the data which was not pulled is fetched from the remote named |remoteName| when it is read.
+func ShallowClone(ctx context.Context, srcDB *doltdb.DoltDB, dEnv *env.DoltEnv, remoteName string, branches []string, depth int, lazy bool, progChan chan datas.PullProgress) error {
+	var branchRefs []ref.DoltRef
+	if len(branches) == 0 {
+		var err error
+		branchRefs, err = srcDB.GetBranches(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		if len(branchRefs) == 0 {
+			return datas.ErrNoData
+		}
+	} else {
+		for _, branch := range branches {
+			branchRefs = append(branchRefs, ref.NewBranchRef(branch))
+		}
+	}
+
+	commits := make([]*doltdb.Commit, len(branchRefs))
+	for i, branchRef := range branchRefs {
+		cm, err := srcDB.ResolveRef(ctx, branchRef)
+
+		if err != nil {
+			return fmt.Errorf("could not resolve branch '%s': %w", branchRef.GetPath(), err)
+		}
+
+		commits[i] = cm
+	}
+
+	boundary, err := dEnv.DoltDB.ShallowPullChunks(ctx, srcDB, commits, depth, progChan)
+
+	if err != nil {
+		return err
+	}
+
+	state := env.NewShallowState("", boundary)
+	if lazy {
+		state.Remote = remoteName
+	}
+
+	err = dEnv.InitShallowClone(ctx, state)
+
+	if err != nil {
+		return err
+	}
+
+	for i, branchRef := range branchRefs {
+		err = dEnv.DoltDB.SetHeadToCommit(ctx, branchRef, commits[i])
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
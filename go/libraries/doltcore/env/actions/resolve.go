@@ -20,13 +20,14 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
 	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
 )
 
 type AutoResolveStats struct {
 }
 
-func AutoResolveAll(ctx context.Context, dEnv *env.DoltEnv, autoResolver merge.AutoResolver) error {
+func AutoResolveAll(ctx context.Context, dEnv *env.DoltEnv, autoResolver merge.AutoResolver, schResolver merge.SchemaResolver) error {
 	root, err := dEnv.WorkingRoot(ctx)
 
 	if err != nil {
@@ -39,21 +40,23 @@ func AutoResolveAll(ctx context.Context, dEnv *env.DoltEnv, autoResolver merge.A
 		return err
 	}
 
-	return autoResolve(ctx, dEnv, root, autoResolver, tbls)
+	return autoResolve(ctx, dEnv, root, autoResolver, schResolver, tbls)
 }
 
-func AutoResolveTables(ctx context.Context, dEnv *env.DoltEnv, autoResolver merge.AutoResolver, tbls []string) error {
+func AutoResolveTables(ctx context.Context, dEnv *env.DoltEnv, autoResolver merge.AutoResolver, schResolver merge.SchemaResolver, tbls []string) error {
 	root, err := dEnv.WorkingRoot(ctx)
 
 	if err != nil {
 		return err
 	}
 
-	return autoResolve(ctx, dEnv, root, autoResolver, tbls)
+	return autoResolve(ctx, dEnv, root, autoResolver, schResolver, tbls)
 }
 
-func autoResolve(ctx context.Context, dEnv *env.DoltEnv, root *doltdb.RootValue, autoResolver merge.AutoResolver, tbls []string) error {
-	tableEditSession := editor.CreateTableEditSession(root, editor.TableEditSessionProps{})
+// autoResolve resolves the conflicts of each of |tbls|. Schema conflicts are resolved first using |schResolver|, after
+// which the merge of the table's rows is finished and any resulting row conflicts are resolved using |autoResolver|.
+func autoResolve(ctx context.Context, dEnv *env.DoltEnv, root *doltdb.RootValue, autoResolver merge.AutoResolver, schResolver merge.SchemaResolver, tbls []string) error {
+	tableEditSession := editor.CreateTableEditSession(root, editor.TableEditSessionProps{NewRowValidator: sqlutil.NewCheckRowValidator})
 
 	for _, tblName := range tbls {
 		tbl, ok, err := root.GetTable(ctx, tblName)
@@ -66,6 +69,31 @@ func autoResolve(ctx context.Context, dEnv *env.DoltEnv, root *doltdb.RootValue,
 			return doltdb.ErrTableNotFound
 		}
 
+		if has, err := tbl.HasSchemaConflicts(); err != nil {
+			return err
+		} else if has {
+			_, err = merge.ResolveSchemaConflicts(ctx, root.VRW(), tblName, tbl, schResolver, tableEditSession)
+			if err != nil {
+				return err
+			}
+
+			resolvedRoot, err := tableEditSession.Flush(ctx)
+			if err != nil {
+				return err
+			}
+
+			tbl, _, err = resolvedRoot.GetTable(ctx, tblName)
+			if err != nil {
+				return err
+			}
+
+			if has, err := tbl.HasConflicts(); err != nil {
+				return err
+			} else if !has {
+				continue
+			}
+		}
+
 		err = merge.ResolveTable(ctx, root.VRW(), tblName, tbl, autoResolver, tableEditSession)
 
 		if err != nil {
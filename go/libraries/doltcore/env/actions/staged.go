@@ -141,6 +141,15 @@ func checkTablesForConflicts(ctx context.Context, tbls []string, working *doltdb
 			return nil, err
 		}
 
+		hasSchCnf, err := tbl.HasSchemaConflicts()
+		if err != nil {
+			return nil, err
+		}
+		if hasSchCnf {
+			inConflict = append(inConflict, tblName)
+			continue
+		}
+
 		has, err := tbl.HasConflicts()
 		if err != nil {
 			return nil, err
@@ -0,0 +1,400 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+)
+
+var ErrNoLocalChangesToStash = errors.New("no local changes to save")
+var ErrStashWithConflicts = errors.New("cannot stash changes while there are unresolved conflicts")
+var ErrStashDuringMerge = errors.New("cannot stash changes or apply a stash while a merge is in progress")
+var ErrStashDuringRebase = errors.New("cannot stash changes or apply a stash while a rebase is in progress")
+var ErrStashStagedConflicts = errors.New("the staged changes of the stash conflict with the staged changes of the working set")
+
+// Stash is a saved set of working and staged changes. A stash is stored as a dangling commit of the working root whose
+// parents are the HEAD commit at the time of the stash, and a dangling commit of the staged root. The ref of a stash is
+// named <seq>-<commit hash>, where seq orders the stashes by when they were pushed.
+type Stash struct {
+	Ref    ref.StashRef
+	Seq    uint64
+	Commit *doltdb.Commit
+	Meta   *doltdb.CommitMeta
+}
+
+// CommitHash returns the hash of the commit holding the stashed changes
+func (s *Stash) CommitHash() string {
+	_, h := parseStashRef(s.Ref)
+	return h
+}
+
+// parseStashRef returns the sequence number and commit hash in the name of the stash ref |r|
+func parseStashRef(r ref.StashRef) (uint64, string) {
+	name := r.GetPath()
+	idx := strings.IndexByte(name, '-')
+	if idx == -1 {
+		return 0, name
+	}
+
+	seq, err := strconv.ParseUint(name[:idx], 10, 64)
+	if err != nil {
+		return 0, name
+	}
+
+	return seq, name[idx+1:]
+}
+
+// StashName returns the name used to refer to the stash at index |idx| e.g. stash@{0}
+func StashName(idx int) string {
+	return fmt.Sprintf("stash@{%d}", idx)
+}
+
+// StashPush saves the changes in the working set, excluding untracked tables, as a new stash entry and resets the
+// working and staged roots to HEAD. Untracked tables are left in the working set.
+func StashPush(ctx context.Context, dEnv *env.DoltEnv, name, email string) (*Stash, error) {
+	if dEnv.IsMergeActive() {
+		return nil, ErrStashDuringMerge
+	}
+
+	if dEnv.IsRebaseActive() {
+		return nil, ErrStashDuringRebase
+	}
+
+	ddb := dEnv.DoltDB
+	working, staged, head, err := env.GetRoots(ctx, ddb, dEnv.RepoStateReader())
+	if err != nil {
+		return nil, err
+	}
+
+	if has, err := working.HasConflicts(ctx); err != nil {
+		return nil, err
+	} else if has {
+		return nil, ErrStashWithConflicts
+	}
+
+	untracked, err := getUntrackedTables(ctx, working, staged, head)
+	if err != nil {
+		return nil, err
+	}
+
+	stashedWorking, err := working.RemoveTables(ctx, untracked...)
+	if err != nil {
+		return nil, err
+	}
+
+	stagedDeltas, err := diff.GetTableDeltas(ctx, head, staged)
+	if err != nil {
+		return nil, err
+	}
+
+	workingDeltas, err := diff.GetTableDeltas(ctx, staged, stashedWorking)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stagedDeltas) == 0 && len(workingDeltas) == 0 {
+		return nil, ErrNoLocalChangesToStash
+	}
+
+	headCommit, err := ddb.ResolveRef(ctx, dEnv.RepoState.CWBHeadRef())
+	if err != nil {
+		return nil, err
+	}
+
+	headHash, err := headCommit.HashOf()
+	if err != nil {
+		return nil, err
+	}
+
+	headMeta, err := headCommit.GetCommitMeta()
+	if err != nil {
+		return nil, err
+	}
+
+	desc := fmt.Sprintf("WIP on %s: %s %s", dEnv.RepoState.CWBHeadRef().GetPath(), headHash.String(), merge.FirstLine(headMeta.Description))
+
+	stagedMeta, err := doltdb.NewCommitMeta(name, email, "index on "+desc)
+	if err != nil {
+		return nil, err
+	}
+
+	stagedHash, err := ddb.WriteRootValue(ctx, staged)
+	if err != nil {
+		return nil, err
+	}
+
+	stagedCommit, err := ddb.WriteDanglingCommit(ctx, stagedHash, []*doltdb.Commit{headCommit}, stagedMeta)
+	if err != nil {
+		return nil, err
+	}
+
+	workingMeta, err := doltdb.NewCommitMeta(name, email, desc)
+	if err != nil {
+		return nil, err
+	}
+
+	workingHash, err := ddb.WriteRootValue(ctx, stashedWorking)
+	if err != nil {
+		return nil, err
+	}
+
+	workingCommit, err := ddb.WriteDanglingCommit(ctx, workingHash, []*doltdb.Commit{headCommit, stagedCommit}, workingMeta)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := workingCommit.HashOf()
+	if err != nil {
+		return nil, err
+	}
+
+	stashes, err := ListStashes(ctx, ddb)
+	if err != nil {
+		return nil, err
+	}
+
+	var seq uint64 = 1
+	if len(stashes) > 0 {
+		seq = stashes[0].Seq + 1
+	}
+
+	stashRef := ref.NewStashRef(fmt.Sprintf("%d-%s", seq, h.String()))
+	err = ddb.NewStashAtCommit(ctx, stashRef, workingCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	newWorking := head
+	for _, tblName := range untracked {
+		tbl, _, err := working.GetTable(ctx, tblName)
+		if err != nil {
+			return nil, err
+		}
+
+		newWorking, err = newWorking.PutTable(ctx, tblName, tbl)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = env.UpdateWorkingRoot(ctx, ddb, dEnv.RepoStateWriter(), newWorking)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = env.UpdateStagedRoot(ctx, ddb, dEnv.RepoStateWriter(), head)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stash{Ref: stashRef, Seq: seq, Commit: workingCommit, Meta: workingMeta}, nil
+}
+
+// ListStashes returns all stash entries in the database, most recently pushed first.
+func ListStashes(ctx context.Context, ddb *doltdb.DoltDB) ([]*Stash, error) {
+	refs, err := ddb.GetStashes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stashes := make([]*Stash, len(refs))
+	for i, r := range refs {
+		cm, err := ddb.ResolveRef(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+
+		meta, err := cm.GetCommitMeta()
+		if err != nil {
+			return nil, err
+		}
+
+		stashRef := r.(ref.StashRef)
+		seq, _ := parseStashRef(stashRef)
+		stashes[i] = &Stash{Ref: stashRef, Seq: seq, Commit: cm, Meta: meta}
+	}
+
+	sort.Slice(stashes, func(i, j int) bool {
+		return stashes[i].Seq > stashes[j].Seq
+	})
+
+	return stashes, nil
+}
+
+// GetStash returns the stash entry at index |idx|, where 0 is the most recent stash.
+func GetStash(ctx context.Context, ddb *doltdb.DoltDB, idx int) (*Stash, error) {
+	stashes, err := ListStashes(ctx, ddb)
+	if err != nil {
+		return nil, err
+	}
+
+	if idx < 0 || idx >= len(stashes) {
+		return nil, doltdb.ErrStashNotFound
+	}
+
+	return stashes[idx], nil
+}
+
+// DropStash deletes the stash entry at index |idx|.
+func DropStash(ctx context.Context, ddb *doltdb.DoltDB, idx int) (*Stash, error) {
+	stash, err := GetStash(ctx, ddb, idx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ddb.DeleteStash(ctx, stash.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return stash, nil
+}
+
+// StashPop applies the stash entry at index |idx| to the working set and drops it. The stashed working root is three-way
+// merged into the current working root using the HEAD commit the stash was created on as the ancestor. If the merge
+// results in conflicts, they are written to the working root, the stash entry is kept and the returned stats will
+// report the conflicts. Otherwise the stashed staged root is merged into the current staged root in the same way. If
+// that merge fails or results in conflicts, an error is returned and neither the working set nor the stash is changed.
+func StashPop(ctx context.Context, dEnv *env.DoltEnv, idx int) (map[string]*merge.MergeStats, error) {
+	if dEnv.IsMergeActive() {
+		return nil, ErrStashDuringMerge
+	}
+
+	if dEnv.IsRebaseActive() {
+		return nil, ErrStashDuringRebase
+	}
+
+	ddb := dEnv.DoltDB
+	stash, err := GetStash(ctx, ddb, idx)
+	if err != nil {
+		return nil, err
+	}
+
+	working, staged, _, err := env.GetRoots(ctx, ddb, dEnv.RepoStateReader())
+	if err != nil {
+		return nil, err
+	}
+
+	if has, err := working.HasConflicts(ctx); err != nil {
+		return nil, err
+	} else if has {
+		return nil, ErrStashWithConflicts
+	}
+
+	parents, err := ddb.ResolveAllParents(ctx, stash.Commit)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(parents) != 2 {
+		return nil, fmt.Errorf("stash %s is corrupt: expected 2 parents, found %d", stash.Ref.GetPath(), len(parents))
+	}
+
+	ancRoot, err := parents[0].GetRootValue()
+	if err != nil {
+		return nil, err
+	}
+
+	stashedStaged, err := parents[1].GetRootValue()
+	if err != nil {
+		return nil, err
+	}
+
+	stashedWorking, err := stash.Commit.GetRootValue()
+	if err != nil {
+		return nil, err
+	}
+
+	mergedWorking, tblToStats, err := merge.MergeRoots(ctx, working, stashedWorking, ancRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	if merge.HasConflicts(tblToStats) {
+		_, err = env.UpdateWorkingRoot(ctx, ddb, dEnv.RepoStateWriter(), mergedWorking)
+		if err != nil {
+			return nil, err
+		}
+
+		return tblToStats, nil
+	}
+
+	mergedStaged, stagedStats, err := merge.MergeRoots(ctx, staged, stashedStaged, ancRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	if merge.HasConflicts(stagedStats) {
+		return nil, ErrStashStagedConflicts
+	}
+
+	_, err = env.UpdateWorkingRoot(ctx, ddb, dEnv.RepoStateWriter(), mergedWorking)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = env.UpdateStagedRoot(ctx, ddb, dEnv.RepoStateWriter(), mergedStaged)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ddb.DeleteStash(ctx, stash.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return tblToStats, nil
+}
+
+func getUntrackedTables(ctx context.Context, working, staged, head *doltdb.RootValue) ([]string, error) {
+	tblNames, err := working.GetTableNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var untracked []string
+	for _, tblName := range tblNames {
+		if tblName == doltdb.DocTableName {
+			continue
+		}
+
+		inStaged, err := staged.HasTable(ctx, tblName)
+		if err != nil {
+			return nil, err
+		}
+
+		inHead, err := head.HasTable(ctx, tblName)
+		if err != nil {
+			return nil, err
+		}
+
+		if !inStaged && !inHead {
+			untracked = append(untracked, tblName)
+		}
+	}
+
+	return untracked, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+)
+
+func TestStashOrderWithinTheSameMillisecond(t *testing.T) {
+	prevNowFunc := doltdb.CommitNowFunc
+	defer func() { doltdb.CommitNowFunc = prevNowFunc }()
+	now := time.Now()
+	doltdb.CommitNowFunc = func() time.Time { return now }
+
+	ctx := context.Background()
+	dEnv := dtestutils.CreateEnvWithSeedData(t)
+	working, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+
+	var pushed []*Stash
+	for i := 0; i < 3; i++ {
+		_, err = env.UpdateWorkingRoot(ctx, dEnv.DoltDB, dEnv.RepoStateWriter(), working)
+		require.NoError(t, err)
+		_, err = env.UpdateStagedRoot(ctx, dEnv.DoltDB, dEnv.RepoStateWriter(), working)
+		require.NoError(t, err)
+
+		stash, err := StashPush(ctx, dEnv, "name", "name@example.com")
+		require.NoError(t, err)
+		pushed = append(pushed, stash)
+	}
+
+	stashes, err := ListStashes(ctx, dEnv.DoltDB)
+	require.NoError(t, err)
+	require.Len(t, stashes, 3)
+	for i, stash := range stashes {
+		assert.Equal(t, pushed[len(pushed)-1-i].Ref, stash.Ref)
+		assert.Equal(t, uint64(len(pushed)-i), stash.Seq)
+	}
+
+	dropped, err := DropStash(ctx, dEnv.DoltDB, 0)
+	require.NoError(t, err)
+	assert.Equal(t, pushed[2].Ref, dropped.Ref)
+	stash, err := GetStash(ctx, dEnv.DoltDB, 0)
+	require.NoError(t, err)
+	assert.Equal(t, pushed[1].Ref, stash.Ref)
+}
+
+func TestStashPopDuringMerge(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateEnvWithSeedData(t)
+	dEnv.RepoState.Merge = &env.MergeState{}
+
+	_, err := StashPop(ctx, dEnv, 0)
+	assert.Equal(t, ErrStashDuringMerge, err)
+}
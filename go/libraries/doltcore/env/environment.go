@@ -122,7 +122,11 @@ func Load(ctx context.Context, hdp HomeDirProvider, fs filesys.Filesys, urlStr,
 		}
 	}
 
-	dbfactory.InitializeFactories(dEnv)
+	dbfactory.InitializeFactories(dEnv, dEnv)
+
+	if dbLoadErr == nil && dEnv.IsShallow() {
+		dEnv.DoltDB, dEnv.DBLoadError = dEnv.shallowDoltDB(repoState.Shallow)
+	}
 
 	return dEnv
 }
@@ -542,6 +546,10 @@ func (dEnv *DoltEnv) IsMergeActive() bool {
 	return dEnv.RepoState.Merge != nil
 }
 
+func (dEnv *DoltEnv) IsRebaseActive() bool {
+	return dEnv.RepoState.IsRebaseActive()
+}
+
 func (dEnv *DoltEnv) GetTablesWithConflicts(ctx context.Context) ([]string, error) {
 	root, err := dEnv.WorkingRoot(ctx)
 
@@ -775,6 +783,23 @@ func (dEnv *DoltEnv) FindCreds(credsDir, pubKeyOrId string) (string, error) {
 	}
 }
 
+// GetCreds returns the dolt credentials in the creds directory with the public key or key id |pubKeyOrId|
+func (dEnv *DoltEnv) GetCreds(pubKeyOrId string) (creds.DoltCreds, error) {
+	credsDir, err := dEnv.CredsDir()
+
+	if err != nil {
+		return creds.EmptyCreds, err
+	}
+
+	path, err := dEnv.FindCreds(credsDir, pubKeyOrId)
+
+	if err != nil {
+		return creds.EmptyCreds, err
+	}
+
+	return creds.JWKCredsReadFromFile(dEnv.FS, path)
+}
+
 func (dEnv *DoltEnv) FindRef(ctx context.Context, refStr string) (ref.DoltRef, error) {
 	localRef := ref.NewBranchRef(refStr)
 	if hasRef, err := dEnv.DoltDB.HasRef(ctx, localRef); err != nil {
@@ -68,13 +68,31 @@ type MergeState struct {
 	PreMergeWorking string `json:"working_pre_merge"`
 }
 
+// RebaseStep is a single entry in the todo list of a rebase
+type RebaseStep struct {
+	Action string `json:"action"`
+	Commit string `json:"commit"`
+}
+
+// RebaseState tracks a rebase that has stopped to let the user resolve conflicts. |Branch| is the branch being rebased,
+// |OrigHead| is the hash of the commit the branch pointed at before the rebase started, |Onto| is the hash of the
+// commit the steps are replayed on top of, and |Todo| holds the remaining steps, starting with the step that stopped.
+type RebaseState struct {
+	Branch   ref.MarshalableRef `json:"branch"`
+	OrigHead string             `json:"orig_head"`
+	Onto     string             `json:"onto"`
+	Todo     []RebaseStep       `json:"todo"`
+}
+
 type RepoState struct {
 	Head     ref.MarshalableRef      `json:"head"`
 	Staged   string                  `json:"staged"`
 	Working  string                  `json:"working"`
 	Merge    *MergeState             `json:"merge"`
+	Rebase   *RebaseState            `json:"rebase,omitempty"`
 	Remotes  map[string]Remote       `json:"remotes"`
 	Branches map[string]BranchConfig `json:"branches"`
+	Shallow  *ShallowState           `json:"shallow,omitempty"`
 }
 
 func LoadRepoState(fs filesys.ReadWriteFS) (*RepoState, error) {
@@ -103,8 +121,10 @@ func CloneRepoState(fs filesys.ReadWriteFS, r Remote) (*RepoState, error) {
 		hashStr,
 		hashStr,
 		nil,
+		nil,
 		map[string]Remote{r.Name: r},
 		make(map[string]BranchConfig),
+		nil,
 	}
 
 	err := rs.Save(fs)
@@ -129,8 +149,10 @@ func CreateRepoState(fs filesys.ReadWriteFS, br string, rootHash hash.Hash) (*Re
 		hashStr,
 		hashStr,
 		nil,
+		nil,
 		make(map[string]Remote),
 		make(map[string]BranchConfig),
+		nil,
 	}
 
 	err = rs.Save(fs)
@@ -178,6 +200,20 @@ func (rs *RepoState) ClearMerge(fs filesys.Filesys) error {
 	return rs.Save(fs)
 }
 
+func (rs *RepoState) StartRebase(branch ref.DoltRef, origHead, onto string, todo []RebaseStep, fs filesys.Filesys) error {
+	rs.Rebase = &RebaseState{ref.MarshalableRef{Ref: branch}, origHead, onto, todo}
+	return rs.Save(fs)
+}
+
+func (rs *RepoState) ClearRebase(fs filesys.Filesys) error {
+	rs.Rebase = nil
+	return rs.Save(fs)
+}
+
+func (rs *RepoState) IsRebaseActive() bool {
+	return rs.Rebase != nil
+}
+
 func (rs *RepoState) AddRemote(r Remote) {
 	rs.Remotes[r.Name] = r
 }
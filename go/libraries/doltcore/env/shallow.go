@@ -0,0 +1,109 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotestorage"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// ShallowState is the state of a repository which was cloned with only part of the history of its remote.
+// |Boundary| holds the commits which are the parents of commits in the repository, but are missing from it. If
+// |Remote| is set, the chunks missing from the repository are fetched from that remote when they are read.
+type ShallowState struct {
+	Remote   string   `json:"remote,omitempty"`
+	Boundary []string `json:"boundary"`
+}
+
+// NewShallowState returns the ShallowState of a repository with the boundary |boundary| which reads missing chunks
+// from the remote named |remote|, or never reads them if |remote| is empty.
+func NewShallowState(remote string, boundary hash.HashSet) *ShallowState {
+	strs := make([]string, 0, len(boundary))
+	for h := range boundary {
+		strs = append(strs, h.String())
+	}
+
+	return &ShallowState{Remote: remote, Boundary: strs}
+}
+
+// IsShallow returns true if the repository was cloned with only part of the history of its remote.
+func (dEnv *DoltEnv) IsShallow() bool {
+	return dEnv.RepoState != nil && dEnv.RepoState.Shallow != nil
+}
+
+// InitShallowClone records that the repository holds only part of the history of its remote, and reopens its
+// database so that the commits of the boundary of |state| are considered present, and missing chunks are read from
+// the remote of |state|. The repo state is not saved.
+func (dEnv *DoltEnv) InitShallowClone(ctx context.Context, state *ShallowState) error {
+	ddb, err := dEnv.shallowDoltDB(state)
+
+	if err != nil {
+		return err
+	}
+
+	dEnv.RepoState.Shallow = state
+	dEnv.DoltDB = ddb
+
+	return nil
+}
+
+// shallowDoltDB returns a database reading the chunks of the database of the environment through a
+// remotestorage.ShallowChunkStore for |state|.
+func (dEnv *DoltEnv) shallowDoltDB(state *ShallowState) (*doltdb.DoltDB, error) {
+	local, ok := dEnv.DoltDB.ChunkStore().(remotestorage.LocalChunkStore)
+
+	if !ok {
+		return nil, errors.New("the database of this repository does not support shallow clones")
+	}
+
+	boundary := hash.HashSet{}
+	for _, str := range state.Boundary {
+		h, ok := hash.MaybeParse(str)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid shallow boundary commit '%s'", str)
+		}
+
+		boundary.Insert(h)
+	}
+
+	var openRemote remotestorage.RemoteChunkStoreOpener
+	if state.Remote != "" {
+		remoteName := state.Remote
+		openRemote = func(ctx context.Context) (chunks.ChunkStore, error) {
+			r, ok := dEnv.RepoState.Remotes[remoteName]
+
+			if !ok {
+				return nil, fmt.Errorf("unknown remote '%s'", remoteName)
+			}
+
+			rdb, err := r.GetRemoteDB(ctx, dEnv.DoltDB.Format())
+
+			if err != nil {
+				return nil, err
+			}
+
+			return rdb.ChunkStore(), nil
+		}
+	}
+
+	return doltdb.DoltDBFromCS(remotestorage.NewShallowChunkStore(local, boundary, openRemote)), nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+)
+
+var ErrCherryPickMergeCommit = errors.New("cherry-picking a merge commit is not supported")
+var ErrCherryPickInitialCommit = errors.New("cannot cherry-pick a commit without a parent")
+
+var errNoParent = errors.New("commit has no parent")
+var errMultipleParents = errors.New("commit has multiple parents")
+
+// CherryPick applies the changes introduced by |cm| relative to its parent onto |root|. The changes are applied by
+// three-way merging |root| with the root of |cm|, using the root of its parent as the ancestor. Conflicting rows are
+// recorded in the resulting root in the same way they are for a merge.
+func CherryPick(ctx context.Context, ddb *doltdb.DoltDB, root *doltdb.RootValue, cm *doltdb.Commit) (*doltdb.RootValue, map[string]*MergeStats, error) {
+	cmRoot, parentRoot, err := getCommitAndParentRoots(ctx, ddb, cm)
+
+	if err == errNoParent {
+		return nil, nil, ErrCherryPickInitialCommit
+	} else if err == errMultipleParents {
+		return nil, nil, ErrCherryPickMergeCommit
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	return MergeRoots(ctx, root, cmRoot, parentRoot)
+}
+
+// getCommitAndParentRoots returns the root value of |cm| and the root value of its single parent. Returns errNoParent
+// or errMultipleParents if |cm| does not have exactly one parent.
+func getCommitAndParentRoots(ctx context.Context, ddb *doltdb.DoltDB, cm *doltdb.Commit) (*doltdb.RootValue, *doltdb.RootValue, error) {
+	numParents, err := cm.NumParents()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if numParents == 0 {
+		return nil, nil, errNoParent
+	} else if numParents > 1 {
+		return nil, nil, errMultipleParents
+	}
+
+	parent, err := ddb.ResolveParent(ctx, cm, 0)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cmRoot, err := cm.GetRootValue()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parentRoot, err := parent.GetRootValue()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cmRoot, parentRoot, nil
+}
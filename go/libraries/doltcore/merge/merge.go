@@ -26,6 +26,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
 	"github.com/dolthub/dolt/go/libraries/doltcore/row"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
 	"github.com/dolthub/dolt/go/libraries/utils/valutil"
 	"github.com/dolthub/dolt/go/store/atomicerr"
@@ -35,17 +36,38 @@ import (
 
 var ErrFastForward = errors.New("fast forward")
 var ErrSameTblAddedTwice = errors.New("table with same name added in 2 commits can't be merged")
+var ErrTableDeletedAndModified = errors.New("conflict: table with same name deleted and modified")
 
 type Merger struct {
 	root      *doltdb.RootValue
 	mergeRoot *doltdb.RootValue
 	ancRoot   *doltdb.RootValue
 	vrw       types.ValueReadWriter
+	policies  map[string]MergePolicy
 }
 
 // NewMerger creates a new merger utility object.
 func NewMerger(ctx context.Context, root, mergeRoot, ancRoot *doltdb.RootValue, vrw types.ValueReadWriter) *Merger {
-	return &Merger{root, mergeRoot, ancRoot, vrw}
+	return &Merger{root: root, mergeRoot: mergeRoot, ancRoot: ancRoot, vrw: vrw}
+}
+
+// conflictResolver returns the rowConflictResolver for the merge policy of the table |tblName|, or nil if the table has
+// no merge policy. Merge policies are read from our root.
+func (merger *Merger) conflictResolver(ctx context.Context, tblName string, sch schema.Schema) (rowConflictResolver, error) {
+	if merger.policies == nil {
+		policies, err := GetMergePolicies(ctx, merger.root)
+		if err != nil {
+			return nil, err
+		}
+		merger.policies = policies
+	}
+
+	p, ok := merger.policies[tblName]
+	if !ok {
+		return nil, nil
+	}
+
+	return p.conflictResolver(ctx, sch)
 }
 
 // MergeTable merges schema and table data for the table tblName.
@@ -117,10 +139,15 @@ func (merger *Merger) MergeTable(ctx context.Context, tblName string, sess *edit
 			}
 		}
 
+		if !ok && mergeOk && mh == anch {
+			// the table was dropped on our side and is unchanged on theirs
+			return nil, &MergeStats{Operation: TableRemoved}, nil
+		}
+
 		if h == anch {
 			// fast-forward
 			ms := MergeStats{Operation: TableModified}
-			if h != mh {
+			if h != mh && mergeOk {
 				ms, err = calcTableMergeStats(ctx, tbl, mergeTbl)
 			}
 			// force load the table editor since this counts as a change
@@ -133,6 +160,10 @@ func (merger *Merger) MergeTable(ctx context.Context, tblName string, sess *edit
 			// fast-forward
 			return tbl, &MergeStats{Operation: TableUnmodified}, nil
 		}
+
+		if !ok || !mergeOk {
+			return nil, nil, ErrTableDeletedAndModified
+		}
 	}
 
 	tblSchema, err := tbl.GetSchema(ctx)
@@ -150,10 +181,29 @@ func (merger *Merger) MergeTable(ctx context.Context, tblName string, sess *edit
 		return nil, nil, err
 	}
 	if schConflicts.Count() != 0 {
-		// error on schema conflicts for now
-		return nil, nil, schConflicts.AsError()
+		// the rows can't be merged until the schema conflicts are resolved, so keep our version of the table and
+		// record what is needed to finish the merge later.
+		resultTbl, err := tbl.SetSchemaConflicts(ctx, doltdb.SchemaConflicts{Ancestor: ancTbl, Theirs: mergeTbl})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		n := schConflicts.Count()
+		return resultTbl, &MergeStats{Operation: TableModified, Conflicts: n, SchemaConflicts: n}, nil
+	}
+
+	resolver, err := merger.conflictResolver(ctx, tblName, postMergeSchema)
+	if err != nil {
+		return nil, nil, err
 	}
 
+	return mergeTableRows(ctx, merger.vrw, tblName, tbl, mergeTbl, ancTbl, postMergeSchema, resolver, sess)
+}
+
+// mergeTableRows updates |tbl| to |postMergeSchema| and merges the rows of |mergeTbl| into it using the rows of |ancTbl|
+// as the common ancestor. Conflicting rows are resolved by |resolver| if it is non-nil, and any conflicts it does not
+// resolve are recorded in the returned table.
+func mergeTableRows(ctx context.Context, vrw types.ValueReadWriter, tblName string, tbl, mergeTbl, ancTbl *doltdb.Table, postMergeSchema schema.Schema, resolver rowConflictResolver, sess *editor.TableEditSession) (*doltdb.Table, *MergeStats, error) {
 	rows, err := tbl.GetRowData(ctx)
 	if err != nil {
 		return nil, nil, err
@@ -183,7 +233,7 @@ func (merger *Merger) MergeTable(ctx context.Context, tblName string, sess *edit
 		return nil, nil, err
 	}
 
-	resultTbl, conflicts, stats, err := mergeTableData(ctx, merger.vrw, tblName, postMergeSchema, rows, mergeRows, ancRows, updatedTblEditor, sess)
+	resultTbl, conflicts, stats, err := mergeTableData(ctx, vrw, tblName, postMergeSchema, rows, mergeRows, ancRows, updatedTblEditor, resolver, sess)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -264,7 +314,7 @@ type rowMerger func(ctx context.Context, nbf *types.NomsBinFormat, sch schema.Sc
 
 type applicator func(ctx context.Context, sch schema.Schema, tableEditor editor.TableEditor, rowData types.Map, stats *MergeStats, change types.ValueChanged) error
 
-func mergeTableData(ctx context.Context, vrw types.ValueReadWriter, tblName string, sch schema.Schema, rows, mergeRows, ancRows types.Map, tblEdit editor.TableEditor, sess *editor.TableEditSession) (*doltdb.Table, types.Map, *MergeStats, error) {
+func mergeTableData(ctx context.Context, vrw types.ValueReadWriter, tblName string, sch schema.Schema, rows, mergeRows, ancRows types.Map, tblEdit editor.TableEditor, resolver rowConflictResolver, sess *editor.TableEditSession) (*doltdb.Table, types.Map, *MergeStats, error) {
 	var rowMerge rowMerger
 	var applyChange applicator
 	if schema.IsKeyless(sch) {
@@ -355,6 +405,10 @@ func mergeTableData(ctx context.Context, vrw types.ValueReadWriter, tblName stri
 
 				if keyNilOrMKLess {
 					err = applyChange(ctx, sch, tblEdit, rows, stats, mergeChange)
+					if sqlutil.IsCheckViolation(err) {
+						// our row is unchanged from the ancestor
+						err = addCheckViolationConflict(vrw, conflictValChan, sm.Done(), stats, mergeKey, mergeChange.OldValue, mergeChange.OldValue, mergeChange.NewValue)
+					}
 					if err != nil {
 						return err
 					}
@@ -370,7 +424,26 @@ func mergeTableData(ctx context.Context, vrw types.ValueReadWriter, tblName stri
 					return err
 				}
 
-				if isConflict {
+				resolved := false
+				if isConflict && resolver != nil {
+					var useTheirs bool
+					useTheirs, resolved, err = resolver(ctx, key, r, mergeRow)
+					if err != nil {
+						return err
+					}
+
+					// our row is already in the table, so there is only something to apply if their row wins
+					if resolved && useTheirs {
+						err = applyChange(ctx, sch, tblEdit, rows, stats, theirRowChange(key, r, mergeRow))
+						if err != nil {
+							return err
+						}
+					}
+				}
+
+				if resolved {
+					stats.ResolvedByPolicy++
+				} else if isConflict {
 					stats.Conflicts++
 					conflictTuple, err := doltdb.NewConflict(ancRow, r, mergeRow).ToNomsList(vrw)
 					if err != nil {
@@ -384,6 +457,9 @@ func mergeTableData(ctx context.Context, vrw types.ValueReadWriter, tblName stri
 				} else {
 					vc := types.ValueChanged{ChangeType: change.ChangeType, Key: key, OldValue: ancRow, NewValue: mergedRow}
 					err = applyChange(ctx, sch, tblEdit, rows, stats, vc)
+					if sqlutil.IsCheckViolation(err) {
+						err = addCheckViolationConflict(vrw, conflictValChan, sm.Done(), stats, key, ancRow, r, mergeRow)
+					}
 					if err != nil {
 						return err
 					}
@@ -426,6 +502,31 @@ func mergeTableData(ctx context.Context, vrw types.ValueReadWriter, tblName stri
 	return mergedTable, conflicts, stats, nil
 }
 
+// theirRowChange returns the change which replaces our version of a row, |r|, with their version, |mergeRow|. Either
+// may be nil if the row was deleted on that side.
+func theirRowChange(key, r, mergeRow types.Value) types.ValueChanged {
+	switch {
+	case types.IsNull(mergeRow):
+		return types.ValueChanged{ChangeType: types.DiffChangeRemoved, Key: key, OldValue: r}
+	case types.IsNull(r):
+		return types.ValueChanged{ChangeType: types.DiffChangeAdded, Key: key, NewValue: mergeRow}
+	default:
+		return types.ValueChanged{ChangeType: types.DiffChangeModified, Key: key, OldValue: r, NewValue: mergeRow}
+	}
+}
+
+// addCheckViolationConflict records a conflict for a row whose merged value violates a check constraint of the table.
+func addCheckViolationConflict(vrw types.ValueReadWriter, conflictChan chan types.Value, done <-chan struct{}, stats *MergeStats, key, ancRow, r, mergeRow types.Value) error {
+	stats.Conflicts++
+	stats.CheckViolations++
+	conflictTuple, err := doltdb.NewConflict(ancRow, r, mergeRow).ToNomsList(vrw)
+	if err != nil {
+		return err
+	}
+
+	return addConflict(conflictChan, done, key, conflictTuple)
+}
+
 func addConflict(conflictChan chan types.Value, done <-chan struct{}, key types.Value, value types.Tuple) error {
 	select {
 	case conflictChan <- key:
@@ -760,6 +861,7 @@ func MergeRoots(ctx context.Context, ourRoot, theirRoot, ancRoot *doltdb.RootVal
 	newRoot := ourRoot
 	tableEditSession := editor.CreateTableEditSession(ourRoot, editor.TableEditSessionProps{
 		ForeignKeyChecksDisabled: true,
+		NewRowValidator:          sqlutil.NewCheckRowValidator,
 	})
 	var unconflicted []string
 	// need to validate merges can be done on all tables before starting the actual merges.
@@ -799,7 +901,8 @@ func MergeRoots(ctx context.Context, ourRoot, theirRoot, ancRoot *doltdb.RootVal
 				return nil, nil, err
 			}
 		} else {
-			panic("?")
+			// the table was dropped on our side, so it stays out of the merged root
+			tblToStats[tblName] = stats
 		}
 	}
 
@@ -808,11 +911,22 @@ func MergeRoots(ctx context.Context, ourRoot, theirRoot, ancRoot *doltdb.RootVal
 		if err != nil {
 			return nil, err
 		}
-		if len(conflicts) > 0 {
-			return nil, fmt.Errorf("foreign key conflicts")
+
+		root, err = root.PutForeignKeyCollection(ctx, mergedFKColl)
+		if err != nil {
+			return nil, err
 		}
-		return root.PutForeignKeyCollection(ctx, mergedFKColl)
+
+		return recordFKConflicts(ctx, root, conflicts, tblToStats)
 	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newRoot, err = tableEditSession.Flush(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	err = tableEditSession.ValidateForeignKeys(ctx)
 	if err != nil {
@@ -828,6 +942,46 @@ func MergeRoots(ctx context.Context, ourRoot, theirRoot, ancRoot *doltdb.RootVal
 	return newRoot, tblToStats, nil
 }
 
+// recordFKConflicts records each foreign key conflict as a schema conflict on the foreign key's child table, and updates
+// the merge stats for that table.
+func recordFKConflicts(ctx context.Context, root *doltdb.RootValue, conflicts []FKConflict, tblToStats map[string]*MergeStats) (*doltdb.RootValue, error) {
+	for _, c := range conflicts {
+		tblName := c.Ours.TableName
+		tbl, ok, err := root.GetTable(ctx, tblName)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+
+		sc, err := tbl.GetSchemaConflicts(ctx)
+		if err != nil && err != doltdb.ErrNoConflicts {
+			return nil, err
+		}
+
+		sc.ForeignKeys = append(sc.ForeignKeys, doltdb.ForeignKeyConflict{Kind: uint8(c.Kind), Ours: c.Ours, Theirs: c.Theirs})
+		tbl, err = tbl.SetSchemaConflicts(ctx, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		root, err = root.PutTable(ctx, tblName, tbl)
+		if err != nil {
+			return nil, err
+		}
+
+		stats, ok := tblToStats[tblName]
+		if !ok || stats.Operation == TableUnmodified {
+			stats = &MergeStats{Operation: TableModified}
+			tblToStats[tblName] = stats
+		}
+		stats.Conflicts++
+		stats.SchemaConflicts++
+	}
+
+	return root, nil
+}
+
 func GetTablesInConflict(ctx context.Context, ddb *doltdb.DoltDB, rsr env.RepoStateReader) (workingInConflict, stagedInConflict, headInConflict []string, err error) {
 	var headRoot, stagedRoot, workingRoot *doltdb.RootValue
 
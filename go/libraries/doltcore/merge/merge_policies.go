@@ -0,0 +1,260 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	// MergeStrategyOurs resolves every row conflict in a table by keeping our version of the row
+	MergeStrategyOurs = "ours"
+	// MergeStrategyTheirs resolves every row conflict in a table by taking their version of the row
+	MergeStrategyTheirs = "theirs"
+	// MergeStrategySql resolves row conflicts in a table by evaluating the policy's SQL expression
+	MergeStrategySql = "sql"
+)
+
+const (
+	policyOursTableName   = "ours"
+	policyTheirsTableName = "theirs"
+)
+
+var mergePoliciesCols = schema.NewColCollection(
+	schema.NewColumn(doltdb.MergePoliciesTableNameCol, schema.MergePoliciesTableNameTag, types.StringKind, true, schema.NotNullConstraint{}),
+	schema.NewColumn(doltdb.MergePoliciesStrategyCol, schema.MergePoliciesStrategyTag, types.StringKind, false, schema.NotNullConstraint{}),
+	schema.NewColumn(doltdb.MergePoliciesExpressionCol, schema.MergePoliciesExpressionTag, types.StringKind, false),
+)
+
+// MergePoliciesSchema is the schema of the dolt_merge_policies table
+var MergePoliciesSchema = schema.MustSchemaFromCols(mergePoliciesCols)
+
+// MergePolicy is an entry in the dolt_merge_policies table which determines how row conflicts in a table are resolved
+// during a merge.
+type MergePolicy struct {
+	TableName  string
+	Strategy   string
+	Expression string
+}
+
+// GetMergePolicies returns the merge policies stored in |root|, keyed by table name. Returns an error if any of the
+// policies are invalid.
+func GetMergePolicies(ctx context.Context, root *doltdb.RootValue) (map[string]MergePolicy, error) {
+	policies := make(map[string]MergePolicy)
+
+	tbl, ok, err := root.GetTable(ctx, doltdb.MergePoliciesTableName)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return policies, nil
+	}
+
+	rows, err := tbl.GetRowData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = rows.IterAll(ctx, func(key, value types.Value) error {
+		p, err := mergePolicyFromKV(key.(types.Tuple), value.(types.Tuple))
+		if err != nil {
+			return err
+		}
+
+		err = p.validate()
+		if err != nil {
+			return err
+		}
+
+		policies[p.TableName] = p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func mergePolicyFromKV(key, value types.Tuple) (MergePolicy, error) {
+	ktv, err := row.ParseTaggedValues(key)
+	if err != nil {
+		return MergePolicy{}, err
+	}
+
+	tv, err := row.ParseTaggedValues(value)
+	if err != nil {
+		return MergePolicy{}, err
+	}
+
+	nameVal := ktv.GetWithDefault(schema.MergePoliciesTableNameTag, types.String(""))
+	strategyVal := tv.GetWithDefault(schema.MergePoliciesStrategyTag, types.String(""))
+	exprVal := tv.GetWithDefault(schema.MergePoliciesExpressionTag, types.String(""))
+
+	return MergePolicy{
+		TableName:  string(nameVal.(types.String)),
+		Strategy:   strings.ToLower(strings.TrimSpace(string(strategyVal.(types.String)))),
+		Expression: string(exprVal.(types.String)),
+	}, nil
+}
+
+func (p MergePolicy) validate() error {
+	switch p.Strategy {
+	case MergeStrategyOurs, MergeStrategyTheirs:
+		return nil
+	case MergeStrategySql:
+		if strings.TrimSpace(p.Expression) == "" {
+			return fmt.Errorf("merge policy for table '%s' uses the '%s' strategy but has no expression", p.TableName, MergeStrategySql)
+		}
+		return nil
+	default:
+		return fmt.Errorf("merge policy for table '%s' has unknown strategy '%s'. Valid strategies are '%s', '%s' and '%s'",
+			p.TableName, p.Strategy, MergeStrategyOurs, MergeStrategyTheirs, MergeStrategySql)
+	}
+}
+
+// rowConflictResolver decides which side of a row conflict should win. |ourRow| and |theirRow| are the noms values of
+// the conflicting rows and are nil if the row was deleted. If |resolved| is false the conflict is recorded as usual.
+type rowConflictResolver func(ctx context.Context, key, ourRow, theirRow types.Value) (useTheirs bool, resolved bool, err error)
+
+// conflictResolver returns the rowConflictResolver for this policy applied to a table with the schema |sch|. Policies
+// only apply to tables with a primary key, and nil is returned for keyless tables.
+func (p MergePolicy) conflictResolver(ctx context.Context, sch schema.Schema) (rowConflictResolver, error) {
+	if schema.IsKeyless(sch) {
+		return nil, nil
+	}
+
+	switch p.Strategy {
+	case MergeStrategyOurs:
+		return func(context.Context, types.Value, types.Value, types.Value) (bool, bool, error) {
+			return false, true, nil
+		}, nil
+	case MergeStrategyTheirs:
+		return func(context.Context, types.Value, types.Value, types.Value) (bool, bool, error) {
+			return true, true, nil
+		}, nil
+	case MergeStrategySql:
+		expr, err := p.parseExpression(ctx, sch)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context, key, ourRow, theirRow types.Value) (bool, bool, error) {
+			// a deleted row has no values to evaluate the expression against
+			if types.IsNull(ourRow) || types.IsNull(theirRow) {
+				return false, false, nil
+			}
+
+			return p.evalSqlStrategy(ctx, expr, sch, key.(types.Tuple), ourRow.(types.Tuple), theirRow.(types.Tuple))
+		}, nil
+	}
+
+	return nil, p.validate()
+}
+
+// parseExpression parses the policy's expression for a table with the schema |sch|. The expression can reference the
+// columns of our and their versions of a conflicting row as `ours`.<column> and `theirs`.<column>, and is evaluated
+// against a row holding the columns of our row followed by the columns of their row.
+func (p MergePolicy) parseExpression(ctx context.Context, sch schema.Schema) (sql.Expression, error) {
+	numCols := sch.GetAllCols().Size()
+	expr, err := sqlutil.ParseExpression(ctx, p.Expression, func(col *expression.UnresolvedColumn) (sql.Expression, error) {
+		var field sql.Expression
+		switch strings.ToLower(col.Table()) {
+		case policyOursTableName:
+			field = sqlutil.ColumnGetField(sch, col.Name(), 0)
+		case policyTheirsTableName:
+			field = sqlutil.ColumnGetField(sch, col.Name(), numCols)
+		default:
+			return nil, fmt.Errorf("merge policy for table '%s' references the column '%s', which is not a column of '%s' or '%s'",
+				p.TableName, col.String(), policyOursTableName, policyTheirsTableName)
+		}
+
+		if field == nil {
+			return nil, fmt.Errorf("merge policy for table '%s' references unknown column '%s'", p.TableName, col.String())
+		}
+
+		return field, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid merge policy expression for table '%s': %w", p.TableName, err)
+	}
+
+	if !expr.Resolved() {
+		return nil, fmt.Errorf("merge policy expression for table '%s' is not supported: '%s'", p.TableName, p.Expression)
+	}
+
+	return expr, nil
+}
+
+// evalSqlStrategy evaluates the policy's expression |expr| against our and their versions of a conflicting row. The
+// expression names the winning side by evaluating to 'ours' or 'theirs'. If it evaluates to NULL the conflict is left
+// unresolved, and any other result is an error.
+func (p MergePolicy) evalSqlStrategy(ctx context.Context, expr sql.Expression, sch schema.Schema, key, ourVal, theirVal types.Tuple) (useTheirs bool, resolved bool, err error) {
+	ourRow, err := nomsToSqlRow(sch, key, ourVal)
+	if err != nil {
+		return false, false, err
+	}
+
+	theirRow, err := nomsToSqlRow(sch, key, theirVal)
+	if err != nil {
+		return false, false, err
+	}
+
+	sqlCtx, ok := ctx.(*sql.Context)
+	if !ok {
+		sqlCtx = sql.NewContext(ctx)
+	}
+
+	result, err := expr.Eval(sqlCtx, append(ourRow, theirRow...))
+	if err != nil {
+		return false, false, fmt.Errorf("error evaluating merge policy for table '%s': %w", p.TableName, err)
+	}
+
+	if result == nil {
+		return false, false, nil
+	}
+
+	if s, ok := result.(string); ok {
+		switch strings.ToLower(s) {
+		case MergeStrategyOurs:
+			return false, true, nil
+		case MergeStrategyTheirs:
+			return true, true, nil
+		}
+	}
+
+	return false, false, fmt.Errorf("merge policy expression for table '%s' must return '%s' or '%s', but returned '%v'",
+		p.TableName, MergeStrategyOurs, MergeStrategyTheirs, result)
+}
+
+func nomsToSqlRow(sch schema.Schema, key, val types.Tuple) (sql.Row, error) {
+	r, err := row.FromNoms(sch, key, val)
+	if err != nil {
+		return nil, err
+	}
+
+	return sqlutil.DoltRowToSqlRow(r, sch)
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+func TestMergePolicyConflictResolver(t *testing.T) {
+	sch := schema.MustSchemaFromCols(schema.NewColCollection(
+		schema.NewColumn("pk", 0, types.IntKind, true, schema.NotNullConstraint{}),
+		schema.NewColumn("v", 1, types.IntKind, false),
+		schema.NewColumn("name", 2, types.StringKind, false),
+	))
+
+	key := mustTuple(types.NewTuple(types.Format_Default, types.Uint(0), types.Int(1)))
+	valTuple := func(v int64, name string) types.Value {
+		val, err := row.TaggedValues{1: types.Int(v), 2: types.String(name)}.NomsTupleForNonPKCols(types.Format_Default, sch.GetNonPKCols()).Value(context.Background())
+		require.NoError(t, err)
+		return val
+	}
+
+	tests := []struct {
+		name      string
+		policy    MergePolicy
+		ours      types.Value
+		theirs    types.Value
+		useTheirs bool
+		resolved  bool
+	}{
+		{"ours", MergePolicy{"t", MergeStrategyOurs, ""}, valTuple(1, "a"), valTuple(2, "b"), false, true},
+		{"theirs", MergePolicy{"t", MergeStrategyTheirs, ""}, valTuple(1, "a"), valTuple(2, "b"), true, true},
+		{"theirs deleted", MergePolicy{"t", MergeStrategyTheirs, ""}, valTuple(1, "a"), nil, true, true},
+		{"named ours", MergePolicy{"t", MergeStrategySql, "IF(ours.v >= theirs.v, 'ours', 'theirs')"}, valTuple(3, "a"), valTuple(2, "b"), false, true},
+		{"named theirs", MergePolicy{"t", MergeStrategySql, "IF(ours.v >= theirs.v, 'ours', 'theirs')"}, valTuple(1, "a"), valTuple(2, "b"), true, true},
+		{"equal values", MergePolicy{"t", MergeStrategySql, "IF(theirs.v > ours.v, 'theirs', 'ours')"}, valTuple(2, "a"), valTuple(2, "b"), false, true},
+		{"named by string column", MergePolicy{"t", MergeStrategySql, "IF(theirs.name = 'b', 'theirs', 'ours')"}, valTuple(1, "a"), valTuple(2, "b"), true, true},
+		{"null result", MergePolicy{"t", MergeStrategySql, "IF(theirs.v > ours.v, 'theirs', NULL)"}, valTuple(3, "a"), valTuple(2, "b"), false, false},
+		{"deleted row", MergePolicy{"t", MergeStrategySql, "IF(theirs.v > ours.v, 'theirs', 'ours')"}, nil, valTuple(2, "b"), false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resolver, err := test.policy.conflictResolver(context.Background(), sch)
+			require.NoError(t, err)
+
+			useTheirs, resolved, err := resolver(context.Background(), key, test.ours, test.theirs)
+			require.NoError(t, err)
+			assert.Equal(t, test.resolved, resolved)
+			assert.Equal(t, test.useTheirs, useTheirs)
+		})
+	}
+}
+
+func TestMergePolicySqlErrors(t *testing.T) {
+	sch := schema.MustSchemaFromCols(schema.NewColCollection(
+		schema.NewColumn("pk", 0, types.IntKind, true, schema.NotNullConstraint{}),
+		schema.NewColumn("v", 1, types.IntKind, false),
+	))
+
+	for _, expr := range []string{"IF(v > 1, 'ours', 'theirs')", "IF(ours.missing > 1, 'ours', 'theirs')", "other.v"} {
+		_, err := MergePolicy{"t", MergeStrategySql, expr}.conflictResolver(context.Background(), sch)
+		assert.Error(t, err, expr)
+	}
+
+	key := mustTuple(types.NewTuple(types.Format_Default, types.Uint(0), types.Int(1)))
+	val, err := row.TaggedValues{1: types.Int(1)}.NomsTupleForNonPKCols(types.Format_Default, sch.GetNonPKCols()).Value(context.Background())
+	require.NoError(t, err)
+	resolver, err := MergePolicy{"t", MergeStrategySql, "GREATEST(ours.v, theirs.v)"}.conflictResolver(context.Background(), sch)
+	require.NoError(t, err)
+	_, _, err = resolver(context.Background(), key, val, val)
+	assert.Error(t, err)
+}
+
+func TestMergePolicyValidate(t *testing.T) {
+	assert.NoError(t, MergePolicy{"t", MergeStrategyOurs, ""}.validate())
+	assert.NoError(t, MergePolicy{"t", MergeStrategySql, "ours.v"}.validate())
+	assert.Error(t, MergePolicy{"t", MergeStrategySql, " "}.validate())
+	assert.Error(t, MergePolicy{"t", "newest", ""}.validate())
+}
@@ -34,22 +34,35 @@ type SchemaConflict struct {
 	TableName    string
 	ColConflicts []ColConflict
 	IdxConflicts []IdxConflict
+	FKConflicts  []FKConflict
 }
 
 var EmptySchConflicts = SchemaConflict{}
 
 func (sc SchemaConflict) Count() int {
-	return len(sc.ColConflicts) + len(sc.IdxConflicts)
+	return len(sc.ColConflicts) + len(sc.IdxConflicts) + len(sc.FKConflicts)
 }
 
-func (sc SchemaConflict) AsError() error {
-	var b strings.Builder
-	b.WriteString(fmt.Sprintf("schema conflicts for table %s:\n", sc.TableName))
+// Descriptions returns a human readable description of each conflict.
+func (sc SchemaConflict) Descriptions() []string {
+	descs := make([]string, 0, sc.Count())
 	for _, c := range sc.ColConflicts {
-		b.WriteString(fmt.Sprintf("\t%s\n", c.String()))
+		descs = append(descs, c.String())
 	}
 	for _, c := range sc.IdxConflicts {
-		b.WriteString(fmt.Sprintf("\t%s\n", c.String()))
+		descs = append(descs, c.String())
+	}
+	for _, c := range sc.FKConflicts {
+		descs = append(descs, c.String())
+	}
+	return descs
+}
+
+func (sc SchemaConflict) AsError() error {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("schema conflicts for table %s:\n", sc.TableName))
+	for _, desc := range sc.Descriptions() {
+		b.WriteString(fmt.Sprintf("\t%s\n", desc))
 	}
 	return fmt.Errorf(b.String())
 }
@@ -75,6 +88,12 @@ type IdxConflict struct {
 }
 
 func (c IdxConflict) String() string {
+	switch c.Kind {
+	case NameCollision:
+		return fmt.Sprintf("two indexes with the name '%s'", c.Ours.Name())
+	case TagCollision:
+		return fmt.Sprintf("different index definitions for our index %s and their index %s", c.Ours.Name(), c.Theirs.Name())
+	}
 	return ""
 }
 
@@ -83,6 +102,16 @@ type FKConflict struct {
 	Ours, Theirs doltdb.ForeignKey
 }
 
+func (c FKConflict) String() string {
+	switch c.Kind {
+	case NameCollision:
+		return fmt.Sprintf("two foreign keys with the name '%s'", c.Ours.Name)
+	case TagCollision:
+		return fmt.Sprintf("different foreign key definitions for our foreign key %s and their foreign key %s", c.Ours.Name, c.Theirs.Name)
+	}
+	return ""
+}
+
 // SchemaMerge performs a three-way merge of ourSch, theirSch, and ancSch.
 func SchemaMerge(ourSch, theirSch, ancSch schema.Schema, tblName string) (sch schema.Schema, sc SchemaConflict, err error) {
 	// (sch - ancSch) ∪ (mergeSch - ancSch) ∪ (sch ∩ mergeSch)
@@ -114,10 +143,49 @@ func SchemaMerge(ourSch, theirSch, ancSch schema.Schema, tblName string) (sch sc
 		sch.Indexes().AddIndex(index)
 		return false, nil
 	})
+	sch.Checks().AddChecks(mergeChecks(ourSch.Checks(), theirSch.Checks(), ancSch.Checks())...)
 
 	return sch, sc, nil
 }
 
+// mergeChecks performs a three-way merge of the check constraints of a table. Checks added or modified on either
+// branch are kept, and checks dropped on either branch are dropped. If both branches define a check with the same name
+// differently, our definition is kept. Merged rows are validated against the result, so a check added on one branch
+// can leave rows from the other branch in conflict.
+func mergeChecks(ours, theirs, anc schema.CheckCollection) []schema.Check {
+	var merged []schema.Check
+	for _, ourCheck := range ours.AllChecks() {
+		ancCheck, inAnc := anc.GetByNameCaseInsensitive(ourCheck.Name())
+		theirCheck, inTheirs := theirs.GetByNameCaseInsensitive(ourCheck.Name())
+		switch {
+		case inAnc && !inTheirs && checksEqual(ourCheck, ancCheck):
+			// dropped on their branch
+		case inAnc && inTheirs && checksEqual(ourCheck, ancCheck):
+			merged = append(merged, theirCheck)
+		default:
+			merged = append(merged, ourCheck)
+		}
+	}
+
+	for _, theirCheck := range theirs.AllChecks() {
+		if _, ok := ours.GetByNameCaseInsensitive(theirCheck.Name()); ok {
+			continue
+		}
+		ancCheck, inAnc := anc.GetByNameCaseInsensitive(theirCheck.Name())
+		if inAnc && checksEqual(theirCheck, ancCheck) {
+			// dropped on our branch
+			continue
+		}
+		merged = append(merged, theirCheck)
+	}
+
+	return merged
+}
+
+func checksEqual(a, b schema.Check) bool {
+	return a.Expression() == b.Expression() && a.Enforced() == b.Enforced()
+}
+
 // ForeignKeysMerge performs a three-way merge of (ourRoot, theirRoot, ancRoot) and using mergeRoot to validate FKs.
 func ForeignKeysMerge(ctx context.Context, mergedRoot, ourRoot, theirRoot, ancRoot *doltdb.RootValue) (*doltdb.ForeignKeyCollection, []FKConflict, error) {
 	ours, err := ourRoot.GetForeignKeyCollection(ctx)
@@ -175,12 +243,25 @@ func ForeignKeysMerge(ctx context.Context, mergedRoot, ourRoot, theirRoot, ancRo
 		return false, err
 	})
 
+	// when foreign keys conflict, ours are kept until the conflict is resolved
 	err = ourNewFKs.Iter(func(ourFK doltdb.ForeignKey) (stop bool, err error) {
 		return false, common.AddKeys(ourFK)
 	})
+	if err != nil {
+		return nil, nil, err
+	}
+
 	err = theirNewFKs.Iter(func(theirFK doltdb.ForeignKey) (stop bool, err error) {
+		for _, c := range conflicts {
+			if c.Theirs.DeepEquals(theirFK) {
+				return false, nil
+			}
+		}
 		return false, common.AddKeys(theirFK)
 	})
+	if err != nil {
+		return nil, nil, err
+	}
 
 	common, err = pruneInvalidForeignKeys(ctx, common, mergedRoot)
 	if err != nil {
@@ -434,6 +515,7 @@ func foreignKeysInCommon(ourFKs, theirFKs, ancFKs *doltdb.ForeignKeyCollection)
 				Ours:   ours,
 				Theirs: theirs,
 			})
+			return false, common.AddKeys(ours)
 		}
 
 		if theirs.EqualDefs(anc) {
@@ -472,7 +554,7 @@ func foreignKeysInCommon(ourFKs, theirFKs, ancFKs *doltdb.ForeignKeyCollection)
 			Ours:   ours,
 			Theirs: theirs,
 		})
-		return false, nil
+		return false, common.AddKeys(ours)
 	})
 
 	if err != nil {
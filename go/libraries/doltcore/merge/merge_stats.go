@@ -29,4 +29,23 @@ type MergeStats struct {
 	Deletes       int
 	Modifications int
 	Conflicts     int
+	// SchemaConflicts is the number of column, index and foreign key conflicts that prevented the table's schema, and
+	// therefore its rows, from being merged. These are also included in Conflicts.
+	SchemaConflicts int
+	// ResolvedByPolicy is the number of row conflicts that were resolved automatically by the table's merge policy.
+	ResolvedByPolicy int
+	// CheckViolations is the number of rows left in conflict because the merged row violated a check constraint of the
+	// table. These are also included in Conflicts.
+	CheckViolations int
+}
+
+// HasConflicts returns true if the merge of any of the tables in |tblToStats| resulted in conflicts
+func HasConflicts(tblToStats map[string]*MergeStats) bool {
+	for _, stats := range tblToStats {
+		if stats.Conflicts > 0 {
+			return true
+		}
+	}
+
+	return false
 }
@@ -466,3 +466,32 @@ func TestMergeCommits(t *testing.T) {
 		assert.Fail(t, "%v and %v do not equal", h, eh)
 	}
 }
+
+func TestMergeRootsTableDroppedOnOurSide(t *testing.T) {
+	_, commit, mergeCommit, _, _ := setupMergeTest(t)
+	ctx := context.Background()
+
+	ancCm, err := doltdb.GetCommitAncestor(ctx, commit, mergeCommit)
+	require.NoError(t, err)
+	ancRoot, err := ancCm.GetRootValue()
+	require.NoError(t, err)
+
+	root, err := commit.GetRootValue()
+	require.NoError(t, err)
+	root, err = root.RemoveTables(ctx, tableName)
+	require.NoError(t, err)
+
+	merged, stats, err := MergeRoots(ctx, root, ancRoot, ancRoot)
+	require.NoError(t, err)
+
+	has, err := merged.HasTable(ctx, tableName)
+	require.NoError(t, err)
+	assert.False(t, has)
+	require.Contains(t, stats, tableName)
+	assert.Equal(t, TableRemoved, stats[tableName].Operation)
+
+	mergeRoot, err := mergeCommit.GetRootValue()
+	require.NoError(t, err)
+	_, _, err = MergeRoots(ctx, root, mergeRoot, ancRoot)
+	assert.Equal(t, ErrTableDeletedAndModified, err)
+}
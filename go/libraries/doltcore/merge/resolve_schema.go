@@ -0,0 +1,324 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"context"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// maxSchemaResolutionPasses bounds the number of times a SchemaResolver is applied. Index conflicts are only detected
+// once the column conflicts have been resolved, so resolving a table can take more than one pass.
+const maxSchemaResolutionPasses = 3
+
+// SchemaResolver resolves the schema conflicts |sc| between |ourSch| and |theirSch|. It returns versions of the two
+// schemas with the conflicts removed, and the foreign key which should be kept for each of the foreign key conflicts in
+// |sc|, in the same order.
+type SchemaResolver func(sc SchemaConflict, ourSch, theirSch schema.Schema) (schema.Schema, schema.Schema, []doltdb.ForeignKey, error)
+
+// OursSchema resolves schema conflicts by taking our version of each conflicting column, index and foreign key.
+func OursSchema(sc SchemaConflict, ourSch, theirSch schema.Schema) (schema.Schema, schema.Schema, []doltdb.ForeignKey, error) {
+	var theirCols []ColConflict
+	var theirIdxs []IdxConflict
+	for _, c := range sc.ColConflicts {
+		theirCols = append(theirCols, ColConflict{Kind: c.Kind, Ours: c.Theirs, Theirs: c.Ours})
+	}
+	for _, c := range sc.IdxConflicts {
+		theirIdxs = append(theirIdxs, IdxConflict{Kind: c.Kind, Ours: c.Theirs, Theirs: c.Ours})
+	}
+
+	theirSch, err := resolveSchemaTo(theirSch, theirCols, theirIdxs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	fks := make([]doltdb.ForeignKey, len(sc.FKConflicts))
+	for i, c := range sc.FKConflicts {
+		fks[i] = c.Ours
+	}
+
+	return ourSch, theirSch, fks, nil
+}
+
+// TheirsSchema resolves schema conflicts by taking their version of each conflicting column, index and foreign key.
+func TheirsSchema(sc SchemaConflict, ourSch, theirSch schema.Schema) (schema.Schema, schema.Schema, []doltdb.ForeignKey, error) {
+	ourSch, err := resolveSchemaTo(ourSch, sc.ColConflicts, sc.IdxConflicts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	fks := make([]doltdb.ForeignKey, len(sc.FKConflicts))
+	for i, c := range sc.FKConflicts {
+		fks[i] = c.Theirs
+	}
+
+	return ourSch, theirSch, fks, nil
+}
+
+// resolveSchemaTo rewrites |sch|, whose versions of the conflicting columns and indexes are the Ours side of the given
+// conflicts, to use the Theirs side instead. Columns and indexes which collide by name are removed from |sch|.
+func resolveSchemaTo(sch schema.Schema, colConflicts []ColConflict, idxConflicts []IdxConflict) (schema.Schema, error) {
+	if len(colConflicts) == 0 && len(idxConflicts) == 0 {
+		return sch, nil
+	}
+
+	replaced := make(map[uint64]schema.Column)
+	removed := make(map[uint64]bool)
+	for _, c := range colConflicts {
+		switch c.Kind {
+		case TagCollision:
+			replaced[c.Ours.Tag] = c.Theirs
+		case NameCollision:
+			removed[c.Ours.Tag] = true
+		}
+	}
+
+	cols := schema.FilterColCollection(sch.GetAllCols(), func(col schema.Column) bool {
+		return !removed[col.Tag]
+	})
+	cols = schema.MapColCollection(cols, func(col schema.Column) schema.Column {
+		if newCol, ok := replaced[col.Tag]; ok {
+			return newCol
+		}
+		return col
+	})
+
+	resolved, err := schema.SchemaFromCols(cols)
+	if err != nil {
+		return nil, err
+	}
+
+	// keep the indexes whose columns still exist
+	err = sch.Indexes().Iter(func(idx schema.Index) (stop bool, err error) {
+		for _, tag := range idx.IndexedColumnTags() {
+			if _, ok := cols.GetByTag(tag); !ok {
+				return false, nil
+			}
+		}
+		resolved.Indexes().AddIndex(idx)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resolved.Checks().AddChecks(sch.Checks().AllChecks()...)
+
+	for _, c := range idxConflicts {
+		if _, ok := resolved.Indexes().GetByNameCaseInsensitive(c.Ours.Name()); ok {
+			_, err = resolved.Indexes().RemoveIndex(c.Ours.Name())
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if c.Kind == TagCollision {
+			resolved.Indexes().AddIndex(c.Theirs)
+		}
+	}
+
+	return resolved, nil
+}
+
+// GetSchemaConflicts returns the unresolved schema conflicts of the table |tblName|. Column and index conflicts are
+// recomputed from the current schema of the table, so that changes made to the schema since the merge are taken into
+// account. Returns doltdb.ErrNoConflicts if the table has no schema conflicts.
+func GetSchemaConflicts(ctx context.Context, tblName string, tbl *doltdb.Table) (SchemaConflict, error) {
+	pending, err := tbl.GetSchemaConflicts(ctx)
+	if err != nil {
+		return EmptySchConflicts, err
+	}
+
+	sc := SchemaConflict{TableName: tblName}
+	if pending.Theirs != nil {
+		ourSch, theirSch, ancSch, err := getSchemaConflictSchemas(ctx, tbl, pending)
+		if err != nil {
+			return EmptySchConflicts, err
+		}
+
+		_, sc, err = SchemaMerge(ourSch, theirSch, ancSch, tblName)
+		if err != nil {
+			return EmptySchConflicts, err
+		}
+	}
+
+	for _, c := range pending.ForeignKeys {
+		sc.FKConflicts = append(sc.FKConflicts, FKConflict{Kind: conflictKind(c.Kind), Ours: c.Ours, Theirs: c.Theirs})
+	}
+
+	return sc, nil
+}
+
+// ResolveSchemaConflicts resolves the schema conflicts of the table |tblName| using |resolver|, and then finishes merging
+// the table's rows. Any row conflicts from finishing the merge are recorded on the table in the same way they are for a
+// merge. If |resolver| is nil, the conflicts must already have been resolved by altering the schema of the table, and
+// the foreign keys are left as they are. Returns doltdb.ErrNoConflicts if the table has no schema conflicts.
+func ResolveSchemaConflicts(ctx context.Context, vrw types.ValueReadWriter, tblName string, tbl *doltdb.Table, resolver SchemaResolver, sess *editor.TableEditSession) (*MergeStats, error) {
+	pending, err := tbl.GetSchemaConflicts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sc, err := GetSchemaConflicts(ctx, tblName, tbl)
+	if err != nil {
+		return nil, err
+	}
+
+	var ourSch, theirSch, ancSch schema.Schema
+	if pending.Theirs != nil {
+		ourSch, theirSch, ancSch, err = getSchemaConflictSchemas(ctx, tbl, pending)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var fks []doltdb.ForeignKey
+	if resolver != nil {
+		for i := 0; sc.Count() > 0 && i < maxSchemaResolutionPasses; i++ {
+			var resolvedFKs []doltdb.ForeignKey
+			ourSch, theirSch, resolvedFKs, err = resolver(sc, ourSch, theirSch)
+			if err != nil {
+				return nil, err
+			}
+
+			fks = append(fks, resolvedFKs...)
+			sc = SchemaConflict{TableName: tblName}
+			if pending.Theirs != nil {
+				_, sc, err = SchemaMerge(ourSch, theirSch, ancSch, tblName)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	} else {
+		// foreign key conflicts are resolved by altering the foreign keys directly
+		sc.FKConflicts = nil
+	}
+
+	if sc.Count() > 0 {
+		return nil, sc.AsError()
+	}
+
+	tbl, err = tbl.ClearSchemaConflicts()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fks) > 0 {
+		err = sess.UpdateRoot(ctx, func(ctx context.Context, root *doltdb.RootValue) (*doltdb.RootValue, error) {
+			return resolveForeignKeys(ctx, root, pending.ForeignKeys, fks)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if pending.Theirs == nil {
+		err = sess.UpdateRoot(ctx, func(ctx context.Context, root *doltdb.RootValue) (*doltdb.RootValue, error) {
+			return root.PutTable(ctx, tblName, tbl)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &MergeStats{Operation: TableModified}, nil
+	}
+
+	postMergeSchema, _, err := SchemaMerge(ourSch, theirSch, ancSch, tblName)
+	if err != nil {
+		return nil, err
+	}
+
+	tbl, err = tbl.UpdateSchema(ctx, ourSch)
+	if err != nil {
+		return nil, err
+	}
+
+	theirTbl, err := pending.Theirs.UpdateSchema(ctx, theirSch)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := sess.Flush(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rowResolver rowConflictResolver
+	if policies, err := GetMergePolicies(ctx, root); err != nil {
+		return nil, err
+	} else if p, ok := policies[tblName]; ok {
+		rowResolver, err = p.conflictResolver(ctx, postMergeSchema)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resultTbl, stats, err := mergeTableRows(ctx, vrw, tblName, tbl, theirTbl, pending.Ancestor, postMergeSchema, rowResolver, sess)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sess.UpdateRoot(ctx, func(ctx context.Context, root *doltdb.RootValue) (*doltdb.RootValue, error) {
+		return root.PutTable(ctx, tblName, resultTbl)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// resolveForeignKeys replaces our side of each foreign key conflict in |conflicts| with the corresponding foreign key in
+// |resolved|.
+func resolveForeignKeys(ctx context.Context, root *doltdb.RootValue, conflicts []doltdb.ForeignKeyConflict, resolved []doltdb.ForeignKey) (*doltdb.RootValue, error) {
+	fkc, err := root.GetForeignKeyCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, c := range conflicts {
+		if i >= len(resolved) || c.Ours.DeepEquals(resolved[i]) {
+			continue
+		}
+
+		fkc.RemoveKeys(c.Ours)
+		err = fkc.AddKeys(resolved[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return root.PutForeignKeyCollection(ctx, fkc)
+}
+
+func getSchemaConflictSchemas(ctx context.Context, tbl *doltdb.Table, pending doltdb.SchemaConflicts) (ourSch, theirSch, ancSch schema.Schema, err error) {
+	if ourSch, err = tbl.GetSchema(ctx); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if theirSch, err = pending.Theirs.GetSchema(ctx); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if ancSch, err = pending.Ancestor.GetSchema(ctx); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return ourSch, theirSch, ancSch, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+)
+
+var ErrRevertMergeCommit = errors.New("reverting a merge commit is not supported")
+var ErrRevertInitialCommit = errors.New("cannot revert a commit without a parent")
+
+// Revert undoes the changes introduced by each of |commits|, in order, on top of |root|. Each commit is reverted by
+// three-way merging the current root with the root of the commit's parent, using the root of the commit itself as the
+// ancestor. The returned stats add up the changes made by reverting each of the commits, and the returned string is a
+// commit message describing the revert.
+//
+// Reverting stops at the first commit which conflicts. If it is the first of |commits|, the conflicted root is
+// returned so the conflicts can be resolved. Otherwise the root with the commits before it reverted is returned. In
+// both cases the commits which were not reverted are returned, so that they can be reverted once the result is
+// committed.
+func Revert(ctx context.Context, ddb *doltdb.DoltDB, root *doltdb.RootValue, commits []*doltdb.Commit) (*doltdb.RootValue, map[string]*MergeStats, string, []*doltdb.Commit, error) {
+	var descs []string
+	var hashes []string
+	var notReverted []*doltdb.Commit
+	tblToStats := make(map[string]*MergeStats)
+
+	for i, cm := range commits {
+		cmRoot, parentRoot, err := getCommitAndParentRoots(ctx, ddb, cm)
+
+		if err == errNoParent {
+			return nil, nil, "", nil, ErrRevertInitialCommit
+		} else if err == errMultipleParents {
+			return nil, nil, "", nil, ErrRevertMergeCommit
+		} else if err != nil {
+			return nil, nil, "", nil, err
+		}
+
+		revertedRoot, cmStats, err := MergeRoots(ctx, root, parentRoot, cmRoot)
+
+		if err != nil {
+			return nil, nil, "", nil, err
+		}
+
+		if HasConflicts(cmStats) && i > 0 {
+			notReverted = commits[i:]
+			break
+		}
+
+		h, err := cm.HashOf()
+
+		if err != nil {
+			return nil, nil, "", nil, err
+		}
+
+		meta, err := cm.GetCommitMeta()
+
+		if err != nil {
+			return nil, nil, "", nil, err
+		}
+
+		descs = append(descs, fmt.Sprintf(`"%s"`, FirstLine(meta.Description)))
+		hashes = append(hashes, h.String())
+
+		root = revertedRoot
+		addMergeStats(tblToStats, cmStats)
+
+		if HasConflicts(cmStats) {
+			notReverted = commits[i+1:]
+			break
+		}
+	}
+
+	msg := fmt.Sprintf("Revert %s\n\nThis reverts commit %s.", strings.Join(descs, " and "), strings.Join(hashes, ", "))
+
+	return root, tblToStats, msg, notReverted, nil
+}
+
+// addMergeStats adds the stats of a merge to |tblToStats|, the stats of the merges which preceded it
+func addMergeStats(tblToStats, stats map[string]*MergeStats) {
+	for tblName, ms := range stats {
+		total, ok := tblToStats[tblName]
+
+		if !ok {
+			msCopy := *ms
+			tblToStats[tblName] = &msCopy
+			continue
+		}
+
+		total.Operation = combineTableMergeOps(total.Operation, ms.Operation)
+		total.Adds += ms.Adds
+		total.Deletes += ms.Deletes
+		total.Modifications += ms.Modifications
+		total.Conflicts += ms.Conflicts
+		total.SchemaConflicts += ms.SchemaConflicts
+		total.ResolvedByPolicy += ms.ResolvedByPolicy
+		total.CheckViolations += ms.CheckViolations
+	}
+}
+
+// combineTableMergeOps returns the operation on a table of two merges applied one after the other
+func combineTableMergeOps(first, second TableMergeOp) TableMergeOp {
+	switch {
+	case second == TableUnmodified:
+		return first
+	case first == TableUnmodified:
+		return second
+	case first == TableAdded && second == TableRemoved:
+		return TableUnmodified
+	case first == TableAdded:
+		return TableAdded
+	case first == TableRemoved && second == TableAdded:
+		return TableModified
+	default:
+		return second
+	}
+}
+
+// FirstLine returns the first line of |s|, such as the summary line of a commit message
+func FirstLine(s string) string {
+	if idx := strings.IndexByte(s, '\n'); idx != -1 {
+		return s[:idx]
+	}
+
+	return s
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddMergeStats(t *testing.T) {
+	tblToStats := make(map[string]*MergeStats)
+
+	addMergeStats(tblToStats, map[string]*MergeStats{
+		"a": {Operation: TableModified, Adds: 1, Deletes: 2},
+		"b": {Operation: TableAdded, Adds: 3},
+		"c": {Operation: TableUnmodified},
+	})
+	addMergeStats(tblToStats, map[string]*MergeStats{
+		"a": {Operation: TableModified, Deletes: 1, Modifications: 4},
+		"b": {Operation: TableModified, Modifications: 1},
+		"c": {Operation: TableRemoved},
+		"d": {Operation: TableModified, Conflicts: 2},
+	})
+
+	assert.Equal(t, &MergeStats{Operation: TableModified, Adds: 1, Deletes: 3, Modifications: 4}, tblToStats["a"])
+	assert.Equal(t, &MergeStats{Operation: TableAdded, Adds: 3, Modifications: 1}, tblToStats["b"])
+	assert.Equal(t, &MergeStats{Operation: TableRemoved}, tblToStats["c"])
+	assert.Equal(t, &MergeStats{Operation: TableModified, Conflicts: 2}, tblToStats["d"])
+
+	assert.Equal(t, TableUnmodified, combineTableMergeOps(TableAdded, TableRemoved))
+	assert.Equal(t, TableModified, combineTableMergeOps(TableRemoved, TableAdded))
+}
@@ -0,0 +1,501 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rebase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions/commitwalk"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+)
+
+const (
+	PickAction   = "pick"
+	SquashAction = "squash"
+	RewordAction = "reword"
+	DropAction   = "drop"
+)
+
+var ErrRebaseInProgress = errors.New("a rebase is already in progress")
+var ErrNoRebaseInProgress = errors.New("no rebase in progress")
+var ErrRebaseUnresolvedConflicts = errors.New("cannot continue the rebase while there are unresolved conflicts")
+var ErrRebaseUnstagedChanges = errors.New("cannot continue the rebase while there are unstaged changes")
+var ErrSquashWithoutPrevious = errors.New("cannot squash without a previous commit")
+var ErrRebaseOtherBranch = errors.New("the branch being rebased is not checked out")
+var ErrRebaseMergeCommit = errors.New("cannot rebase a merge commit")
+
+var actionAbbreviations = map[string]string{
+	"p": PickAction,
+	"s": SquashAction,
+	"r": RewordAction,
+	"d": DropAction,
+}
+
+// RewordFn is called to get the new commit message for a commit being reworded. |msg| is the original message.
+type RewordFn func(ctx context.Context, msg string) (string, error)
+
+// Stop describes a rebase step that could not be applied cleanly. The conflicts have been written to the working root
+// and the rebase can be resumed with Continue once they are resolved.
+type Stop struct {
+	Step  env.RebaseStep
+	Stats map[string]*merge.MergeStats
+}
+
+// BuildTodo returns the steps needed to replay the commits reachable from |head| that are not reachable from
+// |upstream|, in topological order with every commit picked. Merge commits can't be replayed, so ErrRebaseMergeCommit
+// is returned if any of those commits is a merge.
+func BuildTodo(ctx context.Context, ddb *doltdb.DoltDB, head, upstream *doltdb.Commit) ([]env.RebaseStep, error) {
+	headHash, err := head.HashOf()
+	if err != nil {
+		return nil, err
+	}
+
+	upstreamHash, err := upstream.HashOf()
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := commitwalk.GetDotDotRevisions(ctx, ddb, headHash, ddb, upstreamHash, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	todo := make([]env.RebaseStep, len(commits))
+	for i, cm := range commits {
+		h, err := cm.HashOf()
+		if err != nil {
+			return nil, err
+		}
+
+		numParents, err := cm.NumParents()
+		if err != nil {
+			return nil, err
+		}
+
+		if numParents > 1 {
+			return nil, fmt.Errorf("%w: %s", ErrRebaseMergeCommit, h.String())
+		}
+
+		todo[len(commits)-1-i] = env.RebaseStep{Action: PickAction, Commit: h.String()}
+	}
+
+	return todo, nil
+}
+
+// FormatTodo returns the text of a todo file listing |todo|, one step per line followed by the first line of the
+// commit message.
+func FormatTodo(ctx context.Context, ddb *doltdb.DoltDB, todo []env.RebaseStep) (string, error) {
+	sb := strings.Builder{}
+	for _, step := range todo {
+		cm, err := resolveHash(ctx, ddb, step.Commit)
+		if err != nil {
+			return "", err
+		}
+
+		meta, err := cm.GetCommitMeta()
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", step.Action, step.Commit, merge.FirstLine(meta.Description)))
+	}
+
+	return sb.String(), nil
+}
+
+// ParseTodo parses the text of a todo file. Each line holds an action, a commit and an optional description which is
+// ignored. Empty lines and lines starting with '#' are skipped.
+func ParseTodo(ctx context.Context, ddb *doltdb.DoltDB, text string) ([]env.RebaseStep, error) {
+	var todo []env.RebaseStep
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected an action and a commit: '%s'", i+1, line)
+		}
+
+		action := strings.ToLower(fields[0])
+		if full, ok := actionAbbreviations[action]; ok {
+			action = full
+		}
+
+		switch action {
+		case PickAction, SquashAction, RewordAction, DropAction:
+		default:
+			return nil, fmt.Errorf("line %d: unknown action '%s'", i+1, fields[0])
+		}
+
+		cs, err := doltdb.NewCommitSpec(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid commit '%s'", i+1, fields[1])
+		}
+
+		cm, err := ddb.Resolve(ctx, cs, nil)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: could not resolve commit '%s': %w", i+1, fields[1], err)
+		}
+
+		h, err := cm.HashOf()
+		if err != nil {
+			return nil, err
+		}
+
+		todo = append(todo, env.RebaseStep{Action: action, Commit: h.String()})
+	}
+
+	for _, step := range todo {
+		if step.Action == SquashAction {
+			return nil, ErrSquashWithoutPrevious
+		} else if step.Action != DropAction {
+			break
+		}
+	}
+
+	return todo, nil
+}
+
+// Start moves the current branch to |upstream| and replays the steps in |todo| on top of it. The working set is
+// expected to be clean. If a step results in conflicts the rebase stops and the returned *Stop describes the step
+// that needs to be resolved. Returns a nil *Stop once all steps have been applied.
+func Start(ctx context.Context, dEnv *env.DoltEnv, upstream *doltdb.Commit, todo []env.RebaseStep, reword RewordFn) (*Stop, error) {
+	if dEnv.IsRebaseActive() {
+		return nil, ErrRebaseInProgress
+	}
+
+	branch := dEnv.RepoState.CWBHeadRef()
+	head, err := dEnv.DoltDB.ResolveRef(ctx, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	origHead, err := head.HashOf()
+	if err != nil {
+		return nil, err
+	}
+
+	onto, err := upstream.HashOf()
+	if err != nil {
+		return nil, err
+	}
+
+	err = dEnv.RepoState.StartRebase(branch, origHead.String(), onto.String(), todo, dEnv.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	err = resetBranch(ctx, dEnv, branch, upstream)
+	if err != nil {
+		return nil, err
+	}
+
+	return run(ctx, dEnv, reword)
+}
+
+// Continue commits the resolution of the step the rebase stopped at and replays the remaining steps.
+func Continue(ctx context.Context, dEnv *env.DoltEnv, reword RewordFn) (*Stop, error) {
+	if !dEnv.IsRebaseActive() {
+		return nil, ErrNoRebaseInProgress
+	}
+
+	if !isRebaseBranchCheckedOut(dEnv) {
+		return nil, ErrRebaseOtherBranch
+	}
+
+	ddb := dEnv.DoltDB
+	working, staged, head, err := env.GetRoots(ctx, ddb, dEnv.RepoStateReader())
+	if err != nil {
+		return nil, err
+	}
+
+	if has, err := working.HasConflicts(ctx); err != nil {
+		return nil, err
+	} else if has {
+		return nil, ErrRebaseUnresolvedConflicts
+	}
+
+	if eq, err := rootsEqual(working, staged); err != nil {
+		return nil, err
+	} else if !eq {
+		return nil, ErrRebaseUnstagedChanges
+	}
+
+	todo := dEnv.RepoState.Rebase.Todo
+	if len(todo) > 0 {
+		if eq, err := rootsEqual(staged, head); err != nil {
+			return nil, err
+		} else if !eq {
+			err = commitStep(ctx, dEnv, todo[0], staged, reword)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = popStep(dEnv)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return run(ctx, dEnv, reword)
+}
+
+// Abort stops the rebase in progress and restores the branch being rebased to the commit it pointed at before the
+// rebase started. The working and staged roots are reset to that commit's root if the branch is checked out.
+func Abort(ctx context.Context, dEnv *env.DoltEnv) error {
+	if !dEnv.IsRebaseActive() {
+		return ErrNoRebaseInProgress
+	}
+
+	origHead, err := resolveHash(ctx, dEnv.DoltDB, dEnv.RepoState.Rebase.OrigHead)
+	if err != nil {
+		return err
+	}
+
+	branch := dEnv.RepoState.Rebase.Branch.Ref
+	if isRebaseBranchCheckedOut(dEnv) {
+		err = resetBranch(ctx, dEnv, branch, origHead)
+	} else {
+		err = dEnv.DoltDB.SetHeadToCommit(ctx, branch, origHead)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return dEnv.RepoState.ClearRebase(dEnv.FS)
+}
+
+// run applies the steps remaining in the rebase state one at a time until they have all been applied, or until one
+// of them results in conflicts.
+func run(ctx context.Context, dEnv *env.DoltEnv, reword RewordFn) (*Stop, error) {
+	ddb := dEnv.DoltDB
+	branch := dEnv.RepoState.Rebase.Branch.Ref
+	for len(dEnv.RepoState.Rebase.Todo) > 0 {
+		step := dEnv.RepoState.Rebase.Todo[0]
+
+		if step.Action != DropAction {
+			cm, err := resolveHash(ctx, ddb, step.Commit)
+			if err != nil {
+				return nil, err
+			}
+
+			head, err := ddb.ResolveRef(ctx, branch)
+			if err != nil {
+				return nil, err
+			}
+
+			headRoot, err := head.GetRootValue()
+			if err != nil {
+				return nil, err
+			}
+
+			mergedRoot, tblToStats, err := merge.CherryPick(ctx, ddb, headRoot, cm)
+			if err != nil {
+				return nil, fmt.Errorf("could not apply %s: %w", step.Commit, err)
+			}
+
+			if merge.HasConflicts(tblToStats) {
+				_, err = env.UpdateWorkingRoot(ctx, ddb, dEnv.RepoStateWriter(), mergedRoot)
+				if err != nil {
+					return nil, err
+				}
+
+				_, err = env.UpdateStagedRoot(ctx, ddb, dEnv.RepoStateWriter(), headRoot)
+				if err != nil {
+					return nil, err
+				}
+
+				return &Stop{Step: step, Stats: tblToStats}, nil
+			}
+
+			err = commitStep(ctx, dEnv, step, mergedRoot, reword)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err := popStep(dEnv)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	head, err := ddb.ResolveRef(ctx, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	err = resetBranch(ctx, dEnv, branch, head)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, dEnv.RepoState.ClearRebase(dEnv.FS)
+}
+
+// commitStep records |root| as the result of |step| and moves the branch being rebased to the new commit. Picked and
+// reworded commits are added on top of the branch, while squashed commits are combined with the last commit made by
+// the rebase. A squashed commit is picked instead when the rebase hasn't made a commit yet, because every step before
+// it was dropped or already present on the branch, so that the commit the rebase is onto is never rewritten. A commit
+// whose changes are already present on the branch is skipped.
+func commitStep(ctx context.Context, dEnv *env.DoltEnv, step env.RebaseStep, root *doltdb.RootValue, reword RewordFn) error {
+	ddb := dEnv.DoltDB
+	branch := dEnv.RepoState.Rebase.Branch.Ref
+	head, err := ddb.ResolveRef(ctx, branch)
+	if err != nil {
+		return err
+	}
+
+	headHash, err := head.HashOf()
+	if err != nil {
+		return err
+	}
+
+	headRoot, err := head.GetRootValue()
+	if err != nil {
+		return err
+	}
+
+	action := step.Action
+	if action == SquashAction && headHash.String() == dEnv.RepoState.Rebase.Onto {
+		action = PickAction
+	}
+
+	if eq, err := rootsEqual(root, headRoot); err != nil {
+		return err
+	} else if eq && action != SquashAction {
+		return nil
+	}
+
+	cm, err := resolveHash(ctx, ddb, step.Commit)
+	if err != nil {
+		return err
+	}
+
+	meta, err := cm.GetCommitMeta()
+	if err != nil {
+		return err
+	}
+
+	msg := meta.Description
+	parents := []*doltdb.Commit{head}
+
+	switch action {
+	case RewordAction:
+		newMsg, err := reword(ctx, msg)
+		if err != nil {
+			return err
+		}
+
+		if len(strings.TrimSpace(newMsg)) > 0 {
+			msg = strings.TrimSpace(newMsg)
+		}
+	case SquashAction:
+		headMeta, err := head.GetCommitMeta()
+		if err != nil {
+			return err
+		}
+
+		msg = headMeta.Description + "\n\n" + msg
+		parents, err = ddb.ResolveAllParents(ctx, head)
+		if err != nil {
+			return err
+		}
+
+		meta = headMeta
+	}
+
+	newMeta, err := doltdb.NewCommitMetaWithUserTS(meta.Name, meta.Email, msg, doltdb.CommitNowFunc())
+	if err != nil {
+		return err
+	}
+
+	h, err := ddb.WriteRootValue(ctx, root)
+	if err != nil {
+		return err
+	}
+
+	newCm, err := ddb.WriteDanglingCommit(ctx, h, parents, newMeta)
+	if err != nil {
+		return err
+	}
+
+	return ddb.SetHeadToCommit(ctx, branch, newCm)
+}
+
+// resetBranch points |branch| at |cm| and resets the working and staged roots to its root.
+func resetBranch(ctx context.Context, dEnv *env.DoltEnv, branch ref.DoltRef, cm *doltdb.Commit) error {
+	err := dEnv.DoltDB.SetHeadToCommit(ctx, branch, cm)
+	if err != nil {
+		return err
+	}
+
+	root, err := cm.GetRootValue()
+	if err != nil {
+		return err
+	}
+
+	_, err = env.UpdateWorkingRoot(ctx, dEnv.DoltDB, dEnv.RepoStateWriter(), root)
+	if err != nil {
+		return err
+	}
+
+	_, err = env.UpdateStagedRoot(ctx, dEnv.DoltDB, dEnv.RepoStateWriter(), root)
+	return err
+}
+
+// isRebaseBranchCheckedOut returns whether the branch being rebased is the current branch, which the working and
+// staged roots belong to
+func isRebaseBranchCheckedOut(dEnv *env.DoltEnv) bool {
+	return ref.Equals(dEnv.RepoState.Rebase.Branch.Ref, dEnv.RepoState.CWBHeadRef())
+}
+
+func popStep(dEnv *env.DoltEnv) error {
+	dEnv.RepoState.Rebase.Todo = dEnv.RepoState.Rebase.Todo[1:]
+	return dEnv.RepoState.Save(dEnv.FS)
+}
+
+func resolveHash(ctx context.Context, ddb *doltdb.DoltDB, h string) (*doltdb.Commit, error) {
+	cs, err := doltdb.NewCommitSpec(h)
+	if err != nil {
+		return nil, err
+	}
+
+	return ddb.Resolve(ctx, cs, nil)
+}
+
+func rootsEqual(r1, r2 *doltdb.RootValue) (bool, error) {
+	h1, err := r1.HashOf()
+	if err != nil {
+		return false, err
+	}
+
+	h2, err := r2.HashOf()
+	if err != nil {
+		return false, err
+	}
+
+	return h1 == h2, nil
+}
@@ -0,0 +1,217 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rebase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
+	tc "github.com/dolthub/dolt/go/libraries/doltcore/dtestutils/testcommands"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/rebase"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+)
+
+// setupRebaseTest creates a repo where feature has the commits "insert 3" and "insert 4" on top of the initial commit,
+// master has the commit "insert 5", and feature is checked out
+func setupRebaseTest(t *testing.T, extraMasterQuery string) *env.DoltEnv {
+	dEnv := dtestutils.CreateTestEnv()
+	cmds := []tc.Command{
+		tc.Query{Query: "create table test (pk int primary key, c1 int);"},
+		tc.Query{Query: "insert into test values (1,1),(2,2);"},
+		tc.CommitAll{Message: "added table"},
+		tc.Branch{BranchName: "feature"},
+		tc.Checkout{BranchName: "feature"},
+		tc.Query{Query: "insert into test values (3,3);"},
+		tc.CommitAll{Message: "insert 3"},
+		tc.Query{Query: "insert into test values (4,4);"},
+		tc.CommitAll{Message: "insert 4"},
+		tc.Checkout{BranchName: "master"},
+		tc.Query{Query: "insert into test values (5,5);"},
+		tc.CommitAll{Message: "insert 5"},
+	}
+
+	if extraMasterQuery != "" {
+		cmds = append(cmds, tc.Query{Query: extraMasterQuery}, tc.CommitAll{Message: "more changes on master"})
+	}
+
+	cmds = append(cmds, tc.Checkout{BranchName: "feature"})
+
+	for _, cmd := range cmds {
+		require.NoError(t, cmd.Exec(t, dEnv), cmd.CommandString())
+	}
+
+	return dEnv
+}
+
+func resolveBranch(t *testing.T, dEnv *env.DoltEnv, name string) *doltdb.Commit {
+	cm, err := dEnv.DoltDB.ResolveRef(context.Background(), ref.NewBranchRef(name))
+	require.NoError(t, err)
+	return cm
+}
+
+func commitHash(t *testing.T, cm *doltdb.Commit) string {
+	h, err := cm.HashOf()
+	require.NoError(t, err)
+	return h.String()
+}
+
+func commitMessages(t *testing.T, dEnv *env.DoltEnv, cm *doltdb.Commit) []string {
+	var msgs []string
+	for {
+		meta, err := cm.GetCommitMeta()
+		require.NoError(t, err)
+		msgs = append(msgs, meta.Description)
+
+		n, err := cm.NumParents()
+		require.NoError(t, err)
+		if n == 0 {
+			return msgs
+		}
+
+		cm, err = dEnv.DoltDB.ResolveParent(context.Background(), cm, 0)
+		require.NoError(t, err)
+	}
+}
+
+func startRebase(t *testing.T, dEnv *env.DoltEnv, todo []env.RebaseStep) *rebase.Stop {
+	ctx := context.Background()
+	upstream := resolveBranch(t, dEnv, "master")
+
+	if todo == nil {
+		var err error
+		todo, err = rebase.BuildTodo(ctx, dEnv.DoltDB, resolveBranch(t, dEnv, "feature"), upstream)
+		require.NoError(t, err)
+	}
+
+	stop, err := rebase.Start(ctx, dEnv, upstream, todo, nil)
+	require.NoError(t, err)
+	return stop
+}
+
+func TestRebaseReplaysCommits(t *testing.T) {
+	dEnv := setupRebaseTest(t, "")
+	masterHash := commitHash(t, resolveBranch(t, dEnv, "master"))
+
+	stop := startRebase(t, dEnv, nil)
+	require.Nil(t, stop)
+	assert.False(t, dEnv.IsRebaseActive())
+
+	feature := resolveBranch(t, dEnv, "feature")
+	assert.Equal(t, []string{"insert 4", "insert 3", "insert 5", "added table", "Initialize data repository"}, commitMessages(t, dEnv, feature))
+
+	parent, err := dEnv.DoltDB.ResolveParent(context.Background(), feature, 0)
+	require.NoError(t, err)
+	parent, err = dEnv.DoltDB.ResolveParent(context.Background(), parent, 0)
+	require.NoError(t, err)
+	assert.Equal(t, masterHash, commitHash(t, parent))
+}
+
+func TestRebaseAbortAfterBranchSwitch(t *testing.T) {
+	ctx := context.Background()
+	dEnv := setupRebaseTest(t, "insert into test values (3,30);")
+	origHead := commitHash(t, resolveBranch(t, dEnv, "feature"))
+	masterHash := commitHash(t, resolveBranch(t, dEnv, "master"))
+
+	stop := startRebase(t, dEnv, nil)
+	require.NotNil(t, stop)
+	require.True(t, dEnv.IsRebaseActive())
+	assert.Equal(t, "refs/heads/feature", dEnv.RepoState.Rebase.Branch.Ref.String())
+
+	// switch branches behind the rebase's back, e.g. from a sql session
+	otherRef := ref.NewBranchRef("other")
+	require.NoError(t, dEnv.DoltDB.NewBranchAtCommit(ctx, otherRef, resolveBranch(t, dEnv, "feature")))
+	dEnv.RepoState.Head = ref.MarshalableRef{Ref: otherRef}
+
+	_, err := rebase.Continue(ctx, dEnv, nil)
+	assert.Equal(t, rebase.ErrRebaseOtherBranch, err)
+
+	require.NoError(t, rebase.Abort(ctx, dEnv))
+	assert.False(t, dEnv.IsRebaseActive())
+	assert.Equal(t, origHead, commitHash(t, resolveBranch(t, dEnv, "feature")))
+	assert.Equal(t, masterHash, commitHash(t, resolveBranch(t, dEnv, "other")))
+}
+
+func TestRebaseSquashAfterEmptyPick(t *testing.T) {
+	dEnv := setupRebaseTest(t, "insert into test values (3,3);")
+	master := resolveBranch(t, dEnv, "master")
+	masterHash := commitHash(t, master)
+
+	feature := resolveBranch(t, dEnv, "feature")
+	c4 := commitHash(t, feature)
+	c3Cm, err := dEnv.DoltDB.ResolveParent(context.Background(), feature, 0)
+	require.NoError(t, err)
+	c3 := commitHash(t, c3Cm)
+
+	// "insert 3" is already on master, so picking it makes no commit and the squash has nothing to squash into
+	stop := startRebase(t, dEnv, []env.RebaseStep{{Action: rebase.PickAction, Commit: c3}, {Action: rebase.SquashAction, Commit: c4}})
+	require.Nil(t, stop)
+
+	assert.Equal(t, masterHash, commitHash(t, resolveBranch(t, dEnv, "master")))
+
+	feature = resolveBranch(t, dEnv, "feature")
+	msgs := commitMessages(t, dEnv, feature)
+	assert.Equal(t, "insert 4", msgs[0])
+	parent, err := dEnv.DoltDB.ResolveParent(context.Background(), feature, 0)
+	require.NoError(t, err)
+	assert.Equal(t, masterHash, commitHash(t, parent))
+}
+
+func TestBuildTodoPicksEveryCommitNotOnUpstream(t *testing.T) {
+	dEnv := setupRebaseTest(t, "")
+	feature := resolveBranch(t, dEnv, "feature")
+
+	todo, err := rebase.BuildTodo(context.Background(), dEnv.DoltDB, feature, resolveBranch(t, dEnv, "master"))
+	require.NoError(t, err)
+
+	parent, err := dEnv.DoltDB.ResolveParent(context.Background(), feature, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []env.RebaseStep{
+		{Action: rebase.PickAction, Commit: commitHash(t, parent)},
+		{Action: rebase.PickAction, Commit: commitHash(t, feature)},
+	}, todo)
+}
+
+func TestBuildTodoWithMergeCommit(t *testing.T) {
+	dEnv := setupRebaseTest(t, "")
+	cmds := []tc.Command{
+		tc.Branch{BranchName: "side"},
+		tc.Checkout{BranchName: "side"},
+		tc.Query{Query: "insert into test values (6,6);"},
+		tc.CommitAll{Message: "insert 6"},
+		tc.Checkout{BranchName: "feature"},
+		tc.Query{Query: "insert into test values (7,7);"},
+		tc.CommitAll{Message: "insert 7"},
+		tc.Merge{BranchName: "side"},
+		tc.CommitAll{Message: "merge side"},
+	}
+	for _, cmd := range cmds {
+		require.NoError(t, cmd.Exec(t, dEnv), cmd.CommandString())
+	}
+
+	feature := resolveBranch(t, dEnv, "feature")
+	n, err := feature.NumParents()
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	_, err = rebase.BuildTodo(context.Background(), dEnv.DoltDB, feature, resolveBranch(t, dEnv, "master"))
+	assert.True(t, errors.Is(err, rebase.ErrRebaseMergeCommit))
+}
@@ -51,11 +51,14 @@ const (
 
 	// WorkspaceRefType is a reference to a workspace
 	WorkspaceRefType RefType = "workspaces"
+
+	// StashRefType is a reference to a stash entry
+	StashRefType RefType = "stashes"
 )
 
 // RefTypes is the set of all supported reference types.  External RefTypes can be added to this map in order to add
 // RefTypes for external tooling
-var RefTypes = map[RefType]struct{}{BranchRefType: {}, RemoteRefType: {}, InternalRefType: {}, TagRefType: {}, WorkspaceRefType: {}}
+var RefTypes = map[RefType]struct{}{BranchRefType: {}, RemoteRefType: {}, InternalRefType: {}, TagRefType: {}, WorkspaceRefType: {}, StashRefType: {}}
 
 // PrefixForType returns what a reference string for a given type should start with
 func PrefixForType(refType RefType) string {
@@ -148,6 +151,8 @@ func Parse(str string) (DoltRef, error) {
 				return NewTagRef(str), nil
 			case WorkspaceRefType:
 				return NewWorkspaceRef(str), nil
+			case StashRefType:
+				return NewStashRef(str), nil
 			default:
 				panic("unknown type " + rType)
 			}
@@ -44,6 +44,10 @@ func TestJsonMarshalAndUnmarshal(t *testing.T) {
 			NewWorkspaceRef("newworkspace"),
 			`{"test":"refs/workspaces/newworkspace"}`,
 		},
+		{
+			NewStashRef("newstash"),
+			`{"test":"refs/stashes/newstash"}`,
+		},
 	}
 
 	for _, test := range tests {
@@ -158,6 +162,21 @@ func TestEqualsStr(t *testing.T) {
 			"refs/remotes/origin/newworkspace",
 			false,
 		},
+		{
+			NewStashRef("newstash"),
+			"refs/stashes/newstash",
+			true,
+		},
+		{
+			NewStashRef("refs/stashes/newstash"),
+			"refs/stashes/newstash",
+			true,
+		},
+		{
+			NewStashRef("newstash"),
+			"refs/workspaces/newstash",
+			false,
+		},
 	}
 
 	for _, test := range tests {
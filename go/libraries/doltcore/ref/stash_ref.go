@@ -0,0 +1,59 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ref
+
+import "strings"
+
+// StashRef is a reference to a stash entry, which is a dangling commit holding saved working set changes.
+type StashRef struct {
+	stash string
+}
+
+var _ DoltRef = StashRef{}
+
+// NewStashRef creates a reference to a stash entry from a stash name or a stash ref e.g. my-stash, or
+// refs/stashes/my-stash
+func NewStashRef(stash string) StashRef {
+	if IsRef(stash) {
+		prefix := PrefixForType(StashRefType)
+		if strings.HasPrefix(stash, prefix) {
+			stash = stash[len(prefix):]
+		} else {
+			panic(stash + " is a ref that is not of type " + prefix)
+		}
+	}
+
+	return StashRef{stash}
+}
+
+// GetType will return StashRefType
+func (sr StashRef) GetType() RefType {
+	return StashRefType
+}
+
+// GetPath returns the name of the stash
+func (sr StashRef) GetPath() string {
+	return sr.stash
+}
+
+// String returns the fully qualified reference name e.g. refs/stashes/my-stash
+func (sr StashRef) String() string {
+	return String(sr)
+}
+
+// MarshalJSON serializes a StashRef to JSON.
+func (sr StashRef) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(sr)
+}
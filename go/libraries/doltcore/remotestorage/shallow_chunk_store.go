@@ -0,0 +1,308 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotestorage
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/nbs"
+)
+
+// LocalChunkStore is the interface of the stores ShallowChunkStores keep their chunks in
+type LocalChunkStore interface {
+	chunks.ChunkStore
+	nbs.TableFileStore
+	GetManyCompressed(ctx context.Context, hashes hash.HashSet, found func(nbs.CompressedChunk)) error
+}
+
+// RemoteChunkStoreOpener opens the store of the remote a ShallowChunkStore reads missing chunks from
+type RemoteChunkStoreOpener func(ctx context.Context) (chunks.ChunkStore, error)
+
+// ShallowChunkStore is the chunk store of a clone which has only part of the history of its remote. Its boundary is
+// the set of commits which are the parents of commits in the clone, but are missing from it. If the store was
+// created with a RemoteChunkStoreOpener, it reads chunks which are missing from the clone from the remote, and keeps
+// them locally. Otherwise the boundary commits are missing when they are read, and the history of the clone ends there.
+type ShallowChunkStore struct {
+	LocalChunkStore
+	boundary   hash.HashSet
+	openRemote RemoteChunkStoreOpener
+
+	mu     *sync.Mutex
+	remote chunks.ChunkStore
+}
+
+var _ LocalChunkStore = (*ShallowChunkStore)(nil)
+
+// NewShallowChunkStore returns a ShallowChunkStore which keeps its chunks in |local|. |openRemote| may be nil, in which
+// case missing chunks are never fetched. The remote is not opened until a chunk which is missing is read.
+func NewShallowChunkStore(local LocalChunkStore, boundary hash.HashSet, openRemote RemoteChunkStoreOpener) *ShallowChunkStore {
+	return &ShallowChunkStore{
+		LocalChunkStore: local,
+		boundary:        boundary,
+		openRemote:      openRemote,
+		mu:              &sync.Mutex{},
+	}
+}
+
+func (scs *ShallowChunkStore) getRemote(ctx context.Context) (chunks.ChunkStore, error) {
+	scs.mu.Lock()
+	defer scs.mu.Unlock()
+
+	if scs.remote == nil {
+		remote, err := scs.openRemote(ctx)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the remote of this shallow clone: %w", err)
+		}
+
+		scs.remote = remote
+	}
+
+	return scs.remote, nil
+}
+
+// fetchMissing reads the chunks |missing| from the remote, keeps them in the local store and calls |found| with them.
+func (scs *ShallowChunkStore) fetchMissing(ctx context.Context, missing hash.HashSet, found func(*chunks.Chunk)) error {
+	if len(missing) == 0 || scs.openRemote == nil {
+		return nil
+	}
+
+	remote, err := scs.getRemote(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	mu := &sync.Mutex{}
+	var fetched []*chunks.Chunk
+	err = remote.GetMany(ctx, missing, func(c *chunks.Chunk) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetched = append(fetched, c)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if len(fetched) == 0 {
+		return nil
+	}
+
+	err = scs.keepFetched(ctx, fetched)
+
+	if err != nil {
+		return err
+	}
+
+	for _, c := range fetched {
+		found(c)
+	}
+
+	return nil
+}
+
+// keepFetched keeps the chunks |fetched| from the remote in the local store, so that they aren't fetched again. They
+// are written to a table file of their own which is added to the store without moving its root, so that chunks other
+// writers have put in the store but not committed aren't written along with them. If the store can't write table
+// files, the chunks are put in the store and written by its next commit.
+func (scs *ShallowChunkStore) keepFetched(ctx context.Context, fetched []*chunks.Chunk) error {
+	if !scs.LocalChunkStore.SupportedOperations().CanWrite {
+		for _, c := range fetched {
+			err := scs.LocalChunkStore.Put(ctx, *c)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	tempDir, err := ioutil.TempDir("", "shallow_chunk_store")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(tempDir)
+
+	wr, err := nbs.NewCmpChunkTableWriter(tempDir)
+
+	if err != nil {
+		return err
+	}
+
+	for _, c := range fetched {
+		err = wr.AddCmpChunk(nbs.ChunkToCompressedChunk(*c))
+
+		if err != nil && err != nbs.ErrChunkAlreadyWritten {
+			return err
+		}
+	}
+
+	id, err := wr.Finish()
+
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(tempDir, id)
+	err = wr.FlushToFile(path)
+
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	return scs.LocalChunkStore.WriteTableFile(ctx, id, wr.Size(), f, wr.ContentLength(), wr.GetMD5())
+}
+
+// Get returns the chunk with the hash |h|, reading it from the remote if it is missing locally.
+func (scs *ShallowChunkStore) Get(ctx context.Context, h hash.Hash) (chunks.Chunk, error) {
+	c, err := scs.LocalChunkStore.Get(ctx, h)
+
+	if err != nil || !c.IsEmpty() {
+		return c, err
+	}
+
+	err = scs.fetchMissing(ctx, hash.NewHashSet(h), func(fetched *chunks.Chunk) {
+		c = *fetched
+	})
+
+	if err != nil {
+		return chunks.EmptyChunk, err
+	}
+
+	return c, nil
+}
+
+// GetMany calls |found| with the chunks of |hashes|, reading those missing locally from the remote.
+func (scs *ShallowChunkStore) GetMany(ctx context.Context, hashes hash.HashSet, found func(*chunks.Chunk)) error {
+	mu := &sync.Mutex{}
+	missing := copyHashSet(hashes)
+	err := scs.LocalChunkStore.GetMany(ctx, hashes, func(c *chunks.Chunk) {
+		mu.Lock()
+		missing.Remove(c.Hash())
+		mu.Unlock()
+
+		found(c)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return scs.fetchMissing(ctx, missing, found)
+}
+
+// GetManyCompressed calls |found| with the chunks of |hashes|, reading those missing locally from the remote.
+func (scs *ShallowChunkStore) GetManyCompressed(ctx context.Context, hashes hash.HashSet, found func(nbs.CompressedChunk)) error {
+	mu := &sync.Mutex{}
+	missing := copyHashSet(hashes)
+	err := scs.LocalChunkStore.GetManyCompressed(ctx, hashes, func(c nbs.CompressedChunk) {
+		mu.Lock()
+		missing.Remove(c.H)
+		mu.Unlock()
+
+		found(c)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return scs.fetchMissing(ctx, missing, func(c *chunks.Chunk) {
+		found(nbs.ChunkToCompressedChunk(*c))
+	})
+}
+
+func copyHashSet(hashes hash.HashSet) hash.HashSet {
+	cp := make(hash.HashSet, len(hashes))
+	for h := range hashes {
+		cp.Insert(h)
+	}
+
+	return cp
+}
+
+// Has returns true if the chunk with the hash |h| is in the local store, the boundary of the store or the remote.
+func (scs *ShallowChunkStore) Has(ctx context.Context, h hash.Hash) (bool, error) {
+	absent, err := scs.HasMany(ctx, hash.NewHashSet(h))
+
+	if err != nil {
+		return false, err
+	}
+
+	return len(absent) == 0, nil
+}
+
+// HasMany returns the hashes of |hashes| which are in none of the local store, the boundary of the store or the
+// remote. The chunks of the remote are considered to be present so that values which reference them can be written.
+func (scs *ShallowChunkStore) HasMany(ctx context.Context, hashes hash.HashSet) (hash.HashSet, error) {
+	absent, err := scs.LocalChunkStore.HasMany(ctx, hashes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for h := range absent {
+		if scs.boundary.Has(h) {
+			absent.Remove(h)
+		}
+	}
+
+	if len(absent) == 0 || scs.openRemote == nil {
+		return absent, nil
+	}
+
+	remote, err := scs.getRemote(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return remote.HasMany(ctx, absent)
+}
+
+// Close closes the local store, and the remote if it was opened.
+func (scs *ShallowChunkStore) Close() error {
+	err := scs.LocalChunkStore.Close()
+
+	scs.mu.Lock()
+	defer scs.mu.Unlock()
+
+	if scs.remote != nil {
+		if rerr := scs.remote.Close(); err == nil {
+			err = rerr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotestorage
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/constants"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/nbs"
+)
+
+func newTestLocalStore(t *testing.T, ctx context.Context, dir string) LocalChunkStore {
+	require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	nbs, err := nbs.NewLocalStore(ctx, constants.FormatDefaultString, dir, 1<<20)
+	require.NoError(t, err)
+
+	return nbs
+}
+
+func TestShallowChunkStore(t *testing.T) {
+	ctx := context.Background()
+	localChunk := chunks.NewChunk([]byte("local"))
+	remoteChunk := chunks.NewChunk([]byte("remote"))
+	boundaryChunk := chunks.NewChunk([]byte("boundary"))
+	missing := hash.Of([]byte("missing"))
+
+	dir, err := ioutil.TempDir("", "shallow_chunk_store_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("without remote", func(t *testing.T) {
+		local := newTestLocalStore(t, ctx, filepath.Join(dir, "without_remote"))
+		require.NoError(t, local.Put(ctx, localChunk))
+
+		scs := NewShallowChunkStore(local, hash.NewHashSet(boundaryChunk.Hash()), nil)
+		defer scs.Close()
+
+		absent, err := scs.HasMany(ctx, hash.NewHashSet(localChunk.Hash(), boundaryChunk.Hash(), missing))
+		require.NoError(t, err)
+		assert.Equal(t, hash.NewHashSet(missing), absent)
+
+		c, err := scs.Get(ctx, localChunk.Hash())
+		require.NoError(t, err)
+		assert.Equal(t, localChunk.Data(), c.Data())
+
+		// the boundary is considered present, but it can't be read
+		c, err = scs.Get(ctx, boundaryChunk.Hash())
+		require.NoError(t, err)
+		assert.True(t, c.IsEmpty())
+	})
+
+	t.Run("with remote", func(t *testing.T) {
+		local := newTestLocalStore(t, ctx, filepath.Join(dir, "with_remote"))
+		require.NoError(t, local.Put(ctx, localChunk))
+
+		remoteStorage := &chunks.TestStorage{}
+		remote := remoteStorage.NewView()
+		for _, c := range []chunks.Chunk{localChunk, remoteChunk, boundaryChunk} {
+			require.NoError(t, remote.Put(ctx, c))
+		}
+
+		opened := 0
+		scs := NewShallowChunkStore(local, hash.NewHashSet(boundaryChunk.Hash()), func(ctx context.Context) (chunks.ChunkStore, error) {
+			opened++
+			return remote, nil
+		})
+		defer scs.Close()
+
+		c, err := scs.Get(ctx, localChunk.Hash())
+		require.NoError(t, err)
+		assert.Equal(t, localChunk.Data(), c.Data())
+		assert.Equal(t, 0, opened)
+
+		absent, err := scs.HasMany(ctx, hash.NewHashSet(localChunk.Hash(), remoteChunk.Hash(), boundaryChunk.Hash(), missing))
+		require.NoError(t, err)
+		assert.Equal(t, hash.NewHashSet(missing), absent)
+
+		found := map[hash.Hash][]byte{}
+		err = scs.GetMany(ctx, hash.NewHashSet(localChunk.Hash(), remoteChunk.Hash(), boundaryChunk.Hash(), missing), func(c *chunks.Chunk) {
+			found[c.Hash()] = c.Data()
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[hash.Hash][]byte{
+			localChunk.Hash():    localChunk.Data(),
+			remoteChunk.Hash():   remoteChunk.Data(),
+			boundaryChunk.Hash(): boundaryChunk.Data(),
+		}, found)
+		assert.Equal(t, 1, opened)
+
+		// the fetched chunks are kept locally
+		absent, err = local.HasMany(ctx, hash.NewHashSet(localChunk.Hash(), remoteChunk.Hash(), boundaryChunk.Hash()))
+		require.NoError(t, err)
+		assert.Empty(t, absent)
+
+		// fetching chunks persists them without writing the chunks other writers haven't committed
+		pendingChunk := chunks.NewChunk([]byte("pending"))
+		fetchedChunk := chunks.NewChunk([]byte("fetched"))
+		require.NoError(t, remote.Put(ctx, fetchedChunk))
+		require.NoError(t, local.Put(ctx, pendingChunk))
+		c, err = scs.Get(ctx, fetchedChunk.Hash())
+		require.NoError(t, err)
+		assert.Equal(t, fetchedChunk.Data(), c.Data())
+
+		reopened := newTestLocalStore(t, ctx, filepath.Join(dir, "with_remote"))
+		defer reopened.Close()
+		absent, err = reopened.HasMany(ctx, hash.NewHashSet(fetchedChunk.Hash(), pendingChunk.Hash()))
+		require.NoError(t, err)
+		assert.Equal(t, hash.NewHashSet(pendingChunk.Hash()), absent)
+	})
+}
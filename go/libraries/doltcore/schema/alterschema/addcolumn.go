@@ -120,6 +120,7 @@ func addColumnToSchema(sch schema.Schema, tag uint64, newColName string, typeInf
 		return nil, err
 	}
 	newSch.Indexes().AddIndex(sch.Indexes().AllIndexes()...)
+	newSch.Checks().AddChecks(sch.Checks().AllChecks()...)
 
 	return newSch, nil
 }
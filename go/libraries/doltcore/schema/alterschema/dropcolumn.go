@@ -21,6 +21,7 @@ import (
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
 )
 
 var ErrKeylessAltTbl = errors.New("schema alterations not supported for keyless tables")
@@ -62,6 +63,16 @@ func DropColumn(ctx context.Context, tbl *doltdb.Table, colName string, foreignK
 		}
 	}
 
+	for _, check := range sch.Checks().AllChecks() {
+		usesCol, err := sqlutil.CheckReferencesColumn(ctx, check.Expression(), colName)
+		if err != nil {
+			return nil, err
+		}
+		if usesCol {
+			return nil, fmt.Errorf("cannot drop column `%s` as it is used in check constraint `%s`", colName, check.Name())
+		}
+	}
+
 	for _, index := range sch.Indexes().IndexesWithColumn(colName) {
 		_, err = sch.Indexes().RemoveIndex(index.Name())
 		if err != nil {
@@ -91,6 +102,7 @@ func DropColumn(ctx context.Context, tbl *doltdb.Table, colName string, foreignK
 		return nil, err
 	}
 	newSch.Indexes().AddIndex(sch.Indexes().AllIndexes()...)
+	newSch.Checks().AddChecks(sch.Checks().AllChecks()...)
 
 	return tbl.UpdateSchema(ctx, newSch)
 }
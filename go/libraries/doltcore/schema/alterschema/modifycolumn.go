@@ -25,6 +25,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/row"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
 	"github.com/dolthub/dolt/go/store/types"
 )
 
@@ -96,6 +97,18 @@ func validateModifyColumn(ctx context.Context, tbl *doltdb.Table, existingCol sc
 		return err
 	}
 
+	if existingCol.Name != modifiedCol.Name {
+		for _, check := range sch.Checks().AllChecks() {
+			usesCol, err := sqlutil.CheckReferencesColumn(ctx, check.Expression(), existingCol.Name)
+			if err != nil {
+				return err
+			}
+			if usesCol {
+				return fmt.Errorf("cannot rename column `%s` as it is used in check constraint `%s`", existingCol.Name, check.Name())
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -196,5 +209,6 @@ func replaceColumnInSchema(sch schema.Schema, oldCol schema.Column, newCol schem
 		return nil, err
 	}
 	newSch.Indexes().AddIndex(sch.Indexes().AllIndexes()...)
+	newSch.Checks().AddChecks(sch.Checks().AllChecks()...)
 	return newSch, nil
 }
@@ -0,0 +1,156 @@
+// Copyright 2019 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Check is a CHECK constraint on a table. Its expression is a SQL boolean expression over the columns of the table,
+// and a row satisfies the check unless the expression evaluates to false. Checks that are not enforced are persisted
+// but are not evaluated on write.
+type Check interface {
+	// Name returns the name of the check.
+	Name() string
+	// Expression returns the SQL expression of the check.
+	Expression() string
+	// Enforced returns whether the check is enforced.
+	Enforced() bool
+}
+
+type CheckCollection interface {
+	// AddCheck adds a check with the given name and expression. Returns an error if a check with the same name
+	// already exists.
+	AddCheck(name, expression string, enforced bool) (Check, error)
+	// AddChecks adds the given checks, overwriting any current checks with the same name. It does not perform any
+	// kind of checking, and is intended for schema modifications.
+	AddChecks(checks ...Check)
+	// AllChecks returns a slice containing all of the checks in this collection, in the order they were added.
+	AllChecks() []Check
+	// Count returns the number of checks in this collection.
+	Count() int
+	// DropCheck removes the check with the given case-insensitive name.
+	DropCheck(name string) error
+	// Equals returns whether this check collection is equivalent to another.
+	Equals(other CheckCollection) bool
+	// GetByNameCaseInsensitive returns the check with a matching case-insensitive name, the bool return value
+	// indicates if a match was found.
+	GetByNameCaseInsensitive(name string) (Check, bool)
+}
+
+type checkImpl struct {
+	name       string
+	expression string
+	enforced   bool
+}
+
+var _ Check = checkImpl{}
+
+// Name implements Check.
+func (c checkImpl) Name() string {
+	return c.name
+}
+
+// Expression implements Check.
+func (c checkImpl) Expression() string {
+	return c.expression
+}
+
+// Enforced implements Check.
+func (c checkImpl) Enforced() bool {
+	return c.enforced
+}
+
+type checkCollectionImpl struct {
+	checks []Check
+}
+
+var _ CheckCollection = (*checkCollectionImpl)(nil)
+
+// NewCheckCollection returns an empty CheckCollection.
+func NewCheckCollection() CheckCollection {
+	return &checkCollectionImpl{}
+}
+
+// AddCheck implements CheckCollection.
+func (c *checkCollectionImpl) AddCheck(name, expression string, enforced bool) (Check, error) {
+	if _, ok := c.GetByNameCaseInsensitive(name); ok {
+		return nil, fmt.Errorf("a check constraint named `%s` already exists on this table", name)
+	}
+
+	check := checkImpl{name: name, expression: expression, enforced: enforced}
+	c.checks = append(c.checks, check)
+	return check, nil
+}
+
+// AddChecks implements CheckCollection.
+func (c *checkCollectionImpl) AddChecks(checks ...Check) {
+	for _, check := range checks {
+		_ = c.DropCheck(check.Name())
+		c.checks = append(c.checks, checkImpl{name: check.Name(), expression: check.Expression(), enforced: check.Enforced()})
+	}
+}
+
+// AllChecks implements CheckCollection.
+func (c *checkCollectionImpl) AllChecks() []Check {
+	checks := make([]Check, len(c.checks))
+	copy(checks, c.checks)
+	return checks
+}
+
+// Count implements CheckCollection.
+func (c *checkCollectionImpl) Count() int {
+	return len(c.checks)
+}
+
+// DropCheck implements CheckCollection.
+func (c *checkCollectionImpl) DropCheck(name string) error {
+	for i, check := range c.checks {
+		if strings.ToLower(check.Name()) == strings.ToLower(name) {
+			c.checks = append(c.checks[:i:i], c.checks[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("check constraint `%s` does not exist on this table", name)
+}
+
+// Equals implements CheckCollection.
+func (c *checkCollectionImpl) Equals(other CheckCollection) bool {
+	otherChecks := other.AllChecks()
+	if len(c.checks) != len(otherChecks) {
+		return false
+	}
+
+	for i, check := range c.checks {
+		if check.Name() != otherChecks[i].Name() ||
+			check.Expression() != otherChecks[i].Expression() ||
+			check.Enforced() != otherChecks[i].Enforced() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetByNameCaseInsensitive implements CheckCollection.
+func (c *checkCollectionImpl) GetByNameCaseInsensitive(name string) (Check, bool) {
+	for _, check := range c.checks {
+		if strings.ToLower(check.Name()) == strings.ToLower(name) {
+			return check, true
+		}
+	}
+	return nil, false
+}
@@ -45,6 +45,7 @@ func createTestSchema() schema.Schema {
 	colColl := schema.NewColCollection(columns...)
 	sch := schema.MustSchemaFromCols(colColl)
 	_, _ = sch.Indexes().AddIndexByColTags("idx_age", []uint64{3}, schema.IndexProperties{IsUnique: false, Comment: ""})
+	_, _ = sch.Checks().AddCheck("chk_age", "age < 200", true)
 	return sch
 }
 
@@ -222,9 +223,16 @@ type testEncodedIndex struct {
 	Hidden  bool     `noms:"hidden,omitempty" json:"hidden,omitempty"`
 }
 
+type testEncodedCheck struct {
+	Name       string `noms:"name" json:"name"`
+	Expression string `noms:"expression" json:"expression"`
+	Enforced   bool   `noms:"enforced" json:"enforced"`
+}
+
 type testSchemaData struct {
 	Columns         []testEncodedColumn `noms:"columns" json:"columns"`
 	IndexCollection []testEncodedIndex  `noms:"idxColl,omitempty" json:"idxColl,omitempty"`
+	CheckCollection []testEncodedCheck  `noms:"checks,omitempty" json:"checks,omitempty"`
 }
 
 func (tec testEncodedColumn) decodeColumn() (schema.Column, error) {
@@ -270,5 +278,12 @@ func (tsd testSchemaData) decodeSchema() (schema.Schema, error) {
 		}
 	}
 
+	for _, encodedCheck := range tsd.CheckCollection {
+		_, err = sch.Checks().AddCheck(encodedCheck.Name, encodedCheck.Expression, encodedCheck.Enforced)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return sch, nil
 }
@@ -77,6 +77,16 @@ const (
 	DoltSchemasFragmentTag
 )
 
+// Tags for dolt_merge_policies table
+const (
+	// MergePoliciesTableNameTag is the tag of the table name column in the merge policies table
+	MergePoliciesTableNameTag = iota + SystemTableReservedMin + uint64(6000)
+	// MergePoliciesStrategyTag is the tag of the strategy column in the merge policies table
+	MergePoliciesStrategyTag
+	// MergePoliciesExpressionTag is the tag of the expression column in the merge policies table
+	MergePoliciesExpressionTag
+)
+
 // Tags for hidden columns in keyless rows
 const (
 	KeylessRowIdTag = iota + SystemTableReservedMin + uint64(5000)
@@ -27,6 +27,9 @@ type Schema interface {
 
 	// Indexes returns a collection of all indexes on the table that this schema belongs to.
 	Indexes() IndexCollection
+
+	// Checks returns a collection of all check constraints on the table that this schema belongs to.
+	Checks() CheckCollection
 }
 
 // ColFromTag returns a schema.Column from a schema and a tag
@@ -82,7 +85,10 @@ func SchemasAreEqual(sch1, sch2 Schema) (bool, error) {
 	if !colCollIsEqual {
 		return false, nil
 	}
-	return sch1.Indexes().Equals(sch2.Indexes()), nil
+	if !sch1.Indexes().Equals(sch2.Indexes()) {
+		return false, nil
+	}
+	return sch1.Checks().Equals(sch2.Checks()), nil
 }
 
 // TODO: this function never returns an error
@@ -0,0 +1,198 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package typeinfo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// jsonType stores JSON documents as strings in a normalized form: insignificant whitespace is removed and the keys of
+// objects are sorted, so that equal documents are stored as equal values.
+type jsonType struct {
+	sqlJsonType sql.JsonType
+}
+
+var _ TypeInfo = (*jsonType)(nil)
+
+var JSONType = &jsonType{sql.JSON}
+
+// ConvertNomsValueToValue implements TypeInfo interface.
+func (ti *jsonType) ConvertNomsValueToValue(v types.Value) (interface{}, error) {
+	if val, ok := v.(types.String); ok {
+		return []byte(val), nil
+	}
+	if _, ok := v.(types.Null); ok || v == nil {
+		return nil, nil
+	}
+	return nil, fmt.Errorf(`"%v" cannot convert NomsKind "%v" to a value`, ti.String(), v.Kind())
+}
+
+// ReadFrom reads a go value from a noms types.CodecReader directly
+func (ti *jsonType) ReadFrom(_ *types.NomsBinFormat, reader types.CodecReader) (interface{}, error) {
+	k := reader.ReadKind()
+	switch k {
+	case types.StringKind:
+		return []byte(reader.ReadString()), nil
+	case types.NullKind:
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf(`"%v" cannot convert NomsKind "%v" to a value`, ti.String(), k)
+}
+
+// ConvertValueToNomsValue implements TypeInfo interface.
+func (ti *jsonType) ConvertValueToNomsValue(ctx context.Context, vrw types.ValueReadWriter, v interface{}) (types.Value, error) {
+	var doc []byte
+	switch val := v.(type) {
+	case nil:
+		return types.NullValue, nil
+	case types.Null:
+		return types.NullValue, nil
+	case []byte:
+		doc = val
+	case string:
+		doc = []byte(val)
+	case types.String:
+		doc = []byte(val)
+	default:
+		var err error
+		doc, err = json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf(`"%v" cannot convert value "%v" of type "%T" as it is invalid`, ti.String(), v, v)
+		}
+	}
+
+	normalized, err := NormalizeJSON(doc)
+	if err != nil {
+		return nil, fmt.Errorf(`"%v" cannot convert value "%s" as it is not valid JSON: %v`, ti.String(), doc, err)
+	}
+
+	return types.String(normalized), nil
+}
+
+// Equals implements TypeInfo interface.
+func (ti *jsonType) Equals(other TypeInfo) bool {
+	if other == nil {
+		return false
+	}
+	_, ok := other.(*jsonType)
+	return ok
+}
+
+// FormatValue implements TypeInfo interface.
+func (ti *jsonType) FormatValue(v types.Value) (*string, error) {
+	if val, ok := v.(types.String); ok {
+		res := string(val)
+		return &res, nil
+	}
+	if _, ok := v.(types.Null); ok || v == nil {
+		return nil, nil
+	}
+	return nil, fmt.Errorf(`"%v" cannot convert NomsKind "%v" to a string`, ti.String(), v.Kind())
+}
+
+// GetTypeIdentifier implements TypeInfo interface.
+func (ti *jsonType) GetTypeIdentifier() Identifier {
+	return JSONTypeIdentifier
+}
+
+// GetTypeParams implements TypeInfo interface.
+func (ti *jsonType) GetTypeParams() map[string]string {
+	return nil
+}
+
+// IsValid implements TypeInfo interface.
+func (ti *jsonType) IsValid(v types.Value) bool {
+	if val, ok := v.(types.String); ok {
+		return json.Valid([]byte(val))
+	}
+	if _, ok := v.(types.Null); ok || v == nil {
+		return true
+	}
+	return false
+}
+
+// NomsKind implements TypeInfo interface.
+func (ti *jsonType) NomsKind() types.NomsKind {
+	return types.StringKind
+}
+
+// ParseValue implements TypeInfo interface.
+func (ti *jsonType) ParseValue(ctx context.Context, vrw types.ValueReadWriter, str *string) (types.Value, error) {
+	if str == nil {
+		return types.NullValue, nil
+	}
+	return ti.ConvertValueToNomsValue(ctx, vrw, *str)
+}
+
+// Promote implements TypeInfo interface.
+func (ti *jsonType) Promote() TypeInfo {
+	return ti
+}
+
+// String implements TypeInfo interface.
+func (ti *jsonType) String() string {
+	return "JSON"
+}
+
+// ToSqlType implements TypeInfo interface.
+func (ti *jsonType) ToSqlType() sql.Type {
+	return ti.sqlJsonType
+}
+
+// NormalizeJSON returns the normalized form of the JSON document |doc|, with insignificant whitespace removed and the
+// keys of objects sorted. Numbers are kept exactly as written. Returns an error if |doc| is not valid JSON.
+func NormalizeJSON(doc []byte) ([]byte, error) {
+	val, err := UnmarshalJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	err = enc.Encode(val)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// UnmarshalJSON unmarshals the JSON document |doc|. Numbers are returned as json.Number so that they are not rounded.
+func UnmarshalJSON(doc []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+
+	var val interface{}
+	err := dec.Decode(&val)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the end of the JSON document")
+	}
+
+	return val, nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package typeinfo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+func TestJSONConvertNomsValueToValue(t *testing.T) {
+	tests := []struct {
+		input  types.String
+		output []byte
+	}{
+		{
+			`{"a":1}`,
+			[]byte(`{"a":1}`),
+		},
+		{
+			`[1,2,3]`,
+			[]byte(`[1,2,3]`),
+		},
+		{
+			`"abc"`,
+			[]byte(`"abc"`),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf(`%v %v`, JSONType.String(), test.input), func(t *testing.T) {
+			output, err := JSONType.ConvertNomsValueToValue(test.input)
+			require.NoError(t, err)
+			require.Equal(t, test.output, output)
+		})
+	}
+}
+
+func TestJSONConvertValueToNomsValue(t *testing.T) {
+	tests := []struct {
+		input       interface{}
+		output      types.String
+		expectedErr bool
+	}{
+		{
+			`{"b": 2, "a": 1}`,
+			`{"a":1,"b":2}`,
+			false,
+		},
+		{
+			[]byte(" [1, 2,\n 3] "),
+			`[1,2,3]`,
+			false,
+		},
+		{
+			`{"a": {"d": [1.50, 12345678901234567890], "c": "<&>"}}`,
+			`{"a":{"c":"<&>","d":[1.50,12345678901234567890]}}`,
+			false,
+		},
+		{
+			map[string]interface{}{"x": []int{1, 2}},
+			`{"x":[1,2]}`,
+			false,
+		},
+		{
+			int64(5),
+			`5`,
+			false,
+		},
+		{
+			`null`,
+			`null`,
+			false,
+		},
+		{
+			`{"a":`,
+			``,
+			true,
+		},
+		{
+			`{} {}`,
+			``,
+			true,
+		},
+		{
+			`something`,
+			``,
+			true,
+		},
+		{
+			``,
+			``,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf(`%v %v`, JSONType.String(), test.input), func(t *testing.T) {
+			vrw := types.NewMemoryValueStore()
+			output, err := JSONType.ConvertValueToNomsValue(context.Background(), vrw, test.input)
+			if !test.expectedErr {
+				require.NoError(t, err)
+				assert.Equal(t, test.output, output)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestJSONParseValue(t *testing.T) {
+	tests := []struct {
+		input       string
+		output      types.String
+		expectedErr bool
+	}{
+		{
+			`{ "z": true, "y": [null] }`,
+			`{"y":[null],"z":true}`,
+			false,
+		},
+		{
+			`not json`,
+			``,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf(`%v %v`, JSONType.String(), test.input), func(t *testing.T) {
+			vrw := types.NewMemoryValueStore()
+			output, err := JSONType.ParseValue(context.Background(), vrw, &test.input)
+			if !test.expectedErr {
+				require.NoError(t, err)
+				assert.Equal(t, test.output, output)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -37,6 +37,7 @@ const (
 	FloatTypeIdentifier      Identifier = "float"
 	InlineBlobTypeIdentifier Identifier = "inlineblob"
 	IntTypeIdentifier        Identifier = "int"
+	JSONTypeIdentifier       Identifier = "json"
 	SetTypeIdentifier        Identifier = "set"
 	TimeTypeIdentifier       Identifier = "time"
 	TupleTypeIdentifier      Identifier = "tuple"
@@ -57,6 +58,7 @@ var Identifiers = map[Identifier]struct{}{
 	FloatTypeIdentifier:      {},
 	InlineBlobTypeIdentifier: {},
 	IntTypeIdentifier:        {},
+	JSONTypeIdentifier:       {},
 	SetTypeIdentifier:        {},
 	TimeTypeIdentifier:       {},
 	TupleTypeIdentifier:      {},
@@ -214,6 +216,8 @@ func FromSqlType(sqlType sql.Type) (TypeInfo, error) {
 			return nil, fmt.Errorf(`expected "SetTypeIdentifier" from SQL basetype "Set"`)
 		}
 		return &setType{setSQLType}, nil
+	case sqltypes.TypeJSON:
+		return JSONType, nil
 	default:
 		return nil, fmt.Errorf(`no type info can be created from SQL base type "%v"`, sqlType.String())
 	}
@@ -238,6 +242,8 @@ func FromTypeParams(id Identifier, params map[string]string) (TypeInfo, error) {
 		return CreateInlineBlobTypeFromParams(params)
 	case IntTypeIdentifier:
 		return CreateIntTypeFromParams(params)
+	case JSONTypeIdentifier:
+		return JSONType, nil
 	case SetTypeIdentifier:
 		return CreateSetTypeFromParams(params)
 	case TimeTypeIdentifier:
@@ -0,0 +1,465 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+)
+
+// CheckDefinition is a check constraint as declared in a CREATE TABLE or ALTER TABLE statement.
+type CheckDefinition struct {
+	// Name is the name of the check, or empty if one should be generated.
+	Name string
+	// Expression is the text of the boolean expression of the check.
+	Expression string
+	// Enforced is false when the check was declared NOT ENFORCED.
+	Enforced bool
+}
+
+// CheckConstraintStatement is a statement which declares or drops check constraints. The SQL parser does not support
+// check constraints, so these statements are split into the portion the parser can handle, and the check constraints
+// which are applied to the table afterwards.
+type CheckConstraintStatement struct {
+	// Database is the database qualifier of the table, or empty if the statement uses the current database.
+	Database string
+	// TableName is the name of the table the checks belong to.
+	TableName string
+	// Query is the statement with all check constraints removed. It is empty when nothing remains to be run.
+	Query string
+	// CreatesTable is true when Query creates the table.
+	CreatesTable bool
+	// AddChecks are the checks to add to the table.
+	AddChecks []CheckDefinition
+	// DropCheck is the name of the check to drop, if any.
+	DropCheck string
+	// FallbackQuery is run when DropCheck does not name a check on the table. It is used for DROP CONSTRAINT, which may
+	// also name a foreign key.
+	FallbackQuery string
+}
+
+// ParseCheckConstraintStatement returns the CheckConstraintStatement for |query|, or nil if |query| does not declare or
+// drop a check constraint.
+func ParseCheckConstraintStatement(query string) (*CheckConstraintStatement, error) {
+	toks := tokenizeCheckQuery(query)
+	if len(toks) < 3 {
+		return nil, nil
+	}
+
+	switch {
+	case toks[0].is(sqlparser.CREATE) && toks[1].is(sqlparser.TABLE):
+		return parseCreateTableChecks(query, toks)
+	case toks[0].is(sqlparser.ALTER) && toks[1].is(sqlparser.TABLE):
+		return parseAlterTableChecks(query, toks)
+	default:
+		return nil, nil
+	}
+}
+
+// ExecuteCheckConstraintStatement runs |stmt| against |db| using |eng|.
+func ExecuteCheckConstraintStatement(ctx *sql.Context, eng *sqle.Engine, db Database, stmt *CheckConstraintStatement) error {
+	if stmt.CreatesTable {
+		// the checks are added to the schema of the new table when it is created, so the table is never written
+		// without them
+		ctx = ctx.WithContext(context.WithValue(ctx.Context, declaredChecksKey{}, declaredChecksVal{db.Name(), stmt.TableName, stmt.AddChecks}))
+		return runCheckQuery(ctx, eng, stmt.Query)
+	}
+
+	if stmt.Query != "" {
+		if err := runCheckQuery(ctx, eng, stmt.Query); err != nil {
+			return err
+		}
+	}
+
+	tbl, ok, err := db.GetTableInsensitive(ctx, stmt.TableName)
+	if err != nil {
+		return err
+	} else if !ok {
+		return sql.ErrTableNotFound.New(stmt.TableName)
+	}
+
+	alterable, ok := tbl.(*AlterableDoltTable)
+	if !ok {
+		return fmt.Errorf("table `%s` does not support check constraints", stmt.TableName)
+	}
+
+	if stmt.DropCheck != "" {
+		if !alterable.HasCheck(stmt.DropCheck) {
+			if stmt.FallbackQuery != "" {
+				return runCheckQuery(ctx, eng, stmt.FallbackQuery)
+			}
+			return fmt.Errorf("check constraint `%s` does not exist on this table", stmt.DropCheck)
+		}
+		return alterable.DropCheck(ctx, stmt.DropCheck)
+	}
+
+	for _, check := range stmt.AddChecks {
+		err = alterable.CreateCheck(ctx, check.Name, check.Expression, check.Enforced)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type declaredChecksKey struct{}
+
+type declaredChecksVal struct {
+	db     string
+	table  string
+	checks []CheckDefinition
+}
+
+// declaredChecks returns the checks declared on the table |tblName| of the database |dbName| by the CREATE TABLE
+// statement being run with |ctx|.
+func declaredChecks(ctx context.Context, dbName, tblName string) []CheckDefinition {
+	val, ok := ctx.Value(declaredChecksKey{}).(declaredChecksVal)
+	if !ok || !strings.EqualFold(val.db, dbName) || !strings.EqualFold(val.table, tblName) {
+		return nil
+	}
+	return val.checks
+}
+
+func runCheckQuery(ctx *sql.Context, eng *sqle.Engine, query string) error {
+	_, ri, err := eng.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	for _, err = ri.Next(); err == nil; _, err = ri.Next() {
+	}
+	if err != io.EOF {
+		_ = ri.Close()
+		return err
+	}
+
+	return ri.Close()
+}
+
+func parseCreateTableChecks(query string, toks []checkToken) (*CheckConstraintStatement, error) {
+	i := 2
+	if i+2 < len(toks) && toks[i].is(sqlparser.IF) && toks[i+1].is(sqlparser.NOT) && toks[i+2].is(sqlparser.EXISTS) {
+		i += 3
+	}
+
+	db, tblName, i, ok := parseCheckTableName(toks, i)
+	if !ok || i >= len(toks) || !toks[i].is('(') {
+		return nil, nil
+	}
+
+	bodyStart := i
+	bodyEnd := matchingParen(toks, bodyStart)
+	if bodyEnd < 0 {
+		return nil, nil
+	}
+
+	// split the table body into its column and constraint definitions
+	var elements [][2]int
+	start := bodyStart + 1
+	for j := start; j <= bodyEnd; j++ {
+		if j == bodyEnd || (toks[j].depth == 1 && toks[j].is(',')) {
+			elements = append(elements, [2]int{start, j})
+			start = j + 1
+		}
+	}
+
+	stmt := &CheckConstraintStatement{Database: db, TableName: tblName, CreatesTable: true}
+	var removals [][2]int
+	for elemIdx, elem := range elements {
+		if elem[0] >= elem[1] {
+			continue
+		}
+
+		first := toks[elem[0]]
+		if first.is(sqlparser.CHECK) || (first.is(sqlparser.CONSTRAINT) && elementHasCheck(toks, elem)) {
+			check, end, err := parseCheckClause(query, toks, elem[0])
+			if err != nil {
+				return nil, err
+			}
+			if end != elem[1] {
+				return nil, fmt.Errorf("invalid check constraint definition in %s", query)
+			}
+			stmt.AddChecks = append(stmt.AddChecks, check)
+
+			// remove the element along with the comma that separates it from its predecessor
+			if elemIdx > 0 {
+				removals = append(removals, [2]int{toks[elem[0]-1].start, toks[elem[1]-1].end})
+			} else if len(elements) > 1 {
+				removals = append(removals, [2]int{toks[elem[0]].start, toks[elem[1]].end})
+			} else {
+				return nil, fmt.Errorf("a table must have at least one column")
+			}
+			continue
+		}
+
+		if isCheckIndexDefinition(first) {
+			continue
+		}
+
+		// column level checks
+		for j := elem[0]; j < elem[1]; j++ {
+			if toks[j].depth != 1 {
+				continue
+			}
+
+			clauseStart := j
+			if toks[j].is(sqlparser.CONSTRAINT) {
+				k := j + 1
+				if k < elem[1] && toks[k].isIdent() && !toks[k].is(sqlparser.CHECK) {
+					k++
+				}
+				if k >= elem[1] || !toks[k].is(sqlparser.CHECK) {
+					continue
+				}
+			} else if !toks[j].is(sqlparser.CHECK) {
+				continue
+			}
+
+			check, end, err := parseCheckClause(query, toks, clauseStart)
+			if err != nil {
+				return nil, err
+			}
+			stmt.AddChecks = append(stmt.AddChecks, check)
+			removals = append(removals, [2]int{toks[clauseStart].start, toks[end-1].end})
+			j = end - 1
+		}
+	}
+
+	if len(stmt.AddChecks) == 0 {
+		return nil, nil
+	}
+
+	sb := strings.Builder{}
+	prev := 0
+	for _, r := range removals {
+		sb.WriteString(query[prev:r[0]])
+		prev = r[1]
+	}
+	sb.WriteString(query[prev:])
+	stmt.Query = sb.String()
+
+	return stmt, nil
+}
+
+func parseAlterTableChecks(query string, toks []checkToken) (*CheckConstraintStatement, error) {
+	db, tblName, i, ok := parseCheckTableName(toks, 2)
+	if !ok || i >= len(toks) {
+		return nil, nil
+	}
+
+	stmt := &CheckConstraintStatement{Database: db, TableName: tblName}
+	switch {
+	case toks[i].is(sqlparser.ADD):
+		if i+1 >= len(toks) || !(toks[i+1].is(sqlparser.CHECK) || (toks[i+1].is(sqlparser.CONSTRAINT) && elementHasCheck(toks, [2]int{i + 1, len(toks)}))) {
+			return nil, nil
+		}
+
+		check, end, err := parseCheckClause(query, toks, i+1)
+		if err != nil {
+			return nil, err
+		}
+		if end != len(toks) {
+			return nil, fmt.Errorf("adding a check constraint must be the only change made by an ALTER TABLE statement")
+		}
+		stmt.AddChecks = append(stmt.AddChecks, check)
+	case toks[i].is(sqlparser.DROP) && i+2 < len(toks) && toks[i+1].is(sqlparser.CHECK):
+		stmt.DropCheck = toks[i+2].ident()
+		if i+3 != len(toks) {
+			return nil, fmt.Errorf("dropping a check constraint must be the only change made by an ALTER TABLE statement")
+		}
+	case toks[i].is(sqlparser.DROP) && i+2 < len(toks) && toks[i+1].is(sqlparser.CONSTRAINT):
+		if i+3 != len(toks) {
+			return nil, nil
+		}
+		stmt.DropCheck = toks[i+2].ident()
+		stmt.FallbackQuery = query
+	default:
+		return nil, nil
+	}
+
+	return stmt, nil
+}
+
+// parseCheckClause parses `[CONSTRAINT [name]] CHECK (expr) [[NOT] ENFORCED]` beginning at the token |i|, returning the
+// check and the index of the first token following the clause.
+func parseCheckClause(query string, toks []checkToken, i int) (CheckDefinition, int, error) {
+	check := CheckDefinition{Enforced: true}
+
+	if toks[i].is(sqlparser.CONSTRAINT) {
+		i++
+		if i < len(toks) && !toks[i].is(sqlparser.CHECK) {
+			check.Name = toks[i].ident()
+			i++
+		}
+	}
+
+	if i >= len(toks) || !toks[i].is(sqlparser.CHECK) {
+		return check, 0, fmt.Errorf("invalid check constraint definition in %s", query)
+	}
+	i++
+
+	if i >= len(toks) || !toks[i].is('(') {
+		return check, 0, fmt.Errorf("check constraint expression must be enclosed in parentheses")
+	}
+	closeIdx := matchingParen(toks, i)
+	if closeIdx < 0 {
+		return check, 0, fmt.Errorf("unbalanced parentheses in check constraint expression")
+	}
+
+	check.Expression = strings.TrimSpace(query[toks[i].end:toks[closeIdx].start])
+	if check.Expression == "" {
+		return check, 0, fmt.Errorf("check constraint expression cannot be empty")
+	}
+	i = closeIdx + 1
+
+	if i+1 < len(toks) && toks[i].is(sqlparser.NOT) && toks[i+1].isWord("enforced") {
+		check.Enforced = false
+		i += 2
+	} else if i < len(toks) && toks[i].isWord("enforced") {
+		i++
+	}
+
+	return check, i, nil
+}
+
+func parseCheckTableName(toks []checkToken, i int) (db, tblName string, next int, ok bool) {
+	if i >= len(toks) || !toks[i].isIdent() {
+		return "", "", i, false
+	}
+	tblName = toks[i].ident()
+	i++
+
+	if i+1 < len(toks) && toks[i].is('.') && toks[i+1].isIdent() {
+		db = tblName
+		tblName = toks[i+1].ident()
+		i += 2
+	}
+
+	return db, tblName, i, true
+}
+
+func elementHasCheck(toks []checkToken, elem [2]int) bool {
+	i := elem[0] + 1
+	if i < elem[1] && toks[i].is(sqlparser.CHECK) {
+		return true
+	}
+	return i+1 < elem[1] && toks[i+1].is(sqlparser.CHECK)
+}
+
+func isCheckIndexDefinition(tok checkToken) bool {
+	switch tok.typ {
+	case sqlparser.PRIMARY, sqlparser.KEY, sqlparser.INDEX, sqlparser.UNIQUE, sqlparser.FOREIGN, sqlparser.CONSTRAINT, sqlparser.FULLTEXT, sqlparser.SPATIAL:
+		return true
+	}
+	return false
+}
+
+func matchingParen(toks []checkToken, open int) int {
+	for i := open + 1; i < len(toks); i++ {
+		if toks[i].is(')') && toks[i].depth == toks[open].depth {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkToken is a token of a statement as scanned by the tokenizer of the SQL parser. |start| and |end| are its offsets
+// in the statement.
+type checkToken struct {
+	typ   int
+	val   string
+	start int
+	end   int
+	// depth is the number of parentheses enclosing the token. An opening parenthesis has the depth of its surroundings.
+	depth int
+}
+
+func (t checkToken) is(typ int) bool {
+	return t.typ == typ
+}
+
+// isWord returns whether the token is the identifier |w|, for the words of the statements which aren't keywords of the
+// parser
+func (t checkToken) isWord(w string) bool {
+	return t.typ == sqlparser.ID && strings.EqualFold(t.val, w)
+}
+
+// isIdent returns whether the token can be an identifier, which includes the keywords that aren't reserved
+func (t checkToken) isIdent() bool {
+	if t.typ == sqlparser.ID {
+		return true
+	}
+
+	return t.typ != sqlparser.STRING && len(t.val) > 0 && (t.val[0] == '_' || unicode.IsLetter(rune(t.val[0])))
+}
+
+func (t checkToken) ident() string {
+	return t.val
+}
+
+// tokenizeCheckQuery splits |query| into tokens with the tokenizer of the SQL parser, skipping comments and a trailing
+// semicolon. Returns nil if |query| can't be tokenized.
+func tokenizeCheckQuery(query string) []checkToken {
+	tkn := sqlparser.NewStringTokenizer(query)
+
+	var toks []checkToken
+	depth, prevEnd := 0, 0
+	for {
+		typ, val := tkn.Scan()
+		if typ == 0 {
+			break
+		} else if typ == sqlparser.LEX_ERROR {
+			return nil
+		}
+
+		// the tokenizer has read one character past the end of the token
+		end := tkn.Position - 1
+		if end > len(query) {
+			end = len(query)
+		}
+
+		start := prevEnd
+		for start < end && strings.IndexByte(" \t\n\r", query[start]) >= 0 {
+			start++
+		}
+		prevEnd = end
+
+		if typ == sqlparser.COMMENT {
+			continue
+		}
+
+		if typ == ')' {
+			depth--
+		}
+		toks = append(toks, checkToken{typ: typ, val: string(val), start: start, end: end, depth: depth})
+		if typ == '(' {
+			depth++
+		}
+	}
+
+	for len(toks) > 0 && toks[len(toks)-1].is(';') {
+		toks = toks[:len(toks)-1]
+	}
+
+	return toks
+}
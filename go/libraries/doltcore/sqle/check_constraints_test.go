@@ -0,0 +1,178 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
+)
+
+func TestParseCheckConstraintStatement(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected *CheckConstraintStatement
+		err      bool
+	}{
+		{
+			name:  "no checks",
+			query: "CREATE TABLE t (pk int PRIMARY KEY, c1 int)",
+		},
+		{
+			name:  "other statement",
+			query: "SELECT * FROM t WHERE c1 = 'CHECK (c1 > 0)'",
+		},
+		{
+			name:  "table level checks",
+			query: "CREATE TABLE t (pk int PRIMARY KEY, c1 int, CONSTRAINT chk_c1 CHECK (c1 > 0), CHECK (c1 < 10) NOT ENFORCED);",
+			expected: &CheckConstraintStatement{
+				TableName:    "t",
+				Query:        "CREATE TABLE t (pk int PRIMARY KEY, c1 int);",
+				CreatesTable: true,
+				AddChecks: []CheckDefinition{
+					{Name: "chk_c1", Expression: "c1 > 0", Enforced: true},
+					{Expression: "c1 < 10", Enforced: false},
+				},
+			},
+		},
+		{
+			name:  "column level check",
+			query: "CREATE TABLE IF NOT EXISTS `db`.`t` (pk int PRIMARY KEY, c1 varchar(10) CHECK (c1 <> ')') NOT NULL)",
+			expected: &CheckConstraintStatement{
+				Database:     "db",
+				TableName:    "t",
+				Query:        "CREATE TABLE IF NOT EXISTS `db`.`t` (pk int PRIMARY KEY, c1 varchar(10)  NOT NULL)",
+				CreatesTable: true,
+				AddChecks:    []CheckDefinition{{Expression: "c1 <> ')'", Enforced: true}},
+			},
+		},
+		{
+			name:  "check before columns",
+			query: "CREATE TABLE t (CONSTRAINT chk CHECK ((pk + 1) > 0), pk int PRIMARY KEY)",
+			expected: &CheckConstraintStatement{
+				TableName:    "t",
+				Query:        "CREATE TABLE t ( pk int PRIMARY KEY)",
+				CreatesTable: true,
+				AddChecks:    []CheckDefinition{{Name: "chk", Expression: "(pk + 1) > 0", Enforced: true}},
+			},
+		},
+		{
+			name:  "alter table add check",
+			query: "ALTER TABLE t ADD CONSTRAINT `my chk` CHECK (c1 > 0) ENFORCED",
+			expected: &CheckConstraintStatement{
+				TableName: "t",
+				AddChecks: []CheckDefinition{{Name: "my chk", Expression: "c1 > 0", Enforced: true}},
+			},
+		},
+		{
+			name:  "alter table add multiple changes",
+			query: "ALTER TABLE t ADD CHECK (c1 > 0), ADD COLUMN c2 int",
+			err:   true,
+		},
+		{
+			name:  "alter table drop check",
+			query: "alter table t drop check chk",
+			expected: &CheckConstraintStatement{
+				TableName: "t",
+				DropCheck: "chk",
+			},
+		},
+		{
+			name:  "alter table drop constraint",
+			query: "ALTER TABLE t DROP CONSTRAINT fk",
+			expected: &CheckConstraintStatement{
+				TableName:     "t",
+				DropCheck:     "fk",
+				FallbackQuery: "ALTER TABLE t DROP CONSTRAINT fk",
+			},
+		},
+		{
+			name:  "alter table add foreign key",
+			query: "ALTER TABLE t ADD CONSTRAINT fk FOREIGN KEY (c1) REFERENCES parent (pk)",
+		},
+		{
+			name:  "empty check",
+			query: "CREATE TABLE t (pk int PRIMARY KEY, CHECK ())",
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stmt, err := ParseCheckConstraintStatement(test.query)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, stmt)
+		})
+	}
+}
+
+func TestExecuteCheckConstraintStatementCreatesTableWithChecks(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	root, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+
+	db := NewDatabase("dolt", dEnv.DbData())
+	engine, sqlCtx, err := NewTestEngine(ctx, db, root)
+	require.NoError(t, err)
+
+	execute := func(query string) error {
+		stmt, err := ParseCheckConstraintStatement(query)
+		require.NoError(t, err)
+		require.NotNil(t, stmt)
+		return ExecuteCheckConstraintStatement(sqlCtx, engine, db, stmt)
+	}
+
+	// the table isn't created when one of its checks is invalid
+	require.Error(t, execute("CREATE TABLE t (pk int PRIMARY KEY, c1 int CHECK (c1 > 0), CHECK (missing > 0))"))
+	root, err = db.GetRoot(sqlCtx)
+	require.NoError(t, err)
+	has, err := root.HasTable(ctx, "t")
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	require.NoError(t, execute("CREATE TABLE t (pk int PRIMARY KEY, c1 int CHECK (c1 > 0), CONSTRAINT chk_pk CHECK (pk < 10))"))
+	root, err = db.GetRoot(sqlCtx)
+	require.NoError(t, err)
+	tbl, ok, err := root.GetTable(ctx, "t")
+	require.NoError(t, err)
+	require.True(t, ok)
+	sch, err := tbl.GetSchema(ctx)
+	require.NoError(t, err)
+	var names []string
+	for _, check := range sch.Checks().AllChecks() {
+		names = append(names, check.Name())
+	}
+	assert.Equal(t, []string{"t_chk_1", "chk_pk"}, names)
+
+	// the checks of an existing table are left alone by CREATE TABLE IF NOT EXISTS
+	require.NoError(t, execute("CREATE TABLE IF NOT EXISTS t (pk int PRIMARY KEY, CHECK (pk > 5))"))
+	root, err = db.GetRoot(sqlCtx)
+	require.NoError(t, err)
+	tbl, _, err = root.GetTable(ctx, "t")
+	require.NoError(t, err)
+	sch, err = tbl.GetSchema(ctx)
+	require.NoError(t, err)
+	assert.Len(t, sch.Checks().AllChecks(), 2)
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dolthub/go-mysql-server/sql"
@@ -80,12 +81,16 @@ type SqlDatabase interface {
 
 // Database implements sql.Database for a dolt DB.
 type Database struct {
-	name      string
-	ddb       *doltdb.DoltDB
-	rsr       env.RepoStateReader
-	rsw       env.RepoStateWriter
-	drw       env.DocsReadWriter
-	batchMode commitBehavior
+	name       string
+	ddb        *doltdb.DoltDB
+	rsr        env.RepoStateReader
+	rsw        env.RepoStateWriter
+	drw        env.DocsReadWriter
+	batchMode  commitBehavior
+	readOnly   bool
+	protection *sharedWriteProtection
+	// wsLock is held while the working set is written, see DoltSession.LockWorkingSet
+	wsLock *sync.Mutex
 }
 
 var _ SqlDatabase = Database{}
@@ -98,12 +103,14 @@ var _ sql.TriggerDatabase = Database{}
 // NewDatabase returns a new dolt database to use in queries.
 func NewDatabase(name string, dbData env.DbData) Database {
 	return Database{
-		name:      name,
-		ddb:       dbData.Ddb,
-		rsr:       dbData.Rsr,
-		rsw:       dbData.Rsw,
-		drw:       dbData.Drw,
-		batchMode: single,
+		name:       name,
+		ddb:        dbData.Ddb,
+		rsr:        dbData.Rsr,
+		rsw:        dbData.Rsw,
+		drw:        dbData.Drw,
+		batchMode:  single,
+		protection: &sharedWriteProtection{mu: &sync.RWMutex{}},
+		wsLock:     &sync.Mutex{},
 	}
 }
 
@@ -111,12 +118,14 @@ func NewDatabase(name string, dbData env.DbData) Database {
 // commit any outstanding edits.
 func NewBatchedDatabase(name string, dbData env.DbData) Database {
 	return Database{
-		name:      name,
-		ddb:       dbData.Ddb,
-		rsr:       dbData.Rsr,
-		rsw:       dbData.Rsw,
-		drw:       dbData.Drw,
-		batchMode: batched,
+		name:       name,
+		ddb:        dbData.Ddb,
+		rsr:        dbData.Rsr,
+		rsw:        dbData.Rsw,
+		drw:        dbData.Drw,
+		batchMode:  batched,
+		protection: &sharedWriteProtection{mu: &sync.RWMutex{}},
+		wsLock:     &sync.Mutex{},
 	}
 }
 
@@ -125,6 +134,39 @@ func (db Database) Name() string {
 	return db.name
 }
 
+// IsReadOnly returns whether the database rejects all writes, as revision databases bound to a commit do.
+func (db Database) IsReadOnly() bool {
+	return db.readOnly
+}
+
+// SetWriteProtection restricts the writes which may be made to this database according to |wp|. The restrictions are
+// shared by every copy of the database and by the databases sharing them through WithWriteProtectionOf, and apply to
+// them immediately.
+func (db Database) SetWriteProtection(wp WriteProtection) {
+	db.protection.set(wp)
+}
+
+// WithWriteProtectionOf returns a copy of this database which shares the restrictions on writes of |other|
+func (db Database) WithWriteProtectionOf(other Database) Database {
+	db.protection = other.protection
+	return db
+}
+
+// WriteProtection returns the restrictions on the writes which may be made to the database
+func (db Database) WriteProtection() WriteProtection {
+	return db.protection.get()
+}
+
+// CheckWrite returns an error if the database can't be written to, because it is read only or because its checked
+// out branch is protected.
+func (db Database) CheckWrite() error {
+	if db.readOnly {
+		return ErrReadOnlyDatabase.New(db.name)
+	}
+
+	return db.protection.get().CheckWrite(db.name, db.rsr)
+}
+
 // GetDoltDB gets the underlying DoltDB of the Database
 func (db Database) GetDoltDB() *doltdb.DoltDB {
 	return db.ddb
@@ -182,6 +224,10 @@ func (db Database) GetTableInsensitiveWithRoot(ctx *sql.Context, root *doltdb.Ro
 		suffix := tblName[len(doltdb.DoltConfTablePrefix):]
 		found = true
 		dt, err = dtables.NewConflictsTable(ctx, suffix, root, dtables.RootSetter(db))
+	case strings.HasPrefix(lwrName, doltdb.DoltBlameTablePrefix):
+		suffix := tblName[len(doltdb.DoltBlameTablePrefix):]
+		found = true
+		dt, err = dtables.NewBlameTable(ctx, suffix, db.ddb, head)
 	}
 	if err != nil {
 		return nil, false, err
@@ -196,6 +242,8 @@ func (db Database) GetTableInsensitiveWithRoot(ctx *sql.Context, root *doltdb.Ro
 		dt, found = dtables.NewLogTable(ctx, db.ddb, head), true
 	case doltdb.TableOfTablesInConflictName:
 		dt, found = dtables.NewTableOfTablesInConflict(ctx, db.ddb, root), true
+	case doltdb.SchemaConflictsTableName:
+		dt, found = dtables.NewSchemaConflictsTable(ctx, root), true
 	case doltdb.BranchesTableName:
 		dt, found = dtables.NewBranchesTable(ctx, db.ddb), true
 	case doltdb.CommitsTableName:
@@ -209,7 +257,16 @@ func (db Database) GetTableInsensitiveWithRoot(ctx *sql.Context, root *doltdb.Ro
 		return dt, found, nil
 	}
 
-	return db.getTable(ctx, root, tblName)
+	dt, found, err = db.getTable(ctx, root, tblName)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !found && lwrName == doltdb.MergePoliciesTableName {
+		return newEmptyMergePoliciesTable(db), true, nil
+	}
+
+	return dt, found, nil
 }
 
 // GetTableInsensitiveAsOf implements sql.VersionedDatabase
@@ -238,12 +295,7 @@ func (db Database) rootAsOf(ctx *sql.Context, asOf interface{}) (*doltdb.RootVal
 }
 
 func (db Database) getRootForTime(ctx *sql.Context, asOf time.Time) (*doltdb.RootValue, error) {
-	cs, err := doltdb.NewCommitSpec("HEAD")
-	if err != nil {
-		return nil, err
-	}
-
-	cm, err := db.ddb.Resolve(ctx, cs, db.rsr.CWBHeadRef())
+	cm, err := db.ddb.Resolve(ctx, db.rsr.CWBHeadSpec(), db.rsr.CWBHeadRef())
 	if err != nil {
 		return nil, err
 	}
@@ -348,7 +400,7 @@ func (db Database) getTable(ctx *sql.Context, root *doltdb.RootValue, tableName
 	var table sql.Table
 
 	readonlyTable := NewDoltTable(tableName, sch, tbl, db)
-	if doltdb.IsReadOnlySystemTable(tableName) {
+	if db.readOnly || doltdb.IsReadOnlySystemTable(tableName) {
 		table = &readonlyTable
 	} else if doltdb.HasDoltPrefix(tableName) {
 		table = &WritableDoltTable{DoltTable: readonlyTable, db: db}
@@ -420,7 +472,7 @@ func (db Database) GetRoot(ctx *sql.Context) (*doltdb.RootValue, error) {
 		if !dbRootOk {
 			return nil, fmt.Errorf("value for '%s' not found", key)
 		} else {
-			err := db.SetRoot(ctx, currRoot.root)
+			err := dsess.SetRoot(ctx, db.name, currRoot.root)
 
 			if err != nil {
 				return nil, err
@@ -458,48 +510,52 @@ func (db Database) GetRoot(ctx *sql.Context) (*doltdb.RootValue, error) {
 }
 
 // Set a new root value for the database. Can be used if the dolt working
-// set value changes outside of the basic SQL execution engine.
+// set value changes outside of the basic SQL execution engine. An error is
+// returned if the database can't be written to, and the session's pending
+// edits to it are discarded.
 func (db Database) SetRoot(ctx *sql.Context, newRoot *doltdb.RootValue) error {
-	h, err := newRoot.HashOf()
-
-	if err != nil {
-		return err
-	}
-
-	hashStr := h.String()
-	key := db.WorkingKey()
-
-	err = ctx.Session.Set(ctx, key, hashType, hashStr)
-
-	if err != nil {
-		return err
-	}
-
 	dsess := DSessFromSess(ctx.Session)
-	dsess.dbRoots[db.name] = dbRoot{hashStr, newRoot}
 
-	err = dsess.dbEditors[db.name].SetRoot(ctx, newRoot)
-	if err != nil {
+	if err := db.CheckWrite(); err != nil {
+		if current, ok := dsess.dbRoots[db.name]; ok {
+			if resetErr := dsess.dbEditors[db.name].SetRoot(ctx, current.root); resetErr != nil {
+				return resetErr
+			}
+		}
 		return err
 	}
 
-	return nil
+	return dsess.SetRoot(ctx, db.name, newRoot)
 }
 
 // LoadRootFromRepoState loads the root value from the repo state's working hash, then calls SetRoot with the loaded
-// root value.
+// root value. The loaded root is the ancestor that the session's current transaction is merged against.
 func (db Database) LoadRootFromRepoState(ctx *sql.Context) error {
+	dsess := DSessFromSess(ctx.Session)
+	if err := dsess.syncRevisionHead(ctx, db.name, db.rsr); err != nil {
+		return err
+	}
+
 	workingHash := db.rsr.WorkingHash()
 	root, err := db.ddb.ReadRootValue(ctx, workingHash)
 	if err != nil {
 		return err
 	}
 
-	return db.SetRoot(ctx, root)
+	if err = dsess.SetRoot(ctx, db.name, root); err != nil {
+		return err
+	}
+
+	dsess.txRoots[db.name] = dsess.dbRoots[db.name]
+	return nil
 }
 
 // DropTable drops the table with the name given
 func (db Database) DropTable(ctx *sql.Context, tableName string) error {
+	if db.readOnly {
+		return ErrReadOnlyDatabase.New(db.name)
+	}
+
 	root, err := db.GetRoot(ctx)
 
 	if err != nil {
@@ -542,6 +598,10 @@ func (db Database) CreateTable(ctx *sql.Context, tableName string, sch sql.Schem
 
 // Unlike the exported version CreateTable, createSqlTable doesn't enforce any table name checks.
 func (db Database) createSqlTable(ctx *sql.Context, tableName string, sch sql.Schema) error {
+	if db.readOnly {
+		return ErrReadOnlyDatabase.New(db.name)
+	}
+
 	root, err := db.GetRoot(ctx)
 	if err != nil {
 		return err
@@ -558,6 +618,17 @@ func (db Database) createSqlTable(ctx *sql.Context, tableName string, sch sql.Sc
 		return err
 	}
 
+	// checks declared by a CREATE TABLE statement are created along with the table
+	for _, check := range declaredChecks(ctx, db.name, tableName) {
+		name := check.Name
+		if name == "" {
+			name = generateCheckName(tableName, doltSch)
+		}
+		if err := addCheck(ctx, doltSch, name, check.Expression, check.Enforced); err != nil {
+			return err
+		}
+	}
+
 	return db.createDoltTable(ctx, tableName, root, doltSch)
 }
 
@@ -596,6 +667,10 @@ func (db Database) createDoltTable(ctx *sql.Context, tableName string, root *dol
 
 // RenameTable implements sql.TableRenamer
 func (db Database) RenameTable(ctx *sql.Context, oldName, newName string) error {
+	if db.readOnly {
+		return ErrReadOnlyDatabase.New(db.name)
+	}
+
 	root, err := db.GetRoot(ctx)
 
 	if err != nil {
@@ -747,6 +822,10 @@ func (db Database) DropTrigger(ctx *sql.Context, name string) error {
 }
 
 func (db Database) addFragToSchemasTable(ctx *sql.Context, fragType, name, definition string, existingErr error) (retErr error) {
+	if db.readOnly {
+		return ErrReadOnlyDatabase.New(db.name)
+	}
+
 	tbl, err := GetOrCreateDoltSchemasTable(ctx, db)
 	if err != nil {
 		return err
@@ -800,6 +879,10 @@ func (db Database) addFragToSchemasTable(ctx *sql.Context, fragType, name, defin
 }
 
 func (db Database) dropFragFromSchemasTable(ctx *sql.Context, fragType, name string, missingErr error) error {
+	if db.readOnly {
+		return ErrReadOnlyDatabase.New(db.name)
+	}
+
 	stbl, found, err := db.GetTableInsensitive(ctx, doltdb.SchemasTableName)
 	if err != nil {
 		return err
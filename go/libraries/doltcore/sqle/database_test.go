@@ -15,9 +15,14 @@
 package sqle
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
 )
 
 func testKeyFunc(t *testing.T, keyFunc func(string) (bool, string), testVal string, expectedIsKey bool, expectedDBName string) {
@@ -34,3 +39,61 @@ func TestIsKeyFuncs(t *testing.T) {
 	testKeyFunc(t, IsHeadKey, "dolt_working", false, "")
 	testKeyFunc(t, IsWorkingKey, "dolt_working", true, "dolt")
 }
+
+func TestSplitRevisionDbName(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		revision string
+		ok       bool
+	}{
+		{"mydb", "mydb", "", false},
+		{"mydb/feature-x", "mydb", "feature-x", true},
+		{"mydb/feature/nested", "mydb", "feature/nested", true},
+		{"/feature", "/feature", "", false},
+		{"mydb/", "mydb/", "", false},
+	}
+
+	for _, test := range tests {
+		base, revision, ok := SplitRevisionDbName(test.name)
+		assert.Equal(t, test.base, base, test.name)
+		assert.Equal(t, test.revision, revision, test.name)
+		assert.Equal(t, test.ok, ok, test.name)
+	}
+}
+
+func TestLockWorkingSet(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	db := NewDatabase("dolt", dEnv.DbData())
+	other := NewDatabase("other", dEnv.DbData())
+
+	sess1, sess2 := DefaultDoltSession(), DefaultDoltSession()
+	require.NoError(t, sess1.AddDB(ctx, db))
+	require.NoError(t, sess1.AddDB(ctx, other))
+	require.NoError(t, sess2.AddDB(ctx, db))
+
+	unlock := sess1.LockWorkingSet("dolt")
+
+	// the lock of another database isn't held
+	sess1.LockWorkingSet("other")()
+
+	locked := make(chan struct{})
+	go func() {
+		sess2.LockWorkingSet("dolt")()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("the working set was locked by two sessions at once")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the working set wasn't unlocked")
+	}
+}
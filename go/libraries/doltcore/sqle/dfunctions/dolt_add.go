@@ -45,6 +45,13 @@ func (d DoltAddFunc) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 		return 1, fmt.Errorf("Could not load database %s", dbName)
 	}
 
+	if err := dSess.CheckWrite(dbName); err != nil {
+		return 1, err
+	}
+
+	unlock := dSess.LockWorkingSet(dbName)
+	defer unlock()
+
 	ap := cli.CreateAddArgParser()
 	args, err := getDoltArgs(ctx, row, d.Children())
 
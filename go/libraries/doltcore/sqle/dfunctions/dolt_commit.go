@@ -51,6 +51,17 @@ func (d DoltCommitFunc) Eval(ctx *sql.Context, row sql.Row) (interface{}, error)
 		return nil, fmt.Errorf("Could not load %s", dbName)
 	}
 
+	if err := dSess.CheckWrite(dbName); err != nil {
+		return nil, err
+	}
+
+	unlock := dSess.LockWorkingSet(dbName)
+	defer unlock()
+
+	if err := sqle.CheckRevisionHead(ctx, dbData.Rsr); err != nil {
+		return nil, err
+	}
+
 	ddb := dbData.Ddb
 	rsr := dbData.Rsr
 
@@ -46,6 +46,13 @@ func (d DoltResetFunc) Eval(ctx *sql.Context, row sql.Row) (interface{}, error)
 		return 1, fmt.Errorf("Could not load database %s", dbName)
 	}
 
+	if err := dSess.CheckWrite(dbName); err != nil {
+		return 1, err
+	}
+
+	unlock := dSess.LockWorkingSet(dbName)
+	defer unlock()
+
 	ap := cli.CreateResetArgParser()
 	args, err := getDoltArgs(ctx, row, d.Children())
 
@@ -73,6 +73,10 @@ func (cf *MergeFunc) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 		return nil, sql.ErrDatabaseNotFound.New(dbName)
 	}
 
+	if err := sess.CheckWrite(dbName); err != nil {
+		return nil, err
+	}
+
 	root, ok := sess.GetRoot(dbName)
 	if !ok {
 		return nil, sql.ErrDatabaseNotFound.New(dbName)
@@ -22,6 +22,7 @@ import (
 	"github.com/dolthub/go-mysql-server/sql"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
 	"github.com/dolthub/dolt/go/store/types"
 )
 
@@ -215,6 +216,7 @@ type DoltMapIter struct {
 	kvGet         KVGetFunc
 	closeKVGetter func() error
 	conv          *KVToSqlRowConverter
+	limiter       *sqlutil.QueryLimiter
 }
 
 // NewDoltMapIter returns a new DoltMapIter
@@ -224,6 +226,7 @@ func NewDoltMapIter(ctx context.Context, keyValGet KVGetFunc, closeKVGetter func
 		kvGet:         keyValGet,
 		closeKVGetter: closeKVGetter,
 		conv:          conv,
+		limiter:       sqlutil.NewQueryLimiter(ctx),
 	}
 }
 
@@ -231,7 +234,7 @@ func NewDoltMapIter(ctx context.Context, keyValGet KVGetFunc, closeKVGetter func
 func (dmi *DoltMapIter) Next() (sql.Row, error) {
 	k, v, err := dmi.kvGet(dmi.ctx)
 
-	if err != nil {
+	if err := dmi.limiter.RowRead(err); err != nil {
 		return nil, err
 	}
 
@@ -17,16 +17,24 @@ package sqle
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	"gopkg.in/src-d/go-errors.v1"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
 	"github.com/dolthub/dolt/go/store/hash"
 )
 
+var ErrTransactionConflict = errors.NewKind("transaction on database %s could not be committed because of changes committed by another transaction: %s; the transaction was rolled back")
+
 type dbRoot struct {
 	hashStr string
 	root    *doltdb.RootValue
@@ -39,8 +47,21 @@ type DoltSession struct {
 	sql.Session
 	dbRoots   map[string]dbRoot
 	dbDatas   map[string]env.DbData
+	dbs       map[string]Database
 	dbEditors map[string]*editor.TableEditSession
 	caches    map[string]TableCache
+	// txRoots are the working roots of each database when the current transaction started, which transactions are
+	// merged against when they commit.
+	txRoots map[string]dbRoot
+	inTx    bool
+	// txAutocommit is the value of autocommit before the current transaction began
+	txAutocommit interface{}
+	// commitListener is notified of each database a transaction commit writes to
+	commitListener CommitListener
+	// queryPid and queryRows are the process id of the latest query to read rows and the number of rows it has read
+	queryMu   *sync.Mutex
+	queryPid  uint64
+	queryRows *uint64
 
 	Username string
 	Email    string
@@ -68,8 +89,11 @@ func DefaultDoltSession() *DoltSession {
 		Session:   sql.NewBaseSession(),
 		dbRoots:   make(map[string]dbRoot),
 		dbDatas:   make(map[string]env.DbData),
+		dbs:       make(map[string]Database),
 		dbEditors: make(map[string]*editor.TableEditSession),
 		caches:    make(map[string]TableCache),
+		txRoots:   make(map[string]dbRoot),
+		queryMu:   &sync.Mutex{},
 		Username:  "",
 		Email:     "",
 	}
@@ -83,17 +107,20 @@ func NewDoltSession(ctx context.Context, sqlSess sql.Session, username, email st
 	dbEditors := make(map[string]*editor.TableEditSession)
 	for _, db := range dbs {
 		dbDatas[db.Name()] = env.DbData{Rsw: db.rsw, Ddb: db.ddb, Rsr: db.rsr, Drw: db.drw}
-		dbEditors[db.Name()] = editor.CreateTableEditSession(nil, editor.TableEditSessionProps{})
+		dbEditors[db.Name()] = editor.CreateTableEditSession(nil, editor.TableEditSessionProps{NewRowValidator: sqlutil.NewCheckRowValidator})
 	}
 
 	sess := &DoltSession{
 		Session:   sqlSess,
 		dbRoots:   dbRoots,
 		dbDatas:   dbDatas,
+		dbs:       make(map[string]Database),
 		dbEditors: dbEditors,
 		Username:  username,
 		Email:     email,
 		caches:    make(map[string]TableCache),
+		txRoots:   make(map[string]dbRoot),
+		queryMu:   &sync.Mutex{},
 	}
 	for _, db := range dbs {
 		err := sess.AddDB(ctx, db)
@@ -115,26 +142,309 @@ func TableCacheFromSess(sess sql.Session, dbName string) TableCache {
 	return sess.(*DoltSession).caches[dbName]
 }
 
+var _ sqlutil.QueryRowCounter = (*DoltSession)(nil)
+
+// QueryRowCount implements sqlutil.QueryRowCounter. Only the rows read by the session's latest query are counted.
+func (sess *DoltSession) QueryRowCount(pid uint64) *uint64 {
+	sess.queryMu.Lock()
+	defer sess.queryMu.Unlock()
+
+	if sess.queryRows == nil || sess.queryPid != pid {
+		sess.queryPid = pid
+		sess.queryRows = new(uint64)
+	}
+
+	return sess.queryRows
+}
+
+// QueryRowsRead implements sqlutil.QueryRowCounter.
+func (sess *DoltSession) QueryRowsRead() (pid uint64, rows uint64) {
+	sess.queryMu.Lock()
+	defer sess.queryMu.Unlock()
+
+	if sess.queryRows == nil {
+		return 0, 0
+	}
+
+	return sess.queryPid, atomic.LoadUint64(sess.queryRows)
+}
+
+// BeginTransaction starts a transaction which lasts until it is committed or rolled back, as with START TRANSACTION.
+// Autocommit is disabled until the transaction ends.
+func (sess *DoltSession) BeginTransaction(ctx *sql.Context) error {
+	if err := sess.StartTransaction(ctx); err != nil {
+		return err
+	}
+
+	_, autocommit := sess.Session.Get(sql.AutoCommitSessionVar)
+	if err := sess.Session.Set(ctx, sql.AutoCommitSessionVar, sql.Boolean, false); err != nil {
+		return err
+	}
+
+	sess.inTx = true
+	sess.txAutocommit = autocommit
+	return nil
+}
+
+// endTransaction ends the transaction started by BeginTransaction, if there is one, restoring autocommit.
+func (sess *DoltSession) endTransaction(ctx *sql.Context) error {
+	if !sess.inTx {
+		return nil
+	}
+
+	sess.inTx = false
+	if sess.txAutocommit == nil {
+		return nil
+	}
+
+	return sess.Session.Set(ctx, sql.AutoCommitSessionVar, sql.Boolean, sess.txAutocommit)
+}
+
+// InTransaction returns whether a transaction started by BeginTransaction is open
+func (sess *DoltSession) InTransaction() bool {
+	return sess.inTx
+}
+
+// StartTransaction brings every database without uncommitted changes up to date with its working set. The working root
+// of each database becomes the ancestor that changes to it are merged against when the transaction commits.
+func (sess *DoltSession) StartTransaction(ctx *sql.Context) error {
+	for dbName, dbData := range sess.dbDatas {
+		current, ok := sess.dbRoots[dbName]
+		if txRoot, inTx := sess.txRoots[dbName]; ok && inTx && current.hashStr != txRoot.hashStr {
+			continue
+		}
+
+		if err := sess.loadWorkingRoot(ctx, dbName, dbData); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RollbackTransaction discards the uncommitted changes to every database and ends the current transaction.
+func (sess *DoltSession) RollbackTransaction(ctx *sql.Context) error {
+	if err := sess.endTransaction(ctx); err != nil {
+		return err
+	}
+
+	for dbName, dbData := range sess.dbDatas {
+		if err := sess.loadWorkingRoot(ctx, dbName, dbData); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CommitListener is called with the name of each database a transaction commit writes to, and with the error which
+// rolled the transaction back if the commit failed.
+type CommitListener func(dbName string, err error)
+
+// SetCommitListener sets the listener which is notified of the transaction commits of this session.
+func (sess *DoltSession) SetCommitListener(listener CommitListener) {
+	sess.commitListener = listener
+}
+
+func (sess *DoltSession) notifyCommit(dbName string, err error) {
+	if sess.commitListener != nil {
+		sess.commitListener(dbName, err)
+	}
+}
+
+// CommitTransaction commits the changes the current transaction made to each database to its working set and ends the
+// transaction. If another transaction has committed to a database since this one started, the changes are merged with
+// a three-way merge. If the merge has conflicts the transaction is rolled back and an error is returned.
 func (sess *DoltSession) CommitTransaction(ctx *sql.Context) error {
-	currentDb := sess.GetCurrentDatabase()
-	if currentDb == "" {
-		return sql.ErrNoDatabaseSelected.New()
+	if err := sess.endTransaction(ctx); err != nil {
+		return err
 	}
 
-	dbRoot, ok := sess.dbRoots[currentDb]
-	if !ok {
-		return sql.ErrDatabaseNotFound.New(currentDb)
+	for dbName, dbData := range sess.dbDatas {
+		dbRoot, ok := sess.dbRoots[dbName]
+		if !ok {
+			continue
+		}
+
+		// without the root the transaction started from, only the current database is committed
+		txRoot, hasTxRoot := sess.txRoots[dbName]
+		if hasTxRoot && dbRoot.hashStr == txRoot.hashStr || !hasTxRoot && dbName != ctx.GetCurrentDatabase() {
+			continue
+		}
+
+		rollback, err := sess.commitWorkingRoot(ctx, dbName, dbData, dbRoot, txRoot, hasTxRoot)
+		if rollback {
+			sess.notifyCommit(dbName, err)
+			if rbErr := sess.RollbackTransaction(ctx); rbErr != nil {
+				return rbErr
+			}
+			return err
+		} else if err != nil {
+			return err
+		}
+
+		sess.notifyCommit(dbName, nil)
 	}
 
-	dbData := sess.dbDatas[currentDb]
+	return nil
+}
+
+// commitWorkingRoot commits the root |dbRoot| of the database |dbName| to its working set, merging it with the changes
+// committed since the transaction started at |txRoot|. rollback is true if the transaction must be rolled back because
+// the write isn't allowed or the merge failed.
+func (sess *DoltSession) commitWorkingRoot(ctx *sql.Context, dbName string, dbData env.DbData, dbRoot, txRoot dbRoot, hasTxRoot bool) (rollback bool, err error) {
+	unlock := sess.LockWorkingSet(dbName)
+	defer unlock()
+
+	if err := sess.checkCommitWrite(dbName, dbRoot, dbData); err != nil {
+		return true, err
+	}
 
 	root := dbRoot.root
+	if workingHash := dbData.Rsr.WorkingHash(); hasTxRoot && workingHash.String() != txRoot.hashStr {
+		workingRoot, err := dbData.Ddb.ReadRootValue(ctx, workingHash)
+		if err != nil {
+			return false, err
+		}
+
+		root, err = mergeTransaction(ctx, dbName, root, workingRoot, txRoot.root)
+		if err != nil {
+			return true, err
+		}
+	}
+
 	h, err := dbData.Ddb.WriteRootValue(ctx, root)
+	if err != nil {
+		return false, err
+	}
+
+	if err = dbData.Rsw.SetWorkingHash(ctx, h); err != nil {
+		return false, err
+	}
+
+	if err = sess.SetRoot(ctx, dbName, root); err != nil {
+		return false, err
+	}
+
+	sess.txRoots[dbName] = sess.dbRoots[dbName]
+	return false, nil
+}
+
+// LockWorkingSet locks the working set of the database |dbName| and returns the function which unlocks it. Every write
+// to the working or staged root of a database is made while holding its lock, so that a root read from the working set
+// can't be replaced by another session before the write based on it is made.
+func (sess *DoltSession) LockWorkingSet(dbName string) (unlock func()) {
+	db, ok := sess.dbs[dbName]
+	if !ok || db.wsLock == nil {
+		return func() {}
+	}
+
+	db.wsLock.Lock()
+	return db.wsLock.Unlock
+}
+
+// syncRevisionHead brings the working set of the database |dbName| up to date with its branch if it is a branch
+// revision database.
+func (sess *DoltSession) syncRevisionHead(ctx context.Context, dbName string, rsr env.RepoStateReader) error {
+	unlock := sess.LockWorkingSet(dbName)
+	defer unlock()
+	return syncRevisionHead(ctx, rsr)
+}
+
+// checkCommitWrite returns an error if committing |dbRoot| to the working set of the database |dbName| is a write the
+// database doesn't allow.
+func (sess *DoltSession) checkCommitWrite(dbName string, dbRoot dbRoot, dbData env.DbData) error {
+	if dbRoot.hashStr == dbData.Rsr.WorkingHash().String() {
+		return nil
+	}
+
+	return sess.CheckWrite(dbName)
+}
+
+// mergeTransaction merges the root |ourRoot| of a transaction on the database |dbName| with |workingRoot|, the working
+// root committed by other transactions since it started at |txRoot|.
+func mergeTransaction(ctx *sql.Context, dbName string, ourRoot, workingRoot, txRoot *doltdb.RootValue) (*doltdb.RootValue, error) {
+	mergedRoot, stats, err := merge.MergeRoots(ctx, ourRoot, workingRoot, txRoot)
+	if err != nil {
+		return nil, ErrTransactionConflict.New(dbName, err.Error())
+	}
+
+	var conflicted []string
+	for tblName, tblStats := range stats {
+		if tblStats.Conflicts > 0 {
+			conflicted = append(conflicted, tblName)
+		}
+	}
+
+	if len(conflicted) > 0 {
+		sort.Strings(conflicted)
+		return nil, ErrTransactionConflict.New(dbName, "conflicts in table(s) "+strings.Join(conflicted, ", "))
+	}
+
+	return mergedRoot, nil
+}
+
+// loadWorkingRoot sets the root of the database |dbName| to its working root, which becomes the ancestor of the
+// current transaction.
+func (sess *DoltSession) loadWorkingRoot(ctx *sql.Context, dbName string, dbData env.DbData) error {
+	if err := sess.syncRevisionHead(ctx, dbName, dbData.Rsr); err != nil {
+		return err
+	}
+
+	workingHash := dbData.Rsr.WorkingHash()
+	if current, ok := sess.dbRoots[dbName]; ok && current.hashStr == workingHash.String() {
+		sess.txRoots[dbName] = current
+		return nil
+	}
+
+	root, err := dbData.Ddb.ReadRootValue(ctx, workingHash)
 	if err != nil {
 		return err
 	}
 
-	return dbData.Rsw.SetWorkingHash(ctx, h)
+	if err = sess.SetRoot(ctx, dbName, root); err != nil {
+		return err
+	}
+
+	sess.txRoots[dbName] = sess.dbRoots[dbName]
+	return nil
+}
+
+// SetRoot sets the root value of the database |dbName| in this session
+func (sess *DoltSession) SetRoot(ctx *sql.Context, dbName string, newRoot *doltdb.RootValue) error {
+	h, err := newRoot.HashOf()
+
+	if err != nil {
+		return err
+	}
+
+	hashStr := h.String()
+	err = sess.Set(ctx, dbName+WorkingKeySuffix, hashType, hashStr)
+
+	if err != nil {
+		return err
+	}
+
+	sess.dbRoots[dbName] = dbRoot{hashStr, newRoot}
+
+	return sess.dbEditors[dbName].SetRoot(ctx, newRoot)
+}
+
+// CheckWrite returns an error if the database |dbName| can't be written to, because it is read only or because its
+// checked out branch is protected.
+func (sess *DoltSession) CheckWrite(dbName string) error {
+	db, ok := sess.dbs[dbName]
+	if !ok {
+		return nil
+	}
+
+	return db.CheckWrite()
+}
+
+// HasDB returns whether the database named |dbName| has been added to the session
+func (sess *DoltSession) HasDB(dbName string) bool {
+	_, ok := sess.dbDatas[dbName]
+	return ok
 }
 
 // GetDoltDB returns the *DoltDB for a given database by name
@@ -358,8 +668,9 @@ func (sess *DoltSession) AddDB(ctx context.Context, db Database) error {
 	ddb := db.GetDoltDB()
 
 	sess.dbDatas[db.Name()] = env.DbData{Drw: drw, Rsr: rsr, Rsw: rsw, Ddb: ddb}
+	sess.dbs[db.Name()] = db
 
-	sess.dbEditors[db.Name()] = editor.CreateTableEditSession(nil, editor.TableEditSessionProps{})
+	sess.dbEditors[db.Name()] = editor.CreateTableEditSession(nil, editor.TableEditSessionProps{NewRowValidator: sqlutil.NewCheckRowValidator})
 
 	sess.caches[db.name] = newTableCache()
 
@@ -0,0 +1,185 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/blame"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+var _ sql.Table = (*BlameTable)(nil)
+
+// BlameTable is a sql.Table implementation that annotates each row of a user table with the commit which last
+// modified it, as of the head commit.
+type BlameTable struct {
+	tblName string
+	ddb     *doltdb.DoltDB
+	head    *doltdb.Commit
+	sch     schema.Schema
+	sqlSch  sql.Schema
+}
+
+// NewBlameTable returns a new BlameTable for the table named |tblName| as of the commit |head|
+func NewBlameTable(ctx *sql.Context, tblName string, ddb *doltdb.DoltDB, head *doltdb.Commit) (sql.Table, error) {
+	root, err := head.GetRootValue()
+	if err != nil {
+		return nil, err
+	}
+
+	tbl, tblName, ok, err := root.GetTableInsensitive(ctx, tblName)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, sql.ErrTableNotFound.New(doltdb.DoltBlameTablePrefix + tblName)
+	}
+
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if schema.IsKeyless(sch) {
+		return nil, fmt.Errorf("cannot blame table %s as it has no primary key", tblName)
+	}
+
+	tableName := doltdb.DoltBlameTablePrefix + tblName
+	sqlSch, err := sqlutil.FromDoltSchema(tableName, sch)
+	if err != nil {
+		return nil, err
+	}
+
+	var blameSch sql.Schema
+	for _, col := range sqlSch {
+		if col.PrimaryKey {
+			blameSch = append(blameSch, col)
+		}
+	}
+
+	blameSch = append(blameSch,
+		&sql.Column{Name: CommitHashCol, Type: sql.Text, Source: tableName},
+		&sql.Column{Name: CommitterCol, Type: sql.Text, Source: tableName},
+		&sql.Column{Name: "email", Type: sql.Text, Source: tableName},
+		&sql.Column{Name: CommitDateCol, Type: sql.Datetime, Source: tableName},
+		&sql.Column{Name: "message", Type: sql.Text, Source: tableName},
+	)
+
+	return &BlameTable{
+		tblName: tblName,
+		ddb:     ddb,
+		head:    head,
+		sch:     sch,
+		sqlSch:  blameSch,
+	}, nil
+}
+
+// Name returns the name of the table
+func (bt *BlameTable) Name() string {
+	return doltdb.DoltBlameTablePrefix + bt.tblName
+}
+
+// String returns a string identifying the table
+func (bt *BlameTable) String() string {
+	return doltdb.DoltBlameTablePrefix + bt.tblName
+}
+
+// Schema returns the sql.Schema of the table
+func (bt *BlameTable) Schema() sql.Schema {
+	return bt.sqlSch
+}
+
+// Partitions returns a PartitionIter which can be used to get all the data partitions
+func (bt *BlameTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return sqlutil.NewSinglePartitionIter(), nil
+}
+
+// PartitionRows returns a RowIter for the given partition
+func (bt *BlameTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	bg, err := blame.BlameGraphFromCommit(ctx, bt.ddb, bt.head, bt.tblName)
+	if err != nil {
+		return nil, err
+	}
+
+	rowData, err := blame.RowsFromCommit(ctx, bt.head, bt.tblName)
+	if err != nil {
+		return nil, err
+	}
+
+	itr, err := rowData.Iterator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &blameRowIter{ctx: ctx, bg: *bg, sch: bt.sch, nbf: rowData.Format(), itr: itr}, nil
+}
+
+type blameRowIter struct {
+	ctx *sql.Context
+	bg  blame.BlameGraph
+	sch schema.Schema
+	nbf *types.NomsBinFormat
+	itr types.MapIterator
+}
+
+// Next retrieves the next row. It will return io.EOF if it's the last row.
+func (itr *blameRowIter) Next() (sql.Row, error) {
+	key, _, err := itr.itr.Next(itr.ctx)
+	if err != nil {
+		return nil, err
+	} else if key == nil {
+		return nil, io.EOF
+	}
+
+	h, err := key.Hash(itr.nbf)
+	if err != nil {
+		return nil, err
+	}
+	info := itr.bg[h]
+
+	taggedVals, err := row.ParseTaggedValues(key.(types.Tuple))
+	if err != nil {
+		return nil, err
+	}
+
+	pkCols := itr.sch.GetPKCols()
+	r := make(sql.Row, 0, pkCols.Size()+5)
+	err = pkCols.Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		nomsVal, _ := taggedVals.Get(tag)
+		val, err := col.TypeInfo.ConvertNomsValueToValue(nomsVal)
+		if err != nil {
+			return true, err
+		}
+		r = append(r, val)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(r, info.CommitHash, info.Author, info.Email, info.TimestampTime(), info.Description), nil
+}
+
+// Close closes the iterator.
+func (itr *blameRowIter) Close() error {
+	return nil
+}
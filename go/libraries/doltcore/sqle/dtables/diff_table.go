@@ -225,6 +225,7 @@ type diffRowItr struct {
 	sch            schema.Schema
 	fromCommitInfo commitInfo
 	toCommitInfo   commitInfo
+	limiter        *sqlutil.QueryLimiter
 }
 
 type commitInfo struct {
@@ -238,7 +239,7 @@ type commitInfo struct {
 func (itr *diffRowItr) Next() (sql.Row, error) {
 	r, _, err := itr.diffSrc.NextDiff()
 
-	if err != nil {
+	if err := itr.limiter.RowRead(err); err != nil {
 		return nil, err
 	}
 
@@ -375,6 +376,7 @@ func (dp diffPartition) getRowIter(ctx *sql.Context, ddb *doltdb.DoltDB, ss *sch
 		sch:            joiner.GetSchema(),
 		fromCommitInfo: fromCmInfo,
 		toCommitInfo:   toCmInfo,
+		limiter:        sqlutil.NewQueryLimiter(ctx),
 	}, nil
 }
 
@@ -296,6 +296,7 @@ type rowItrForTableAtCommit struct {
 	toSuperSchConv *rowconv.RowConverter
 	extraVals      map[uint64]types.Value
 	empty          bool
+	limiter        *sqlutil.QueryLimiter
 }
 
 func newRowItrForTableAtCommit(
@@ -391,7 +392,8 @@ func newRowItrForTableAtCommit(
 			dateCol.Tag:      types.Timestamp(meta.Time()),
 			committerCol.Tag: types.String(meta.Name),
 		},
-		empty: false,
+		empty:   false,
+		limiter: sqlutil.NewQueryLimiter(ctx),
 	}, nil
 }
 
@@ -404,7 +406,7 @@ func (tblItr *rowItrForTableAtCommit) Next() (sql.Row, error) {
 
 	r, err := tblItr.rd.ReadRow(tblItr.ctx)
 
-	if err != nil {
+	if err := tblItr.limiter.RowRead(err); err != nil {
 		return nil, err
 	}
 
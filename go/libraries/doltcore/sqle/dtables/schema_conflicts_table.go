@@ -0,0 +1,122 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlfmt"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+)
+
+var _ sql.Table = (*SchemaConflictsTable)(nil)
+
+// SchemaConflictsTable is a sql.Table implementation that implements a system table which shows the schema conflicts
+// from a merge which have not yet been resolved
+type SchemaConflictsTable struct {
+	root *doltdb.RootValue
+}
+
+// NewSchemaConflictsTable creates a SchemaConflictsTable
+func NewSchemaConflictsTable(_ *sql.Context, root *doltdb.RootValue) sql.Table {
+	return &SchemaConflictsTable{root: root}
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// SchemaConflictsTableName
+func (sct *SchemaConflictsTable) Name() string {
+	return doltdb.SchemaConflictsTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// SchemaConflictsTableName
+func (sct *SchemaConflictsTable) String() string {
+	return doltdb.SchemaConflictsTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the schema conflicts system table
+func (sct *SchemaConflictsTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "table_name", Type: sql.Text, Source: doltdb.SchemaConflictsTableName, PrimaryKey: true, Nullable: false},
+		{Name: "base_schema", Type: sql.Text, Source: doltdb.SchemaConflictsTableName, PrimaryKey: false, Nullable: true},
+		{Name: "our_schema", Type: sql.Text, Source: doltdb.SchemaConflictsTableName, PrimaryKey: false, Nullable: false},
+		{Name: "their_schema", Type: sql.Text, Source: doltdb.SchemaConflictsTableName, PrimaryKey: false, Nullable: true},
+		{Name: "description", Type: sql.Text, Source: doltdb.SchemaConflictsTableName, PrimaryKey: false, Nullable: false},
+	}
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data.  Currently the data is unpartitioned.
+func (sct *SchemaConflictsTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return sqlutil.NewSinglePartitionIter(), nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (sct *SchemaConflictsTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	tblNames, err := sct.root.TablesInConflict(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []sql.Row
+	for _, tblName := range tblNames {
+		tbl, ok, err := sct.root.GetTable(ctx, tblName)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+
+		pending, err := tbl.GetSchemaConflicts(ctx)
+		if err == doltdb.ErrNoConflicts {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		sc, err := merge.GetSchemaConflicts(ctx, tblName, tbl)
+		if err != nil {
+			return nil, err
+		}
+
+		ourSch, err := tbl.GetSchema(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var baseSchStr, theirSchStr interface{}
+		if pending.Theirs != nil {
+			baseSch, err := pending.Ancestor.GetSchema(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			theirSch, err := pending.Theirs.GetSchema(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			baseSchStr = sqlfmt.CreateTableStmt(tblName, baseSch)
+			theirSchStr = sqlfmt.CreateTableStmt(tblName, theirSch)
+		}
+
+		rows = append(rows, sql.NewRow(tblName, baseSchStr, sqlfmt.CreateTableStmt(tblName, ourSch), theirSchStr, strings.Join(sc.Descriptions(), "\n")))
+	}
+
+	return sql.RowsToRowIter(rows...), nil
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/dolthub/go-mysql-server/sql"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
 )
 
 var _ sql.Table = (*TableOfTablesInConflict)(nil)
@@ -123,13 +124,23 @@ func (dt *TableOfTablesInConflict) Partitions(ctx *sql.Context) (sql.PartitionIt
 		if err != nil {
 			return nil, err
 		} else if ok {
+			// tables with schema conflicts have no row conflicts until the schema conflicts are resolved
+			var numConflicts uint64
 			schemas, m, err := tbl.GetConflicts(ctx)
 
-			if err != nil {
+			if err == nil {
+				numConflicts = m.Len()
+			} else if err != doltdb.ErrNoConflicts {
 				return nil, err
 			}
 
-			partitions = append(partitions, &tableInConflict{tblName, m.Len(), false, schemas})
+			if sc, err := merge.GetSchemaConflicts(ctx, tblName, tbl); err == nil {
+				numConflicts += uint64(sc.Count())
+			} else if err != doltdb.ErrNoConflicts {
+				return nil, err
+			}
+
+			partitions = append(partitions, &tableInConflict{tblName, numConflicts, false, schemas})
 		}
 	}
 
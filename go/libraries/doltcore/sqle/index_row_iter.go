@@ -22,6 +22,7 @@ import (
 	"github.com/dolthub/go-mysql-server/sql"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
 	"github.com/dolthub/dolt/go/libraries/utils/async"
 	"github.com/dolthub/dolt/go/libraries/utils/set"
 	"github.com/dolthub/dolt/go/store/types"
@@ -36,6 +37,7 @@ type indexLookupRowIterAdapter struct {
 	rowChan chan sql.Row
 	err     error
 	buffer  []sql.Row
+	limiter *sqlutil.QueryLimiter
 }
 
 type keyPos struct {
@@ -61,6 +63,7 @@ func NewIndexLookupRowIterAdapter(ctx *sql.Context, idx DoltIndex, keyIter nomsK
 		ctx:     ctx,
 		rowChan: make(chan sql.Row, runtime.NumCPU()*10),
 		buffer:  make([]sql.Row, runtime.NumCPU()*5),
+		limiter: sqlutil.NewQueryLimiter(ctx),
 	}
 	go iter.queueRows()
 	return iter
@@ -70,11 +73,17 @@ func NewIndexLookupRowIterAdapter(ctx *sql.Context, idx DoltIndex, keyIter nomsK
 func (i *indexLookupRowIterAdapter) Next() (sql.Row, error) {
 	r, ok := <-i.rowChan
 	if !ok { // Only closes when we are finished iterating over the keys or an error has occurred.
-		if i.err != nil {
-			return nil, i.err
+		err := i.err
+		if err == nil {
+			err = io.EOF
 		}
-		return nil, io.EOF
+		return nil, i.limiter.RowRead(err)
 	}
+
+	if err := i.limiter.RowRead(nil); err != nil {
+		return nil, err
+	}
+
 	return r, nil
 }
 
@@ -210,6 +219,7 @@ type coveringIndexRowIterAdapter struct {
 	pkCols    *schema.ColCollection
 	nonPKCols *schema.ColCollection
 	nbf       *types.NomsBinFormat
+	limiter   *sqlutil.QueryLimiter
 }
 
 func NewCoveringIndexRowIterAdapter(ctx *sql.Context, idx DoltIndex, keyIter nomsKeyIter, resultCols []string) *coveringIndexRowIterAdapter {
@@ -243,6 +253,7 @@ func NewCoveringIndexRowIterAdapter(ctx *sql.Context, idx DoltIndex, keyIter nom
 		pkCols:    sch.GetPKCols(),
 		nonPKCols: sch.GetNonPKCols(),
 		nbf:       idx.TableData().Format(),
+		limiter:   sqlutil.NewQueryLimiter(ctx),
 	}
 }
 
@@ -250,7 +261,7 @@ func NewCoveringIndexRowIterAdapter(ctx *sql.Context, idx DoltIndex, keyIter nom
 func (ci *coveringIndexRowIterAdapter) Next() (sql.Row, error) {
 	key, err := ci.keyIter.ReadKey(ci.ctx)
 
-	if err != nil {
+	if err := ci.limiter.RowRead(err); err != nil {
 		return nil, err
 	}
 
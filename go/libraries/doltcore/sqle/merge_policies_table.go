@@ -0,0 +1,92 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+)
+
+var _ sql.InsertableTable = (*emptyMergePoliciesTable)(nil)
+
+// emptyMergePoliciesTable is the dolt_merge_policies table of a database which does not have any merge policies yet.
+// It has no rows, and creates the table the first time rows are inserted into it. Once the table exists it is a normal
+// WritableDoltTable.
+type emptyMergePoliciesTable struct {
+	db Database
+}
+
+func newEmptyMergePoliciesTable(db Database) sql.Table {
+	return &emptyMergePoliciesTable{db: db}
+}
+
+// Name implements sql.Table
+func (t *emptyMergePoliciesTable) Name() string {
+	return doltdb.MergePoliciesTableName
+}
+
+// String implements sql.Table
+func (t *emptyMergePoliciesTable) String() string {
+	return doltdb.MergePoliciesTableName
+}
+
+// Schema implements sql.Table
+func (t *emptyMergePoliciesTable) Schema() sql.Schema {
+	sch, err := sqlutil.FromDoltSchema(doltdb.MergePoliciesTableName, merge.MergePoliciesSchema)
+	if err != nil {
+		panic(err) // should never happen
+	}
+	return sch
+}
+
+// Partitions implements sql.Table
+func (t *emptyMergePoliciesTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return sqlutil.NewSinglePartitionIter(), nil
+}
+
+// PartitionRows implements sql.Table
+func (t *emptyMergePoliciesTable) PartitionRows(*sql.Context, sql.Partition) (sql.RowIter, error) {
+	return sql.RowsToRowIter(), nil
+}
+
+// Inserter implements sql.InsertableTable. It creates the dolt_merge_policies table and returns its inserter.
+func (t *emptyMergePoliciesTable) Inserter(ctx *sql.Context) sql.RowInserter {
+	root, err := t.db.GetRoot(ctx)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
+
+	err = t.db.createDoltTable(ctx, doltdb.MergePoliciesTableName, root, merge.MergePoliciesSchema)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
+
+	root, err = t.db.GetRoot(ctx)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
+
+	tbl, ok, err := t.db.getTable(ctx, root, doltdb.MergePoliciesTableName)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	} else if !ok {
+		return sqlutil.NewStaticErrorEditor(doltdb.ErrTableNotFound)
+	}
+
+	return tbl.(sql.InsertableTable).Inserter(ctx)
+}
@@ -0,0 +1,391 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privileges
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dfunctions"
+)
+
+const informationSchemaDB = "information_schema"
+
+// mutatingFunctions are the dolt functions which change the state of a database
+var mutatingFunctions = map[string]bool{
+	dfunctions.CommitFuncName:     true,
+	dfunctions.DoltAddFuncName:    true,
+	dfunctions.DoltCommitFuncName: true,
+	dfunctions.DoltResetFuncName:  true,
+	dfunctions.MergeFuncName:      true,
+	"reset":                       true,
+}
+
+var _ auth.Auth = (*Auth)(nil)
+
+// Auth is an auth.Auth implementation which authenticates users against a Store and authorizes each query using the
+// privileges granted to the user running it.
+type Auth struct {
+	store *Store
+	// readOnly is 1 if the server only allows reads. It is accessed atomically as it may change while the server runs.
+	readOnly int32
+}
+
+// NewAuth returns an Auth for the users in |store|. If |readOnly| is true, no user may run a query that requires any
+// privilege other than SELECT.
+func NewAuth(store *Store, readOnly bool) *Auth {
+	a := &Auth{store: store}
+	a.SetReadOnly(readOnly)
+	return a
+}
+
+// Store returns the store of users and privileges
+func (a *Auth) Store() *Store {
+	return a.store
+}
+
+// ReadOnly returns whether the server only allows reads
+func (a *Auth) ReadOnly() bool {
+	return atomic.LoadInt32(&a.readOnly) == 1
+}
+
+// SetReadOnly sets whether the server only allows reads
+func (a *Auth) SetReadOnly(readOnly bool) {
+	var val int32
+	if readOnly {
+		val = 1
+	}
+	atomic.StoreInt32(&a.readOnly, val)
+}
+
+// Mysql implements auth.Auth
+func (a *Auth) Mysql() mysql.AuthServer {
+	return &authServer{store: a.store}
+}
+
+// Allowed implements auth.Auth. The query of |ctx| is parsed to find the privileges it requires on each table it
+// references, and an error is returned if the user does not hold all of them.
+func (a *Auth) Allowed(ctx *sql.Context, permission auth.Permission) error {
+	user := ctx.Client().User
+	currentDB := ctx.GetCurrentDatabase()
+
+	reqs, err := requiredPrivileges(ctx.Query(), currentDB)
+	if err != nil {
+		// statements which vitess cannot parse are authorized by the permission the engine asks for. Reads need access
+		// to the current database, or SELECT on every database when there isn't one, as the tables they read are
+		// unknown.
+		if permission&auth.WritePerm != 0 {
+			reqs = []requirement{{db: Wildcard, tbl: Wildcard, privs: AllPrivileges}}
+		} else if currentDB != "" {
+			reqs = []requirement{{db: currentDB, tbl: Wildcard, access: true}}
+		} else {
+			reqs = []requirement{{db: Wildcard, tbl: Wildcard, privs: SelectPriv}}
+		}
+	}
+
+	readOnly := a.ReadOnly()
+	for _, req := range reqs {
+		if err := a.authorize(user, req, readOnly); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RequiresTablePrivileges returns the error to give when |user| runs a statement which needs |privs| on the table
+// |tbl| of the database |db| without holding them, or nil if the user may run it. It authorizes the statements the SQL
+// parser does not support, whose requirements Allowed can't find.
+func (a *Auth) RequiresTablePrivileges(user, db, tbl string, privs Privilege) error {
+	return a.authorize(user, requirement{db: db, tbl: tbl, privs: privs}, a.ReadOnly())
+}
+
+func (a *Auth) authorize(user string, req requirement, readOnly bool) error {
+	if readOnly && !req.access && req.privs&^SelectPriv != NoPrivileges {
+		return auth.ErrNotAuthorized.Wrap(fmt.Errorf("the server is read only"))
+	}
+
+	if !a.satisfied(user, req) {
+		return auth.ErrNotAuthorized.Wrap(req.deniedError(user))
+	}
+
+	return nil
+}
+
+// satisfied returns whether |user| holds the privileges of |req|. Privileges on a database also apply to all of its
+// revision databases.
+func (a *Auth) satisfied(user string, req requirement) bool {
+	db := sqle.BaseDbName(req.db)
+	if req.access {
+		return db == informationSchemaDB || a.store.HasAccessToDatabase(user, db)
+	}
+
+	if strings.EqualFold(db, informationSchemaDB) && req.privs == SelectPriv {
+		return true
+	}
+
+	held := a.store.Privileges(user, db, req.tbl)
+	if req.anyOf {
+		return held&req.privs != NoPrivileges
+	}
+	return held.Has(req.privs)
+}
+
+// requirement is a set of privileges needed on a table, or on a whole database when tbl is the Wildcard.
+type requirement struct {
+	db    string
+	tbl   string
+	privs Privilege
+	// anyOf is true when holding any one of privs is enough
+	anyOf bool
+	// access is true when the requirement is only that the user holds some privilege on db
+	access bool
+}
+
+func (req requirement) deniedError(user string) error {
+	if req.access {
+		return fmt.Errorf("%w for user %s to database '%s'", ErrAccessDenied, QuoteUser(user), req.db)
+	}
+
+	privs := req.privs.String()
+	if req.anyOf {
+		privs = strings.Join(req.privs.Names(), " or ")
+	}
+	return fmt.Errorf("%w; user %s needs %s on %s.%s", ErrAccessDenied, QuoteUser(user), privs, quoteScope(req.db), quoteScope(req.tbl))
+}
+
+// RequiresDatabaseAccess returns the error to give when |user| selects the database |db| without holding any
+// privileges on it, or nil if the user may use it.
+func (a *Auth) RequiresDatabaseAccess(user, db string) error {
+	req := requirement{db: db, tbl: Wildcard, access: true}
+	if !a.satisfied(user, req) {
+		return req.deniedError(user)
+	}
+	return nil
+}
+
+// requiredPrivileges returns the privileges needed to run |query| when the current database is |currentDB|.
+func requiredPrivileges(query, currentDB string) ([]requirement, error) {
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &requirementCollector{currentDB: currentDB}
+	c.statement(stmt)
+	return c.reqs, nil
+}
+
+type requirementCollector struct {
+	currentDB string
+	reqs      []requirement
+}
+
+func (c *requirementCollector) add(name sqlparser.TableName, privs Privilege) {
+	c.reqs = append(c.reqs, requirement{db: c.dbOf(name), tbl: name.Name.String(), privs: privs})
+}
+
+func (c *requirementCollector) addAnyOf(name sqlparser.TableName, privs Privilege) {
+	c.reqs = append(c.reqs, requirement{db: c.dbOf(name), tbl: name.Name.String(), privs: privs, anyOf: true})
+}
+
+func (c *requirementCollector) addDatabase(db string, privs Privilege) {
+	c.reqs = append(c.reqs, requirement{db: db, tbl: Wildcard, privs: privs})
+}
+
+func (c *requirementCollector) dbOf(name sqlparser.TableName) string {
+	if !name.Qualifier.IsEmpty() {
+		return name.Qualifier.String()
+	}
+	return c.currentDB
+}
+
+func (c *requirementCollector) statement(stmt sqlparser.Statement) {
+	switch n := stmt.(type) {
+	case sqlparser.SelectStatement:
+		c.reads(n)
+	case *sqlparser.Insert:
+		privs := InsertPriv
+		if n.Action == sqlparser.ReplaceStr {
+			privs |= DeletePriv
+		}
+		if len(n.OnDup) > 0 {
+			privs |= UpdatePriv
+		}
+		c.add(n.Table, privs)
+		c.reads(n.Rows)
+	case *sqlparser.Update:
+		for _, name := range tableNames(n.TableExprs) {
+			c.add(name, UpdatePriv)
+		}
+		c.reads(n.Exprs, n.Where)
+	case *sqlparser.Delete:
+		targets := n.Targets
+		if len(targets) == 0 {
+			targets = tableNames(n.TableExprs)
+		}
+		for _, name := range targets {
+			c.add(name, DeletePriv)
+		}
+		c.reads(n.TableExprs, n.Where)
+	case *sqlparser.DDL:
+		c.ddl(n)
+	case *sqlparser.DBDDL:
+		switch n.Action {
+		case sqlparser.CreateStr:
+			c.addDatabase(n.DBName, CreatePriv)
+		case sqlparser.DropStr:
+			c.addDatabase(n.DBName, DropPriv)
+		default:
+			c.addDatabase(n.DBName, AlterPriv)
+		}
+	case *sqlparser.Show:
+		if !n.Table.IsEmpty() {
+			c.add(n.Table, SelectPriv)
+		}
+	case *sqlparser.Use:
+		if !n.DBName.IsEmpty() {
+			c.reqs = append(c.reqs, requirement{db: n.DBName.String(), tbl: Wildcard, access: true})
+		}
+	case *sqlparser.Explain:
+		c.statement(n.Statement)
+	case *sqlparser.Set:
+		c.reads(n.Exprs)
+	}
+}
+
+func (c *requirementCollector) ddl(n *sqlparser.DDL) {
+	switch n.Action {
+	case sqlparser.CreateStr:
+		if !n.View.IsEmpty() {
+			c.add(n.View, CreateViewPriv)
+			c.reads(n.ViewExpr)
+		} else if n.TriggerSpec != nil {
+			c.add(n.Table, TriggerPriv)
+		} else {
+			c.add(n.Table, CreatePriv)
+			if n.OptLike != nil {
+				c.add(n.OptLike.LikeTable, SelectPriv)
+			}
+		}
+	case sqlparser.AlterStr:
+		if n.IndexSpec != nil {
+			c.addAnyOf(n.Table, AlterPriv|IndexPriv)
+		} else {
+			c.add(n.Table, AlterPriv)
+		}
+	case sqlparser.DropStr:
+		for _, name := range n.FromTables {
+			c.add(name, DropPriv)
+		}
+		for _, name := range n.FromViews {
+			c.add(name, DropPriv)
+		}
+		if n.TriggerSpec != nil {
+			if n.Table.IsEmpty() {
+				c.addDatabase(c.currentDB, TriggerPriv)
+			} else {
+				c.add(n.Table, TriggerPriv)
+			}
+		}
+	case sqlparser.TruncateStr:
+		c.add(n.Table, DropPriv)
+	case sqlparser.RenameStr:
+		for _, name := range n.FromTables {
+			c.add(name, AlterPriv|DropPriv)
+		}
+		for _, name := range n.ToTables {
+			c.add(name, CreatePriv|InsertPriv)
+		}
+	default:
+		c.addDatabase(Wildcard, AllPrivileges)
+	}
+}
+
+// reads adds a SELECT requirement for every table read by |nodes|, and a write requirement on the current database
+// for any function which changes its state.
+func (c *requirementCollector) reads(nodes ...sqlparser.SQLNode) {
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *sqlparser.AliasedTableExpr:
+			// selects without a FROM clause read from dual
+			if name, ok := n.Expr.(sqlparser.TableName); ok && !(name.Qualifier.IsEmpty() && name.Name.String() == "dual") {
+				c.add(name, SelectPriv)
+			}
+		case *sqlparser.FuncExpr:
+			if mutatingFunctions[n.Name.Lowered()] {
+				c.addDatabase(c.currentDB, WritePrivileges)
+			}
+		}
+		return true, nil
+	}, nodes...)
+}
+
+// tableNames returns the names of the tables referenced directly by |exprs|, excluding any in subqueries
+func tableNames(exprs sqlparser.TableExprs) []sqlparser.TableName {
+	var names []sqlparser.TableName
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *sqlparser.AliasedTableExpr:
+			if name, ok := n.Expr.(sqlparser.TableName); ok {
+				names = append(names, name)
+			}
+			return false, nil
+		case *sqlparser.Subquery:
+			return false, nil
+		}
+		return true, nil
+	}, exprs)
+	return names
+}
+
+// authServer is a mysql.AuthServer which validates passwords against the users of a Store
+type authServer struct {
+	store *Store
+}
+
+var _ mysql.AuthServer = (*authServer)(nil)
+
+// AuthMethod implements mysql.AuthServer
+func (as *authServer) AuthMethod(user string) (string, error) {
+	return mysql.MysqlNativePassword, nil
+}
+
+// Salt implements mysql.AuthServer
+func (as *authServer) Salt() ([]byte, error) {
+	return mysql.NewSalt()
+}
+
+// ValidateHash implements mysql.AuthServer
+func (as *authServer) ValidateHash(salt []byte, user string, authResponse []byte, remoteAddr net.Addr) (mysql.Getter, error) {
+	static := mysql.NewAuthServerStatic()
+	if hash, ok := as.store.PasswordHash(user); ok {
+		static.Entries[user] = []*mysql.AuthServerStaticEntry{{MysqlNativePassword: hash, Password: hash}}
+	}
+	return static.ValidateHash(salt, user, authResponse, remoteAddr)
+}
+
+// Negotiate implements mysql.AuthServer. It is never called, as every user authenticates with mysql_native_password.
+func (as *authServer) Negotiate(c *mysql.Conn, user string, remoteAddr net.Addr) (mysql.Getter, error) {
+	return nil, mysql.NewSQLError(mysql.ERAccessDeniedError, mysql.SSAccessDeniedError, "Access denied for user '%v'", user)
+}
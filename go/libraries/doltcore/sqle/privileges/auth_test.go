@@ -0,0 +1,147 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privileges
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+func TestRequiredPrivileges(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []requirement
+	}{
+		{
+			"SELECT a.x FROM t1 a JOIN other.t2 ON a.x = t2.x WHERE a.y IN (SELECT y FROM t3)",
+			[]requirement{{db: "mydb", tbl: "t1", privs: SelectPriv}, {db: "other", tbl: "t2", privs: SelectPriv}, {db: "mydb", tbl: "t3", privs: SelectPriv}},
+		},
+		{
+			"INSERT INTO t1 SELECT * FROM t2",
+			[]requirement{{db: "mydb", tbl: "t1", privs: InsertPriv}, {db: "mydb", tbl: "t2", privs: SelectPriv}},
+		},
+		{
+			"REPLACE INTO t1 VALUES (1)",
+			[]requirement{{db: "mydb", tbl: "t1", privs: InsertPriv | DeletePriv}},
+		},
+		{
+			"UPDATE t1 SET x = 1 WHERE y = 2",
+			[]requirement{{db: "mydb", tbl: "t1", privs: UpdatePriv}},
+		},
+		{
+			"DELETE FROM t1 WHERE x IN (SELECT x FROM t2)",
+			[]requirement{{db: "mydb", tbl: "t1", privs: DeletePriv}, {db: "mydb", tbl: "t1", privs: SelectPriv}, {db: "mydb", tbl: "t2", privs: SelectPriv}},
+		},
+		{
+			"CREATE TABLE t4 (pk int primary key)",
+			[]requirement{{db: "mydb", tbl: "t4", privs: CreatePriv}},
+		},
+		{
+			"CREATE INDEX idx ON t1 (x)",
+			[]requirement{{db: "mydb", tbl: "t1", privs: AlterPriv | IndexPriv, anyOf: true}},
+		},
+		{
+			"DROP TABLE t1, t2",
+			[]requirement{{db: "mydb", tbl: "t1", privs: DropPriv}, {db: "mydb", tbl: "t2", privs: DropPriv}},
+		},
+		{
+			"CREATE VIEW v1 AS SELECT * FROM t1",
+			[]requirement{{db: "mydb", tbl: "v1", privs: CreateViewPriv}, {db: "mydb", tbl: "t1", privs: SelectPriv}},
+		},
+		{
+			"SELECT DOLT_COMMIT('-m', 'msg')",
+			[]requirement{{db: "mydb", tbl: Wildcard, privs: WritePrivileges}},
+		},
+		{
+			"USE other",
+			[]requirement{{db: "other", tbl: Wildcard, access: true}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			reqs, err := requiredPrivileges(test.query, "mydb")
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, reqs)
+		})
+	}
+}
+
+func TestAuthAllowed(t *testing.T) {
+	s := newTestStore(t, filesys.EmptyInMemFS("/"))
+	require.NoError(t, s.CreateUser("bob", "", false))
+	require.NoError(t, s.Grant("bob", Grant{Database: "mydb", Table: "t1", Privileges: SelectPriv}))
+
+	allowed := func(a *Auth, user, query string) bool {
+		ctx := sql.NewContext(context.Background(),
+			sql.WithSession(sql.NewSession("localhost", "localhost", user, 1)),
+			sql.WithQuery(query))
+		ctx.SetCurrentDatabase("mydb")
+		return a.Allowed(ctx, auth.ReadPerm) == nil
+	}
+
+	a := NewAuth(s, false)
+	assert.True(t, allowed(a, "bob", "SELECT * FROM t1"))
+	assert.True(t, allowed(a, "bob", "SELECT * FROM information_schema.tables"))
+	assert.False(t, allowed(a, "bob", "SELECT * FROM t2"))
+	assert.False(t, allowed(a, "bob", "INSERT INTO t1 VALUES (1)"))
+	assert.False(t, allowed(a, "bob", "USE other"))
+	assert.True(t, allowed(a, "bob", "SELECT * FROM `mydb/feature`.t1"))
+	assert.False(t, allowed(a, "bob", "SELECT * FROM `other/feature`.t1"))
+	assert.True(t, allowed(a, "root", "INSERT INTO t1 VALUES (1)"))
+
+	// statements vitess can't parse are denied when the tables they read are unknown
+	noDB := func(user, query string) bool {
+		ctx := sql.NewContext(context.Background(),
+			sql.WithSession(sql.NewSession("localhost", "localhost", user, 1)),
+			sql.WithQuery(query))
+		return a.Allowed(ctx, auth.ReadPerm) == nil
+	}
+	assert.True(t, allowed(a, "bob", "SELECT * FROM t1 WHERE"))
+	assert.False(t, noDB("bob", "SELECT * FROM t1 WHERE"))
+	assert.True(t, noDB("root", "SELECT * FROM t1 WHERE"))
+	assert.True(t, noDB("bob", "SELECT 1"))
+
+	readOnly := NewAuth(s, true)
+	assert.True(t, allowed(readOnly, "root", "SELECT * FROM t1"))
+	assert.False(t, allowed(readOnly, "root", "INSERT INTO t1 VALUES (1)"))
+	readOnly.SetReadOnly(false)
+	assert.False(t, readOnly.ReadOnly())
+	assert.True(t, allowed(readOnly, "root", "INSERT INTO t1 VALUES (1)"))
+}
+
+func TestAuthRequiresTablePrivileges(t *testing.T) {
+	s := newTestStore(t, filesys.EmptyInMemFS("/"))
+	require.NoError(t, s.CreateUser("bob", "", false))
+	require.NoError(t, s.Grant("bob", Grant{Database: "mydb", Table: "t1", Privileges: AlterPriv}))
+	require.NoError(t, s.Grant("bob", Grant{Database: "mydb", Table: "t2", Privileges: SelectPriv | DropPriv}))
+
+	a := NewAuth(s, false)
+	assert.NoError(t, a.RequiresTablePrivileges("bob", "mydb", "t1", AlterPriv))
+	assert.NoError(t, a.RequiresTablePrivileges("bob", "mydb/feature", "t1", AlterPriv))
+	assert.Error(t, a.RequiresTablePrivileges("bob", "mydb", "t2", AlterPriv))
+	assert.Error(t, a.RequiresTablePrivileges("bob", "other", "t1", AlterPriv))
+	assert.NoError(t, a.RequiresTablePrivileges("root", "mydb", "t2", AlterPriv))
+
+	a.SetReadOnly(true)
+	assert.Error(t, a.RequiresTablePrivileges("root", "mydb", "t2", AlterPriv))
+}
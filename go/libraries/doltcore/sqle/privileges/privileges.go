@@ -0,0 +1,107 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privileges
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Privilege is a set of privileges which may be granted to a user.
+type Privilege uint32
+
+const (
+	SelectPriv Privilege = 1 << iota
+	InsertPriv
+	UpdatePriv
+	DeletePriv
+	CreatePriv
+	DropPriv
+	AlterPriv
+	IndexPriv
+	TriggerPriv
+	CreateViewPriv
+	CreateUserPriv
+
+	// NoPrivileges is the empty set of privileges
+	NoPrivileges Privilege = 0
+	// AllPrivileges is the set of every privilege. As in MySQL, it does not include the ability to grant privileges to
+	// other users, which is given by WITH GRANT OPTION.
+	AllPrivileges = SelectPriv | InsertPriv | UpdatePriv | DeletePriv | CreatePriv | DropPriv | AlterPriv | IndexPriv |
+		TriggerPriv | CreateViewPriv | CreateUserPriv
+	// WritePrivileges are the privileges needed to modify the data in a database
+	WritePrivileges = InsertPriv | UpdatePriv | DeletePriv
+)
+
+// privilegeNames are the names of each privilege in the order they are displayed
+var privilegeNames = []struct {
+	priv Privilege
+	name string
+}{
+	{SelectPriv, "SELECT"},
+	{InsertPriv, "INSERT"},
+	{UpdatePriv, "UPDATE"},
+	{DeletePriv, "DELETE"},
+	{CreatePriv, "CREATE"},
+	{DropPriv, "DROP"},
+	{AlterPriv, "ALTER"},
+	{IndexPriv, "INDEX"},
+	{TriggerPriv, "TRIGGER"},
+	{CreateViewPriv, "CREATE VIEW"},
+	{CreateUserPriv, "CREATE USER"},
+}
+
+// ParsePrivilege returns the privilege with the name given, ignoring case. "ALL" and "ALL PRIVILEGES" return
+// AllPrivileges.
+func ParsePrivilege(name string) (Privilege, error) {
+	name = strings.Join(strings.Fields(strings.ToUpper(name)), " ")
+	if name == "ALL" || name == "ALL PRIVILEGES" {
+		return AllPrivileges, nil
+	}
+
+	for _, pn := range privilegeNames {
+		if pn.name == name {
+			return pn.priv, nil
+		}
+	}
+
+	return NoPrivileges, fmt.Errorf("unknown privilege '%s'", name)
+}
+
+// Has returns whether every privilege in |other| is in this set.
+func (p Privilege) Has(other Privilege) bool {
+	return p&other == other
+}
+
+// Names returns the names of the privileges in this set.
+func (p Privilege) Names() []string {
+	var names []string
+	for _, pn := range privilegeNames {
+		if p.Has(pn.priv) {
+			names = append(names, pn.name)
+		}
+	}
+	return names
+}
+
+// String returns the privileges in this set as they would appear in a GRANT statement.
+func (p Privilege) String() string {
+	if p == AllPrivileges {
+		return "ALL PRIVILEGES"
+	} else if p == NoPrivileges {
+		return "USAGE"
+	}
+	return strings.Join(p.Names(), ", ")
+}
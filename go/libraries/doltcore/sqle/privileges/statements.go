@@ -0,0 +1,576 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privileges
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+)
+
+// ErrAccessDenied is returned when a user attempts an operation it does not have the privileges for
+var ErrAccessDenied = errors.New("access denied")
+
+// AccountStatementKind is the kind of an account management statement
+type AccountStatementKind int
+
+const (
+	CreateUserStmt AccountStatementKind = iota
+	DropUserStmt
+	AlterUserStmt
+	GrantStmt
+	RevokeStmt
+	ShowGrantsStmt
+)
+
+// UserSpec is a user named in an account management statement, along with its password if one was given
+type UserSpec struct {
+	Name        string
+	Password    string
+	HasPassword bool
+}
+
+// AccountStatement is a parsed CREATE USER, DROP USER, ALTER USER, GRANT, REVOKE or SHOW GRANTS statement. Hosts in
+// account names are accepted but ignored, as every account may connect from any host.
+type AccountStatement struct {
+	Kind AccountStatementKind
+	// Users are the users the statement applies to. For SHOW GRANTS with no FOR clause it is empty.
+	Users       []UserSpec
+	IfExists    bool
+	IfNotExists bool
+	// Grant is the scope and privileges of a GRANT or REVOKE statement. An empty Database refers to the current
+	// database.
+	Grant Grant
+}
+
+// String returns a description of the statement which does not include any passwords, suitable for logging
+func (stmt *AccountStatement) String() string {
+	var users []string
+	for _, u := range stmt.Users {
+		users = append(users, QuoteUser(u.Name))
+	}
+
+	switch stmt.Kind {
+	case CreateUserStmt:
+		return "CREATE USER " + strings.Join(users, ", ")
+	case DropUserStmt:
+		return "DROP USER " + strings.Join(users, ", ")
+	case AlterUserStmt:
+		return "ALTER USER " + strings.Join(users, ", ")
+	case GrantStmt:
+		return fmt.Sprintf("GRANT %s ON %s.%s TO %s", stmt.grantedPrivileges(), quoteScope(stmt.Grant.Database), quoteScope(stmt.Grant.Table), strings.Join(users, ", "))
+	case RevokeStmt:
+		return fmt.Sprintf("REVOKE %s ON %s.%s FROM %s", stmt.grantedPrivileges(), quoteScope(stmt.Grant.Database), quoteScope(stmt.Grant.Table), strings.Join(users, ", "))
+	default:
+		if len(users) == 0 {
+			return "SHOW GRANTS"
+		}
+		return "SHOW GRANTS FOR " + users[0]
+	}
+}
+
+func (stmt *AccountStatement) grantedPrivileges() string {
+	if stmt.Grant.Privileges == NoPrivileges && stmt.Grant.GrantOption {
+		return "GRANT OPTION"
+	}
+	return stmt.Grant.Privileges.String()
+}
+
+// ModifiesAccounts returns whether executing the statement changes any user or privilege
+func (stmt *AccountStatement) ModifiesAccounts() bool {
+	return stmt.Kind != ShowGrantsStmt
+}
+
+// ShowGrantsUser returns the user whose grants are shown by a SHOW GRANTS statement run by |currentUser|
+func (stmt *AccountStatement) ShowGrantsUser(currentUser string) string {
+	if len(stmt.Users) == 0 {
+		return currentUser
+	}
+	return stmt.Users[0].Name
+}
+
+// ParseAccountStatement parses |query| as an account management statement. If the query is some other kind of
+// statement, nil is returned with no error.
+func ParseAccountStatement(query string) (*AccountStatement, error) {
+	p := &accountParser{toks: lexAccountQuery(query)}
+	if len(p.toks) < 2 {
+		return nil, nil
+	}
+
+	var stmt *AccountStatement
+	var err error
+	switch {
+	case p.toks[0].isWord("create") && p.toks[1].isWord("user"):
+		p.pos = 2
+		stmt, err = p.parseCreateUser()
+	case p.toks[0].isWord("drop") && p.toks[1].isWord("user"):
+		p.pos = 2
+		stmt, err = p.parseDropUser()
+	case p.toks[0].isWord("alter") && p.toks[1].isWord("user"):
+		p.pos = 2
+		stmt, err = p.parseAlterUser()
+	case p.toks[0].isWord("grant"):
+		p.pos = 1
+		stmt, err = p.parseGrant(GrantStmt, "to")
+	case p.toks[0].isWord("revoke"):
+		p.pos = 1
+		stmt, err = p.parseGrant(RevokeStmt, "from")
+	case p.toks[0].isWord("show") && p.toks[1].isWord("grants"):
+		p.pos = 2
+		stmt, err = p.parseShowGrants()
+	default:
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.done() {
+		return nil, p.errorf("unexpected '%s'", p.peek().text)
+	}
+
+	return stmt, nil
+}
+
+type accountParser struct {
+	toks []accountToken
+	pos  int
+}
+
+func (p *accountParser) done() bool {
+	return p.pos >= len(p.toks)
+}
+
+func (p *accountParser) peek() accountToken {
+	if p.done() {
+		return accountToken{}
+	}
+	return p.toks[p.pos]
+}
+
+func (p *accountParser) acceptWords(words ...string) bool {
+	if p.pos+len(words) > len(p.toks) {
+		return false
+	}
+	for i, w := range words {
+		if !p.toks[p.pos+i].isWord(w) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+func (p *accountParser) acceptPunct(punct string) bool {
+	if p.peek().isPunct(punct) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *accountParser) errorf(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if p.done() {
+		msg = "unexpected end of statement"
+	}
+	return fmt.Errorf("syntax error in account statement: %s", msg)
+}
+
+func (p *accountParser) parseCreateUser() (*AccountStatement, error) {
+	stmt := &AccountStatement{Kind: CreateUserStmt}
+	stmt.IfNotExists = p.acceptWords("if", "not", "exists")
+
+	users, err := p.parseUserList(true)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt.Users = users
+	return stmt, nil
+}
+
+func (p *accountParser) parseDropUser() (*AccountStatement, error) {
+	stmt := &AccountStatement{Kind: DropUserStmt}
+	stmt.IfExists = p.acceptWords("if", "exists")
+
+	users, err := p.parseUserList(false)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt.Users = users
+	return stmt, nil
+}
+
+func (p *accountParser) parseAlterUser() (*AccountStatement, error) {
+	stmt := &AccountStatement{Kind: AlterUserStmt}
+	stmt.IfExists = p.acceptWords("if", "exists")
+
+	users, err := p.parseUserList(true)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		if !u.HasPassword {
+			return nil, fmt.Errorf("ALTER USER only supports changing passwords with IDENTIFIED BY")
+		}
+	}
+
+	stmt.Users = users
+	return stmt, nil
+}
+
+func (p *accountParser) parseGrant(kind AccountStatementKind, usersKeyword string) (*AccountStatement, error) {
+	stmt := &AccountStatement{Kind: kind}
+
+	for {
+		var words []string
+		for !p.done() && p.peek().kind == accountWordTok && !p.peek().isWord("on") {
+			words = append(words, p.peek().text)
+			p.pos++
+		}
+
+		if len(words) == 0 {
+			return nil, p.errorf("expected a privilege but found '%s'", p.peek().text)
+		}
+
+		name := strings.ToUpper(strings.Join(words, " "))
+		if name == "GRANT OPTION" {
+			stmt.Grant.GrantOption = true
+		} else {
+			priv, err := ParsePrivilege(name)
+			if err != nil {
+				return nil, err
+			}
+			stmt.Grant.Privileges |= priv
+		}
+
+		if !p.acceptPunct(",") {
+			break
+		}
+	}
+
+	if !p.acceptWords("on") {
+		return nil, p.errorf("expected ON but found '%s'", p.peek().text)
+	}
+	p.acceptWords("table")
+
+	db, tbl, err := p.parseScope()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Grant.Database, stmt.Grant.Table = db, tbl
+
+	if !p.acceptWords(usersKeyword) {
+		return nil, p.errorf("expected %s but found '%s'", strings.ToUpper(usersKeyword), p.peek().text)
+	}
+
+	stmt.Users, err = p.parseUserList(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == GrantStmt && p.acceptWords("with", "grant", "option") {
+		stmt.Grant.GrantOption = true
+	}
+
+	return stmt, nil
+}
+
+func (p *accountParser) parseShowGrants() (*AccountStatement, error) {
+	stmt := &AccountStatement{Kind: ShowGrantsStmt}
+	if !p.acceptWords("for") {
+		return stmt, nil
+	}
+
+	if p.acceptWords("current_user") {
+		if p.acceptPunct("(") && !p.acceptPunct(")") {
+			return nil, p.errorf("expected ')' but found '%s'", p.peek().text)
+		}
+		return stmt, nil
+	}
+
+	u, err := p.parseUser(false)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt.Users = []UserSpec{u}
+	return stmt, nil
+}
+
+// parseScope parses the database and table of a GRANT or REVOKE. A database which is not given is returned as empty.
+func (p *accountParser) parseScope() (db, tbl string, err error) {
+	first, ok := p.parseScopeName()
+	if !ok {
+		return "", "", p.errorf("expected a database or table name but found '%s'", p.peek().text)
+	}
+
+	if !p.acceptPunct(".") {
+		return "", first, nil
+	}
+
+	second, ok := p.parseScopeName()
+	if !ok {
+		return "", "", p.errorf("expected a table name but found '%s'", p.peek().text)
+	}
+
+	if first == Wildcard && second != Wildcard {
+		return "", "", fmt.Errorf("invalid grant scope *.%s", second)
+	}
+
+	return first, second, nil
+}
+
+func (p *accountParser) parseScopeName() (string, bool) {
+	tok := p.peek()
+	if tok.isPunct("*") {
+		p.pos++
+		return Wildcard, true
+	} else if tok.kind == accountWordTok || tok.kind == accountQuotedIdentTok {
+		p.pos++
+		return tok.value(), true
+	}
+	return "", false
+}
+
+func (p *accountParser) parseUserList(allowPassword bool) ([]UserSpec, error) {
+	var users []UserSpec
+	for {
+		u, err := p.parseUser(allowPassword)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, u)
+		if !p.acceptPunct(",") {
+			return users, nil
+		}
+	}
+}
+
+func (p *accountParser) parseUser(allowPassword bool) (UserSpec, error) {
+	tok := p.peek()
+	if tok.kind == accountPunctTok || p.done() {
+		return UserSpec{}, p.errorf("expected a user name but found '%s'", tok.text)
+	}
+	p.pos++
+
+	u := UserSpec{Name: tok.value()}
+	if p.acceptPunct("@") {
+		host := p.peek()
+		if p.done() || (host.kind == accountPunctTok && !host.isPunct("%")) {
+			return UserSpec{}, p.errorf("expected a host name but found '%s'", host.text)
+		}
+		p.pos++
+	}
+
+	if allowPassword && p.acceptWords("identified", "by") {
+		pw := p.peek()
+		if pw.kind != accountStringTok {
+			return UserSpec{}, p.errorf("expected a password string but found '%s'", pw.text)
+		}
+		p.pos++
+		u.Password = pw.value()
+		u.HasPassword = true
+	}
+
+	return u, nil
+}
+
+// Execute runs |stmt| against the store on behalf of |currentUser|, whose current database is |currentDB|. For SHOW
+// GRANTS the grant statements are returned.
+func (s *Store) Execute(currentUser, currentDB string, stmt *AccountStatement) ([]string, error) {
+	switch stmt.Kind {
+	case CreateUserStmt:
+		if !s.Privileges(currentUser, Wildcard, Wildcard).Has(CreateUserPriv) {
+			return nil, accessDenied(currentUser, "CREATE USER")
+		}
+		for _, u := range stmt.Users {
+			if err := s.CreateUser(u.Name, u.Password, stmt.IfNotExists); err != nil {
+				return nil, err
+			}
+		}
+
+	case DropUserStmt:
+		if !s.Privileges(currentUser, Wildcard, Wildcard).Has(CreateUserPriv) {
+			return nil, accessDenied(currentUser, "CREATE USER")
+		}
+		for _, u := range stmt.Users {
+			if err := s.DropUser(u.Name, stmt.IfExists); err != nil {
+				return nil, err
+			}
+		}
+
+	case AlterUserStmt:
+		for _, u := range stmt.Users {
+			if u.Name != currentUser && !s.Privileges(currentUser, Wildcard, Wildcard).Has(CreateUserPriv) {
+				return nil, accessDenied(currentUser, "CREATE USER")
+			}
+		}
+		for _, u := range stmt.Users {
+			if _, ok := s.PasswordHash(u.Name); !ok && stmt.IfExists {
+				continue
+			}
+			if err := s.SetPassword(u.Name, u.Password); err != nil {
+				return nil, err
+			}
+		}
+
+	case GrantStmt, RevokeStmt:
+		g := stmt.Grant
+		if g.Database == "" {
+			if currentDB == "" {
+				return nil, fmt.Errorf("no database selected")
+			}
+			g.Database = currentDB
+		}
+
+		if !s.CanGrant(currentUser, g.Database, g.Table) || !s.Privileges(currentUser, g.Database, g.Table).Has(g.Privileges) {
+			return nil, accessDenied(currentUser, "GRANT OPTION")
+		}
+
+		for _, u := range stmt.Users {
+			var err error
+			if stmt.Kind == GrantStmt {
+				err = s.Grant(u.Name, g)
+			} else {
+				err = s.Revoke(u.Name, g)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+	case ShowGrantsStmt:
+		name := stmt.ShowGrantsUser(currentUser)
+		if name != currentUser && !s.Privileges(currentUser, Wildcard, Wildcard).Has(CreateUserPriv) {
+			return nil, accessDenied(currentUser, "CREATE USER")
+		}
+		return s.GrantStatements(name)
+	}
+
+	return nil, nil
+}
+
+func accessDenied(user, priv string) error {
+	return fmt.Errorf("%w; user %s needs the %s privilege for this operation", ErrAccessDenied, QuoteUser(user), priv)
+}
+
+type accountTokenKind int
+
+const (
+	accountWordTok accountTokenKind = iota
+	accountQuotedIdentTok
+	accountStringTok
+	accountPunctTok
+)
+
+type accountToken struct {
+	kind accountTokenKind
+	// text is the token as it appears in the query
+	text string
+	// val is the value of a string or quoted identifier, with its quotes and escapes removed
+	val string
+}
+
+func (t accountToken) isWord(w string) bool {
+	return t.kind == accountWordTok && strings.EqualFold(t.text, w)
+}
+
+func (t accountToken) isPunct(p string) bool {
+	return t.kind == accountPunctTok && t.text == p
+}
+
+// value returns the text of the token with any quotes removed
+func (t accountToken) value() string {
+	if t.kind == accountQuotedIdentTok || t.kind == accountStringTok {
+		return t.val
+	}
+	return t.text
+}
+
+// lexAccountQuery splits |query| into tokens with the tokenizer of the SQL parser, skipping comments and a trailing
+// semicolon. The tokenizer reads '@' as part of an identifier, so identifiers are split around it to separate the
+// user and host of an account name. Tokenizing stops at the first character the tokenizer can't read, which is
+// returned as a punctuation token.
+func lexAccountQuery(query string) []accountToken {
+	tkn := sqlparser.NewStringTokenizer(query)
+
+	var toks []accountToken
+	prevEnd := 0
+	for {
+		typ, val := tkn.Scan()
+		if typ == 0 {
+			break
+		}
+
+		// the tokenizer has read one character past the end of the token
+		end := tkn.Position - 1
+		if end > len(query) {
+			end = len(query)
+		}
+
+		start := prevEnd
+		for start < end && strings.IndexByte(" \t\n\r", query[start]) >= 0 {
+			start++
+		}
+		prevEnd = end
+		text := query[start:end]
+
+		switch {
+		case typ == sqlparser.COMMENT:
+			continue
+		case typ == sqlparser.LEX_ERROR:
+			return append(toks, accountToken{kind: accountPunctTok, text: text})
+		case typ == sqlparser.STRING:
+			toks = append(toks, accountToken{kind: accountStringTok, text: text, val: string(val)})
+		case typ == sqlparser.ID && strings.HasPrefix(text, "`"):
+			toks = append(toks, accountToken{kind: accountQuotedIdentTok, text: text, val: string(val)})
+		case len(val) == 0:
+			toks = append(toks, accountToken{kind: accountPunctTok, text: text})
+		default:
+			toks = appendAccountWords(toks, text)
+		}
+	}
+
+	for len(toks) > 0 && toks[len(toks)-1].isPunct(";") {
+		toks = toks[:len(toks)-1]
+	}
+
+	return toks
+}
+
+// appendAccountWords appends the word |text| to |toks|, split around the first '@' it contains
+func appendAccountWords(toks []accountToken, text string) []accountToken {
+	at := strings.IndexByte(text, '@')
+	if at < 0 {
+		return append(toks, accountToken{kind: accountWordTok, text: text})
+	}
+
+	if at > 0 {
+		toks = append(toks, accountToken{kind: accountWordTok, text: text[:at]})
+	}
+	toks = append(toks, accountToken{kind: accountPunctTok, text: "@"})
+	if at+1 < len(text) {
+		toks = append(toks, accountToken{kind: accountWordTok, text: text[at+1:]})
+	}
+	return toks
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privileges
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+func TestParseAccountStatement(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected *AccountStatement
+	}{
+		{
+			"CREATE USER 'bob'@'localhost' IDENTIFIED BY 'p''w';",
+			&AccountStatement{Kind: CreateUserStmt, Users: []UserSpec{{Name: "bob", Password: "p'w", HasPassword: true}}},
+		},
+		{
+			"create user if not exists alice, `bob`@`%`",
+			&AccountStatement{Kind: CreateUserStmt, IfNotExists: true, Users: []UserSpec{{Name: "alice"}, {Name: "bob"}}},
+		},
+		{
+			"DROP USER IF EXISTS 'bob'",
+			&AccountStatement{Kind: DropUserStmt, IfExists: true, Users: []UserSpec{{Name: "bob"}}},
+		},
+		{
+			"ALTER USER bob IDENTIFIED BY \"new\"",
+			&AccountStatement{Kind: AlterUserStmt, Users: []UserSpec{{Name: "bob", Password: "new", HasPassword: true}}},
+		},
+		{
+			"GRANT SELECT, INSERT ON mydb.t1 TO 'bob'@'%' WITH GRANT OPTION",
+			&AccountStatement{Kind: GrantStmt, Users: []UserSpec{{Name: "bob"}},
+				Grant: Grant{Database: "mydb", Table: "t1", Privileges: SelectPriv | InsertPriv, GrantOption: true}},
+		},
+		{
+			"grant all privileges on *.* to bob",
+			&AccountStatement{Kind: GrantStmt, Users: []UserSpec{{Name: "bob"}},
+				Grant: Grant{Database: Wildcard, Table: Wildcard, Privileges: AllPrivileges}},
+		},
+		{
+			"GRANT CREATE VIEW ON `my db`.* TO bob",
+			&AccountStatement{Kind: GrantStmt, Users: []UserSpec{{Name: "bob"}},
+				Grant: Grant{Database: "my db", Table: Wildcard, Privileges: CreateViewPriv}},
+		},
+		{
+			"REVOKE UPDATE, GRANT OPTION ON t1 FROM bob, alice",
+			&AccountStatement{Kind: RevokeStmt, Users: []UserSpec{{Name: "bob"}, {Name: "alice"}},
+				Grant: Grant{Table: "t1", Privileges: UpdatePriv, GrantOption: true}},
+		},
+		{
+			"SHOW GRANTS",
+			&AccountStatement{Kind: ShowGrantsStmt},
+		},
+		{
+			"SHOW GRANTS FOR CURRENT_USER()",
+			&AccountStatement{Kind: ShowGrantsStmt},
+		},
+		{
+			"show grants for 'bob'@'%'",
+			&AccountStatement{Kind: ShowGrantsStmt, Users: []UserSpec{{Name: "bob"}}},
+		},
+		{
+			`CREATE USER bob@localhost IDENTIFIED BY 'a\nb\'c\\d'`,
+			&AccountStatement{Kind: CreateUserStmt, Users: []UserSpec{{Name: "bob", Password: "a\nb'c\\d", HasPassword: true}}},
+		},
+		{
+			"/* setup */ DROP USER `we``ird`@'%', bob@`%` -- cleanup",
+			&AccountStatement{Kind: DropUserStmt, Users: []UserSpec{{Name: "we`ird"}, {Name: "bob"}}},
+		},
+		{"SELECT * FROM grants", nil},
+		{"CREATE TABLE user (pk int primary key)", nil},
+		{"SHOW TABLES", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			stmt, err := ParseAccountStatement(test.query)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, stmt)
+		})
+	}
+
+	for _, query := range []string{
+		"CREATE USER",
+		"GRANT SELECT mydb.* TO bob",
+		"GRANT FLY ON *.* TO bob",
+		"GRANT SELECT ON *.t1 TO bob",
+		"ALTER USER bob",
+		"DROP USER bob IDENTIFIED BY 'pw'",
+	} {
+		_, err := ParseAccountStatement(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestExecuteAccountStatements(t *testing.T) {
+	s := newTestStore(t, filesys.EmptyInMemFS("/"))
+
+	exec := func(user, query string) ([]string, error) {
+		stmt, err := ParseAccountStatement(query)
+		require.NoError(t, err)
+		require.NotNil(t, stmt)
+		return s.Execute(user, "mydb", stmt)
+	}
+
+	_, err := exec("root", "CREATE USER bob IDENTIFIED BY 'pw'")
+	require.NoError(t, err)
+	_, err = exec("root", "CREATE USER alice")
+	require.NoError(t, err)
+
+	_, err = exec("bob", "CREATE USER eve")
+	assert.True(t, errors.Is(err, ErrAccessDenied))
+
+	_, err = exec("root", "GRANT SELECT, INSERT ON t1 TO bob WITH GRANT OPTION")
+	require.NoError(t, err)
+	assert.Equal(t, SelectPriv|InsertPriv, s.Privileges("bob", "mydb", "t1"))
+
+	// bob may pass on privileges he holds, but no others
+	_, err = exec("bob", "GRANT SELECT ON mydb.t1 TO alice")
+	require.NoError(t, err)
+	_, err = exec("bob", "GRANT DELETE ON mydb.t1 TO alice")
+	assert.True(t, errors.Is(err, ErrAccessDenied))
+	_, err = exec("alice", "GRANT SELECT ON mydb.t1 TO bob")
+	assert.True(t, errors.Is(err, ErrAccessDenied))
+
+	grants, err := exec("alice", "SHOW GRANTS")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GRANT USAGE ON *.* TO 'alice'@'%'", "GRANT SELECT ON `mydb`.`t1` TO 'alice'@'%'"}, grants)
+	_, err = exec("alice", "SHOW GRANTS FOR bob")
+	assert.True(t, errors.Is(err, ErrAccessDenied))
+
+	_, err = exec("alice", "ALTER USER alice IDENTIFIED BY 'mine'")
+	require.NoError(t, err)
+	_, err = exec("alice", "ALTER USER bob IDENTIFIED BY 'mine'")
+	assert.True(t, errors.Is(err, ErrAccessDenied))
+
+	_, err = exec("root", "REVOKE SELECT ON mydb.t1 FROM alice")
+	require.NoError(t, err)
+	assert.Equal(t, NoPrivileges, s.Privileges("alice", "mydb", "t1"))
+
+	_, err = exec("root", "DROP USER alice, bob")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"root"}, s.UserNames())
+}
@@ -0,0 +1,533 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privileges
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dolthub/go-mysql-server/auth"
+
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+// Wildcard matches every database or every table in a Grant
+const Wildcard = "*"
+
+// privilegeFilePerm is the file mode of the file a Store is persisted to
+const privilegeFilePerm os.FileMode = 0600
+
+var nativePasswordRegex = regexp.MustCompile(`^\*[0-9A-F]{40}$`)
+
+// Grant is a set of privileges on a database and table. Either may be the Wildcard.
+type Grant struct {
+	Database    string
+	Table       string
+	Privileges  Privilege
+	GrantOption bool
+}
+
+// Covers returns whether this grant applies to the table |tbl| in the database |db|. A |tbl| of Wildcard asks whether
+// the grant applies to the database as a whole.
+func (g Grant) Covers(db, tbl string) bool {
+	if g.Database != Wildcard && !strings.EqualFold(g.Database, db) {
+		return false
+	}
+	return g.Table == Wildcard || strings.EqualFold(g.Table, tbl)
+}
+
+func (g Grant) sameScope(other Grant) bool {
+	return strings.EqualFold(g.Database, other.Database) && strings.EqualFold(g.Table, other.Table)
+}
+
+// String returns the GRANT statement which creates this grant for |user|
+func (g Grant) String(user string) string {
+	str := fmt.Sprintf("GRANT %s ON %s.%s TO %s", g.Privileges.String(), quoteScope(g.Database), quoteScope(g.Table), QuoteUser(user))
+	if g.GrantOption {
+		str += " WITH GRANT OPTION"
+	}
+	return str
+}
+
+func quoteScope(name string) string {
+	if name == Wildcard {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// QuoteUser returns the account name of |user| as it appears in account management statements
+func QuoteUser(user string) string {
+	return "'" + strings.ReplaceAll(user, "'", "''") + "'@'%'"
+}
+
+type user struct {
+	name         string
+	passwordHash string
+	grants       []Grant
+}
+
+// userMap holds the users of a Store by name. A Store's userMap and the users in it are never modified once they are
+// in use; changes are made to a copy which replaces it.
+type userMap map[string]*user
+
+// clone replaces the user |name| with a copy which may be modified, and returns the copy
+func (um userMap) clone(name string) (*user, bool) {
+	u, ok := um[name]
+	if !ok {
+		return nil, false
+	}
+
+	cp := *u
+	cp.grants = append([]Grant(nil), u.grants...)
+	um[name] = &cp
+	return &cp, true
+}
+
+// Store holds the user accounts of a server and the privileges granted to them. The super user is given every
+// privilege on every database and is not persisted. When the store has a path, every change to it is written to that
+// file.
+type Store struct {
+	mu        sync.RWMutex
+	fs        filesys.ReadWriteFS
+	path      string
+	superUser user
+	users     userMap
+}
+
+// NewStore returns a Store with the super user |superUser|, loading other users from the file at |path| if it exists.
+// If |path| is empty the store is kept in memory only.
+func NewStore(fs filesys.ReadWriteFS, path, superUser, superPassword string) (*Store, error) {
+	s := &Store{
+		fs:        fs,
+		path:      path,
+		superUser: user{name: superUser, passwordHash: auth.NativePassword(superPassword)},
+		users:     make(userMap),
+	}
+
+	if path != "" {
+		if exists, _ := fs.Exists(path); exists {
+			if err := s.load(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// Persisted returns whether the store is persisted to a file that already exists.
+func (s *Store) Persisted() bool {
+	if s.path == "" {
+		return false
+	}
+	exists, _ := s.fs.Exists(s.path)
+	return exists
+}
+
+// IsSuperUser returns whether |name| is the super user of the store.
+func (s *Store) IsSuperUser(name string) bool {
+	return name == s.superUser.name
+}
+
+// UserNames returns the names of every user in the store, including the super user.
+func (s *Store) UserNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := []string{s.superUser.name}
+	for name := range s.users {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// PasswordHash returns the mysql_native_password hash of the password of the user |name|.
+func (s *Store) PasswordHash(name string) (string, bool) {
+	if s.IsSuperUser(name) {
+		return s.superUser.passwordHash, true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[name]
+	if !ok {
+		return "", false
+	}
+	return u.passwordHash, true
+}
+
+// CreateUser adds the user |name| with no privileges.
+func (s *Store) CreateUser(name, password string, ifNotExists bool) error {
+	if name == "" {
+		return fmt.Errorf("user name cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[name]; ok || s.IsSuperUser(name) {
+		if ifNotExists {
+			return nil
+		}
+		return fmt.Errorf("user %s already exists", QuoteUser(name))
+	}
+
+	users := s.copyUsers()
+	users[name] = &user{name: name, passwordHash: auth.NativePassword(password)}
+	return s.save(users)
+}
+
+// DropUser removes the user |name| and all of its privileges.
+func (s *Store) DropUser(name string, ifExists bool) error {
+	if s.IsSuperUser(name) {
+		return fmt.Errorf("the super user %s cannot be dropped", QuoteUser(name))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[name]; !ok {
+		if ifExists {
+			return nil
+		}
+		return fmt.Errorf("user %s does not exist", QuoteUser(name))
+	}
+
+	users := s.copyUsers()
+	delete(users, name)
+	return s.save(users)
+}
+
+// SetPassword changes the password of the user |name|.
+func (s *Store) SetPassword(name, password string) error {
+	if s.IsSuperUser(name) {
+		return fmt.Errorf("the password of the super user %s is set by the server configuration", QuoteUser(name))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := s.copyUsers()
+	u, ok := users.clone(name)
+	if !ok {
+		return fmt.Errorf("user %s does not exist", QuoteUser(name))
+	}
+
+	u.passwordHash = auth.NativePassword(password)
+	return s.save(users)
+}
+
+// Grant gives the privileges in |g| to the user |name|.
+func (s *Store) Grant(name string, g Grant) error {
+	if s.IsSuperUser(name) {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := s.copyUsers()
+	u, ok := users.clone(name)
+	if !ok {
+		return fmt.Errorf("user %s does not exist", QuoteUser(name))
+	}
+
+	for i := range u.grants {
+		if u.grants[i].sameScope(g) {
+			u.grants[i].Privileges |= g.Privileges
+			u.grants[i].GrantOption = u.grants[i].GrantOption || g.GrantOption
+			return s.save(users)
+		}
+	}
+
+	u.grants = append(u.grants, g)
+	return s.save(users)
+}
+
+// Revoke takes the privileges in |g| away from the user |name|. If |g| has GrantOption set, the user loses the ability
+// to grant privileges on the scope of |g|. Only grants made with exactly the same scope as |g| are changed.
+func (s *Store) Revoke(name string, g Grant) error {
+	if s.IsSuperUser(name) {
+		return fmt.Errorf("privileges cannot be revoked from the super user %s", QuoteUser(name))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := s.copyUsers()
+	u, ok := users.clone(name)
+	if !ok {
+		return fmt.Errorf("user %s does not exist", QuoteUser(name))
+	}
+
+	for i := range u.grants {
+		if u.grants[i].sameScope(g) {
+			u.grants[i].Privileges &^= g.Privileges
+			if g.GrantOption {
+				u.grants[i].GrantOption = false
+			}
+
+			if u.grants[i].Privileges == NoPrivileges && !u.grants[i].GrantOption {
+				u.grants = append(u.grants[:i], u.grants[i+1:]...)
+			}
+			return s.save(users)
+		}
+	}
+
+	return fmt.Errorf("there is no such grant defined for user %s on %s.%s", QuoteUser(name), quoteScope(g.Database), quoteScope(g.Table))
+}
+
+// Privileges returns the privileges the user |name| has on the table |tbl| in the database |db|. A |tbl| of Wildcard
+// returns the privileges the user has on the database as a whole.
+func (s *Store) Privileges(name, db, tbl string) Privilege {
+	if s.IsSuperUser(name) {
+		return AllPrivileges
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[name]
+	if !ok {
+		return NoPrivileges
+	}
+
+	privs := NoPrivileges
+	for _, g := range u.grants {
+		if g.Covers(db, tbl) {
+			privs |= g.Privileges
+		}
+	}
+	return privs
+}
+
+// CanGrant returns whether the user |name| may grant privileges on the table |tbl| in the database |db| to others.
+func (s *Store) CanGrant(name, db, tbl string) bool {
+	if s.IsSuperUser(name) {
+		return true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[name]
+	if !ok {
+		return false
+	}
+
+	for _, g := range u.grants {
+		if g.GrantOption && g.Covers(db, tbl) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAccessToDatabase returns whether the user |name| holds any privilege on the database |db| or any of its tables.
+func (s *Store) HasAccessToDatabase(name, db string) bool {
+	if s.IsSuperUser(name) {
+		return true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[name]
+	if !ok {
+		return false
+	}
+
+	for _, g := range u.grants {
+		if (g.Database == Wildcard || strings.EqualFold(g.Database, db)) && (g.Privileges != NoPrivileges || g.GrantOption) {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantStatements returns the statements which grant the user |name| its privileges, as displayed by SHOW GRANTS.
+func (s *Store) GrantStatements(name string) ([]string, error) {
+	if s.IsSuperUser(name) {
+		return []string{Grant{Wildcard, Wildcard, AllPrivileges, true}.String(name)}, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[name]
+	if !ok {
+		return nil, fmt.Errorf("there is no such grant defined for user %s", QuoteUser(name))
+	}
+
+	stmts := []string{Grant{Wildcard, Wildcard, NoPrivileges, false}.String(name)}
+	for _, g := range u.grants {
+		if g.Database == Wildcard && g.Table == Wildcard {
+			stmts[0] = g.String(name)
+		} else {
+			stmts = append(stmts, g.String(name))
+		}
+	}
+	return stmts, nil
+}
+
+type storeFile struct {
+	Users []userFile `json:"users"`
+}
+
+type userFile struct {
+	Name     string      `json:"name"`
+	Password string      `json:"password"`
+	Grants   []grantFile `json:"grants"`
+}
+
+type grantFile struct {
+	Database    string   `json:"database"`
+	Table       string   `json:"table"`
+	Privileges  []string `json:"privileges"`
+	GrantOption bool     `json:"grant_option,omitempty"`
+}
+
+func (s *Store) load() error {
+	data, err := s.fs.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	var sf storeFile
+	if err := json.Unmarshal(data, &sf); err != nil {
+		return fmt.Errorf("error parsing privilege file %s: %v", s.path, err)
+	}
+
+	for _, uf := range sf.Users {
+		if _, ok := s.users[uf.Name]; ok || s.IsSuperUser(uf.Name) || uf.Name == "" {
+			return fmt.Errorf("error parsing privilege file %s: invalid or duplicate user '%s'", s.path, uf.Name)
+		}
+
+		// passwords may be given in plain text when the file is written by hand
+		hash := uf.Password
+		if !nativePasswordRegex.MatchString(hash) {
+			hash = auth.NativePassword(hash)
+		}
+
+		u := &user{name: uf.Name, passwordHash: hash}
+		for _, gf := range uf.Grants {
+			g := Grant{Database: gf.Database, Table: gf.Table, GrantOption: gf.GrantOption}
+			if g.Database == "" {
+				g.Database = Wildcard
+			}
+			if g.Table == "" {
+				g.Table = Wildcard
+			}
+
+			for _, name := range gf.Privileges {
+				priv, err := ParsePrivilege(name)
+				if err != nil {
+					return fmt.Errorf("error parsing privilege file %s: %v", s.path, err)
+				}
+				g.Privileges |= priv
+			}
+
+			u.grants = append(u.grants, g)
+		}
+
+		s.users[uf.Name] = u
+	}
+
+	return nil
+}
+
+// copyUsers returns a copy of the users of the store which can be changed and then passed to save. Callers must hold
+// the write lock.
+func (s *Store) copyUsers() userMap {
+	users := make(userMap, len(s.users))
+	for name, u := range s.users {
+		users[name] = u
+	}
+	return users
+}
+
+// save makes |users| the users of the store after writing them to its file, leaving the store unchanged if the write
+// fails. The file is written with permissions only for its owner, as it holds password hashes. Callers must hold the
+// write lock.
+func (s *Store) save(users userMap) error {
+	if s.path != "" {
+		if err := s.writeFile(users); err != nil {
+			return err
+		}
+	}
+
+	s.users = users
+	return nil
+}
+
+func (s *Store) writeFile(users userMap) error {
+	names := make([]string, 0, len(users))
+	for name := range users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sf := storeFile{Users: []userFile{}}
+	for _, name := range names {
+		u := users[name]
+		uf := userFile{Name: u.name, Password: u.passwordHash, Grants: []grantFile{}}
+		for _, g := range u.grants {
+			names := g.Privileges.Names()
+			if names == nil {
+				names = []string{}
+			}
+			uf.Grants = append(uf.Grants, grantFile{Database: g.Database, Table: g.Table, Privileges: names, GrantOption: g.GrantOption})
+		}
+		sf.Users = append(sf.Users, uf)
+	}
+
+	data, err := json.MarshalIndent(sf, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// the file is replaced by a new one so that it is never left partially written, and so that a file created with
+	// broader permissions by an earlier version is made private
+	tmpPath := s.path + ".tmp"
+	if exists, _ := s.fs.Exists(tmpPath); exists {
+		if err := s.fs.DeleteFile(tmpPath); err != nil {
+			return err
+		}
+	}
+
+	wr, err := s.fs.OpenForWrite(tmpPath, privilegeFilePerm)
+	if err != nil {
+		return err
+	}
+
+	_, err = wr.Write(data)
+	if closeErr := wr.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = s.fs.DeleteFile(tmpPath)
+		return err
+	}
+
+	return s.fs.MoveFile(tmpPath, s.path)
+}
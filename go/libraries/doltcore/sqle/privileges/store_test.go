@@ -0,0 +1,196 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privileges
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+const testPrivilegeFile = "/privileges.json"
+
+func newTestStore(t *testing.T, fs filesys.ReadWriteFS) *Store {
+	s, err := NewStore(fs, testPrivilegeFile, "root", "rootpass")
+	require.NoError(t, err)
+	return s
+}
+
+func TestParsePrivilege(t *testing.T) {
+	priv, err := ParsePrivilege("select")
+	require.NoError(t, err)
+	assert.Equal(t, SelectPriv, priv)
+
+	priv, err = ParsePrivilege("create   view")
+	require.NoError(t, err)
+	assert.Equal(t, CreateViewPriv, priv)
+
+	priv, err = ParsePrivilege("all privileges")
+	require.NoError(t, err)
+	assert.Equal(t, AllPrivileges, priv)
+
+	_, err = ParsePrivilege("fly")
+	assert.Error(t, err)
+
+	assert.Equal(t, "SELECT, INSERT", (SelectPriv | InsertPriv).String())
+	assert.Equal(t, "USAGE", NoPrivileges.String())
+	assert.Equal(t, "ALL PRIVILEGES", AllPrivileges.String())
+}
+
+func TestStoreGrantAndRevoke(t *testing.T) {
+	s := newTestStore(t, filesys.EmptyInMemFS("/"))
+
+	require.NoError(t, s.CreateUser("bob", "pw", false))
+	assert.Error(t, s.CreateUser("bob", "pw", false))
+	assert.NoError(t, s.CreateUser("bob", "pw", true))
+	assert.Error(t, s.CreateUser("root", "pw", false))
+
+	require.NoError(t, s.Grant("bob", Grant{Database: "mydb", Table: "t1", Privileges: SelectPriv}))
+	require.NoError(t, s.Grant("bob", Grant{Database: "mydb", Table: "t1", Privileges: InsertPriv}))
+	require.NoError(t, s.Grant("bob", Grant{Database: "other", Table: Wildcard, Privileges: AllPrivileges}))
+
+	assert.Equal(t, SelectPriv|InsertPriv, s.Privileges("bob", "mydb", "t1"))
+	assert.Equal(t, SelectPriv|InsertPriv, s.Privileges("bob", "MYDB", "T1"))
+	assert.Equal(t, NoPrivileges, s.Privileges("bob", "mydb", "t2"))
+	assert.Equal(t, NoPrivileges, s.Privileges("bob", "mydb", Wildcard))
+	assert.Equal(t, AllPrivileges, s.Privileges("bob", "other", "anything"))
+	assert.Equal(t, AllPrivileges, s.Privileges("root", "mydb", "t2"))
+	assert.Equal(t, NoPrivileges, s.Privileges("nobody", "mydb", "t1"))
+
+	assert.True(t, s.HasAccessToDatabase("bob", "mydb"))
+	assert.False(t, s.HasAccessToDatabase("bob", "third"))
+	assert.False(t, s.CanGrant("bob", "mydb", "t1"))
+	assert.True(t, s.CanGrant("root", "mydb", "t1"))
+
+	require.NoError(t, s.Revoke("bob", Grant{Database: "mydb", Table: "t1", Privileges: InsertPriv}))
+	assert.Equal(t, SelectPriv, s.Privileges("bob", "mydb", "t1"))
+	assert.Error(t, s.Revoke("bob", Grant{Database: "mydb", Table: "t9", Privileges: InsertPriv}))
+
+	stmts, err := s.GrantStatements("bob")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"GRANT USAGE ON *.* TO 'bob'@'%'",
+		"GRANT SELECT ON `mydb`.`t1` TO 'bob'@'%'",
+		"GRANT ALL PRIVILEGES ON `other`.* TO 'bob'@'%'",
+	}, stmts)
+
+	require.NoError(t, s.DropUser("bob", false))
+	assert.Error(t, s.DropUser("bob", false))
+	assert.NoError(t, s.DropUser("bob", true))
+	assert.Error(t, s.DropUser("root", false))
+}
+
+func TestStorePersistence(t *testing.T) {
+	fs := filesys.EmptyInMemFS("/")
+	s := newTestStore(t, fs)
+	assert.False(t, s.Persisted())
+
+	require.NoError(t, s.CreateUser("alice", "secret", false))
+	require.NoError(t, s.Grant("alice", Grant{Database: "mydb", Table: Wildcard, Privileges: SelectPriv | UpdatePriv, GrantOption: true}))
+	assert.True(t, s.Persisted())
+
+	reloaded := newTestStore(t, fs)
+	assert.Equal(t, SelectPriv|UpdatePriv, reloaded.Privileges("alice", "mydb", "t1"))
+	assert.True(t, reloaded.CanGrant("alice", "mydb", "t1"))
+
+	hash, ok := reloaded.PasswordHash("alice")
+	require.True(t, ok)
+	assert.Equal(t, auth.NativePassword("secret"), hash)
+	assert.Equal(t, []string{"root", "alice"}, reloaded.UserNames())
+}
+
+func TestStoreLoadsPlainTextPasswords(t *testing.T) {
+	fs := filesys.EmptyInMemFS("/")
+	data := `{"users": [{"name": "carol", "password": "hunter2", "grants": [{"database": "mydb", "privileges": ["select", "insert"]}]}]}`
+	require.NoError(t, fs.WriteFile(testPrivilegeFile, []byte(data)))
+
+	s := newTestStore(t, fs)
+	hash, ok := s.PasswordHash("carol")
+	require.True(t, ok)
+	assert.Equal(t, auth.NativePassword("hunter2"), hash)
+	assert.Equal(t, SelectPriv|InsertPriv, s.Privileges("carol", "mydb", "t1"))
+
+	require.NoError(t, fs.WriteFile(testPrivilegeFile, []byte(`{"users": [{"name": "root"}]}`)))
+	_, err := NewStore(fs, testPrivilegeFile, "root", "")
+	assert.Error(t, err)
+}
+
+// failingWriteFS is a filesystem whose writes fail when fail is set
+type failingWriteFS struct {
+	filesys.ReadWriteFS
+	fail bool
+}
+
+func (fs *failingWriteFS) OpenForWrite(fp string, perm os.FileMode) (io.WriteCloser, error) {
+	if fs.fail {
+		return nil, errors.New("no space left on device")
+	}
+	return fs.ReadWriteFS.OpenForWrite(fp, perm)
+}
+
+func TestStoreUnchangedWhenSaveFails(t *testing.T) {
+	fs := &failingWriteFS{ReadWriteFS: filesys.EmptyInMemFS("/")}
+	s := newTestStore(t, fs)
+	require.NoError(t, s.CreateUser("bob", "pw", false))
+	require.NoError(t, s.Grant("bob", Grant{Database: "db", Table: Wildcard, Privileges: SelectPriv}))
+
+	fs.fail = true
+	assert.Error(t, s.Grant("bob", Grant{Database: "db", Table: Wildcard, Privileges: InsertPriv}))
+	assert.Error(t, s.Grant("bob", Grant{Database: "other", Table: Wildcard, Privileges: InsertPriv}))
+	assert.Error(t, s.Revoke("bob", Grant{Database: "db", Table: Wildcard, Privileges: SelectPriv}))
+	assert.Error(t, s.SetPassword("bob", "new"))
+	assert.Error(t, s.CreateUser("alice", "pw", false))
+	assert.Error(t, s.DropUser("bob", false))
+
+	assert.Equal(t, SelectPriv, s.Privileges("bob", "db", "t"))
+	assert.Equal(t, NoPrivileges, s.Privileges("bob", "other", "t"))
+	assert.Equal(t, []string{"root", "bob"}, s.UserNames())
+	hash, _ := s.PasswordHash("bob")
+	assert.Equal(t, auth.NativePassword("pw"), hash)
+
+	fs.fail = false
+	loaded := newTestStore(t, fs)
+	assert.Equal(t, SelectPriv, loaded.Privileges("bob", "db", "t"))
+}
+
+func TestStoreFileIsPrivate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "privileges")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "privileges.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"users": []}`), 0644))
+
+	s, err := NewStore(filesys.LocalFS, path, "root", "rootpass")
+	require.NoError(t, err)
+	require.NoError(t, s.CreateUser("bob", "pw", false))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := NewStore(filesys.LocalFS, path, "root", "rootpass")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"root", "bob"}, loaded.UserNames())
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+)
+
+func TestQueryLimits(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateEnvWithSeedData(t)
+	require.NoError(t, actions.StageAllTables(ctx, dEnv.DbData()))
+	_, err := actions.CommitStaged(ctx, dEnv.DbData(), actions.CommitStagedProps{Message: "add people", Date: time.Now(), Name: "Bill Billerson", Email: "bill@billerson.com"})
+	require.NoError(t, err)
+	root, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+
+	db := NewDatabase("dolt", dEnv.DbData())
+	_, sqlCtx, err := NewTestEngine(ctx, db, root)
+	require.NoError(t, err)
+
+	// each query of a session has its own pid, and its own count of the rows it has read
+	var pid uint64 = 100
+	tableRowIter := func(ctx context.Context, tableName string) sql.RowIter {
+		pid++
+		queryCtx := sql.NewContext(ctx, sql.WithSession(sqlCtx.Session), sql.WithPid(pid))
+
+		tbl, ok, err := db.GetTableInsensitive(queryCtx, tableName)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		partitions, err := tbl.Partitions(queryCtx)
+		require.NoError(t, err)
+		return sql.NewTableRowIter(queryCtx, tbl, partitions)
+	}
+	readTable := func(ctx context.Context, tableName string) ([]sql.Row, error) {
+		return sql.RowIterToRows(tableRowIter(ctx, tableName))
+	}
+
+	for _, tableName := range []string{"people", "dolt_history_people"} {
+		t.Run(tableName, func(t *testing.T) {
+			require.NoError(t, sqlCtx.Session.Set(sqlCtx, sqlutil.MaxQueryRowsSessionVar, sql.Uint64, uint64(0)))
+			rows, err := readTable(ctx, tableName)
+			require.NoError(t, err)
+			require.NotEmpty(t, rows)
+			numRows := uint64(len(rows))
+
+			require.NoError(t, sqlCtx.Session.Set(sqlCtx, sqlutil.MaxQueryRowsSessionVar, sql.Uint64, numRows-1))
+			_, err = readTable(ctx, tableName)
+			assert.True(t, sqlutil.ErrQueryRowLimitExceeded.Is(err), "unexpected error %v", err)
+
+			require.NoError(t, sqlCtx.Session.Set(sqlCtx, sqlutil.MaxQueryRowsSessionVar, sql.Uint64, numRows))
+			rows, err = readTable(ctx, tableName)
+			require.NoError(t, err)
+			assert.Len(t, rows, int(numRows))
+
+			// the rows which are left aren't read once the query is killed, whether the iterator of the table or one it reads
+			// from notices first
+			killableCtx, kill := context.WithCancel(ctx)
+			iter := tableRowIter(killableCtx, tableName)
+			_, err = iter.Next()
+			require.NoError(t, err)
+			kill()
+			_, err = iter.Next()
+			assert.True(t, sqlutil.ErrQueryInterrupted.Is(err) || errors.Is(err, context.Canceled), "unexpected error %v", err)
+			require.NoError(t, iter.Close())
+		})
+	}
+}
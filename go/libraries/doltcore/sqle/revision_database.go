@@ -0,0 +1,486 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/merge"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// DbRevisionDelimiter separates the name of a database from the branch or commit in the name of a revision database,
+// e.g. mydb/feature-x
+const DbRevisionDelimiter = "/"
+
+var ErrReadOnlyDatabase = errors.NewKind("Database %s is read only")
+var ErrRevisionNotFound = errors.NewKind("Database %s not found: no branch or commit named %s")
+var ErrRevisionBehindBranch = errors.NewKind("the changes to branch %s conflict with the commits made to it by other databases, and can't be committed")
+var ErrRevisionStateInvalid = errors.NewKind("the saved working set of branch %s in %s is invalid: %v")
+
+// revisionStateDir is the directory within the dolt dir holding the working sets of branch revision databases
+const revisionStateDir = "revisions"
+
+// SplitRevisionDbName splits the name of a revision database into the name of the database it is a revision of and the
+// revision. ok is false if |name| is not the name of a revision database.
+func SplitRevisionDbName(name string) (base string, revision string, ok bool) {
+	idx := strings.Index(name, DbRevisionDelimiter)
+	if idx <= 0 || idx == len(name)-1 {
+		return name, "", false
+	}
+
+	return name[:idx], name[idx+1:], true
+}
+
+// BaseDbName returns the name of the database |name| is a revision of, or |name| if it isn't a revision database.
+func BaseDbName(name string) string {
+	base, _, _ := SplitRevisionDbName(name)
+	return base
+}
+
+// NewRevisionDatabase returns a Database named |name|/|revision| which is bound to the branch or commit |revision| of
+// the database |dbData|. Writes to a branch database go to a working set for the branch, and committing it advances the
+// branch. The working set is saved in the dolt dir of |fs|, which should be the filesystem of the database's repo, so
+// uncommitted changes survive the database being created again, e.g. after a restart. If |fs| is nil the working set
+// is only kept in memory. A database bound to a commit hash is read only.
+func NewRevisionDatabase(ctx context.Context, name, revision string, dbData env.DbData, fs filesys.ReadWriteFS) (Database, error) {
+	dbName := name + DbRevisionDelimiter + revision
+	ddb := dbData.Ddb
+
+	if doltdb.IsValidUserBranchName(revision) {
+		branchRef := ref.NewBranchRef(revision)
+		hasBranch, err := ddb.HasRef(ctx, branchRef)
+
+		if err != nil {
+			return Database{}, err
+		}
+
+		if hasBranch {
+			cm, err := ddb.ResolveRef(ctx, branchRef)
+
+			if err != nil {
+				return Database{}, err
+			}
+
+			rs, err := newRevisionRepoState(ctx, ddb, cm, branchRef)
+
+			if err != nil {
+				return Database{}, err
+			}
+
+			if fs != nil {
+				err = rs.load(ctx, fs)
+
+				if err != nil {
+					return Database{}, err
+				}
+			}
+
+			db := NewDatabase(dbName, env.DbData{Ddb: ddb, Rsr: rs, Rsw: rs, Drw: dbData.Drw})
+			return db, nil
+		}
+	}
+
+	if !hash.IsValid(revision) {
+		return Database{}, ErrRevisionNotFound.New(dbName, revision)
+	}
+
+	cs, err := doltdb.NewCommitSpec(revision)
+
+	if err != nil {
+		return Database{}, err
+	}
+
+	cm, err := ddb.Resolve(ctx, cs, nil)
+
+	if err != nil {
+		return Database{}, ErrRevisionNotFound.New(dbName, revision)
+	}
+
+	rs, err := newRevisionRepoState(ctx, ddb, cm, nil)
+
+	if err != nil {
+		return Database{}, err
+	}
+
+	db := NewDatabase(dbName, env.DbData{Ddb: ddb, Rsr: rs, Rsw: rs, Drw: dbData.Drw})
+	db.readOnly = true
+
+	return db, nil
+}
+
+// revisionRepoState is the repo state of a revision database. The working and staged roots start out as the root of
+// the revision's commit. |branch| is nil for a database bound to a commit, which has a detached head and cannot be
+// written to. |commit| is the commit of the branch the working and staged roots are based on, and |commitRoot| is its
+// root. If |fs| isn't nil every change to them is saved to the file at |path|, and the file is removed when the working
+// and staged roots have no changes.
+type revisionRepoState struct {
+	mu         *sync.RWMutex
+	ddb        *doltdb.DoltDB
+	branch     ref.DoltRef
+	commit     hash.Hash
+	commitRoot hash.Hash
+	working    hash.Hash
+	staged     hash.Hash
+	fs         filesys.ReadWriteFS
+	path       string
+}
+
+// revisionStateFile is the saved working set of a branch revision database
+type revisionStateFile struct {
+	Commit  string `json:"commit"`
+	Working string `json:"working"`
+	Staged  string `json:"staged"`
+}
+
+var _ env.RepoStateReader = (*revisionRepoState)(nil)
+var _ env.RepoStateWriter = (*revisionRepoState)(nil)
+
+func newRevisionRepoState(ctx context.Context, ddb *doltdb.DoltDB, cm *doltdb.Commit, branch ref.DoltRef) (*revisionRepoState, error) {
+	h, err := cm.HashOf()
+
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := cm.GetRootValue()
+
+	if err != nil {
+		return nil, err
+	}
+
+	rootHash, err := root.HashOf()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &revisionRepoState{mu: &sync.RWMutex{}, ddb: ddb, branch: branch, commit: h, commitRoot: rootHash, working: rootHash, staged: rootHash}, nil
+}
+
+// revisionStatePath returns the path of the file the working set of |branch| is saved to
+func revisionStatePath(branch ref.DoltRef) string {
+	return filepath.Join(dbfactory.DoltDir, revisionStateDir, filepath.FromSlash(branch.GetPath())+".json")
+}
+
+// load restores the working set saved in |fs| if there is one, and saves every later change to it there. The roots of
+// a saved working set whose commit is no longer on the branch are rebased onto the branch the next time it is synced.
+func (rs *revisionRepoState) load(ctx context.Context, fs filesys.ReadWriteFS) error {
+	path := revisionStatePath(rs.branch)
+	invalid := func(err error) error {
+		return ErrRevisionStateInvalid.New(rs.branch.GetPath(), path, err)
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.fs, rs.path = fs, path
+
+	if exists, _ := fs.Exists(path); !exists {
+		return nil
+	}
+
+	var sf revisionStateFile
+	err := filesys.UnmarshalJSONFile(fs, path, &sf)
+	if err != nil {
+		return invalid(err)
+	}
+
+	var hashes [3]hash.Hash
+	for i, str := range []string{sf.Commit, sf.Working, sf.Staged} {
+		h, ok := hash.MaybeParse(str)
+		if !ok {
+			return invalid(fmt.Errorf("%s is not a hash", str))
+		}
+		hashes[i] = h
+	}
+
+	cs, err := doltdb.NewCommitSpec(sf.Commit)
+	if err != nil {
+		return invalid(err)
+	}
+
+	cm, err := rs.ddb.Resolve(ctx, cs, nil)
+	if err != nil {
+		return invalid(err)
+	}
+
+	commitRoot, err := cm.GetRootValue()
+	if err != nil {
+		return err
+	}
+
+	commitRootHash, err := commitRoot.HashOf()
+	if err != nil {
+		return err
+	}
+
+	for _, h := range hashes[1:] {
+		if _, err = rs.ddb.ReadRootValue(ctx, h); err != nil {
+			return invalid(err)
+		}
+	}
+
+	rs.commit, rs.commitRoot, rs.working, rs.staged = hashes[0], commitRootHash, hashes[1], hashes[2]
+	return nil
+}
+
+// save saves the working set to |rs.fs|, or removes the saved working set if it has no changes. The caller must hold
+// the write lock.
+func (rs *revisionRepoState) save() error {
+	if rs.fs == nil {
+		return nil
+	}
+
+	if rs.working == rs.commitRoot && rs.staged == rs.commitRoot {
+		if exists, _ := rs.fs.Exists(rs.path); !exists {
+			return nil
+		}
+		return rs.fs.DeleteFile(rs.path)
+	}
+
+	data, err := json.MarshalIndent(revisionStateFile{rs.commit.String(), rs.working.String(), rs.staged.String()}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = rs.fs.MkDirs(filepath.Dir(rs.path))
+	if err != nil {
+		return err
+	}
+
+	// the roots were flushed when they were written, so once the file is replaced the working set can be restored
+	tmp := rs.path + ".tmp"
+	err = rs.fs.WriteFile(tmp, data)
+	if err != nil {
+		return err
+	}
+
+	return rs.fs.MoveFile(tmp, rs.path)
+}
+
+// syncRevisionHead brings the working and staged roots of |rsr| up to date with the head of its branch if it is the
+// repo state of a branch revision database. It does nothing for any other repo state.
+func syncRevisionHead(ctx context.Context, rsr env.RepoStateReader) error {
+	if rs, ok := rsr.(*revisionRepoState); ok {
+		_, err := rs.syncWithHead(ctx)
+		return err
+	}
+
+	return nil
+}
+
+// CheckRevisionHead returns an error if |rsr| is the repo state of a branch revision database whose working and staged
+// roots can't be brought up to date with the head of the branch, which was moved by a commit made through another
+// database. Committing them would undo the changes of the commits the branch has moved past.
+func CheckRevisionHead(ctx context.Context, rsr env.RepoStateReader) error {
+	rs, ok := rsr.(*revisionRepoState)
+	if !ok {
+		return nil
+	}
+
+	upToDate, err := rs.syncWithHead(ctx)
+	if err != nil {
+		return err
+	} else if !upToDate {
+		return ErrRevisionBehindBranch.New(rs.branch.GetPath())
+	}
+
+	return nil
+}
+
+// syncWithHead rebases the working and staged roots onto the head of the branch if it has moved since they were last
+// synced. Roots without changes become the root of the new head, and the changes of the others are merged with it.
+// If the changes can't be merged the roots are left as they are, and false is returned.
+func (rs *revisionRepoState) syncWithHead(ctx context.Context) (bool, error) {
+	if rs.branch == nil {
+		return true, nil
+	}
+
+	cm, err := rs.ddb.ResolveRef(ctx, rs.branch)
+	if err != nil {
+		return false, err
+	}
+
+	h, err := cm.HashOf()
+	if err != nil {
+		return false, err
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if h == rs.commit {
+		return true, nil
+	}
+
+	headRoot, err := cm.GetRootValue()
+	if err != nil {
+		return false, err
+	}
+
+	headRootHash, err := headRoot.HashOf()
+	if err != nil {
+		return false, err
+	}
+
+	// the head is the commit of the staged root, which was committed through this database
+	if headRootHash == rs.staged {
+		rs.commit, rs.commitRoot = h, headRootHash
+		return true, rs.save()
+	}
+
+	working, ok, err := rs.rebaseRoot(ctx, rs.working, headRoot, headRootHash)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	staged, ok, err := rs.rebaseRoot(ctx, rs.staged, headRoot, headRootHash)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	rs.commit, rs.commitRoot, rs.working, rs.staged = h, headRootHash, working, staged
+	return true, rs.save()
+}
+
+// rebaseRoot returns the hash of the root with the changes the root |rootHash| made to the commit root merged into
+// |headRoot|. ok is false if the changes can't be merged.
+func (rs *revisionRepoState) rebaseRoot(ctx context.Context, rootHash hash.Hash, headRoot *doltdb.RootValue, headRootHash hash.Hash) (h hash.Hash, ok bool, err error) {
+	if rootHash == rs.commitRoot || rootHash == headRootHash {
+		return headRootHash, true, nil
+	}
+
+	root, err := rs.ddb.ReadRootValue(ctx, rootHash)
+	if err != nil {
+		return hash.Hash{}, false, err
+	}
+
+	ancRoot, err := rs.ddb.ReadRootValue(ctx, rs.commitRoot)
+	if err != nil {
+		return hash.Hash{}, false, err
+	}
+
+	// like conflicts, merge errors leave the root as it is
+	merged, stats, err := merge.MergeRoots(ctx, root, headRoot, ancRoot)
+	if err != nil || merge.HasConflicts(stats) {
+		return hash.Hash{}, false, nil
+	}
+
+	h, err = rs.ddb.WriteRootValue(ctx, merged)
+	if err != nil {
+		return hash.Hash{}, false, err
+	}
+
+	return h, true, nil
+}
+
+// CWBHeadRef implements env.RepoStateReader
+func (rs *revisionRepoState) CWBHeadRef() ref.DoltRef {
+	return rs.branch
+}
+
+// CWBHeadSpec implements env.RepoStateReader
+func (rs *revisionRepoState) CWBHeadSpec() *doltdb.CommitSpec {
+	spec := "HEAD"
+	if rs.branch == nil {
+		spec = rs.commit.String()
+	}
+
+	cs, _ := doltdb.NewCommitSpec(spec)
+	return cs
+}
+
+// CWBHeadHash implements env.RepoStateReader
+func (rs *revisionRepoState) CWBHeadHash(ctx context.Context) (hash.Hash, error) {
+	if rs.branch == nil {
+		return rs.commit, nil
+	}
+
+	cm, err := rs.ddb.ResolveRef(ctx, rs.branch)
+
+	if err != nil {
+		return hash.Hash{}, err
+	}
+
+	return cm.HashOf()
+}
+
+// WorkingHash implements env.RepoStateReader
+func (rs *revisionRepoState) WorkingHash() hash.Hash {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	return rs.working
+}
+
+// StagedHash implements env.RepoStateReader
+func (rs *revisionRepoState) StagedHash() hash.Hash {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	return rs.staged
+}
+
+// IsMergeActive implements env.RepoStateReader
+func (rs *revisionRepoState) IsMergeActive() bool {
+	return false
+}
+
+// GetMergeCommit implements env.RepoStateReader
+func (rs *revisionRepoState) GetMergeCommit() string {
+	return ""
+}
+
+// SetStagedHash implements env.RepoStateWriter
+func (rs *revisionRepoState) SetStagedHash(ctx context.Context, h hash.Hash) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.branch == nil && h != rs.staged {
+		return env.ErrStateUpdate
+	}
+
+	rs.staged = h
+	return rs.save()
+}
+
+// SetWorkingHash implements env.RepoStateWriter. The working root of a database bound to a commit can't change.
+func (rs *revisionRepoState) SetWorkingHash(ctx context.Context, h hash.Hash) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.branch == nil && h != rs.working {
+		return env.ErrStateUpdate
+	}
+
+	rs.working = h
+	return rs.save()
+}
+
+// ClearMerge implements env.RepoStateWriter
+func (rs *revisionRepoState) ClearMerge() error {
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/dolthub/go-mysql-server/sql"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table"
 	"github.com/dolthub/dolt/go/libraries/utils/set"
 	"github.com/dolthub/dolt/go/store/types"
@@ -28,8 +29,9 @@ import (
 // An iterator over the rows of a table.
 type doltTableRowIter struct {
 	sql.RowIter
-	ctx    context.Context
-	reader table.SqlTableReader
+	ctx     context.Context
+	reader  table.SqlTableReader
+	limiter *sqlutil.QueryLimiter
 }
 
 // Returns a new row iterator for the table given
@@ -62,8 +64,9 @@ func newKeylessRowIterator(ctx *sql.Context, tbl *DoltTable, partition *doltTabl
 	}
 
 	return &doltTableRowIter{
-		ctx:    ctx,
-		reader: iter,
+		ctx:     ctx,
+		reader:  iter,
+		limiter: sqlutil.NewQueryLimiter(ctx),
 	}, nil
 }
 
@@ -107,7 +110,13 @@ func newKeyedRowIter(ctx context.Context, tbl *DoltTable, projectedCols []string
 
 // Next returns the next row in this row iterator, or an io.EOF error if there aren't any more.
 func (itr *doltTableRowIter) Next() (sql.Row, error) {
-	return itr.reader.ReadSqlRow(itr.ctx)
+	r, err := itr.reader.ReadSqlRow(itr.ctx)
+
+	if err := itr.limiter.RowRead(err); err != nil {
+		return nil, err
+	}
+
+	return r, nil
 }
 
 // Close required by sql.RowIter interface
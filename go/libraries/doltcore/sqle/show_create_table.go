@@ -17,13 +17,23 @@ package sqle
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	sqle "github.com/dolthub/go-mysql-server"
 	"github.com/dolthub/go-mysql-server/sql"
 
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlfmt"
 	"github.com/dolthub/dolt/go/libraries/utils/tracing"
 )
 
+// checkedTable is a table with check constraints. The engine doesn't know about check constraints, so they are added
+// to the CREATE TABLE statements that it generates.
+type checkedTable interface {
+	sql.Table
+	checkConstraints() []schema.Check
+}
+
 // These functions cannot be in the sqlfmt package as the reliance on the sqle package creates a circular reference.
 
 func PrepareCreateTableStmt(ctx context.Context, sqlDb sql.Database) (*sql.Context, *sqle.Engine, *DoltSession) {
@@ -55,5 +65,31 @@ func GetCreateTableStmt(ctx *sql.Context, engine *sqle.Engine, tableName string)
 	if !ok {
 		return "", fmt.Errorf("expected string statement from SHOW CREATE TABLE")
 	}
+
+	tbl, err := engine.Catalog.Table(ctx, ctx.GetCurrentDatabase(), tableName)
+	if err != nil {
+		return "", err
+	}
+	if ct, ok := tbl.(checkedTable); ok {
+		stmt = addChecksToCreateTableStmt(stmt, ct.checkConstraints())
+	}
+
 	return stmt + ";", nil
 }
+
+// addChecksToCreateTableStmt adds the definitions of |checks| after the last column or index definition of |stmt|.
+func addChecksToCreateTableStmt(stmt string, checks []schema.Check) string {
+	end := strings.LastIndex(stmt, "\n)")
+	if len(checks) == 0 || end == -1 {
+		return stmt
+	}
+
+	var sb strings.Builder
+	sb.WriteString(stmt[:end])
+	for _, check := range checks {
+		sb.WriteString(",\n  ")
+		sb.WriteString(sqlfmt.FmtCheck(check))
+	}
+	sb.WriteString(stmt[end:])
+	return sb.String()
+}
@@ -74,6 +74,10 @@ func (db *SingleTableInfoDatabase) String() string {
 	return db.tableName
 }
 
+func (db *SingleTableInfoDatabase) checkConstraints() []schema.Check {
+	return db.sch.Checks().AllChecks()
+}
+
 // Schema implements sql.Table.
 func (db *SingleTableInfoDatabase) Schema() sql.Schema {
 	sqlSch, err := sqlutil.FromDoltSchema(db.tableName, db.sch)
@@ -91,6 +91,20 @@ func FmtIndex(index schema.Index) string {
 	return sb.String()
 }
 
+// FmtCheck creates a string representing a check constraint within a sql create table statement.
+func FmtCheck(check schema.Check) string {
+	sb := strings.Builder{}
+	sb.WriteString("CONSTRAINT ")
+	sb.WriteString(QuoteIdentifier(check.Name()))
+	sb.WriteString(" CHECK (")
+	sb.WriteString(check.Expression())
+	sb.WriteRune(')')
+	if !check.Enforced() {
+		sb.WriteString(" NOT ENFORCED")
+	}
+	return sb.String()
+}
+
 func FmtForeignKey(fk doltdb.ForeignKey, sch, parentSch schema.Schema) string {
 	sb := strings.Builder{}
 	sb.WriteString("CONSTRAINT ")
@@ -125,6 +139,41 @@ func FmtForeignKey(fk doltdb.ForeignKey, sch, parentSch schema.Schema) string {
 	return sb.String()
 }
 
+// CreateTableStmt returns a CREATE TABLE statement for a table with the given name and schema. Foreign keys are not
+// included, as they are not part of the schema.
+func CreateTableStmt(tableName string, sch schema.Schema) string {
+	var lines []string
+	_ = sch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		lines = append(lines, FmtCol(2, 0, 0, col))
+		return false, nil
+	})
+
+	var pks []string
+	_ = sch.GetPKCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		pks = append(pks, QuoteIdentifier(col.Name))
+		return false, nil
+	})
+	if len(pks) > 0 {
+		lines = append(lines, "  PRIMARY KEY ("+strings.Join(pks, ",")+")")
+	}
+
+	for _, idx := range sch.Indexes().AllIndexes() {
+		lines = append(lines, "  "+FmtIndex(idx))
+	}
+
+	for _, check := range sch.Checks().AllChecks() {
+		lines = append(lines, "  "+FmtCheck(check))
+	}
+
+	var b strings.Builder
+	b.WriteString("CREATE TABLE ")
+	b.WriteString(QuoteIdentifier(tableName))
+	b.WriteString(" (\n")
+	b.WriteString(strings.Join(lines, ",\n"))
+	b.WriteString("\n);")
+	return b.String()
+}
+
 func DropTableStmt(tableName string) string {
 	var b strings.Builder
 	b.WriteString("DROP TABLE ")
@@ -241,3 +290,23 @@ func AlterTableDropForeignKeyStmt(fk doltdb.ForeignKey) string {
 	b.WriteRune(';')
 	return b.String()
 }
+
+func AlterTableAddCheckStmt(tableName string, check schema.Check) string {
+	var b strings.Builder
+	b.WriteString("ALTER TABLE ")
+	b.WriteString(QuoteIdentifier(tableName))
+	b.WriteString(" ADD ")
+	b.WriteString(FmtCheck(check))
+	b.WriteRune(';')
+	return b.String()
+}
+
+func AlterTableDropCheckStmt(tableName string, check schema.Check) string {
+	var b strings.Builder
+	b.WriteString("ALTER TABLE ")
+	b.WriteString(QuoteIdentifier(tableName))
+	b.WriteString(" DROP CHECK ")
+	b.WriteString(QuoteIdentifier(check.Name()))
+	b.WriteRune(';')
+	return b.String()
+}
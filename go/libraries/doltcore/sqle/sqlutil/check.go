@@ -0,0 +1,270 @@
+// Copyright 2019 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/expression/function"
+	"github.com/dolthub/go-mysql-server/sql/parse"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+)
+
+var checkFunctions = func() sql.FunctionRegistry {
+	fr := sql.NewFunctionRegistry()
+	fr.MustRegister(function.Defaults...)
+	return fr
+}()
+
+// CheckViolationError is returned when a row does not satisfy a check constraint of its table.
+type CheckViolationError struct {
+	CheckName string
+}
+
+func (e CheckViolationError) Error() string {
+	return fmt.Sprintf("Check constraint '%s' is violated.", e.CheckName)
+}
+
+// IsCheckViolation returns whether |err| is a CheckViolationError.
+func IsCheckViolation(err error) bool {
+	_, ok := err.(CheckViolationError)
+	return ok
+}
+
+type compiledCheck struct {
+	name string
+	expr sql.Expression
+}
+
+// CheckEvaluator evaluates the check constraints of a schema against the rows of a table.
+type CheckEvaluator struct {
+	sch    schema.Schema
+	checks []compiledCheck
+	// sqlCtx evaluates the checks when they aren't given a *sql.Context
+	sqlCtx *sql.Context
+}
+
+// NewCheckEvaluator returns a CheckEvaluator for the enforced checks of |sch|. Returns nil if |sch| has no enforced
+// checks.
+func NewCheckEvaluator(ctx context.Context, sch schema.Schema) (*CheckEvaluator, error) {
+	var checks []compiledCheck
+	for _, check := range sch.Checks().AllChecks() {
+		if !check.Enforced() {
+			continue
+		}
+
+		expr, err := ParseCheckExpression(ctx, sch, check.Expression())
+		if err != nil {
+			return nil, fmt.Errorf("invalid check constraint '%s': %v", check.Name(), err)
+		}
+
+		checks = append(checks, compiledCheck{check.Name(), expr})
+	}
+
+	if len(checks) == 0 {
+		return nil, nil
+	}
+
+	sqlCtx, ok := ctx.(*sql.Context)
+	if !ok {
+		sqlCtx = sql.NewContext(ctx)
+	}
+
+	return &CheckEvaluator{sch, checks, sqlCtx}, nil
+}
+
+// NewCheckRowValidator returns a function validating rows against the enforced checks of |sch|, suitable for
+// editor.TableEditSessionProps.NewRowValidator. Returns nil if |sch| has no enforced checks.
+func NewCheckRowValidator(ctx context.Context, sch schema.Schema) (func(ctx context.Context, r row.Row) error, error) {
+	ce, err := NewCheckEvaluator(ctx, sch)
+	if err != nil || ce == nil {
+		return nil, err
+	}
+
+	return ce.Validate, nil
+}
+
+// Validate returns a CheckViolationError for the first check that |r| does not satisfy, or nil if it satisfies all
+// of them. A check is only violated when its expression evaluates to false; a NULL result satisfies the check.
+func (ce *CheckEvaluator) Validate(ctx context.Context, r row.Row) error {
+	sqlCtx, sqlRow, err := ce.toSqlRow(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	for _, check := range ce.checks {
+		ok, err := check.satisfiedBy(sqlCtx, sqlRow)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return CheckViolationError{check.name}
+		}
+	}
+
+	return nil
+}
+
+// ViolatedChecks returns the names of all of the checks that |r| does not satisfy.
+func (ce *CheckEvaluator) ViolatedChecks(ctx context.Context, r row.Row) ([]string, error) {
+	sqlCtx, sqlRow, err := ce.toSqlRow(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var violated []string
+	for _, check := range ce.checks {
+		ok, err := check.satisfiedBy(sqlCtx, sqlRow)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			violated = append(violated, check.name)
+		}
+	}
+
+	return violated, nil
+}
+
+func (ce *CheckEvaluator) toSqlRow(ctx context.Context, r row.Row) (*sql.Context, sql.Row, error) {
+	sqlRow, err := DoltRowToSqlRow(r, ce.sch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sqlCtx, ok := ctx.(*sql.Context)
+	if !ok {
+		sqlCtx = ce.sqlCtx
+	}
+
+	return sqlCtx, sqlRow, nil
+}
+
+func (c compiledCheck) satisfiedBy(ctx *sql.Context, r sql.Row) (bool, error) {
+	res, err := c.expr.Eval(ctx, r)
+	if err != nil {
+		return false, err
+	}
+
+	if res == nil {
+		return true, nil
+	}
+
+	return sql.EvaluateCondition(ctx, expression.NewLiteral(res, c.expr.Type()), r)
+}
+
+// ParseCheckExpression parses the check constraint expression |exprStr| and resolves it against the columns of |sch|.
+// Expressions may only reference the columns of the table and built in functions.
+func ParseCheckExpression(ctx context.Context, sch schema.Schema, exprStr string) (sql.Expression, error) {
+	expr, err := ParseExpression(ctx, exprStr, func(col *expression.UnresolvedColumn) (sql.Expression, error) {
+		field := ColumnGetField(sch, col.Name(), 0)
+		if field == nil {
+			return nil, fmt.Errorf("unknown column '%s' in check constraint", col.Name())
+		}
+		return field, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !expr.Resolved() {
+		return nil, fmt.Errorf("'%s' is not supported in a check constraint", exprStr)
+	}
+
+	return expr, nil
+}
+
+// ParseExpression parses the SQL expression |exprStr|, resolving each column it references with |resolveColumn| and
+// each function it calls against the built in functions. The returned expression is not resolved if |exprStr| uses
+// anything else, such as a subquery.
+func ParseExpression(ctx context.Context, exprStr string, resolveColumn func(col *expression.UnresolvedColumn) (sql.Expression, error)) (sql.Expression, error) {
+	sqlCtx, ok := ctx.(*sql.Context)
+	if !ok {
+		sqlCtx = sql.NewContext(ctx)
+	}
+
+	// expressions will not parse on their own, so we parse them as the projection of a SELECT
+	node, err := parse.Parse(sqlCtx, "SELECT "+exprStr)
+	if err != nil {
+		return nil, err
+	}
+
+	project, ok := node.(*plan.Project)
+	if !ok || len(project.Projections) != 1 {
+		return nil, fmt.Errorf("'%s' is not a single expression", exprStr)
+	}
+
+	return expression.TransformUp(project.Projections[0], func(e sql.Expression) (sql.Expression, error) {
+		switch e := e.(type) {
+		case *expression.UnresolvedColumn:
+			return resolveColumn(e)
+		case *expression.UnresolvedFunction:
+			f, err := checkFunctions.Function(e.Name())
+			if err != nil {
+				return nil, err
+			}
+			return f.Call(e.Arguments...)
+		}
+		return e, nil
+	})
+}
+
+// ColumnGetField returns a GetField expression for the column of |sch| named |colName|, reading it from a row which
+// holds the columns of |sch| in order starting at |offset|. Returns nil if |sch| has no such column.
+func ColumnGetField(sch schema.Schema, colName string, offset int) sql.Expression {
+	idx := offset
+	var field sql.Expression
+	_ = sch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		if strings.ToLower(col.Name) == strings.ToLower(colName) {
+			field = expression.NewGetField(idx, col.TypeInfo.ToSqlType(), col.Name, col.IsNullable())
+			return true, nil
+		}
+		idx++
+		return false, nil
+	})
+
+	return field
+}
+
+// CheckReferencesColumn returns whether the check constraint expression |exprStr| references the column |colName|.
+func CheckReferencesColumn(ctx context.Context, exprStr string, colName string) (bool, error) {
+	sqlCtx, ok := ctx.(*sql.Context)
+	if !ok {
+		sqlCtx = sql.NewContext(ctx)
+	}
+
+	node, err := parse.Parse(sqlCtx, "SELECT "+exprStr)
+	if err != nil {
+		return false, err
+	}
+
+	found := false
+	plan.InspectExpressions(node, func(e sql.Expression) bool {
+		if col, ok := e.(*expression.UnresolvedColumn); ok && strings.ToLower(col.Name()) == strings.ToLower(colName) {
+			found = true
+		}
+		return !found
+	})
+
+	return found, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlutil
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+// MaxQueryRowsSessionVar is the session variable holding the number of rows a single query may read from tables,
+// summed over every table the query reads. 0 means there is no limit.
+const MaxQueryRowsSessionVar = "dolt_max_query_rows"
+
+var ErrQueryInterrupted = errors.NewKind("Query execution was interrupted")
+var ErrQueryRowLimitExceeded = errors.NewKind("Query execution was interrupted, the query read more than %d rows. The limit is set by " + MaxQueryRowsSessionVar)
+
+// QueryRowCounter is implemented by sessions which count the rows read by their queries.
+type QueryRowCounter interface {
+	// QueryRowCount returns the counter of the rows read by the query with the process id |pid|. The same counter is
+	// returned for each call made with the pid of the session's current query.
+	QueryRowCount(pid uint64) *uint64
+	// QueryRowsRead returns the process id of the latest query to read rows and the number of rows it has read.
+	QueryRowsRead() (pid uint64, rows uint64)
+}
+
+// QueryLimiter is used by row iterators to stop reading rows once their query has been killed or cancelled, or has
+// read more rows than its session's limit allows.
+type QueryLimiter struct {
+	ctx   context.Context
+	rows  *uint64
+	limit uint64
+}
+
+// NewQueryLimiter returns a QueryLimiter for the query which |ctx| belongs to. If |ctx| is not a *sql.Context, or its
+// session doesn't count rows, only the cancellation of |ctx| is checked.
+func NewQueryLimiter(ctx context.Context) *QueryLimiter {
+	l := &QueryLimiter{ctx: ctx}
+
+	sqlCtx, ok := ctx.(*sql.Context)
+	if !ok || sqlCtx.Session == nil {
+		return l
+	}
+
+	counter, ok := sqlCtx.Session.(QueryRowCounter)
+	if !ok {
+		return l
+	}
+
+	if limit := MaxQueryRows(sqlCtx.Session); limit > 0 {
+		l.rows = counter.QueryRowCount(sqlCtx.Pid())
+		l.limit = limit
+	}
+
+	return l
+}
+
+// MaxQueryRows returns the number of rows each query of |sess| may read, or 0 if there is no limit.
+func MaxQueryRows(sess sql.Session) uint64 {
+	_, val := sess.Get(MaxQueryRowsSessionVar)
+	if val == nil {
+		return 0
+	}
+
+	limit, err := sql.Uint64.Convert(val)
+	if err != nil {
+		return 0
+	}
+
+	return limit.(uint64)
+}
+
+// RowRead is called with the error of reading each row, and returns the error the row iterator should return. That is
+// ErrQueryInterrupted if the query has been killed or cancelled, ErrQueryRowLimitExceeded if the row is over the limit
+// of the query, and |err| otherwise.
+func (l *QueryLimiter) RowRead(err error) error {
+	if l.ctx.Err() != nil {
+		return ErrQueryInterrupted.New()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if l.rows != nil && atomic.AddUint64(l.rows, 1) > l.limit {
+		return ErrQueryRowLimitExceeded.New(l.limit)
+	}
+
+	return nil
+}
@@ -139,6 +139,9 @@ func (te *sqlTableEditor) flush(ctx *sql.Context) error {
 	if !ok {
 		return errhand.BuildDError("failed to find updated table").Build()
 	}
+	if err = te.t.db.SetRoot(ctx, newRoot); err != nil {
+		return err
+	}
 	te.t.table = newTable
-	return te.t.db.SetRoot(ctx, newRoot)
+	return nil
 }
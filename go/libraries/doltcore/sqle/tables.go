@@ -216,6 +216,10 @@ func (t *DoltTable) Schema() sql.Schema {
 	return t.sqlSchema()
 }
 
+func (t *DoltTable) checkConstraints() []schema.Check {
+	return t.sch.Checks().AllChecks()
+}
+
 func (t *DoltTable) sqlSchema() sql.Schema {
 	if t.sqlSch != nil {
 		return t.sqlSch
@@ -876,6 +880,97 @@ func (t *AlterableDoltTable) RenameIndex(ctx *sql.Context, fromIndexName string,
 	return t.updateFromRoot(ctx, newRoot)
 }
 
+// CreateCheck adds a check constraint named |name| to this table. If |name| is empty, a name is generated. When the check
+// is enforced, every existing row must satisfy it.
+func (t *AlterableDoltTable) CreateCheck(ctx *sql.Context, name string, expression string, enforced bool) error {
+	if name == "" {
+		name = t.generateCheckName()
+	}
+	if err := addCheck(ctx, t.sch, name, expression, enforced); err != nil {
+		return err
+	}
+
+	if enforced {
+		rowData, err := t.table.GetRowData(ctx)
+		if err == nil {
+			var violations []string
+			violations, err = table.CheckConstraintViolations(ctx, t.name, t.sch, rowData)
+			if err == nil && len(violations) > 0 {
+				err = sqlutil.CheckViolationError{CheckName: name}
+			}
+		}
+		if err != nil {
+			_ = t.sch.Checks().DropCheck(name)
+			return err
+		}
+	}
+
+	return t.updateSchema(ctx)
+}
+
+// DropCheck removes the check constraint named |name| from this table.
+func (t *AlterableDoltTable) DropCheck(ctx *sql.Context, name string) error {
+	if err := t.sch.Checks().DropCheck(name); err != nil {
+		return err
+	}
+	return t.updateSchema(ctx)
+}
+
+// HasCheck returns whether this table has a check constraint named |name|.
+func (t *AlterableDoltTable) HasCheck(name string) bool {
+	_, ok := t.sch.Checks().GetByNameCaseInsensitive(name)
+	return ok
+}
+
+func (t *AlterableDoltTable) generateCheckName() string {
+	return generateCheckName(t.name, t.sch)
+}
+
+// generateCheckName returns the first unused name of the form <table>_chk_<n> for a check on the table |tblName|
+func generateCheckName(tblName string, sch schema.Schema) string {
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s_chk_%d", tblName, i)
+		if _, ok := sch.Checks().GetByNameCaseInsensitive(name); !ok {
+			return name
+		}
+	}
+}
+
+// addCheck validates the check constraint |name| and adds it to |sch|
+func addCheck(ctx *sql.Context, sch schema.Schema, name string, expression string, enforced bool) error {
+	if !doltdb.IsValidTableName(name) {
+		return fmt.Errorf("invalid check constraint name `%s` as it must match the regular expression %s", name, doltdb.TableNameRegexStr)
+	}
+	if _, err := sqlutil.ParseCheckExpression(ctx, sch, expression); err != nil {
+		return err
+	}
+
+	_, err := sch.Checks().AddCheck(name, expression, enforced)
+	return err
+}
+
+func (t *AlterableDoltTable) updateSchema(ctx *sql.Context) error {
+	newTable, err := t.table.UpdateSchema(ctx, t.sch)
+	if err != nil {
+		return err
+	}
+
+	root, err := t.db.GetRoot(ctx)
+	if err != nil {
+		return err
+	}
+	newRoot, err := root.PutTable(ctx, t.name, newTable)
+	if err != nil {
+		return err
+	}
+
+	err = t.db.SetRoot(ctx, newRoot)
+	if err != nil {
+		return err
+	}
+	return t.updateFromRoot(ctx, newRoot)
+}
+
 // CreateForeignKey implements sql.ForeignKeyAlterableTable
 func (t *AlterableDoltTable) CreateForeignKey(
 	ctx *sql.Context,
@@ -0,0 +1,93 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"path"
+	"sync"
+
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+)
+
+var ErrProtectedBranch = errors.NewKind("Branch %s of database %s is protected and cannot be written to")
+var ErrInvalidBranchPattern = errors.NewKind("Invalid protected branch pattern '%s'")
+
+// WriteProtection restricts the writes which may be made to a database. The zero value allows every write.
+type WriteProtection struct {
+	// ReadOnly rejects every write to the database
+	ReadOnly bool
+	// ProtectedBranches are patterns matching the names of branches which can't be written to, in the syntax of
+	// path.Match, e.g. "main" or "release/*"
+	ProtectedBranches []string
+}
+
+// Validate returns an error if any of the protected branch patterns are malformed
+func (wp WriteProtection) Validate() error {
+	for _, pattern := range wp.ProtectedBranches {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return ErrInvalidBranchPattern.New(pattern)
+		}
+	}
+
+	return nil
+}
+
+// IsProtectedBranch returns whether the branch named |branch| matches one of the protected branch patterns
+func (wp WriteProtection) IsProtectedBranch(branch string) bool {
+	for _, pattern := range wp.ProtectedBranches {
+		if matched, err := path.Match(pattern, branch); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckWrite returns an error if the database |dbName|, whose checked out branch is given by |rsr|, can't be written to.
+func (wp WriteProtection) CheckWrite(dbName string, rsr env.RepoStateReader) error {
+	if wp.ReadOnly {
+		return ErrReadOnlyDatabase.New(dbName)
+	}
+
+	if len(wp.ProtectedBranches) == 0 {
+		return nil
+	}
+
+	if headRef := rsr.CWBHeadRef(); headRef != nil && wp.IsProtectedBranch(headRef.GetPath()) {
+		return ErrProtectedBranch.New(headRef.GetPath(), dbName)
+	}
+
+	return nil
+}
+
+// sharedWriteProtection is a WriteProtection shared by databases, which can be changed while they are in use
+type sharedWriteProtection struct {
+	mu *sync.RWMutex
+	wp WriteProtection
+}
+
+func (swp *sharedWriteProtection) get() WriteProtection {
+	swp.mu.RLock()
+	defer swp.mu.RUnlock()
+	return swp.wp
+}
+
+func (swp *sharedWriteProtection) set(wp WriteProtection) {
+	swp.mu.Lock()
+	defer swp.mu.Unlock()
+	swp.wp = wp
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
+)
+
+func TestWriteProtection(t *testing.T) {
+	dEnv := dtestutils.CreateTestEnv()
+	rsr := dEnv.RepoStateReader()
+
+	tests := []struct {
+		name     string
+		wp       WriteProtection
+		expected error
+	}{
+		{"no protection", WriteProtection{}, nil},
+		{"read only", WriteProtection{ReadOnly: true}, ErrReadOnlyDatabase.New("mydb")},
+		{"other branch protected", WriteProtection{ProtectedBranches: []string{"main", "release/*"}}, nil},
+		{"branch protected", WriteProtection{ProtectedBranches: []string{"main", "mast*"}}, ErrProtectedBranch.New("master", "mydb")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.wp.CheckWrite("mydb", rsr)
+			if test.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expected.Error())
+			}
+		})
+	}
+
+	assert.True(t, WriteProtection{ProtectedBranches: []string{"release/*"}}.IsProtectedBranch("release/1.0"))
+	assert.False(t, WriteProtection{ProtectedBranches: []string{"release/*"}}.IsProtectedBranch("release/1.0/hotfix"))
+	assert.NoError(t, WriteProtection{ProtectedBranches: []string{"main", "release/*"}}.Validate())
+	assert.Error(t, WriteProtection{ProtectedBranches: []string{"release/["}}.Validate())
+}
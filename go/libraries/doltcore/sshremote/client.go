@@ -0,0 +1,241 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sshremote
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os/exec"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotestorage"
+)
+
+const (
+	// rpcPathPrefix is the path prefix of the requests which call the methods of the chunk store service
+	rpcPathPrefix = "/rpc/"
+	// statusCodeHeader is the header holding the gRPC status code of a failed call
+	statusCodeHeader = "Dolt-Status-Code"
+	// transferHost is the host of the URLs the server hands out for table files. Requests for them are sent over the
+	// connection of the client whatever their host.
+	transferHost = "dolt-transfer"
+)
+
+// Client is a remotesapi.ChunkStoreServiceClient which calls a server over a single connection. It is also the
+// remotestorage.HTTPFetcher used to upload and download the table files of the server.
+type Client struct {
+	conn net.Conn
+	cc   *http2.ClientConn
+}
+
+var _ remotesapi.ChunkStoreServiceClient = (*Client)(nil)
+var _ remotestorage.HTTPFetcher = (*Client)(nil)
+
+// NewClient returns a Client which calls the server on the other end of |conn|.
+func NewClient(conn net.Conn) (*Client, error) {
+	t := &http2.Transport{AllowHTTP: true}
+	cc, err := t.NewClientConn(conn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{conn, cc}, nil
+}
+
+// StartCommand starts |cmd| and returns a Client which calls the server reading from its stdin and writing to its
+// stdout. Closing the client closes the stdin of |cmd| and waits for it to exit.
+func StartCommand(cmd *exec.Cmd) (*Client, error) {
+	stdin, err := cmd.StdinPipe()
+
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = cmd.Start()
+
+	if err != nil {
+		return nil, err
+	}
+
+	conn := NewPipeConn(stdout, stdin, func() error {
+		_ = stdin.Close()
+		return cmd.Wait()
+	})
+
+	client, err := NewClient(conn)
+
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() error {
+	_ = c.cc.Close()
+	return c.conn.Close()
+}
+
+// Do sends |req| to the server, whatever the host of its URL. It is used for the table file URLs the server hands out.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	return c.cc.RoundTrip(req)
+}
+
+// call calls |method| of the server with |req|, and reads the response into |resp|. The errors of failed calls have
+// the gRPC status the server returned.
+func (c *Client) call(ctx context.Context, method string, req, resp proto.Message) error {
+	data, err := proto.Marshal(req)
+
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, "http://"+transferHost+rpcPathPrefix+method, bytes.NewReader(data))
+
+	if err != nil {
+		return err
+	}
+
+	httpResp, err := c.cc.RoundTrip(httpReq.WithContext(ctx))
+
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	defer httpResp.Body.Close()
+
+	body, err := ioutil.ReadAll(httpResp.Body)
+
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		code, err := strconv.Atoi(httpResp.Header.Get(statusCodeHeader))
+
+		if err != nil {
+			code = int(codes.Unknown)
+		}
+
+		return status.Error(codes.Code(code), string(body))
+	}
+
+	return proto.Unmarshal(body, resp)
+}
+
+func (c *Client) GetRepoMetadata(ctx context.Context, in *remotesapi.GetRepoMetadataRequest, opts ...grpc.CallOption) (*remotesapi.GetRepoMetadataResponse, error) {
+	resp := &remotesapi.GetRepoMetadataResponse{}
+	if err := c.call(ctx, "GetRepoMetadata", in, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *Client) HasChunks(ctx context.Context, in *remotesapi.HasChunksRequest, opts ...grpc.CallOption) (*remotesapi.HasChunksResponse, error) {
+	resp := &remotesapi.HasChunksResponse{}
+	if err := c.call(ctx, "HasChunks", in, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *Client) GetDownloadLocations(ctx context.Context, in *remotesapi.GetDownloadLocsRequest, opts ...grpc.CallOption) (*remotesapi.GetDownloadLocsResponse, error) {
+	resp := &remotesapi.GetDownloadLocsResponse{}
+	if err := c.call(ctx, "GetDownloadLocations", in, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// StreamDownloadLocations is not supported. Clients use GetDownloadLocations.
+func (c *Client) StreamDownloadLocations(ctx context.Context, opts ...grpc.CallOption) (remotesapi.ChunkStoreService_StreamDownloadLocationsClient, error) {
+	return nil, status.Error(codes.Unimplemented, "StreamDownloadLocations is not supported by ssh remotes")
+}
+
+func (c *Client) GetUploadLocations(ctx context.Context, in *remotesapi.GetUploadLocsRequest, opts ...grpc.CallOption) (*remotesapi.GetUploadLocsResponse, error) {
+	resp := &remotesapi.GetUploadLocsResponse{}
+	if err := c.call(ctx, "GetUploadLocations", in, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *Client) Rebase(ctx context.Context, in *remotesapi.RebaseRequest, opts ...grpc.CallOption) (*remotesapi.RebaseResponse, error) {
+	resp := &remotesapi.RebaseResponse{}
+	if err := c.call(ctx, "Rebase", in, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *Client) Root(ctx context.Context, in *remotesapi.RootRequest, opts ...grpc.CallOption) (*remotesapi.RootResponse, error) {
+	resp := &remotesapi.RootResponse{}
+	if err := c.call(ctx, "Root", in, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *Client) Commit(ctx context.Context, in *remotesapi.CommitRequest, opts ...grpc.CallOption) (*remotesapi.CommitResponse, error) {
+	resp := &remotesapi.CommitResponse{}
+	if err := c.call(ctx, "Commit", in, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *Client) ListTableFiles(ctx context.Context, in *remotesapi.ListTableFilesRequest, opts ...grpc.CallOption) (*remotesapi.ListTableFilesResponse, error) {
+	resp := &remotesapi.ListTableFilesResponse{}
+	if err := c.call(ctx, "ListTableFiles", in, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *Client) AddTableFiles(ctx context.Context, in *remotesapi.AddTableFilesRequest, opts ...grpc.CallOption) (*remotesapi.AddTableFilesResponse, error) {
+	resp := &remotesapi.AddTableFilesResponse{}
+	if err := c.call(ctx, "AddTableFiles", in, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sshremote serves the chunk store of a dolt database over a pair of pipes, such as the stdin and stdout of a
+// `dolt transfer` process run over ssh, and provides the client used by ssh:// remotes. The messages of the remotesapi
+// chunk store service are sent as HTTP/2 requests over the pipes, along with the table file uploads and downloads
+// which the remotesapi server would serve over separate HTTP connections.
+package sshremote
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// pipeConn is a net.Conn which reads from one pipe and writes to another. Deadlines are not supported.
+type pipeConn struct {
+	io.Reader
+	io.Writer
+	closeFn  func() error
+	once     *sync.Once
+	closeErr error
+}
+
+var _ net.Conn = (*pipeConn)(nil)
+
+// NewPipeConn returns a net.Conn which reads from |r| and writes to |w|. Closing it calls |closeFn|, which should close
+// |w| so that the other side sees the end of the connection. |closeFn| is only called once.
+func NewPipeConn(r io.Reader, w io.Writer, closeFn func() error) net.Conn {
+	return &pipeConn{Reader: r, Writer: w, closeFn: closeFn, once: &sync.Once{}}
+}
+
+func (c *pipeConn) Close() error {
+	c.once.Do(func() {
+		c.closeErr = c.closeFn()
+	})
+
+	return c.closeErr
+}
+
+func (c *pipeConn) LocalAddr() net.Addr {
+	return pipeAddr{}
+}
+
+func (c *pipeConn) RemoteAddr() net.Addr {
+	return pipeAddr{}
+}
+
+func (c *pipeConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *pipeConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *pipeConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string {
+	return "pipe"
+}
+
+func (pipeAddr) String() string {
+	return "pipe"
+}
@@ -0,0 +1,501 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sshremote
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotestorage"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/nbs"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const defaultMemTableSize = 128 * 1024 * 1024
+
+// Server serves the chunk store of a single database. It ignores the repo ids of requests.
+type Server struct {
+	remotesapi.UnimplementedChunkStoreServiceServer
+
+	dir string
+
+	mu *sync.Mutex
+	cs *nbs.NomsBlockStore
+	// expectedFiles are the details of the table files clients have asked to upload, by file id
+	expectedFiles map[string]*remotesapi.TableFileDetails
+}
+
+var _ remotesapi.ChunkStoreServiceServer = (*Server)(nil)
+var _ http.Handler = (*Server)(nil)
+
+// NewServer returns a Server for the database whose table files are in |dir|.
+func NewServer(dir string) (*Server, error) {
+	info, err := os.Stat(dir)
+
+	if err != nil {
+		return nil, fmt.Errorf("no dolt database at '%s': %w", dir, err)
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("no dolt database at '%s': not a directory", dir)
+	}
+
+	return &Server{
+		dir:           dir,
+		mu:            &sync.Mutex{},
+		expectedFiles: make(map[string]*remotesapi.TableFileDetails),
+	}, nil
+}
+
+// Serve serves the requests of the client on the other end of |conn| until it closes the connection.
+func (s *Server) Serve(ctx context.Context, conn net.Conn) {
+	h2 := &http2.Server{}
+	h2.ServeConn(conn, &http2.ServeConnOpts{Context: ctx, Handler: s, BaseConfig: &http.Server{}})
+}
+
+// Close closes the chunk store of the server.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cs == nil {
+		return nil
+	}
+
+	return s.cs.Close()
+}
+
+// getStore returns the chunk store of the server, opening it with the format |nbfVerStr| if it isn't open yet.
+func (s *Server) getStore(ctx context.Context, nbfVerStr string) (*nbs.NomsBlockStore, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cs == nil {
+		cs, err := nbs.NewLocalStore(ctx, nbfVerStr, s.dir, defaultMemTableSize)
+
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to open chunk store: "+err.Error())
+		}
+
+		s.cs = cs
+	}
+
+	return s.cs, nil
+}
+
+func (s *Server) getDefaultStore(ctx context.Context) (*nbs.NomsBlockStore, error) {
+	return s.getStore(ctx, types.Format_Default.VersionString())
+}
+
+func tableFileUrl(fileId string) string {
+	return fmt.Sprintf("http://%s/%s", transferHost, fileId)
+}
+
+// ServeHTTP serves calls of the methods of the chunk store service, and the uploads and downloads of table files.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if strings.HasPrefix(req.URL.Path, rpcPathPrefix) {
+		s.serveRPC(w, req, strings.TrimPrefix(req.URL.Path, rpcPathPrefix))
+		return
+	}
+
+	fileId := strings.TrimPrefix(req.URL.Path, "/")
+	if _, ok := hash.MaybeParse(fileId); !ok {
+		http.Error(w, "invalid table file id: "+fileId, http.StatusNotFound)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		s.readTableFile(w, req, fileId)
+	case http.MethodPost, http.MethodPut:
+		s.writeTableFile(w, req, fileId)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) serveRPC(w http.ResponseWriter, httpReq *http.Request, method string) {
+	ctx := httpReq.Context()
+
+	var req proto.Message
+	var call func() (proto.Message, error)
+	switch method {
+	case "GetRepoMetadata":
+		r := &remotesapi.GetRepoMetadataRequest{}
+		req, call = r, func() (proto.Message, error) { return s.GetRepoMetadata(ctx, r) }
+	case "HasChunks":
+		r := &remotesapi.HasChunksRequest{}
+		req, call = r, func() (proto.Message, error) { return s.HasChunks(ctx, r) }
+	case "GetDownloadLocations":
+		r := &remotesapi.GetDownloadLocsRequest{}
+		req, call = r, func() (proto.Message, error) { return s.GetDownloadLocations(ctx, r) }
+	case "GetUploadLocations":
+		r := &remotesapi.GetUploadLocsRequest{}
+		req, call = r, func() (proto.Message, error) { return s.GetUploadLocations(ctx, r) }
+	case "Rebase":
+		r := &remotesapi.RebaseRequest{}
+		req, call = r, func() (proto.Message, error) { return s.Rebase(ctx, r) }
+	case "Root":
+		r := &remotesapi.RootRequest{}
+		req, call = r, func() (proto.Message, error) { return s.Root(ctx, r) }
+	case "Commit":
+		r := &remotesapi.CommitRequest{}
+		req, call = r, func() (proto.Message, error) { return s.Commit(ctx, r) }
+	case "ListTableFiles":
+		r := &remotesapi.ListTableFilesRequest{}
+		req, call = r, func() (proto.Message, error) { return s.ListTableFiles(ctx, r) }
+	case "AddTableFiles":
+		r := &remotesapi.AddTableFilesRequest{}
+		req, call = r, func() (proto.Message, error) { return s.AddTableFiles(ctx, r) }
+	default:
+		writeRPCError(w, status.Error(codes.Unimplemented, "unknown method "+method))
+		return
+	}
+
+	data, err := ioutil.ReadAll(httpReq.Body)
+
+	if err == nil {
+		err = proto.Unmarshal(data, req)
+	}
+
+	if err != nil {
+		writeRPCError(w, status.Error(codes.InvalidArgument, "failed to read request: "+err.Error()))
+		return
+	}
+
+	resp, err := call()
+
+	if err == nil {
+		data, err = proto.Marshal(resp)
+	}
+
+	if err != nil {
+		writeRPCError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/protobuf")
+	_, _ = w.Write(data)
+}
+
+func writeRPCError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	w.Header().Set(statusCodeHeader, strconv.Itoa(int(st.Code())))
+	w.WriteHeader(http.StatusInternalServerError)
+	_, _ = io.WriteString(w, st.Message())
+}
+
+func (s *Server) readTableFile(w http.ResponseWriter, req *http.Request, fileId string) {
+	f, err := os.Open(filepath.Join(s.dir, fileId))
+
+	if os.IsNotExist(err) {
+		http.Error(w, "table file not found: "+fileId, http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	defer f.Close()
+
+	// ServeContent handles the range requests used to download chunks
+	http.ServeContent(w, req, fileId, time.Time{}, f)
+}
+
+// writeTableFile writes the body of |req| to the table file |fileId|, which must be one a client was given the upload
+// location of. The file is added to the manifest of the store by a later call to Commit or AddTableFiles.
+func (s *Server) writeTableFile(w http.ResponseWriter, req *http.Request, fileId string) {
+	s.mu.Lock()
+	tfd, ok := s.expectedFiles[fileId]
+	s.mu.Unlock()
+
+	if !ok {
+		http.Error(w, "unexpected table file: "+fileId, http.StatusBadRequest)
+		return
+	}
+
+	data, err := ioutil.ReadAll(req.Body)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if tfd.ContentLength != 0 && tfd.ContentLength != uint64(len(data)) {
+		http.Error(w, "table file has the wrong length: "+fileId, http.StatusBadRequest)
+		return
+	}
+
+	contentHash := tfd.ContentHash
+	if md5Str := req.Header.Get("Content-MD5"); len(contentHash) == 0 && md5Str != "" {
+		contentHash, _ = base64.StdEncoding.DecodeString(md5Str)
+	}
+
+	if len(contentHash) > 0 {
+		actual := md5.Sum(data)
+		if !bytes.Equal(contentHash, actual[:]) {
+			http.Error(w, "table file has the wrong content hash: "+fileId, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// the file is written under a temporary name so that a failed upload never leaves a partial table file
+	tmp, err := ioutil.TempFile(s.dir, fileId+".*.tmp")
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = tmp.Write(data)
+
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(s.dir, fileId))
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.Lock()
+	delete(s.expectedFiles, fileId)
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) GetRepoMetadata(ctx context.Context, req *remotesapi.GetRepoMetadataRequest) (*remotesapi.GetRepoMetadataResponse, error) {
+	cs, err := s.getStore(ctx, req.ClientRepoFormat.NbfVersion)
+
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := cs.Size(ctx)
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get the size of the store: "+err.Error())
+	}
+
+	return &remotesapi.GetRepoMetadataResponse{
+		NbfVersion:  cs.Version(),
+		NbsVersion:  req.ClientRepoFormat.NbsVersion,
+		StorageSize: size,
+	}, nil
+}
+
+func (s *Server) HasChunks(ctx context.Context, req *remotesapi.HasChunksRequest) (*remotesapi.HasChunksResponse, error) {
+	cs, err := s.getDefaultStore(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hashes, hashToIndex := remotestorage.ParseByteSlices(req.Hashes)
+	absent, err := cs.HasMany(ctx, hashes)
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, "HasMany failure: "+err.Error())
+	}
+
+	indices := make([]int32, 0, len(absent))
+	for h := range absent {
+		indices = append(indices, int32(hashToIndex[h]))
+	}
+
+	return &remotesapi.HasChunksResponse{Absent: indices}, nil
+}
+
+func (s *Server) GetDownloadLocations(ctx context.Context, req *remotesapi.GetDownloadLocsRequest) (*remotesapi.GetDownloadLocsResponse, error) {
+	cs, err := s.getDefaultStore(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hashes, _ := remotestorage.ParseByteSlices(req.ChunkHashes)
+	locations, err := cs.GetChunkLocations(hashes)
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get chunk locations: "+err.Error())
+	}
+
+	var locs []*remotesapi.DownloadLoc
+	for loc, hashToRange := range locations {
+		var ranges []*remotesapi.RangeChunk
+		for h, r := range hashToRange {
+			hCpy := h
+			ranges = append(ranges, &remotesapi.RangeChunk{Hash: hCpy[:], Offset: r.Offset, Length: r.Length})
+		}
+
+		getRange := &remotesapi.HttpGetRange{Url: tableFileUrl(loc.String()), Ranges: ranges}
+		locs = append(locs, &remotesapi.DownloadLoc{Location: &remotesapi.DownloadLoc_HttpGetRange{HttpGetRange: getRange}})
+	}
+
+	return &remotesapi.GetDownloadLocsResponse{Locs: locs}, nil
+}
+
+func (s *Server) GetUploadLocations(ctx context.Context, req *remotesapi.GetUploadLocsRequest) (*remotesapi.GetUploadLocsResponse, error) {
+	tfds := req.GetTableFileDetails()
+
+	if len(tfds) == 0 {
+		for _, h := range req.TableFileHashes {
+			tfds = append(tfds, &remotesapi.TableFileDetails{Id: h})
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var locs []*remotesapi.UploadLoc
+	for _, tfd := range tfds {
+		h := hash.New(tfd.Id)
+		s.expectedFiles[h.String()] = tfd
+
+		loc := &remotesapi.UploadLoc_HttpPost{HttpPost: &remotesapi.HttpPostTableFile{Url: tableFileUrl(h.String())}}
+		locs = append(locs, &remotesapi.UploadLoc{TableFileHash: h[:], Location: loc})
+	}
+
+	return &remotesapi.GetUploadLocsResponse{Locs: locs}, nil
+}
+
+func (s *Server) Rebase(ctx context.Context, req *remotesapi.RebaseRequest) (*remotesapi.RebaseResponse, error) {
+	cs, err := s.getDefaultStore(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cs.Rebase(ctx); err != nil {
+		return nil, status.Error(codes.Internal, "failed to rebase: "+err.Error())
+	}
+
+	return &remotesapi.RebaseResponse{}, nil
+}
+
+func (s *Server) Root(ctx context.Context, req *remotesapi.RootRequest) (*remotesapi.RootResponse, error) {
+	cs, err := s.getDefaultStore(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := cs.Root(ctx)
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get root: "+err.Error())
+	}
+
+	return &remotesapi.RootResponse{RootHash: h[:]}, nil
+}
+
+func (s *Server) Commit(ctx context.Context, req *remotesapi.CommitRequest) (*remotesapi.CommitResponse, error) {
+	cs, err := s.getDefaultStore(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := updateManifest(ctx, cs, req.ChunkTableInfo); err != nil {
+		return nil, err
+	}
+
+	ok, err := cs.Commit(ctx, hash.New(req.Current), hash.New(req.Last))
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to commit: "+err.Error())
+	}
+
+	return &remotesapi.CommitResponse{Success: ok}, nil
+}
+
+func (s *Server) ListTableFiles(ctx context.Context, req *remotesapi.ListTableFilesRequest) (*remotesapi.ListTableFilesResponse, error) {
+	cs, err := s.getDefaultStore(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	root, tables, err := cs.Sources(ctx)
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get sources: "+err.Error())
+	}
+
+	var tableFileInfo []*remotesapi.TableFileInfo
+	for _, tbl := range tables {
+		tableFileInfo = append(tableFileInfo, &remotesapi.TableFileInfo{
+			FileId:    tbl.FileID(),
+			NumChunks: uint32(tbl.NumChunks()),
+			Url:       tableFileUrl(tbl.FileID()),
+		})
+	}
+
+	return &remotesapi.ListTableFilesResponse{RootHash: root[:], TableFileInfo: tableFileInfo}, nil
+}
+
+// AddTableFiles updates the manifest with new table files without modifying the root hash.
+func (s *Server) AddTableFiles(ctx context.Context, req *remotesapi.AddTableFilesRequest) (*remotesapi.AddTableFilesResponse, error) {
+	cs, err := s.getDefaultStore(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := updateManifest(ctx, cs, req.ChunkTableInfo); err != nil {
+		return nil, err
+	}
+
+	return &remotesapi.AddTableFilesResponse{Success: true}, nil
+}
+
+func updateManifest(ctx context.Context, cs *nbs.NomsBlockStore, infos []*remotesapi.ChunkTableInfo) error {
+	updates := make(map[hash.Hash]uint32, len(infos))
+	for _, cti := range infos {
+		updates[hash.New(cti.Hash)] = cti.ChunkCount
+	}
+
+	_, err := cs.UpdateManifest(ctx, updates)
+
+	if err != nil {
+		return status.Error(codes.Internal, "failed to update the manifest: "+err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sshremote
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/remotestorage"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// serveDirEnvVar is set for the subprocesses of the tests, which serve the directory it holds over stdin and stdout
+const serveDirEnvVar = "SSHREMOTE_TEST_SERVE_DIR"
+
+func TestMain(m *testing.M) {
+	if dir := os.Getenv(serveDirEnvVar); dir != "" {
+		os.Exit(serve(dir))
+	}
+
+	os.Exit(m.Run())
+}
+
+func serve(dir string) int {
+	srv, err := NewServer(dir)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	srv.Serve(context.Background(), NewPipeConn(os.Stdin, os.Stdout, os.Stdout.Close))
+
+	if err := srv.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+// startServer runs the test binary as a server of |dir| and returns a client of it
+func startServer(t *testing.T, dir string) *Client {
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), serveDirEnvVar+"="+dir)
+	cmd.Stderr = os.Stderr
+
+	client, err := StartCommand(cmd)
+	require.NoError(t, err)
+
+	return client
+}
+
+func newChunkStore(t *testing.T, client *Client) *remotestorage.DoltChunkStore {
+	cs, err := remotestorage.NewDoltChunkStore(context.Background(), types.Format_Default, "", "test", "", client)
+	require.NoError(t, err)
+	return cs.WithHTTPFetcher(client)
+}
+
+func TestSubprocessTransport(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "sshremote")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c1 := chunks.NewChunk([]byte("abc"))
+	c2 := chunks.NewChunk([]byte("def"))
+
+	client := startServer(t, dir)
+	cs := newChunkStore(t, client)
+
+	assert.Equal(t, types.Format_Default.VersionString(), cs.Version())
+
+	root, err := cs.Root(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, hash.Hash{}, root)
+
+	require.NoError(t, cs.Put(ctx, c1))
+	require.NoError(t, cs.Put(ctx, c2))
+
+	// committing uploads a table file of the chunks
+	ok, err := cs.Commit(ctx, c1.Hash(), root)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// committing with a stale root fails
+	ok, err = cs.Commit(ctx, c2.Hash(), root)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, client.Close())
+
+	client = startServer(t, dir)
+	defer client.Close()
+	cs = newChunkStore(t, client)
+
+	root, err = cs.Root(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, c1.Hash(), root)
+
+	absent, err := cs.HasMany(ctx, hash.NewHashSet(c1.Hash(), c2.Hash(), hash.Of([]byte("ghi"))))
+	require.NoError(t, err)
+	assert.Equal(t, hash.NewHashSet(hash.Of([]byte("ghi"))), absent)
+
+	// the chunks are downloaded from the table file with range requests
+	found := map[hash.Hash][]byte{}
+	err = cs.GetMany(ctx, hash.NewHashSet(c1.Hash(), c2.Hash()), func(c *chunks.Chunk) {
+		found[c.Hash()] = c.Data()
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[hash.Hash][]byte{c1.Hash(): c1.Data(), c2.Hash(): c2.Data()}, found)
+
+	sourceRoot, tableFiles, err := cs.Sources(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, c1.Hash(), sourceRoot)
+	require.Len(t, tableFiles, 1)
+	assert.Equal(t, 2, tableFiles[0].NumChunks())
+
+	rd, err := tableFiles[0].Open(ctx)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(rd)
+	require.NoError(t, err)
+	require.NoError(t, rd.Close())
+	fileData, err := ioutil.ReadFile(filepath.Join(dir, tableFiles[0].FileID()))
+	require.NoError(t, err)
+	assert.Equal(t, fileData, data)
+}
+
+func TestSubprocessTransportErrors(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "sshremote")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	client := startServer(t, dir)
+	defer client.Close()
+
+	_, err = client.StreamDownloadLocations(ctx)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// table files which were never uploaded are rejected
+	current, last, missing := hash.Of([]byte("abc")), hash.Hash{}, hash.Of([]byte("missing"))
+	_, err = client.Commit(ctx, &remotesapi.CommitRequest{
+		Current:        current[:],
+		Last:           last[:],
+		ChunkTableInfo: []*remotesapi.ChunkTableInfo{{Hash: missing[:], ChunkCount: 1}},
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
@@ -31,6 +31,9 @@ type sessionedTableEditor struct {
 	tableEditor       TableEditor
 	referencedTables  []doltdb.ForeignKey // The tables that we reference to ensure an insert or update is valid
 	referencingTables []doltdb.ForeignKey // The tables that reference us to ensure their inserts and updates are valid
+
+	// validateRow validates each row written to the table, and is nil if the rows need no validation
+	validateRow func(ctx context.Context, r row.Row) error
 }
 
 var _ TableEditor = &sessionedTableEditor{}
@@ -39,6 +42,16 @@ func (ste *sessionedTableEditor) InsertKeyVal(ctx context.Context, key, val type
 	ste.tableEditSession.writeMutex.RLock()
 	defer ste.tableEditSession.writeMutex.RUnlock()
 
+	if ste.validateRow != nil {
+		r, err := row.FromNoms(ste.tableEditor.Schema(), key, val)
+		if err != nil {
+			return err
+		}
+		if err = ste.validateRow(ctx, r); err != nil {
+			return err
+		}
+	}
+
 	err := ste.validateKeyValForInsert(ctx, key, val, tagToVal)
 	if err != nil {
 		return err
@@ -53,6 +66,12 @@ func (ste *sessionedTableEditor) InsertRow(ctx context.Context, dRow row.Row) er
 	ste.tableEditSession.writeMutex.RLock()
 	defer ste.tableEditSession.writeMutex.RUnlock()
 
+	if ste.validateRow != nil {
+		if err := ste.validateRow(ctx, dRow); err != nil {
+			return err
+		}
+	}
+
 	err := ste.validateForInsert(ctx, dRow)
 	if err != nil {
 		return err
@@ -327,6 +346,12 @@ func (ste *sessionedTableEditor) tupleforTags(nbf *types.NomsBinFormat, original
 }
 
 func (ste *sessionedTableEditor) updateRow(ctx context.Context, dOldRow row.Row, dNewRow row.Row, checkReferences bool) error {
+	if ste.validateRow != nil {
+		if err := ste.validateRow(ctx, dNewRow); err != nil {
+			return err
+		}
+	}
+
 	if checkReferences {
 		err := ste.validateForInsert(ctx, dNewRow)
 		if err != nil {
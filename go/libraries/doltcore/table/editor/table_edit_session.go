@@ -38,6 +38,10 @@ type TableEditSession struct {
 // TableEditSessionProps are properties that define different functionality for the TableEditSession.
 type TableEditSessionProps struct {
 	ForeignKeyChecksDisabled bool // If true, then ALL foreign key checks AND updates (through CASCADE, etc.) are skipped
+	// NewRowValidator returns the function that validates the rows written to a table with the given schema, such as
+	// against its check constraints. A nil validator is returned if the rows need no validation. If NewRowValidator is
+	// nil, then rows are not validated.
+	NewRowValidator func(ctx context.Context, sch schema.Schema) (func(ctx context.Context, r row.Row) error, error)
 }
 
 // CreateTableEditSession creates and returns a TableEditSession. Inserting a nil root is not an error, as there are
@@ -243,6 +247,10 @@ func (tes *TableEditSession) getTableEditor(ctx context.Context, tableName strin
 		return nil, err
 	}
 	localTableEditor.tableEditor = tableEditor
+	localTableEditor.validateRow, err = tes.newRowValidator(ctx, tableSch)
+	if err != nil {
+		return nil, err
+	}
 	if tes.Props.ForeignKeyChecksDisabled {
 		return localTableEditor, nil
 	}
@@ -311,10 +319,15 @@ func (tes *TableEditSession) setRoot(ctx context.Context, root *doltdb.RootValue
 		if err != nil {
 			return err
 		}
+		validateRow, err := tes.newRowValidator(ctx, tSch)
+		if err != nil {
+			return err
+		}
 		if err := localTableEditor.tableEditor.Close(); err != nil {
 			return err
 		}
 		localTableEditor.tableEditor = newTableEditor
+		localTableEditor.validateRow = validateRow
 		localTableEditor.referencedTables, localTableEditor.referencingTables = fkCollection.KeysForTable(tableName)
 		err = tes.loadForeignKeys(ctx, localTableEditor)
 		if err != nil {
@@ -323,3 +336,12 @@ func (tes *TableEditSession) setRoot(ctx context.Context, root *doltdb.RootValue
 	}
 	return nil
 }
+
+// newRowValidator returns the validator of the rows written to a table with the given schema, or nil if they need no
+// validation.
+func (tes *TableEditSession) newRowValidator(ctx context.Context, sch schema.Schema) (func(ctx context.Context, r row.Row) error, error) {
+	if tes.Props.NewRowValidator == nil {
+		return nil, nil
+	}
+	return tes.Props.NewRowValidator(ctx, sch)
+}
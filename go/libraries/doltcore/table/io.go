@@ -24,6 +24,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/row"
 	"github.com/dolthub/dolt/go/libraries/doltcore/rowconv"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/typed/noms"
 	"github.com/dolthub/dolt/go/store/types"
 )
@@ -187,3 +188,38 @@ func ForeignKeyIsSatisfied(ctx context.Context, fk doltdb.ForeignKey, childIdx,
 
 	return nil
 }
+
+// CheckConstraintViolations returns a description of each violation of the enforced check constraints of |sch| by the
+// rows in |rowData|.
+func CheckConstraintViolations(ctx context.Context, tblName string, sch schema.Schema, rowData types.Map) ([]string, error) {
+	ce, err := sqlutil.NewCheckEvaluator(ctx, sch)
+	if err != nil || ce == nil {
+		return nil, err
+	}
+
+	var violations []string
+	err = rowData.Iter(ctx, func(key, value types.Value) (stop bool, err error) {
+		r, err := row.FromNoms(sch, key.(types.Tuple), value.(types.Tuple))
+		if err != nil {
+			return true, err
+		}
+
+		violated, err := ce.ViolatedChecks(ctx, r)
+		if err != nil {
+			return true, err
+		}
+
+		for _, name := range violated {
+			keyStr, _ := types.EncodedValue(ctx, key)
+			violations = append(violations, fmt.Sprintf("check constraint violation on `%s`.`%s`: `%s`", name, tblName, keyStr))
+		}
+
+		return false, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return violations, nil
+}
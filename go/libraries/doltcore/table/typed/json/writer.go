@@ -100,6 +100,11 @@ func (jsonw *JSONWriter) WriteRow(ctx context.Context, r row.Row) error {
 			}
 			val = types.String(*v)
 
+		case typeinfo.JSONTypeIdentifier:
+			// embed the document rather than a string containing it
+			colValMap[col.Name] = json.RawMessage(val.(types.String))
+			return false, nil
+
 		case typeinfo.BitTypeIdentifier,
 			typeinfo.BoolTypeIdentifier,
 			typeinfo.VarStringTypeIdentifier,
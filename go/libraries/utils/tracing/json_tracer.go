@@ -0,0 +1,315 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// SpanRecord is the JSON representation of a finished span written by a JSONTracer
+type SpanRecord struct {
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Operation  string                 `json:"operation"`
+	Start      time.Time              `json:"start"`
+	DurationMs float64                `json:"duration_ms"`
+	Tags       map[string]interface{} `json:"tags,omitempty"`
+	Logs       []SpanLogRecord        `json:"logs,omitempty"`
+}
+
+// SpanLogRecord is the JSON representation of the fields logged to a span
+type SpanLogRecord struct {
+	Time   time.Time              `json:"time"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// JSONTracer is an opentracing.Tracer which writes each span to an io.Writer as a line of JSON when it is finished.
+// Spans which are started without a parent begin a new trace.
+type JSONTracer struct {
+	mu  *sync.Mutex
+	enc *json.Encoder
+	rnd *rand.Rand
+}
+
+var _ opentracing.Tracer = (*JSONTracer)(nil)
+
+// NewJSONTracer returns a JSONTracer which writes finished spans to |wr|.
+func NewJSONTracer(wr io.Writer) *JSONTracer {
+	return &JSONTracer{
+		mu:  &sync.Mutex{},
+		enc: json.NewEncoder(wr),
+		rnd: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (t *JSONTracer) newID() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rnd.Uint64()
+}
+
+func (t *JSONTracer) write(rec SpanRecord) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_ = t.enc.Encode(rec)
+}
+
+// StartSpan implements opentracing.Tracer
+func (t *JSONTracer) StartSpan(operationName string, opts ...opentracing.StartSpanOption) opentracing.Span {
+	var sso opentracing.StartSpanOptions
+	for _, opt := range opts {
+		opt.Apply(&sso)
+	}
+
+	start := sso.StartTime
+	if start.IsZero() {
+		start = time.Now()
+	}
+
+	span := &jsonSpan{
+		tracer:    t,
+		mu:        &sync.Mutex{},
+		operation: operationName,
+		start:     start,
+		tags:      make(map[string]interface{}),
+	}
+
+	for _, ref := range sso.References {
+		if parent, ok := ref.ReferencedContext.(jsonSpanContext); ok {
+			span.ctx.traceID = parent.traceID
+			span.parentID = parent.spanID
+			span.ctx.baggage = copyBaggage(parent.baggage)
+			break
+		}
+	}
+
+	span.ctx.spanID = t.newID()
+	if span.ctx.traceID == 0 {
+		span.ctx.traceID = span.ctx.spanID
+	}
+
+	for k, v := range sso.Tags {
+		span.tags[k] = v
+	}
+
+	return span
+}
+
+// Inject implements opentracing.Tracer. Span contexts cannot be propagated across processes.
+func (t *JSONTracer) Inject(sm opentracing.SpanContext, format interface{}, carrier interface{}) error {
+	return opentracing.ErrUnsupportedFormat
+}
+
+// Extract implements opentracing.Tracer. Span contexts cannot be propagated across processes.
+func (t *JSONTracer) Extract(format interface{}, carrier interface{}) (opentracing.SpanContext, error) {
+	return nil, opentracing.ErrUnsupportedFormat
+}
+
+type jsonSpanContext struct {
+	traceID uint64
+	spanID  uint64
+	baggage map[string]string
+}
+
+// ForeachBaggageItem implements opentracing.SpanContext
+func (c jsonSpanContext) ForeachBaggageItem(handler func(k, v string) bool) {
+	for k, v := range c.baggage {
+		if !handler(k, v) {
+			return
+		}
+	}
+}
+
+func copyBaggage(baggage map[string]string) map[string]string {
+	if len(baggage) == 0 {
+		return nil
+	}
+
+	cp := make(map[string]string, len(baggage))
+	for k, v := range baggage {
+		cp[k] = v
+	}
+	return cp
+}
+
+type jsonSpan struct {
+	tracer    *JSONTracer
+	mu        *sync.Mutex
+	ctx       jsonSpanContext
+	parentID  uint64
+	operation string
+	start     time.Time
+	tags      map[string]interface{}
+	logs      []SpanLogRecord
+	finished  bool
+}
+
+// Finish implements opentracing.Span
+func (s *jsonSpan) Finish() {
+	s.FinishWithOptions(opentracing.FinishOptions{})
+}
+
+// FinishWithOptions implements opentracing.Span. Only the first call writes the span.
+func (s *jsonSpan) FinishWithOptions(opts opentracing.FinishOptions) {
+	finish := opts.FinishTime
+	if finish.IsZero() {
+		finish = time.Now()
+	}
+
+	for _, lr := range opts.LogRecords {
+		s.LogFields(lr.Fields...)
+	}
+
+	s.mu.Lock()
+	if s.finished {
+		s.mu.Unlock()
+		return
+	}
+	s.finished = true
+
+	rec := SpanRecord{
+		TraceID:    formatID(s.ctx.traceID),
+		SpanID:     formatID(s.ctx.spanID),
+		Operation:  s.operation,
+		Start:      s.start,
+		DurationMs: float64(finish.Sub(s.start)) / float64(time.Millisecond),
+		Logs:       s.logs,
+	}
+	if s.parentID != 0 {
+		rec.ParentID = formatID(s.parentID)
+	}
+	if len(s.tags) > 0 {
+		rec.Tags = make(map[string]interface{}, len(s.tags))
+		for k, v := range s.tags {
+			rec.Tags[k] = jsonValue(v)
+		}
+	}
+	s.mu.Unlock()
+
+	s.tracer.write(rec)
+}
+
+func formatID(id uint64) string {
+	return fmt.Sprintf("%016x", id)
+}
+
+// jsonValue returns |v| if it can be marshalled to JSON, or its string representation otherwise
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, bool, string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		if _, err := json.Marshal(v); err != nil {
+			return fmt.Sprint(v)
+		}
+		return v
+	}
+}
+
+// Context implements opentracing.Span
+func (s *jsonSpan) Context() opentracing.SpanContext {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return jsonSpanContext{traceID: s.ctx.traceID, spanID: s.ctx.spanID, baggage: copyBaggage(s.ctx.baggage)}
+}
+
+// SetOperationName implements opentracing.Span
+func (s *jsonSpan) SetOperationName(operationName string) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.operation = operationName
+	return s
+}
+
+// SetTag implements opentracing.Span
+func (s *jsonSpan) SetTag(key string, value interface{}) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tags[key] = value
+	return s
+}
+
+// LogFields implements opentracing.Span
+func (s *jsonSpan) LogFields(fields ...log.Field) {
+	rec := SpanLogRecord{Time: time.Now(), Fields: make(map[string]interface{}, len(fields))}
+	for _, f := range fields {
+		rec.Fields[f.Key()] = jsonValue(f.Value())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, rec)
+}
+
+// LogKV implements opentracing.Span
+func (s *jsonSpan) LogKV(alternatingKeyValues ...interface{}) {
+	fields, err := log.InterleavedKVToFields(alternatingKeyValues...)
+	if err != nil {
+		s.LogFields(log.Error(err), log.String("function", "LogKV"))
+		return
+	}
+	s.LogFields(fields...)
+}
+
+// SetBaggageItem implements opentracing.Span
+func (s *jsonSpan) SetBaggageItem(restrictedKey, value string) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx.baggage == nil {
+		s.ctx.baggage = make(map[string]string)
+	}
+	s.ctx.baggage[restrictedKey] = value
+	return s
+}
+
+// BaggageItem implements opentracing.Span
+func (s *jsonSpan) BaggageItem(restrictedKey string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ctx.baggage[restrictedKey]
+}
+
+// Tracer implements opentracing.Span
+func (s *jsonSpan) Tracer() opentracing.Tracer {
+	return s.tracer
+}
+
+// LogEvent implements opentracing.Span
+func (s *jsonSpan) LogEvent(event string) {
+	s.LogFields(log.String("event", event))
+}
+
+// LogEventWithPayload implements opentracing.Span
+func (s *jsonSpan) LogEventWithPayload(event string, payload interface{}) {
+	s.LogFields(log.String("event", event), log.Object("payload", payload))
+}
+
+// Log implements opentracing.Span
+func (s *jsonSpan) Log(data opentracing.LogData) {
+	s.LogFields(data.ToLogRecord().Fields...)
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readSpanRecords(t *testing.T, buf *bytes.Buffer) []SpanRecord {
+	var recs []SpanRecord
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var rec SpanRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &rec))
+		recs = append(recs, rec)
+	}
+	require.NoError(t, scanner.Err())
+	return recs
+}
+
+func TestJSONTracer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tracer := NewJSONTracer(buf)
+
+	root := tracer.StartSpan("query", opentracing.Tag{Key: "query", Value: "select 1"})
+	child := tracer.StartSpan("analyze", opentracing.ChildOf(root.Context()))
+	child.LogKV("rule", "resolve_tables")
+	child.Finish()
+	child.Finish()
+	assert.Len(t, readSpanRecords(t, bytes.NewBuffer(buf.Bytes())), 1)
+
+	unrelated := tracer.StartSpan("plan.Project")
+	unrelated.Finish()
+	root.SetTag("rows", 1)
+	root.Finish()
+
+	recs := readSpanRecords(t, buf)
+	require.Len(t, recs, 3)
+
+	analyze, project, query := recs[0], recs[1], recs[2]
+	assert.Equal(t, "analyze", analyze.Operation)
+	assert.Equal(t, "query", query.Operation)
+	assert.Equal(t, "plan.Project", project.Operation)
+
+	assert.Equal(t, query.TraceID, analyze.TraceID)
+	assert.Equal(t, query.SpanID, analyze.ParentID)
+	assert.Empty(t, query.ParentID)
+	assert.NotEqual(t, query.TraceID, project.TraceID)
+
+	assert.Equal(t, "select 1", query.Tags["query"])
+	assert.Equal(t, float64(1), query.Tags["rows"])
+	require.Len(t, analyze.Logs, 1)
+	assert.Equal(t, "resolve_tables", analyze.Logs[0].Fields["rule"])
+	assert.True(t, query.DurationMs >= analyze.DurationMs)
+}
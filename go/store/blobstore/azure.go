@@ -0,0 +1,349 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	azureAPIVersion = "2019-12-12"
+
+	// the number of times a read of a range from the end of a blob is retried when the blob changes between reading
+	// its size and reading the range
+	azureSuffixRangeRetries = 3
+)
+
+// AzureCredentials are the credentials of an Azure storage account. Requests are signed with AccountKey if it is set,
+// otherwise SASToken is added to their urls.
+type AzureCredentials struct {
+	AccountName string
+	AccountKey  string
+	SASToken    string
+}
+
+// AzureBlobstore provides an implementation of the Blobstore interface for Azure Blob Storage, using the REST API of
+// the Blob service. The version of a blob is its ETag.
+type AzureBlobstore struct {
+	client    *http.Client
+	endpoint  *url.URL
+	container string
+	prefix    string
+	account   string
+	key       []byte
+	sasQuery  url.Values
+}
+
+// NewAzureBlobstore creates a new instance of an AzureBlobstore storing blobs under |prefix| in |container|. |endpoint|
+// is the url of the Blob service of the account, which is https://<account>.blob.core.windows.net if it is empty.
+func NewAzureBlobstore(client *http.Client, endpoint, container, prefix string, creds AzureCredentials) (*AzureBlobstore, error) {
+	if creds.AccountName == "" {
+		return nil, errors.New("an azure storage account name is required")
+	}
+
+	if endpoint == "" {
+		endpoint = "https://" + creds.AccountName + ".blob.core.windows.net"
+	}
+
+	endpointURL, err := url.Parse(strings.TrimRight(endpoint, "/"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	bs := &AzureBlobstore{
+		client:    client,
+		endpoint:  endpointURL,
+		container: container,
+		prefix:    strings.Trim(prefix, "/"),
+		account:   creds.AccountName,
+	}
+
+	switch {
+	case creds.AccountKey != "":
+		bs.key, err = base64.StdEncoding.DecodeString(creds.AccountKey)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid azure storage account key: %w", err)
+		}
+	case creds.SASToken != "":
+		bs.sasQuery, err = url.ParseQuery(strings.TrimPrefix(creds.SASToken, "?"))
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid azure sas token: %w", err)
+		}
+	}
+
+	return bs, nil
+}
+
+// AzureError is returned when a request to the Blob service fails
+type AzureError struct {
+	StatusCode int
+	Code       string
+}
+
+// Error returns the status and error code of the failed request
+func (err AzureError) Error() string {
+	return fmt.Sprintf("azure blob request failed with status %d: %s", err.StatusCode, err.Code)
+}
+
+func (bs *AzureBlobstore) absKey(key string) string {
+	return path.Join(bs.prefix, key)
+}
+
+func (bs *AzureBlobstore) blobURL(key string) string {
+	u := *bs.endpoint
+	u.Path = u.Path + "/" + bs.container + "/" + bs.absKey(key)
+	u.RawQuery = bs.sasQuery.Encode()
+	return u.String()
+}
+
+// Exists returns true if a blob exists for the given key, and false if it does not.
+func (bs *AzureBlobstore) Exists(ctx context.Context, key string) (bool, error) {
+	resp, err := bs.do(ctx, http.MethodHead, key, nil, nil)
+
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+
+	return false, azureErrorFromResponse(resp)
+}
+
+// Get retrieves an io.reader for the portion of a blob specified by br along with
+// its version
+func (bs *AzureBlobstore) Get(ctx context.Context, key string, br BlobRange) (io.ReadCloser, string, error) {
+	if br.offset >= 0 {
+		return bs.get(ctx, key, br, "")
+	}
+
+	// the Blob service doesn't support ranges from the end of a blob, so they are read from the blob's size, as long as
+	// the blob doesn't change in between.
+	for i := 0; ; i++ {
+		resp, err := bs.do(ctx, http.MethodHead, key, nil, nil)
+
+		if err != nil {
+			return nil, "", err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, "", bs.getError(key, resp)
+		}
+
+		size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		rc, ver, err := bs.get(ctx, key, br.positiveRange(size), resp.Header.Get("ETag"))
+
+		var azErr AzureError
+		if errors.As(err, &azErr) && azErr.StatusCode == http.StatusPreconditionFailed && i < azureSuffixRangeRetries {
+			continue
+		}
+
+		return rc, ver, err
+	}
+}
+
+func (bs *AzureBlobstore) get(ctx context.Context, key string, br BlobRange, ifMatch string) (io.ReadCloser, string, error) {
+	hdrs := http.Header{}
+	if !br.isAllRange() {
+		if br.length == 0 {
+			hdrs.Set("x-ms-range", fmt.Sprintf("bytes=%d-", br.offset))
+		} else {
+			hdrs.Set("x-ms-range", fmt.Sprintf("bytes=%d-%d", br.offset, br.offset+br.length-1))
+		}
+	}
+
+	if ifMatch != "" {
+		hdrs.Set("If-Match", ifMatch)
+	}
+
+	resp, err := bs.do(ctx, http.MethodGet, key, hdrs, nil)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return nil, "", bs.getError(key, resp)
+	}
+
+	return resp.Body, resp.Header.Get("ETag"), nil
+}
+
+func (bs *AzureBlobstore) getError(key string, resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return NotFound{"az://" + path.Join(bs.account, bs.container, bs.absKey(key))}
+	}
+
+	return azureErrorFromResponse(resp)
+}
+
+// Put sets the blob and the version for a key
+func (bs *AzureBlobstore) Put(ctx context.Context, key string, reader io.Reader) (string, error) {
+	return bs.put(ctx, key, reader, http.Header{})
+}
+
+// CheckAndPut will check the current version of a blob against an expectedVersion, and if the
+// versions match it will update the data and version associated with the key
+func (bs *AzureBlobstore) CheckAndPut(ctx context.Context, expectedVersion, key string, reader io.Reader) (string, error) {
+	hdrs := http.Header{}
+	if expectedVersion == "" {
+		hdrs.Set("If-None-Match", "*")
+	} else {
+		hdrs.Set("If-Match", expectedVersion)
+	}
+
+	ver, err := bs.put(ctx, key, reader, hdrs)
+
+	var azErr AzureError
+	if errors.As(err, &azErr) && isAzureConditionNotMet(azErr.StatusCode) {
+		return "", CheckAndPutError{key, expectedVersion, "unknown (Not supported in Azure implementation)"}
+	}
+
+	return ver, err
+}
+
+func (bs *AzureBlobstore) put(ctx context.Context, key string, reader io.Reader, hdrs http.Header) (string, error) {
+	data, err := ioutil.ReadAll(reader)
+
+	if err != nil {
+		return "", err
+	}
+
+	hdrs.Set("x-ms-blob-type", "BlockBlob")
+	resp, err := bs.do(ctx, http.MethodPut, key, hdrs, data)
+
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", azureErrorFromResponse(resp)
+	}
+
+	return resp.Header.Get("ETag"), nil
+}
+
+func (bs *AzureBlobstore) do(ctx context.Context, method, key string, hdrs http.Header, body []byte) (*http.Response, error) {
+	var bodyRdr io.Reader
+	if body != nil {
+		bodyRdr = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, bs.blobURL(key), bodyRdr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for k, vals := range hdrs {
+		req.Header[k] = vals
+	}
+
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureAPIVersion)
+
+	if bs.key != nil {
+		req.Header.Set("Authorization", "SharedKey "+bs.account+":"+azureSharedKeySignature(req, bs.account, bs.key))
+	}
+
+	return bs.client.Do(req.WithContext(ctx))
+}
+
+// azureSharedKeySignature returns the Shared Key signature of |req|, as described at
+// https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func azureSharedKeySignature(req *http.Request, account string, key []byte) string {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var msHeaders []string
+	for k := range req.Header {
+		if lk := strings.ToLower(k); strings.HasPrefix(lk, "x-ms-") {
+			msHeaders = append(msHeaders, lk+":"+strings.TrimSpace(req.Header.Get(k)))
+		}
+	}
+	sort.Strings(msHeaders)
+
+	resource := "/" + account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	params := make([]string, 0, len(query))
+	for k, vals := range query {
+		sort.Strings(vals)
+		params = append(params, strings.ToLower(k)+":"+strings.Join(vals, ","))
+	}
+	sort.Strings(params)
+
+	strToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		req.Header.Get("Date"),
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}, "\n") + "\n" + strings.Join(msHeaders, "\n") + "\n" + strings.Join(append([]string{resource}, params...), "\n")
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// isAzureConditionNotMet returns true if a conditional write failed because the blob didn't have the expected version.
+// The write fails with 409 rather than 412 if the blob already exists when it was expected not to, and with 404 if it
+// doesn't exist when it was expected to.
+func isAzureConditionNotMet(status int) bool {
+	return status == http.StatusPreconditionFailed || status == http.StatusConflict || status == http.StatusNotFound
+}
+
+func azureErrorFromResponse(resp *http.Response) error {
+	return AzureError{resp.StatusCode, resp.Header.Get("x-ms-error-code")}
+}
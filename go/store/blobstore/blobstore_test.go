@@ -17,6 +17,7 @@ package blobstore
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/binary"
 	"fmt"
 	"io/ioutil"
@@ -28,6 +29,10 @@ import (
 	"testing"
 
 	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/google/uuid"
 )
 
@@ -82,10 +87,37 @@ func appendLocalTest(tests []BlobstoreTest) []BlobstoreTest {
 	return append(tests, BlobstoreTest{"local", NewLocalBlobstore(dir), 10, 20})
 }
 
+func appendS3Test(tests []BlobstoreTest) []BlobstoreTest {
+	srv := newFakeS3Server()
+	sess := session.Must(session.NewSession(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials("access-key", "secret-key", ""),
+		Endpoint:         aws.String(srv.URL),
+		Region:           aws.String("us-east-1"),
+		S3ForcePathStyle: aws.Bool(true),
+	}))
+
+	return append(tests, BlobstoreTest{"s3", NewS3Blobstore(s3.New(sess), "bucket", uuid.New().String()), 10, 20})
+}
+
+func appendAzureTest(tests []BlobstoreTest) []BlobstoreTest {
+	accountKey := randBytes(64)
+	srv := newFakeAzureServer("account", accountKey)
+	creds := AzureCredentials{AccountName: "account", AccountKey: base64.StdEncoding.EncodeToString(accountKey)}
+	bs, err := NewAzureBlobstore(srv.Client(), srv.URL+"/account", "container", uuid.New().String(), creds)
+
+	if err != nil {
+		panic("Could not create AzureBlobstore")
+	}
+
+	return append(tests, BlobstoreTest{"azure", bs, 10, 20})
+}
+
 func newBlobStoreTests() []BlobstoreTest {
 	var tests []BlobstoreTest
 	tests = append(tests, BlobstoreTest{"inmem", NewInMemoryBlobstore(), 10, 20})
 	tests = appendLocalTest(tests)
+	tests = appendS3Test(tests)
+	tests = appendAzureTest(tests)
 	tests = appendGCSTest(tests)
 
 	return tests
@@ -365,6 +397,9 @@ func TestGetRange(t *testing.T) {
 
 			// test the range beginning 2048 bytes from the end of size 512 which will be shorts 1024 from the end til 768 from the end
 			testGetRange(t, bsTest.bs, NewBlobRange(-2*1024, 512), rangeData(maxValue-1024, maxValue-768))
+
+			// test a range from the end which is longer than the blob, which will be the whole blob
+			testGetRange(t, bsTest.bs, NewBlobRange(-4*maxValue, 0), rangeData(0, maxValue))
 		})
 	}
 }
@@ -0,0 +1,286 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blobstore
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// fakeObjectStore holds the objects of the fake S3 and Azure servers, with versions like those of both services, and
+// implements their conditional writes and range reads.
+type fakeObjectStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	etags   map[string]string
+	gen     int
+}
+
+func newFakeObjectStore() *fakeObjectStore {
+	return &fakeObjectStore{objects: make(map[string][]byte), etags: make(map[string]string)}
+}
+
+func (fos *fakeObjectStore) get(key string) ([]byte, string, bool) {
+	fos.mu.Lock()
+	defer fos.mu.Unlock()
+
+	data, ok := fos.objects[key]
+	return data, fos.etags[key], ok
+}
+
+// put writes |data| to |key| if the conditions of the If-Match and If-None-Match headers of |r| hold. It returns the
+// status of the write.
+func (fos *fakeObjectStore) put(key string, data []byte, r *http.Request) (string, int) {
+	fos.mu.Lock()
+	defer fos.mu.Unlock()
+
+	etag, exists := fos.etags[key]
+
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if !exists {
+			return "", http.StatusNotFound
+		} else if ifMatch != etag {
+			return "", http.StatusPreconditionFailed
+		}
+	}
+
+	if r.Header.Get("If-None-Match") == "*" && exists {
+		return "", http.StatusPreconditionFailed
+	}
+
+	fos.gen++
+	sum := md5.Sum(data)
+	etag = fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]), fos.gen)
+	fos.objects[key] = data
+	fos.etags[key] = etag
+
+	return etag, http.StatusOK
+}
+
+// parseRangeHeader returns the range of an object of size |size| read by a range header, which can be a range from the
+// end of the object
+func parseRangeHeader(hdr string, size int) (start, end int, ok bool) {
+	if !strings.HasPrefix(hdr, "bytes=") {
+		return 0, 0, false
+	}
+
+	tokens := strings.SplitN(hdr[len("bytes="):], "-", 2)
+	if len(tokens) != 2 {
+		return 0, 0, false
+	}
+
+	if tokens[0] == "" {
+		n, err := strconv.Atoi(tokens[1])
+		if err != nil {
+			return 0, 0, false
+		}
+
+		start = size - n
+		if start < 0 {
+			start = 0
+		}
+
+		return start, size, true
+	}
+
+	start, err := strconv.Atoi(tokens[0])
+	if err != nil || start > size {
+		return 0, 0, false
+	}
+
+	end = size
+	if tokens[1] != "" {
+		last, err := strconv.Atoi(tokens[1])
+		if err != nil || last < start {
+			return 0, 0, false
+		}
+
+		if last+1 < end {
+			end = last + 1
+		}
+	}
+
+	return start, end, true
+}
+
+// newFakeS3Server returns a server implementing the object operations of the S3 REST API used by S3Blobstore, with
+// path style urls
+func newFakeS3Server() *httptest.Server {
+	fos := newFakeObjectStore()
+	writeErr := func(w http.ResponseWriter, status int, code string) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+			writeErr(w, http.StatusForbidden, "AccessDenied")
+			return
+		}
+
+		key := r.URL.Path
+		switch r.Method {
+		case http.MethodPut:
+			data, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				writeErr(w, http.StatusBadRequest, "IncompleteBody")
+				return
+			}
+
+			etag, status := fos.put(key, data, r)
+			switch status {
+			case http.StatusOK:
+				w.Header().Set("ETag", etag)
+			case http.StatusNotFound:
+				writeErr(w, status, "NoSuchKey")
+			default:
+				writeErr(w, status, "PreconditionFailed")
+			}
+
+		case http.MethodGet, http.MethodHead:
+			data, etag, ok := fos.get(key)
+			if !ok {
+				if r.Method == http.MethodHead {
+					w.WriteHeader(http.StatusNotFound)
+				} else {
+					writeErr(w, http.StatusNotFound, "NoSuchKey")
+				}
+				return
+			}
+
+			w.Header().Set("ETag", etag)
+			status := http.StatusOK
+			if rng := r.Header.Get("Range"); rng != "" {
+				start, end, ok := parseRangeHeader(rng, len(data))
+				if !ok {
+					writeErr(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+					return
+				}
+
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, len(data)))
+				data = data[start:end]
+				status = http.StatusPartialContent
+			}
+
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			w.WriteHeader(status)
+			if r.Method == http.MethodGet {
+				_, _ = w.Write(data)
+			}
+
+		default:
+			writeErr(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+		}
+	}))
+}
+
+// newFakeAzureServer returns a server implementing the blob operations of the Azure Blob service REST API used by
+// AzureBlobstore, which checks the Shared Key signatures of requests made for |account| with |key|. Like the Azure
+// storage emulator, the account is the first element of the path of the urls of the server.
+func newFakeAzureServer(account string, key []byte) *httptest.Server {
+	fos := newFakeObjectStore()
+	writeErr := func(w http.ResponseWriter, status int, code string) {
+		w.Header().Set("x-ms-error-code", code)
+		w.WriteHeader(status)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-ms-version") == "" || r.Header.Get("x-ms-date") == "" {
+			writeErr(w, http.StatusBadRequest, "MissingRequiredHeader")
+			return
+		}
+
+		if r.Header.Get("Authorization") != "SharedKey "+account+":"+azureSharedKeySignature(r, account, key) {
+			writeErr(w, http.StatusForbidden, "AuthenticationFailed")
+			return
+		}
+
+		if !strings.HasPrefix(r.URL.Path, "/"+account+"/") {
+			writeErr(w, http.StatusNotFound, "ResourceNotFound")
+			return
+		}
+
+		key := r.URL.Path
+		switch r.Method {
+		case http.MethodPut:
+			if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+				writeErr(w, http.StatusBadRequest, "MissingRequiredHeader")
+				return
+			}
+
+			data, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				writeErr(w, http.StatusBadRequest, "InvalidInput")
+				return
+			}
+
+			etag, status := fos.put(key, data, r)
+			switch {
+			case status == http.StatusOK:
+				w.Header().Set("ETag", etag)
+				w.WriteHeader(http.StatusCreated)
+			case status == http.StatusNotFound:
+				writeErr(w, status, "BlobNotFound")
+			case r.Header.Get("If-None-Match") == "*":
+				writeErr(w, http.StatusConflict, "BlobAlreadyExists")
+			default:
+				writeErr(w, status, "ConditionNotMet")
+			}
+
+		case http.MethodGet, http.MethodHead:
+			data, etag, ok := fos.get(key)
+			if !ok {
+				writeErr(w, http.StatusNotFound, "BlobNotFound")
+				return
+			}
+
+			if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != etag {
+				writeErr(w, http.StatusPreconditionFailed, "ConditionNotMet")
+				return
+			}
+
+			w.Header().Set("ETag", etag)
+			status := http.StatusOK
+			if rng := r.Header.Get("x-ms-range"); rng != "" {
+				start, end, ok := parseRangeHeader(rng, len(data))
+				if !ok || strings.HasPrefix(rng, "bytes=-") {
+					writeErr(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+					return
+				}
+
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, len(data)))
+				data = data[start:end]
+				status = http.StatusPartialContent
+			}
+
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			w.WriteHeader(status)
+			if r.Method == http.MethodGet {
+				_, _ = w.Write(data)
+			}
+
+		default:
+			writeErr(w, http.StatusMethodNotAllowed, "UnsupportedHttpVerb")
+		}
+	}))
+}
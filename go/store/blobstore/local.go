@@ -116,7 +116,8 @@ func readCloserForFileRange(f *os.File, pos int, br BlobRange) (io.ReadCloser, e
 			return nil, err
 		}
 		seekType = 0
-		br = br.positiveRange(info.Size())
+		br = br.positiveRange(info.Size() - int64(pos))
+		br.offset += int64(pos)
 	}
 
 	_, err := f.Seek(br.offset, seekType)
@@ -46,6 +46,11 @@ func (br BlobRange) positiveRange(size int64) BlobRange {
 
 	if offset < 0 {
 		offset = size + offset
+
+		// like http suffix ranges, a range from the end of a blob which is longer than the blob covers the whole blob
+		if offset < 0 {
+			offset = 0
+		}
 	}
 
 	if offset+length > size || length == 0 {
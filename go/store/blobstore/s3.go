@@ -0,0 +1,187 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// S3API is the subset of the S3 client used by S3Blobstore. It is implemented by *s3.S3.
+type S3API interface {
+	GetObjectWithContext(ctx aws.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error)
+	PutObjectWithContext(ctx aws.Context, input *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error)
+	HeadObjectWithContext(ctx aws.Context, input *s3.HeadObjectInput, opts ...request.Option) (*s3.HeadObjectOutput, error)
+}
+
+// S3Blobstore provides an implementation of the Blobstore interface for S3 and S3 compatible object stores. The
+// version of a blob is its ETag, and CheckAndPut relies on the store supporting conditional writes with the If-Match
+// and If-None-Match headers, which S3, MinIO and Ceph do.
+type S3Blobstore struct {
+	s3     S3API
+	bucket string
+	prefix string
+}
+
+// NewS3Blobstore creates a new instance of an S3Blobstore storing blobs under |prefix| in |bucket|
+func NewS3Blobstore(s3 S3API, bucket, prefix string) *S3Blobstore {
+	for len(prefix) > 0 && prefix[0] == '/' {
+		prefix = prefix[1:]
+	}
+
+	return &S3Blobstore{s3, bucket, prefix}
+}
+
+func (bs *S3Blobstore) absKey(key string) string {
+	return path.Join(bs.prefix, key)
+}
+
+// Exists returns true if a blob exists for the given key, and false if it does not.
+func (bs *S3Blobstore) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := bs.s3.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bs.bucket),
+		Key:    aws.String(bs.absKey(key)),
+	})
+
+	if isS3NotFound(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Get retrieves an io.reader for the portion of a blob specified by br along with
+// its version
+func (bs *S3Blobstore) Get(ctx context.Context, key string, br BlobRange) (io.ReadCloser, string, error) {
+	absKey := bs.absKey(key)
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bs.bucket),
+		Key:    aws.String(absKey),
+	}
+
+	if !br.isAllRange() {
+		input.Range = aws.String(s3RangeHeader(br))
+	}
+
+	result, err := bs.s3.GetObjectWithContext(ctx, input)
+
+	if isS3NotFound(err) {
+		return nil, "", NotFound{"s3://" + path.Join(bs.bucket, absKey)}
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	rc := result.Body
+	if br.offset < 0 && br.length > 0 {
+		// a range from the end of a blob can't have a length in a range header
+		rc = limitedReadCloser{io.LimitReader(rc, br.length), rc}
+	}
+
+	return rc, aws.StringValue(result.ETag), nil
+}
+
+func s3RangeHeader(br BlobRange) string {
+	if br.offset < 0 {
+		return "bytes=" + strconv.FormatInt(br.offset, 10)
+	}
+
+	if br.length == 0 {
+		return "bytes=" + strconv.FormatInt(br.offset, 10) + "-"
+	}
+
+	return "bytes=" + strconv.FormatInt(br.offset, 10) + "-" + strconv.FormatInt(br.offset+br.length-1, 10)
+}
+
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Put sets the blob and the version for a key
+func (bs *S3Blobstore) Put(ctx context.Context, key string, reader io.Reader) (string, error) {
+	return bs.put(ctx, key, reader)
+}
+
+// CheckAndPut will check the current version of a blob against an expectedVersion, and if the
+// versions match it will update the data and version associated with the key
+func (bs *S3Blobstore) CheckAndPut(ctx context.Context, expectedVersion, key string, reader io.Reader) (string, error) {
+	cond := func(r *request.Request) {
+		if expectedVersion == "" {
+			r.HTTPRequest.Header.Set("If-None-Match", "*")
+		} else {
+			r.HTTPRequest.Header.Set("If-Match", expectedVersion)
+		}
+	}
+
+	ver, err := bs.put(ctx, key, reader, cond)
+
+	if isS3PreconditionFailed(err) {
+		return "", CheckAndPutError{key, expectedVersion, "unknown (Not supported in S3 implementation)"}
+	}
+
+	return ver, err
+}
+
+func (bs *S3Blobstore) put(ctx context.Context, key string, reader io.Reader, opts ...request.Option) (string, error) {
+	// the body of a put must be seekable so that requests can be signed and retried
+	data, err := ioutil.ReadAll(reader)
+
+	if err != nil {
+		return "", err
+	}
+
+	result, err := bs.s3.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bs.bucket),
+		Key:    aws.String(bs.absKey(key)),
+		Body:   bytes.NewReader(data),
+	}, opts...)
+
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(result.ETag), nil
+}
+
+func isS3NotFound(err error) bool {
+	if reqErr, ok := err.(awserr.RequestFailure); ok {
+		return reqErr.StatusCode() == http.StatusNotFound
+	}
+
+	return false
+}
+
+func isS3PreconditionFailed(err error) bool {
+	if reqErr, ok := err.(awserr.RequestFailure); ok {
+		// a write which conflicts with a conditional write in progress fails with 409 rather than 412, and a write
+		// expecting a version of an object which doesn't exist fails with 404
+		switch reqErr.StatusCode() {
+		case http.StatusPreconditionFailed, http.StatusConflict, http.StatusNotFound:
+			return true
+		}
+	}
+
+	return false
+}
@@ -179,6 +179,11 @@ func CanUsePuller(db Database) bool {
 	return false
 }
 
+// ChunkStoreFromDatabase returns the ChunkStore which |db| reads from and writes to.
+func ChunkStoreFromDatabase(db Database) chunks.ChunkStore {
+	return db.chunkStore()
+}
+
 func GetCSStatSummaryForDB(db Database) string {
 	cs := db.chunkStore()
 	return cs.StatsSummary()
@@ -0,0 +1,146 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// ShallowPull pulls the chunks reachable from the commits |commitRefs| from srcDB to sinkDB, without following the
+// parents of commits more than |depth| commits away from them. The commits of |commitRefs| are at a depth of 1. A
+// |depth| of 0 or less pulls every ancestor. Chunks which sinkDB already has are assumed to be complete, and are not
+// walked. The returned set holds the commits which are the parents of pulled commits but were not pulled themselves,
+// the boundary of the shallow history in sinkDB.
+func ShallowPull(ctx context.Context, srcDB, sinkDB Database, commitRefs []types.Ref, depth int, progressCh chan PullProgress) (hash.HashSet, error) {
+	if srcDB.chunkStore().Version() != sinkDB.chunkStore().Version() {
+		return nil, fmt.Errorf("cannot pull from src to sink; src version is %v and sink version is %v", srcDB.chunkStore().Version(), sinkDB.chunkStore().Version())
+	}
+
+	// the depth of each commit which is pulled. The depth of a commit is the length of the shortest path to it from the
+	// commits being pulled.
+	commitDepths := make(map[hash.Hash]int)
+	notPulled := hash.HashSet{}
+	heads := hash.HashSet{}
+	var ordered hash.HashSlice
+	for _, r := range commitRefs {
+		h := r.TargetHash()
+		if !heads.Has(h) {
+			heads.Insert(h)
+			ordered = append(ordered, h)
+		}
+		commitDepths[h] = 1
+	}
+
+	absent, err := nextLevelMissingChunks(ctx, sinkDB, heads, nil, ordered)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var sampleSize, sampleCount uint64
+	updateProgress := makeProgTrack(progressCh)
+
+	nbf := sinkDB.Format()
+	for len(absent) != 0 {
+		updateProgress(0, uint64(len(absent)), 0)
+
+		neededChunks, err := getChunks(ctx, srcDB, absent, sampleSize, sampleCount, updateProgress)
+
+		if err != nil {
+			return nil, err
+		}
+
+		nextLevel := hash.HashSet{}
+		var uniqueOrdered hash.HashSlice
+		for _, h := range absent {
+			c, ok := neededChunks[h]
+
+			if !ok {
+				return nil, errors.New("not found")
+			}
+
+			err = sinkDB.chunkStore().Put(ctx, *c)
+
+			if err != nil {
+				return nil, err
+			}
+
+			chunkDepth, isCommit := commitDepths[h]
+			err = types.WalkRefs(*c, nbf, func(r types.Ref) error {
+				childHash := r.TargetHash()
+
+				if isCommit {
+					childIsCommit, err := isRefOfCommit(nbf, r)
+
+					if err != nil {
+						return err
+					}
+
+					if childIsCommit {
+						if depth > 0 && chunkDepth >= depth {
+							notPulled.Insert(childHash)
+							return nil
+						}
+
+						if prevDepth, ok := commitDepths[childHash]; !ok || chunkDepth+1 < prevDepth {
+							commitDepths[childHash] = chunkDepth + 1
+						}
+					}
+				}
+
+				if !nextLevel.Has(childHash) {
+					uniqueOrdered = append(uniqueOrdered, childHash)
+					nextLevel.Insert(childHash)
+				}
+
+				return nil
+			})
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		absent, err = nextLevelMissingChunks(ctx, sinkDB, nextLevel, absent, uniqueOrdered)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = persistChunks(ctx, sinkDB.chunkStore())
+
+	if err != nil {
+		return nil, err
+	}
+
+	// commits which were too deep on one path may have been pulled through a shorter one
+	return sinkDB.chunkStore().HasMany(ctx, notPulled)
+}
+
+func isRefOfCommit(nbf *types.NomsBinFormat, r types.Ref) (bool, error) {
+	t, err := r.TargetType()
+
+	if err != nil {
+		return false, err
+	}
+
+	return IsCommitType(nbf, t), nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datas
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+func TestShallowPull(t *testing.T) {
+	ctx := context.Background()
+	srcStorage := &chunks.TestStorage{}
+	src := NewDatabase(srcStorage.NewView())
+
+	// commits a list of |i| values on each of 4 commits, so that each commit has chunks of its own
+	ds, err := src.GetDataset(ctx, datasetID)
+	require.NoError(t, err)
+	var commits, values []hash.Hash
+	for i := 1; i <= 4; i++ {
+		vals := make([]types.Value, 0, i)
+		for j := 0; j < i; j++ {
+			vals = append(vals, types.String(string(rune('a'+i))+string(rune('a'+j))))
+		}
+		l, err := types.NewList(ctx, src, vals...)
+		require.NoError(t, err)
+		lRef, err := src.WriteValue(ctx, l)
+		require.NoError(t, err)
+
+		ds, err = src.CommitValue(ctx, ds, lRef)
+		require.NoError(t, err)
+
+		headRef, ok, err := ds.MaybeHeadRef()
+		require.NoError(t, err)
+		require.True(t, ok)
+		commits = append(commits, headRef.TargetHash())
+		values = append(values, lRef.TargetHash())
+	}
+	headRef, _, err := ds.MaybeHeadRef()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		depth     int
+		numPulled int
+	}{
+		{"depth 1", 1, 1},
+		{"depth 2", 2, 2},
+		{"unlimited depth", 0, 4},
+		{"depth beyond history", 10, 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sinkStorage := &chunks.TestStorage{}
+			sinkCS := sinkStorage.NewView()
+			sink := NewDatabase(sinkCS)
+
+			boundary, err := ShallowPull(ctx, src, sink, []types.Ref{headRef}, test.depth, nil)
+			require.NoError(t, err)
+
+			expectedBoundary := hash.HashSet{}
+			if test.numPulled < len(commits) {
+				expectedBoundary.Insert(commits[len(commits)-test.numPulled-1])
+			}
+			assert.Equal(t, expectedBoundary, boundary)
+
+			for i := range commits {
+				pulled := i >= len(commits)-test.numPulled
+				has, err := sinkCS.Has(ctx, commits[i])
+				require.NoError(t, err)
+				assert.Equal(t, pulled, has, "commit %d", i)
+				has, err = sinkCS.Has(ctx, values[i])
+				require.NoError(t, err)
+				assert.Equal(t, pulled, has, "value %d", i)
+			}
+
+			// pulling again is a no-op
+			boundary, err = ShallowPull(ctx, src, sink, []types.Ref{headRef}, test.depth, nil)
+			require.NoError(t, err)
+			assert.Empty(t, boundary)
+		})
+	}
+}
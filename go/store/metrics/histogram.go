@@ -135,6 +135,21 @@ func (h Histogram) Samples() uint64 {
 	return s
 }
 
+// Type returns the type of the values sampled by the histogram
+func (h Histogram) Type() HistogramType {
+	return h.histType
+}
+
+// BucketCounts returns the number of samples in each bucket of the histogram. Bucket i contains the samples in the
+// range [2^i, 2^(i+1)).
+func (h Histogram) BucketCounts() []uint64 {
+	counts := make([]uint64, bucketCount)
+	for i := 0; i < bucketCount; i++ {
+		counts[i] = atomic.LoadUint64(&h.buckets[i])
+	}
+	return counts
+}
+
 func uintToString(v uint64) string {
 	return strconv.FormatUint(v, 10)
 }
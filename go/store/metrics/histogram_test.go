@@ -67,6 +67,23 @@ func TestHistogramBasic(t *testing.T) {
 	assert.Equal(uint64(144), h.Mean())
 }
 
+func TestHistogramBucketCounts(t *testing.T) {
+	assert := assert.New(t)
+
+	h := NewTimeHistogram()
+	h.Sample(1)
+	h.Sample(3)
+	h.Sample(2)
+	h.Sample(300)
+
+	counts := h.BucketCounts()
+	assert.Len(counts, bucketCount)
+	assert.Equal(uint64(1), counts[0])
+	assert.Equal(uint64(2), counts[1])
+	assert.Equal(uint64(1), counts[8])
+	assert.Equal(TimeHistogram, h.Type())
+}
+
 func TestHistogramLarge(t *testing.T) {
 	assert := assert.New(t)
 	h := Histogram{}
@@ -91,7 +91,7 @@ func newAWSChunkSource(ctx context.Context, ddb *ddbTableStore, s3 *s3ObjectRead
 	index, err := parseIndex(indexBytes)
 
 	if err != nil {
-		return emptyChunkSource{}, err
+		return emptyChunkSource{}, tableFileError(name, err)
 	}
 
 	if ohi, ok := index.(onHeapTableIndex); indexCache != nil && ok {
@@ -145,3 +145,42 @@ func (atra *awsTableReaderAt) getTableReaderAt(ctx context.Context, stats *Stats
 
 	return &s3TableReaderAt{s3: atra.s3, h: atra.name}, nil
 }
+
+// newEncryptedAWSChunkSource opens an encrypted table file, which is always stored in S3
+func newEncryptedAWSChunkSource(ctx context.Context, s3 *s3ObjectReader, name addr, chunkCount uint32, tfc *TableFileCipher, stats *Stats, parseIndex indexParserF) (chunkSource, error) {
+	t1 := time.Now()
+	size := indexSize(chunkCount) + footerSize + encryptedTrailerSize
+	buff := make([]byte, size)
+
+	n, _, err := s3.ReadFromEnd(ctx, name, buff, stats)
+
+	if err != nil {
+		return emptyChunkSource{}, err
+	}
+
+	if size != uint64(n) {
+		if n >= magicNumberSize && string(buff[n-magicNumberSize:n]) == magicNumber {
+			return emptyChunkSource{}, tableFileError(name, ErrTableFileNotEncrypted)
+		}
+
+		return emptyChunkSource{}, errors.New("failed to read all data")
+	}
+
+	indexBytes, iv, err := tfc.decryptIndex(buff, chunkCount)
+
+	if err != nil {
+		return emptyChunkSource{}, tableFileError(name, err)
+	}
+
+	stats.IndexBytesPerRead.Sample(uint64(len(indexBytes)))
+	stats.IndexReadLatency.SampleTimeSince(t1)
+
+	index, err := parseIndex(indexBytes)
+
+	if err != nil {
+		return emptyChunkSource{}, err
+	}
+
+	tra := &encryptedTableReaderAt{&s3TableReaderAt{s3: s3, h: name}, tfc, iv}
+	return &chunkSourceAdapter{newTableReader(index, tra, s3BlockSize), name}, nil
+}
@@ -63,6 +63,7 @@ type awsTablePersister struct {
 	indexCache *indexCache
 	ns         string
 	parseIndex indexParserF
+	tfc        *TableFileCipher
 }
 
 type awsLimits struct {
@@ -83,6 +84,18 @@ func (al awsLimits) tableMayBeInDynamo(chunkCount uint32) bool {
 }
 
 func (s3p awsTablePersister) Open(ctx context.Context, name addr, chunkCount uint32, stats *Stats) (chunkSource, error) {
+	if s3p.tfc != nil {
+		return newEncryptedAWSChunkSource(
+			ctx,
+			&s3ObjectReader{s3: s3p.s3, bucket: s3p.bucket, readRl: s3p.rl, tc: s3p.tc, ns: s3p.ns},
+			name,
+			chunkCount,
+			s3p.tfc,
+			stats,
+			s3p.parseIndex,
+		)
+	}
+
 	return newAWSChunkSource(
 		ctx,
 		s3p.ddb,
@@ -119,6 +132,10 @@ func (s3p awsTablePersister) Persist(ctx context.Context, mt *memTable, haver ch
 		return emptyChunkSource{}, nil
 	}
 
+	if s3p.tfc != nil {
+		return s3p.persistEncrypted(ctx, name, data, chunkCount)
+	}
+
 	if s3p.limits.tableFitsInDynamo(name, len(data), chunkCount) {
 		err := s3p.ddb.Write(ctx, name, data)
 
@@ -146,6 +163,32 @@ func (s3p awsTablePersister) Persist(ctx context.Context, mt *memTable, haver ch
 	return newReaderFromIndexData(s3p.indexCache, data, name, tra, s3BlockSize)
 }
 
+// persistEncrypted encrypts the table file |data| and uploads it to S3. Encrypted table files are never written to
+// DynamoDB, and their indexes aren't cached since the trailer of the table file is needed to read them.
+func (s3p awsTablePersister) persistEncrypted(ctx context.Context, name addr, data []byte, chunkCount uint32) (chunkSource, error) {
+	encrypted, iv, err := s3p.tfc.encrypt(data, chunkCount)
+
+	if err != nil {
+		return emptyChunkSource{}, err
+	}
+
+	if s3p.tc != nil {
+		go func() {
+			// Ignore errors.  Will be reloaded on read if needed, or error will occur at that time.
+			_ = s3p.tc.store(name, bytes.NewReader(encrypted), uint64(len(encrypted)))
+		}()
+	}
+
+	err = s3p.multipartUpload(ctx, encrypted, name.String())
+
+	if err != nil {
+		return emptyChunkSource{}, err
+	}
+
+	s3TRA := &s3TableReaderAt{&s3ObjectReader{s3: s3p.s3, bucket: s3p.bucket, readRl: s3p.rl, tc: s3p.tc, ns: s3p.ns}, name}
+	return newReaderFromIndexData(nil, data, name, &encryptedTableReaderAt{s3TRA, s3p.tfc, iv}, s3BlockSize)
+}
+
 func (s3p awsTablePersister) multipartUpload(ctx context.Context, data []byte, key string) error {
 	uploadID, err := s3p.startMultipartUpload(ctx, key)
 
@@ -309,6 +352,25 @@ func (s3p awsTablePersister) ConjoinAll(ctx context.Context, sources chunkSource
 	}
 	t1 := time.Now()
 	name := nameFromSuffixes(plan.suffixes())
+
+	if s3p.tfc != nil {
+		// the parts of encrypted tables can't be copied, so the conjoined table is assembled and encrypted locally
+		data, err := assembleConjoinedTable(ctx, plan)
+
+		if err != nil {
+			return nil, err
+		}
+
+		cs, err := s3p.persistEncrypted(ctx, name, data, plan.chunkCount)
+
+		if err != nil {
+			return nil, err
+		}
+
+		verbose.Logger(ctx).Sugar().Debugf("Compacted table of %d Kb in %s", plan.totalCompressedData/1024, time.Since(t1))
+		return cs, nil
+	}
+
 	err = s3p.executeCompactionPlan(ctx, plan, name.String())
 
 	if err != nil {
@@ -329,6 +391,33 @@ func (s3p awsTablePersister) ConjoinAll(ctx context.Context, sources chunkSource
 	return newReaderFromIndexData(s3p.indexCache, plan.mergedIndex, name, tra, s3BlockSize)
 }
 
+// assembleConjoinedTable returns the table file which conjoins the sources of |plan|
+func assembleConjoinedTable(ctx context.Context, plan compactionPlan) ([]byte, error) {
+	buff := bytes.NewBuffer(make([]byte, 0, plan.totalCompressedData+uint64(len(plan.mergedIndex))))
+
+	for _, sws := range plan.sources.sws {
+		r, err := sws.source.reader(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := io.CopyN(buff, r, int64(sws.dataLen))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if uint64(n) != sws.dataLen {
+			return nil, errors.New("failed to copy all data")
+		}
+	}
+
+	buff.Write(plan.mergedIndex)
+
+	return buff.Bytes(), nil
+}
+
 func (s3p awsTablePersister) loadIntoCache(ctx context.Context, name addr) error {
 	input := &s3.GetObjectInput{
 		Bucket: aws.String(s3p.bucket),
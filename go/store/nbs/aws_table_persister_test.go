@@ -370,6 +370,7 @@ func TestAWSTablePersisterConjoinAll(t *testing.T) {
 			ic,
 			"",
 			parseIndexF,
+			nil,
 		}
 	}
 
@@ -28,6 +28,7 @@ type blobstorePersister struct {
 	bs         blobstore.Blobstore
 	blockSize  uint64
 	indexCache *indexCache
+	tfc        *TableFileCipher
 }
 
 // Persist makes the contents of mt durable. Chunks already present in
@@ -43,6 +44,24 @@ func (bsp *blobstorePersister) Persist(ctx context.Context, mt *memTable, haver
 		return emptyChunkSource{}, nil
 	}
 
+	if bsp.tfc != nil {
+		encrypted, iv, err := bsp.tfc.encrypt(data, chunkCount)
+
+		if err != nil {
+			return emptyChunkSource{}, err
+		}
+
+		_, err = blobstore.PutBytes(ctx, bsp.bs, name.String(), encrypted)
+
+		if err != nil {
+			return emptyChunkSource{}, err
+		}
+
+		// the indexes of encrypted table files aren't cached, since the trailer of the table file is needed to read it
+		etra := &encryptedTableReaderAt{&bsTableReaderAt{name.String(), bsp.bs}, bsp.tfc, iv}
+		return newReaderFromIndexData(nil, data, name, etra, bsp.blockSize)
+	}
+
 	_, err = blobstore.PutBytes(ctx, bsp.bs, name.String(), data)
 
 	if err != nil {
@@ -61,6 +80,10 @@ func (bsp *blobstorePersister) ConjoinAll(ctx context.Context, sources chunkSour
 
 // Open a table named |name|, containing |chunkCount| chunks.
 func (bsp *blobstorePersister) Open(ctx context.Context, name addr, chunkCount uint32, stats *Stats) (chunkSource, error) {
+	if bsp.tfc != nil {
+		return newEncryptedBSChunkSource(ctx, bsp.bs, name, chunkCount, bsp.blockSize, bsp.tfc, stats)
+	}
+
 	return newBSChunkSource(ctx, bsp.bs, name, chunkCount, bsp.blockSize, bsp.indexCache, stats)
 }
 
@@ -103,7 +126,7 @@ func newBSChunkSource(ctx context.Context, bs blobstore.Blobstore, name addr, ch
 		defer func() {
 			unlockErr := indexCache.unlockEntry(name)
 
-			if err != nil {
+			if err == nil {
 				err = unlockErr
 			}
 		}()
@@ -141,7 +164,7 @@ func newBSChunkSource(ctx context.Context, bs blobstore.Blobstore, name addr, ch
 	index, err := parseTableIndex(indexBytes)
 
 	if err != nil {
-		return nil, err
+		return nil, tableFileError(name, err)
 	}
 
 	if indexCache != nil {
@@ -151,6 +174,43 @@ func newBSChunkSource(ctx context.Context, bs blobstore.Blobstore, name addr, ch
 	return &chunkSourceAdapter{newTableReader(index, tra, s3BlockSize), name}, nil
 }
 
+func newEncryptedBSChunkSource(ctx context.Context, bs blobstore.Blobstore, name addr, chunkCount uint32, blockSize uint64, tfc *TableFileCipher, stats *Stats) (chunkSource, error) {
+	t1 := time.Now()
+	size := int64(indexSize(chunkCount) + footerSize + encryptedTrailerSize)
+	key := name.String()
+	buff, _, err := blobstore.GetBytes(ctx, bs, key, blobstore.NewBlobRange(-size, 0))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if size != int64(len(buff)) {
+		if string(buff[len(buff)-magicNumberSize:]) == magicNumber {
+			return nil, tableFileError(name, ErrTableFileNotEncrypted)
+		}
+
+		return nil, errors.New("failed to read all data")
+	}
+
+	indexBytes, iv, err := tfc.decryptIndex(buff, chunkCount)
+
+	if err != nil {
+		return nil, tableFileError(name, err)
+	}
+
+	stats.IndexBytesPerRead.Sample(uint64(len(indexBytes)))
+	stats.IndexReadLatency.SampleTimeSince(t1)
+
+	index, err := parseTableIndex(indexBytes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tra := &encryptedTableReaderAt{&bsTableReaderAt{key, bs}, tfc, iv}
+	return &chunkSourceAdapter{newTableReader(index, tra, blockSize), name}, nil
+}
+
 func (bsp *blobstorePersister) PruneTableFiles(ctx context.Context, contents manifestContents) error {
 	return chunks.ErrUnsupportedOperation
 }
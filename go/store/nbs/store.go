@@ -267,12 +267,19 @@ func NewAWSStoreWithMMapIndex(ctx context.Context, nbfVerStr string, table, ns,
 			}
 			return newMmapTableIndex(ohi, nil)
 		},
+		nil,
 	}
 	mm := makeManifestManager(newDynamoManifest(table, ns, ddb))
 	return newNomsBlockStore(ctx, nbfVerStr, mm, p, inlineConjoiner{defaultMaxTables}, memTableSize)
 }
 
 func NewAWSStore(ctx context.Context, nbfVerStr string, table, ns, bucket string, s3 s3svc, ddb ddbsvc, memTableSize uint64) (*NomsBlockStore, error) {
+	return NewEncryptedAWSStore(ctx, nbfVerStr, table, ns, bucket, s3, ddb, memTableSize, nil)
+}
+
+// NewEncryptedAWSStore returns an nbs implementation backed by S3 and DynamoDB whose table files are encrypted with
+// |tfc|. Table files aren't encrypted if |tfc| is nil.
+func NewEncryptedAWSStore(ctx context.Context, nbfVerStr string, table, ns, bucket string, s3 s3svc, ddb ddbsvc, memTableSize uint64, tfc *TableFileCipher) (*NomsBlockStore, error) {
 	cacheOnce.Do(makeGlobalCaches)
 	readRateLimiter := make(chan struct{}, 32)
 	p := &awsTablePersister{
@@ -287,6 +294,7 @@ func NewAWSStore(ctx context.Context, nbfVerStr string, table, ns, bucket string
 		func(bs []byte) (tableIndex, error) {
 			return parseTableIndex(bs)
 		},
+		tfc,
 	}
 	mm := makeManifestManager(newDynamoManifest(table, ns, ddb))
 	return newNomsBlockStore(ctx, nbfVerStr, mm, p, inlineConjoiner{defaultMaxTables}, memTableSize)
@@ -302,11 +310,17 @@ func NewGCSStore(ctx context.Context, nbfVerStr string, bucketName, path string,
 
 // NewBSStore returns an nbs implementation backed by a Blobstore
 func NewBSStore(ctx context.Context, nbfVerStr string, bs blobstore.Blobstore, memTableSize uint64) (*NomsBlockStore, error) {
+	return NewEncryptedBSStore(ctx, nbfVerStr, bs, memTableSize, nil)
+}
+
+// NewEncryptedBSStore returns an nbs implementation backed by a Blobstore whose table files are encrypted with |tfc|.
+// Table files aren't encrypted if |tfc| is nil.
+func NewEncryptedBSStore(ctx context.Context, nbfVerStr string, bs blobstore.Blobstore, memTableSize uint64, tfc *TableFileCipher) (*NomsBlockStore, error) {
 	cacheOnce.Do(makeGlobalCaches)
 
 	mm := makeManifestManager(blobstoreManifest{"manifest", bs})
 
-	p := &blobstorePersister{bs, s3BlockSize, globalIndexCache}
+	p := &blobstorePersister{bs, s3BlockSize, globalIndexCache, tfc}
 	return newNomsBlockStore(ctx, nbfVerStr, mm, p, inlineConjoiner{defaultMaxTables}, memTableSize)
 }
 
@@ -0,0 +1,219 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Encrypted table files hold the table file encrypted with AES-256 in counter mode, followed by a trailer. Counter mode
+// keeps the offsets of chunks and of the index unchanged, so encrypted table files can be read with the same range
+// reads as plain ones. The trailer is laid out as:
+//
+//   iv [16]byte | table file length uint64 | key check [16]byte | mac [32]byte | magic [8]byte
+//
+// The key check identifies the key a table file was encrypted with, and the mac authenticates the encrypted index.
+
+const (
+	// TableFileEncryptionKeySize is the size of the keys used to encrypt table files
+	TableFileEncryptionKeySize = 32
+
+	encryptedMagicNumber  = "\xff\xb5\xd8\xc2\x45\x4e\x43\x31"
+	encryptionIVSize      = aes.BlockSize
+	encryptionKeyChkSize  = 16
+	encryptionMACSize     = sha256.Size
+	encryptedTrailerSize  = encryptionIVSize + uint64Size + encryptionKeyChkSize + encryptionMACSize + magicNumberSize
+	encryptionKeyInfo     = "dolt table file encryption"
+	authenticationKeyInfo = "dolt table file authentication"
+	keyCheckInfo          = "dolt table file key check"
+)
+
+// ErrWrongEncryptionKey is returned when reading a table file which was encrypted with a different key
+var ErrWrongEncryptionKey = errors.New("table file was encrypted with a different key")
+
+// ErrTableFileEncrypted is returned when reading an encrypted table file without an encryption key
+var ErrTableFileEncrypted = errors.New("table file is encrypted, and no encryption key was provided")
+
+// ErrTableFileNotEncrypted is returned when reading a table file which isn't encrypted with an encryption key
+var ErrTableFileNotEncrypted = errors.New("table file is not encrypted, but an encryption key was provided")
+
+// TableFileCipher encrypts the table files written by a store, and decrypts the table files it reads. The addresses of
+// chunks and the names of table files are unchanged by encryption.
+type TableFileCipher struct {
+	block   cipher.Block
+	authKey []byte
+}
+
+// NewTableFileCipher returns a TableFileCipher for the TableFileEncryptionKeySize byte key |key|
+func NewTableFileCipher(key []byte) (*TableFileCipher, error) {
+	if len(key) != TableFileEncryptionKeySize {
+		return nil, fmt.Errorf("table file encryption keys must be %d bytes", TableFileEncryptionKeySize)
+	}
+
+	block, err := aes.NewCipher(deriveKey(key, encryptionKeyInfo))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &TableFileCipher{block, deriveKey(key, authenticationKeyInfo)}, nil
+}
+
+func deriveKey(key []byte, info string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(info))
+	return mac.Sum(nil)
+}
+
+// encrypt returns the encrypted table file of the table file |data| holding |chunkCount| chunks, and the iv it was
+// encrypted with.
+func (tfc *TableFileCipher) encrypt(data []byte, chunkCount uint32) ([]byte, []byte, error) {
+	indexLen := indexSize(chunkCount) + footerSize
+	if uint64(len(data)) < indexLen {
+		return nil, nil, ErrInvalidTableFile
+	}
+
+	iv := make([]byte, encryptionIVSize)
+	_, err := rand.Read(iv)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encrypted := make([]byte, len(data), len(data)+encryptedTrailerSize)
+	copy(encrypted, data)
+	tfc.xorKeyStreamAt(iv, encrypted, 0)
+
+	tableLen := uint64(len(data))
+	encrypted = append(encrypted, iv...)
+	encrypted = append(encrypted, make([]byte, uint64Size)...)
+	binary.BigEndian.PutUint64(encrypted[len(encrypted)-uint64Size:], tableLen)
+	encrypted = append(encrypted, tfc.keyCheck(iv)...)
+	encrypted = append(encrypted, tfc.mac(iv, tableLen, encrypted[tableLen-indexLen:tableLen])...)
+	encrypted = append(encrypted, encryptedMagicNumber...)
+
+	return encrypted, iv, nil
+}
+
+// decryptIndex takes |tail|, the last indexLen+encryptedTrailerSize bytes of an encrypted table file where indexLen is
+// the size of the index and footer of a table file holding |chunkCount| chunks. It returns the decrypted index and
+// footer, and the iv the table file was encrypted with.
+func (tfc *TableFileCipher) decryptIndex(tail []byte, chunkCount uint32) (index []byte, iv []byte, err error) {
+	if len(tail) < encryptedTrailerSize || string(tail[len(tail)-magicNumberSize:]) != encryptedMagicNumber {
+		return nil, nil, ErrTableFileNotEncrypted
+	}
+
+	indexLen := indexSize(chunkCount) + footerSize
+	if uint64(len(tail)) != indexLen+encryptedTrailerSize {
+		return nil, nil, ErrInvalidTableFile
+	}
+
+	trailer := tail[indexLen:]
+	iv = trailer[:encryptionIVSize]
+	pos := encryptionIVSize
+	tableLen := binary.BigEndian.Uint64(trailer[pos:])
+	pos += uint64Size
+	keyCheck := trailer[pos : pos+encryptionKeyChkSize]
+	pos += encryptionKeyChkSize
+	mac := trailer[pos : pos+encryptionMACSize]
+
+	if !hmac.Equal(keyCheck, tfc.keyCheck(iv)) {
+		return nil, nil, ErrWrongEncryptionKey
+	}
+
+	if tableLen < indexLen || !hmac.Equal(mac, tfc.mac(iv, tableLen, tail[:indexLen])) {
+		return nil, nil, ErrInvalidTableFile
+	}
+
+	index = make([]byte, indexLen)
+	copy(index, tail[:indexLen])
+	tfc.xorKeyStreamAt(iv, index, int64(tableLen-indexLen))
+
+	return index, iv, nil
+}
+
+// xorKeyStreamAt encrypts or decrypts |p|, which is at the offset |off| of a table file encrypted with |iv|
+func (tfc *TableFileCipher) xorKeyStreamAt(iv []byte, p []byte, off int64) {
+	if len(p) == 0 {
+		return
+	}
+
+	// the counter of the block holding |off| is the iv plus the index of the block
+	ctr := make([]byte, encryptionIVSize)
+	copy(ctr, iv)
+	blockIdx := uint64(off) / aes.BlockSize
+	for i := len(ctr) - 1; i >= 0 && blockIdx > 0; i-- {
+		sum := uint64(ctr[i]) + blockIdx&0xff
+		ctr[i] = byte(sum)
+		blockIdx = blockIdx>>8 + sum>>8
+	}
+
+	stream := cipher.NewCTR(tfc.block, ctr)
+
+	if skip := int(uint64(off) % aes.BlockSize); skip > 0 {
+		discard := make([]byte, skip)
+		stream.XORKeyStream(discard, discard)
+	}
+
+	stream.XORKeyStream(p, p)
+}
+
+func (tfc *TableFileCipher) keyCheck(iv []byte) []byte {
+	mac := hmac.New(sha256.New, tfc.authKey)
+	mac.Write([]byte(keyCheckInfo))
+	mac.Write(iv)
+	return mac.Sum(nil)[:encryptionKeyChkSize]
+}
+
+func (tfc *TableFileCipher) mac(iv []byte, tableLen uint64, encryptedIndex []byte) []byte {
+	lenBytes := make([]byte, uint64Size)
+	binary.BigEndian.PutUint64(lenBytes, tableLen)
+
+	mac := hmac.New(sha256.New, tfc.authKey)
+	mac.Write(iv)
+	mac.Write(lenBytes)
+	mac.Write(encryptedIndex)
+	return mac.Sum(nil)
+}
+
+// encryptedTableReaderAt decrypts the data read from an encrypted table file
+type encryptedTableReaderAt struct {
+	tra tableReaderAt
+	tfc *TableFileCipher
+	iv  []byte
+}
+
+func (etra *encryptedTableReaderAt) ReadAtWithStats(ctx context.Context, p []byte, off int64, stats *Stats) (int, error) {
+	n, err := etra.tra.ReadAtWithStats(ctx, p, off, stats)
+	etra.tfc.xorKeyStreamAt(etra.iv, p[:n], off)
+	return n, err
+}
+
+// tableFileError adds the name of the table file |name| to errors reading it
+func tableFileError(name addr, err error) error {
+	if err == ErrWrongEncryptionKey || err == ErrTableFileEncrypted || err == ErrTableFileNotEncrypted {
+		return fmt.Errorf("%w: %s", err, name.String())
+	}
+
+	return err
+}
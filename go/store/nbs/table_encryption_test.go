@@ -0,0 +1,209 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/store/blobstore"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/constants"
+)
+
+func newTestCipher(t *testing.T, b byte) *TableFileCipher {
+	tfc, err := NewTableFileCipher(bytes.Repeat([]byte{b}, TableFileEncryptionKeySize))
+	require.NoError(t, err)
+	return tfc
+}
+
+func TestTableFileCipher(t *testing.T) {
+	_, err := NewTableFileCipher([]byte("too short"))
+	assert.Error(t, err)
+
+	tfc := newTestCipher(t, 1)
+	data, _, err := buildTable(testChunks)
+	require.NoError(t, err)
+	chunkCount := uint32(len(testChunks))
+
+	encrypted, iv, err := tfc.encrypt(data, chunkCount)
+	require.NoError(t, err)
+	require.Equal(t, len(data)+encryptedTrailerSize, len(encrypted))
+	assert.NotEqual(t, data, encrypted[:len(data)])
+
+	tailLen := indexSize(chunkCount) + footerSize + encryptedTrailerSize
+	tail := encrypted[uint64(len(encrypted))-tailLen:]
+
+	t.Run("decrypt index", func(t *testing.T) {
+		index, tailIV, err := tfc.decryptIndex(tail, chunkCount)
+		require.NoError(t, err)
+		assert.Equal(t, iv, tailIV)
+		assert.Equal(t, data[uint64(len(data))-indexSize(chunkCount)-footerSize:], index)
+	})
+
+	t.Run("decrypt at offsets", func(t *testing.T) {
+		for off := 0; off < len(data); off++ {
+			for _, l := range []int{1, 15, 16, 17, 40} {
+				if off+l > len(data) {
+					continue
+				}
+
+				p := make([]byte, l)
+				copy(p, encrypted[off:off+l])
+				tfc.xorKeyStreamAt(iv, p, int64(off))
+				require.Equal(t, data[off:off+l], p, "offset %d length %d", off, l)
+			}
+		}
+	})
+
+	t.Run("counter carry", func(t *testing.T) {
+		iv := bytes.Repeat([]byte{0xff}, encryptionIVSize)
+		iv[0] = 0
+		plain := make([]byte, 4*aes.BlockSize)
+		expected := make([]byte, len(plain))
+		cipher.NewCTR(tfc.block, iv).XORKeyStream(expected, plain)
+
+		p := make([]byte, 2*aes.BlockSize+3)
+		tfc.xorKeyStreamAt(iv, p, aes.BlockSize+5)
+		assert.Equal(t, expected[aes.BlockSize+5:3*aes.BlockSize+8], p)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		_, _, err := newTestCipher(t, 2).decryptIndex(tail, chunkCount)
+		assert.Equal(t, ErrWrongEncryptionKey, err)
+	})
+
+	t.Run("corrupt index", func(t *testing.T) {
+		corrupt := make([]byte, len(tail))
+		copy(corrupt, tail)
+		corrupt[0] ^= 0xff
+		_, _, err := tfc.decryptIndex(corrupt, chunkCount)
+		assert.Equal(t, ErrInvalidTableFile, err)
+	})
+
+	t.Run("not encrypted", func(t *testing.T) {
+		plainTail := append(make([]byte, encryptedTrailerSize), data[uint64(len(data))-indexSize(chunkCount)-footerSize:]...)
+		_, _, err := tfc.decryptIndex(plainTail, chunkCount)
+		assert.Equal(t, ErrTableFileNotEncrypted, err)
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		_, err := parseTableIndex(encrypted)
+		assert.Equal(t, ErrTableFileEncrypted, err)
+	})
+}
+
+func TestEncryptedAWSTablePersister(t *testing.T) {
+	ctx := context.Background()
+	s3svc, ddb := makeFakeS3(t), makeFakeDTS(makeFakeDDB(t), nil)
+	tfc := newTestCipher(t, 1)
+	newPersister := func(tfc *TableFileCipher) awsTablePersister {
+		return awsTablePersister{s3: s3svc, bucket: "bucket", ddb: ddb, limits: awsLimits{partTarget: 1 << 20}, ns: "ns", parseIndex: parseIndexF, tfc: tfc}
+	}
+	s3p := newPersister(tfc)
+
+	persist := func(chunks [][]byte) chunkSource {
+		mt := newMemTable(testMemTableSize)
+		for _, c := range chunks {
+			require.True(t, mt.addChunk(computeAddr(c), c))
+		}
+
+		src, err := s3p.Persist(ctx, mt, nil, &Stats{})
+		require.NoError(t, err)
+		return src
+	}
+
+	src := persist(testChunks)
+	assertChunksInReader(testChunks, src, assert.New(t))
+
+	name, count := mustAddr(src.hash()), mustUint32(src.count())
+	rdr, err := s3p.Open(ctx, name, count, &Stats{})
+	require.NoError(t, err)
+	assertChunksInReader(testChunks, rdr, assert.New(t))
+
+	// the table file stored in S3 isn't readable without the key
+	_, err = s3svc.readerForTableWithNamespace("ns", name)
+	assert.Equal(t, ErrTableFileEncrypted, err)
+
+	_, err = newPersister(newTestCipher(t, 2)).Open(ctx, name, count, &Stats{})
+	assert.True(t, errors.Is(err, ErrWrongEncryptionKey))
+
+	_, err = newPersister(nil).Open(ctx, name, count, &Stats{})
+	assert.True(t, errors.Is(err, ErrTableFileEncrypted))
+
+	t.Run("ConjoinAll", func(t *testing.T) {
+		moreChunks := [][]byte{[]byte("hello3"), []byte("goodbye3")}
+		sources := chunkSources{src, persist(moreChunks)}
+
+		conjoined, err := s3p.ConjoinAll(ctx, sources, &Stats{})
+		require.NoError(t, err)
+
+		allChunks := append(append([][]byte{}, testChunks...), moreChunks...)
+		assertChunksInReader(allChunks, conjoined, assert.New(t))
+
+		rdr, err := s3p.Open(ctx, mustAddr(conjoined.hash()), mustUint32(conjoined.count()), &Stats{})
+		require.NoError(t, err)
+		assertChunksInReader(allChunks, rdr, assert.New(t))
+	})
+}
+
+func TestEncryptedBSStore(t *testing.T) {
+	ctx := context.Background()
+	bs := blobstore.NewInMemoryBlobstore()
+	tfc := newTestCipher(t, 1)
+	c := chunks.NewChunk([]byte("encrypted chunk"))
+
+	store, err := NewEncryptedBSStore(ctx, constants.FormatDefaultString, bs, testMemTableSize, tfc)
+	require.NoError(t, err)
+	require.NoError(t, store.Put(ctx, c))
+	root, err := store.Root(ctx)
+	require.NoError(t, err)
+	ok, err := store.Commit(ctx, c.Hash(), root)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, store.Close())
+
+	store, err = NewEncryptedBSStore(ctx, constants.FormatDefaultString, bs, testMemTableSize, tfc)
+	require.NoError(t, err)
+	read, err := store.Get(ctx, c.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, c.Data(), read.Data())
+	require.NoError(t, store.Close())
+
+	_, err = NewEncryptedBSStore(ctx, constants.FormatDefaultString, bs, testMemTableSize, newTestCipher(t, 2))
+	assert.True(t, errors.Is(err, ErrWrongEncryptionKey))
+
+	_, err = NewBSStore(ctx, constants.FormatDefaultString, bs, testMemTableSize)
+	assert.True(t, errors.Is(err, ErrTableFileEncrypted))
+
+	plain := blobstore.NewInMemoryBlobstore()
+	store, err = NewBSStore(ctx, constants.FormatDefaultString, plain, testMemTableSize)
+	require.NoError(t, err)
+	require.NoError(t, store.Put(ctx, c))
+	ok, err = store.Commit(ctx, c.Hash(), root)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, store.Close())
+
+	_, err = NewEncryptedBSStore(ctx, constants.FormatDefaultString, plain, testMemTableSize, tfc)
+	assert.True(t, errors.Is(err, ErrTableFileNotEncrypted))
+}
@@ -370,6 +370,10 @@ func parseTableIndex(buff []byte) (onHeapTableIndex, error) {
 	pos -= magicNumberSize
 
 	if string(buff[pos:]) != magicNumber {
+		if string(buff[pos:]) == encryptedMagicNumber {
+			return onHeapTableIndex{}, ErrTableFileEncrypted
+		}
+
 		return onHeapTableIndex{}, ErrInvalidTableFile
 	}
 
@@ -0,0 +1,371 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/creds"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+// Permission is the level of access a user has to a repository
+type Permission int
+
+const (
+	NoPermission Permission = iota
+	ReadPermission
+	WritePermission
+)
+
+const (
+	// AnyUser is the user name which grants a permission to every authenticated user in the repos section of an
+	// auth config.
+	AnyUser = "*"
+
+	// AnonymousUser is the user name which grants a permission to every request, including requests without
+	// credentials, in the repos section of an auth config.
+	AnonymousUser = "anonymous"
+
+	// tokenLeeway is the clock skew allowed when checking the expiration of the bearer tokens of dolt credentials.
+	tokenLeeway = time.Minute
+)
+
+var ErrUnauthenticated = errors.New("invalid credentials")
+
+// AuthConfig is the file read by the --auth-config parameter. Users are identified by the public key of the dolt
+// credentials they sign their requests with, or by a static bearer token. Repos maps "org/repo", "org/*" or "*" to
+// the permissions of users on the matching repositories, which are "read" or "write". The most specific pattern which
+// matches a repository is used.
+type AuthConfig struct {
+	Users []UserConfig                 `json:"users"`
+	Repos map[string]map[string]string `json:"repos"`
+}
+
+// UserConfig is a user of an AuthConfig. Exactly one of PublicKey, which is the base32 public key printed by
+// `dolt creds ls -v`, JWKFile, which is the path of a .jwk file created by `dolt creds new`, or Token must be set.
+type UserConfig struct {
+	Name      string `json:"name"`
+	PublicKey string `json:"public_key,omitempty"`
+	JWKFile   string `json:"jwk_file,omitempty"`
+	Token     string `json:"token,omitempty"`
+}
+
+// Authorizer authenticates the requests made to the server, and checks the permissions of their users.
+type Authorizer struct {
+	keys   map[string]userKey
+	tokens []userToken
+	repos  map[string]map[string]Permission
+}
+
+type userKey struct {
+	user   string
+	pubKey ed25519.PublicKey
+}
+
+type userToken struct {
+	user  string
+	token []byte
+}
+
+// LoadAuthConfig reads the AuthConfig at |path|
+func LoadAuthConfig(path string) (*Authorizer, error) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg AuthConfig
+	err = json.Unmarshal(data, &cfg)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse auth config '%s': %w", path, err)
+	}
+
+	return NewAuthorizer(cfg)
+}
+
+// NewAuthorizer returns an Authorizer for the users and repository permissions of |cfg|
+func NewAuthorizer(cfg AuthConfig) (*Authorizer, error) {
+	auth := &Authorizer{
+		keys:  make(map[string]userKey),
+		repos: make(map[string]map[string]Permission),
+	}
+
+	names := make(map[string]bool)
+	for _, u := range cfg.Users {
+		if u.Name == "" || u.Name == AnyUser || u.Name == AnonymousUser {
+			return nil, fmt.Errorf("invalid user name '%s'", u.Name)
+		}
+
+		if names[u.Name] {
+			return nil, fmt.Errorf("user '%s' is defined more than once", u.Name)
+		}
+		names[u.Name] = true
+
+		var pubKey []byte
+		var err error
+		switch {
+		case u.PublicKey != "" && u.JWKFile == "" && u.Token == "":
+			pubKey, err = creds.B32CredsEncoding.DecodeString(u.PublicKey)
+		case u.JWKFile != "" && u.PublicKey == "" && u.Token == "":
+			var dc creds.DoltCreds
+			dc, err = creds.JWKCredsReadFromFile(filesys.LocalFS, u.JWKFile)
+			pubKey = dc.PubKey
+		case u.Token != "" && u.PublicKey == "" && u.JWKFile == "":
+			auth.tokens = append(auth.tokens, userToken{u.Name, []byte(u.Token)})
+			continue
+		default:
+			return nil, fmt.Errorf("user '%s' must have exactly one of public_key, jwk_file or token", u.Name)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the key of user '%s': %w", u.Name, err)
+		}
+
+		if len(pubKey) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("the key of user '%s' is not a valid dolt credentials public key", u.Name)
+		}
+
+		auth.keys[creds.PubKeyToKIDStr(pubKey)] = userKey{u.Name, pubKey}
+	}
+
+	for pattern, users := range cfg.Repos {
+		if !isValidRepoPattern(pattern) {
+			return nil, fmt.Errorf("invalid repo '%s'. repos must be 'org/repo', 'org/*' or '*'", pattern)
+		}
+
+		perms := make(map[string]Permission)
+		for user, permStr := range users {
+			if user != AnyUser && user != AnonymousUser && !names[user] {
+				return nil, fmt.Errorf("repo '%s' grants permissions to unknown user '%s'", pattern, user)
+			}
+
+			perm, err := parsePermission(permStr)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid permission for user '%s' on repo '%s': %w", user, pattern, err)
+			}
+
+			perms[user] = perm
+		}
+
+		auth.repos[pattern] = perms
+	}
+
+	return auth, nil
+}
+
+func isValidRepoPattern(pattern string) bool {
+	if pattern == "*" {
+		return true
+	}
+
+	tokens := strings.Split(pattern, "/")
+	return len(tokens) == 2 && isValidPathComponent(tokens[0]) && (tokens[1] == "*" || isValidPathComponent(tokens[1]))
+}
+
+func parsePermission(str string) (Permission, error) {
+	switch strings.ToLower(str) {
+	case "read":
+		return ReadPermission, nil
+	case "write":
+		return WritePermission, nil
+	case "none", "":
+		return NoPermission, nil
+	}
+
+	return NoPermission, fmt.Errorf("unknown permission '%s'. should be 'read' or 'write'", str)
+}
+
+// Authenticate returns the user of the value of an authorization header, which is a bearer token signed by dolt
+// credentials or a static token. Requests without a header, or signed by credentials which don't belong to any user,
+// are anonymous, and their user is returned as "".
+func (auth *Authorizer) Authenticate(authHeader string) (string, error) {
+	if authHeader == "" {
+		return "", nil
+	}
+
+	const bearerPrefix = "bearer "
+	if len(authHeader) <= len(bearerPrefix) || !strings.EqualFold(authHeader[:len(bearerPrefix)], bearerPrefix) {
+		return "", ErrUnauthenticated
+	}
+
+	token := strings.TrimSpace(authHeader[len(bearerPrefix):])
+
+	for _, t := range auth.tokens {
+		if subtle.ConstantTimeCompare(t.token, []byte(token)) == 1 {
+			return t.user, nil
+		}
+	}
+
+	return auth.authenticateCredsToken(token)
+}
+
+// authenticateCredsToken checks a JWT created by dolt credentials against the keys of the users
+func (auth *Authorizer) authenticateCredsToken(token string) (string, error) {
+	tok, err := jwt.ParseSigned(token)
+
+	if err != nil || len(tok.Headers) != 1 {
+		return "", ErrUnauthenticated
+	}
+
+	// dolt clients send a token for the credentials they are configured with to every remote, so the requests of users
+	// which aren't known to this server are anonymous.
+	kid := tok.Headers[0].KeyID
+	key, ok := auth.keys[kid]
+
+	if !ok {
+		return "", nil
+	}
+
+	var claims jwt.Claims
+	err = tok.Claims(key.pubKey, &claims)
+
+	if err != nil {
+		return "", ErrUnauthenticated
+	}
+
+	err = claims.ValidateWithLeeway(jwt.Expected{
+		Issuer:   creds.ClientIssuer,
+		Subject:  creds.ClientSubjectPrefix + kid,
+		Audience: jwt.Audience{creds.RemoteAPIAudience},
+		Time:     time.Now(),
+	}, tokenLeeway)
+
+	if err != nil || claims.Expiry == nil {
+		return "", ErrUnauthenticated
+	}
+
+	return key.user, nil
+}
+
+// Permission returns the permission |user| has on the repository |org|/|repo|. |user| is "" for anonymous requests.
+func (auth *Authorizer) Permission(user, org, repo string) Permission {
+	perms, ok := auth.repos[org+"/"+repo]
+
+	if !ok {
+		perms, ok = auth.repos[org+"/*"]
+	}
+
+	if !ok {
+		perms = auth.repos["*"]
+	}
+
+	perm := perms[AnonymousUser]
+	if user != "" {
+		if p := perms[AnyUser]; p > perm {
+			perm = p
+		}
+
+		if p := perms[user]; p > perm {
+			perm = p
+		}
+	}
+
+	return perm
+}
+
+// requiredPermissions is the permission needed to call each method of the remotesapi ChunkStoreService. Methods which
+// aren't listed are rejected.
+var requiredPermissions = map[string]Permission{
+	"GetRepoMetadata":      ReadPermission,
+	"HasChunks":            ReadPermission,
+	"GetDownloadLocations": ReadPermission,
+	"Rebase":               ReadPermission,
+	"Root":                 ReadPermission,
+	"ListTableFiles":       ReadPermission,
+	"GetUploadLocations":   WritePermission,
+	"Commit":               WritePermission,
+	"AddTableFiles":        WritePermission,
+}
+
+type repoRequest interface {
+	GetRepoId() *remotesapi.RepoId
+}
+
+type permissionKey struct{}
+
+// permissionFromContext returns the permission the user of a grpc request has on the repository of the request
+func permissionFromContext(ctx context.Context) Permission {
+	if perm, ok := ctx.Value(permissionKey{}).(Permission); ok {
+		return perm
+	}
+
+	return WritePermission
+}
+
+// UnaryServerInterceptor authenticates grpc requests and checks that their users have permission to call the
+// method on the repository of the request. A nil Authorizer allows every request.
+func (auth *Authorizer) UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if auth == nil {
+		return handler(ctx, req)
+	}
+
+	method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+	required, ok := requiredPermissions[method]
+
+	if !ok {
+		return nil, status.Error(codes.PermissionDenied, method+" is not allowed")
+	}
+
+	rr, ok := req.(repoRequest)
+
+	if !ok || rr.GetRepoId() == nil {
+		return nil, status.Error(codes.InvalidArgument, "request has no repo id")
+	}
+
+	var authHeader string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get("authorization"); len(vals) > 0 {
+			authHeader = vals[0]
+		}
+	}
+
+	user, err := auth.Authenticate(authHeader)
+
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	repoId := rr.GetRepoId()
+	perm := auth.Permission(user, repoId.Org, repoId.RepoName)
+
+	if perm < required {
+		if user == "" {
+			return nil, status.Errorf(codes.Unauthenticated, "credentials are required to access %s/%s", repoId.Org, repoId.RepoName)
+		}
+
+		return nil, status.Errorf(codes.PermissionDenied, "user %s does not have permission to call %s on %s/%s", user, method, repoId.Org, repoId.RepoName)
+	}
+
+	return handler(context.WithValue(ctx, permissionKey{}, perm), req)
+}
@@ -0,0 +1,170 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/creds"
+)
+
+func authHeader(t *testing.T, dc creds.DoltCreds) string {
+	md, err := dc.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	return md["authorization"]
+}
+
+func TestAuthorizer(t *testing.T) {
+	alice, err := creds.GenerateCredentials()
+	require.NoError(t, err)
+	bob, err := creds.GenerateCredentials()
+	require.NoError(t, err)
+	stranger, err := creds.GenerateCredentials()
+	require.NoError(t, err)
+
+	auth, err := NewAuthorizer(AuthConfig{
+		Users: []UserConfig{
+			{Name: "alice", PublicKey: alice.PubKeyBase32Str()},
+			{Name: "bob", PublicKey: bob.PubKeyBase32Str()},
+			{Name: "ci", Token: "secret"},
+		},
+		Repos: map[string]map[string]string{
+			"team/*":      {"alice": "write", "bob": "read"},
+			"team/shared": {"*": "write"},
+			"public/*":    {"anonymous": "read", "alice": "write"},
+			"*":           {"ci": "read"},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("authenticate", func(t *testing.T) {
+		user, err := auth.Authenticate(authHeader(t, alice))
+		require.NoError(t, err)
+		assert.Equal(t, "alice", user)
+
+		user, err = auth.Authenticate(authHeader(t, bob))
+		require.NoError(t, err)
+		assert.Equal(t, "bob", user)
+
+		user, err = auth.Authenticate("Bearer secret")
+		require.NoError(t, err)
+		assert.Equal(t, "ci", user)
+
+		user, err = auth.Authenticate("")
+		require.NoError(t, err)
+		assert.Equal(t, "", user)
+
+		user, err = auth.Authenticate(authHeader(t, stranger))
+		require.NoError(t, err)
+		assert.Equal(t, "", user)
+
+		// a token signed by another key with the key id of alice
+		forged := creds.DoltCreds{PubKey: stranger.PubKey, PrivKey: stranger.PrivKey, KeyID: alice.KeyID}
+		_, err = auth.Authenticate(authHeader(t, forged))
+		assert.Equal(t, ErrUnauthenticated, err)
+
+		_, err = auth.Authenticate("Bearer not-a-secret")
+		assert.Equal(t, ErrUnauthenticated, err)
+
+		_, err = auth.Authenticate("Basic c2VjcmV0")
+		assert.Equal(t, ErrUnauthenticated, err)
+	})
+
+	t.Run("permission", func(t *testing.T) {
+		tests := []struct {
+			user     string
+			org      string
+			repo     string
+			expected Permission
+		}{
+			{"alice", "team", "db", WritePermission},
+			{"bob", "team", "db", ReadPermission},
+			{"ci", "team", "db", NoPermission},
+			{"", "team", "db", NoPermission},
+			{"bob", "team", "shared", WritePermission},
+			{"", "team", "shared", NoPermission},
+			{"alice", "public", "db", WritePermission},
+			{"bob", "public", "db", ReadPermission},
+			{"", "public", "db", ReadPermission},
+			{"ci", "other", "db", ReadPermission},
+			{"alice", "other", "db", NoPermission},
+		}
+
+		for _, test := range tests {
+			assert.Equal(t, test.expected, auth.Permission(test.user, test.org, test.repo), "%s on %s/%s", test.user, test.org, test.repo)
+		}
+	})
+}
+
+func TestInvalidAuthConfig(t *testing.T) {
+	dc, err := creds.GenerateCredentials()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		cfg  AuthConfig
+	}{
+		{"no key", AuthConfig{Users: []UserConfig{{Name: "alice"}}}},
+		{"two keys", AuthConfig{Users: []UserConfig{{Name: "alice", PublicKey: dc.PubKeyBase32Str(), Token: "secret"}}}},
+		{"bad key", AuthConfig{Users: []UserConfig{{Name: "alice", PublicKey: "abc"}}}},
+		{"reserved name", AuthConfig{Users: []UserConfig{{Name: AnonymousUser, Token: "secret"}}}},
+		{"duplicate user", AuthConfig{Users: []UserConfig{{Name: "alice", Token: "a"}, {Name: "alice", Token: "b"}}}},
+		{"unknown user", AuthConfig{Repos: map[string]map[string]string{"org/repo": {"alice": "read"}}}},
+		{"bad permission", AuthConfig{Repos: map[string]map[string]string{"org/repo": {"*": "admin"}}}},
+		{"bad repo", AuthConfig{Repos: map[string]map[string]string{"org/../repo": {"*": "read"}}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewAuthorizer(test.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestURLSigner(t *testing.T) {
+	signer, err := NewURLSigner(time.Minute)
+	require.NoError(t, err)
+
+	now := time.Now()
+	signer.now = func() time.Time { return now }
+
+	path := tableFilePath("org", "repo", "nnqsu7jel3p7h7tguc9fjflt5fadm8up")
+	query, err := url.ParseQuery(signer.Sign(readOp, path))
+	require.NoError(t, err)
+
+	assert.NoError(t, signer.Verify(readOp, path, query))
+	assert.Equal(t, ErrURLSignatureInvalid, signer.Verify(writeOp, path, query))
+	assert.Equal(t, ErrURLSignatureInvalid, signer.Verify(readOp, tableFilePath("org", "other", "nnqsu7jel3p7h7tguc9fjflt5fadm8up"), query))
+	assert.Equal(t, ErrURLNotSigned, signer.Verify(readOp, path, url.Values{}))
+
+	tampered := url.Values{}
+	tampered.Set(expiresParam, "99999999999")
+	tampered.Set(signatureParam, query.Get(signatureParam))
+	assert.Equal(t, ErrURLSignatureInvalid, signer.Verify(readOp, path, tampered))
+
+	other, err := NewURLSigner(time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, ErrURLSignatureInvalid, other.Verify(readOp, path, query))
+
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, ErrURLExpired, signer.Verify(readOp, path, query))
+}
@@ -16,7 +16,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/dolthub/dolt/go/libraries/utils/filesys"
@@ -27,6 +29,9 @@ const (
 	defaultMemTableSize = 128 * 1024 * 1024
 )
 
+var ErrRepoNotFound = errors.New("repo not found")
+var ErrInvalidRepoName = errors.New("invalid repo name")
+
 type DBCache struct {
 	mu  *sync.Mutex
 	dbs map[string]*nbs.NomsBlockStore
@@ -42,7 +47,13 @@ func NewLocalCSCache(filesys filesys.Filesys) *DBCache {
 	}
 }
 
-func (cache *DBCache) Get(org, repo, nbfVerStr string) (*nbs.NomsBlockStore, error) {
+// Get returns the store of the repository |org|/|repo|. If the repository doesn't exist it is created with the format
+// |nbfVerStr| when |create| is true, and ErrRepoNotFound is returned otherwise.
+func (cache *DBCache) Get(org, repo, nbfVerStr string, create bool) (*nbs.NomsBlockStore, error) {
+	if !isValidPathComponent(org) || !isValidPathComponent(repo) {
+		return nil, ErrInvalidRepoName
+	}
+
 	cache.mu.Lock()
 	defer cache.mu.Unlock()
 
@@ -54,6 +65,10 @@ func (cache *DBCache) Get(org, repo, nbfVerStr string) (*nbs.NomsBlockStore, err
 
 	var newCS *nbs.NomsBlockStore
 	if cache.fs != nil {
+		if exists, _ := cache.fs.Exists(id); !exists && !create {
+			return nil, ErrRepoNotFound
+		}
+
 		err := cache.fs.MkDirs(id)
 
 		if err != nil {
@@ -71,3 +86,9 @@ func (cache *DBCache) Get(org, repo, nbfVerStr string) (*nbs.NomsBlockStore, err
 
 	return newCS, nil
 }
+
+// isValidPathComponent returns true if |name| can be used as the name of an org or repository, which are directories
+// under the root directory of the server.
+func isValidPathComponent(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, "/\\\x00")
+}
@@ -18,8 +18,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync/atomic"
 
 	"google.golang.org/grpc/codes"
@@ -36,14 +38,16 @@ type RemoteChunkStore struct {
 	HttpHost string
 	csCache  *DBCache
 	bucket   string
+	signer   *URLSigner
 	remotesapi.UnimplementedChunkStoreServiceServer
 }
 
-func NewHttpFSBackedChunkStore(httpHost string, csCache *DBCache) *RemoteChunkStore {
+func NewHttpFSBackedChunkStore(httpHost string, csCache *DBCache, signer *URLSigner) *RemoteChunkStore {
 	return &RemoteChunkStore{
 		HttpHost: httpHost,
 		csCache:  csCache,
 		bucket:   "",
+		signer:   signer,
 	}
 }
 
@@ -51,10 +55,10 @@ func (rs *RemoteChunkStore) HasChunks(ctx context.Context, req *remotesapi.HasCh
 	logger := getReqLogger("GRPC", "HasChunks")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "HasChunks")
+	cs, err := rs.getStore(ctx, req.RepoId, "HasChunks")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
 	logger(fmt.Sprintf("found repo %s/%s", req.RepoId.Org, req.RepoId.RepoName))
@@ -88,10 +92,10 @@ func (rs *RemoteChunkStore) GetDownloadLocations(ctx context.Context, req *remot
 	logger := getReqLogger("GRPC", "GetDownloadLocations")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "GetDownloadLoctions")
+	cs, err := rs.getStore(ctx, req.RepoId, "GetDownloadLoctions")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
 	logger(fmt.Sprintf("found repo %s/%s", req.RepoId.Org, req.RepoId.RepoName))
@@ -118,7 +122,7 @@ func (rs *RemoteChunkStore) GetDownloadLocations(ctx context.Context, req *remot
 			log.Println("Failed to sign request", err)
 		}
 
-		logger("The URL is " + url)
+		logger("The URL is " + withoutQuery(url))
 
 		getRange := &remotesapi.HttpGetRange{Url: url, Ranges: ranges}
 		locs = append(locs, &remotesapi.DownloadLoc{Location: &remotesapi.DownloadLoc_HttpGetRange{HttpGetRange: getRange}})
@@ -128,7 +132,29 @@ func (rs *RemoteChunkStore) GetDownloadLocations(ctx context.Context, req *remot
 }
 
 func (rs *RemoteChunkStore) getDownloadUrl(logger func(string), org, repoName, fileId string) (string, error) {
-	return fmt.Sprintf("http://%s/%s/%s/%s", rs.HttpHost, org, repoName, fileId), nil
+	return rs.getSignedUrl(readOp, org, repoName, fileId), nil
+}
+
+// getSignedUrl returns the url of a table file on the http server, which allows |op| on the file until it expires
+func (rs *RemoteChunkStore) getSignedUrl(op, org, repoName, fileId string) string {
+	path := tableFilePath(org, repoName, fileId)
+	u := url.URL{
+		Scheme:   "http",
+		Host:     rs.HttpHost,
+		Path:     path,
+		RawQuery: rs.signer.Sign(op, path),
+	}
+
+	return u.String()
+}
+
+// withoutQuery returns |u| without its query string, so that signatures aren't logged
+func withoutQuery(u string) string {
+	if i := strings.IndexByte(u, '?'); i != -1 {
+		return u[:i]
+	}
+
+	return u
 }
 
 func parseTableFileDetails(req *remotesapi.GetUploadLocsRequest) []*remotesapi.TableFileDetails {
@@ -154,10 +180,10 @@ func (rs *RemoteChunkStore) GetUploadLocations(ctx context.Context, req *remotes
 	logger := getReqLogger("GRPC", "GetUploadLocations")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "GetWriteChunkUrls")
+	_, err := rs.getStore(ctx, req.RepoId, "GetWriteChunkUrls")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
 	logger(fmt.Sprintf("found repo %s/%s", req.RepoId.Org, req.RepoId.RepoName))
@@ -178,7 +204,7 @@ func (rs *RemoteChunkStore) GetUploadLocations(ctx context.Context, req *remotes
 		loc := &remotesapi.UploadLoc_HttpPost{HttpPost: &remotesapi.HttpPostTableFile{Url: url}}
 		locs = append(locs, &remotesapi.UploadLoc{TableFileHash: h[:], Location: loc})
 
-		logger(fmt.Sprintf("sending upload location for chunk %s: %s", h.String(), url))
+		logger(fmt.Sprintf("sending upload location for chunk %s: %s", h.String(), withoutQuery(url)))
 	}
 
 	return &remotesapi.GetUploadLocsResponse{Locs: locs}, nil
@@ -186,23 +212,23 @@ func (rs *RemoteChunkStore) GetUploadLocations(ctx context.Context, req *remotes
 
 func (rs *RemoteChunkStore) getUploadUrl(logger func(string), org, repoName string, tfd *remotesapi.TableFileDetails) (string, error) {
 	fileID := hash.New(tfd.Id).String()
-	expectedFiles[fileID] = tfd
-	return fmt.Sprintf("http://%s/%s/%s/%s", rs.HttpHost, org, repoName, fileID), nil
+	expectedFiles.Put(org, repoName, fileID, tfd)
+	return rs.getSignedUrl(writeOp, org, repoName, fileID), nil
 }
 
 func (rs *RemoteChunkStore) Rebase(ctx context.Context, req *remotesapi.RebaseRequest) (*remotesapi.RebaseResponse, error) {
 	logger := getReqLogger("GRPC", "Rebase")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "Rebase")
+	cs, err := rs.getStore(ctx, req.RepoId, "Rebase")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
 	logger(fmt.Sprintf("found %s/%s", req.RepoId.Org, req.RepoId.RepoName))
 
-	err := cs.Rebase(ctx)
+	err = cs.Rebase(ctx)
 
 	if err != nil {
 		logger(fmt.Sprintf("error occurred during processing of Rebace rpc of %s/%s details: %v", req.RepoId.Org, req.RepoId.RepoName, err))
@@ -216,10 +242,10 @@ func (rs *RemoteChunkStore) Root(ctx context.Context, req *remotesapi.RootReques
 	logger := getReqLogger("GRPC", "Root")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "Root")
+	cs, err := rs.getStore(ctx, req.RepoId, "Root")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
 	h, err := cs.Root(ctx)
@@ -236,10 +262,10 @@ func (rs *RemoteChunkStore) Commit(ctx context.Context, req *remotesapi.CommitRe
 	logger := getReqLogger("GRPC", "Commit")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "Commit")
+	cs, err := rs.getStore(ctx, req.RepoId, "Commit")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
 	logger(fmt.Sprintf("found %s/%s", req.RepoId.Org, req.RepoId.RepoName))
@@ -250,7 +276,7 @@ func (rs *RemoteChunkStore) Commit(ctx context.Context, req *remotesapi.CommitRe
 		updates[hash.New(cti.Hash)] = cti.ChunkCount
 	}
 
-	_, err := cs.UpdateManifest(ctx, updates)
+	_, err = cs.UpdateManifest(ctx, updates)
 
 	if err != nil {
 		logger(fmt.Sprintf("error occurred updating the manifest: %s", err.Error()))
@@ -276,9 +302,9 @@ func (rs *RemoteChunkStore) GetRepoMetadata(ctx context.Context, req *remotesapi
 	logger := getReqLogger("GRPC", "GetRepoMetadata")
 	defer func() { logger("finished") }()
 
-	cs := rs.getOrCreateStore(req.RepoId, "GetRepoMetadata", req.ClientRepoFormat.NbfVersion)
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	cs, err := rs.getOrCreateStore(ctx, req.RepoId, "GetRepoMetadata", req.ClientRepoFormat.NbfVersion)
+	if err != nil {
+		return nil, err
 	}
 
 	_, tfs, err := cs.Sources(ctx)
@@ -310,10 +336,10 @@ func (rs *RemoteChunkStore) ListTableFiles(ctx context.Context, req *remotesapi.
 	logger := getReqLogger("GRPC", "ListTableFiles")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "ListTableFiles")
+	cs, err := rs.getStore(ctx, req.RepoId, "ListTableFiles")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
 	logger(fmt.Sprintf("found repo %s/%s", req.RepoId.Org, req.RepoId.RepoName))
@@ -352,10 +378,10 @@ func (rs *RemoteChunkStore) AddTableFiles(ctx context.Context, req *remotesapi.A
 	logger := getReqLogger("GRPC", "Commit")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "Commit")
+	cs, err := rs.getStore(ctx, req.RepoId, "Commit")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
 	logger(fmt.Sprintf("found %s/%s", req.RepoId.Org, req.RepoId.RepoName))
@@ -366,7 +392,7 @@ func (rs *RemoteChunkStore) AddTableFiles(ctx context.Context, req *remotesapi.A
 		updates[hash.New(cti.Hash)] = cti.ChunkCount
 	}
 
-	_, err := cs.UpdateManifest(ctx, updates)
+	_, err = cs.UpdateManifest(ctx, updates)
 
 	if err != nil {
 		logger(fmt.Sprintf("error occurred updating the manifest: %s", err.Error()))
@@ -376,21 +402,33 @@ func (rs *RemoteChunkStore) AddTableFiles(ctx context.Context, req *remotesapi.A
 	return &remotesapi.AddTableFilesResponse{Success: true}, nil
 }
 
-func (rs *RemoteChunkStore) getStore(repoId *remotesapi.RepoId, rpcName string) *nbs.NomsBlockStore {
-	return rs.getOrCreateStore(repoId, rpcName, types.Format_Default.VersionString())
+func (rs *RemoteChunkStore) getStore(ctx context.Context, repoId *remotesapi.RepoId, rpcName string) (*nbs.NomsBlockStore, error) {
+	return rs.getOrCreateStore(ctx, repoId, rpcName, types.Format_Default.VersionString())
 }
 
-func (rs *RemoteChunkStore) getOrCreateStore(repoId *remotesapi.RepoId, rpcName, nbfVerStr string) *nbs.NomsBlockStore {
+// getOrCreateStore returns the store of the repository |repoId|, and creates it if it doesn't exist and the user of
+// the request has write permission.
+func (rs *RemoteChunkStore) getOrCreateStore(ctx context.Context, repoId *remotesapi.RepoId, rpcName, nbfVerStr string) (*nbs.NomsBlockStore, error) {
+	if repoId == nil {
+		return nil, status.Error(codes.InvalidArgument, "request has no repo id")
+	}
+
 	org := repoId.Org
 	repoName := repoId.RepoName
 
-	cs, err := rs.csCache.Get(org, repoName, nbfVerStr)
+	create := rpcName == "GetRepoMetadata" && permissionFromContext(ctx) >= WritePermission
+	cs, err := rs.csCache.Get(org, repoName, nbfVerStr, create)
 
-	if err != nil {
+	if err == ErrInvalidRepoName {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid repo %s/%s", org, repoName)
+	} else if err == ErrRepoNotFound {
+		return nil, status.Errorf(codes.NotFound, "repo %s/%s not found", org, repoName)
+	} else if err != nil {
 		log.Printf("Failed to retrieve chunkstore for %s/%s\n", org, repoName)
+		return nil, status.Error(codes.Internal, "Could not get chunkstore")
 	}
 
-	return cs
+	return cs, nil
 }
 
 var requestId int32
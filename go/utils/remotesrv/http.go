@@ -26,6 +26,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	remotesapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
 
@@ -33,27 +34,77 @@ import (
 	"github.com/dolthub/dolt/go/store/hash"
 )
 
-var expectedFiles = make(map[string]*remotesapi.TableFileDetails)
+// expectedFiles are the table files which the grpc server handed out upload urls for
+var expectedFiles = &fileDetailsMap{files: make(map[string]*remotesapi.TableFileDetails)}
 
-func ServeHTTP(respWr http.ResponseWriter, req *http.Request) {
-	logger := getReqLogger("HTTP_"+req.Method, req.RequestURI)
+type fileDetailsMap struct {
+	mu    sync.Mutex
+	files map[string]*remotesapi.TableFileDetails
+}
+
+func (m *fileDetailsMap) Put(org, repo, fileId string, tfd *remotesapi.TableFileDetails) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[tableFilePath(org, repo, fileId)] = tfd
+}
+
+func (m *fileDetailsMap) Get(org, repo, fileId string) (*remotesapi.TableFileDetails, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tfd, ok := m.files[tableFilePath(org, repo, fileId)]
+	return tfd, ok
+}
+
+// tableFilePath is the path of the url of a table file on the http server
+func tableFilePath(org, repo, fileId string) string {
+	return "/" + org + "/" + repo + "/" + fileId
+}
+
+// fileServer serves the table files of repositories over http. Requests must use the signed urls given out by the
+// grpc server.
+type fileServer struct {
+	signer *URLSigner
+}
+
+func (fs fileServer) ServeHTTP(respWr http.ResponseWriter, req *http.Request) {
+	logger := getReqLogger("HTTP_"+req.Method, req.URL.Path)
 	defer func() { logger("finished") }()
 
 	path := strings.TrimLeft(req.URL.Path, "/")
 	tokens := strings.Split(path, "/")
 
-	if len(tokens) != 3 {
-		logger(fmt.Sprintf("response to: %v method: %v http response code: %v", req.RequestURI, req.Method, http.StatusNotFound))
+	if len(tokens) != 3 || !isValidPathComponent(tokens[0]) || !isValidPathComponent(tokens[1]) {
+		logger(fmt.Sprintf("response to: %v method: %v http response code: %v", req.URL.Path, req.Method, http.StatusNotFound))
 		respWr.WriteHeader(http.StatusNotFound)
+		return
 	}
 
 	org := tokens[0]
 	repo := tokens[1]
 	hashStr := tokens[2]
 
-	statusCode := http.StatusMethodNotAllowed
+	var op string
 	switch req.Method {
 	case http.MethodGet:
+		op = readOp
+	case http.MethodPost, http.MethodPut:
+		op = writeOp
+	default:
+		respWr.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := fs.signer.Verify(op, tableFilePath(org, repo, hashStr), req.URL.Query())
+
+	if err != nil {
+		logger(fmt.Sprintf("rejecting request: %v", err))
+		respWr.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	var statusCode int
+	switch op {
+	case readOp:
 		rangeStr := req.Header.Get("Range")
 
 		if rangeStr == "" {
@@ -62,7 +113,7 @@ func ServeHTTP(respWr http.ResponseWriter, req *http.Request) {
 			statusCode = readChunk(logger, org, repo, hashStr, rangeStr, respWr)
 		}
 
-	case http.MethodPost, http.MethodPut:
+	case writeOp:
 		statusCode = writeTableFile(logger, org, repo, hashStr, req)
 	}
 
@@ -79,7 +130,7 @@ func writeTableFile(logger func(string), org, repo, fileId string, request *http
 		return http.StatusBadRequest
 	}
 
-	tfd, ok := expectedFiles[fileId]
+	tfd, ok := expectedFiles.Get(org, repo, fileId)
 
 	if !ok {
 		return http.StatusBadRequest
@@ -24,6 +24,7 @@ import (
 	"os"
 	"os/signal"
 	"sync"
+	"time"
 
 	"google.golang.org/grpc"
 
@@ -32,11 +33,30 @@ import (
 )
 
 func main() {
-	dirParam := flag.String("dir", "", "root directory that this command will run in.")
-	grpcPortParam := flag.Int("grpc-port", -1, "root directory that this command will run in.")
-	httpPortParam := flag.Int("http-port", -1, "root directory that this command will run in.")
+	dirParam := flag.String("dir", "", "root directory that this command will run in. repositories are stored in <dir>/<org>/<repo>.")
+	grpcPortParam := flag.Int("grpc-port", -1, "port the grpc remote chunkstore api is served on.")
+	httpPortParam := flag.Int("http-port", -1, "port the http table file server is served on.")
+	httpHostParam := flag.String("http-host", "", "host and port clients reach the http table file server at. defaults to localhost:<http-port>.")
+	authConfigParam := flag.String("auth-config", "", "json file of the users of the server and their permissions on repositories. if not provided every request can read and write every repository.")
+	urlExpirationParam := flag.Duration("url-expiration", time.Hour, "how long the signed table file urls given to clients are valid for.")
 	flag.Parse()
 
+	// the auth config is read before changing directories, so that the paths in it are relative to where the server
+	// was started.
+	var auth *Authorizer
+	if len(*authConfigParam) > 0 {
+		var err error
+		auth, err = LoadAuthConfig(*authConfigParam)
+
+		if err != nil {
+			log.Fatalln("failed to load auth config:", err.Error())
+		}
+
+		log.Println("loaded auth config " + *authConfigParam)
+	} else {
+		log.Println("'auth-config' parameter not provided. Requests will not be authenticated.")
+	}
+
 	if dirParam != nil && len(*dirParam) > 0 {
 		err := os.Chdir(*dirParam)
 
@@ -60,12 +80,22 @@ func main() {
 		log.Println("'http-port' parameter not provided. Using default port 80")
 	}
 
+	if len(*httpHostParam) > 0 {
+		httpHost = *httpHostParam
+	}
+
 	if *grpcPortParam == -1 {
 		*grpcPortParam = 50051
 		log.Println("'grpc-port' parameter not provided. Using default port 50051")
 	}
 
-	stopChan, wg := startServer(httpHost, *httpPortParam, *grpcPortParam)
+	signer, err := NewURLSigner(*urlExpirationParam)
+
+	if err != nil {
+		log.Fatalln("failed to create url signing key:", err.Error())
+	}
+
+	stopChan, wg := startServer(httpHost, *httpPortParam, *grpcPortParam, auth, signer)
 	waitForSignal()
 
 	close(stopChan)
@@ -73,46 +103,46 @@ func main() {
 }
 
 func waitForSignal() {
-	c := make(chan os.Signal)
+	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
 	signal.Notify(c, os.Kill)
 
 	<-c
 }
 
-func startServer(httpHost string, httpPort, grpcPort int) (chan interface{}, *sync.WaitGroup) {
+func startServer(httpHost string, httpPort, grpcPort int, auth *Authorizer, signer *URLSigner) (chan interface{}, *sync.WaitGroup) {
 	wg := sync.WaitGroup{}
 	stopChan := make(chan interface{})
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		httpServer(httpPort, stopChan)
+		httpServer(httpPort, signer, stopChan)
 	}()
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		grpcServer(httpHost, grpcPort, stopChan)
+		grpcServer(httpHost, grpcPort, auth, signer, stopChan)
 	}()
 
 	return stopChan, &wg
 }
 
-func grpcServer(httpHost string, grpcPort int, stopChan chan interface{}) {
+func grpcServer(httpHost string, grpcPort int, auth *Authorizer, signer *URLSigner, stopChan chan interface{}) {
 	defer func() {
 		log.Println("exiting grpc Server go routine")
 	}()
 
 	dbCache := NewLocalCSCache(filesys.LocalFS)
-	chnkSt := NewHttpFSBackedChunkStore(httpHost, dbCache)
+	chnkSt := NewHttpFSBackedChunkStore(httpHost, dbCache, signer)
 
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", grpcPort))
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer(grpc.MaxRecvMsgSize(128 * 1024 * 1024))
+	grpcServer := grpc.NewServer(grpc.MaxRecvMsgSize(128*1024*1024), grpc.UnaryInterceptor(auth.UnaryServerInterceptor))
 	go func() {
 		remotesapi.RegisterChunkStoreServiceServer(grpcServer, chnkSt)
 
@@ -125,14 +155,14 @@ func grpcServer(httpHost string, grpcPort int, stopChan chan interface{}) {
 	grpcServer.GracefulStop()
 }
 
-func httpServer(httpPort int, stopChan chan interface{}) {
+func httpServer(httpPort int, signer *URLSigner, stopChan chan interface{}) {
 	defer func() {
 		log.Println("exiting http Server go routine")
 	}()
 
 	server := http.Server{
 		Addr:    fmt.Sprintf(":%d", httpPort),
-		Handler: http.HandlerFunc(ServeHTTP),
+		Handler: fileServer{signer},
 	}
 
 	go func() {
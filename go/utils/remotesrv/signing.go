@@ -0,0 +1,104 @@
+// Copyright 2021 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	expiresParam   = "expires"
+	signatureParam = "signature"
+
+	readOp  = "read"
+	writeOp = "write"
+)
+
+var ErrURLNotSigned = errors.New("url is not signed")
+var ErrURLSignatureInvalid = errors.New("url signature is invalid")
+var ErrURLExpired = errors.New("url has expired")
+
+// URLSigner signs the urls of the http table file server, so that the table files of a repository can only be read or
+// written by the users the grpc server gave the urls to, until the urls expire.
+type URLSigner struct {
+	key    []byte
+	expiry time.Duration
+	now    func() time.Time
+}
+
+// NewURLSigner returns a URLSigner for urls which expire |expiry| after they are signed, with a random key. Urls
+// signed by the server are invalid after it restarts.
+func NewURLSigner(expiry time.Duration) (*URLSigner, error) {
+	key := make([]byte, sha256.Size)
+	_, err := rand.Read(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &URLSigner{key, expiry, time.Now}, nil
+}
+
+// Sign returns the query string which allows |op| on |path| until the url expires
+func (s *URLSigner) Sign(op, path string) string {
+	expires := strconv.FormatInt(s.now().Add(s.expiry).Unix(), 10)
+
+	vals := url.Values{}
+	vals.Set(expiresParam, expires)
+	vals.Set(signatureParam, hex.EncodeToString(s.mac(op, path, expires)))
+
+	return vals.Encode()
+}
+
+// Verify checks that |query| is the query string of a url signed for |op| on |path| which hasn't expired
+func (s *URLSigner) Verify(op, path string, query url.Values) error {
+	expires := query.Get(expiresParam)
+	sigStr := query.Get(signatureParam)
+
+	if expires == "" || sigStr == "" {
+		return ErrURLNotSigned
+	}
+
+	sig, err := hex.DecodeString(sigStr)
+
+	if err != nil || !hmac.Equal(sig, s.mac(op, path, expires)) {
+		return ErrURLSignatureInvalid
+	}
+
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+
+	if err != nil {
+		return ErrURLSignatureInvalid
+	}
+
+	if s.now().Unix() > expiresUnix {
+		return ErrURLExpired
+	}
+
+	return nil
+}
+
+func (s *URLSigner) mac(op, path, expires string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(op + "\n" + path + "\n" + expires))
+	return mac.Sum(nil)
+}